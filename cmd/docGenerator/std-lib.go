@@ -98,7 +98,7 @@ func readGoFileStdLib(path string) error {
 			continue
 		}
 
-		if strings.HasPrefix(line, "environment.AddNativeFunction(") {
+		if strings.HasPrefix(line, "environment.AddNativeFunction(") || strings.HasPrefix(line, "environment.AddNativeFunctionByRef(") {
 			if category == "" {
 				return fmt.Errorf("category is not set before adding native functions in file %s", path)
 			}
@@ -107,7 +107,7 @@ func readGoFileStdLib(path string) error {
 				stdLibFunctions[category] = []string{}
 			}
 
-			functionName := strings.Replace(line, `environment.AddNativeFunction("`, "", 1)
+			functionName := line[strings.Index(line, `"`)+1:]
 			functionName = functionName[:strings.Index(functionName, `"`)]
 
 			stdLibFunctions[category] = append(stdLibFunctions[category], functionName)
@@ -78,8 +78,17 @@ func (visitor DumpVisitor) getKindAndPos(stmt IStatement) string {
 
 // ProcessAnonymousFunctionCreationExpr implements Visitor.
 func (visitor DumpVisitor) ProcessAnonymousFunctionCreationExpr(stmt *AnonymousFunctionCreationExpression, _ any) (any, error) {
-	return fmt.Sprintf(`{ %s, "params": %s, "body": %s, "returnType": [%s] }`,
-		visitor.getKindAndPos(stmt), visitor.ProcessFunctionParameterSlice(stmt.Params), visitor.toString(stmt.Body), common.ImplodeStrSlice(stmt.ReturnType),
+	uses := "["
+	for _, use := range stmt.Uses {
+		if len(uses) > 1 {
+			uses += ", "
+		}
+		uses += fmt.Sprintf(`{ "byRef": %v, "name": "%s" }`, use.ByRef, use.Name)
+	}
+	uses += "]"
+	return fmt.Sprintf(`{ %s, "params": %s, "uses": %s, "body": %s, "returnType": [%s], "isArrowFunction": %v }`,
+		visitor.getKindAndPos(stmt), visitor.ProcessFunctionParameterSlice(stmt.Params), uses, visitor.toString(stmt.Body),
+		common.ImplodeStrSlice(stmt.ReturnType), stmt.IsArrowFunction,
 	), nil
 }
 
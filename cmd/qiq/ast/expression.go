@@ -699,14 +699,34 @@ func (stmt *MemberAccessExpression) Process(visitor Visitor, context any) (any,
 
 type AnonymousFunctionCreationExpression struct {
 	*Statement
-	Params     []FunctionParameter
-	Body       *CompoundStatement
-	ReturnType []string
+	Params          []FunctionParameter
+	Uses            []AnonymousFunctionUse
+	Body            *CompoundStatement
+	ReturnType      []string
+	IsArrowFunction bool
 }
 
-func NewAnonymousFunctionCreationExpr(id int64, pos *position.Position, params []FunctionParameter, body *CompoundStatement, returnType []string) *AnonymousFunctionCreationExpression {
+// Variable captured by the use clause of an anonymous function
+type AnonymousFunctionUse struct {
+	Name  string
+	ByRef bool
+}
+
+func NewAnonymousFunctionUse(byRef bool, name string) AnonymousFunctionUse {
+	return AnonymousFunctionUse{ByRef: byRef, Name: name}
+}
+
+func NewAnonymousFunctionCreationExpr(id int64, pos *position.Position, params []FunctionParameter, uses []AnonymousFunctionUse, body *CompoundStatement, returnType []string) *AnonymousFunctionCreationExpression {
+	return &AnonymousFunctionCreationExpression{Statement: NewStmt(id, AnonymousFunctionCreationExpr, pos),
+		Params: params, Uses: uses, Body: body, ReturnType: returnType,
+	}
+}
+
+// Create an arrow function (`fn($x) => $x * 2`). The expression is wrapped into a return statement.
+func NewArrowFunctionCreationExpr(id int64, pos *position.Position, params []FunctionParameter, expr IExpression, returnType []string) *AnonymousFunctionCreationExpression {
 	return &AnonymousFunctionCreationExpression{Statement: NewStmt(id, AnonymousFunctionCreationExpr, pos),
-		Params: params, Body: body, ReturnType: returnType,
+		Params: params, Uses: []AnonymousFunctionUse{}, Body: NewCompoundStmt(0, []IStatement{NewReturnStmt(0, pos, expr)}),
+		ReturnType: returnType, IsArrowFunction: true,
 	}
 }
 
@@ -30,3 +30,11 @@ func IsVariableExpr(expr IExpression) bool {
 
 	return slices.Contains(variableExpressions, expr.GetKind())
 }
+
+// Get the innermost expression of a (nested) subscript expression (e.g. "$a" for "$a[1][2]")
+func GetSubscriptRoot(expr IExpression) IExpression {
+	for expr != nil && expr.GetKind() == SubscriptExpr {
+		expr = expr.(*SubscriptExpression).Variable
+	}
+	return expr
+}
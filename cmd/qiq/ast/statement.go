@@ -73,8 +73,8 @@ func (stmt *MethodDefinitionStatement) Process(visitor Visitor, context any) (an
 
 func (stmt *MethodDefinitionStatement) GetRequiredParamLen() int {
 	for i, param := range stmt.Params {
-		if param.DefaultValue != nil {
-			return i + 1
+		if param.DefaultValue != nil || param.IsVariadic {
+			return i
 		}
 	}
 	return len(stmt.Params)
@@ -84,6 +84,10 @@ func (stmt *MethodDefinitionStatement) IsStatic() bool {
 	return slices.Contains(stmt.Modifiers, "static")
 }
 
+func (stmt *MethodDefinitionStatement) IsAbstract() bool {
+	return slices.Contains(stmt.Modifiers, "abstract")
+}
+
 // -------------------------------------- FunctionDefinitionStatement -------------------------------------- MARK: FunctionDefinitionStatement
 
 type FunctionParameter struct {
@@ -91,6 +95,7 @@ type FunctionParameter struct {
 	Name         string
 	ByRef        bool
 	DefaultValue IExpression
+	IsVariadic   bool
 }
 
 func NewFunctionParam(byRef bool, name string, paramType []string, defaultValue IExpression) FunctionParameter {
@@ -337,6 +342,7 @@ type PropertyDeclarationStatement struct {
 	*Statement
 	Visibility   string
 	IsStatic     bool
+	IsReadonly   bool
 	Name         string
 	Type         []string
 	InitialValue IExpression
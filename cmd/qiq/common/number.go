@@ -2,6 +2,7 @@ package common
 
 import (
 	"fmt"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -266,3 +267,85 @@ func FloatingLiteralToFloat64WithSign(str string, leadingNumeric bool) float64 {
 	}
 	return result
 }
+
+// Format a float with the given number of significant digits like PHP's zend_gcvt (e.g. for echo with the "precision" ini directive).
+// A precision of -1 uses the shortest representation that is read back to the same value.
+// The exponential format (e.g. 1.0E+25) is used if the exponent is smaller than -4 or greater than or equal to the precision.
+func FormatFloatWithPrecision(value float64, precision int, exponentChar byte) string {
+	if math.IsNaN(value) {
+		return "NAN"
+	}
+	if math.IsInf(value, 1) {
+		return "INF"
+	}
+	if math.IsInf(value, -1) {
+		return "-INF"
+	}
+
+	if precision == 0 {
+		precision = 1
+	}
+	maxDigits := precision
+	fractionDigits := precision - 1
+	if precision < 0 {
+		maxDigits = 17
+		fractionDigits = -1
+	}
+
+	var builder strings.Builder
+	if math.Signbit(value) && value != 0 {
+		builder.WriteByte('-')
+	}
+
+	// Get the significant digits (without trailing zeros) and the position of the decimal point
+	formatted := strconv.FormatFloat(math.Abs(value), 'e', fractionDigits, 64)
+	mantissa, exponentStr, _ := strings.Cut(formatted, "e")
+	digits := strings.TrimRight(strings.Replace(mantissa, ".", "", 1), "0")
+	if digits == "" {
+		digits = "0"
+	}
+	exponent, _ := strconv.Atoi(exponentStr)
+	if value == 0 {
+		exponent = 0
+	}
+	decimalPoint := exponent + 1
+
+	if decimalPoint < -3 || decimalPoint > maxDigits {
+		// Exponential format
+		builder.WriteByte(digits[0])
+		builder.WriteByte('.')
+		if len(digits) == 1 {
+			builder.WriteByte('0')
+		} else {
+			builder.WriteString(digits[1:])
+		}
+		builder.WriteByte(exponentChar)
+		if exponent < 0 {
+			builder.WriteByte('-')
+			exponent = -exponent
+		} else {
+			builder.WriteByte('+')
+		}
+		builder.WriteString(strconv.Itoa(exponent))
+		return builder.String()
+	}
+
+	if decimalPoint <= 0 {
+		// Standard format "0.000ddd"
+		builder.WriteString("0.")
+		builder.WriteString(strings.Repeat("0", -decimalPoint))
+		builder.WriteString(digits)
+		return builder.String()
+	}
+
+	// Standard format "ddd.ddd"
+	if len(digits) <= decimalPoint {
+		builder.WriteString(digits)
+		builder.WriteString(strings.Repeat("0", decimalPoint-len(digits)))
+		return builder.String()
+	}
+	builder.WriteString(digits[:decimalPoint])
+	builder.WriteByte('.')
+	builder.WriteString(digits[decimalPoint:])
+	return builder.String()
+}
@@ -364,3 +364,117 @@ func ExtendWithSpaces(str string, length int) string {
 	}
 	return string(append(input, padding...))
 }
+
+// Compare two strings using a "natural order" algorithm (e.g. "img2" < "img12").
+// Returns -1, 0 or 1 like PHP's strnatcmp and strnatcasecmp.
+func NaturalCompare(a string, b string, ignoreCase bool) int {
+	// Spec: https://www.php.net/manual/en/function.strnatcmp.php
+	if len(a) == 0 || len(b) == 0 {
+		return compareInts(len(a), len(b))
+	}
+
+	isDigit := func(char byte) bool { return char >= '0' && char <= '9' }
+	isSpace := func(char byte) bool { return char == ' ' || (char >= '\t' && char <= '\r') }
+	charAt := func(str string, index int) byte {
+		if index < len(str) {
+			return str[index]
+		}
+		return 0
+	}
+
+	aPos, bPos := 0, 0
+	// Skip over leading zeros
+	for a[aPos] == '0' && isDigit(charAt(a, aPos+1)) {
+		aPos++
+	}
+	for b[bPos] == '0' && isDigit(charAt(b, bPos+1)) {
+		bPos++
+	}
+
+	for {
+		// Skip consecutive whitespace
+		for isSpace(charAt(a, aPos)) {
+			aPos++
+		}
+		for isSpace(charAt(b, bPos)) {
+			bPos++
+		}
+
+		// Process run of digits
+		if isDigit(charAt(a, aPos)) && isDigit(charAt(b, bPos)) {
+			result := 0
+			if a[aPos] == '0' || b[bPos] == '0' {
+				// Fractional parts are compared left-aligned
+				for ; result == 0; aPos, bPos = aPos+1, bPos+1 {
+					aIsDigit, bIsDigit := isDigit(charAt(a, aPos)), isDigit(charAt(b, bPos))
+					if !aIsDigit || !bIsDigit {
+						result = compareInts(boolToInt(aIsDigit), boolToInt(bIsDigit))
+						break
+					}
+					result = compareInts(int(a[aPos]), int(b[bPos]))
+				}
+			} else {
+				// The longest run of digits wins. Otherwise the first differing digit decides.
+				bias := 0
+				for ; ; aPos, bPos = aPos+1, bPos+1 {
+					aIsDigit, bIsDigit := isDigit(charAt(a, aPos)), isDigit(charAt(b, bPos))
+					if !aIsDigit || !bIsDigit {
+						result = compareInts(boolToInt(aIsDigit), boolToInt(bIsDigit))
+						if result == 0 {
+							result = bias
+						}
+						break
+					}
+					if bias == 0 {
+						bias = compareInts(int(a[aPos]), int(b[bPos]))
+					}
+				}
+			}
+			if result != 0 {
+				return result
+			}
+			if aPos >= len(a) || bPos >= len(b) {
+				return compareInts(len(a)-aPos, len(b)-bPos)
+			}
+		}
+
+		aChar, bChar := charAt(a, aPos), charAt(b, bPos)
+		if ignoreCase {
+			aChar, bChar = ToUpper(aChar), ToUpper(bChar)
+		}
+		if aChar != bChar {
+			return compareInts(int(aChar), int(bChar))
+		}
+
+		aPos++
+		bPos++
+		if aPos >= len(a) || bPos >= len(b) {
+			return compareInts(len(a)-aPos, len(b)-bPos)
+		}
+	}
+}
+
+func compareInts(a int, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func boolToInt(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// Convert the byte to uppercase if it is in the range "a" to "z"
+func ToUpper(char byte) byte {
+	if char >= 'a' && char <= 'z' {
+		return char - 'a' + 'A'
+	}
+	return char
+}
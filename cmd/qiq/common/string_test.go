@@ -114,3 +114,22 @@ func TestIsVariableName(t *testing.T) {
 	doTest(t, "$0", false)
 	doTest(t, "var", false)
 }
+
+func TestNaturalCompare(t *testing.T) {
+	doTest := func(t *testing.T, a string, b string, ignoreCase bool, expected int) {
+		if got := NaturalCompare(a, b, ignoreCase); got != expected {
+			t.Errorf("\nCompare \"%s\" and \"%s\"\nExpected: %d.\nGot: %d", a, b, expected, got)
+		}
+	}
+
+	doTest(t, "img12.png", "img10.png", false, 1)
+	doTest(t, "img2.png", "img12.png", false, -1)
+	doTest(t, "img1.png", "img1.png", false, 0)
+	doTest(t, "IMG1.png", "img1.png", false, -1)
+	doTest(t, "IMG1.png", "img1.png", true, 0)
+	doTest(t, "1.010", "1.02", false, -1)
+	doTest(t, "x 2", "x  2", false, 0)
+	doTest(t, "007", "7", false, 0)
+	doTest(t, "a", "", false, 1)
+	doTest(t, "abc", "abcd", false, -1)
+}
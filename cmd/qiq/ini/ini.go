@@ -36,8 +36,20 @@ func NewDevIniFromArray(ini []string) (*Ini, phpError.Error) {
 	var resultErr phpError.Error = nil
 
 	for _, setting := range ini {
+		// Settings are read like the lines of an ini file. Lines without a directive are ignored.
+		setting = strings.TrimSpace(setting)
+		if setting == "" || strings.HasPrefix(setting, ";") || !strings.Contains(setting, "=") {
+			continue
+		}
 		parts := strings.SplitN(setting, "=", 2)
-		err := defaultIni.Set(parts[0], parts[1], INI_ALL)
+		directive, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		// Text after an unquoted semicolon is a comment
+		if index := strings.Index(value, ";"); index >= 0 && !strings.HasPrefix(value, `"`) {
+			value = strings.TrimSpace(value[:index])
+		}
+		value = strings.TrimPrefix(value, `"`)
+		value = strings.TrimSuffix(value, `"`)
+		err := defaultIni.Set(directive, value, INI_ALL)
 		if err != nil {
 			if resultErr == nil {
 				resultErr = phpError.NewError("%s", err)
@@ -14,6 +14,7 @@ var allowedDirectives = map[string]int{
 	"precision":           INI_ALL,
 	"serialize_precision": INI_ALL,
 	"disable_functions":   INI_SYSTEM,
+	"disable_classes":     INI_SYSTEM,
 	"expose_php":          INI_SYSTEM,
 	// Data Handling
 	// Spec: https://www.php.net/manual/en/ini.core.php#ini.sect.data-handling
@@ -37,15 +38,23 @@ var allowedDirectives = map[string]int{
 	"max_input_vars":          INI_PERDIR,
 	"upload_max_filesize":     INI_PERDIR,
 	"max_file_uploads":        INI_PERDIR,
+	// BCMath
+	// Spec: https://www.php.net/manual/en/bc.configuration.php
+	"bcmath.scale": INI_ALL,
+	// Date/Time
+	// Spec: https://www.php.net/manual/en/datetime.configuration.php
+	"date.timezone": INI_ALL,
 	// Installation / Configuration
 	// Spec: https://www.php.net/manual/en/info.configuration.php
-	"assert.exception":            INI_ALL,
-	"max_execution_time":          INI_ALL,
-	"max_input_time":              INI_PERDIR,
-	"zend.enable_gc":              INI_ALL,
-	"zend.max_allowed_stack_size": INI_SYSTEM,
-	"zend.reserved_stack_size":    INI_SYSTEM,
-	"fiber.stack_size":            INI_ALL,
+	"assert.exception":                    INI_ALL,
+	"max_execution_time":                  INI_ALL,
+	"max_input_time":                      INI_PERDIR,
+	"zend.enable_gc":                      INI_ALL,
+	"zend.exception_ignore_args":          INI_ALL,
+	"zend.exception_string_param_max_len": INI_ALL,
+	"zend.max_allowed_stack_size":         INI_SYSTEM,
+	"zend.reserved_stack_size":            INI_SYSTEM,
+	"fiber.stack_size":                    INI_ALL,
 	// Category: Misc.
 	// Spec: https://www.php.net/manual/en/misc.configuration.php
 	"ignore_user_about": INI_ALL,
@@ -63,6 +72,11 @@ var allowedDirectives = map[string]int{
 	// Spec: https://www.php.net/manual/en/ini.core.php#ini.sect.resource-limits
 	"max_memory_limit": INI_SYSTEM,
 	"memory_limit":     INI_ALL,
+	// PCRE
+	// Spec: https://www.php.net/manual/en/pcre.configuration.php
+	"pcre.backtrack_limit": INI_ALL,
+	"pcre.recursion_limit": INI_ALL,
+	"pcre.jit":             INI_ALL,
 	// Session
 	// Spec: https://www.php.net/manual/en/session.configuration.php
 	"session.save_path":                INI_ALL,
@@ -122,6 +136,7 @@ var defaultValues = map[string]string{
 	"precision":           "14",
 	"serialize_precision": "-1",
 	"disable_functions":   "",
+	"disable_classes":     "",
 	"expose_php":          "",
 	// Category: Data Handling
 	// Spec: https://www.php.net/manual/en/ini.core.php#ini.sect.data-handling
@@ -145,15 +160,23 @@ var defaultValues = map[string]string{
 	"max_input_vars":          "1000",
 	"upload_max_filesize":     "2M",
 	"max_file_uploads":        "20",
+	// Category: BCMath
+	// Spec: https://www.php.net/manual/en/bc.configuration.php
+	"bcmath.scale": "0",
+	// Category: Date/Time
+	// Spec: https://www.php.net/manual/en/datetime.configuration.php
+	"date.timezone": "",
 	// Category: Installation / Configuration
 	// Spec: https://www.php.net/manual/en/info.configuration.php
-	"assert.exception":            "1",
-	"max_execution_time":          "30",
-	"max_input_time":              "-1",
-	"zend.enable_gc":              "1",
-	"zend.max_allowed_stack_size": "0",
-	"zend.reserved_stack_size":    "0",
-	"fiber.stack_size":            "2M",
+	"assert.exception":                    "1",
+	"max_execution_time":                  "30",
+	"max_input_time":                      "-1",
+	"zend.enable_gc":                      "1",
+	"zend.exception_ignore_args":          "",
+	"zend.exception_string_param_max_len": "15",
+	"zend.max_allowed_stack_size":         "0",
+	"zend.reserved_stack_size":            "0",
+	"fiber.stack_size":                    "2M",
 	// Category: Misc.
 	// Spec: https://www.php.net/manual/en/misc.configuration.php
 	"ignore_user_about": "0",
@@ -171,6 +194,11 @@ var defaultValues = map[string]string{
 	// Spec: https://www.php.net/manual/en/ini.core.php#ini.sect.resource-limits
 	"max_memory_limit": "-1",
 	"memory_limit":     "128M",
+	// Category: PCRE
+	// Spec: https://www.php.net/manual/en/pcre.configuration.php
+	"pcre.backtrack_limit": "1000000",
+	"pcre.recursion_limit": "100000",
+	"pcre.jit":             "1",
 	// Category: Session
 	// Spec: https://www.php.net/manual/en/session.configuration.php
 	"session.save_path":                "",
@@ -225,6 +253,7 @@ var boolDirectives = []string{
 	"file_uploads",
 	"ignore_user_abort",
 	"mbstring.encoding_translation",
+	"pcre.jit",
 	"register_argc_argv",
 	"session.auto_start",
 	"session.cookie_httponly",
@@ -238,6 +267,7 @@ var boolDirectives = []string{
 	"session.use_trans_sid",
 	"short_open_tag",
 	"zend.enable_gc",
+	"zend.exception_ignore_args",
 	"zlib.output_compression",
 	// QIQ
 	"qiq.case_sensitive_include",
@@ -245,6 +275,7 @@ var boolDirectives = []string{
 }
 
 var intDirectives = []string{
+	"bcmath.scale",
 	"error_reporting",
 	"fiber.stack_size",
 	"max_execution_time",
@@ -252,6 +283,8 @@ var intDirectives = []string{
 	"max_input_nesting_level",
 	"max_input_time",
 	"max_input_vars",
+	"pcre.backtrack_limit",
+	"pcre.recursion_limit",
 	// "max_memory_limit", // TODO Fix ini logic because this can also be "128M"
 	// "memory_limit", // TODO Fix ini logic because this can also be "128M"
 	"precision",
@@ -265,6 +298,7 @@ var intDirectives = []string{
 	"session.sid_bits_per_character",
 	"session.sid_length",
 	"session.upload_progress.min_freq",
+	"zend.exception_string_param_max_len",
 	"zend.max_allowed_stack_size",
 	"zend.reserved_stack_size",
 	"zlib.output_compression_level",
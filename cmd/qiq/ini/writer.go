@@ -112,6 +112,9 @@ func (w *Writer) Write() error {
 	if err := w.addDirectiveAndValue(&builder, "disable_functions"); err != nil {
 		return err
 	}
+	if err := w.addDirectiveAndValue(&builder, "disable_classes"); err != nil {
+		return err
+	}
 	if err := w.addDirectiveAndValue(&builder, "expose_php"); err != nil {
 		return err
 	}
@@ -199,6 +202,12 @@ func (w *Writer) Write() error {
 	if err := w.addDirectiveAndValue(&builder, "zend.enable_gc"); err != nil {
 		return err
 	}
+	if err := w.addDirectiveAndValue(&builder, "zend.exception_ignore_args"); err != nil {
+		return err
+	}
+	if err := w.addDirectiveAndValue(&builder, "zend.exception_string_param_max_len"); err != nil {
+		return err
+	}
 	if err := w.addDirectiveAndValue(&builder, "zend.max_allowed_stack_size"); err != nil {
 		return err
 	}
@@ -244,6 +253,18 @@ func (w *Writer) Write() error {
 		return err
 	}
 
+	w.addHeading(&builder, "PCRE")
+
+	if err := w.addDirectiveAndValue(&builder, "pcre.backtrack_limit"); err != nil {
+		return err
+	}
+	if err := w.addDirectiveAndValue(&builder, "pcre.recursion_limit"); err != nil {
+		return err
+	}
+	if err := w.addDirectiveAndValue(&builder, "pcre.jit"); err != nil {
+		return err
+	}
+
 	w.addHeading(&builder, "Zlib")
 
 	if err := w.addDirectiveAndValue(&builder, "zlib.output_compression"); err != nil {
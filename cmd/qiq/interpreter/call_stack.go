@@ -0,0 +1,141 @@
+package interpreter
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/stdlib/errorHandling"
+	"QIQ/cmd/qiq/runtime/values"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Frame of the call stack
+type callFrame struct {
+	runtime.CallFrame
+	// Natively implemented function or method
+	isNative bool
+}
+
+// Push the frame of a call onto the call stack.
+// The position of the call is the position of the currently processed statement.
+func (interpreter *Interpreter) pushCallFrame(frame callFrame) {
+	// Calls from natively implemented code have no position
+	if interpreter.currentStmt != nil && (len(interpreter.callStack) == 0 || !interpreter.callStack[len(interpreter.callStack)-1].isNative) {
+		frame.Position = interpreter.currentStmt.GetPosition()
+	}
+	interpreter.callStack = append(interpreter.callStack, frame)
+}
+
+func (interpreter *Interpreter) popCallFrame() {
+	interpreter.callStack = interpreter.callStack[:len(interpreter.callStack)-1]
+}
+
+func (interpreter *Interpreter) pushMethodCallFrame(object *values.Object, method *ast.MethodDefinitionStatement, args []*values.Slot, isNative bool) {
+	callType := "->"
+	if object == nil {
+		callType = "::"
+	}
+	interpreter.pushCallFrame(callFrame{
+		CallFrame: runtime.CallFrame{Function: method.Name, Class: method.Class.GetQualifiedName(), Object: object, Type: callType, Args: slotValues(args)},
+		isNative:  isNative,
+	})
+}
+
+func (interpreter *Interpreter) pushFunctionCallFrame(function *ast.FunctionDefinitionStatement, args []*values.Slot, functionEnv *Environment) {
+	frame := runtime.CallFrame{Function: function.FunctionName, Args: slotValues(args)}
+	// Closures that are bound to a class scope
+	if functionEnv.CurrentMethod != nil && functionEnv.CurrentMethod.Class != nil {
+		frame.Class = functionEnv.CurrentMethod.Class.GetQualifiedName()
+		frame.Object = functionEnv.CurrentObject
+		frame.Type = "::"
+		if frame.Object != nil {
+			frame.Type = "->"
+		}
+	}
+	interpreter.pushCallFrame(callFrame{CallFrame: frame})
+}
+
+// Call a natively implemented function.
+// refArgs contains the slots of the arguments passed by reference.
+func (interpreter *Interpreter) callNativeFunction(
+	name string, function runtime.NativeFunction, args []values.RuntimeValue, refArgs []*values.Slot, env *Environment, stmt ast.IStatement,
+) (values.RuntimeValue, phpError.Error) {
+	interpreter.pushCallFrame(callFrame{CallFrame: runtime.CallFrame{Function: strings.ToLower(name), Args: args}, isNative: true})
+	context := runtime.NewContext(interpreter, env, stmt)
+	context.RefArgs = refArgs
+	runtimeValue, err := function(args, context)
+	interpreter.popCallFrame()
+	interpreter.releaseNativeCall(env, args, runtimeValue, err)
+	return runtimeValue, err
+}
+
+func slotValues(slots []*values.Slot) []values.RuntimeValue {
+	runtimeValues := make([]values.RuntimeValue, len(slots))
+	for index, slot := range slots {
+		runtimeValues[index] = slot.Value
+	}
+	return runtimeValues
+}
+
+// Get the frames of the call stack starting with the innermost call
+func (interpreter *Interpreter) GetBacktrace() []runtime.CallFrame {
+	frames := make([]runtime.CallFrame, len(interpreter.callStack))
+	for index, frame := range interpreter.callStack {
+		frames[len(frames)-1-index] = frame.CallFrame
+	}
+	return frames
+}
+
+// Get the arguments passed to the function that is executed in the given environment.
+// The values of the parameters reflect modifications made by the function.
+func (interpreter *Interpreter) GetFunctionArgs(env any) ([]values.RuntimeValue, bool) {
+	// The innermost frame is the frame of the native function requesting the arguments
+	if len(interpreter.callStack) < 2 {
+		return nil, false
+	}
+	args := slices.Clone(interpreter.callStack[len(interpreter.callStack)-2].Args)
+
+	var params []ast.FunctionParameter
+	environment := env.(*Environment)
+	if environment.CurrentFunction != nil {
+		params = environment.CurrentFunction.Params
+	} else if environment.CurrentMethod != nil {
+		params = environment.CurrentMethod.Params
+	}
+	for index, param := range params {
+		if index >= len(args) {
+			break
+		}
+		if slot, found := environment.variables[param.Name]; found {
+			args[index] = slot.Value
+		}
+	}
+	return args, true
+}
+
+// Get the class of the method executed in the given environment that is bound to "static" (late static binding)
+func (interpreter *Interpreter) GetCalledClass(env any) (*ast.ClassDeclarationStatement, bool) {
+	calledClass := env.(*Environment).CalledClass
+	return calledClass, calledClass != nil
+}
+
+// Get the trace of a Throwable that is created at the current position
+func (interpreter *Interpreter) getThrowableTrace() *values.Array {
+	options := int64(0)
+	if interpreter.ini.GetBool("zend.exception_ignore_args") {
+		options = errorHandling.DEBUG_BACKTRACE_IGNORE_ARGS
+	}
+	return errorHandling.BacktraceToArray(interpreter.GetBacktrace(), options, 0)
+}
+
+// Format the trace of a Throwable like "#0 file.php(3): f()\n#1 {main}"
+func (interpreter *Interpreter) throwableTraceToString(object *values.Object) string {
+	lines := []string{}
+	if trace, found := object.GetProperty("$trace"); found && trace.GetType() == values.ArrayValue {
+		lines = errorHandling.TraceToLines(trace.(*values.Array), interpreter.ini.GetInt("zend.exception_string_param_max_len"))
+	}
+	lines = append(lines, fmt.Sprintf("#%d {main}", len(lines)))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,459 @@
+package interpreter
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/values"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Resolved PHP callable.
+// Spec: https://www.php.net/manual/en/language.types.callable.php
+type callable struct {
+	name           string
+	nativeFunction runtime.NativeFunction
+	function       *ast.FunctionDefinitionStatement
+	closure        *values.Object
+	object         *values.Object
+	class          *ast.ClassDeclarationStatement
+	method         *ast.MethodDefinitionStatement
+}
+
+// Get the parameters of the callable if they are known (native functions do not declare their parameters)
+func (callable *callable) params() []ast.FunctionParameter {
+	switch {
+	case callable.function != nil:
+		return callable.function.Params
+	case callable.closure != nil:
+		return getClosure(callable.closure).params()
+	case callable.method != nil:
+		return callable.method.Params
+	default:
+		return []ast.FunctionParameter{}
+	}
+}
+
+// Resolve a function name, closure, invokable object or array of object/class and method into a callable
+func (interpreter *Interpreter) resolveCallable(value values.RuntimeValue, env *Environment) (*callable, phpError.Error) {
+	switch value.GetType() {
+	case values.StrValue:
+		name := value.(*values.Str).Value
+		if className, methodName, found := strings.Cut(name, "::"); found {
+			return interpreter.resolveMethodCallable(nil, className, methodName, name)
+		}
+		if nativeFunction, err := env.lookupNativeFunction(name); err == nil {
+			return &callable{name: name, nativeFunction: nativeFunction}, nil
+		}
+		function, err := env.lookupUserFunction(name)
+		if err != nil {
+			return nil, phpError.NewError("Uncaught Error: Call to undefined function %s()", name)
+		}
+		return &callable{name: function.FunctionName, function: function}, nil
+
+	case values.ObjectValue:
+		object := value.(*values.Object)
+		if object.Class.GetQualifiedName() == "Closure" {
+			return &callable{name: "{closure}", closure: object}, nil
+		}
+		method, found := interpreter.getClassMethod(object.Class, "__invoke")
+		if !found {
+			return nil, phpError.NewError("Uncaught Error: Object of type %s is not callable", object.Class.GetQualifiedName())
+		}
+		return &callable{name: object.Class.GetQualifiedName() + "::__invoke", object: object, class: object.Class, method: method}, nil
+
+	case values.ArrayValue:
+		array := value.(*values.Array)
+		if len(array.Keys) != 2 {
+			return nil, phpError.NewError("Uncaught Error: Array callback must have exactly two elements")
+		}
+		target, found := array.GetElement(values.NewInt(0))
+		methodSlot, methodFound := array.GetElement(values.NewInt(1))
+		if !found || !methodFound || methodSlot.GetType() != values.StrValue {
+			return nil, phpError.NewError("Uncaught Error: Array callback must have exactly two elements")
+		}
+		methodName := methodSlot.Value.(*values.Str).Value
+		switch target.GetType() {
+		case values.ObjectValue:
+			object := target.Value.(*values.Object)
+			return interpreter.resolveMethodCallable(object, object.Class.GetQualifiedName(), methodName, object.Class.GetQualifiedName()+"::"+methodName)
+		case values.StrValue:
+			className := target.Value.(*values.Str).Value
+			return interpreter.resolveMethodCallable(nil, className, methodName, className+"::"+methodName)
+		}
+		return nil, phpError.NewError("Uncaught Error: First array member is not a valid class name or object")
+	}
+
+	return nil, phpError.NewError("Uncaught Error: Value not callable")
+}
+
+func (interpreter *Interpreter) resolveMethodCallable(object *values.Object, className string, methodName string, name string) (*callable, phpError.Error) {
+	class, found := interpreter.GetClass(className)
+	if !found {
+		return nil, phpError.NewError(`Uncaught Error: Class "%s" not found`, className)
+	}
+	method, found := interpreter.getClassMethod(class, methodName)
+	if !found {
+		return nil, phpError.NewError("Uncaught Error: Call to undefined method %s::%s()", class.GetQualifiedName(), methodName)
+	}
+	if object == nil && !method.IsStatic() {
+		return nil, phpError.NewError("Uncaught Error: Non-static method %s::%s() cannot be called statically", class.GetQualifiedName(), method.Name)
+	}
+	return &callable{name: name, object: object, class: class, method: method}, nil
+}
+
+// Check if the value is callable without calling it.
+// Returns the name of the callable or the reason why it is not callable (e.g. `function "f" not found or invalid function name`).
+// If syntaxOnly is set, only the structure of the value is checked.
+func (interpreter *Interpreter) IsCallable(value values.RuntimeValue, syntaxOnly bool, env any) (string, string, bool) {
+	switch value.GetType() {
+	case values.StrValue:
+		name := value.(*values.Str).Value
+		if syntaxOnly {
+			return name, "", true
+		}
+		if className, methodName, found := strings.Cut(name, "::"); found {
+			reason, ok := interpreter.isMethodCallable(false, className, methodName)
+			return name, reason, ok
+		}
+		if !env.(*Environment).FunctionExists(name) {
+			return name, fmt.Sprintf(`function "%s" not found or invalid function name`, name), false
+		}
+		return name, "", true
+
+	case values.ObjectValue:
+		object := value.(*values.Object)
+		if object.Class.GetQualifiedName() == "Closure" {
+			return "Closure::__invoke", "", true
+		}
+		if _, found := interpreter.getClassMethod(object.Class, "__invoke"); !found {
+			return object.Class.GetQualifiedName(), "no array or string given", false
+		}
+		return object.Class.GetQualifiedName() + "::__invoke", "", true
+
+	case values.ArrayValue:
+		array := value.(*values.Array)
+		target, found := array.GetElement(values.NewInt(0))
+		methodSlot, methodFound := array.GetElement(values.NewInt(1))
+		if len(array.Keys) != 2 || !found || !methodFound {
+			return "Array", "array callback must have exactly two members", false
+		}
+		var className string
+		switch target.GetType() {
+		case values.ObjectValue:
+			className = target.Value.(*values.Object).Class.GetQualifiedName()
+		case values.StrValue:
+			className = target.Value.(*values.Str).Value
+		default:
+			return "Array", "first array member is not a valid class name or object", false
+		}
+		if methodSlot.GetType() != values.StrValue {
+			return "Array", "second array member is not a valid method", false
+		}
+		methodName := methodSlot.Value.(*values.Str).Value
+		name := className + "::" + methodName
+		if syntaxOnly {
+			return name, "", true
+		}
+		reason, ok := interpreter.isMethodCallable(target.GetType() == values.ObjectValue, className, methodName)
+		return name, reason, ok
+	}
+
+	name, _ := variableHandling.StrVal(value)
+	return name, "no array or string given", false
+}
+
+func (interpreter *Interpreter) isMethodCallable(hasObject bool, className string, methodName string) (string, bool) {
+	class, found := interpreter.GetClass(className)
+	if !found {
+		return fmt.Sprintf(`class "%s" not found`, className), false
+	}
+	method, found := interpreter.getClassMethod(class, methodName)
+	if !found {
+		return fmt.Sprintf(`class %s does not have a method "%s"`, class.GetQualifiedName(), methodName), false
+	}
+	if !hasObject && !method.IsStatic() {
+		return fmt.Sprintf("non-static method %s::%s() cannot be called statically", class.GetQualifiedName(), method.Name), false
+	}
+	return "", true
+}
+
+// Invoke a resolved callable with already evaluated arguments.
+// Arguments for by-reference parameters must be passed as the slot of the referenced variable.
+// Arguments for named parameters can be passed as array with the parameter names as keys (nil if there are none).
+func (interpreter *Interpreter) invokeCallable(callable *callable, args []*values.Slot, namedArgs *values.Array, env *Environment) (*values.Slot, phpError.Error) {
+	switch {
+	case callable.nativeFunction != nil:
+		if namedArgs != nil && len(namedArgs.Keys) > 0 {
+			return values.NewVoidSlot(), phpError.NewError("Uncaught Error: Named arguments are not supported for %s()", callable.name)
+		}
+		runtimeValue, err := interpreter.callNativeFunction(callable.name, callable.nativeFunction, slotValues(args), nil, env, nil)
+		return values.NewSlot(runtimeValue), err
+
+	case callable.function != nil:
+		functionEnv, err := NewEnvironment(env, nil, interpreter)
+		if err != nil {
+			return values.NewVoidSlot(), err
+		}
+		return interpreter.invokeUserFunction(callable.function, args, namedArgs, functionEnv)
+
+	case callable.closure != nil:
+		return interpreter.invokeClosure(callable.closure, args, namedArgs, env)
+
+	default:
+		return interpreter.invokeMethod(callable.object, callable.class, callable.method, args, namedArgs, env)
+	}
+}
+
+// Call a callable with already evaluated arguments (e.g. from native functions or methods)
+func (interpreter *Interpreter) CallFunction(function values.RuntimeValue, args []values.RuntimeValue, env any) (values.RuntimeValue, phpError.Error) {
+	callable, err := interpreter.resolveCallable(function, env.(*Environment))
+	if err != nil {
+		return values.NewNull(), err
+	}
+
+	argSlots := make([]*values.Slot, len(args))
+	for index, arg := range args {
+		argSlots[index] = values.NewSlot(arg)
+	}
+
+	slot, err := interpreter.invokeCallable(callable, argSlots, nil, env.(*Environment))
+	return slot.Value, err
+}
+
+// Call a callable with the given argument slots (e.g. array_walk passing the array elements by reference)
+func (interpreter *Interpreter) CallFunctionWithSlots(function values.RuntimeValue, args []*values.Slot, env any) (values.RuntimeValue, phpError.Error) {
+	callable, err := interpreter.resolveCallable(function, env.(*Environment))
+	if err != nil {
+		return values.NewNull(), err
+	}
+
+	slot, err := interpreter.invokeCallable(callable, args, nil, env.(*Environment))
+	return slot.Value, err
+}
+
+// Call a callable with positional arguments followed by arguments for named parameters (e.g. call_user_func_array with string keys)
+func (interpreter *Interpreter) CallFunctionWithNamedArgs(function values.RuntimeValue, args []values.RuntimeValue, namedArgs *values.Array, env any) (values.RuntimeValue, phpError.Error) {
+	callable, err := interpreter.resolveCallable(function, env.(*Environment))
+	if err != nil {
+		return values.NewNull(), err
+	}
+
+	argSlots := make([]*values.Slot, len(args))
+	for index, arg := range args {
+		argSlots[index] = values.NewSlot(arg)
+	}
+
+	slot, err := interpreter.invokeCallable(callable, argSlots, namedArgs, env.(*Environment))
+	return slot.Value, err
+}
+
+// Call a callable like CallFunctionWithNamedArgs but forward the called class of the given environment (late static binding).
+// The called class is only forwarded to static methods of the called class or one of its parents.
+func (interpreter *Interpreter) ForwardStaticCall(function values.RuntimeValue, args []values.RuntimeValue, namedArgs *values.Array, env any) (values.RuntimeValue, phpError.Error) {
+	callable, err := interpreter.resolveCallable(function, env.(*Environment))
+	if err != nil {
+		return values.NewNull(), err
+	}
+
+	calledClass := env.(*Environment).CalledClass
+	if callable.method != nil && callable.object == nil && calledClass != nil &&
+		interpreter.executionContext.IsInstanceOf(calledClass, callable.class.GetQualifiedName()) {
+		callable.class = calledClass
+	}
+
+	argSlots := make([]*values.Slot, len(args))
+	for index, arg := range args {
+		argSlots[index] = values.NewSlot(arg)
+	}
+
+	slot, err := interpreter.invokeCallable(callable, argSlots, namedArgs, env.(*Environment))
+	return slot.Value, err
+}
+
+// Bind the positional arguments and the arguments for named parameters to the parameters of the called function.
+// Skipped optional parameters get their default value evaluated in the environment of the called function.
+// A variadic parameter collects the remaining positional arguments and the named arguments without a matching parameter.
+// Returns the slot for each parameter.
+func (interpreter *Interpreter) bindParams(functionName string, params []ast.FunctionParameter, args []*values.Slot, namedArgs *values.Array, functionEnv *Environment) ([]*values.Slot, phpError.Error) {
+	paramSlots := make([]*values.Slot, len(params))
+	if namedArgs == nil {
+		namedArgs = values.NewArray()
+	}
+
+	variadicIndex := slices.IndexFunc(params, func(param ast.FunctionParameter) bool { return param.IsVariadic })
+	for _, key := range namedArgs.Keys {
+		name := key.(*values.Str).Value
+		index := slices.IndexFunc(params, func(param ast.FunctionParameter) bool { return param.Name == "$"+name && !param.IsVariadic })
+		if index == -1 && variadicIndex == -1 {
+			return paramSlots, phpError.NewError("Uncaught Error: Unknown named parameter $%s", name)
+		}
+		if index != -1 && index < len(args) {
+			return paramSlots, phpError.NewError("Uncaught Error: Named parameter $%s overwrites previous argument", name)
+		}
+	}
+
+	for index, param := range params {
+		if param.IsVariadic {
+			variadic := values.NewArray()
+			for _, arg := range args[min(index, len(args)):] {
+				variadic.SetElement(nil, values.DeepCopy(arg).Value)
+			}
+			for _, key := range namedArgs.Keys {
+				name := key.(*values.Str).Value
+				if !slices.ContainsFunc(params, func(param ast.FunctionParameter) bool { return param.Name == "$"+name }) {
+					slot, _ := namedArgs.GetElement(key)
+					variadic.SetElement(key, slot.Value)
+				}
+			}
+			paramSlots[index] = values.NewSlot(variadic)
+			continue
+		}
+		if index < len(args) {
+			paramSlots[index] = args[index]
+			continue
+		}
+		if slot, found := namedArgs.GetElement(values.NewStr(param.Name[1:])); found {
+			paramSlots[index] = slot
+			continue
+		}
+		if param.DefaultValue == nil {
+			return paramSlots, phpError.NewError(
+				"Uncaught ArgumentCountError: %s(): Argument #%d (%s) not passed", functionName, index+1, param.Name,
+			)
+		}
+		slot, err := interpreter.processStmt(param.DefaultValue, functionEnv)
+		if err != nil {
+			return paramSlots, err
+		}
+		paramSlots[index] = slot
+	}
+	return paramSlots, nil
+}
+
+// Check if the type of the argument bound to the parameter matches. The elements collected by a variadic parameter are checked individually.
+func checkArgumentType(functionName string, index int, param ast.FunctionParameter, slot *values.Slot) phpError.Error {
+	args := []values.RuntimeValue{slot.Value}
+	if param.IsVariadic {
+		variadic := slot.Value.(*values.Array)
+		args = make([]values.RuntimeValue, len(variadic.Keys))
+		for keyIndex, key := range variadic.Keys {
+			element, _ := variadic.GetElement(key)
+			args[keyIndex] = element.Value
+		}
+	}
+
+	for argIndex, arg := range args {
+		err := checkParameterTypes(arg, param.Type)
+		if err == nil || err.GetMessage() != "Types do not match" {
+			continue
+		}
+		givenType, err := variableHandling.GetType(arg)
+		if err != nil {
+			return err
+		}
+		return phpError.NewError(
+			"Uncaught TypeError: %s(): Argument #%d (%s) must be of type %s, %s given",
+			functionName, index+argIndex+1, param.Name, strings.Join(param.Type, "|"), givenType,
+		)
+	}
+	return nil
+}
+
+// Evaluate the arguments of a call. Arguments for by-reference parameters are passed as slot of the variable.
+func (interpreter *Interpreter) evaluateArguments(args []ast.IExpression, params []ast.FunctionParameter, env *Environment) ([]*values.Slot, phpError.Error) {
+	argSlots := make([]*values.Slot, len(args))
+	for index, arg := range args {
+		if index < len(params) && params[index].ByRef && isReferenceableExpr(arg) {
+			slot, err := interpreter.lookupWritableSlot(arg, env)
+			if err != nil {
+				return argSlots, err
+			}
+			argSlots[index] = slot
+			continue
+		}
+		slot, err := interpreter.processStmt(arg, env)
+		if err != nil {
+			return argSlots, err
+		}
+		argSlots[index] = values.DeepCopy(slot)
+	}
+	return argSlots, nil
+}
+
+// Check if the expression designates a variable, an array element or a property that can be passed by reference
+func isReferenceableExpr(expr ast.IExpression) bool {
+	return expr.GetKind() == ast.SimpleVariableExpr || expr.GetKind() == ast.SubscriptExpr || expr.GetKind() == ast.MemberAccessExpr
+}
+
+// Bind the arguments to the parameters in the given function environment and execute the function body
+func (interpreter *Interpreter) invokeUserFunction(function *ast.FunctionDefinitionStatement, args []*values.Slot, namedArgs *values.Array, functionEnv *Environment) (*values.Slot, phpError.Error) {
+	functionEnv.CurrentFunction = function
+	interpreter.pushFunctionCallFrame(function, args, functionEnv)
+	defer interpreter.popCallFrame()
+
+	requiredParams := len(function.Params)
+	for i := len(function.Params) - 1; i >= 0; i-- {
+		if function.Params[i].DefaultValue != nil || function.Params[i].IsVariadic {
+			requiredParams--
+		} else {
+			break
+		}
+	}
+
+	// Missing arguments of calls with named arguments are reported per parameter
+	if requiredParams > len(args) && (namedArgs == nil || len(namedArgs.Keys) == 0) {
+		if len(function.Params) == requiredParams {
+			return values.NewVoidSlot(), phpError.NewError(
+				"Uncaught ArgumentCountError: %s() expects exactly %d arguments, %d given",
+				function.FunctionName, len(function.Params), len(args),
+			)
+		}
+		return values.NewVoidSlot(), phpError.NewError(
+			"Uncaught ArgumentCountError: %s() expects at least %d arguments, %d given",
+			function.FunctionName, requiredParams, len(args),
+		)
+	}
+	paramSlots, err := interpreter.bindParams(function.FunctionName, function.Params, args, namedArgs, functionEnv)
+	if err != nil {
+		return values.NewVoidSlot(), err
+	}
+	for index, param := range function.Params {
+		slot := paramSlots[index]
+
+		// Check if the parameter types match
+		if err := checkArgumentType(function.FunctionName, index, param, slot); err != nil {
+			return values.NewVoidSlot(), err
+		}
+		// Declare parameter in function environment
+		if param.ByRef && !param.IsVariadic {
+			functionEnv.declareVariableByRef(param.Name, slot)
+		} else {
+			functionEnv.declareVariable(param.Name, values.DeepCopy(slot).Value)
+		}
+	}
+
+	runtimeValue, err := interpreter.processStmt(function.Body, functionEnv)
+	interpreter.releaseFunctionEnv(functionEnv, args, runtimeValue, err)
+	if err != nil && !(err.GetErrorType() == phpError.EventError && err.GetMessage() == phpError.ReturnEvent) {
+		return runtimeValue, err
+	}
+	err = checkParameterTypes(runtimeValue.Value, function.ReturnType)
+	if err != nil && err.GetMessage() == "Types do not match" {
+		givenType, err := variableHandling.GetType(runtimeValue.Value)
+		if runtimeValue.GetType() == values.VoidValue {
+			givenType = "void"
+		}
+		if err != nil {
+			return runtimeValue, err
+		}
+		return runtimeValue, phpError.NewError(
+			"Uncaught TypeError: %s(): Return value must be of type %s, %s given",
+			function.FunctionName, strings.Join(function.ReturnType, "|"), givenType,
+		)
+	}
+	return runtimeValue, nil
+}
@@ -0,0 +1,256 @@
+package interpreter
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/values"
+	"maps"
+	"slices"
+)
+
+// Internal state of a Closure object
+type closure struct {
+	function *ast.FunctionDefinitionStatement
+	// Captured variables by value or by reference (use clause)
+	captured map[string]*values.Slot
+	byRef    map[string]bool
+	// Bound object and class scope
+	this        *values.Object
+	scope       *ast.MethodDefinitionStatement
+	calledClass *ast.ClassDeclarationStatement
+	// Callable wrapped by Closure::fromCallable
+	callable *callable
+}
+
+// Call the function for each slot of the captured variables in the order of their names
+func (closure *closure) forEachCaptured(function func(slot *values.Slot)) {
+	for _, name := range slices.Sorted(maps.Keys(closure.captured)) {
+		function(closure.captured[name])
+	}
+}
+
+// Call the function for each object that is referenced directly by the closure (bound object and wrapped callable)
+func (closure *closure) forEachObject(function func(object *values.Object)) {
+	if closure.this != nil {
+		function(closure.this)
+	}
+	if closure.callable != nil && closure.callable.object != nil {
+		function(closure.callable.object)
+	}
+	if closure.callable != nil && closure.callable.closure != nil {
+		function(closure.callable.closure)
+	}
+}
+
+// The closure holds the captured variables and references the bound object and the wrapped callable
+func (closure *closure) retain() {
+	closure.forEachCaptured(func(slot *values.Slot) { slot.Hold() })
+	closure.forEachObject(func(object *values.Object) { values.Retain(object) })
+}
+
+func (closure *closure) release() {
+	closure.forEachCaptured(func(slot *values.Slot) { slot.Release() })
+	closure.forEachObject(func(object *values.Object) { values.Release(object) })
+}
+
+func getClosure(object *values.Object) *closure { return object.Internal.(*closure) }
+
+func (closure *closure) params() []ast.FunctionParameter {
+	if closure.callable != nil {
+		return closure.callable.params()
+	}
+	return closure.function.Params
+}
+
+func (interpreter *Interpreter) newClosureObject(closure *closure, env *Environment) (*values.Object, phpError.Error) {
+	class, found := interpreter.GetClass("Closure")
+	if !found {
+		return nil, phpError.NewError(`Class "Closure" not found`)
+	}
+	object := values.NewObject(class)
+	object.Internal = closure
+	closure.retain()
+	interpreter.trackObject(object, env)
+	return object, nil
+}
+
+// Create a Closure object for an anonymous function and capture the variables of the defining scope
+func (interpreter *Interpreter) createClosure(expr *ast.AnonymousFunctionCreationExpression, env *Environment) (*values.Object, phpError.Error) {
+	closure := &closure{
+		function:    ast.NewFunctionDefinitionStmt(expr.GetId(), expr.GetPosition(), "{closure}", expr.Params, expr.Body, expr.ReturnType),
+		captured:    map[string]*values.Slot{},
+		byRef:       map[string]bool{},
+		this:        env.CurrentObject,
+		scope:       env.CurrentMethod,
+		calledClass: env.CalledClass,
+	}
+
+	if expr.IsArrowFunction {
+		// Arrow functions capture the entire defining scope by value
+		for name, slot := range env.variables {
+			if name != "$this" {
+				closure.captured[name] = values.DeepCopy(slot)
+			}
+		}
+	}
+
+	for _, use := range expr.Uses {
+		if use.Name == "$this" {
+			return nil, phpError.NewError("Cannot use $this as lexical variable in %s", expr.GetPosString())
+		}
+		if use.ByRef {
+			slot, err := env.LookupVariable(use.Name)
+			if err != nil {
+				if slot, err = env.declareVariable(use.Name, values.NewNull()); err != nil {
+					return nil, err
+				}
+			}
+			closure.captured[use.Name] = slot
+			closure.byRef[use.Name] = true
+			continue
+		}
+		slot, err := env.LookupVariable(use.Name)
+		if err != nil && !interpreter.suppressWarning {
+			interpreter.PrintError(phpError.NewWarning("Undefined variable %s in %s", use.Name, expr.GetPosString()))
+		}
+		closure.captured[use.Name] = values.DeepCopy(slot)
+	}
+
+	return interpreter.newClosureObject(closure, env)
+}
+
+// Invoke a Closure object with already evaluated arguments
+func (interpreter *Interpreter) invokeClosure(object *values.Object, args []*values.Slot, namedArgs *values.Array, env *Environment) (*values.Slot, phpError.Error) {
+	closure := getClosure(object)
+	if closure.callable != nil {
+		return interpreter.invokeCallable(closure.callable, args, namedArgs, env)
+	}
+
+	functionEnv, err := NewEnvironment(env, nil, interpreter)
+	if err != nil {
+		return values.NewVoidSlot(), err
+	}
+	functionEnv.CurrentMethod = closure.scope
+	functionEnv.CalledClass = closure.calledClass
+	if closure.this != nil {
+		functionEnv.CurrentObject = closure.this
+		functionEnv.CalledClass = closure.this.Class
+		functionEnv.declareThis(closure.this)
+	}
+	for name, slot := range closure.captured {
+		if closure.byRef[name] {
+			functionEnv.declareVariableByRef(name, slot)
+		} else {
+			functionEnv.declareVariable(name, slot.Value)
+		}
+	}
+
+	return interpreter.invokeUserFunction(closure.function, args, namedArgs, functionEnv)
+}
+
+// Copy the closure with a new bound object and class scope
+func (interpreter *Interpreter) bindClosure(object *values.Object, newThis values.RuntimeValue, newScope values.RuntimeValue, env *Environment) (values.RuntimeValue, phpError.Error) {
+	bound := *getClosure(object)
+	if bound.callable != nil {
+		return interpreter.newClosureObject(&bound, env)
+	}
+	bound.captured = maps.Clone(bound.captured)
+
+	if newThis.GetType() == values.ObjectValue {
+		bound.this = newThis.(*values.Object)
+	} else {
+		bound.this = nil
+	}
+
+	var scopeClass *ast.ClassDeclarationStatement
+	switch newScope.GetType() {
+	case values.ObjectValue:
+		scopeClass = newScope.(*values.Object).Class
+	case values.StrValue:
+		if className := newScope.(*values.Str).Value; className != "static" {
+			class, found := interpreter.GetClass(className)
+			if !found {
+				return values.NewVoid(), phpError.NewError(`Uncaught Error: Class "%s" not found`, className)
+			}
+			scopeClass = class
+		}
+	}
+	if scopeClass != nil {
+		bound.scope = ast.NewMethodDefinitionStmt(0, nil, "{closure}", []string{"public"}, bound.function.Params, bound.function.Body, bound.function.ReturnType)
+		bound.scope.Class = scopeClass
+		bound.calledClass = scopeClass
+	} else if bound.scope == nil && bound.this != nil {
+		// Without a scope the closure is bound to the class of the new object
+		bound.scope = ast.NewMethodDefinitionStmt(0, nil, "{closure}", []string{"public"}, bound.function.Params, bound.function.Body, bound.function.ReturnType)
+		bound.scope.Class = bound.this.Class
+	}
+
+	return interpreter.newClosureObject(&bound, env)
+}
+
+// -------------------------------------- Closure -------------------------------------- MARK: Closure
+
+func registerClosureClass(interpreter *Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.closure.php
+	runtime.NewNativeClass(interpreter, "Closure").
+		Final().
+		AddStaticMethod("bind", []ast.FunctionParameter{
+			runtime.NewParam("$closure", "Closure"),
+			runtime.NewParam("$newThis", "object", "null"),
+			runtime.NewOptionalParam("$newScope", ast.NewStringLiteralExpr(0, nil, "static", ast.SingleQuotedString), "object", "string", "null"),
+		}, []string{"Closure", "null"}, interpreter.closureBind).
+		AddMethod("bindTo", []ast.FunctionParameter{
+			runtime.NewParam("$newThis", "object", "null"),
+			runtime.NewOptionalParam("$newScope", ast.NewStringLiteralExpr(0, nil, "static", ast.SingleQuotedString), "object", "string", "null"),
+		}, []string{"Closure", "null"}, interpreter.closureBindTo).
+		AddMethod("call", []ast.FunctionParameter{runtime.NewParam("$newThis", "object")}, []string{"mixed"}, interpreter.closureCall).
+		AddStaticMethod("fromCallable", []ast.FunctionParameter{runtime.NewParam("$callback", "callable")}, []string{"Closure"}, interpreter.closureFromCallable).
+		AddMethod("__invoke", []ast.FunctionParameter{}, []string{"mixed"}, interpreter.closureInvoke).
+		Register()
+}
+
+func (interpreter *Interpreter) closureBind(_ *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if len(args) < 2 || args[0].GetType() != values.ObjectValue || args[0].(*values.Object).Class.GetQualifiedName() != "Closure" {
+		return values.NewVoid(), phpError.NewError("Uncaught TypeError: Closure::bind(): Argument #1 ($closure) must be of type Closure")
+	}
+	var newScope values.RuntimeValue = values.NewStr("static")
+	if len(args) > 2 {
+		newScope = args[2]
+	}
+	return interpreter.bindClosure(args[0].(*values.Object), args[1], newScope, context.Env.(*Environment))
+}
+
+func (interpreter *Interpreter) closureBindTo(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	var newScope values.RuntimeValue = values.NewStr("static")
+	if len(args) > 1 {
+		newScope = args[1]
+	}
+	return interpreter.bindClosure(object, args[0], newScope, context.Env.(*Environment))
+}
+
+func (interpreter *Interpreter) closureCall(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if args[0].GetType() != values.ObjectValue {
+		return values.NewVoid(), phpError.NewError("Uncaught TypeError: Closure::call(): Argument #1 ($newThis) must be of type object")
+	}
+	bound, err := interpreter.bindClosure(object, args[0], args[0], context.Env.(*Environment))
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return interpreter.CallFunction(bound, args[1:], context.Env)
+}
+
+func (interpreter *Interpreter) closureFromCallable(_ *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if args[0].GetType() == values.ObjectValue && args[0].(*values.Object).Class.GetQualifiedName() == "Closure" {
+		return args[0], nil
+	}
+	callable, err := interpreter.resolveCallable(args[0], context.Env.(*Environment))
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return interpreter.newClosureObject(&closure{callable: callable}, context.Env.(*Environment))
+}
+
+func (interpreter *Interpreter) closureInvoke(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	return interpreter.CallFunction(object, args, context.Env)
+}
@@ -15,17 +15,22 @@ type Environment struct {
 	parent          *Environment
 	globalVariables []string
 	variables       map[string]*values.Slot
+	variableNames   []string
 	constants       map[string]*values.Slot
 	functions       map[string]*ast.FunctionDefinitionStatement
 	objects         []*values.Object
+	resources       []*values.Resource
 	// StdLib
 	predefinedVariables map[string]*values.Slot
 	predefinedConstants map[string]*values.Slot
 	nativeFunctions     map[string]runtime.NativeFunction
+	nativeFunctionRefs  map[string]runtime.ByRefParams
 	// Context
 	CurrentFunction *ast.FunctionDefinitionStatement
 	CurrentObject   *values.Object
 	CurrentMethod   *ast.MethodDefinitionStatement
+	// Class named in the call of the current method (late static binding)
+	CalledClass *ast.ClassDeclarationStatement
 }
 
 func NewEnvironment(parentEnv *Environment, request *request.Request, interpreter runtime.Interpreter) (*Environment, phpError.Error) {
@@ -35,10 +40,12 @@ func NewEnvironment(parentEnv *Environment, request *request.Request, interprete
 		constants: map[string]*values.Slot{},
 		functions: map[string]*ast.FunctionDefinitionStatement{},
 		objects:   []*values.Object{},
+		resources: []*values.Resource{},
 		// StdLib
 		predefinedVariables: map[string]*values.Slot{},
 		predefinedConstants: map[string]*values.Slot{},
 		nativeFunctions:     map[string]runtime.NativeFunction{},
+		nativeFunctionRefs:  map[string]runtime.ByRefParams{},
 	}
 
 	if parentEnv == nil {
@@ -63,22 +70,46 @@ func (env *Environment) declareVariable(variableName string, value values.Runtim
 	}
 
 	if _, found := env.variables[variableName]; found {
-		env.variables[variableName].Value = value
+		env.variables[variableName].Assign(value)
 	} else {
-		env.variables[variableName] = values.DeepCopy(values.NewSlot(value))
+		slot := values.DeepCopy(values.NewSlot(value))
+		slot.Hold()
+		env.variables[variableName] = slot
+		env.variableNames = append(env.variableNames, variableName)
 	}
 	return env.variables[variableName], nil
 }
 
+func (env *Environment) DeclareVariable(variableName string, value values.RuntimeValue) (*values.Slot, phpError.Error) {
+	return env.declareVariable(variableName, value)
+}
+
+func (env *Environment) DeclareVariableByRef(variableName string, slot *values.Slot) (*values.Slot, phpError.Error) {
+	return env.declareVariableByRef(variableName, slot)
+}
+
 func (env *Environment) declareVariableByRef(variableName string, slot *values.Slot) (*values.Slot, phpError.Error) {
 	if slices.Contains(env.globalVariables, variableName) {
 		return env.parent.declareVariableByRef(variableName, slot)
 	}
 
+	slot.Hold()
+	if previous, found := env.variables[variableName]; !found {
+		env.variableNames = append(env.variableNames, variableName)
+	} else {
+		previous.Release()
+	}
 	env.variables[variableName] = slot
 	return slot, nil
 }
 
+// Bind the object to $this
+func (env *Environment) declareThis(object *values.Object) {
+	slot := values.NewSlot(object)
+	slot.Hold()
+	env.variables["$this"] = slot
+}
+
 func (env *Environment) resolvePredefinedVariable(variableName string) (*Environment, phpError.Error) {
 	if _, ok := env.predefinedVariables[variableName]; ok {
 		return env, nil
@@ -138,7 +169,15 @@ func (env *Environment) unsetVariable(variableName string) {
 	if err != nil {
 		return
 	}
+	slot, found := environment.variables[variableName]
+	if !found {
+		return
+	}
 	delete(environment.variables, variableName)
+	if index := slices.Index(environment.variableNames, variableName); index >= 0 {
+		environment.variableNames = slices.Delete(environment.variableNames, index, index+1)
+	}
+	slot.Release()
 }
 
 func (env *Environment) addGlobalVariable(variableName string) {
@@ -151,20 +190,48 @@ func (env *Environment) addGlobalVariable(variableName string) {
 	env.globalVariables = append(env.globalVariables, variableName)
 }
 
-func (env *Environment) getAllObjects() []*values.Object {
-	objects := []*values.Object{}
-	for _, variable := range env.variables {
-		if variable.GetType() != values.ObjectValue {
-			continue
+// Release the variables in the order of their declaration and $this at the end of a function call
+func (env *Environment) releaseVariables() {
+	variables, names := env.variables, env.variableNames
+	env.variables = map[string]*values.Slot{}
+	env.variableNames = []string{}
+	for _, name := range names {
+		if slot, found := variables[name]; found {
+			delete(variables, name)
+			slot.Release()
 		}
-		objects = append(objects, variable.Value.(*values.Object))
 	}
-	return objects
+	for _, slot := range variables {
+		slot.Release()
+	}
 }
 
 // -------------------------------------- Objects -------------------------------------- MARK: Objects
 
-func (env *Environment) AddObject(object *values.Object) { env.objects = append(env.objects, object) }
+// Objects that are created or returned by the current statement (temporary values).
+// The statement holds a reference to the object until it is finished.
+func (env *Environment) AddObject(object *values.Object) {
+	values.Retain(object)
+	env.objects = append(env.objects, object)
+}
+
+// Resources that are returned by the current statement (temporary values)
+func (env *Environment) AddResource(resource *values.Resource) {
+	values.Retain(resource)
+	env.resources = append(env.resources, resource)
+}
+
+// Release the temporary values of the finished statement
+func (env *Environment) releaseTemporaryValues() {
+	for _, object := range env.objects {
+		values.Release(object)
+	}
+	env.objects = env.objects[:0]
+	for _, resource := range env.resources {
+		values.Release(resource)
+	}
+	env.resources = env.resources[:0]
+}
 
 // -------------------------------------- Constants -------------------------------------- MARK: Constants
 
@@ -225,6 +292,20 @@ func (env *Environment) AddNativeFunction(functionName string, function runtime.
 	env.nativeFunctions[functionName] = function
 }
 
+func (env *Environment) AddNativeFunctionByRef(functionName string, function runtime.NativeFunction, byRefParams runtime.ByRefParams) {
+	env.nativeFunctions[functionName] = function
+	env.nativeFunctionRefs[functionName] = byRefParams
+}
+
+// Remove the native functions listed in the ini directive disable_functions.
+// Like in PHP, disabled functions are undefined and can be declared by the script.
+func (env *Environment) disableNativeFunctions(functionNames []string) {
+	for _, functionName := range functionNames {
+		delete(env.nativeFunctions, functionName)
+		delete(env.nativeFunctionRefs, functionName)
+	}
+}
+
 func (env *Environment) resolveNativeFunction(functionName string) (*Environment, phpError.Error) {
 	if _, ok := env.nativeFunctions[functionName]; ok {
 		return env, nil
@@ -252,6 +333,17 @@ func (env *Environment) lookupNativeFunction(functionName string) (runtime.Nativ
 	return value, nil
 }
 
+// Get the parameters of the native function that are passed by reference
+func (env *Environment) lookupNativeFunctionByRefParams(functionName string) runtime.ByRefParams {
+	functionName = strings.ToLower(functionName)
+
+	environment, err := env.resolveNativeFunction(functionName)
+	if err != nil {
+		return runtime.ByRefParams{}
+	}
+	return environment.nativeFunctionRefs[functionName]
+}
+
 // -------------------------------------- User functions -------------------------------------- MARK: User functions
 
 func (env *Environment) defineUserFunction(function *ast.FunctionDefinitionStatement) phpError.Error {
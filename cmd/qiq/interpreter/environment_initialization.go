@@ -251,6 +251,10 @@ func registerPredefinedConstants(environment *Environment) {
 	environment.AddPredefinedConstant("PHP_INT_MAX", values.NewInt(math.MaxInt64))
 	environment.AddPredefinedConstant("PHP_INT_MIN", values.NewInt(math.MinInt64))
 	environment.AddPredefinedConstant("PHP_INT_SIZE", values.NewInt(64/8))
+	environment.AddPredefinedConstant("PHP_FLOAT_DIG", values.NewInt(15))
+	environment.AddPredefinedConstant("PHP_FLOAT_EPSILON", values.NewFloat(math.Nextafter(1, 2)-1))
+	environment.AddPredefinedConstant("PHP_FLOAT_MAX", values.NewFloat(math.MaxFloat64))
+	environment.AddPredefinedConstant("PHP_FLOAT_MIN", values.NewFloat(2.2250738585072014e-308))
 	environment.AddPredefinedConstant("PHP_OS", values.NewStr(os.Os()))
 	environment.AddPredefinedConstant("PHP_OS_FAMILY", values.NewStr(os.OS_FAMILY))
 	environment.AddPredefinedConstant("PHP_EOL", values.NewStr(os.EOL))
@@ -0,0 +1,86 @@
+package interpreter
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/values"
+	"regexp"
+	"strconv"
+)
+
+// Error levels that can be handled by a user-defined error handler
+const handleableErrorLevels = phpError.E_WARNING | phpError.E_NOTICE | phpError.E_USER_ERROR | phpError.E_USER_WARNING |
+	phpError.E_USER_NOTICE | phpError.E_STRICT | phpError.E_RECOVERABLE_ERROR | phpError.E_DEPRECATED | phpError.E_USER_DEPRECATED
+
+var errorPositionRegex = regexp.MustCompile(`(?s)^(.*) in (.*):(\d+):\d+$`)
+
+// Split the error message into the message, the filename and the line
+func splitErrorMessage(message string) (string, string, int64) {
+	match := errorPositionRegex.FindStringSubmatch(message)
+	if match == nil {
+		return message, "", 0
+	}
+	line, _ := strconv.ParseInt(match[3], 10, 64)
+	return match[1], match[2], line
+}
+
+// Pass the error to the user-defined error handler.
+// Returns true if the error was handled and the standard error handler must not be called.
+func (interpreter *Interpreter) CallErrorHandler(err phpError.Error) bool {
+	level := phpError.GetErrorLevel(err.GetErrorType())
+	message, file, line := splitErrorMessage(err.GetRawMessage())
+
+	handler, found := interpreter.executionContext.GetErrorHandler()
+	if !found || handler.Callback.GetType() == values.NullValue || level&handleableErrorLevels == 0 ||
+		handler.Levels&level == 0 || interpreter.skipErrorHandler {
+		interpreter.executionContext.SetLastError(&runtime.LastError{Type: level, Message: message, File: file, Line: line})
+		return false
+	}
+
+	env := interpreter.currentEnv
+	if env == nil {
+		env = interpreter.env
+	}
+
+	// Errors raised by the error handler itself are passed to the standard error handler
+	interpreter.skipErrorHandler = true
+	result, handlerErr := interpreter.CallFunction(
+		handler.Callback,
+		[]values.RuntimeValue{values.NewInt(level), values.NewStr(message), values.NewStr(file), values.NewInt(line)},
+		env,
+	)
+	interpreter.skipErrorHandler = false
+
+	// The exception thrown by the error handler is raised after the current expression
+	if handlerErr != nil {
+		if interpreter.pendingError == nil {
+			interpreter.pendingError = handlerErr
+		}
+		return true
+	}
+
+	if result.GetType() == values.BoolValue && !result.(*values.Bool).Value {
+		interpreter.executionContext.SetLastError(&runtime.LastError{Type: level, Message: message, File: file, Line: line})
+		return false
+	}
+	return true
+}
+
+// Pass an uncaught exception to the user-defined exception handler.
+// Returns true if an exception handler was called.
+func (interpreter *Interpreter) callExceptionHandler(err phpError.Error) (bool, phpError.Error) {
+	throwErr, ok := err.(*phpError.ThrowError)
+	if !ok {
+		return false, nil
+	}
+
+	handler, found := interpreter.executionContext.GetExceptionHandler()
+	if !found || handler.GetType() == values.NullValue {
+		return false, nil
+	}
+
+	// Exceptions thrown by the exception handler are not handled again
+	interpreter.executionContext.PushExceptionHandler(values.NewNull())
+	_, handlerErr := interpreter.CallFunction(handler, []values.RuntimeValue{throwErr.GetObject().(*values.Object)}, interpreter.env)
+	return true, handlerErr
+}
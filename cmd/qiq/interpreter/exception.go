@@ -0,0 +1,156 @@
+package interpreter
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/position"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/values"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Matches errors of natively implemented code like "Uncaught TypeError: message in file.php:1:2"
+var uncaughtErrorRegex = regexp.MustCompile(`(?s)^Uncaught ([A-Za-z_\\][A-Za-z0-9_\\]*): (.*?)(?: in (.+):(\d+):\d+)?$`)
+
+// Set the file, line and trace of a Throwable to the position of its creation
+func (interpreter *Interpreter) initThrowable(object *values.Object, pos *position.Position) {
+	if !interpreter.executionContext.IsInstanceOf(object.Class, "Throwable") {
+		return
+	}
+	object.SetProperty("$trace", interpreter.getThrowableTrace())
+	if pos == nil || pos.File == nil {
+		return
+	}
+	object.SetProperty("$file", values.NewStr(pos.File.Filename))
+	object.SetProperty("$line", values.NewInt(int64(pos.Line)))
+}
+
+// Format the Throwable and its previous Throwables like PHP's Throwable::__toString
+func (interpreter *Interpreter) throwableToString(object *values.Object) string {
+	result := ""
+	visited := map[*values.Object]bool{}
+	for object != nil && !visited[object] {
+		visited[object] = true
+		message, _ := object.GetProperty("$message")
+		file, _ := object.GetProperty("$file")
+		line, _ := object.GetProperty("$line")
+		messageStr, _ := variableHandling.StrVal(message)
+		fileStr, _ := variableHandling.StrVal(file)
+		lineStr, _ := variableHandling.StrVal(line)
+
+		str := object.Class.GetQualifiedName()
+		if messageStr != "" {
+			str += ": " + messageStr
+		}
+		str += " in " + fileStr + ":" + lineStr + "\nStack trace:\n" + interpreter.throwableTraceToString(object)
+		if result != "" {
+			str += "\n\nNext " + result
+		}
+		result = str
+
+		previous, _ := object.GetProperty("$previous")
+		object, _ = previous.(*values.Object)
+	}
+	return result
+}
+
+// Format an uncaught Throwable like "Uncaught Exception: message in file.php:3\nStack trace:\n#0 {main}\n  thrown in file.php on line 3"
+func (interpreter *Interpreter) uncaughtThrowableToString(object *values.Object) string {
+	file, _ := object.GetProperty("$file")
+	line, _ := object.GetProperty("$line")
+	fileStr, _ := variableHandling.StrVal(file)
+	lineStr, _ := variableHandling.StrVal(line)
+	return "Uncaught " + interpreter.throwableToString(object) + "\n  thrown in " + fileStr + " on line " + lineStr
+}
+
+// Natively implemented methods of the Throwable classes declared in runtime/classes
+func registerThrowableMethods(interpreter *Interpreter) {
+	for _, className := range []string{"Exception", "Error"} {
+		interpreter.executionContext.AddNativeMethod(className, "getTraceAsString", throwableGetTraceAsString)
+		interpreter.executionContext.AddNativeMethod(className, "__toString", throwableToString)
+	}
+}
+
+func throwableGetTraceAsString(object *values.Object, _ []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	return values.NewStr(context.Interpreter.(*Interpreter).throwableTraceToString(object)), nil
+}
+
+func throwableToString(object *values.Object, _ []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	return values.NewStr(context.Interpreter.(*Interpreter).throwableToString(object)), nil
+}
+
+// Create a new Throwable object of the given class
+func (interpreter *Interpreter) newThrowable(className string, message string, pos *position.Position, env *Environment) (*values.Object, phpError.Error) {
+	class, found := interpreter.GetClass(className)
+	if !found || !interpreter.executionContext.IsInstanceOf(class, "Throwable") {
+		return nil, phpError.NewError(`Class "%s" not found`, className)
+	}
+	object := values.NewObject(class)
+	interpreter.trackObject(object, env)
+	args := []ast.IExpression{ast.NewStringLiteralExpr(0, nil, message, ast.SingleQuotedString)}
+	if err := interpreter.initObject(object, args, pos, env); err != nil {
+		interpreter.markDestructed(object)
+		return nil, err
+	}
+	return object, nil
+}
+
+// Create the error that carries a thrown object up to the next matching catch
+func (interpreter *Interpreter) throwObject(object *values.Object) phpError.Error {
+	message, _ := object.GetProperty("$message")
+	file, _ := object.GetProperty("$file")
+	line, _ := object.GetProperty("$line")
+	messageStr, _ := variableHandling.StrVal(message)
+	fileStr, _ := variableHandling.StrVal(file)
+	lineStr, _ := variableHandling.StrVal(line)
+	return phpError.NewThrowError(object, "Uncaught %s: %s in %s:%s", object.Class.GetQualifiedName(), messageStr, fileStr, lineStr)
+}
+
+// Get the Throwable object for the given error.
+// Errors of natively implemented code are converted into an object of the named class.
+func (interpreter *Interpreter) errorToThrowable(err phpError.Error, env *Environment) *values.Object {
+	if throwErr, ok := err.(*phpError.ThrowError); ok {
+		return throwErr.GetObject().(*values.Object)
+	}
+	if err.GetErrorType() != phpError.ErrorPhpError {
+		return nil
+	}
+
+	match := uncaughtErrorRegex.FindStringSubmatch(err.GetRawMessage())
+	if match == nil {
+		return nil
+	}
+	var pos *position.Position
+	if match[3] != "" {
+		line, _ := strconv.Atoi(match[4])
+		pos = position.NewPosition(position.NewFile(match[3]), line, 0)
+	}
+	object, newErr := interpreter.newThrowable(match[1], match[2], pos, env)
+	if newErr != nil {
+		return nil
+	}
+	if codedErr, ok := err.(*phpError.CodedError); ok {
+		object.SetProperty("$code", values.NewInt(codedErr.GetCode()))
+	}
+	return object
+}
+
+// Check if the thrown object matches one of the types of the catch clause
+func (interpreter *Interpreter) catchMatches(catch ast.CatchStatement, object *values.Object, namespace string) bool {
+	for _, errorType := range catch.ErrorType {
+		if strings.HasPrefix(errorType, `\`) {
+			if interpreter.executionContext.IsInstanceOf(object.Class, errorType[1:]) {
+				return true
+			}
+			continue
+		}
+		if interpreter.executionContext.IsInstanceOf(object.Class, errorType) ||
+			(namespace != "" && interpreter.executionContext.IsInstanceOf(object.Class, namespace+errorType)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,271 @@
+package interpreter
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/values"
+	"slices"
+)
+
+type fiberStatus int
+
+const (
+	fiberInit fiberStatus = iota
+	fiberRunning
+	fiberSuspended
+	fiberTerminated
+)
+
+// Value or error passed between a fiber and the code that started or resumed it
+type fiberTransfer struct {
+	value values.RuntimeValue
+	err   phpError.Error
+}
+
+// Internal state of a Fiber object.
+// Each fiber runs in its own goroutine. Only one goroutine executes PHP code at a time:
+// the caller blocks until the fiber suspends or terminates and the fiber blocks until it is resumed.
+type fiber struct {
+	object   *values.Object
+	callback values.RuntimeValue
+	status   fiberStatus
+	// Fiber that was active when this fiber was started or resumed
+	previous *fiber
+	// Environment of the side that waits: the resuming code while running, the fiber while suspended
+	env       *Environment
+	callStack []callFrame
+	resumeCh  chan fiberTransfer
+	suspendCh chan fiberTransfer
+	// Result
+	returnValue values.RuntimeValue
+	threw       bool
+}
+
+func (fiber *fiber) GetReferences() []values.RuntimeValue {
+	return []values.RuntimeValue{fiber.callback, fiber.returnValue}
+}
+
+func getFiber(object *values.Object) (*fiber, phpError.Error) {
+	fiber, ok := object.Internal.(*fiber)
+	if !ok {
+		return nil, phpError.NewError("Uncaught Error: Object of type %s has not been correctly initialized", object.Class.GetQualifiedName())
+	}
+	return fiber, nil
+}
+
+// Switch into the fiber and wait until it suspends or terminates
+func (interpreter *Interpreter) switchToFiber(fiber *fiber, env *Environment, transfer *fiberTransfer, start func()) (values.RuntimeValue, phpError.Error) {
+	fiber.status = fiberRunning
+	fiber.env = env
+	fiber.previous = interpreter.currentFiber
+	interpreter.currentFiber = fiber
+	currentEnv, currentStmt, callStack := interpreter.currentEnv, interpreter.currentStmt, interpreter.callStack
+	// The frames of the fiber are placed on top of the frames of the resuming code
+	interpreter.callStack = append(slices.Clip(callStack), fiber.callStack...)
+
+	if transfer == nil {
+		start()
+	} else {
+		fiber.resumeCh <- *transfer
+	}
+
+	result := <-fiber.suspendCh
+	fiber.callStack = slices.Clone(interpreter.callStack[len(callStack):])
+	interpreter.currentEnv, interpreter.currentStmt, interpreter.callStack = currentEnv, currentStmt, callStack
+	interpreter.currentFiber = fiber.previous
+	fiber.previous = nil
+	if fiber.status == fiberTerminated {
+		fiber.env = nil
+		values.Release(fiber.object)
+	}
+	if result.value == nil {
+		result.value = values.NewNull()
+	}
+	return result.value, result.err
+}
+
+// Unwind all suspended fibers at the end of the script so that their goroutines terminate
+func (interpreter *Interpreter) destroyFibers() {
+	for _, fiber := range interpreter.fibers {
+		if fiber.status != fiberSuspended {
+			continue
+		}
+		interpreter.switchToFiber(fiber, interpreter.env, &fiberTransfer{err: phpError.NewEvent(phpError.ExitEvent)}, nil)
+	}
+	interpreter.fibers = nil
+}
+
+// -------------------------------------- Fiber -------------------------------------- MARK: Fiber
+
+// The methods use the interpreter of the calling context because the fiber state is bound to the interpreter that executes the script
+func registerFiberClass(interpreter *Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.fiber.php
+	runtime.NewNativeClass(interpreter, "Fiber").
+		Final().
+		AddMethod("__construct", []ast.FunctionParameter{runtime.NewParam("$callback", "callable")}, []string{}, fiberConstruct).
+		AddMethod("start", []ast.FunctionParameter{}, []string{"mixed"}, fiberStart).
+		AddMethod("resume", []ast.FunctionParameter{runtime.NewOptionalParam("$value", ast.NewConstantAccessExpr(0, nil, "null"), "mixed")}, []string{"mixed"}, fiberResume).
+		AddMethod("throw", []ast.FunctionParameter{runtime.NewParam("$exception", "Throwable")}, []string{"mixed"}, fiberThrow).
+		AddMethod("getReturn", []ast.FunctionParameter{}, []string{"mixed"}, fiberGetReturn).
+		AddMethod("isStarted", []ast.FunctionParameter{}, []string{"bool"}, fiberIsStarted).
+		AddMethod("isSuspended", []ast.FunctionParameter{}, []string{"bool"}, fiberIsSuspended).
+		AddMethod("isRunning", []ast.FunctionParameter{}, []string{"bool"}, fiberIsRunning).
+		AddMethod("isTerminated", []ast.FunctionParameter{}, []string{"bool"}, fiberIsTerminated).
+		AddStaticMethod("suspend", []ast.FunctionParameter{runtime.NewOptionalParam("$value", ast.NewConstantAccessExpr(0, nil, "null"), "mixed")}, []string{"mixed"}, fiberSuspend).
+		AddStaticMethod("getCurrent", []ast.FunctionParameter{}, []string{"Fiber", "null"}, fiberGetCurrent).
+		Register()
+}
+
+func fiberConstruct(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	interpreter := context.Interpreter.(*Interpreter)
+	if _, err := interpreter.resolveCallable(args[0], context.Env.(*Environment)); err != nil {
+		return values.NewVoid(), phpError.NewError("Uncaught TypeError: Fiber::__construct(): Argument #1 ($callback) must be a valid callback, %s", err.GetRawMessage())
+	}
+	object.Internal = &fiber{
+		object:    object,
+		callback:  args[0],
+		status:    fiberInit,
+		resumeCh:  make(chan fiberTransfer),
+		suspendCh: make(chan fiberTransfer),
+	}
+	values.Retain(args[0])
+	return values.NewVoid(), nil
+}
+
+func fiberStart(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	interpreter := context.Interpreter.(*Interpreter)
+	fiber, err := getFiber(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if fiber.status != fiberInit {
+		return values.NewVoid(), phpError.NewError("Uncaught FiberError: Cannot start a fiber that has already been started")
+	}
+
+	// A started fiber is kept alive until it terminates
+	interpreter.fibers = append(interpreter.fibers, fiber)
+	values.Retain(object)
+	return interpreter.switchToFiber(fiber, context.Env.(*Environment), nil, func() {
+		go func() {
+			value, err := interpreter.CallFunction(fiber.callback, args, context.Env)
+			fiber.status = fiberTerminated
+			values.Store(&fiber.returnValue, value)
+			fiber.threw = err != nil
+			fiber.suspendCh <- fiberTransfer{value: values.NewNull(), err: err}
+		}()
+	})
+}
+
+func fiberResume(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	interpreter := context.Interpreter.(*Interpreter)
+	fiber, err := getFiber(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if fiber.status != fiberSuspended {
+		return values.NewVoid(), phpError.NewError("Uncaught FiberError: Cannot resume a fiber that is not suspended")
+	}
+	var value values.RuntimeValue = values.NewNull()
+	if len(args) > 0 {
+		value = args[0]
+	}
+	return interpreter.switchToFiber(fiber, context.Env.(*Environment), &fiberTransfer{value: value}, nil)
+}
+
+func fiberThrow(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	interpreter := context.Interpreter.(*Interpreter)
+	fiber, err := getFiber(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if args[0].GetType() != values.ObjectValue || !interpreter.executionContext.IsInstanceOf(args[0].(*values.Object).Class, "Throwable") {
+		return values.NewVoid(), phpError.NewError("Uncaught TypeError: Fiber::throw(): Argument #1 ($exception) must be of type Throwable, %s given", values.ToPhpType(args[0]))
+	}
+	if fiber.status != fiberSuspended {
+		return values.NewVoid(), phpError.NewError("Uncaught FiberError: Cannot resume a fiber that is not suspended")
+	}
+	return interpreter.switchToFiber(fiber, context.Env.(*Environment), &fiberTransfer{err: interpreter.throwObject(args[0].(*values.Object))}, nil)
+}
+
+func fiberGetReturn(object *values.Object, _ []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	fiber, err := getFiber(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	switch {
+	case fiber.status == fiberInit:
+		return values.NewVoid(), phpError.NewError("Uncaught FiberError: Cannot get fiber return value: The fiber has not been started")
+	case fiber.status != fiberTerminated:
+		return values.NewVoid(), phpError.NewError("Uncaught FiberError: Cannot get fiber return value: The fiber has not returned")
+	case fiber.threw:
+		return values.NewVoid(), phpError.NewError("Uncaught FiberError: Cannot get fiber return value: The fiber threw an exception")
+	}
+	if fiber.returnValue == nil || fiber.returnValue.GetType() == values.VoidValue {
+		return values.NewNull(), nil
+	}
+	return fiber.returnValue, nil
+}
+
+func fiberIsStarted(object *values.Object, _ []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	fiber, err := getFiber(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewBool(fiber.status != fiberInit), nil
+}
+
+func fiberIsSuspended(object *values.Object, _ []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	fiber, err := getFiber(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewBool(fiber.status == fiberSuspended), nil
+}
+
+func fiberIsRunning(object *values.Object, _ []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	fiber, err := getFiber(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewBool(fiber.status == fiberRunning), nil
+}
+
+func fiberIsTerminated(object *values.Object, _ []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	fiber, err := getFiber(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewBool(fiber.status == fiberTerminated), nil
+}
+
+func fiberSuspend(_ *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	interpreter := context.Interpreter.(*Interpreter)
+	fiber := interpreter.currentFiber
+	if fiber == nil {
+		return values.NewVoid(), phpError.NewError("Uncaught FiberError: Cannot suspend outside of fiber")
+	}
+	var value values.RuntimeValue = values.NewNull()
+	if len(args) > 0 {
+		value = args[0]
+	}
+
+	fiber.status = fiberSuspended
+	fiber.env = context.Env.(*Environment)
+	currentStmt := interpreter.currentStmt
+	fiber.suspendCh <- fiberTransfer{value: value}
+	transfer := <-fiber.resumeCh
+	interpreter.currentEnv, interpreter.currentStmt = context.Env.(*Environment), currentStmt
+	if transfer.value == nil {
+		transfer.value = values.NewNull()
+	}
+	return transfer.value, transfer.err
+}
+
+func fiberGetCurrent(_ *values.Object, _ []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	interpreter := context.Interpreter.(*Interpreter)
+	if interpreter.currentFiber == nil {
+		return values.NewNull(), nil
+	}
+	return interpreter.currentFiber.object, nil
+}
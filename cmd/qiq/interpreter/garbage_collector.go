@@ -0,0 +1,626 @@
+package interpreter
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/values"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Number of possible roots after which the cycle collector runs automatically.
+// As in PHP, the threshold is raised if a run collects only a few objects and lowered again if it collects many.
+const (
+	gcThresholdDefault int64 = 10001
+	gcThresholdStep    int64 = 10000
+	gcThresholdMax     int64 = 1000000000
+	gcThresholdTrigger int64 = 100
+)
+
+// Size of the root buffer as reported by gc_status
+const gcBufferSize int64 = 16384
+
+// Statements after which the temporary values of the statement are released
+var gcCollectionPoints = map[ast.NodeType]bool{
+	ast.ConstDeclarationStmt:  true,
+	ast.DoStmt:                true,
+	ast.EchoStmt:              true,
+	ast.ExpressionStmt:        true,
+	ast.ForeachStmt:           true,
+	ast.ForStmt:               true,
+	ast.GlobalDeclarationStmt: true,
+	ast.IfStmt:                true,
+	ast.TryStmt:               true,
+	ast.WhileStmt:             true,
+}
+
+// The garbage collector frees objects by reference counting (see values.Retain and values.Release).
+// Objects whose reference count drops to zero are destructed and freed in the order in which they are released.
+// Objects whose reference count is decremented to a value greater than zero are buffered as possible roots of
+// garbage cycles. The cycle collector searches the buffered roots for cycles that are only referenced by themselves.
+type garbageCollector struct {
+	// Objects that are not freed yet with the number of their creation
+	objects    map[*values.Object]int64
+	lastObject int64
+	// Objects whose reference count dropped to zero in the order in which they were released
+	unreferenced []*values.Object
+	// Root buffer of the cycle collector
+	possibleRoots  []*values.Object
+	isPossibleRoot map[*values.Object]bool
+	threshold      int64
+	hasDestructor  map[*ast.ClassDeclarationStatement]bool
+	isCollecting   bool
+	isShuttingDown bool
+	// Statistics
+	runs           int64
+	collected      int64
+	startTime      time.Time
+	collectorTime  time.Duration
+	destructorTime time.Duration
+}
+
+func newGarbageCollector() *garbageCollector {
+	return &garbageCollector{
+		objects:        map[*values.Object]int64{},
+		unreferenced:   []*values.Object{},
+		possibleRoots:  []*values.Object{},
+		isPossibleRoot: map[*values.Object]bool{},
+		threshold:      gcThresholdDefault,
+		hasDestructor:  map[*ast.ClassDeclarationStatement]bool{},
+		startTime:      time.Now(),
+	}
+}
+
+func (gc *garbageCollector) Unreferenced(object *values.Object) {
+	gc.unreferenced = append(gc.unreferenced, object)
+}
+
+func (gc *garbageCollector) PossibleRoot(object *values.Object) {
+	if gc.isPossibleRoot[object] {
+		return
+	}
+	gc.isPossibleRoot[object] = true
+	gc.possibleRoots = append(gc.possibleRoots, object)
+}
+
+func (gc *garbageCollector) removePossibleRoot(object *values.Object) {
+	if !gc.isPossibleRoot[object] {
+		return
+	}
+	delete(gc.isPossibleRoot, object)
+	// Freed objects are removed from the buffer lazily
+	if len(gc.possibleRoots) > 2*len(gc.isPossibleRoot)+64 {
+		gc.possibleRoots = slices.DeleteFunc(gc.possibleRoots, func(object *values.Object) bool { return !gc.isPossibleRoot[object] })
+	}
+}
+
+// -------------------------------------- Tracking -------------------------------------- MARK: Tracking
+
+// Register a newly created object as temporary value of the current statement
+func (interpreter *Interpreter) trackObject(object *values.Object, env *Environment) {
+	object.Observer = interpreter.gc
+	env.AddObject(object)
+	interpreter.executionContext.AddObject(object.Class.GetQualifiedName(), object)
+	interpreter.gc.lastObject++
+	interpreter.gc.objects[object] = interpreter.gc.lastObject
+}
+
+func (interpreter *Interpreter) hasDestructor(class *ast.ClassDeclarationStatement) bool {
+	hasDestructor, found := interpreter.gc.hasDestructor[class]
+	if !found {
+		_, hasDestructor = interpreter.getClassMethod(class, "__destruct")
+		interpreter.gc.hasDestructor[class] = hasDestructor
+	}
+	return hasDestructor
+}
+
+// Mark the object as destructed without calling its destructor (e.g. if the constructor failed)
+func (interpreter *Interpreter) markDestructed(object *values.Object) {
+	object.IsDestructed = true
+}
+
+// Protect a value that is used by a running statement from being collected (e.g. the collection of a foreach loop)
+func (interpreter *Interpreter) protectValue(value values.RuntimeValue) { values.Retain(value) }
+
+func (interpreter *Interpreter) unprotectValue(value values.RuntimeValue) { values.Release(value) }
+
+// -------------------------------------- Releasing -------------------------------------- MARK: Releasing
+
+// Release the temporary values of the finished statement and free the objects that are no longer referenced
+func (interpreter *Interpreter) releaseTemporaries(env *Environment) {
+	env.releaseTemporaryValues()
+	interpreter.collectGarbage(env)
+}
+
+// Release the variables of a function environment after the function returned.
+// The objects of the variables are released in the order of the variables.
+// Objects that are returned become temporary values of the calling statement.
+func (interpreter *Interpreter) releaseFunctionEnv(functionEnv *Environment, args []*values.Slot, result *values.Slot, err phpError.Error) {
+	if functionEnv.parent == nil || (err != nil && isExitEvent(err)) {
+		return
+	}
+	var resultValue values.RuntimeValue
+	if result != nil {
+		resultValue = result.Value
+	}
+	interpreter.releaseCall(functionEnv.parent, functionEnv, slotValues(args), resultValue, err)
+}
+
+// Release the temporary objects that were passed to a natively implemented function or method.
+// Objects that are returned by the function and not referenced otherwise become temporary values of the calling statement.
+func (interpreter *Interpreter) releaseNativeCall(env *Environment, args []values.RuntimeValue, result values.RuntimeValue, err phpError.Error) {
+	if env == nil {
+		return
+	}
+	if slices.ContainsFunc(args, func(arg values.RuntimeValue) bool { return isTemporary(arg, env) }) {
+		interpreter.releaseCall(env, nil, args, result, err)
+		return
+	}
+	forEachObject(result, func(object *values.Object) {
+		if object.Observer == nil {
+			interpreter.trackObject(object, env)
+		} else if object.GetRefCount() == 0 && !object.IsReleased {
+			env.AddObject(object)
+		}
+	})
+	forEachResource(result, func(resource *values.Resource) {
+		if resource.GetRefCount() == 0 {
+			env.AddResource(resource)
+		}
+	})
+}
+
+func (interpreter *Interpreter) releaseCall(caller *Environment, functionEnv *Environment, args []values.RuntimeValue, result values.RuntimeValue, err phpError.Error) {
+	// The result and the thrown object are kept alive as temporary values of the calling statement
+	protected := []*values.Object{}
+	forEachObject(result, func(object *values.Object) { protected = append(protected, object) })
+	protectedResources := []*values.Resource{}
+	forEachResource(result, func(resource *values.Resource) {
+		values.Retain(resource)
+		protectedResources = append(protectedResources, resource)
+	})
+	if throwErr, ok := err.(*phpError.ThrowError); ok {
+		protected = append(protected, throwErr.GetObject().(*values.Object))
+	}
+	for _, object := range protected {
+		if object.Observer == nil {
+			object.Observer = interpreter.gc
+			interpreter.gc.lastObject++
+			interpreter.gc.objects[object] = interpreter.gc.lastObject
+		}
+		values.Retain(object)
+	}
+
+	if functionEnv != nil {
+		functionEnv.releaseVariables()
+		functionEnv.releaseTemporaryValues()
+	}
+	// Temporary arguments are released together with the call
+	caller.objects = slices.DeleteFunc(caller.objects, func(object *values.Object) bool {
+		if slices.ContainsFunc(args, func(arg values.RuntimeValue) bool { return arg == object }) {
+			values.Release(object)
+			return true
+		}
+		return false
+	})
+	caller.objects = append(caller.objects, protected...)
+	caller.resources = append(caller.resources, protectedResources...)
+
+	interpreter.collectGarbage(caller)
+}
+
+func isTemporary(value values.RuntimeValue, env *Environment) bool {
+	object, isObject := value.(*values.Object)
+	return isObject && slices.Contains(env.objects, object)
+}
+
+// -------------------------------------- Collection -------------------------------------- MARK: Collection
+
+// Destruct and free the objects whose reference count dropped to zero.
+// The cycle collector runs if the root buffer reached the threshold.
+func (interpreter *Interpreter) collectGarbage(env *Environment) {
+	interpreter.releaseUnreferenced(env)
+	gc := interpreter.gc
+	if gc.isCollecting || !interpreter.ini.GetBool("zend.enable_gc") || int64(len(gc.isPossibleRoot)) < gc.threshold {
+		return
+	}
+	collected := interpreter.collectCycles(env)
+	if collected < gcThresholdTrigger {
+		gc.threshold = min(gc.threshold+gcThresholdStep, gcThresholdMax)
+	} else if gc.threshold > gcThresholdDefault {
+		gc.threshold = max(gc.threshold-gcThresholdStep, gcThresholdDefault)
+	}
+}
+
+// Collect all garbage including reference cycles and return the number of collected objects
+func (interpreter *Interpreter) CollectCycles(env any) int64 {
+	interpreter.releaseUnreferenced(env.(*Environment))
+	if interpreter.gc.isCollecting {
+		return 0
+	}
+	return interpreter.collectCycles(env.(*Environment))
+}
+
+// Destruct and free the unreferenced objects.
+// Objects that are released while an object is destructed or freed are processed before the remaining objects.
+func (interpreter *Interpreter) releaseUnreferenced(env *Environment) {
+	for len(interpreter.gc.unreferenced) > 0 {
+		batch := interpreter.gc.unreferenced
+		interpreter.gc.unreferenced = []*values.Object{}
+		for _, object := range batch {
+			interpreter.releaseObject(object, env)
+		}
+	}
+}
+
+func (interpreter *Interpreter) releaseObject(object *values.Object, env *Environment) {
+	if object.GetRefCount() > 0 || object.IsReleased {
+		return
+	}
+	if !object.IsDestructed && interpreter.hasDestructor(object.Class) {
+		interpreter.destructGarbage(object, env)
+		interpreter.releaseUnreferenced(env)
+		// The destructor stored a new reference to the object
+		if object.GetRefCount() > 0 || object.IsReleased {
+			return
+		}
+	}
+	interpreter.freeObject(object, env)
+}
+
+// Release the properties and internal references of the object
+func (interpreter *Interpreter) freeObject(object *values.Object, env *Environment) {
+	interpreter.markDestructed(object)
+	object.IsReleased = true
+	interpreter.executionContext.ReleaseWeakReferences(object)
+	delete(interpreter.gc.objects, object)
+	interpreter.gc.removePossibleRoot(object)
+
+	forEachProperty(object, func(slot *values.Slot) {
+		slot.Release()
+		interpreter.releaseUnreferenced(env)
+	})
+	if closure, ok := object.Internal.(*closure); ok {
+		closure.release()
+	} else if internal, ok := object.Internal.(values.InternalReferences); ok {
+		for _, value := range internal.GetReferences() {
+			values.Release(value)
+			interpreter.releaseUnreferenced(env)
+		}
+	}
+	interpreter.releaseUnreferenced(env)
+}
+
+func (interpreter *Interpreter) destructGarbage(object *values.Object, env *Environment) {
+	start := time.Now()
+	if err := interpreter.destructObject(object, env); err != nil {
+		interpreter.PrintError(err)
+	}
+	interpreter.gc.destructorTime += time.Since(start)
+}
+
+// -------------------------------------- Cycle collection -------------------------------------- MARK: Cycle collection
+
+type gcColor int
+
+const (
+	gcGray gcColor = iota + 1
+	gcBlack
+	gcWhite
+)
+
+// Synchronous cycle collection (Bacon and Rajan) on the graph of objects, arrays and slots.
+// The references between the nodes that are reachable from the possible roots are subtracted from trial reference counts.
+// Nodes whose trial count stays above zero are referenced from outside and are restored with everything they reference.
+// The remaining nodes are only referenced by garbage cycles.
+type cycleCollector struct {
+	counts map[any]int
+	colors map[any]gcColor
+}
+
+func (interpreter *Interpreter) collectCycles(env *Environment) int64 {
+	gc := interpreter.gc
+	gc.isCollecting = true
+	defer func() { gc.isCollecting = false }()
+	start := time.Now()
+	gc.runs++
+
+	roots := []*values.Object{}
+	for _, object := range gc.possibleRoots {
+		if gc.isPossibleRoot[object] && !object.IsReleased && object.GetRefCount() > 0 {
+			roots = append(roots, object)
+		}
+	}
+	gc.possibleRoots = []*values.Object{}
+	gc.isPossibleRoot = map[*values.Object]bool{}
+
+	// Destructors are called before the garbage is freed.
+	// Objects that are referenced again by a destructor are not freed.
+	garbage := interpreter.findGarbage(roots)
+	hasDestructed := false
+	for _, object := range garbage {
+		if !object.IsDestructed && interpreter.hasDestructor(object.Class) {
+			interpreter.destructGarbage(object, env)
+			hasDestructed = true
+		}
+	}
+	if hasDestructed {
+		garbage = interpreter.findGarbage(slices.DeleteFunc(garbage, func(object *values.Object) bool {
+			return object.IsReleased || object.GetRefCount() == 0
+		}))
+	}
+
+	for _, object := range garbage {
+		if !object.IsReleased {
+			interpreter.freeObject(object, env)
+		}
+	}
+	collected := int64(len(garbage))
+	interpreter.releaseUnreferenced(env)
+	gc.collected += collected
+	gc.collectorTime += time.Since(start)
+	return collected
+}
+
+// Find the objects that are only referenced by garbage cycles in the order of their creation
+func (interpreter *Interpreter) findGarbage(roots []*values.Object) []*values.Object {
+	collector := &cycleCollector{counts: map[any]int{}, colors: map[any]gcColor{}}
+	for _, root := range roots {
+		collector.markGray(root)
+	}
+	for _, root := range roots {
+		collector.scan(root)
+	}
+
+	garbage := []*values.Object{}
+	for node, color := range collector.colors {
+		if object, isObject := node.(*values.Object); isObject && color == gcWhite {
+			garbage = append(garbage, object)
+		}
+	}
+	sort.Slice(garbage, func(i, j int) bool { return interpreter.gc.objects[garbage[i]] < interpreter.gc.objects[garbage[j]] })
+	return garbage
+}
+
+// Subtract the internal references from the trial counts of all nodes that are reachable from the root
+func (collector *cycleCollector) markGray(root any) {
+	if collector.colors[root] == gcGray {
+		return
+	}
+	collector.touch(root)
+	collector.colors[root] = gcGray
+	stack := []any{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		forEachChild(node, func(child any) {
+			collector.touch(child)
+			collector.counts[child]--
+			if collector.colors[child] != gcGray {
+				collector.colors[child] = gcGray
+				stack = append(stack, child)
+			}
+		})
+	}
+}
+
+// Restore the nodes that are referenced from outside and color the others white
+func (collector *cycleCollector) scan(root any) {
+	stack := []any{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if collector.colors[node] != gcGray {
+			continue
+		}
+		if collector.counts[node] > 0 {
+			collector.scanBlack(node)
+			continue
+		}
+		collector.colors[node] = gcWhite
+		forEachChild(node, func(child any) { stack = append(stack, child) })
+	}
+}
+
+func (collector *cycleCollector) scanBlack(root any) {
+	collector.colors[root] = gcBlack
+	stack := []any{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		forEachChild(node, func(child any) {
+			collector.counts[child]++
+			if collector.colors[child] != gcBlack {
+				collector.colors[child] = gcBlack
+				stack = append(stack, child)
+			}
+		})
+	}
+}
+
+// Initialize the trial count of the node with its reference count
+func (collector *cycleCollector) touch(node any) {
+	if _, found := collector.counts[node]; found {
+		return
+	}
+	switch node := node.(type) {
+	case *values.Object:
+		collector.counts[node] = node.GetRefCount()
+	case *values.Array:
+		collector.counts[node] = node.GetHolders()
+	case *values.Slot:
+		collector.counts[node] = node.GetHolders()
+	}
+}
+
+// Call the function for each node that is referenced by the given node.
+// Only references that are counted in the reference count of the child are reported.
+func forEachChild(node any, function func(child any)) {
+	switch node := node.(type) {
+	case *values.Object:
+		if node.IsReleased {
+			return
+		}
+		forEachProperty(node, func(slot *values.Slot) { function(slot) })
+		if closure, ok := node.Internal.(*closure); ok {
+			closure.forEachCaptured(func(slot *values.Slot) { function(slot) })
+			closure.forEachObject(func(object *values.Object) { function(object) })
+		} else if internal, ok := node.Internal.(values.InternalReferences); ok {
+			for _, value := range internal.GetReferences() {
+				forEachNode(value, function)
+			}
+		}
+	case *values.Array:
+		if node.GetHolders() == 0 {
+			return
+		}
+		for _, key := range node.Keys {
+			if slot, found := node.GetElement(key); found {
+				function(slot)
+			}
+		}
+	case *values.Slot:
+		if node.GetHolders() > 0 {
+			forEachNode(node.Value, function)
+		}
+	}
+}
+
+func forEachNode(value values.RuntimeValue, function func(node any)) {
+	switch value := value.(type) {
+	case *values.Object:
+		if value != nil {
+			function(value)
+		}
+	case *values.Array:
+		if value != nil {
+			function(value)
+		}
+	}
+}
+
+// -------------------------------------- Shutdown -------------------------------------- MARK: Shutdown
+
+// Destruct the remaining objects at the end of the script.
+// As in PHP, global variables are released in reverse order as long as they hold the only reference to an object.
+// All other objects are destructed in the order of their creation.
+func (interpreter *Interpreter) destructAllObjects() {
+	gc := interpreter.gc
+	if gc.isShuttingDown {
+		return
+	}
+	gc.isShuttingDown = true
+	defer func() { gc.isShuttingDown = false }()
+
+	env := interpreter.env
+	interpreter.releaseUnreferenced(env)
+	for {
+		count := len(env.variables)
+		names := slices.Clone(env.variableNames)
+		for index := len(names) - 1; index >= 0; index-- {
+			slot, found := env.variables[names[index]]
+			if !found || slot.GetHolders() != 1 {
+				continue
+			}
+			if object, isObject := slot.Value.(*values.Object); isObject && object.GetRefCount() == 1 {
+				env.unsetVariable(names[index])
+				interpreter.releaseUnreferenced(env)
+			}
+		}
+		if count == len(env.variables) {
+			break
+		}
+	}
+
+	objects := slices.Collect(maps.Keys(gc.objects))
+	sort.Slice(objects, func(i, j int) bool { return gc.objects[objects[i]] < gc.objects[objects[j]] })
+	for _, object := range objects {
+		interpreter.destructGarbage(object, env)
+	}
+}
+
+// Release all remaining resources at the end of the script
+func (interpreter *Interpreter) releaseAllResources() {
+	interpreter.executionContext.ReleaseAllResources()
+}
+
+func (interpreter *Interpreter) GetGcStatus() runtime.GcStatus {
+	return runtime.GcStatus{
+		Runs:            interpreter.gc.runs,
+		Collected:       interpreter.gc.collected,
+		Threshold:       interpreter.gc.threshold,
+		Roots:           int64(len(interpreter.gc.isPossibleRoot)),
+		Running:         interpreter.gc.isCollecting,
+		BufferSize:      gcBufferSize,
+		ApplicationTime: time.Since(interpreter.gc.startTime).Seconds(),
+		CollectorTime:   interpreter.gc.collectorTime.Seconds(),
+		DestructorTime:  interpreter.gc.destructorTime.Seconds(),
+	}
+}
+
+// -------------------------------------- Helpers -------------------------------------- MARK: Helpers
+
+// Call the function for each property slot of the object (declared properties in declaration order, then dynamic properties by name)
+func forEachProperty(object *values.Object, function func(slot *values.Slot)) {
+	for _, name := range object.PropertyNames {
+		if slot, found := object.Properties[name]; found {
+			function(slot)
+		}
+	}
+	if len(object.Properties) > len(object.PropertyNames) {
+		dynamicNames := []string{}
+		for name := range object.Properties {
+			if !slices.Contains(object.PropertyNames, name) {
+				dynamicNames = append(dynamicNames, name)
+			}
+		}
+		sort.Strings(dynamicNames)
+		for _, name := range dynamicNames {
+			function(object.Properties[name])
+		}
+	}
+}
+
+// Call the function for the object or each object that is stored in the (nested) array
+func forEachObject(value values.RuntimeValue, function func(object *values.Object)) {
+	forEachNestedValue(value, func(value values.RuntimeValue) {
+		if object, isObject := value.(*values.Object); isObject && object != nil {
+			function(object)
+		}
+	})
+}
+
+// Call the function for the resource or each resource that is stored in the (nested) array
+func forEachResource(value values.RuntimeValue, function func(resource *values.Resource)) {
+	forEachNestedValue(value, func(value values.RuntimeValue) {
+		if resource, isResource := value.(*values.Resource); isResource && resource != nil {
+			function(resource)
+		}
+	})
+}
+
+// Call the function for the value or each value that is stored in the (nested) array
+func forEachNestedValue(value values.RuntimeValue, function func(value values.RuntimeValue)) {
+	visited := map[*values.Array]bool{}
+	var visit func(value values.RuntimeValue)
+	visit = func(value values.RuntimeValue) {
+		array, isArray := value.(*values.Array)
+		if !isArray {
+			function(value)
+			return
+		}
+		if array == nil || visited[array] {
+			return
+		}
+		visited[array] = true
+		for _, key := range array.Keys {
+			if slot, found := array.GetElement(key); found {
+				visit(slot.Value)
+			}
+		}
+	}
+	visit(value)
+}
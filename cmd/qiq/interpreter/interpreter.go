@@ -2,7 +2,6 @@ package interpreter
 
 import (
 	"QIQ/cmd/qiq/ast"
-	"QIQ/cmd/qiq/common"
 	"QIQ/cmd/qiq/config"
 	"QIQ/cmd/qiq/ini"
 	"QIQ/cmd/qiq/parser"
@@ -12,6 +11,8 @@ import (
 	"QIQ/cmd/qiq/runtime/classes"
 	"QIQ/cmd/qiq/runtime/interfaces"
 	"QIQ/cmd/qiq/runtime/outputBuffer"
+	"QIQ/cmd/qiq/runtime/stdlib"
+	"QIQ/cmd/qiq/runtime/stream"
 	"QIQ/cmd/qiq/runtime/values"
 	"QIQ/cmd/qiq/stats"
 	"path/filepath"
@@ -28,12 +29,26 @@ type Interpreter struct {
 	response           *request.Response
 	parser             *parser.Parser
 	env                *Environment
+	currentEnv         *Environment
+	currentStmt        ast.IStatement
+	callStack          []callFrame
 	cache              map[int64]values.RuntimeValue
 	outputBufferStack  *outputBuffer.Stack
 	result             string
 	resultRuntimeValue values.RuntimeValue
 	workingDir         string
+	disabledClasses    map[string]*ast.ClassDeclarationStatement
+	// Garbage collection
+	gc *garbageCollector
+	// Fibers
+	fibers       []*fiber
+	currentFiber *fiber
+	// Error handling
+	pendingError     phpError.Error
+	skipErrorHandler bool // Errors are passed to the standard error handler
 	// Status
+	isRunning       bool
+	isShuttingDown  bool
 	suppressWarning bool
 	exitCalled      bool
 }
@@ -49,6 +64,8 @@ func NewInterpreter(executionContext *runtime.ExecutionContext, ini *ini.Ini, r
 		parser:            parser.NewParser(ini),
 		cache:             map[int64]values.RuntimeValue{},
 		outputBufferStack: outputBuffer.NewStack(),
+		gc:                newGarbageCollector(),
+		disabledClasses:   map[string]*ast.ClassDeclarationStatement{},
 	}
 
 	if filename != "" {
@@ -60,9 +77,19 @@ func NewInterpreter(executionContext *runtime.ExecutionContext, ini *ini.Ini, r
 	if err != nil {
 		return interpreter, err
 	}
+	interpreter.env.disableNativeFunctions(parseDisableList(ini.GetStr("disable_functions")))
+	// Writes to STDOUT are part of the output of the script (after the output buffers)
+	if stdout, found := interpreter.env.predefinedConstants["STDOUT"]; found {
+		stdout.Value.(*values.Resource).Internal.(*stream.Stream).SetPrint(interpreter.WriteResult)
+	}
 
 	interfaces.RegisterDefaultInterfaces(interpreter)
 	classes.RegisterDefaultClasses(interpreter)
+	registerThrowableMethods(interpreter)
+	registerClosureClass(interpreter)
+	registerFiberClass(interpreter)
+	stdlib.RegisterClasses(interpreter)
+	interpreter.disableClasses(parseDisableList(ini.GetStr("disable_classes")))
 
 	if ini.GetBool("register_argc_argv") {
 		server := interpreter.env.predefinedVariables["$_SERVER"].Value.(*values.Array)
@@ -83,6 +110,8 @@ func (interpreter *Interpreter) GetFilename() string { return interpreter.filena
 
 func (interpreter *Interpreter) GetWorkingDir() string { return interpreter.workingDir }
 
+func (interpreter *Interpreter) SetWorkingDir(workingDir string) { interpreter.workingDir = workingDir }
+
 func (interpreter *Interpreter) GetIni() *ini.Ini { return interpreter.ini }
 
 func (interpreter *Interpreter) GetRequest() *request.Request { return interpreter.request }
@@ -129,26 +158,70 @@ func (interpreter *Interpreter) processProgram(program *ast.Program, env *Enviro
 		return values.NewVoidSlot(), err
 	}
 
+	// Included and evaluated code is executed as part of the running script
+	if interpreter.isRunning {
+		return interpreter.processStatements(program.GetStatements(), env)
+	}
+	interpreter.isRunning = true
+
 	defer interpreter.flushOutputBuffers()
+	defer interpreter.releaseAllResources()
+	defer interpreter.destroyFibers()
 
-	slot := values.NewSlot(nil)
-	for _, stmt := range program.GetStatements() {
-		if slot, err = interpreter.processStmt(stmt, env); err != nil {
-			// Handle exit event - Stop code execution
-			if err.GetErrorType() == phpError.EventError && err.GetMessage() == phpError.ExitEvent {
-				break
-			}
+	slot, err := interpreter.processStatements(program.GetStatements(), env)
+	if err != nil && !isExitEvent(err) {
+		handled, handlerErr := interpreter.callExceptionHandler(err)
+		if !handled {
 			return slot, err
 		}
+		if handlerErr != nil && !isExitEvent(handlerErr) {
+			return slot, handlerErr
+		}
 	}
 
 	if !interpreter.exitCalled {
-		interpreter.ProcessExitIntrinsicExpr(ast.NewExitIntrinsic(0, nil, ast.NewIntegerLiteralExpr(0, nil, 0)), interpreter.env)
+		_, err := interpreter.ProcessExitIntrinsicExpr(ast.NewExitIntrinsic(0, nil, ast.NewIntegerLiteralExpr(0, nil, 0)), interpreter.env)
+		if err != nil && !isExitEvent(err.(phpError.Error)) {
+			return slot, err.(phpError.Error)
+		}
 	}
 
 	return slot, nil
 }
 
+func (interpreter *Interpreter) processStatements(statements []ast.IStatement, env *Environment) (*values.Slot, phpError.Error) {
+	slot := values.NewSlot(nil)
+	var err phpError.Error
+	for _, stmt := range statements {
+		if slot, err = interpreter.processStmt(stmt, env); err != nil {
+			return slot, err
+		}
+	}
+	return slot, nil
+}
+
+// Check if the error is an exit event that stops the code execution
+func isExitEvent(err phpError.Error) bool {
+	return err.GetErrorType() == phpError.EventError && err.GetMessage() == phpError.ExitEvent
+}
+
+// Call the functions registered with register_shutdown_function in their order of registration.
+// Shutdown functions registered by a shutdown function are called as well.
+func (interpreter *Interpreter) callShutdownFunctions() phpError.Error {
+	for index := 0; ; index++ {
+		function, found := interpreter.executionContext.GetShutdownFunction(index)
+		if !found {
+			return nil
+		}
+		if _, err := interpreter.CallFunction(function.Callback, function.Args, interpreter.env); err != nil {
+			if isExitEvent(err) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
 func (interperter *Interpreter) ProcessStatement(stmt ast.IStatement, env any) (*values.Slot, phpError.Error) {
 	return interperter.processStmt(stmt, env)
 }
@@ -161,29 +234,33 @@ func (interpreter *Interpreter) processStmt(stmt ast.IStatement, env any) (slot
 		}
 	}()
 
+	// Remember the executed statement and its environment for error handlers and the call stack
+	previousEnv, previousStmt := interpreter.currentEnv, interpreter.currentStmt
+	if environment, ok := env.(*Environment); ok {
+		interpreter.currentEnv = environment
+	}
+	interpreter.currentStmt = stmt
+	defer func() { interpreter.currentEnv, interpreter.currentStmt = previousEnv, previousStmt }()
+
 	ast.PrintInterpreterCallstack(stmt)
 	result, err := stmt.Process(interpreter, env)
 	if err != nil {
 		phpErr = err.(phpError.Error)
 	}
+	// An error handler threw an exception while the statement was processed
+	if phpErr == nil && interpreter.pendingError != nil {
+		phpErr = interpreter.pendingError
+		interpreter.pendingError = nil
+	}
 	if _, ok := result.(*values.Slot); !ok {
 		slot = values.NewVoidSlot()
 		return
 	}
 	slot = result.(*values.Slot)
 
-	// Destruct unused objects
-	if stmt.GetKind() != ast.ObjectCreationExpr {
-		for index := len(env.(*Environment).objects) - 1; index >= 0; index-- {
-			object := env.(*Environment).objects[index]
-			if object.IsUsed {
-				continue
-			}
-			if err := interpreter.destructObject(object, env.(*Environment)); err != nil {
-				interpreter.PrintError(err)
-			}
-			env.(*Environment).objects = common.RemoveIndex(env.(*Environment).objects, index)
-		}
+	// Release the temporary values of the statement
+	if phpErr == nil && gcCollectionPoints[stmt.GetKind()] {
+		interpreter.releaseTemporaries(env.(*Environment))
 	}
 
 	return
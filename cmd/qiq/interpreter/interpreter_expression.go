@@ -5,10 +5,9 @@ import (
 	"QIQ/cmd/qiq/common"
 	"QIQ/cmd/qiq/ini"
 	"QIQ/cmd/qiq/phpError"
-	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/position"
 	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
 	"QIQ/cmd/qiq/runtime/values"
-	"slices"
 	"strings"
 )
 
@@ -74,6 +73,14 @@ func (interpreter *Interpreter) ProcessSimpleAssignmentExpr(expr *ast.SimpleAssi
 	variableName := mustOrVoid(interpreter.varExprToVarName(expr.Variable, env.(*Environment)))
 	currentValue, _ := env.(*Environment).LookupVariable(variableName)
 
+	// Subscript of a property (e.g. "$this->items[] = $value")
+	if root := ast.GetSubscriptRoot(expr.Variable); expr.Variable.GetKind() == ast.SubscriptExpr && root.GetKind() == ast.MemberAccessExpr {
+		currentValue = mustOrVoid(interpreter.lookupPropertySlot(root.(*ast.MemberAccessExpression), env.(*Environment)))
+		if currentValue.GetType() == values.NullValue {
+			currentValue.Assign(values.NewArray())
+		}
+	}
+
 	// SubscriptExpr
 	if currentValue.GetType() == values.StrValue && expr.Variable.GetKind() == ast.SubscriptExpr {
 		if expr.Variable.(*ast.SubscriptExpression).Index == nil {
@@ -114,8 +121,8 @@ func (interpreter *Interpreter) ProcessSimpleAssignmentExpr(expr *ast.SimpleAssi
 		currentValue, _ = env.(*Environment).LookupVariable(variableName)
 	}
 
-	// Array
-	if currentValue.GetType() == values.ArrayValue {
+	// Array (assigning a new value to a variable holding an array replaces the array)
+	if currentValue.GetType() == values.ArrayValue && expr.Variable.GetKind() != ast.SimpleVariableExpr {
 		if expr.Variable.GetKind() != ast.SubscriptExpr {
 			return values.NewVoidSlot(), phpError.NewError("processSimpleAssignmentExpr - Array: Unsupported variable type %s", expr.Variable.GetKind())
 		}
@@ -165,7 +172,26 @@ func (interpreter *Interpreter) ProcessSimpleAssignmentExpr(expr *ast.SimpleAssi
 		return valueSlot, nil
 	}
 
-	if currentValue.GetType() == values.ObjectValue {
+	// Spec: https://phplang.org/spec/10-expressions.html#grammar-subscript-expression
+	// If the usage context is as the left-hand side of a simple-assignment-expression, the object’s method offsetSet is called
+	// with a first argument of expression and a second argument that is the value of the right-hand side of that simple-assignment-expression.
+	if currentValue.GetType() == values.ObjectValue && expr.Variable.GetKind() == ast.SubscriptExpr &&
+		expr.Variable.(*ast.SubscriptExpression).Variable.GetKind() != ast.SubscriptExpr {
+		object := mustOrVoid(interpreter.getArrayAccessObject(currentValue.Value, expr.Variable))
+
+		var key values.RuntimeValue = values.NewNull()
+		if expr.Variable.(*ast.SubscriptExpression).Index != nil {
+			key = must(interpreter.processStmt(expr.Variable.(*ast.SubscriptExpression).Index, env)).Value
+		}
+		valueSlot := values.DeepCopy(must(interpreter.processStmt(expr.Value, env)))
+		if _, err := interpreter.CallMethod(object, "offsetSet", []values.RuntimeValue{key, valueSlot.Value}, env); err != nil {
+			return values.NewVoidSlot(), err
+		}
+		return valueSlot, nil
+	}
+
+	// Assigning a new value to a variable holding an object replaces the object
+	if currentValue.GetType() == values.ObjectValue && expr.Variable.GetKind() != ast.SimpleVariableExpr {
 		if expr.Variable.GetKind() != ast.MemberAccessExpr {
 			return values.NewVoidSlot(), phpError.NewError("processSimpleAssignmentExpr - Object: Unsupported variable type %s", expr.Variable.GetKind())
 		}
@@ -180,6 +206,9 @@ func (interpreter *Interpreter) ProcessSimpleAssignmentExpr(expr *ast.SimpleAssi
 
 		// TODO check if property can be changed (public, protected, private)
 		object := currentValue.Value.(*values.Object)
+		if err := interpreter.checkReadonlyProperty(object, propertyName, "modify", expr.Variable); err != nil {
+			return values.NewVoidSlot(), err
+		}
 		object.SetProperty("$"+propertyName, valueSlot.Value)
 
 		return valueSlot, nil
@@ -190,9 +219,6 @@ func (interpreter *Interpreter) ProcessSimpleAssignmentExpr(expr *ast.SimpleAssi
 	}
 
 	valueSlot := must(interpreter.processStmt(expr.Value, env))
-	if valueSlot.GetType() == values.ObjectValue {
-		valueSlot.Value.(*values.Object).IsUsed = true
-	}
 	return env.(*Environment).declareVariable(variableName, valueSlot.Value)
 }
 
@@ -201,9 +227,16 @@ func (interpreter *Interpreter) ProcessSubscriptExpr(expr *ast.SubscriptExpressi
 	// Spec: https://phplang.org/spec/10-expressions.html#grammar-subscript-expression
 
 	// variableName, variableSlot, err := interpreter.lookupVariable(expr.Variable, env.(*Environment))
-	_, variableSlot, err := interpreter.lookupVariable(expr.Variable, env.(*Environment))
-	if err != nil {
-		return values.NewVoidSlot(), err
+	var variableSlot *values.Slot
+	if root := ast.GetSubscriptRoot(expr); root.GetKind() == ast.MemberAccessExpr {
+		// Subscript of a property (e.g. "$this->items[0]")
+		variableSlot = must(interpreter.processStmt(root, env))
+	} else {
+		var err phpError.Error
+		_, variableSlot, err = interpreter.lookupVariable(expr.Variable, env.(*Environment))
+		if err != nil {
+			return values.NewVoidSlot(), err
+		}
 	}
 
 	if variableSlot.GetType() == values.StrValue {
@@ -292,6 +325,30 @@ func (interpreter *Interpreter) ProcessSubscriptExpr(expr *ast.SubscriptExpressi
 		}
 	}
 
+	// Spec: https://phplang.org/spec/10-expressions.html#grammar-subscript-expression
+	// dereferencable-expression designates an object of a type that implements ArrayAccess
+	if variableSlot.GetType() == values.ObjectValue {
+		if expr.Variable.GetKind() == ast.SubscriptExpr {
+			variableSlot = must(interpreter.processStmt(expr.Variable, env))
+			if variableSlot.GetType() == values.ArrayValue {
+				keyValueSlot := must(interpreter.processStmt(expr.Index, env))
+				if element, found := variableSlot.Value.(*values.Array).GetElement(keyValueSlot.Value); found {
+					return element, nil
+				}
+				return values.NewNullSlot(), nil
+			}
+		}
+		if variableSlot.GetType() == values.ObjectValue {
+			var key values.RuntimeValue = values.NewNull()
+			if expr.Index != nil {
+				key = must(interpreter.processStmt(expr.Index, env)).Value
+			}
+			object := mustOrVoid(interpreter.getArrayAccessObject(variableSlot.Value, expr))
+			runtimeValue, err := interpreter.CallMethod(object, "offsetGet", []values.RuntimeValue{key}, env)
+			return values.NewSlot(runtimeValue), err
+		}
+	}
+
 	return values.NewVoidSlot(), phpError.NewError("Unsupported subscript expression: %s", ast.ToString(expr))
 
 	/*
@@ -366,17 +423,40 @@ func (interpreter *Interpreter) ProcessSubscriptExpr(expr *ast.SubscriptExpressi
 // ProcessFunctionCallExpr implements Visitor.
 func (interpreter *Interpreter) ProcessFunctionCallExpr(expr *ast.FunctionCallExpression, env any) (any, error) {
 	functionNameRuntime := must(interpreter.processStmt(expr.FunctionName, env))
-	functionName := mustOrVoid(variableHandling.StrVal(functionNameRuntime.Value))
+
+	// Closures, invokable objects, array callables and static methods (e.g. `$f(1)`, `[$object, 'method'](1)` or `'C::m'(1)`)
+	if functionNameRuntime.GetType() != values.StrValue || strings.Contains(functionNameRuntime.Value.(*values.Str).Value, "::") {
+		callable, err := interpreter.resolveCallable(functionNameRuntime.Value, env.(*Environment))
+		if err != nil {
+			return values.NewVoidSlot(), phpError.NewError("%s in %s", err.GetRawMessage(), expr.FunctionName.GetPosString())
+		}
+		functionArguments, err := interpreter.evaluateArguments(expr.Arguments, callable.params(), env.(*Environment))
+		if err != nil {
+			return values.NewVoidSlot(), err
+		}
+		return interpreter.invokeCallable(callable, functionArguments, nil, env.(*Environment))
+	}
+
+	functionName := functionNameRuntime.Value.(*values.Str).Value
 
 	// Lookup native function
 	nativeFunction, err := env.(*Environment).lookupNativeFunction(functionName)
 	if err == nil {
+		byRefParams := env.(*Environment).lookupNativeFunctionByRefParams(functionName)
 		functionArguments := make([]values.RuntimeValue, len(expr.Arguments))
+		refArguments := make([]*values.Slot, len(expr.Arguments))
 		for index, arg := range expr.Arguments {
+			// Arguments passed by reference are passed as the value of the variable itself
+			if byRefParams.IsByRef(index) && isReferenceableExpr(arg) {
+				slot := must(interpreter.lookupWritableSlot(arg, env.(*Environment)))
+				functionArguments[index] = slot.Value
+				refArguments[index] = slot
+				continue
+			}
 			slot := must(interpreter.processStmt(arg, env))
 			functionArguments[index] = values.DeepCopy(slot).Value
 		}
-		runtimeValue, err := nativeFunction(functionArguments, runtime.NewContext(interpreter, env.(*Environment), expr))
+		runtimeValue, err := interpreter.callNativeFunction(functionName, nativeFunction, functionArguments, refArguments, env.(*Environment), expr)
 		return values.NewSlot(runtimeValue), err
 	}
 
@@ -390,79 +470,12 @@ func (interpreter *Interpreter) ProcessFunctionCallExpr(expr *ast.FunctionCallEx
 	if err != nil {
 		return values.NewVoidSlot(), err
 	}
-	functionEnv.CurrentFunction = userFunction
-
-	requiredParams := len(userFunction.Params)
-	for i := len(userFunction.Params) - 1; i >= 0; i-- {
-		if userFunction.Params[i].DefaultValue != nil {
-			requiredParams--
-		} else {
-			break
-		}
-	}
-
-	if requiredParams > len(expr.Arguments) {
-		if len(userFunction.Params) == requiredParams {
-			return values.NewVoidSlot(), phpError.NewError(
-				"Uncaught ArgumentCountError: %s() expects exactly %d arguments, %d given",
-				userFunction.FunctionName, len(userFunction.Params), len(expr.Arguments),
-			)
-		}
-		return values.NewVoidSlot(), phpError.NewError(
-			"Uncaught ArgumentCountError: %s() expects at least %d arguments, %d given",
-			userFunction.FunctionName, requiredParams, len(expr.Arguments),
-		)
-	}
-	for index, param := range userFunction.Params {
-		var slot *values.Slot = nil
-		if len(expr.Arguments) > index {
-			slot = must(interpreter.processStmt(expr.Arguments[index], env))
-		} else if param.DefaultValue != nil {
-			slot = must(interpreter.processStmt(param.DefaultValue, env))
-		} else {
-			return values.NewVoidSlot(), phpError.NewError("Too few arguments... in %s", expr.Arguments[index].GetPosString())
-		}
 
-		// Check if the parameter types match
-		err = checkParameterTypes(slot.Value, param.Type)
-		if err != nil && err.GetMessage() == "Types do not match" {
-			givenType, err := variableHandling.GetType(slot.Value)
-			if err != nil {
-				return values.NewVoidSlot(), err
-			}
-			return values.NewVoidSlot(), phpError.NewError(
-				"Uncaught TypeError: %s(): Argument #%d (%s) must be of type %s, %s given",
-				userFunction.FunctionName, index+1, param.Name, strings.Join(param.Type, "|"), givenType,
-			)
-		}
-		// Declare parameter in function environment
-		if param.ByRef {
-			functionEnv.declareVariableByRef(param.Name, slot)
-		} else {
-			functionEnv.declareVariable(param.Name, values.DeepCopy(slot).Value)
-		}
-	}
-
-	runtimeValue, err := interpreter.processStmt(userFunction.Body, functionEnv)
-	interpreter.destructAllObjects(functionEnv)
-	if err != nil && !(err.GetErrorType() == phpError.EventError && err.GetMessage() == phpError.ReturnEvent) {
-		return runtimeValue, err
-	}
-	err = checkParameterTypes(runtimeValue.Value, userFunction.ReturnType)
-	if err != nil && err.GetMessage() == "Types do not match" {
-		givenType, err := variableHandling.GetType(runtimeValue.Value)
-		if runtimeValue.GetType() == values.VoidValue {
-			givenType = "void"
-		}
-		if err != nil {
-			return runtimeValue, err
-		}
-		return runtimeValue, phpError.NewError(
-			"Uncaught TypeError: %s(): Return value must be of type %s, %s given",
-			userFunction.FunctionName, strings.Join(userFunction.ReturnType, "|"), givenType,
-		)
+	functionArguments, err := interpreter.evaluateArguments(expr.Arguments, userFunction.Params, env.(*Environment))
+	if err != nil {
+		return values.NewVoidSlot(), err
 	}
-	return runtimeValue, nil
+	return interpreter.invokeUserFunction(userFunction, functionArguments, nil, functionEnv)
 }
 
 // ProcessEmptyIntrinsicExpr implements Visitor.
@@ -538,10 +551,6 @@ func (interpreter *Interpreter) ProcessExitIntrinsicExpr(expr *ast.ExitIntrinsic
 	//   1. Writes the optional string to STDOUT.
 	//   2. Calls any functions registered via the library function register_shutdown_function in their order of registration.
 
-	// Spec: https://phplang.org/spec/10-expressions.html#grammar-exit-intrinsic
-	// Invokes destructors for all remaining instances
-	interpreter.destructAllObjects(env.(*Environment))
-
 	expression := expr.Arguments[0]
 	if expression != nil {
 		exprValue := must(interpreter.processStmt(expression, env))
@@ -556,8 +565,17 @@ func (interpreter *Interpreter) ProcessExitIntrinsicExpr(expr *ast.ExitIntrinsic
 		}
 	}
 
-	// TODO processExitIntrinsicExpr - call shutdown functions
-	// TODO processExitIntrinsicExpr - call destructors
+	// Calling exit within a shutdown function stops the processing of all further shutdown functions
+	if !interpreter.isShuttingDown {
+		interpreter.isShuttingDown = true
+		if err := interpreter.callShutdownFunctions(); err != nil {
+			return values.NewVoidSlot(), err
+		}
+	}
+
+	// Spec: https://phplang.org/spec/10-expressions.html#grammar-exit-intrinsic
+	// Invokes destructors for all remaining instances
+	interpreter.destructAllObjects()
 
 	interpreter.exitCalled = true
 
@@ -580,6 +598,20 @@ func (interpreter *Interpreter) ProcessIssetIntrinsicExpr(expr *ast.IssetIntrins
 	defer func() { interpreter.suppressWarning = false }()
 
 	for _, arg := range expr.Arguments {
+		if object, isArrayAccess := interpreter.isArrayAccessSubscript(arg, env.(*Environment)); isArrayAccess {
+			var key values.RuntimeValue = values.NewNull()
+			if arg.(*ast.SubscriptExpression).Index != nil {
+				key = must(interpreter.processStmt(arg.(*ast.SubscriptExpression).Index, env)).Value
+			}
+			exists, err := interpreter.CallMethod(object, "offsetExists", []values.RuntimeValue{key}, env)
+			if err != nil {
+				return values.NewVoidSlot(), err
+			}
+			if isset := mustOrVoid(variableHandling.BoolVal(exists)); !isset {
+				return values.NewBoolSlot(false), nil
+			}
+			continue
+		}
 		if arg.GetKind() == ast.SubscriptExpr {
 			runtimeValue, err := interpreter.processStmt(arg, env)
 			if err != nil || runtimeValue.GetType() == values.NullValue {
@@ -604,11 +636,24 @@ func (interpreter *Interpreter) ProcessUnsetIntrinsicExpr(expr *ast.UnsetIntrins
 
 	environment := env.(*Environment)
 	for _, arg := range expr.Arguments {
-		variableName := mustOrVoid(interpreter.varExprToVarName(arg, environment))
-		value, _ := env.(*Environment).LookupVariable(variableName)
-		if value.GetType() == values.ObjectValue {
-			interpreter.destructObject(value.Value.(*values.Object), environment)
+		if object, isArrayAccess := interpreter.isArrayAccessSubscript(arg, environment); isArrayAccess {
+			var key values.RuntimeValue = values.NewNull()
+			if arg.(*ast.SubscriptExpression).Index != nil {
+				key = must(interpreter.processStmt(arg.(*ast.SubscriptExpression).Index, env)).Value
+			}
+			if _, err := interpreter.CallMethod(object, "offsetUnset", []values.RuntimeValue{key}, env); err != nil {
+				return values.NewVoidSlot(), err
+			}
+			continue
 		}
+		if arg.GetKind() == ast.SubscriptExpr || arg.GetKind() == ast.MemberAccessExpr {
+			if err := interpreter.unsetElement(arg, environment); err != nil {
+				return values.NewVoidSlot(), err
+			}
+			continue
+		}
+		// Objects that are no longer referenced are destructed at the end of the statement
+		variableName := mustOrVoid(interpreter.varExprToVarName(arg, environment))
 		environment.unsetVariable(variableName)
 	}
 	return values.NewVoidSlot(), nil
@@ -696,11 +741,16 @@ func (interpreter *Interpreter) ProcessCompoundAssignmentExpr(expr *ast.Compound
 
 	operand1 := must(interpreter.processStmt(expr.Variable, env))
 	operand2 := must(interpreter.processStmt(expr.Value, env))
-	newValue := must(calculate(operand1.Value, expr.Operator, operand2.Value))
-
-	variableName := mustOrVoid(interpreter.varExprToVarName(expr.Variable, env.(*Environment)))
+	result, handled, err := interpreter.overloadedOperator(operand1.Value, expr.Operator, operand2.Value, env.(*Environment), expr)
+	if err != nil {
+		return values.NewVoidSlot(), err
+	}
+	newValue := values.NewSlot(result)
+	if !handled {
+		newValue = must(calculate(operand1.Value, expr.Operator, operand2.Value))
+	}
 
-	return env.(*Environment).declareVariable(variableName, newValue.Value)
+	return newValue, interpreter.writeVariable(expr.Variable, newValue.Value, env.(*Environment))
 }
 
 // ProcessConditionalExpr implements Visitor.
@@ -774,6 +824,9 @@ func (interpreter *Interpreter) ProcessCoalesceExpr(expr *ast.CoalesceExpression
 func (interpreter *Interpreter) ProcessRelationalExpr(expr *ast.RelationalExpression, env any) (any, error) {
 	lhs := must(interpreter.processStmt(expr.Lhs, env))
 	rhs := must(interpreter.processStmt(expr.Rhs, env))
+	if result, handled, err := interpreter.overloadedComparison(lhs.Value, expr.Operator, rhs.Value, env.(*Environment), expr); handled || err != nil {
+		return result, err
+	}
 	return variableHandling.CompareRelation(lhs.Value, expr.Operator, rhs.Value, true)
 }
 
@@ -787,6 +840,11 @@ func (interpreter *Interpreter) ProcessEqualityExpr(expr *ast.EqualityExpression
 	if (expr.Operator == "!=" || expr.Operator == "<>") && interpreter.ini.GetBool("qiq.strict_comparison") {
 		return variableHandling.Compare(lhs.Value, "!==", rhs.Value)
 	}
+	if expr.Operator != "===" && expr.Operator != "!==" {
+		if result, handled, err := interpreter.overloadedComparison(lhs.Value, expr.Operator, rhs.Value, env.(*Environment), expr); handled || err != nil {
+			return result, err
+		}
+	}
 	return variableHandling.Compare(lhs.Value, expr.Operator, rhs.Value)
 }
 
@@ -794,12 +852,29 @@ func (interpreter *Interpreter) ProcessEqualityExpr(expr *ast.EqualityExpression
 func (interpreter *Interpreter) ProcessBinaryOpExpr(expr *ast.BinaryOpExpression, env any) (any, error) {
 	lhs := must(interpreter.processStmt(expr.Lhs, env))
 	rhs := must(interpreter.processStmt(expr.Rhs, env))
+	if result, handled, err := interpreter.overloadedOperator(lhs.Value, expr.Operator, rhs.Value, env.(*Environment), expr); err != nil {
+		return values.NewVoidSlot(), err
+	} else if handled {
+		return values.NewSlot(result), nil
+	}
 	return calculate(lhs.Value, expr.Operator, rhs.Value)
 }
 
 // ProcessUnaryExpr implements Visitor.
 func (interpreter *Interpreter) ProcessUnaryExpr(expr *ast.UnaryOpExpression, env any) (any, error) {
 	operand := must(interpreter.processStmt(expr.Expr, env))
+	// Unary plus and minus are multiplications with 1 and -1 for objects that overload operators
+	if expr.Operator == "+" || expr.Operator == "-" {
+		factor := values.NewInt(1)
+		if expr.Operator == "-" {
+			factor = values.NewInt(-1)
+		}
+		if result, handled, err := interpreter.overloadedOperator(operand.Value, "*", factor, env.(*Environment), expr); err != nil {
+			return values.NewVoidSlot(), err
+		} else if handled {
+			return values.NewSlot(result), nil
+		}
+	}
 	return calculateUnary(expr.Operator, operand.Value)
 }
 
@@ -898,10 +973,7 @@ func (interpreter *Interpreter) ProcessPostfixIncExpr(expr *ast.PostfixIncExpres
 	previous := values.DeepCopy(must(interpreter.processStmt(expr.Expr, env)))
 	newValue := must(calculateIncDec(expr.Operator, previous.Value))
 
-	variableName := mustOrVoid(interpreter.varExprToVarName(expr.Expr, env.(*Environment)))
-	env.(*Environment).declareVariable(variableName, newValue.Value)
-
-	return previous, nil
+	return previous, interpreter.writeVariable(expr.Expr, newValue.Value, env.(*Environment))
 }
 
 // ProcessPrefixIncExpr implements Visitor.
@@ -909,10 +981,7 @@ func (interpreter *Interpreter) ProcessPrefixIncExpr(expr *ast.PrefixIncExpressi
 	previous := must(interpreter.processStmt(expr.Expr, env))
 	newValue := must(calculateIncDec(expr.Operator, previous.Value))
 
-	variableName := mustOrVoid(interpreter.varExprToVarName(expr.Expr, env.(*Environment)))
-	env.(*Environment).declareVariable(variableName, newValue.Value)
-
-	return newValue, nil
+	return newValue, interpreter.writeVariable(expr.Expr, newValue.Value, env.(*Environment))
 }
 
 // ProcessPrintExpr implements Visitor.
@@ -958,7 +1027,7 @@ func (interpreter *Interpreter) ProcessErrorControlExpr(stmt *ast.ErrorControlEx
 	before := interpreter.ini.GetStr("error_reporting")
 	interpreter.ini.Set("error_reporting", "0", ini.INI_ALL)
 
-	runtimeValue, _ := interpreter.processStmt(stmt.Expr, env)
+	runtimeValue, err := interpreter.processStmt(stmt.Expr, env)
 
 	// TODO call custom error-handler
 	// Spec: https://phplang.org/spec/10-expressions.html#error-control-operator
@@ -966,29 +1035,41 @@ func (interpreter *Interpreter) ProcessErrorControlExpr(stmt *ast.ErrorControlEx
 
 	interpreter.ini.Set("error_reporting", before, ini.INI_ALL)
 
-	return runtimeValue, nil
+	// Only diagnostics are suppressed. Thrown exceptions, fatal errors and exit are passed on.
+	return runtimeValue, err
 }
 
 // ProcessObjectCreationExpr implements Visitor.
 func (interpreter *Interpreter) ProcessObjectCreationExpr(stmt *ast.ObjectCreationExpression, env any) (any, error) {
 	// TODO ProcessObjectCreationExpr - Add correct handling of namespaces
-	class, found := interpreter.GetClass(stmt.GetPosition().File.GetNamespaceStr() + stmt.Designator)
+	className := stmt.GetPosition().File.GetNamespaceStr() + stmt.Designator
+	if strings.HasPrefix(stmt.Designator, `\`) {
+		// Fully qualified name
+		className = stmt.Designator
+	}
+	class, found := interpreter.GetClass(className)
 	if !found {
 		return values.NewVoidSlot(), phpError.NewError(`Class "%s" not found.`, stmt.Designator)
 	}
+	if disabledClass, found := interpreter.disabledClasses[strings.ToLower(class.Name)]; found {
+		interpreter.PrintError(phpError.NewWarning("%s() has been disabled for security reasons in %s", class.Name, stmt.GetPosString()))
+		object := values.NewObject(disabledClass)
+		interpreter.trackObject(object, env.(*Environment))
+		return values.NewSlot(object), nil
+	}
 	object := values.NewObject(class)
+	interpreter.trackObject(object, env.(*Environment))
 
-	if err := interpreter.initObject(object, stmt.Args, env); err != nil {
+	if err := interpreter.initObject(object, stmt.Args, stmt.GetPosition(), env); err != nil {
+		// The destructor is not called if the constructor failed
+		interpreter.markDestructed(object)
 		return values.NewVoidSlot(), err
 	}
 
-	env.(*Environment).AddObject(object)
-	interpreter.executionContext.AddObject(class.GetQualifiedName(), object)
-
 	return values.NewSlot(object), nil
 }
 
-func (interpreter *Interpreter) initObject(object *values.Object, constructorArgs []ast.IExpression, env any) phpError.Error {
+func (interpreter *Interpreter) initObject(object *values.Object, constructorArgs []ast.IExpression, pos *position.Position, env any) phpError.Error {
 	initializeProperties := func(properties map[string]*ast.PropertyDeclarationStatement, isParent bool) phpError.Error {
 		// Initialize properties
 		for _, property := range properties {
@@ -1005,8 +1086,9 @@ func (interpreter *Interpreter) initObject(object *values.Object, constructorArg
 
 		// Call constructor
 		if !isParent {
+			interpreter.initThrowable(object, pos)
 			if _, found := interpreter.getClassMethod(object.Class, "__construct"); found {
-				if _, err := interpreter.CallMethod(object, "__construct", constructorArgs, env.(*Environment)); err != nil {
+				if _, err := interpreter.callObjectMethod(object, "__construct", constructorArgs, env.(*Environment)); err != nil {
 					return err
 				}
 			}
@@ -1043,9 +1125,10 @@ func (interpreter *Interpreter) initObject(object *values.Object, constructorArg
 func (interpreter *Interpreter) ProcessMemberAccessExpr(stmt *ast.MemberAccessExpression, env any) (any, error) {
 	if stmt.IsScoped {
 		var class *ast.ClassDeclarationStatement
+		// Calls like "parent::method()", "self::method()" or "static::method()" forward the called class
+		var calledClass *ast.ClassDeclarationStatement
 		if stmt.Object.GetKind() != ast.ConstantAccessExpr {
-			variableName := mustOrVoid(interpreter.varExprToVarName(stmt.Object, env.(*Environment)))
-			runtimeObject, err := env.(*Environment).LookupVariable(variableName)
+			runtimeObject, err := interpreter.processMemberAccessObject(stmt.Object, env.(*Environment))
 			if err != nil {
 				return values.NewVoidSlot(), err
 			}
@@ -1076,10 +1159,28 @@ func (interpreter *Interpreter) ProcessMemberAccessExpr(stmt *ast.MemberAccessEx
 					)
 				}
 				class = classDecl
+				calledClass = env.(*Environment).CalledClass
 			} else if strings.ToLower(constantName) == "self" && env.(*Environment).CurrentMethod != nil {
 				class = env.(*Environment).CurrentMethod.Class
+				calledClass = env.(*Environment).CalledClass
+			} else if strings.ToLower(constantName) == "static" && env.(*Environment).CalledClass != nil {
+				class = env.(*Environment).CalledClass
+				calledClass = class
 			} else {
 				classDecl, found := interpreter.GetClass(constantName)
+				if !found && stmt.Member.GetKind() == ast.ConstantAccessExpr {
+					// Interface constant
+					if _, isInterface := interpreter.executionContext.GetInterface(constantName); isInterface {
+						member := stmt.Member.(*ast.ConstantAccessExpression).ConstantName
+						constant, found := interpreter.executionContext.GetInterfaceConst(constantName, member)
+						if !found {
+							return values.NewVoidSlot(), phpError.NewError(
+								"Uncaught Error: Undefined constant %s::%s in %s", constantName, member, stmt.GetPosString(),
+							)
+						}
+						return interpreter.processStmt(constant.Value, env)
+					}
+				}
 				if !found {
 					return values.NewVoidSlot(), phpError.NewError(
 						`Uncaught Error: "%s" is not a class in %s`,
@@ -1094,7 +1195,11 @@ func (interpreter *Interpreter) ProcessMemberAccessExpr(stmt *ast.MemberAccessEx
 		if stmt.Member.GetKind() == ast.ConstantAccessExpr {
 			member := stmt.Member.(*ast.ConstantAccessExpression).ConstantName
 
-			constant, found := class.GetConst(member)
+			if object, found := interpreter.executionContext.GetEnumCase(class.GetQualifiedName(), member); found {
+				return values.NewSlot(object), nil
+			}
+
+			constant, found := interpreter.executionContext.GetClassConst(class, member)
 			if !found {
 				return values.NewVoidSlot(), phpError.NewError(
 					"Uncaught Error: Undefined constant %s::%s in %s",
@@ -1145,20 +1250,25 @@ func (interpreter *Interpreter) ProcessMemberAccessExpr(stmt *ast.MemberAccessEx
 				}
 			}
 
-			if !slices.Contains([]string{"__callStatic" /*"__construct"*/}, strings.ToLower(functionName)) && !methodDecl.IsStatic() {
+			// Calls like "parent::method()" or "self::method()" inside of an object context are bound to the current object
+			var object *values.Object
+			if !methodDecl.IsStatic() && env.(*Environment).CurrentObject != nil &&
+				interpreter.executionContext.IsInstanceOf(env.(*Environment).CurrentObject.Class, class.GetQualifiedName()) {
+				object = env.(*Environment).CurrentObject
+			}
+
+			if strings.ToLower(functionName) != "__callstatic" && !methodDecl.IsStatic() && object == nil {
 				return values.NewVoidSlot(), phpError.NewError(
 					"Uncaught Error: Non-static method %s::%s() cannot be called statically in %s",
 					class.GetQualifiedName(), functionName, functionCall.FunctionName.GetPosString(),
 				)
 			}
 
-			// var object *values.Object
-			// if slices.Contains([]string{"__callStatic", "__construct"}, strings.ToLower(functionName)) && env.(*Environment).CurrentObject != nil {
-			// 	object = env.(*Environment).CurrentObject
-			// }
-			// TODO ProcessMemberAccessExpr: Fix so that parent constructor can be called
+			if object != nil {
+				return interpreter.callMethod(object, class, nil, functionName, arguments, env.(*Environment))
+			}
 
-			result, err := interpreter.CallStaticMethod(class, functionName, arguments, env.(*Environment))
+			result, err := interpreter.CallStaticMethod(class, calledClass, functionName, arguments, env.(*Environment))
 			if err != nil {
 				return values.NewVoidSlot(), err
 			}
@@ -1167,8 +1277,7 @@ func (interpreter *Interpreter) ProcessMemberAccessExpr(stmt *ast.MemberAccessEx
 
 		return values.NewVoidSlot(), phpError.NewError("ProcessMemberAccessExpr - scoped: Unsupported member type %s in %s", stmt.Member.GetKind(), stmt.Member.GetPosString())
 	} else {
-		variableName := mustOrVoid(interpreter.varExprToVarName(stmt.Object, env.(*Environment)))
-		runtimeObject, err := env.(*Environment).LookupVariable(variableName)
+		runtimeObject, err := interpreter.processMemberAccessObject(stmt.Object, env.(*Environment))
 		if err != nil {
 			return values.NewVoidSlot(), err
 		}
@@ -1239,7 +1348,7 @@ func (interpreter *Interpreter) ProcessMemberAccessExpr(stmt *ast.MemberAccessEx
 					)
 				}
 			}
-			result, err := interpreter.CallMethod(object, functionName, arguments, env.(*Environment))
+			result, err := interpreter.callObjectMethod(object, functionName, arguments, env.(*Environment))
 			if err != nil {
 				return values.NewVoidSlot(), err
 			}
@@ -1250,7 +1359,26 @@ func (interpreter *Interpreter) ProcessMemberAccessExpr(stmt *ast.MemberAccessEx
 	}
 }
 
+// Evaluate the object of a member access expression, e.g. "$obj" in "$obj->member" or "getObj()" in "getObj()->member"
+func (interpreter *Interpreter) processMemberAccessObject(expr ast.IExpression, env *Environment) (*values.Slot, phpError.Error) {
+	if expr.GetKind() != ast.SimpleVariableExpr {
+		return interpreter.processStmt(expr, env)
+	}
+
+	variableName, err := interpreter.varExprToVarName(expr, env)
+	if err != nil {
+		return values.NewVoidSlot(), err
+	}
+	return env.LookupVariable(variableName)
+}
+
 // ProcessAnonymousFunctionCreationExpr implements Visitor.
-func (interpreter *Interpreter) ProcessAnonymousFunctionCreationExpr(stmt *ast.AnonymousFunctionCreationExpression, _ any) (any, error) {
-	return values.NewVoidSlot(), phpError.NewError("ProcessAnonymousFunctionCreationExpr not implemented")
+func (interpreter *Interpreter) ProcessAnonymousFunctionCreationExpr(stmt *ast.AnonymousFunctionCreationExpression, env any) (any, error) {
+	// Spec: https://phplang.org/spec/10-expressions.html#anonymous-function-creation
+	// This operator returns an object of type Closure, or a derived type thereof, that encapsulates the anonymous function defined within.
+	closure, err := interpreter.createClosure(stmt, env.(*Environment))
+	if err != nil {
+		return values.NewVoidSlot(), err
+	}
+	return values.NewSlot(closure), nil
 }
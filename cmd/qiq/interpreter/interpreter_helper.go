@@ -6,8 +6,11 @@ import (
 	"QIQ/cmd/qiq/common/os"
 	"QIQ/cmd/qiq/phpError"
 	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/stdlib/array"
+	phpMath "QIQ/cmd/qiq/runtime/stdlib/math"
 	"QIQ/cmd/qiq/runtime/stdlib/outputControl"
 	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/stream"
 	"QIQ/cmd/qiq/runtime/values"
 	"math"
 	GoOs "os"
@@ -22,10 +25,14 @@ import (
 // -------------------------------------- Common -------------------------------------- MARK: Common
 
 func (interpreter *Interpreter) Print(str string) {
-	if interpreter.outputBufferStack.Len() > 0 {
-		interpreter.outputBufferStack.Get(interpreter.outputBufferStack.Len() - 1).Content += str
-	} else {
+	if interpreter.outputBufferStack.Len() == 0 {
 		interpreter.WriteResult(str)
+		return
+	}
+
+	// The exception thrown by an output handler is raised after the current expression
+	if err := outputControl.WriteOutput(str, interpreter.outputContext()); err != nil && interpreter.pendingError == nil {
+		interpreter.pendingError = err
 	}
 }
 
@@ -33,13 +40,22 @@ func (interpreter *Interpreter) Println(str string) { interpreter.Print(str + os
 
 func (interpreter *Interpreter) WriteResult(str string) { interpreter.result += str }
 
+// Context in which output handlers are called
+func (interpreter *Interpreter) outputContext() runtime.Context {
+	env := interpreter.currentEnv
+	if env == nil {
+		env = interpreter.env
+	}
+	return runtime.NewContext(interpreter, env, interpreter.currentStmt)
+}
+
 func (interpreter *Interpreter) flushOutputBuffers() {
 	if interpreter.outputBufferStack.Len() == 0 {
 		return
 	}
 
-	for interpreter.outputBufferStack.Len() > 0 {
-		outputControl.ObEndFlush(runtime.NewContext(interpreter, nil, nil))
+	if err := outputControl.EndAll(interpreter.outputContext()); err != nil {
+		interpreter.WriteResult("\n" + interpreter.ErrorToString(err))
 	}
 }
 
@@ -111,16 +127,20 @@ func (interpreter *Interpreter) varExprToVarName(expr ast.IExpression, env *Envi
 }
 
 func (interpreter *Interpreter) ErrorToString(err phpError.Error) string {
-	if (err.GetErrorType() == phpError.WarningPhpError && interpreter.ini.GetInt("error_reporting")&phpError.E_WARNING == 0) ||
-		(err.GetErrorType() == phpError.ErrorPhpError && interpreter.ini.GetInt("error_reporting")&phpError.E_ERROR == 0) ||
-		(err.GetErrorType() == phpError.NoticePhpError && interpreter.ini.GetInt("error_reporting")&phpError.E_NOTICE == 0) ||
-		(err.GetErrorType() == phpError.ParsePhpError && interpreter.ini.GetInt("error_reporting")&phpError.E_PARSE == 0) {
+	if level := phpError.GetErrorLevel(err.GetErrorType()); level != 0 && interpreter.ini.GetInt("error_reporting")&level == 0 {
 		return ""
 	}
+	if throwErr, ok := err.(*phpError.ThrowError); ok {
+		return "Fatal error: " + interpreter.uncaughtThrowableToString(throwErr.GetObject().(*values.Object))
+	}
 	return err.GetMessage()
 }
 
 func (interpreter *Interpreter) PrintError(err phpError.Error) {
+	if interpreter.CallErrorHandler(err) {
+		return
+	}
+
 	if errStr := interpreter.ErrorToString(err); errStr == "" {
 		return
 	} else {
@@ -181,6 +201,30 @@ func checkParameterTypes(runtimeValue values.RuntimeValue, expectedTypes []strin
 	return phpError.NewError("Types do not match")
 }
 
+// Split the value of the ini directives disable_functions and disable_classes (e.g. "exec, system" => ["exec", "system"])
+func parseDisableList(value string) []string {
+	names := strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	for i, name := range names {
+		names[i] = strings.ToLower(name)
+	}
+	return names
+}
+
+// Disable the classes listed in the ini directive disable_classes. Like in PHP, a disabled class
+// has no methods and creating an instance prints a warning. The shared declaration is not modified.
+func (interpreter *Interpreter) disableClasses(classNames []string) {
+	for _, className := range classNames {
+		class, found := interpreter.GetClass(className)
+		if !found {
+			continue
+		}
+		disabledClass := *class
+		disabledClass.MethodNames = []string{}
+		disabledClass.Methods = map[string]*ast.MethodDefinitionStatement{}
+		interpreter.disabledClasses[className] = &disabledClass
+	}
+}
+
 func (interpreter *Interpreter) includeFile(filepathExpr ast.IExpression, env *Environment, include bool, once bool) (*values.Slot, phpError.Error) {
 	slot, err := interpreter.processStmt(filepathExpr, env)
 	if err != nil {
@@ -234,6 +278,14 @@ func (interpreter *Interpreter) includeFile(filepathExpr ast.IExpression, env *E
 		}
 	}
 
+	if !stream.CheckOpenBasedir(functionName, absFilename, runtime.NewContext(interpreter, env, filepathExpr)) {
+		interpreter.PrintError(phpError.NewWarning(
+			"%s(%s): Failed to open stream: Operation not permitted in %s",
+			functionName, filename, filepathExpr.GetPosString(),
+		))
+		return getError()
+	}
+
 	if !common.PathExists(absFilename) {
 		interpreter.PrintError(phpError.NewWarning(
 			"%s(%s): Failed to open stream: No such file or directory in %s",
@@ -433,13 +485,12 @@ func (interpreter *Interpreter) destructObject(object *values.Object, env *Envir
 	if object.IsDestructed {
 		return nil
 	}
-	_, found := interpreter.getClassMethod(object.Class, "__destruct")
-	if found {
-		_, err := interpreter.CallMethod(object, "__destruct", []ast.IExpression{}, env)
-		if err != nil {
+	// Objects without destructor are also marked so that weak references to them are cleared
+	interpreter.markDestructed(object)
+	if _, found := interpreter.getClassMethod(object.Class, "__destruct"); found {
+		if _, err := interpreter.callObjectMethod(object, "__destruct", []ast.IExpression{}, env); err != nil {
 			return err
 		}
-		object.IsDestructed = true
 	}
 	return nil
 }
@@ -459,79 +510,131 @@ func (interpreter *Interpreter) getClassMethod(class *ast.ClassDeclarationStatem
 	return nil, false
 }
 
-func (interpreter *Interpreter) destructAllObjects(env *Environment) {
-	objects := env.getAllObjects()
-	for _, object := range objects {
-		if err := interpreter.destructObject(object, env); err != nil {
-			interpreter.PrintError(err)
-		}
-	}
+// Call a static method. The called class is the class used for late static binding (static::) and defaults to the given class.
+func (interpreter *Interpreter) CallStaticMethod(class *ast.ClassDeclarationStatement, calledClass *ast.ClassDeclarationStatement, method string, args []ast.IExpression, env *Environment) (*values.Slot, phpError.Error) {
+	return interpreter.callMethod(nil, class, calledClass, method, args, env)
 }
 
-func (interpreter *Interpreter) CallStaticMethod(class *ast.ClassDeclarationStatement, method string, args []ast.IExpression, env *Environment) (*values.Slot, phpError.Error) {
-	return interpreter.callMethod(nil, class, method, args, env)
+func (interpreter *Interpreter) callObjectMethod(object *values.Object, method string, args []ast.IExpression, env *Environment) (*values.Slot, phpError.Error) {
+	return interpreter.callMethod(object, nil, nil, method, args, env)
 }
 
-func (interpreter *Interpreter) CallMethod(object *values.Object, method string, args []ast.IExpression, env *Environment) (*values.Slot, phpError.Error) {
-	return interpreter.callMethod(object, nil, method, args, env)
+// Call a method of the given object with already evaluated arguments (e.g. from native functions or methods)
+func (interpreter *Interpreter) CallMethod(object *values.Object, method string, args []values.RuntimeValue, env any) (values.RuntimeValue, phpError.Error) {
+	methodDefinition, found := interpreter.getClassMethod(object.Class, method)
+	if !found {
+		return values.NewNull(), phpError.NewError(
+			"Uncaught Error: Call to undefined method %s::%s()", object.Class.GetQualifiedName(), method,
+		)
+	}
+
+	argSlots := make([]*values.Slot, len(args))
+	for index, arg := range args {
+		argSlots[index] = values.NewSlot(arg)
+	}
+
+	slot, err := interpreter.invokeMethod(object, object.Class, methodDefinition, argSlots, nil, env.(*Environment))
+	return slot.Value, err
 }
 
-func (interpreter *Interpreter) callMethod(object *values.Object, class *ast.ClassDeclarationStatement, method string, args []ast.IExpression, env *Environment) (*values.Slot, phpError.Error) {
-	if object != nil {
+func (interpreter *Interpreter) callMethod(object *values.Object, class *ast.ClassDeclarationStatement, calledClass *ast.ClassDeclarationStatement, method string, args []ast.IExpression, env *Environment) (*values.Slot, phpError.Error) {
+	if class == nil {
 		class = object.Class
 	}
+	if calledClass == nil {
+		calledClass = class
+	}
 	methodDefinition, found := interpreter.getClassMethod(class, method)
 	if !found {
 		return values.NewNullSlot(), phpError.NewError(`Class %s does not have a function "%s"`, class.Name, method)
 	}
-	if object == nil && !methodDefinition.IsStatic() && !slices.Contains([]string{"__callStatic" /*"__construct"*/}, strings.ToLower(method)) {
+	if object == nil && !methodDefinition.IsStatic() && strings.ToLower(method) != "__callstatic" {
 		return values.NewNullSlot(), phpError.NewError(`%s::%s is not a static function`, class.Name, method)
 	}
 
+	argSlots := make([]*values.Slot, len(args))
+	for index, arg := range args {
+		slot := must(interpreter.processStmt(arg, env))
+		if index < len(methodDefinition.Params) && methodDefinition.Params[index].ByRef {
+			argSlots[index] = slot
+		} else {
+			argSlots[index] = values.DeepCopy(slot)
+		}
+	}
+
+	return interpreter.invokeMethod(object, calledClass, methodDefinition, argSlots, nil, env)
+}
+
+// Invoke a method with already evaluated arguments.
+// The called class is bound to "static" inside of the method. Calls with an object are bound to the class of the object.
+func (interpreter *Interpreter) invokeMethod(object *values.Object, calledClass *ast.ClassDeclarationStatement, methodDefinition *ast.MethodDefinitionStatement, args []*values.Slot, namedArgs *values.Array, env *Environment) (*values.Slot, phpError.Error) {
+	hasNamedArgs := namedArgs != nil && len(namedArgs.Keys) > 0
+	if methodDefinition.GetRequiredParamLen() > len(args) && !hasNamedArgs {
+		return values.NewVoidSlot(), phpError.NewError(
+			"Uncaught ArgumentCountError: %s::%s() expects exactly %d arguments, %d given",
+			methodDefinition.Class.GetQualifiedName(), methodDefinition.Name, len(methodDefinition.Params), len(args),
+		)
+	}
+
+	// Natively implemented method
+	if nativeMethod, found := interpreter.executionContext.GetNativeMethod(methodDefinition.Class.GetQualifiedName(), methodDefinition.Name); found {
+		if hasNamedArgs {
+			return values.NewVoidSlot(), phpError.NewError(
+				"Uncaught Error: Named arguments are not supported for %s::%s()", methodDefinition.Class.GetQualifiedName(), methodDefinition.Name,
+			)
+		}
+		argValues := slotValues(args)
+		interpreter.pushMethodCallFrame(object, methodDefinition, args, true)
+		runtimeValue, err := nativeMethod(object, argValues, runtime.NewContext(interpreter, env, methodDefinition))
+		interpreter.popCallFrame()
+		interpreter.releaseNativeCall(env, argValues, runtimeValue, err)
+		return values.NewSlot(runtimeValue), err
+	}
+
+	if methodDefinition.IsAbstract() {
+		return values.NewVoidSlot(), phpError.NewError(
+			"Uncaught Error: Cannot call abstract method %s::%s()", methodDefinition.Class.GetQualifiedName(), methodDefinition.Name,
+		)
+	}
+
+	interpreter.pushMethodCallFrame(object, methodDefinition, args, false)
+	defer interpreter.popCallFrame()
+
 	methodEnv, err := NewEnvironment(env, nil, interpreter)
 	if err != nil {
 		return values.NewVoidSlot(), err
 	}
 	methodEnv.CurrentMethod = methodDefinition
+	methodEnv.CalledClass = calledClass
 	methodEnv.AddConstant("self", values.NewNull())
 	methodEnv.AddConstant("parent", values.NewNull())
 	if object != nil {
 		methodEnv.CurrentObject = object
-		methodEnv.variables["$this"] = values.NewSlot(object)
+		methodEnv.CalledClass = object.Class
+		methodEnv.declareThis(object)
 	}
 
-	if methodDefinition.GetRequiredParamLen() > len(args) {
-		return values.NewVoidSlot(), phpError.NewError(
-			"Uncaught ArgumentCountError: %s::%s() expects exactly %d arguments, %d given",
-			class.BaseClass, methodDefinition.Name, len(methodDefinition.Params), len(args),
-		)
+	methodName := methodDefinition.Class.GetQualifiedName() + "::" + methodDefinition.Name
+	paramSlots, err := interpreter.bindParams(methodName, methodDefinition.Params, args, namedArgs, methodEnv)
+	if err != nil {
+		return values.NewVoidSlot(), err
 	}
-
 	for index, param := range methodDefinition.Params {
-		var slot *values.Slot
-		if index+1 > len(args) {
-			slot = must(interpreter.processStmt(param.DefaultValue, env))
-		} else {
-			slot = must(interpreter.processStmt(args[index], env))
-		}
+		slot := paramSlots[index]
 
 		// Check if the parameter types match
-		err = checkParameterTypes(slot.Value, param.Type)
-		if err != nil && err.GetMessage() == "Types do not match" {
-			givenType, err := variableHandling.GetType(slot.Value)
-			if err != nil {
-				return values.NewVoidSlot(), err
-			}
-			return values.NewVoidSlot(), phpError.NewError(
-				"Uncaught TypeError: %s::%s(): Argument #%d (%s) must be of type %s, %s given",
-				class.BaseClass, methodDefinition.Name, index+1, param.Name, strings.Join(param.Type, "|"), givenType,
-			)
+		if err := checkArgumentType(methodName, index, param, slot); err != nil {
+			return values.NewVoidSlot(), err
 		}
 		// Declare parameter in method environment
-		methodEnv.declareVariable(param.Name, values.DeepCopy(slot).Value)
+		if param.ByRef && !param.IsVariadic {
+			methodEnv.declareVariableByRef(param.Name, slot)
+		} else {
+			methodEnv.declareVariable(param.Name, slot.Value)
+		}
 	}
-
 	slot, err := interpreter.processStmt(methodDefinition.Body, methodEnv)
+	interpreter.releaseFunctionEnv(methodEnv, args, slot, err)
 	if err != nil && !(err.GetErrorType() == phpError.EventError && err.GetMessage() == phpError.ReturnEvent) {
 		return slot, err
 	}
@@ -546,13 +649,224 @@ func (interpreter *Interpreter) callMethod(object *values.Object, class *ast.Cla
 		}
 		return slot, phpError.NewError(
 			"Uncaught TypeError: %s::%s(): Return value must be of type %s, %s given",
-			class.BaseClass, methodDefinition.Name, strings.Join(methodDefinition.ReturnType, "|"), givenType,
+			methodDefinition.Class.GetQualifiedName(), methodDefinition.Name, strings.Join(methodDefinition.ReturnType, "|"), givenType,
 		)
 	}
 
 	return slot, nil
 }
 
+// Write the given value into the variable, array element or property designated by the expression
+func (interpreter *Interpreter) writeVariable(expr ast.IExpression, value values.RuntimeValue, env *Environment) phpError.Error {
+	if object, isArrayAccess := interpreter.isArrayAccessSubscript(expr, env); isArrayAccess {
+		var key values.RuntimeValue = values.NewNull()
+		if expr.(*ast.SubscriptExpression).Index != nil {
+			keySlot, err := interpreter.processStmt(expr.(*ast.SubscriptExpression).Index, env)
+			if err != nil {
+				return err
+			}
+			key = keySlot.Value
+		}
+		_, err := interpreter.CallMethod(object, "offsetSet", []values.RuntimeValue{key, value}, env)
+		return err
+	}
+
+	if expr.GetKind() == ast.SimpleVariableExpr {
+		variableName, err := interpreter.varExprToVarName(expr, env)
+		if err != nil {
+			return err
+		}
+		_, err = env.declareVariable(variableName, value)
+		return err
+	}
+
+	slot, err := interpreter.lookupWritableSlot(expr, env)
+	if err != nil {
+		return err
+	}
+	slot.Assign(value)
+	return nil
+}
+
+// Get the slot designated by the expression. Not existing variables, array elements and properties are created.
+func (interpreter *Interpreter) lookupWritableSlot(expr ast.IExpression, env *Environment) (*values.Slot, phpError.Error) {
+	switch expr.GetKind() {
+	case ast.SimpleVariableExpr:
+		variableName, err := interpreter.varExprToVarName(expr, env)
+		if err != nil {
+			return values.NewVoidSlot(), err
+		}
+		if slot, err := env.LookupVariable(variableName); err == nil {
+			return slot, nil
+		}
+		return env.declareVariable(variableName, values.NewNull())
+
+	case ast.MemberAccessExpr:
+		return interpreter.lookupPropertySlot(expr.(*ast.MemberAccessExpression), env)
+
+	case ast.SubscriptExpr:
+		subscriptExpr := expr.(*ast.SubscriptExpression)
+		containerSlot, err := interpreter.lookupWritableSlot(subscriptExpr.Variable, env)
+		if err != nil {
+			return values.NewVoidSlot(), err
+		}
+		if containerSlot.GetType() == values.NullValue {
+			containerSlot.Assign(values.NewArray())
+		}
+		if containerSlot.GetType() != values.ArrayValue {
+			return values.NewVoidSlot(), phpError.NewError("Cannot use a scalar value as an array in %s", expr.GetPosString())
+		}
+		array := containerSlot.Value.(*values.Array)
+
+		var key values.RuntimeValue
+		if subscriptExpr.Index != nil {
+			keySlot, err := interpreter.processStmt(subscriptExpr.Index, env)
+			if err != nil {
+				return values.NewVoidSlot(), err
+			}
+			key = keySlot.Value
+			if slot, found := array.GetElement(key); found {
+				return slot, nil
+			}
+		}
+		if err := array.SetElement(key, values.NewNull()); err != nil {
+			return values.NewVoidSlot(), err
+		}
+		if key == nil {
+			key = array.Keys[len(array.Keys)-1]
+		}
+		slot, _ := array.GetElement(key)
+		return slot, nil
+
+	default:
+		return values.NewVoidSlot(), phpError.NewError("lookupWritableSlot: Unsupported expression %s in %s", expr.GetKind(), expr.GetPosString())
+	}
+}
+
+// Get the slot of an object property (e.g. "$this->items"). Not existing properties are created.
+func (interpreter *Interpreter) lookupPropertySlot(expr *ast.MemberAccessExpression, env *Environment) (*values.Slot, phpError.Error) {
+	if expr.IsScoped || expr.Member.GetKind() != ast.ConstantAccessExpr {
+		return values.NewVoidSlot(), phpError.NewError("lookupPropertySlot: Unsupported member expression %s in %s", expr.Member.GetKind(), expr.GetPosString())
+	}
+	objectSlot, err := interpreter.processStmt(expr.Object, env)
+	if err != nil {
+		return values.NewVoidSlot(), err
+	}
+	propertyName := expr.Member.(*ast.ConstantAccessExpression).ConstantName
+	if objectSlot.GetType() != values.ObjectValue {
+		return values.NewVoidSlot(), phpError.NewError(
+			`Uncaught Error: Attempt to assign property "%s" on %s in %s`,
+			propertyName, values.ToPhpType(objectSlot.Value), expr.GetPosString(),
+		)
+	}
+	object := objectSlot.Value.(*values.Object)
+	if err := interpreter.checkReadonlyProperty(object, propertyName, "modify", expr); err != nil {
+		return values.NewVoidSlot(), err
+	}
+	slot, found := object.GetPropertySlot("$" + propertyName)
+	if !found {
+		object.SetProperty("$"+propertyName, values.NewNull())
+		slot, _ = object.GetPropertySlot("$" + propertyName)
+	}
+	return slot, nil
+}
+
+// Check that the property is not readonly before it is modified or unset ("modify" or "unset")
+func (interpreter *Interpreter) checkReadonlyProperty(object *values.Object, propertyName string, action string, expr ast.IExpression) phpError.Error {
+	class := object.Class
+	for class != nil {
+		if property, found := class.Properties["$"+propertyName]; found {
+			if property.IsReadonly {
+				return phpError.NewError("Uncaught Error: Cannot %s readonly property %s::$%s in %s", action, class.GetQualifiedName(), propertyName, expr.GetPosString())
+			}
+			return nil
+		}
+		if class.BaseClass == "" {
+			return nil
+		}
+		class, _ = interpreter.executionContext.GetClass(class.BaseClass)
+	}
+	return nil
+}
+
+// Unset the array element or property designated by the expression
+func (interpreter *Interpreter) unsetElement(expr ast.IExpression, env *Environment) phpError.Error {
+	var container ast.IExpression
+	if expr.GetKind() == ast.SubscriptExpr {
+		container = expr.(*ast.SubscriptExpression).Variable
+	} else {
+		container = expr.(*ast.MemberAccessExpression).Object
+	}
+
+	// Unsetting an element of a non-existent container is ignored
+	interpreter.suppressWarning = true
+	containerSlot, err := interpreter.processStmt(container, env)
+	interpreter.suppressWarning = false
+	if err != nil {
+		return nil
+	}
+
+	if expr.GetKind() == ast.MemberAccessExpr {
+		memberExpr := expr.(*ast.MemberAccessExpression)
+		if memberExpr.IsScoped || memberExpr.Member.GetKind() != ast.ConstantAccessExpr || containerSlot.GetType() != values.ObjectValue {
+			return nil
+		}
+		object := containerSlot.Value.(*values.Object)
+		propertyName := memberExpr.Member.(*ast.ConstantAccessExpression).ConstantName
+		if err := interpreter.checkReadonlyProperty(object, propertyName, "unset", expr); err != nil {
+			return err
+		}
+		object.DeleteProperty("$" + propertyName)
+		return nil
+	}
+
+	if containerSlot.GetType() != values.ArrayValue {
+		return nil
+	}
+	subscriptExpr := expr.(*ast.SubscriptExpression)
+	if subscriptExpr.Index == nil {
+		return phpError.NewError("Cannot use [] for unsetting in %s", expr.GetPosString())
+	}
+	containerSlot, err = interpreter.lookupWritableSlot(container, env)
+	if err != nil {
+		return err
+	}
+	key, err := interpreter.processStmt(subscriptExpr.Index, env)
+	if err != nil {
+		return err
+	}
+	if containerSlot.Value.(*values.Array).Contains(key.Value) {
+		return array.RemoveByKey(containerSlot.Value.(*values.Array), key.Value)
+	}
+	return nil
+}
+
+// -------------------------------------- ArrayAccess -------------------------------------- MARK: ArrayAccess
+
+// Get the object of a subscript expression if it implements the interface ArrayAccess
+func (interpreter *Interpreter) getArrayAccessObject(runtimeValue values.RuntimeValue, expr ast.IExpression) (*values.Object, phpError.Error) {
+	object := runtimeValue.(*values.Object)
+	if !interpreter.executionContext.IsInstanceOf(object.Class, "ArrayAccess") {
+		return object, phpError.NewError("Uncaught Error: Cannot use object of type %s as array in %s", object.Class.GetQualifiedName(), expr.GetPosString())
+	}
+	return object, nil
+}
+
+// Check if the given expression is a subscript expression on an object implementing the interface ArrayAccess (e.g. "$object[$key]")
+func (interpreter *Interpreter) isArrayAccessSubscript(expr ast.IExpression, env *Environment) (*values.Object, bool) {
+	if expr.GetKind() != ast.SubscriptExpr || expr.(*ast.SubscriptExpression).Variable.GetKind() == ast.SubscriptExpr {
+		return nil, false
+	}
+	interpreter.suppressWarning = true
+	slot, err := interpreter.processStmt(expr.(*ast.SubscriptExpression).Variable, env)
+	interpreter.suppressWarning = false
+	if err != nil || slot.GetType() != values.ObjectValue {
+		return nil, false
+	}
+	object, err := interpreter.getArrayAccessObject(slot.Value, expr)
+	return object, err == nil
+}
+
 // -------------------------------------- Caching -------------------------------------- MARK: Caching
 
 func (interpreter *Interpreter) isCached(stmt ast.IStatement) bool {
@@ -616,6 +930,11 @@ func (interpreter *Interpreter) exprToRuntimeValue(expr ast.IExpression, env *En
 				if err != nil {
 					return values.NewVoidSlot(), err
 				}
+				// Warnings are reported by this interpreter with the position of the string
+				interp.skipErrorHandler = true
+				interp.callStack = slices.Clip(interpreter.callStack)
+				// The expression is evaluated as part of the running script
+				interp.isRunning = true
 				result, err := interp.process(exprStr, env, true)
 				if err != nil {
 					return values.NewVoidSlot(), err
@@ -882,7 +1201,7 @@ func calculateUnaryInteger(operator string, operand *values.Int) (*values.Slot,
 		// For a unary - operator used with an arithmetic operand, the value of the result is the negated value of the operand.
 		// However, if an int operand’s original value is the smallest representable for that type,
 		// the operand is treated as if it were float and the result will be float.
-		return values.NewIntSlot(-operand.Value), nil
+		return values.NewSlot(phpMath.SubInt(0, operand.Value)), nil
 
 	case "~":
 		// Spec: https://phplang.org/spec/10-expressions.html#unary-arithmetic-operators
@@ -897,6 +1216,45 @@ func calculateUnaryInteger(operator string, operand *values.Int) (*values.Slot,
 
 // -------------------------------------- binary-op-calculation -------------------------------------- MARK: binary-op-calculation
 
+// Call the operator handler if one of the operands is an object of a native class that overloads operators (e.g. BcMath\Number).
+// The handler of the left operand takes precedence.
+func (interpreter *Interpreter) overloadedOperator(lhs values.RuntimeValue, operator string, rhs values.RuntimeValue, env *Environment, stmt ast.IStatement) (values.RuntimeValue, bool, phpError.Error) {
+	handler, found := interpreter.executionContext.GetOperatorHandler(lhs)
+	if !found {
+		if handler, found = interpreter.executionContext.GetOperatorHandler(rhs); !found {
+			return nil, false, nil
+		}
+	}
+	return handler(lhs, operator, rhs, runtime.NewContext(interpreter, env, stmt))
+}
+
+// Compare the operands with the operator handler of an object that overloads operators
+func (interpreter *Interpreter) overloadedComparison(lhs values.RuntimeValue, operator string, rhs values.RuntimeValue, env *Environment, stmt ast.IStatement) (*values.Slot, bool, phpError.Error) {
+	result, handled, err := interpreter.overloadedOperator(lhs, "<=>", rhs, env, stmt)
+	if err != nil || !handled {
+		return values.NewVoidSlot(), handled, err
+	}
+	comparison := result.(*values.Int).Value
+	switch operator {
+	case "<=>":
+		return values.NewIntSlot(comparison), true, nil
+	case "<":
+		return values.NewBoolSlot(comparison < 0), true, nil
+	case "<=":
+		return values.NewBoolSlot(comparison <= 0), true, nil
+	case ">":
+		return values.NewBoolSlot(comparison > 0), true, nil
+	case ">=":
+		return values.NewBoolSlot(comparison >= 0), true, nil
+	case "==":
+		return values.NewBoolSlot(comparison == 0), true, nil
+	case "!=", "<>":
+		return values.NewBoolSlot(comparison != 0), true, nil
+	default:
+		return values.NewVoidSlot(), false, nil
+	}
+}
+
 func calculate(operand1 values.RuntimeValue, operator string, operand2 values.RuntimeValue) (*values.Slot, phpError.Error) {
 	resultType := values.VoidValue
 	if slices.Contains([]string{"."}, operator) {
@@ -986,24 +1344,24 @@ func calculateInteger(operand1 *values.Int, operator string, operand2 *values.In
 	case "&":
 		return values.NewIntSlot(operand1.Value & operand2.Value), nil
 	case "+":
-		return values.NewIntSlot(operand1.Value + operand2.Value), nil
+		return values.NewSlot(phpMath.AddInt(operand1.Value, operand2.Value)), nil
 	case "-":
-		return values.NewIntSlot(operand1.Value - operand2.Value), nil
+		return values.NewSlot(phpMath.SubInt(operand1.Value, operand2.Value)), nil
 	case "*":
-		return values.NewIntSlot(operand1.Value * operand2.Value), nil
+		return values.NewSlot(phpMath.MulInt(operand1.Value, operand2.Value)), nil
 	case "/":
 		if operand2.Value == 0 {
 			// TODO Add position in output: Fatal error: Uncaught DivisionByZeroError: Division by zero in /home/user/scripts/code.php:3
 			return values.NewIntSlot(0), phpError.NewError("Uncaught DivisionByZeroError: Division by zero")
 		}
-		return values.NewIntSlot(operand1.Value / operand2.Value), nil
+		return values.NewSlot(phpMath.DivInt(operand1.Value, operand2.Value)), nil
 	case "%":
 		if operand2.Value == 0 {
 			return values.NewVoidSlot(), phpError.NewError("Division by zero")
 		}
 		return values.NewIntSlot(operand1.Value % operand2.Value), nil
 	case "**":
-		return values.NewIntSlot(int64(math.Pow(float64(operand1.Value), float64(operand2.Value)))), nil
+		return values.NewSlot(phpMath.PowInt(operand1.Value, operand2.Value)), nil
 	default:
 		return values.NewVoidSlot(), phpError.NewError(`calculateInteger: Operator "%s" not implemented`, operator)
 	}
@@ -353,7 +353,16 @@ func (interpreter *Interpreter) ProcessGlobalDeclarationStmt(stmt *ast.GlobalDec
 
 // ProcessThrowStmt implements Visitor.
 func (interpreter *Interpreter) ProcessThrowStmt(stmt *ast.ThrowStatement, env any) (any, error) {
-	return values.NewVoidSlot(), phpError.NewError("ProcessThrowStmt is not implemented")
+	// Spec: https://www.php.net/manual/en/language.exceptions.php
+	slot := must(interpreter.processStmt(stmt.Expr, env))
+	if slot.GetType() != values.ObjectValue {
+		return values.NewVoidSlot(), phpError.NewError("Uncaught Error: Can only throw objects in %s", stmt.GetPosString())
+	}
+	object := slot.Value.(*values.Object)
+	if !interpreter.executionContext.IsInstanceOf(object.Class, "Throwable") {
+		return values.NewVoidSlot(), phpError.NewError("Uncaught Error: Cannot throw objects that do not implement Throwable in %s", stmt.GetPosString())
+	}
+	return values.NewVoidSlot(), interpreter.throwObject(object)
 }
 
 // ProcessDeclareStmt implements Visitor.
@@ -377,6 +386,10 @@ func (interpreter *Interpreter) ProcessForeachStmt(stmt *ast.ForeachStatement, e
 		return values.NewVoidSlot(), err
 	}
 
+	// The collection is in use until the loop is finished
+	interpreter.protectValue(runtimeValue.Value)
+	defer interpreter.unprotectValue(runtimeValue.Value)
+
 	environment := env.(*Environment)
 
 	// Array
@@ -423,8 +436,12 @@ func (interpreter *Interpreter) ProcessForeachStmt(stmt *ast.ForeachStatement, e
 	if runtimeValue.GetType() == values.ObjectValue {
 		runtimeObject := runtimeValue.Value.(*values.Object)
 
+		if interpreter.executionContext.IsInstanceOf(runtimeObject.Class, "Traversable") {
+			return interpreter.processForeachIterator(stmt, runtimeObject, environment)
+		}
+
 		for _, propertyName := range runtimeObject.PropertyNames {
-			if runtimeObject.Class.Properties[propertyName].Visibility != "public" {
+			if property, found := runtimeObject.Class.Properties[propertyName]; found && property.Visibility != "public" {
 				continue
 			}
 
@@ -481,31 +498,101 @@ func (interpreter *Interpreter) ProcessForeachStmt(stmt *ast.ForeachStatement, e
 	return values.NewVoidSlot(), nil
 }
 
+func (interpreter *Interpreter) processForeachIterator(stmt *ast.ForeachStatement, iterator *values.Object, env *Environment) (*values.Slot, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/class.iteratoraggregate.php
+	for interpreter.executionContext.IsInstanceOf(iterator.Class, "IteratorAggregate") {
+		runtimeValue, err := interpreter.CallMethod(iterator, "getIterator", []values.RuntimeValue{}, env)
+		if err != nil {
+			return values.NewVoidSlot(), err
+		}
+		if runtimeValue.GetType() != values.ObjectValue || !interpreter.executionContext.IsInstanceOf(runtimeValue.(*values.Object).Class, "Traversable") {
+			return values.NewVoidSlot(), phpError.NewError(
+				"Uncaught TypeError: %s::getIterator(): Return value must be of type Traversable, %s returned in %s",
+				iterator.Class.GetQualifiedName(), values.ToPhpType(runtimeValue), stmt.Collection.GetPosString(),
+			)
+		}
+		iterator = runtimeValue.(*values.Object)
+	}
+
+	if stmt.ByRef {
+		return values.NewVoidSlot(), phpError.NewError("Uncaught Error: An iterator cannot be used with foreach by reference in %s", stmt.Collection.GetPosString())
+	}
+
+	callIteratorMethod := func(method string) values.RuntimeValue {
+		return mustOrVoid(interpreter.CallMethod(iterator, method, []values.RuntimeValue{}, env))
+	}
+
+	// Spec: https://www.php.net/manual/en/class.iterator.php
+	callIteratorMethod("rewind")
+	for {
+		if valid := mustOrVoid(variableHandling.BoolVal(callIteratorMethod("valid"))); !valid {
+			break
+		}
+
+		// Set key and value variable
+		valueName := mustOrVoid(interpreter.varExprToVarName(stmt.Value, env))
+		env.declareVariable(valueName, callIteratorMethod("current"))
+		if stmt.Key != nil {
+			keyName := mustOrVoid(interpreter.varExprToVarName(stmt.Key, env))
+			env.declareVariable(keyName, callIteratorMethod("key"))
+		}
+
+		// Execute body
+		runtimeValue, err := interpreter.processStmt(stmt.Block, env)
+		if err != nil {
+			if err.GetErrorType() == phpError.EventError && err.GetMessage() == "break" {
+				breakoutLevel := err.(*phpError.ContinueEventError).GetBreakoutLevel()
+				if breakoutLevel == 1 {
+					return values.NewVoidSlot(), nil
+				}
+				return values.NewVoidSlot(), phpError.NewBreakEvent(breakoutLevel - 1)
+			}
+			if err.GetErrorType() == phpError.EventError && err.GetMessage() == "continue" {
+				breakoutLevel := err.(*phpError.ContinueEventError).GetBreakoutLevel()
+				if breakoutLevel != 1 {
+					return values.NewVoidSlot(), phpError.NewContinueEvent(breakoutLevel - 1)
+				}
+			} else {
+				return runtimeValue, err
+			}
+		}
+
+		callIteratorMethod("next")
+	}
+	return values.NewVoidSlot(), nil
+}
+
 // ProcessTryStmt implements Visitor.
 func (interpreter *Interpreter) ProcessTryStmt(stmt *ast.TryStatement, env any) (any, error) {
-	_, bodyErr := interpreter.processStmt(stmt.Body, env)
+	// Spec: https://www.php.net/manual/en/language.exceptions.php
+	bodySlot, bodyErr := interpreter.processStmt(stmt.Body, env)
 
-	// TODO better handling of event errors
-	// TODO implement correct handling of catches - this requires that the error is an object
-	if bodyErr != nil {
-		for _, catch := range stmt.Catches {
-			// TODO Check if catch.ErrorType contains current error type
-			_, err := interpreter.processStmt(catch.Body, env)
-			if err != nil {
-				return values.NewVoidSlot(), err
+	if bodyErr != nil && bodyErr.GetErrorType() != phpError.EventError && len(stmt.Catches) > 0 {
+		if object := interpreter.errorToThrowable(bodyErr, env.(*Environment)); object != nil {
+			for _, catch := range stmt.Catches {
+				if !interpreter.catchMatches(catch, object, stmt.GetPosition().File.GetNamespaceStr()) {
+					continue
+				}
+				if catch.VariableName != "" {
+					if _, err := env.(*Environment).declareVariable(catch.VariableName, object); err != nil {
+						return values.NewVoidSlot(), err
+					}
+				}
+				bodySlot, bodyErr = interpreter.processStmt(catch.Body, env)
+				break
 			}
 		}
 	}
 
 	if stmt.Finally != nil {
-		_, err := interpreter.processStmt(stmt.Finally, env)
+		slot, err := interpreter.processStmt(stmt.Finally, env)
 		if err != nil {
-			return values.NewVoidSlot(), err
+			return slot, err
 		}
 	}
 
-	if bodyErr != nil && bodyErr.GetErrorType() == phpError.EventError {
-		return values.NewVoidSlot(), bodyErr
+	if bodyErr != nil {
+		return bodySlot, bodyErr
 	}
 	return values.NewVoidSlot(), nil
 }
@@ -5,6 +5,7 @@ import (
 	"QIQ/cmd/qiq/ini"
 	"QIQ/cmd/qiq/phpError"
 	"fmt"
+	"path/filepath"
 	"testing"
 )
 
@@ -15,10 +16,12 @@ func TestLibMath(t *testing.T) {
 	testInputOutput(t, `<?php var_dump(abs(-4.2));`, "float(4.2)\n")
 	testInputOutput(t, `<?php var_dump(abs(5));`, "int(5)\n")
 	testInputOutput(t, `<?php var_dump(abs(-5));`, "int(5)\n")
+	testInputOutput(t, `<?php var_dump(abs(PHP_INT_MIN));`, "float(9223372036854776000)\n")
 
 	// acos
 	testInputOutput(t, `<?php var_dump(acos(1.0));`, "float(0)\n")
 	testInputOutput(t, `<?php var_dump(acos(0.5)/M_PI*180);`, "float(60)\n")
+	testInputOutput(t, `<?php var_dump(acos(1));`, "float(0)\n")
 
 	// acosh
 	testInputOutput(t, `<?php var_dump(acosh(1.0));`, "float(0)\n")
@@ -29,6 +32,33 @@ func TestLibMath(t *testing.T) {
 	// asinh
 	testInputOutput(t, `<?php var_dump(asinh(0.0));`, "float(0)\n")
 
+	// atan
+	testInputOutput(t, `<?php var_dump(atan(1) * 4 === M_PI);`, "bool(true)\n")
+
+	// atan2
+	testInputOutput(t, `<?php var_dump(atan2(1, 1) === M_PI_4);`, "bool(true)\n")
+
+	// atanh
+	testInputOutput(t, `<?php var_dump(atanh(0));`, "float(0)\n")
+
+	// base_convert
+	testInputOutput(t, `<?php var_dump(base_convert('a37334', 16, 2));`, "string(24) \"101000110111001100110100\"\n")
+	testInputOutput(t, `<?php var_dump(base_convert('ZZ', 36, 10));`, "string(4) \"1295\"\n")
+	testForError(t, `<?php base_convert('1', 1, 10);`, phpError.NewError("Uncaught ValueError: base_convert(): Argument #2 ($frombase) must be between 2 and 36 (inclusive)"))
+	testForError(t, `<?php base_convert('1', 10, 37);`, phpError.NewError("Uncaught ValueError: base_convert(): Argument #3 ($tobase) must be between 2 and 36 (inclusive)"))
+
+	// bindec
+	testInputOutput(t, `<?php var_dump(bindec('110011'));`, "int(51)\n")
+	testInputOutput(t, `<?php var_dump(bindec('0b111'));`, "int(7)\n")
+	testInputOutput(t, `<?php var_dump(bindec(str_repeat('1', 64)));`, "float(18446744073709552000)\n")
+	testInputOutput(t, `<?php var_dump(bindec('1021'));`,
+		fmt.Sprintf("\nDeprecated: Invalid characters passed for attempted conversion, these have been ignored in %s:1:16\nint(5)\n", TEST_FILE_NAME))
+
+	// ceil
+	testInputOutput(t, `<?php var_dump(ceil(4.3));`, "float(5)\n")
+	testInputOutput(t, `<?php var_dump(ceil(-3.14));`, "float(-3)\n")
+	testInputOutput(t, `<?php var_dump(ceil(5));`, "float(5)\n")
+
 	// clamp
 	// Examples from https://php.watch/versions/8.6/clamp
 	// - Integer clamping
@@ -72,18 +102,312 @@ func TestLibMath(t *testing.T) {
 
 	// Examples from https://wiki.php.net/rfc/clamp_v2
 	// - Special numbers
-	// TODO clamp - special numbers
-	// clamp(M_PI, -INF, INF) // 3.141592653589793
-	// clamp(NAN, 4, 6) // NAN
+	testInputOutput(t, `<?php var_dump(clamp(M_PI, -INF, INF));`, "float(3.141592653589793)\n")
+	testInputOutput(t, `<?php var_dump(clamp(NAN, 4, 6));`, "float(NAN)\n")
 	// - Errors
 	// TODO clamp - errors
 	// TODO clamp - errors - change error type ValueError: clamp(): Argument #2 ($min) must be smaller than or equal to argument #3 ($max)
 	testForError(t, `<?php clamp(4, 8, 6);`, phpError.NewError("clamp(): Argument #2 ($min) must be smaller than or equal to argument #3 ($max) in %s:1:7", TEST_FILE_NAME))
-	// clamp(4, NAN, 6) // Throws ValueError: clamp(): Argument #2 ($min) cannot be NAN
-	// clamp(4, 6, NAN) // Throws ValueError: clamp(): Argument #3 ($max) cannot be NAN
+	testForError(t, `<?php clamp(4, NAN, 6);`, phpError.NewError("Uncaught ValueError: clamp(): Argument #2 ($min) cannot be NAN"))
+	testForError(t, `<?php clamp(4, 6, NAN);`, phpError.NewError("Uncaught ValueError: clamp(): Argument #3 ($max) cannot be NAN"))
+
+	// cos
+	testInputOutput(t, `<?php var_dump(cos(M_PI));`, "float(-1)\n")
+
+	// cosh
+	testInputOutput(t, `<?php var_dump(cosh(0));`, "float(1)\n")
+
+	// decbin
+	testInputOutput(t, `<?php var_dump(decbin(26));`, "string(5) \"11010\"\n")
+	testInputOutput(t, `<?php var_dump(decbin(-1));`, "string(64) \"1111111111111111111111111111111111111111111111111111111111111111\"\n")
+
+	// dechex
+	testInputOutput(t, `<?php var_dump(dechex(47));`, "string(2) \"2f\"\n")
+	testInputOutput(t, `<?php var_dump(dechex(PHP_INT_MAX));`, "string(16) \"7fffffffffffffff\"\n")
+
+	// decoct
+	testInputOutput(t, `<?php var_dump(decoct(264));`, "string(3) \"410\"\n")
+
+	// deg2rad
+	testInputOutput(t, `<?php var_dump(deg2rad(180) === M_PI);`, "bool(true)\n")
+
+	// exp
+	testInputOutput(t, `<?php var_dump(exp(0));`, "float(1)\n")
+	testInputOutput(t, `<?php var_dump(exp(1) === M_E);`, "bool(true)\n")
+
+	// expm1
+	testInputOutput(t, `<?php var_dump(expm1(0));`, "float(0)\n")
+
+	// fdiv
+	testInputOutput(t, `<?php var_dump(fdiv(10, 4));`, "float(2.5)\n")
+	testInputOutput(t, `<?php var_dump(fdiv(1, 0));`, "float(INF)\n")
+	testInputOutput(t, `<?php var_dump(fdiv(-1, 0));`, "float(-INF)\n")
+	testInputOutput(t, `<?php var_dump(fdiv(0, 0));`, "float(NAN)\n")
+
+	// floor
+	testInputOutput(t, `<?php var_dump(floor(4.3));`, "float(4)\n")
+	testInputOutput(t, `<?php var_dump(floor(-3.14));`, "float(-4)\n")
+
+	// fmod
+	testInputOutput(t, `<?php var_dump(fmod(5.7, 1.3));`, "float(0.5)\n")
+	testInputOutput(t, `<?php var_dump(fmod(-10, 3));`, "float(-1)\n")
+	testInputOutput(t, `<?php var_dump(fmod(1, 0));`, "float(NAN)\n")
+
+	// fpow
+	testInputOutput(t, `<?php var_dump(fpow(2, 3));`, "float(8)\n")
+	testInputOutput(t, `<?php var_dump(fpow(0, -1));`, "float(INF)\n")
+
+	// hexdec
+	testInputOutput(t, `<?php var_dump(hexdec('ee'));`, "int(238)\n")
+	testInputOutput(t, `<?php var_dump(hexdec('0xFF'));`, "int(255)\n")
+	testInputOutput(t, `<?php var_dump(hexdec('ffffffffffffffff'));`, "float(18446744073709552000)\n")
+	testInputOutput(t, `<?php var_dump(hexdec('a0g'));`,
+		fmt.Sprintf("\nDeprecated: Invalid characters passed for attempted conversion, these have been ignored in %s:1:16\nint(160)\n", TEST_FILE_NAME))
+
+	// hypot
+	testInputOutput(t, `<?php var_dump(hypot(3, 4));`, "float(5)\n")
+
+	// intdiv
+	testInputOutput(t, `<?php var_dump(intdiv(7, 2));`, "int(3)\n")
+	testInputOutput(t, `<?php var_dump(intdiv(-7, 2));`, "int(-3)\n")
+	testForError(t, `<?php intdiv(1, 0);`, phpError.NewError("Uncaught DivisionByZeroError: Division by zero"))
+	testForError(t, `<?php intdiv(PHP_INT_MIN, -1);`, phpError.NewError("Uncaught ArithmeticError: Division of PHP_INT_MIN by -1 is not an integer"))
+
+	// is_finite
+	testInputOutput(t, `<?php var_dump(is_finite(1.5), is_finite(INF), is_finite(NAN));`, "bool(true)\nbool(false)\nbool(false)\n")
+
+	// is_infinite
+	testInputOutput(t, `<?php var_dump(is_infinite(-INF), is_infinite(log(0)), is_infinite(PHP_FLOAT_MAX));`, "bool(true)\nbool(true)\nbool(false)\n")
+
+	// is_nan
+	testInputOutput(t, `<?php var_dump(is_nan(NAN), is_nan(acos(8)), is_nan(1));`, "bool(true)\nbool(true)\nbool(false)\n")
+
+	// log
+	testInputOutput(t, `<?php var_dump(log(M_E));`, "float(1)\n")
+	testInputOutput(t, `<?php var_dump(log(8, 2));`, "float(3)\n")
+	testInputOutput(t, `<?php var_dump(log(100, 10));`, "float(2)\n")
+	testInputOutput(t, `<?php var_dump(log(0));`, "float(-INF)\n")
+	testForError(t, `<?php log(8, 0);`, phpError.NewError("Uncaught ValueError: log(): Argument #2 ($base) must be greater than 0"))
+
+	// log10
+	testInputOutput(t, `<?php var_dump(log10(1000));`, "float(3)\n")
+
+	// log1p
+	testInputOutput(t, `<?php var_dump(log1p(0));`, "float(0)\n")
+
+	// max
+	testInputOutput(t, `<?php var_dump(max(2, 3, 1, 6, 7));`, "int(7)\n")
+	testInputOutput(t, `<?php var_dump(max([2, 4, 5]));`, "int(5)\n")
+	testInputOutput(t, `<?php var_dump(max('apple', 'banana'));`, "string(6) \"banana\"\n")
+	testInputOutput(t, `<?php var_dump(max('10', 9));`, "string(2) \"10\"\n")
+	testInputOutput(t, `<?php var_dump(max(1, 1.0));`, "int(1)\n")
+	testInputOutput(t, `<?php var_dump(max([1, 2, 3], [1, 2, 4]));`, "array(3) {\n  [0]=>\n  int(1)\n  [1]=>\n  int(2)\n  [2]=>\n  int(4)\n}\n")
+	testInputOutput(t, `<?php var_dump(max('string', [1, 2]));`, "array(2) {\n  [0]=>\n  int(1)\n  [1]=>\n  int(2)\n}\n")
+	testForError(t, `<?php max([]);`, phpError.NewError("Uncaught ValueError: max(): Argument #1 ($value) must contain at least one element"))
+	testForError(t, `<?php max(1);`, phpError.NewError("Uncaught TypeError: max(): Argument #1 ($value) must be of type array, int given"))
+
+	// min
+	testInputOutput(t, `<?php var_dump(min(2, 3, 1, 6, 7));`, "int(1)\n")
+	testInputOutput(t, `<?php var_dump(min([2, 4, 5]));`, "int(2)\n")
+	testInputOutput(t, `<?php var_dump(min('hello', 0));`, "int(0)\n")
+	testInputOutput(t, `<?php var_dump(min(-1.5, -1));`, "float(-1.5)\n")
+	testForError(t, `<?php min([]);`, phpError.NewError("Uncaught ValueError: min(): Argument #1 ($value) must contain at least one element"))
+
+	// octdec
+	testInputOutput(t, `<?php var_dump(octdec('777'));`, "int(511)\n")
+	testInputOutput(t, `<?php var_dump(octdec('0o17'));`, "int(15)\n")
 
 	// pi
 	testInputOutput(t, `<?php var_dump(M_PI === pi());`, "bool(true)\n")
+
+	// pow
+	testInputOutput(t, `<?php var_dump(pow(2, 8));`, "int(256)\n")
+	testInputOutput(t, `<?php var_dump(pow(-1, 20));`, "int(1)\n")
+	testInputOutput(t, `<?php var_dump(pow(0, 0));`, "int(1)\n")
+	testInputOutput(t, `<?php var_dump(pow(2, 0.5) === M_SQRT2);`, "bool(true)\n")
+	testInputOutput(t, `<?php var_dump(pow('3', 2));`, "int(9)\n")
+	testInputOutput(t, `<?php var_dump(pow(2, 64));`, "float(18446744073709552000)\n")
+	testInputOutput(t, `<?php var_dump(pow(2, -1));`, "float(0.5)\n")
+	testForError(t, `<?php pow([], 2);`, phpError.NewError("Uncaught TypeError: Unsupported operand types: array ** int"))
+
+	// rad2deg
+	testInputOutput(t, `<?php var_dump(rad2deg(M_PI_4));`, "float(45)\n")
+
+	// round
+	testInputOutput(t, `<?php var_dump(round(3.4));`, "float(3)\n")
+	testInputOutput(t, `<?php var_dump(round(3.5));`, "float(4)\n")
+	testInputOutput(t, `<?php var_dump(round(-3.5));`, "float(-4)\n")
+	testInputOutput(t, `<?php var_dump(round(5));`, "float(5)\n")
+	testInputOutput(t, `<?php var_dump(round(1.955, 2));`, "float(1.96)\n")
+	testInputOutput(t, `<?php var_dump(round(0.285, 2));`, "float(0.29)\n")
+	testInputOutput(t, `<?php var_dump(round(5.045, 2));`, "float(5.05)\n")
+	testInputOutput(t, `<?php var_dump(round(5.055, 2));`, "float(5.06)\n")
+	testInputOutput(t, `<?php var_dump(round(1241757, -3));`, "float(1242000)\n")
+	testInputOutput(t, `<?php var_dump(round(345, -2));`, "float(300)\n")
+	testInputOutput(t, `<?php var_dump(round(678, -2));`, "float(700)\n")
+	testInputOutput(t, `<?php var_dump(round(9.5, 0, PHP_ROUND_HALF_UP));`, "float(10)\n")
+	testInputOutput(t, `<?php var_dump(round(9.5, 0, PHP_ROUND_HALF_DOWN));`, "float(9)\n")
+	testInputOutput(t, `<?php var_dump(round(9.5, 0, PHP_ROUND_HALF_EVEN));`, "float(10)\n")
+	testInputOutput(t, `<?php var_dump(round(9.5, 0, PHP_ROUND_HALF_ODD));`, "float(9)\n")
+	testInputOutput(t, `<?php var_dump(round(-1.55, 1, PHP_ROUND_HALF_DOWN));`, "float(-1.5)\n")
+	testInputOutput(t, `<?php var_dump(round(-1.55, 1, PHP_ROUND_HALF_EVEN));`, "float(-1.6)\n")
+	testInputOutput(t, `<?php var_dump(round(8.5, 0, RoundingMode::HalfEven));`, "float(8)\n")
+	testInputOutput(t, `<?php var_dump(round(8.5, 0, RoundingMode::HalfOdd));`, "float(9)\n")
+	testInputOutput(t, `<?php var_dump(round(8.5, 0, RoundingMode::HalfTowardsZero));`, "float(8)\n")
+	testInputOutput(t, `<?php var_dump(round(-8.5, 0, RoundingMode::HalfAwayFromZero));`, "float(-9)\n")
+	testInputOutput(t, `<?php var_dump(round(8.1, 0, RoundingMode::AwayFromZero));`, "float(9)\n")
+	testInputOutput(t, `<?php var_dump(round(-8.9, 0, RoundingMode::TowardsZero));`, "float(-8)\n")
+	testInputOutput(t, `<?php var_dump(round(-8.1, 0, RoundingMode::NegativeInfinity));`, "float(-9)\n")
+	testInputOutput(t, `<?php var_dump(round(8.1, 0, RoundingMode::PositiveInfinity));`, "float(9)\n")
+	testInputOutput(t, `<?php var_dump(round(1.2345, 2, RoundingMode::PositiveInfinity));`, "float(1.24)\n")
+	testInputOutput(t, `<?php var_dump(RoundingMode::HalfEven->name);`, "string(8) \"HalfEven\"\n")
+	testInputOutput(t, `<?php var_dump(RoundingMode::HalfEven === RoundingMode::HalfEven);`, "bool(true)\n")
+	testInputOutput(t, `<?php var_dump(count(RoundingMode::cases()));`, "int(8)\n")
+	testForError(t, `<?php round(1.5, 0, 9);`, phpError.NewError("Uncaught ValueError: round(): Argument #3 ($mode) must be a valid rounding mode (RoundingMode::*)"))
+
+	// sin
+	testInputOutput(t, `<?php var_dump(sin(M_PI_2));`, "float(1)\n")
+
+	// sinh
+	testInputOutput(t, `<?php var_dump(sinh(0));`, "float(0)\n")
+
+	// sqrt
+	testInputOutput(t, `<?php var_dump(sqrt(9));`, "float(3)\n")
+	testInputOutput(t, `<?php var_dump(sqrt(2) === M_SQRT2);`, "bool(true)\n")
+	testInputOutput(t, `<?php var_dump(sqrt(-1));`, "float(NAN)\n")
+
+	// tan
+	testInputOutput(t, `<?php var_dump(tan(0));`, "float(0)\n")
+
+	// tanh
+	testInputOutput(t, `<?php var_dump(tanh(0));`, "float(0)\n")
+}
+
+// -------------------------------------- random -------------------------------------- MARK: random
+
+func TestLibRandom(t *testing.T) {
+	// getrandmax
+	testInputOutput(t, `<?php var_dump(getrandmax(), mt_getrandmax());`, "int(2147483647)\nint(2147483647)\n")
+
+	// mt_rand / mt_srand
+	testInputOutput(t, `<?php mt_srand(1); var_dump(mt_rand(), mt_rand());`, "int(895547922)\nint(2141438069)\n")
+	testInputOutput(t, `<?php mt_srand(42); var_dump(mt_rand(), mt_rand(1, 6));`, "int(804318771)\nint(6)\n")
+	testInputOutput(t, `<?php mt_srand(1, MT_RAND_PHP);`,
+		fmt.Sprintf("\nDeprecated: mt_srand(): The MT_RAND_PHP variant of Mt19937 is deprecated in %s:1:7\n", TEST_FILE_NAME))
+	testInputOutput(t, `<?php try { mt_rand(2, 1); } catch (ValueError $e) { echo $e->getMessage(); }`, "mt_rand(): Argument #2 ($max) must be greater than or equal to argument #1 ($min)")
+	testInputOutput(t, `<?php $a = mt_rand(5, 5); echo $a;`, "5")
+
+	// rand / srand
+	testInputOutput(t, `<?php srand(1); var_dump(rand());`, "int(895547922)\n")
+	testInputOutput(t, `<?php $a = rand(3, 3); $b = rand(4, 4); echo $a, $b;`, "34")
+
+	// random_bytes
+	testInputOutput(t, `<?php echo strlen(random_bytes(5));`, "5")
+	testInputOutput(t, `<?php try { random_bytes(0); } catch (ValueError $e) { echo $e->getMessage(); }`, "random_bytes(): Argument #1 ($length) must be greater than 0")
+
+	// random_int
+	testInputOutput(t, `<?php $a = random_int(7, 7); $b = random_int(1, 2); echo $a, $b >= 1 && $b <= 2 ? "y" : "n";`, "7y")
+	testInputOutput(t, `<?php try { random_int(2, 1); } catch (ValueError $e) { echo $e->getMessage(); }`, "random_int(): Argument #1 ($min) must be less than or equal to argument #2 ($max)")
+
+	// Random\Engine\Mt19937
+	testInputOutput(t, `<?php $e = new Random\Engine\Mt19937(1); echo bin2hex($e->generate());`, "25f4c16a")
+	testInputOutput(t, `<?php $e = new Random\Engine\Mt19937(1, MT_RAND_PHP);`,
+		"\nDeprecated: Random\\Engine\\Mt19937::__construct(): The MT_RAND_PHP variant of Mt19937 is deprecated\n")
+	testInputOutput(t, `<?php try { $e = new Random\Engine\Mt19937(1, 5); } catch (ValueError $e) { echo $e->getMessage(); }`, "Random\\Engine\\Mt19937::__construct(): Argument #2 ($mode) must be either MT_RAND_MT19937 or MT_RAND_PHP")
+
+	// Random\Engine\PcgOneseq128XslRr64
+	testInputOutput(t, `<?php $e = new Random\Engine\PcgOneseq128XslRr64(1); echo bin2hex($e->generate());`, "fa7b50d32ee375e1")
+	testInputOutput(t, `<?php $e = new Random\Engine\PcgOneseq128XslRr64(1); $e->jump(1); echo bin2hex($e->generate());`, "0931280b2a92bfc0")
+	testInputOutput(t, `<?php try { $e = new Random\Engine\PcgOneseq128XslRr64("short"); } catch (ValueError $e) { echo $e->getMessage(); }`, "Random\\Engine\\PcgOneseq128XslRr64::__construct(): Argument #1 ($seed) must be a 16 byte (128 bit) string")
+
+	// Random\Engine\Xoshiro256StarStar
+	testInputOutput(t, `<?php $e = new Random\Engine\Xoshiro256StarStar(1); echo bin2hex($e->generate());`, "c510c70f6daff2b3")
+	testInputOutput(t, `<?php $e = new Random\Engine\Xoshiro256StarStar(1); $e->jump(); echo bin2hex($e->generate());`, "d0e9aa1ef8022833")
+	testInputOutput(t, `<?php try { $e = new Random\Engine\Xoshiro256StarStar(str_repeat(chr(0), 32)); } catch (ValueError $e) { echo $e->getMessage(); }`, "Random\\Engine\\Xoshiro256StarStar::__construct(): Argument #1 ($seed) must not consist entirely of NUL bytes")
+
+	// Random\Engine\Secure
+	testInputOutput(t, `<?php $e = new Random\Engine\Secure(); echo strlen($e->generate());`, "8")
+
+	// Random\Randomizer
+	testInputOutput(t, `<?php $r = new Random\Randomizer(new Random\Engine\Mt19937(1)); echo bin2hex($r->getBytes(6));`, "25f4c16aeb80")
+	testInputOutput(t, `<?php $r = new Random\Randomizer(new Random\Engine\Mt19937(1)); var_dump($r->nextInt());`, "int(895547922)\n")
+	testInputOutput(t, `<?php $r = new Random\Randomizer(new Random\Engine\Mt19937(1)); var_dump($r->getInt(1, 100));`, "int(46)\n")
+	testInputOutput(t, `<?php $r = new Random\Randomizer(new Random\Engine\Mt19937(1)); echo $r->getBytesFromString("ab", 8);`, "babababb")
+	testInputOutput(t, `<?php $r = new Random\Randomizer(new Random\Engine\Mt19937(1)); var_dump($r->nextFloat());`, "float(0.9971848082302655)\n")
+	testInputOutput(t, `<?php $r = new Random\Randomizer(new Random\Engine\Mt19937(1)); $r->getInt(1, 100); echo implode(",", $r->shuffleArray([1, 2, 3, 4, 5]));`, "4,2,3,1,5")
+	testInputOutput(t, `<?php $r = new Random\Randomizer(new Random\Engine\Mt19937(1)); echo strlen($r->shuffleBytes("abcdef"));`, "6")
+	testInputOutput(t, `<?php $r = new Random\Randomizer(); echo get_class($r->engine), strlen($r->getBytes(3));`, "Random\\Engine\\Secure3")
+	testInputOutput(t, `<?php $r = new Random\Randomizer(); echo implode(",", $r->pickArrayKeys(["a" => 1, "b" => 2, "c" => 3], 3));`, "a,b,c")
+	testInputOutput(t, `<?php $r = new Random\Randomizer(); try { $r->getInt(5, 1); } catch (ValueError $e) { echo $e->getMessage(); }`, "Random\\Randomizer::getInt(): Argument #2 ($max) must be greater than or equal to argument #1 ($min)")
+	testInputOutput(t, `<?php try { new Random\Randomizer(42); } catch (TypeError $e) { echo $e->getMessage(); }`, "Random\\Randomizer::__construct(): Argument #1 ($engine) must be of type ?Random\\Engine, int given")
+
+	// User engine
+	testInputOutput(t,
+		`<?php class Counter implements Random\Engine { private $i = 0; public function generate(): string { return chr($this->i++); } }
+		$r = new Random\Randomizer(new Counter()); echo bin2hex($r->getBytes(4)), $r->getInt(0, 255);`,
+		"000102034",
+	)
+	testInputOutput(t,
+		`<?php class Broken implements \Random\Engine { public function generate(): string { return ""; } }
+		$r = new Random\Randomizer(new Broken());
+		try { $r->nextInt(); } catch (Random\RandomError $e) { echo get_class($e), ": ", $e->getMessage(); }`,
+		"Random\\BrokenRandomEngineError: A random engine must return a non-empty string",
+	)
+}
+
+// -------------------------------------- bcmath -------------------------------------- MARK: bcmath
+
+func TestLibBcmath(t *testing.T) {
+	// bcadd / bcsub / bcmul
+	testInputOutput(t, `<?php echo bcadd('1.234', '5', 4), " ", bcadd('1.234', '5'), " ", bcsub('1.234', '5', 4);`, "6.2340 6 -3.7660")
+	testInputOutput(t, `<?php echo bcmul('1.34747474747', '35', 3), " ", bcmul('2', '4'), " ", bcmul('-0.1', '0.1', 1);`, "47.161 8 0.0")
+	testInputOutput(t, `<?php try { bcadd('1a', '1'); } catch (ValueError $e) { echo $e->getMessage(); }`, "bcadd(): Argument #1 ($num1) is not well-formed")
+
+	// bcdiv / bcmod / bcdivmod
+	testInputOutput(t, `<?php echo bcdiv('105', '6.55957', 3), " ", bcmod('5.7', '1.3', 1), " ", bcmod('-4', '3');`, "16.007 0.5 -1")
+	testInputOutput(t, `<?php var_dump(bcdivmod('11', '3', 1));`, "array(2) {\n  [0]=>\n  string(1) \"3\"\n  [1]=>\n  string(3) \"2.0\"\n}\n")
+	testInputOutput(t, `<?php try { bcdiv('1', '0'); } catch (DivisionByZeroError $e) { echo $e->getMessage(); }`, "Division by zero")
+	testInputOutput(t, `<?php try { bcmod('1', '0'); } catch (DivisionByZeroError $e) { echo $e->getMessage(); }`, "Modulo by zero")
+
+	// bcpow / bcpowmod / bcsqrt
+	testInputOutput(t, `<?php echo bcpow('4.2', '3', 2), " ", bcpow('5', '2', 2), " ", bcpow('2', '-2', 4);`, "74.08 25.00 0.2500")
+	testInputOutput(t, `<?php try { bcpow('1', '1.5'); } catch (ValueError $e) { echo $e->getMessage(); }`, "bcpow(): Argument #2 ($exponent) cannot have a fractional part")
+	testInputOutput(t, `<?php echo bcpowmod('4', '3', '5'), " ", bcpowmod('-4', '3', '5');`, "4 -4")
+	testInputOutput(t, `<?php echo bcsqrt('2', 3);`, "1.414")
+	testInputOutput(t, `<?php try { bcsqrt('-1'); } catch (ValueError $e) { echo $e->getMessage(); }`, "bcsqrt(): Argument #1 ($num) must be greater than or equal to 0")
+
+	// bccomp
+	testInputOutput(t, `<?php echo bccomp('1.00001', '1', 3), " ", bccomp('1.00001', '1', 5), " ", bccomp('-1', '1');`, "0 1 -1")
+
+	// bcfloor / bcceil / bcround
+	testInputOutput(t, `<?php echo bcfloor('4.3'), " ", bcfloor('-4.3'), " ", bcceil('-4.3'), " ", bcceil('-0.5');`, "4 -5 -4 0")
+	testInputOutput(t, `<?php echo bcround('3.4'), " ", bcround('3.5'), " ", bcround('1.95583', 2), " ", bcround('5.045', 2), " ", bcround('9.999', 2), " ", bcround('1234.5678', -2);`, "3 4 1.96 5.05 10.00 1200")
+	testInputOutput(t, `<?php echo bcround('2.5', 0, RoundingMode::HalfEven), " ", bcround('-2.5', 0, PHP_ROUND_HALF_DOWN), " ", bcround('1.21', 1, RoundingMode::AwayFromZero);`, "2 -2 1.3")
+
+	// bcscale
+	testInputOutput(t, `<?php echo bcscale(3), " "; echo bcdiv('105', '6.55957'), " ", bcscale(), " ", ini_get('bcmath.scale');`, "0 16.007 3 3")
+	testInputOutput(t, `<?php try { bcscale(-1); } catch (ValueError $e) { echo $e->getMessage(); }`, "bcscale(): Argument #1 ($scale) must be between 0 and 2147483647")
+
+	// BcMath\Number
+	testInputOutput(t, `<?php $a = new BcMath\Number('0.10'); var_dump($a->value, $a->scale, $a->__toString());`, "string(4) \"0.10\"\nint(2)\nstring(4) \"0.10\"\n")
+	testInputOutput(t, `<?php $a = new BcMath\Number('0.2'); $b = $a->add('1.111', 2); $c = $a->div(3); $d = $a->sqrt(); echo $b->value, " ", $c->value, " ", $d->value;`, "1.31 0.06666666666 0.44721359549")
+	testInputOutput(t, `<?php $a = new BcMath\Number('1.25'); $b = $a->round(1); $c = $a->round(1, RoundingMode::HalfEven); $d = $a->floor(); echo $b->value, " ", $c->value, " ", $d->value;`, "1.3 1.2 1")
+	testInputOutput(t, `<?php $a = new BcMath\Number('11'); $r = $a->divmod('3'); echo $r[0]->value, " ", $r[1]->value, " ", $a->compare(11);`, "3 2 0")
+	testInputOutput(t, `<?php try { new BcMath\Number('x'); } catch (ValueError $e) { echo $e->getMessage(); }`, "BcMath\\Number::__construct(): Argument #1 ($num) is not well-formed")
+	testInputOutput(t, `<?php $a = new BcMath\Number('1.5'); try { $a->value = '3'; } catch (Error $e) { echo $e->getMessage(), "\n"; } try { $a->scale += 1; } catch (Error $e) { echo $e->getMessage(), "\n"; } $b = $a + 1; echo $a->value, " ", $b->value;`,
+		"Cannot modify readonly property BcMath\\Number::$value\nCannot modify readonly property BcMath\\Number::$scale\n1.5 2.5")
+	testInputOutput(t, `<?php $a = new BcMath\Number('1'); try { unset($a->scale); } catch (Error $e) { echo $e->getMessage(), "\n"; } try { $a->__construct('2'); } catch (Error $e) { echo $e->getMessage(); }`,
+		"Cannot unset readonly property BcMath\\Number::$scale\nCannot modify readonly property BcMath\\Number::$value")
+
+	// BcMath\Number operators
+	testInputOutput(t, `<?php $a = new BcMath\Number('0.1'); $b = $a + '0.2'; echo $b->value, " ", $b->scale;`, "0.3 1")
+	testInputOutput(t, `<?php $a = new BcMath\Number('1.5'); $b = 2 - $a; $c = $a * '1.25'; $d = $a ** 2; $e = 5 % $a; $f = -$a; echo $b->value, " ", $c->value, " ", $d->value, " ", $e->value, " ", $f->value;`, "0.5 1.875 2.25 0.5 -1.5")
+	testInputOutput(t, `<?php $a = new BcMath\Number('1'); $b = new BcMath\Number('1.5'); $c = $a / 3; $d = $b / '0.3'; echo $c->value, " ", $d->value;`, "0.3333333333 5.0")
+	testInputOutput(t, `<?php $a = new BcMath\Number('1.5'); $a += 1; $a *= 2; echo $a->value;`, "5.0")
+	testInputOutput(t, `<?php $a = new BcMath\Number('0.1'); $b = new BcMath\Number('0.2'); var_dump($a < $b, $a == '0.10', $a != $b, $a <=> $b, 1 > $a);`, "bool(true)\nbool(true)\nbool(true)\nint(-1)\nbool(true)\n")
+	testInputOutput(t, `<?php $a = new BcMath\Number('1'); try { $a + 'abc'; } catch (ValueError $e) { echo $e->getMessage(); }`, "Number is not well-formed")
+	testInputOutput(t, `<?php $a = new BcMath\Number('1'); try { $a + []; } catch (TypeError $e) { echo $e->getMessage(); }`, "Unsupported operand types: BcMath\\Number + array")
+	testInputOutput(t, `<?php $a = new BcMath\Number('1'); try { $a / 0; } catch (DivisionByZeroError $e) { echo $e->getMessage(); }`, "Division by zero")
 }
 
 // -------------------------------------- ob_ functions -------------------------------------- MARK: ob_ functions
@@ -127,6 +451,71 @@ func TestObFunctions(t *testing.T) {
             echo '-' . $ob;
         ?>`,
 		"034-12")
+	// ob_get_length
+	testInputOutput(t, `<?php var_dump(ob_get_length()); ob_start(); echo 'abc'; $l = ob_get_length(); ob_end_clean(); echo $l;`, "bool(false)\n3")
+	// ob_list_handlers
+	testInputOutput(t,
+		`<?php function h($b) { return $b; } ob_start(); ob_start('h'); ob_start(function ($b) { return $b; });
+        $h = ob_list_handlers(); ob_end_clean(); ob_end_clean(); ob_end_clean(); echo implode(',', $h);`,
+		"default output handler,h,Closure::__invoke")
+	// ob_get_status
+	testInputOutput(t,
+		`<?php var_dump(count(ob_get_status())); ob_start(null, 4096); echo 'ab'; $s = ob_get_status(); ob_end_clean();
+        echo $s['name'], ',', $s['type'], ',', $s['flags'], ',', $s['level'], ',', $s['chunk_size'], ',', $s['buffer_size'], ',', $s['buffer_used'];`,
+		"int(0)\ndefault output handler,0,112,0,4096,8192,2")
+	testInputOutput(t,
+		`<?php function h($b) { return $b; } ob_start(); ob_start('h'); $s = ob_get_status(true); ob_end_clean(); ob_end_clean();
+        echo count($s), $s[1]['name'], $s[1]['type'], ',', $s[1]['flags'], ',', $s[1]['level'];`,
+		"2h1,113,1")
+	// ob_implicit_flush and flush
+	testInputOutput(t, `<?php ob_implicit_flush(); echo 'a'; flush(); echo 'b';`, "ab")
+	// Output handler with phases
+	testInputOutput(t,
+		`<?php function h($b, $p) { return "[" . $p . ":" . $b . "]"; }
+        ob_start('h'); echo 'a'; ob_flush(); echo 'b'; ob_clean(); echo 'c'; ob_end_flush();`,
+		"[5:a][8:c]")
+	testInputOutput(t,
+		`<?php function h($b, $p) { return "[" . $p . ":" . $b . "]"; } ob_start('h'); echo 'a'; $ob = ob_get_flush(); echo $ob;`,
+		"[9:a]a")
+	testInputOutput(t,
+		`<?php $log = ''; ob_start(function ($b, $p) use (&$log) { $log .= "$p:$b,"; return $b; }); echo 'a'; ob_end_clean(); echo $log;`,
+		"11:a,")
+	testInputOutput(t, `<?php ob_start(function ($b, $p) { return strtoupper($b) . $p; }); echo 'abc';`, "ABC9")
+	testInputOutput(t,
+		`<?php ob_start(function ($b, $p) { return "[" . $p . ":" . $b . "]"; }); ob_start(function ($b) { return strtoupper($b); }); echo 'abc'; ob_end_flush(); echo 'd';`,
+		"[9:ABCd]")
+	// Output of the output handler is discarded
+	testInputOutput(t, `<?php ob_start(function ($b) { echo 'lost'; return "<$b>"; }); echo 'abc';`, "<abc>")
+	// Output handler returning false is disabled
+	testInputOutput(t,
+		`<?php ob_start(function ($b) { return false; }); echo 'a'; ob_flush(); echo 'b'; $s = ob_get_status(); ob_end_flush(); echo $s['flags'] & PHP_OUTPUT_HANDLER_DISABLED;`,
+		"ab8192")
+	// Chunk size
+	testInputOutput(t,
+		`<?php ob_start(function ($b, $p) { return "<$b|$p>"; }, 4); echo 'ab'; echo 'cd'; echo 'efghi'; echo 'j'; ob_end_flush();`,
+		"<abcd|1><efghi|0><j|8>")
+	// Flags
+	testInputOutput(t,
+		`<?php ob_start(null, 0, PHP_OUTPUT_HANDLER_STDFLAGS ^ PHP_OUTPUT_HANDLER_CLEANABLE); echo 'a'; var_dump(ob_clean());`,
+		fmt.Sprintf("a\nNotice: ob_clean(): Failed to delete buffer of default output handler (0) in %s:1:105\nbool(false)\n", TEST_FILE_NAME))
+	testInputOutput(t,
+		`<?php ob_start(null, 0, PHP_OUTPUT_HANDLER_STDFLAGS ^ PHP_OUTPUT_HANDLER_FLUSHABLE); echo 'a'; var_dump(ob_flush());`,
+		fmt.Sprintf("a\nNotice: ob_flush(): Failed to flush buffer of default output handler (0) in %s:1:105\nbool(false)\n", TEST_FILE_NAME))
+	testInputOutput(t,
+		`<?php ob_start(null, 0, 0); echo 'a'; var_dump(ob_end_clean());`,
+		fmt.Sprintf("a\nNotice: ob_end_clean(): Failed to discard buffer of default output handler (0) in %s:1:48\nbool(false)\n", TEST_FILE_NAME))
+	testInputOutput(t,
+		`<?php ob_start(null, 0, 0); echo 'a'; var_dump(ob_end_flush());`,
+		fmt.Sprintf("a\nNotice: ob_end_flush(): Failed to send buffer of default output handler (0) in %s:1:48\nbool(false)\n", TEST_FILE_NAME))
+	// Output buffering in output handlers
+	testForError(t, `<?php ob_start(function ($b) { ob_start(); return $b; }); echo 'a'; ob_end_flush();`,
+		phpError.NewError("ob_start(): Cannot use output buffering in output buffering display handlers in %s:1:32", TEST_FILE_NAME))
+	testForError(t, `<?php ob_start(function ($b) { return ob_get_flush(); }); echo 'a'; ob_end_flush();`,
+		phpError.NewError("ob_get_flush(): Cannot use output buffering in output buffering display handlers in %s:1:39", TEST_FILE_NAME))
+	// Exceptions thrown by output handlers
+	testInputOutput(t,
+		`<?php ob_start(function ($b) { throw new Exception('boom'); }); echo 'a'; try { ob_end_flush(); } catch (Exception $e) { echo $e->getMessage(), ob_get_level(); }`,
+		"boom0")
 }
 
 // -------------------------------------- date -------------------------------------- MARK: date
@@ -177,11 +566,281 @@ func TestLibDate(t *testing.T) {
 		"Array\n(\n    [seconds] => 56\n    [minutes] => 43\n    [hours] => 17\n    [mday] => 3\n    [wday] => 6\n    [mon] => 8\n"+
 			"    [year] => 2024\n    [yday] => 215\n    [weekday] => Saturday\n    [month] => August\n    [0] => 1722707036\n)\n",
 	)
+
+	// gettimeofday
+	testInputOutput(t, `<?php $t = gettimeofday(); var_dump(array_keys($t), $t['minuteswest'], is_float(gettimeofday(true)));`,
+		"array(4) {\n  [0]=>\n  string(3) \"sec\"\n  [1]=>\n  string(4) \"usec\"\n  [2]=>\n  string(11) \"minuteswest\"\n  [3]=>\n  string(7) \"dsttime\"\n}\nint(0)\nbool(true)\n",
+	)
+
+	// gmdate
+	testInputOutput(t, `<?php date_default_timezone_set('Asia/Tokyo'); echo gmdate('Y-m-d H:i:s T e', 1700000000), ' ', date('Y-m-d H:i:s T e', 1700000000);`,
+		"2023-11-14 22:13:20 GMT UTC 2023-11-15 07:13:20 JST Asia/Tokyo")
+
+	// gmmktime
+	testInputOutput(t, `<?php date_default_timezone_set('Europe/Berlin'); var_dump(gmmktime(12, 0, 0, 7, 1, 2024), mktime(12, 0, 0, 7, 1, 2024));`, "int(1719835200)\nint(1719828000)\n")
+
+	// gmstrftime
+	testInputOutput(t, `<?php echo gmstrftime('%a %d %b %Y %H:%M:%S %Z', 1700000000);`,
+		fmt.Sprintf("\nDeprecated: Function gmstrftime() is deprecated since 8.1, use IntlDateFormatter::format() instead in %s:1:12\nTue 14 Nov 2023 22:13:20 GMT", TEST_FILE_NAME))
+
+	// idate
+	testInputOutput(t, `<?php foreach (['y', 'Y', 'm', 'd', 'H', 'h', 'i', 's', 'z', 'w', 'N', 'W', 'L', 't', 'B', 'I', 'Z', 'U'] as $f) { echo idate($f, 1120000000), ','; }`,
+		"5,2005,6,28,23,11,6,40,178,2,2,26,0,30,4,0,0,1120000000,")
+	testForError(t, `<?php idate('yy');`, phpError.NewError("Uncaught ValueError: idate(): Argument #1 ($format) must be one character"))
+	testForError(t, `<?php idate('x');`, phpError.NewError("Uncaught ValueError: idate(): Argument #1 ($format) must be a valid date format character"))
+
+	// localtime
+	testInputOutput(t, `<?php date_default_timezone_set('Europe/Berlin'); $l = localtime(1720000000, true); echo $l['tm_hour'], ' ', $l['tm_isdst'], ' ', $l['tm_year'];`, "11 1 124")
+	testInputOutput(t, `<?php date_default_timezone_set('UTC'); $l = localtime(1704067200, true); $n = localtime(1704067200); echo $l['tm_mon'], ' ', $l['tm_mday'], ' ', $n[4];`, "0 1 0")
+
+	// date_add
+	testInputOutput(t, `<?php $d = date_create('2020-02-29'); date_add($d, new DateInterval('P1Y')); echo $d->format('Y-m-d');`, "2021-03-01")
+
+	// date_create
+	testInputOutput(t, `<?php var_dump(date_create('2024-01-31 10:30:15.5', timezone_open('Europe/Berlin')));`,
+		"object(DateTime)#1 (3) {\n  [\"date\":\"DateTime\":public]=>\n  string(26) \"2024-01-31 10:30:15.500000\"\n"+
+			"  [\"timezone_type\":\"DateTime\":public]=>\n  int(3)\n  [\"timezone\":\"DateTime\":public]=>\n  string(13) \"Europe/Berlin\"\n}\n",
+	)
+	testInputOutput(t, `<?php $d = date_create('@1700000000'); echo $d->format('Y-m-d H:i:s e');`, "2023-11-14 22:13:20 +00:00")
+	testInputOutput(t, `<?php $d = date_create('2024-06-01T12:00:00Z'); echo $d->format('c T');`, "2024-06-01T12:00:00+00:00 Z")
+	testInputOutput(t, `<?php $d = date_create('12/24/2023 8:15 pm'); echo $d->format('Y-m-d H:i');`, "2023-12-24 20:15")
+	testInputOutput(t, `<?php $d = date_create('24.12.2023 -0500'); echo $d->format('Y-m-d H:i:s P');`, "2023-12-24 00:00:00 -05:00")
+	testInputOutput(t, `<?php var_dump(date_create('foo'));`, "bool(false)\n")
+
+	// date_create_from_format
+	testInputOutput(t, `<?php $d = date_create_from_format('d/m/Y H:i', '15/08/2023 14:05'); echo $d->format('Y-m-d H:i:s.u');`, "2023-08-15 14:05:00.000000")
+	testInputOutput(t, `<?php $d = date_create_from_format('!Y-m-d', '2024-02-10'); echo $d->format('Y-m-d H:i:s');`, "2024-02-10 00:00:00")
+	testInputOutput(t, `<?php $d = date_create_from_format('D, d M Y g:i a', 'Mon, 15 Jan 2024 3:04 pm'); echo $d->format('r');`, "Mon, 15 Jan 2024 15:04:00 +0000")
+	testInputOutput(t, `<?php $d = date_create_from_format('U.u', '1700000000.5'); echo $d->format('Y-m-d H:i:s.u');`, "2023-11-14 22:13:20.500000")
+	testInputOutput(t, `<?php var_dump(date_create_from_format('Y-m-d', '2023-02-01x'));`, "bool(false)\n")
+
+	// date_default_timezone_get
+	testInputOutput(t, `<?php echo date_default_timezone_get();`, "UTC")
+	berlinIni := ini.NewDevIni()
+	berlinIni.Set("date.timezone", "Europe/Berlin", ini.INI_ALL)
+	testInputOutputCustomIni(t, berlinIni, `<?php echo date_default_timezone_get(), ' ', date('Y-m-d H:i T', 1700000000), ' ', strtotime('2024-01-01'), ' ', idate('H', 0);`,
+		"Europe/Berlin 2023-11-14 23:13 CET 1704063600 1")
+	testInputOutputCustomIni(t, berlinIni, `<?php $g = getdate(1722707036); echo $g['hours'], ' ', date_create('2024-07-01')->format('c');`, "19 2024-07-01T00:00:00+02:00")
+	invalidIni := ini.NewDevIni()
+	invalidIni.Set("date.timezone", "Mars/Base", ini.INI_ALL)
+	testInputOutputCustomIni(t, invalidIni, `<?php echo date_default_timezone_get();`,
+		fmt.Sprintf("\nWarning: Invalid date.timezone value 'Mars/Base', using 'UTC' instead in %s:1:12\nUTC", TEST_FILE_NAME))
+
+	// date_default_timezone_set
+	testInputOutputCustomIni(t, berlinIni, `<?php var_dump(date_default_timezone_set('america/new_york'), date_default_timezone_get(), date('H:i T', 1700000000));`,
+		"bool(true)\nstring(16) \"America/New_York\"\nstring(9) \"17:13 EST\"\n")
+	testInputOutput(t, `<?php var_dump(date_default_timezone_set('Mars/Base'), date_default_timezone_get());`,
+		fmt.Sprintf("\nNotice: date_default_timezone_set(): Timezone ID 'Mars/Base' is invalid in %s:1:16\nbool(false)\nstring(3) \"UTC\"\n", TEST_FILE_NAME))
+
+	// date_diff
+	testInputOutput(t, `<?php $i = date_diff(date_create('2021-01-31'), date_create('2021-03-01')); echo $i->format('%R %y %m %d %a');`, "+ 0 0 29 29")
+	testInputOutput(t, `<?php $i = date_diff(date_create('2024-03-15 12:00'), date_create('2000-01-01')); echo $i->format('%R%y %m %d %H:%I:%S %a');`, "-24 2 14 12:00:00 8840")
+	testInputOutput(t, `<?php $i = date_diff(date_create('2024-03-15 12:00'), date_create('2000-01-01'), true); echo $i->format('%R');`, "+")
+	testInputOutput(t, `<?php $i = date_diff(date_create('2024-01-01 10:00 UTC'), date_create('2024-01-01 12:30', timezone_open('Europe/Berlin'))); echo $i->format('%R%h:%I');`, "+1:30")
+
+	// date_format
+	testInputOutput(t, `<?php echo date_format(date_create('2000-01-01 00:00:00'), 'l jS \of F Y h:i:s A');`, "Saturday 1st of January 2000 12:00:00 AM")
+	testInputOutput(t, `<?php echo date_format(date_create('2021-12-31 13:14:15.123456'), 'o-W N z t L B u v y D M');`, "2021-52 5 364 31 0 593 123456 123 21 Fri Dec")
+	testInputOutput(t, `<?php echo date_format(date_create('2024-07-01', timezone_open('Europe/Berlin')), 'e T I O P p Z U');`, "Europe/Berlin CEST 1 +0200 +02:00 +02:00 7200 1719784800")
+	testInputOutput(t, `<?php echo date_format(date_create('2024-07-01'), DATE_ATOM . '|' . DateTime::RFC3339_EXTENDED . '|' . DateTimeInterface::COOKIE);`,
+		"2024-07-01T00:00:00+00:00|2024-07-01T00:00:00.000+00:00|Monday, 01-Jul-2024 00:00:00 UTC")
+
+	// date_get_last_errors
+	testInputOutput(t, `<?php date_create_from_format('Y-m-d', '2023-02-30'); print_r(date_get_last_errors());`,
+		"Array\n(\n    [warning_count] => 1\n    [warnings] => Array\n        (\n            [10] => The parsed date was invalid\n        )\n\n"+
+			"    [error_count] => 0\n    [errors] => Array\n        (\n        )\n\n)\n",
+	)
+	testInputOutput(t, `<?php date_create('2024-01-01'); var_dump(date_get_last_errors());`, "bool(false)\n")
+
+	// date_interval_create_from_date_string
+	testInputOutput(t, `<?php $i = date_interval_create_from_date_string('3 days 2 hours ago'); echo $i->format('%d %h %R');`, "-3 -2 +")
+
+	// date_interval_format
+	testInputOutput(t, `<?php echo date_interval_format(new DateInterval('P1Y2M3DT4H5M6S'), '%Y-%M-%D %H:%I:%S %F %a %% %x');`, "01-02-03 04:05:06 000000 (unknown) % %x")
+
+	// date_isodate_set
+	testInputOutput(t, `<?php $d = date_create('2024-01-01'); date_isodate_set($d, 2024, 10, 3); echo $d->format('Y-m-d');`, "2024-03-06")
+
+	// date_modify
+	testInputOutput(t, `<?php $d = date_create('2024-01-31 10:00'); date_modify($d, '+1 month -3 hours 30 minutes'); echo $d->format('Y-m-d H:i');`, "2024-03-02 07:30")
+	testInputOutput(t, `<?php $d = date_create('2024-01-01 10:00'); date_modify($d, 'tomorrow noon'); echo $d->format('Y-m-d H:i');`, "2024-01-02 12:00")
+	testInputOutput(t, `<?php $d = date_create('2024-01-01 10:00'); date_modify($d, '2 weeks ago'); echo $d->format('Y-m-d H:i');`, "2023-12-18 10:00")
+	testInputOutput(t, `<?php $d = date_create('2024-01-31 10:00'); date_modify($d, '2024-03-01 Europe/Berlin'); echo $d->format('Y-m-d H:i e');`, "2024-03-01 10:00 UTC")
+	testInputOutput(t, `<?php $d = date_create('2024-01-31 10:00'); date_modify($d, 'last day of next month'); echo $d->format('Y-m-d H:i');`, "2024-02-29 10:00")
+	testInputOutput(t, `<?php var_dump(date_modify(date_create(), 'bogus'));`, fmt.Sprintf(
+		"\nWarning: date_modify(): Failed to parse time string (bogus) at position 0 (b): The timezone could not be found in the database in %s:1:16\nbool(false)\n", TEST_FILE_NAME,
+	))
+
+	// date_offset_get
+	testInputOutput(t, `<?php echo date_offset_get(date_create('2024-01-01', timezone_open('America/New_York')));`, "-18000")
+
+	// date_parse
+	testInputOutput(t, `<?php print_r(date_parse('2024-01-31 10:00:00.5 +1 week'));`,
+		"Array\n(\n    [year] => 2024\n    [month] => 1\n    [day] => 31\n    [hour] => 10\n    [minute] => 0\n    [second] => 0\n    [fraction] => 0.5\n"+
+			"    [warning_count] => 0\n    [warnings] => Array\n        (\n        )\n\n    [error_count] => 0\n    [errors] => Array\n        (\n        )\n\n"+
+			"    [is_localtime] => \n    [relative] => Array\n        (\n            [year] => 0\n            [month] => 0\n            [day] => 7\n"+
+			"            [hour] => 0\n            [minute] => 0\n            [second] => 0\n        )\n\n)\n",
+	)
+	testInputOutput(t, `<?php $p = date_parse('last day of next month CEST'); var_dump($p['year'], $p['zone'], $p['is_dst'], $p['tz_abbr'], $p['relative']['month'], $p['relative']['last_day_of_month']);`,
+		"bool(false)\nint(7200)\nbool(true)\nstring(4) \"CEST\"\nint(1)\nbool(true)\n",
+	)
+	testInputOutput(t, `<?php $p = date_parse('next monday Europe/Berlin'); var_dump($p['hour'], $p['zone_type'], $p['tz_id'], $p['relative']['weekday']);`,
+		"int(0)\nint(3)\nstring(13) \"Europe/Berlin\"\nint(1)\n",
+	)
+	testInputOutput(t, `<?php $p = date_parse('2024-02-30 foo'); print_r($p['warnings']); print_r($p['errors']);`,
+		"Array\n(\n    [14] => The parsed date was invalid\n)\nArray\n(\n    [11] => The timezone could not be found in the database\n)\n",
+	)
+
+	// date_parse_from_format
+	testInputOutput(t, `<?php $p = date_parse_from_format('j.n.Y H:iP', '6.1.2009 13:00+01:00'); echo $p['year'], $p['month'], $p['day'], $p['hour'], $p['zone_type'], $p['zone'], $p['error_count'];`,
+		"20091613136000",
+	)
+
+	// date_sub
+	testInputOutput(t, `<?php $d = date_create('2024-03-31 03:30', timezone_open('Europe/Berlin')); date_sub($d, new DateInterval('PT1H')); echo $d->format('c');`, "2024-03-31T01:30:00+01:00")
+
+	// date_time_set
+	testInputOutput(t, `<?php $d = date_create('2024-01-31'); date_time_set($d, 25, 0); echo $d->format('Y-m-d H:i:s');`, "2024-02-01 01:00:00")
+
+	// date_timestamp_get
+	testInputOutput(t, `<?php echo date_timestamp_get(date_create('2024-01-01 00:00:00 +01:00'));`, "1704063600")
+
+	// date_timestamp_set
+	testInputOutput(t, `<?php $d = date_create('now', timezone_open('Asia/Tokyo')); date_timestamp_set($d, 0); echo $d->format('Y-m-d H:i T');`, "1970-01-01 09:00 JST")
+
+	// date_timezone_get
+	testInputOutput(t, `<?php echo timezone_name_get(date_timezone_get(date_create('2024-01-01 EST')));`, "EST")
+
+	// date_timezone_set
+	testInputOutput(t, `<?php $d = date_create('@1700000000'); date_timezone_set($d, timezone_open('America/New_York')); echo $d->format('Y-m-d H:i:s T');`, "2023-11-14 17:13:20 EST")
+
+	// strftime
+	testInputOutput(t, `<?php date_default_timezone_set('Europe/Berlin'); echo @strftime('%A %e %B %Y, %I:%M %p %Z %z|%j %U %W %V %G|%D %F %T|%c|%%', 1700000000);`,
+		"Tuesday 14 November 2023, 11:13 PM CET +0100|318 46 46 46 2023|11/14/23 2023-11-14 23:13:20|Tue Nov 14 23:13:20 2023|%")
+
+	// strtotime
+	testInputOutput(t, `<?php
+		$base = strtotime('2024-01-31 10:15:30');
+		foreach (['now', 'tomorrow', 'yesterday noon', 'next monday', 'last monday', 'wednesday', 'next wednesday', '+1 week 2 days',
+			'last day of next month', 'first day of next month', 'last day of february', 'first monday of next month', 'last friday of next month',
+			'second tuesday of march 2024', 'this week', 'next week', 'sunday this week', '+3 weekdays', '1 year ago', '10:00 tomorrow', 'tomorrow 10:00',
+			'2010', 'next month'] as $time) {
+			echo date_format(date_create('@' . strtotime($time, $base)), 'D Y-m-d H:i:s'), "\n";
+		}`,
+		"Wed 2024-01-31 10:15:30\nThu 2024-02-01 00:00:00\nTue 2024-01-30 12:00:00\nMon 2024-02-05 00:00:00\nMon 2024-01-29 00:00:00\n"+
+			"Wed 2024-01-31 00:00:00\nWed 2024-02-07 00:00:00\nFri 2024-02-09 10:15:30\nThu 2024-02-29 10:15:30\nThu 2024-02-01 10:15:30\n"+
+			"Thu 2024-02-29 00:00:00\nMon 2024-02-05 00:00:00\nFri 2024-02-23 00:00:00\nTue 2024-03-12 00:00:00\nMon 2024-01-29 10:15:30\n"+
+			"Mon 2024-02-05 10:15:30\nSun 2024-02-04 00:00:00\nMon 2024-02-05 10:15:30\nTue 2023-01-31 10:15:30\nThu 2024-02-01 00:00:00\n"+
+			"Thu 2024-02-01 10:00:00\nWed 2024-01-31 20:10:00\nSat 2024-03-02 10:15:30\n",
+	)
+	testInputOutput(t, `<?php
+		foreach (['@1700000000', '2024-02-29T12:00:00Z', 'Sat, 13 Jan 2024 10:00:00 +0100', 'Saturday, 13-Jan-24 10:00:00 UTC', 'January 5th, 2024',
+			'5 January 2024 3pm', '2024W05', '2024-W05-3', '2024.032', '24-01-31', '31.01.2024', '2024-Jan-05', '20240131T103000'] as $time) {
+			echo date_format(date_create('@' . strtotime($time)), 'Y-m-d H:i:s'), "\n";
+		}`,
+		"2023-11-14 22:13:20\n2024-02-29 12:00:00\n2024-01-13 09:00:00\n2024-01-13 10:00:00\n2024-01-05 00:00:00\n2024-01-05 15:00:00\n"+
+			"2024-01-29 00:00:00\n2024-01-31 00:00:00\n2024-02-01 00:00:00\n2024-01-31 00:00:00\n2024-01-31 00:00:00\n2024-01-05 00:00:00\n2024-01-31 10:30:00\n",
+	)
+	testInputOutput(t, `<?php var_dump(strtotime(''), strtotime('foo'));`, "bool(false)\nbool(false)\n")
+
+	// timezone_identifiers_list
+	testInputOutput(t, `<?php $l = timezone_identifiers_list(); var_dump(in_array('Europe/Berlin', $l), in_array('UTC', $l), $l === DateTimeZone::listIdentifiers());`,
+		"bool(true)\nbool(true)\nbool(true)\n")
+	testInputOutput(t, `<?php print_r(timezone_identifiers_list(DateTimeZone::PER_COUNTRY, 'de')); print_r(DateTimeZone::listIdentifiers(DateTimeZone::UTC | DateTimeZone::ARCTIC));`,
+		"Array\n(\n    [0] => Europe/Berlin\n    [1] => Europe/Busingen\n)\nArray\n(\n    [0] => Arctic/Longyearbyen\n    [1] => UTC\n)\n")
+	testForError(t, `<?php timezone_identifiers_list(DateTimeZone::PER_COUNTRY);`,
+		phpError.NewError("Uncaught ValueError: timezone_identifiers_list(): Argument #2 ($countryCode) must be a two-letter ISO 3166-1 compatible country code when argument #1 ($timezoneGroup) is DateTimeZone::PER_COUNTRY"))
+	testForError(t, `<?php timezone_identifiers_list(0);`,
+		phpError.NewError("Uncaught ValueError: timezone_identifiers_list(): Argument #1 ($timezoneGroup) must be one of the DateTimeZone group constants"))
+
+	// timezone_name_get
+	testInputOutput(t, `<?php echo timezone_name_get(timezone_open('+05:30')), timezone_name_get(timezone_open('utc'));`, "+05:30UTC")
+
+	// timezone_offset_get
+	testInputOutput(t, `<?php echo timezone_offset_get(timezone_open('Europe/Berlin'), date_create('2024-07-01'));`, "7200")
+	testForError(t, `<?php timezone_offset_get(timezone_open('UTC'), 1);`,
+		phpError.NewError("Uncaught TypeError: timezone_offset_get(): Argument #2 ($datetime) must be of type DateTimeInterface, int given"))
+
+	// timezone_open
+	testInputOutput(t, `<?php var_dump(timezone_open('Nope'));`, fmt.Sprintf("\nWarning: timezone_open(): Unknown or bad timezone (Nope) in %s:1:16\nbool(false)\n", TEST_FILE_NAME))
+
+	// DateTime
+	testInputOutput(t, `<?php $d = new DateTime('2024-01-31'); $r = $d->modify('+1 day'); echo $d->format('Y-m-d'), $r === $d ? ' same' : ' copy';`, "2024-02-01 same")
+	testInputOutput(t, `<?php $d = new DateTime('2024-05-05 05:05:05'); $d->setDate(2023, 2, 29)->setTime(1, 2, 3, 4); echo $d->format('Y-m-d H:i:s.u');`, "2023-03-01 01:02:03.000004")
+	testInputOutput(t, `<?php $d = DateTime::createFromImmutable(new DateTimeImmutable('2024-01-01')); echo get_class($d), $d->getTimestamp();`, "DateTime1704067200")
+	testInputOutput(t, `<?php try { new DateTime('foo'); } catch (DateMalformedStringException $e) { echo $e->getMessage(); }`,
+		"Failed to parse time string (foo) at position 0 (f): The timezone could not be found in the database")
+	testInputOutput(t, `<?php try { $d = new DateTime(); $d->modify('10:00 ?'); } catch (DateException $e) { echo $e->getMessage(); }`,
+		"DateTime::modify(): Failed to parse time string (10:00 ?) at position 6 (?): Unexpected character")
+	testForError(t, `<?php $d = new DateTime(); $d->setMicrosecond(1000000);`,
+		phpError.NewError("Uncaught ValueError: DateTime::setMicrosecond(): Argument #1 ($microsecond) must be between 0 and 999999, 1000000 given"))
+	testForError(t, `<?php new DateTime('now', 'UTC');`,
+		phpError.NewError("Uncaught TypeError: DateTime::__construct(): Argument #2 ($timezone) must be of type ?DateTimeZone, string given"))
+
+	// DateTimeImmutable
+	testInputOutput(t, `<?php $d = new DateTimeImmutable('2020-02-29'); $e = $d->add(new DateInterval('P1Y')); echo $d->format('Y-m-d'), ' ', $e->format('Y-m-d');`, "2020-02-29 2021-03-01")
+	testInputOutput(t, `<?php class D extends DateTimeImmutable {} $d = new D('2024-05-05 05:05:05.123456'); $e = $d->sub(new DateInterval('PT5H')); echo get_class($e), $e->format(' Y-m-d H:i:s.u');`, "D 2024-05-05 00:05:05.123456")
+	testInputOutput(t, `<?php $d = DateTimeImmutable::createFromFormat('Y-m-d H:i:s', '2024-01-01 12:00:00', new DateTimeZone('Asia/Tokyo')); echo get_class($d), $d->format(' c');`, "DateTimeImmutable 2024-01-01T12:00:00+09:00")
+	testInputOutput(t, `<?php $d = DateTimeImmutable::createFromMutable(new DateTime('2024-01-01 UTC')); echo $d->getTimezone()->getName(), $d->getOffset(), $d->getMicrosecond();`, "UTC00")
+	testInputOutput(t, `<?php $d = DateTimeImmutable::createFromFormat('!H:i', '25:01'); $e = DateTimeImmutable::getLastErrors(); echo $d->format('d H:i '), $e['warnings'][5];`,
+		"02 01:01 The parsed time was invalid")
+	testInputOutput(t, `<?php var_dump(DateTimeImmutable::createFromFormat('H:i', '10:1'));`, "bool(false)\n")
+
+	// DateTimeInterface comparison
+	testInputOutput(t, `<?php $a = new DateTime('2024-01-01 12:00:00', new DateTimeZone('UTC')); $b = new DateTime('2024-01-01 13:00:00', new DateTimeZone('Europe/Berlin')); var_dump($a == $b, $a != $b, $a <=> $b);`,
+		"bool(true)\nbool(false)\nint(0)\n")
+	testInputOutput(t, `<?php $a = new DateTime('2024-01-01 12:00:00', new DateTimeZone('UTC')); $b = new DateTimeImmutable('2024-01-01 12:00:00.5', new DateTimeZone('Asia/Tokyo')); var_dump($a < $b, $a > $b, $b > $a, $a <=> $b, $b <=> $a);`,
+		"bool(false)\nbool(true)\nbool(false)\nint(1)\nint(-1)\n")
+	testInputOutput(t, `<?php $a = new DateTime('2024-01-01 12:00:00.000001', new DateTimeZone('America/New_York')); $b = new DateTimeImmutable('2024-01-01 17:00:00', new DateTimeZone('UTC')); var_dump($a > $b, $a == $b, $a <= $b);`,
+		"bool(true)\nbool(false)\nbool(false)\n")
+	testInputOutput(t, `<?php class Carbon extends DateTime {} $a = new Carbon('2024-01-02', new DateTimeZone('UTC')); $b = new DateTime('2024-01-01', new DateTimeZone('UTC')); var_dump($a > $b, $b < $a);`,
+		"bool(true)\nbool(true)\n")
+
+	// DateInterval
+	testInputOutput(t, `<?php var_dump(new DateInterval('P2W3DT1S'));`,
+		"object(DateInterval)#1 (10) {\n  [\"y\":\"DateInterval\":public]=>\n  int(0)\n  [\"m\":\"DateInterval\":public]=>\n  int(0)\n"+
+			"  [\"d\":\"DateInterval\":public]=>\n  int(17)\n  [\"h\":\"DateInterval\":public]=>\n  int(0)\n  [\"i\":\"DateInterval\":public]=>\n  int(0)\n"+
+			"  [\"s\":\"DateInterval\":public]=>\n  int(1)\n  [\"f\":\"DateInterval\":public]=>\n  float(0)\n  [\"invert\":\"DateInterval\":public]=>\n  int(0)\n"+
+			"  [\"days\":\"DateInterval\":public]=>\n  bool(false)\n  [\"from_string\":\"DateInterval\":public]=>\n  bool(false)\n}\n",
+	)
+	testInputOutput(t, `<?php $i = new DateInterval('P1D'); $i->invert = 1; $d = new DateTime('2024-01-01'); $d->add($i); echo $d->format('Y-m-d');`, "2023-12-31")
+	testInputOutput(t, `<?php try { new DateInterval('P1X'); } catch (DateMalformedIntervalStringException $e) { echo $e->getMessage(); }`, "Unknown or bad format (P1X)")
+	testInputOutput(t, `<?php var_dump(DateInterval::createFromDateString('foo'));`,
+		"\nWarning: DateInterval::createFromDateString(): Unknown or bad format (foo) at position 0 (f): The timezone could not be found in the database\nbool(false)\n",
+	)
+
+	// DatePeriod
+	testInputOutput(t, `<?php $p = new DatePeriod(new DateTime('2024-01-01'), new DateInterval('P1W'), 3); foreach ($p as $k => $d) { echo $k, $d->format(' Y-m-d,'); }`,
+		"0 2024-01-01,1 2024-01-08,2 2024-01-15,3 2024-01-22,")
+	testInputOutput(t, `<?php $p = new DatePeriod(new DateTimeImmutable('2024-01-01'), new DateInterval('P1D'), new DateTime('2024-01-04'), DatePeriod::EXCLUDE_START_DATE); foreach ($p as $d) { echo get_class($d), $d->format(' d,'); }`,
+		"DateTimeImmutable 02,DateTimeImmutable 03,")
+	testInputOutput(t, `<?php $p = new DatePeriod(new DateTime('2024-01-01'), new DateInterval('P1D'), new DateTime('2024-01-03'), DatePeriod::INCLUDE_END_DATE); foreach ($p as $d) { echo $d->format('d,'); } var_dump($p->getRecurrences());`,
+		"01,02,03,NULL\n")
+	testInputOutput(t, `<?php $p = new DatePeriod('R3/2024-01-31T00:00:00Z/P1M'); foreach ($p as $d) { echo $d->format('m-d,'); } echo $p->getRecurrences(), $p->getStartDate()->format(' e');`,
+		"01-31,03-02,04-02,05-02,3 Z")
+	testForError(t, `<?php new DatePeriod('R3/2024-01-31/X');`,
+		phpError.NewError("Uncaught DateMalformedPeriodStringException: DatePeriod::__construct(): Unknown or bad format (R3/2024-01-31/X)"))
+
+	// DateTimeZone
+	testInputOutput(t, `<?php $tz = new DateTimeZone('America/New_York'); echo $tz->getName(), ' ', $tz->getOffset(new DateTime('2024-07-01')), ' ', DateTimeZone::EUROPE;`, "America/New_York -14400 128")
+	testInputOutput(t, `<?php var_dump(new DateTimeZone('CEST'));`,
+		"object(DateTimeZone)#1 (2) {\n  [\"timezone_type\":\"DateTimeZone\":public]=>\n  int(2)\n  [\"timezone\":\"DateTimeZone\":public]=>\n  string(4) \"CEST\"\n}\n",
+	)
+	testForError(t, `<?php new DateTimeZone('Mars/Base');`,
+		phpError.NewError("Uncaught DateInvalidTimeZoneException: DateTimeZone::__construct(): Unknown or bad timezone (Mars/Base)"))
 }
 
 // -------------------------------------- strings -------------------------------------- MARK: strings
 
 func TestLibStrings(t *testing.T) {
+	// addcslashes
+	testInputOutput(t, `<?php var_dump(addcslashes('foo[bar]', 'A..Z'));`, "string(8) \"foo[bar]\"\n")
+	testInputOutput(t, `<?php var_dump(addcslashes("zoo['.']\n", 'z..A'));`, fmt.Sprintf("\nWarning: addcslashes(): Invalid '..'-range, '..'-range needs to be incrementing in %s:1:16\nstring(11) \"\\zoo['\\.']\n\"\n", TEST_FILE_NAME))
+	testInputOutput(t, `<?php var_dump(addcslashes("a\tb" . chr(200), chr(0) . '..' . chr(31) . chr(200)));`, "string(8) \"a\\tb\\310\"\n")
+
+	// addslashes
+	testInputOutput(t, `<?php var_dump(addslashes("O'Reilly \"\\"));`, "string(14) \"O\\'Reilly \\\"\\\\\"\n")
+
 	// bin2hex
 	testInputOutput(t, `<?php var_dump(bin2hex('Hello world!'));`, "string(24) \"48656c6c6f20776f726c6421\"\n")
 	testInputOutput(t, `<?php var_dump(bin2hex('Äàßê'));`, "string(16) \"c384c3a0c39fc3aa\"\n")
@@ -192,23 +851,86 @@ func TestLibStrings(t *testing.T) {
 	testInputOutput(t, `<?php var_dump(chr(60-256));`, "string(1) \"<\"\n")
 	testInputOutput(t, `<?php var_dump(chr(60+256));`, "string(1) \"<\"\n")
 
+	// chunk_split
+	testInputOutput(t, `<?php var_dump(chunk_split('abcdefg', 3, '|'));`, "string(10) \"abc|def|g|\"\n")
+	testForError(t, `<?php chunk_split('abc', 0);`, phpError.NewError("Uncaught ValueError: chunk_split(): Argument #2 ($length) must be greater than 0"))
+
+	// count_chars
+	testInputOutput(t, `<?php var_dump(count_chars('Two Ts and one F.', 3));`, "string(11) \" .FTadenosw\"\n")
+	testInputOutput(t, `<?php var_dump(count_chars('abca', 1));`, "array(3) {\n  [97]=>\n  int(2)\n  [98]=>\n  int(1)\n  [99]=>\n  int(1)\n}\n")
+	testForError(t, `<?php count_chars('abc', 5);`, phpError.NewError("Uncaught ValueError: count_chars(): Argument #2 ($mode) must be between 0 and 4 (inclusive)"))
+
+	// crc32
+	testInputOutput(t, `<?php var_dump(crc32('The quick brown fox jumped over the lazy dog.'));`, "int(2191738434)\n")
+
+	// explode
+	testInputOutput(t, `<?php var_dump(explode(',', 'a,b,c'));`, "array(3) {\n  [0]=>\n  string(1) \"a\"\n  [1]=>\n  string(1) \"b\"\n  [2]=>\n  string(1) \"c\"\n}\n")
+	testInputOutput(t, `<?php var_dump(explode(',', 'a,b,c', 2));`, "array(2) {\n  [0]=>\n  string(1) \"a\"\n  [1]=>\n  string(3) \"b,c\"\n}\n")
+	testInputOutput(t, `<?php var_dump(explode(',', 'a,b,c', -2));`, "array(1) {\n  [0]=>\n  string(1) \"a\"\n}\n")
+	testInputOutput(t, `<?php var_dump(explode(',', 'a,b,c', 0));`, "array(1) {\n  [0]=>\n  string(5) \"a,b,c\"\n}\n")
+	testInputOutput(t, `<?php var_dump(explode(',', ''));`, "array(1) {\n  [0]=>\n  string(0) \"\"\n}\n")
+	testInputOutput(t, `<?php var_dump(explode(',', '', -1));`, "array(0) {\n}\n")
+	testForError(t, `<?php explode('', 'abc');`, phpError.NewError("Uncaught ValueError: explode(): Argument #1 ($separator) cannot be empty"))
+
+	// get_html_translation_table
+	testInputOutput(t, `<?php var_dump(get_html_translation_table(HTML_SPECIALCHARS, ENT_NOQUOTES));`, "array(3) {\n  [\"&\"]=>\n  string(5) \"&amp;\"\n  [\"<\"]=>\n  string(4) \"&lt;\"\n  [\">\"]=>\n  string(4) \"&gt;\"\n}\n")
+
 	// hex2bin
 	testInputOutput(t, `<?php var_dump(hex2bin(''));`, "string(0) \"\"\n")
 	testInputOutput(t, `<?php var_dump(hex2bin('6578616d706c65206865782064617461'));`, "string(16) \"example hex data\"\n")
 	testInputOutput(t, `<?php var_dump(hex2bin('6'));`, fmt.Sprintf("\nWarning: Hexadecimal input string must have an even length in %s:1:24\nbool(false)\n", TEST_FILE_NAME))
 
+	// html_entity_decode
+	testInputOutput(t, `<?php var_dump(html_entity_decode('caf&eacute; &copy; &euro; &lt;b&gt; &#39; &#x263A; &unknown;'));`, "string(32) \"café © € <b> ' ☺ &unknown;\"\n")
+	testInputOutput(t, `<?php var_dump(html_entity_decode('&apos; &NotEqualTilde; &quot;'));`, "string(24) \"&apos; &NotEqualTilde; \"\"\n")
+	testInputOutput(t, `<?php var_dump(html_entity_decode('&apos; &quot;', ENT_NOQUOTES | ENT_HTML5));`, "string(13) \"&apos; &quot;\"\n")
+	testInputOutput(t, `<?php var_dump(html_entity_decode('&apos; &hearts;', ENT_QUOTES | ENT_HTML5));`, "string(5) \"' ♥\"\n")
+
+	// htmlentities
+	testInputOutput(t, `<?php var_dump(htmlentities('café © € <b>'));`, "string(35) \"caf&eacute; &copy; &euro; &lt;b&gt;\"\n")
+	testInputOutput(t, `<?php var_dump(htmlentities("a\n!'", ENT_QUOTES | ENT_HTML5));`, "string(22) \"a&NewLine;&excl;&apos;\"\n")
+	testInputOutput(t, `<?php var_dump(htmlentities('é', ENT_XML1));`, "string(2) \"é\"\n")
+
+	// htmlspecialchars
+	testInputOutput(t, `<?php var_dump(htmlspecialchars("<a href='test'>Test & \"x\"</a>"));`, "string(65) \"&lt;a href=&#039;test&#039;&gt;Test &amp; &quot;x&quot;&lt;/a&gt;\"\n")
+	testInputOutput(t, `<?php var_dump(htmlspecialchars("'\"", ENT_NOQUOTES));`, "string(2) \"'\"\"\n")
+	testInputOutput(t, `<?php var_dump(htmlspecialchars("'\"", ENT_COMPAT));`, "string(7) \"'&quot;\"\n")
+	testInputOutput(t, `<?php var_dump(htmlspecialchars("'", ENT_QUOTES | ENT_HTML5));`, "string(6) \"&apos;\"\n")
+	testInputOutput(t, `<?php var_dump(htmlspecialchars('a &amp; b &foo; &#65; &#xZZ;', ENT_QUOTES, 'UTF-8', false));`, "string(36) \"a &amp; b &amp;foo; &#65; &amp;#xZZ;\"\n")
+	testInputOutput(t, `<?php var_dump(htmlspecialchars('a' . chr(128) . 'b'));`, "string(5) \"a\uFFFDb\"\n")
+	testInputOutput(t, `<?php var_dump(htmlspecialchars('a' . chr(128) . 'b', ENT_QUOTES));`, "string(0) \"\"\n")
+	testInputOutput(t, `<?php var_dump(htmlspecialchars('a' . chr(128) . 'b', ENT_IGNORE));`, "string(2) \"ab\"\n")
+	testInputOutput(t, `<?php var_dump(htmlspecialchars('<', ENT_QUOTES, 'ABC'));`, fmt.Sprintf("\nWarning: htmlspecialchars(): Charset \"ABC\" is not supported, assuming UTF-8 in %s:1:16\nstring(4) \"&lt;\"\n", TEST_FILE_NAME))
+
+	// htmlspecialchars_decode
+	testInputOutput(t, `<?php var_dump(htmlspecialchars_decode('&lt;p&gt; &amp;amp; &#039; &quot; &eacute;'));`, "string(22) \"<p> &amp; ' \" &eacute;\"\n")
+	testInputOutput(t, `<?php var_dump(htmlspecialchars_decode('&quot; &#039;', ENT_NOQUOTES));`, "string(13) \"&quot; &#039;\"\n")
+
 	// lcfirst
 	testInputOutput(t, `<?php var_dump(lcfirst('ABC'));`, "string(3) \"aBC\"\n")
 	testInputOutput(t, `<?php var_dump(lcfirst('Abc'));`, "string(3) \"abc\"\n")
 	testInputOutput(t, `<?php var_dump(lcfirst('abc'));`, "string(3) \"abc\"\n")
 	testInputOutput(t, `<?php var_dump(lcfirst(''));`, "string(0) \"\"\n")
 
+	// levenshtein
+	testInputOutput(t, `<?php var_dump(levenshtein('kitten', 'sitting'));`, "int(3)\n")
+	testInputOutput(t, `<?php var_dump(levenshtein('', 'abc'));`, "int(3)\n")
+	testInputOutput(t, `<?php var_dump(levenshtein('abc', 'abd', 1, 5, 1));`, "int(2)\n")
+
+	// ltrim
+	testInputOutput(t, `<?php var_dump(ltrim("  \tabc  "));`, "string(5) \"abc  \"\n")
+	testInputOutput(t, `<?php var_dump(ltrim('0012300', '0'));`, "string(5) \"12300\"\n")
+
 	// md5
 	testInputOutput(t, `<?php var_dump(md5('apple'));`, "string(32) \"1f3870be274f6c49b3e31a0c6728957f\"\n")
 	testInputOutput(t, `<?php var_dump(bin2hex(md5('apple', true)));`, "string(32) \"1f3870be274f6c49b3e31a0c6728957f\"\n")
 	testInputOutput(t, `<?php var_dump(md5('hello world'));`, "string(32) \"5eb63bbbe01eeed093cb22bb8f5acdc3\"\n")
 	testInputOutput(t, `<?php var_dump(bin2hex(md5('hello world', true)));`, "string(32) \"5eb63bbbe01eeed093cb22bb8f5acdc3\"\n")
 
+	// metaphone
+	testInputOutput(t, `<?php var_dump(metaphone('Knight'));`, "string(3) \"NFT\"\n")
+	testInputOutput(t, `<?php var_dump(metaphone('Thumb'));`, "string(2) \"0M\"\n")
+
 	// nl2br
 	testInputOutput(t, "<?php var_dump(nl2br(\"a\nb\"));", "string(9) \"a<br />\nb\"\n")
 	testInputOutput(t, "<?php var_dump(nl2br(\"c\nd\", false));", "string(7) \"c<br>\nd\"\n")
@@ -223,17 +945,89 @@ func TestLibStrings(t *testing.T) {
 	testInputOutput(t, "<?php var_dump(nl2br(\"v\n\nw\"));", "string(16) \"v<br />\n<br />\nw\"\n")
 	testInputOutput(t, "<?php var_dump(nl2br(\"x\n\ny\", false));", "string(12) \"x<br>\n<br>\ny\"\n")
 
+	// number_format
+	testInputOutput(t, `<?php var_dump(number_format(1234567.891));`, "string(9) \"1,234,568\"\n")
+	testInputOutput(t, `<?php var_dump(number_format(1234567.891, 2));`, "string(12) \"1,234,567.89\"\n")
+	testInputOutput(t, `<?php var_dump(number_format(1234567.891, 2, ',', '.'));`, "string(12) \"1.234.567,89\"\n")
+	testInputOutput(t, `<?php var_dump(number_format(-1234.567, 2, '.', ' '));`, "string(9) \"-1 234.57\"\n")
+	testInputOutput(t, `<?php var_dump(number_format(0.5));`, "string(1) \"1\"\n")
+	testInputOutput(t, `<?php var_dump(number_format(-0.4));`, "string(1) \"0\"\n")
+	testInputOutput(t, `<?php var_dump(number_format(1.005, 2));`, "string(4) \"1.01\"\n")
+	testInputOutput(t, `<?php var_dump(number_format(1234.5, -2));`, "string(5) \"1,200\"\n")
+	testInputOutput(t, `<?php var_dump(number_format(1000, 2, null, null));`, "string(8) \"1,000.00\"\n")
+
+	// ord
+	testInputOutput(t, `<?php var_dump(ord('A'));`, "int(65)\n")
+	testInputOutput(t, `<?php var_dump(ord(chr(200)));`, "int(200)\n")
+	testInputOutput(t, `<?php var_dump(ord(''));`, "int(0)\n")
+
+	// parse_str
+	testInputOutput(t, `<?php parse_str('first=value&arr[]=foo+bar&arr[]=baz', $output); var_dump($output);`, "array(2) {\n  [\"first\"]=>\n  string(5) \"value\"\n  [\"arr\"]=>\n  array(2) {\n    [0]=>\n    string(7) \"foo bar\"\n    [1]=>\n    string(3) \"baz\"\n  }\n}\n")
+	testInputOutput(t, `<?php parse_str('a.b=1&c d=2&e[=3&f[x]y=4&g[0]=5', $output); var_dump($output);`, "array(5) {\n  [\"a_b\"]=>\n  string(1) \"1\"\n  [\"c_d\"]=>\n  string(1) \"2\"\n  [\"e_\"]=>\n  string(1) \"3\"\n  [\"f\"]=>\n  array(1) {\n    [\"x\"]=>\n    string(1) \"4\"\n  }\n  [\"g\"]=>\n  array(1) {\n    [0]=>\n    string(1) \"5\"\n  }\n}\n")
+
+	// printf
+	testInputOutput(t, `<?php $n = printf("%s: %05.1f\n", "value", 3.14159); var_dump($n);`, "value: 003.1\nint(13)\n")
+
 	// quotemeta
 	testInputOutput(t, `<?php var_dump(quotemeta('. \ + * ? [ ^ ] ( $ )'));`, `string(31) "\. \\ \+ \* \? \[ \^ ] \( \$ \)"`+"\n")
 	testInputOutput(t, `<?php var_dump(quotemeta('Hello. (can you hear me?)'));`, `string(29) "Hello\. \(can you hear me\?\)"`+"\n")
 	testInputOutput(t, `<?php var_dump(quotemeta(''));`, "bool(false)\n")
 
+	// rtrim
+	testInputOutput(t, `<?php var_dump(rtrim('abc123', '0..9'));`, "string(3) \"abc\"\n")
+	testInputOutput(t, `<?php var_dump(chop("abc \n"));`, "string(3) \"abc\"\n")
+	testInputOutput(t, `<?php var_dump(rtrim('abc', '..a'));`, fmt.Sprintf("\nWarning: rtrim(): Invalid '..'-range, no character to the left of '..' in %s:1:16\nstring(3) \"abc\"\n", TEST_FILE_NAME))
+
 	// sha1
 	testInputOutput(t, `<?php var_dump(sha1('apple'));`, "string(40) \"d0be2dc421be4fcd0172e5afceea3970e2f3d940\"\n")
 	testInputOutput(t, `<?php var_dump(bin2hex(sha1('apple', true)));`, "string(40) \"d0be2dc421be4fcd0172e5afceea3970e2f3d940\"\n")
 	testInputOutput(t, `<?php var_dump(sha1('hello world'));`, "string(40) \"2aae6c35c94fcfb415dbe95f408b9ce91ee846ed\"\n")
 	testInputOutput(t, `<?php var_dump(bin2hex(sha1('hello world', true)));`, "string(40) \"2aae6c35c94fcfb415dbe95f408b9ce91ee846ed\"\n")
 
+	// similar_text
+	testInputOutput(t, `<?php var_dump(similar_text('World', 'Word', $percent)); var_dump($percent);`, "int(4)\nfloat(88.88888888888889)\n")
+	testInputOutput(t, `<?php var_dump(similar_text('bafoobar', 'barfoo'));`, "int(5)\n")
+	testInputOutput(t, `<?php var_dump(similar_text('barfoo', 'bafoobar'));`, "int(3)\n")
+
+	// soundex
+	testInputOutput(t, `<?php var_dump(soundex('Robert'));`, "string(4) \"R163\"\n")
+	testInputOutput(t, `<?php var_dump(soundex('Tymczak'));`, "string(4) \"T522\"\n")
+	testInputOutput(t, `<?php var_dump(soundex(''));`, "string(0) \"\"\n")
+
+	// sprintf
+	testInputOutput(t, `<?php var_dump(sprintf("%05d|%-5d|%+d|%+05d|%u", -42, 42, 7, 7, -1));`, "string(41) \"-0042|42   |+7|+0007|18446744073709551615\"\n")
+	testInputOutput(t, `<?php var_dump(sprintf("%'*10s|%-10s|%'#-6s|%.3s", "hi", "left", "ab", "abcdef"));`, "string(32) \"********hi|left      |ab####|abc\"\n")
+	testInputOutput(t, `<?php var_dump(sprintf('%2$s %1$s %2$s', 'a', 'b'));`, "string(5) \"b a b\"\n")
+	testInputOutput(t, `<?php var_dump(sprintf("%b %o %x %X %c %%", 10, 8, 255, 255, 65));`, "string(17) \"1010 10 ff FF A %\"\n")
+	testInputOutput(t, `<?php var_dump(sprintf("%x", -1));`, "string(16) \"ffffffffffffffff\"\n")
+	testInputOutput(t, `<?php var_dump(sprintf("%e|%.2E|%.0e|%e", 1234.5678, 0.000123, 12345, 0));`, "string(36) \"1.234568e+3|1.23E-4|1e+4|0.000000e+0\"\n")
+	testInputOutput(t, `<?php var_dump(sprintf("%f|%.3F|%5.1f|%-8.2f|%08.3f|%'#10.2f", 1.5, -2.0005, 3.14159, 2.5, -3.14159, 1.005));`, "string(50) \"1.500000|-2.001|  3.1|2.50    |-003.142|######1.01\"\n")
+	testInputOutput(t, `<?php var_dump(sprintf("%g|%G|%g|%.3g", 0.00001234, 1e20, 100000, 3.14159));`, "string(28) \"1.234e-5|1.0E+20|100000|3.14\"\n")
+	testInputOutput(t, `<?php var_dump(sprintf("%s|%s|%s|%s", 1.0, 0.1 + 0.2, 1e25, -1.5e-7));`, "string(21) \"1|0.3|1.0E+25|-1.5E-7\"\n")
+	testInputOutput(t, `<?php ini_set('precision', 17); var_dump(sprintf("%s", 0.1 + 0.2));`, "string(19) \"0.30000000000000004\"\n")
+	testInputOutput(t, `<?php var_dump(sprintf("%*d|%-*d|%.*f", 5, 1, 4, 2, 2, 3.14159));`, "string(15) \"    1|2   |3.14\"\n")
+	testInputOutput(t, `<?php var_dump(sprintf("%d %d %d %d", "12abc", true, 3.99, null));`, "string(8) \"12 1 3 0\"\n")
+	testInputOutput(t, `<?php var_dump(sprintf("%.60f", 1));`,
+		fmt.Sprintf("\nNotice: sprintf(): Requested precision of 60 digits was truncated to PHP maximum of 53 digits in %s:1:16\n", TEST_FILE_NAME)+
+			"string(55) \"1.00000000000000000000000000000000000000000000000000000\"\n",
+	)
+	testForError(t, `<?php sprintf("%s %s", "a");`, phpError.NewError("Uncaught ArgumentCountError: 3 arguments are required, 2 given"))
+	testForError(t, `<?php sprintf('%0$s', "a");`, phpError.NewError("Uncaught ValueError: Argument number specifier must be greater than zero and less than 2147483647"))
+	testForError(t, `<?php sprintf("%y", "a");`, phpError.NewError(`Uncaught ValueError: Unknown format specifier "y"`))
+	testForError(t, `<?php sprintf("abc%", "a");`, phpError.NewError("Uncaught ValueError: Missing format specifier at end of string"))
+	testForError(t, `<?php sprintf("%'", "a");`, phpError.NewError("Uncaught ValueError: Missing padding character"))
+
+	// sscanf
+	testInputOutput(t, `<?php var_dump(sscanf("age: 25 name: Bob", "age: %d name: %s"));`, "array(2) {\n  [0]=>\n  int(25)\n  [1]=>\n  string(3) \"Bob\"\n}\n")
+	testInputOutput(t, `<?php var_dump(sscanf("12 apples", "%d %s %s"));`, "array(3) {\n  [0]=>\n  int(12)\n  [1]=>\n  string(6) \"apples\"\n  [2]=>\n  NULL\n}\n")
+	testInputOutput(t, `<?php var_dump(sscanf("", "%d"));`, "NULL\n")
+	testInputOutput(t, `<?php var_dump(sscanf("0x1A ff 017", "%i %x %o"));`, "array(3) {\n  [0]=>\n  int(26)\n  [1]=>\n  int(255)\n  [2]=>\n  int(15)\n}\n")
+	testInputOutput(t, `<?php var_dump(sscanf("abc123def", "%[a-z]%d%[^0-9]"));`, "array(3) {\n  [0]=>\n  string(3) \"abc\"\n  [1]=>\n  int(123)\n  [2]=>\n  string(3) \"def\"\n}\n")
+	testInputOutput(t, `<?php var_dump(sscanf("3.5e2 x", "%f %c"));`, "array(2) {\n  [0]=>\n  float(350)\n  [1]=>\n  string(1) \"x\"\n}\n")
+	testInputOutput(t, `<?php $c = sscanf("2024-01-05", "%d-%d-%d", $y, $m, $d); var_dump($c, $y, $m, $d);`, "int(3)\nint(2024)\nint(1)\nint(5)\n")
+	testInputOutput(t, `<?php var_dump(sscanf("", "%d", $a));`, "int(-1)\n")
+	testForError(t, `<?php sscanf("1 2", "%d %d", $a);`, phpError.NewError("Uncaught ValueError: Different numbers of variable names and field specifiers"))
+
 	// str_contains
 	testInputOutput(t, `<?php var_dump(str_contains('abc', ''));`, "bool(true)\n")
 	testInputOutput(t, `<?php var_dump(str_contains('The lazy fox', 'lazy'));`, "bool(true)\n")
@@ -244,22 +1038,136 @@ func TestLibStrings(t *testing.T) {
 	testInputOutput(t, `<?php var_dump(str_ends_with('The lazy fox', 'fox'));`, "bool(true)\n")
 	testInputOutput(t, `<?php var_dump(str_ends_with('The lazy fox', 'Fox'));`, "bool(false)\n")
 
+	// str_ireplace
+	testInputOutput(t, `<?php var_dump(str_ireplace('WORLD', 'there', 'Hello world, World!', $count)); var_dump($count);`, "string(19) \"Hello there, there!\"\nint(2)\n")
+
+	// str_pad
+	testInputOutput(t, `<?php var_dump(str_pad('5', 3, '0', STR_PAD_LEFT));`, "string(3) \"005\"\n")
+	testInputOutput(t, `<?php var_dump(str_pad('ab', 7, '-=', STR_PAD_BOTH));`, "string(7) \"-=ab-=-\"\n")
+	testInputOutput(t, `<?php var_dump(str_pad('ab', 6, 'xy'));`, "string(6) \"abxyxy\"\n")
+	testInputOutput(t, `<?php var_dump(str_pad('abc', 2));`, "string(3) \"abc\"\n")
+	testForError(t, `<?php str_pad('abc', 5, '');`, phpError.NewError("Uncaught ValueError: str_pad(): Argument #3 ($pad_string) must be a non-empty string"))
+	testForError(t, `<?php str_pad('abc', 5, ' ', 3);`, phpError.NewError("Uncaught ValueError: str_pad(): Argument #4 ($pad_type) must be STR_PAD_LEFT, STR_PAD_RIGHT, or STR_PAD_BOTH"))
+
 	// str_repeat
 	testInputOutput(t, `<?php var_dump(str_repeat('abc', 0));`, "string(0) \"\"\n")
 	testInputOutput(t, `<?php var_dump(str_repeat('abc', 1));`, "string(3) \"abc\"\n")
 	testInputOutput(t, `<?php var_dump(str_repeat('abc', 2));`, "string(6) \"abcabc\"\n")
 	testForError(t, `<?php var_dump(str_repeat('abc', -1));`, phpError.NewError("Uncaught ValueError: str_repeat(): Argument #2 ($times) must be greater than or equal to 0"))
 
+	// str_replace
+	testInputOutput(t, `<?php var_dump(str_replace('l', 'L', 'Hello', $count)); var_dump($count);`, "string(5) \"HeLLo\"\nint(2)\n")
+	testInputOutput(t, `<?php var_dump(str_replace(['a', 'b'], ['b', 'c'], 'ab'));`, "string(2) \"cc\"\n")
+	testInputOutput(t, `<?php var_dump(str_replace(['a', 'e', ''], ['A'], 'banane'));`, "string(5) \"bAnAn\"\n")
+	testInputOutput(t, `<?php var_dump(str_replace('o', '0', ['x' => 'foo', 'y' => 5]));`, "array(2) {\n  [\"x\"]=>\n  string(3) \"f00\"\n  [\"y\"]=>\n  string(1) \"5\"\n}\n")
+	testForError(t, `<?php str_replace('a', ['b'], 'abc');`, phpError.NewError("Uncaught TypeError: str_replace(): Argument #2 ($replace) must be of type string when argument #1 ($search) is a string"))
+
+	// str_rot13
+	testInputOutput(t, `<?php var_dump(str_rot13('Hello, World!'));`, "string(13) \"Uryyb, Jbeyq!\"\n")
+
+	// str_shuffle
+	testInputOutput(t, `<?php mt_srand(3); var_dump(str_shuffle("abcdef"));`, "string(6) \"fcabde\"\n")
+
+	// str_split
+	testInputOutput(t, `<?php var_dump(str_split('abcde', 2));`, "array(3) {\n  [0]=>\n  string(2) \"ab\"\n  [1]=>\n  string(2) \"cd\"\n  [2]=>\n  string(1) \"e\"\n}\n")
+	testInputOutput(t, `<?php var_dump(str_split(''));`, "array(0) {\n}\n")
+	testForError(t, `<?php str_split('abc', 0);`, phpError.NewError("Uncaught ValueError: str_split(): Argument #2 ($length) must be greater than 0"))
+
 	// str_starts_with
 	testInputOutput(t, `<?php var_dump(str_starts_with('abc', ''));`, "bool(true)\n")
 	testInputOutput(t, `<?php var_dump(str_starts_with('The lazy fox', 'The'));`, "bool(true)\n")
 	testInputOutput(t, `<?php var_dump(str_starts_with('The lazy fox', 'the'));`, "bool(false)\n")
 
+	// str_word_count
+	testInputOutput(t, `<?php var_dump(str_word_count("Hello fri3nd, you're looking good today!"));`, "int(7)\n")
+	testInputOutput(t, `<?php var_dump(str_word_count('Hello fri3nd', 2));`, "array(3) {\n  [0]=>\n  string(5) \"Hello\"\n  [6]=>\n  string(3) \"fri\"\n  [10]=>\n  string(2) \"nd\"\n}\n")
+	testInputOutput(t, `<?php var_dump(str_word_count('Hello fri3nd', 1, '0..9'));`, "array(2) {\n  [0]=>\n  string(5) \"Hello\"\n  [1]=>\n  string(6) \"fri3nd\"\n}\n")
+
+	// strcasecmp
+	testInputOutput(t, `<?php var_dump(strcasecmp('Hello', 'hello'));`, "int(0)\n")
+	testInputOutput(t, `<?php var_dump(strcasecmp('a', 'B'));`, "int(-1)\n")
+
+	// strcmp
+	testInputOutput(t, `<?php var_dump(strcmp('a', 'b'));`, "int(-1)\n")
+	testInputOutput(t, `<?php var_dump(strcmp('b', 'a'));`, "int(1)\n")
+	testInputOutput(t, `<?php var_dump(strcmp('Hello', 'hello'));`, "int(-1)\n")
+	testInputOutput(t, `<?php var_dump(strcmp('abc', 'abc'));`, "int(0)\n")
+
+	// strcspn
+	testInputOutput(t, `<?php var_dump(strcspn('abcd', 'cd'));`, "int(2)\n")
+	testInputOutput(t, `<?php var_dump(strcspn('hello', 'l', 1, 2));`, "int(1)\n")
+
+	// strip_tags
+	testInputOutput(t, `<?php var_dump(strip_tags('<p>Test paragraph.</p><!-- Comment --> <a href="#fragment">Other text</a>'));`, "string(26) \"Test paragraph. Other text\"\n")
+	testInputOutput(t, `<?php var_dump(strip_tags('<p>Test paragraph.</p><!-- Comment --> <a href="#fragment">Other text</a>', '<p><a>'));`, "string(57) \"<p>Test paragraph.</p> <a href=\"#fragment\">Other text</a>\"\n")
+	testInputOutput(t, `<?php var_dump(strip_tags('<br/>a<B>b</B><?php echo 1; ?>c<i>d</i>', ['br', 'b']));`, "string(16) \"<br/>a<B>b</B>cd\"\n")
+	testInputOutput(t, `<?php var_dump(strip_tags('a < b and <b title="x>y">c</b>'));`, "string(11) \"a < b and c\"\n")
+
+	// stripcslashes
+	testInputOutput(t, `<?php var_dump(stripcslashes('a\x41\101\n'));`, "string(4) \"aAA\n\"\n")
+
+	// stripos
+	testInputOutput(t, `<?php var_dump(stripos('ABCabc', 'c', 3));`, "int(5)\n")
+
+	// stripslashes
+	testInputOutput(t, `<?php var_dump(stripslashes('O\\\'Re\\\\illy'));`, "string(9) \"O'Re\\illy\"\n")
+
+	// stristr
+	testInputOutput(t, `<?php var_dump(stristr('HayStack', 'st'));`, "string(5) \"Stack\"\n")
+	testInputOutput(t, `<?php var_dump(stristr('HayStack', 'st', true));`, "string(3) \"Hay\"\n")
+
 	// strlen
 	testInputOutput(t, `<?php var_dump(strlen('abcdef'));`, "int(6)\n")
 	testInputOutput(t, `<?php var_dump(strlen(' ab cd '));`, "int(7)\n")
 	testInputOutput(t, `<?php var_dump(strlen(' äb ćd '));`, "int(9)\n")
 
+	// strnatcasecmp
+	testInputOutput(t, `<?php var_dump(strnatcasecmp('IMG12', 'img10'));`, "int(1)\n")
+
+	// strnatcmp
+	testInputOutput(t, `<?php var_dump(strnatcmp('img12', 'img10'));`, "int(1)\n")
+	testInputOutput(t, `<?php var_dump(strnatcmp('img2', 'img10'));`, "int(-1)\n")
+
+	// strncasecmp
+	testInputOutput(t, `<?php var_dump(strncasecmp('Hello', 'hELP', 3));`, "int(0)\n")
+
+	// strncmp
+	testInputOutput(t, `<?php var_dump(strncmp('abcd', 'abef', 2));`, "int(0)\n")
+	testInputOutput(t, `<?php var_dump(strncmp('abcd', 'abef', 3));`, "int(-1)\n")
+
+	// strpbrk
+	testInputOutput(t, `<?php var_dump(strpbrk('This is a test', 'st'));`, "string(11) \"s is a test\"\n")
+
+	// strpos
+	testInputOutput(t, `<?php var_dump(strpos('abcabc', 'c'));`, "int(2)\n")
+	testInputOutput(t, `<?php var_dump(strpos('abcabc', 'c', 3));`, "int(5)\n")
+	testInputOutput(t, `<?php var_dump(strpos('abcabc', 'c', -2));`, "int(5)\n")
+	testInputOutput(t, `<?php var_dump(strpos('abcabc', 'd'));`, "bool(false)\n")
+	testForError(t, `<?php strpos('abc', 'a', 4);`, phpError.NewError("Uncaught ValueError: strpos(): Argument #3 ($offset) must be contained in argument #1 ($haystack)"))
+
+	// strrchr
+	testInputOutput(t, `<?php var_dump(strrchr('a/b/c', '/'));`, "string(2) \"/c\"\n")
+
+	// strrev
+	testInputOutput(t, `<?php var_dump(strrev('Hello'));`, "string(5) \"olleH\"\n")
+
+	// strripos
+	testInputOutput(t, `<?php var_dump(strripos('ABCabc', 'B'));`, "int(4)\n")
+
+	// strrpos
+	testInputOutput(t, `<?php var_dump(strrpos('0123456789a123456789b123456789c', '7'));`, "int(27)\n")
+	testInputOutput(t, `<?php var_dump(strrpos('0123456789a123456789b123456789c', '7', -5));`, "int(17)\n")
+	testInputOutput(t, `<?php var_dump(strrpos('0123456789a123456789b123456789c', '7', 20));`, "int(27)\n")
+	testInputOutput(t, `<?php var_dump(strrpos('0123456789a123456789b123456789c', '7', 28));`, "bool(false)\n")
+
+	// strspn
+	testInputOutput(t, `<?php var_dump(strspn('42 is the answer', '1234567890'));`, "int(2)\n")
+
+	// strstr
+	testInputOutput(t, `<?php var_dump(strstr('user@example.com', '@'));`, "string(12) \"@example.com\"\n")
+	testInputOutput(t, `<?php var_dump(strchr('user@example.com', '@', true));`, "string(4) \"user\"\n")
+	testInputOutput(t, `<?php var_dump(strstr('abc', 'd'));`, "bool(false)\n")
+
 	// strtolower
 	testInputOutput(t, `<?php var_dump(strtolower('Mary Had A Little Lamb and She LOVED It So'));`, "string(42) \"mary had a little lamb and she loved it so\"\n")
 	testInputOutput(t, `<?php var_dump(strtolower(''));`, "string(0) \"\"\n")
@@ -270,6 +1178,10 @@ func TestLibStrings(t *testing.T) {
 	testInputOutput(t, `<?php var_dump(strtoupper(''));`, "string(0) \"\"\n")
 	testInputOutput(t, `<?php var_dump(strtoupper('aäoöuüsß'));`, "string(12) \"AäOöUüSß\"\n")
 
+	// strtr
+	testInputOutput(t, `<?php var_dump(strtr('Hi all', 'al', 'AL'));`, "string(6) \"Hi ALL\"\n")
+	testInputOutput(t, `<?php var_dump(strtr('Hi all, I said hello', ['Hi' => 'Hello', 'hello' => 'hi', 'Hello' => 'x']));`, "string(20) \"Hello all, I said hi\"\n")
+
 	// substr
 	// Spec: https://www.php.net/manual/en/function.substr.php
 	testInputOutput(t, `<?php var_dump(substr("abcdef", 1));`, "string(5) \"bcdef\"\n")
@@ -282,15 +1194,288 @@ func TestLibStrings(t *testing.T) {
 	testInputOutput(t, `<?php var_dump(substr("abcdef", 4, -4));`, "string(0) \"\"\n")
 	testInputOutput(t, `<?php var_dump(substr("abcdef", -3, -1));`, "string(2) \"de\"\n")
 
+	// substr_compare
+	testInputOutput(t, `<?php var_dump(substr_compare('abcde', 'bc', 1, 2));`, "int(0)\n")
+	testInputOutput(t, `<?php var_dump(substr_compare('abcde', 'BC', 1, 2, true));`, "int(0)\n")
+	testInputOutput(t, `<?php var_dump(substr_compare('abcde', 'de', -2));`, "int(0)\n")
+
+	// substr_count
+	testInputOutput(t, `<?php var_dump(substr_count('hello hello', 'll'));`, "int(2)\n")
+	testInputOutput(t, `<?php var_dump(substr_count('hello hello', 'll', 3));`, "int(1)\n")
+	testForError(t, `<?php substr_count('abc', '');`, phpError.NewError("Uncaught ValueError: substr_count(): Argument #2 ($needle) cannot be empty"))
+
+	// substr_replace
+	testInputOutput(t, `<?php var_dump(substr_replace('Hello', 'J', 0, 1));`, "string(5) \"Jello\"\n")
+	testInputOutput(t, `<?php var_dump(substr_replace('Hello', '!', -1, 0));`, "string(6) \"Hell!o\"\n")
+	testInputOutput(t, `<?php var_dump(substr_replace(['abc', 'def'], 'X', [1, 2], 1));`, "array(2) {\n  [0]=>\n  string(3) \"aXc\"\n  [1]=>\n  string(3) \"deX\"\n}\n")
+
+	// trim
+	testInputOutput(t, "<?php var_dump(trim(\" \\t\\n abc \" . chr(0)));", "string(3) \"abc\"\n")
+	testInputOutput(t, `<?php var_dump(trim('xxhixx', 'x'));`, "string(2) \"hi\"\n")
+	testInputOutput(t, `<?php var_dump(trim('abcHIcba', 'a..c'));`, "string(2) \"HI\"\n")
+
 	// ucfirst
 	testInputOutput(t, `<?php var_dump(ucfirst('ABC'));`, "string(3) \"ABC\"\n")
 	testInputOutput(t, `<?php var_dump(ucfirst('abc'));`, "string(3) \"Abc\"\n")
 	testInputOutput(t, `<?php var_dump(ucfirst('Abc'));`, "string(3) \"Abc\"\n")
 	testInputOutput(t, `<?php var_dump(ucfirst(''));`, "string(0) \"\"\n")
+
+	// ucwords
+	testInputOutput(t, `<?php var_dump(ucwords('hello world'));`, "string(11) \"Hello World\"\n")
+	testInputOutput(t, `<?php var_dump(ucwords('hello|world', '|'));`, "string(11) \"Hello|World\"\n")
+
+	// vprintf
+	testInputOutput(t, `<?php $n = vprintf("%s=%d\n", ["x", 5]); var_dump($n);`, "x=5\nint(4)\n")
+
+	// vsprintf
+	testInputOutput(t, `<?php var_dump(vsprintf("%04d-%02d-%02d", [2024, 1, 5]));`, "string(10) \"2024-01-05\"\n")
+	testInputOutput(t, `<?php var_dump(vsprintf('%2$s %1$s', ['a', 'b']));`, "string(3) \"b a\"\n")
+	testForError(t, `<?php vsprintf("%s %s", ["a"]);`, phpError.NewError("Uncaught ValueError: The arguments array must contain 2 items, 1 given"))
+
+	// wordwrap
+	testInputOutput(t, `<?php var_dump(wordwrap('The quick brown fox', 10, '<br>'));`, "string(22) \"The quick<br>brown fox\"\n")
+	testInputOutput(t, `<?php var_dump(wordwrap('A very long woooooooooooord.', 8, '|', true));`, "string(29) \"A very|long|wooooooo|ooooord.\"\n")
+	testInputOutput(t, `<?php var_dump(wordwrap('A very long woooooooooooooooooord.', 8, '|'));`, "string(34) \"A very|long|woooooooooooooooooord.\"\n")
+	testForError(t, `<?php wordwrap('abc', 10, '');`, phpError.NewError("Uncaught ValueError: wordwrap(): Argument #3 ($break) cannot be empty"))
+	testForError(t, `<?php wordwrap('abc', 0, '|', true);`, phpError.NewError("Uncaught ValueError: wordwrap(): Argument #2 ($width) cannot be 0 when argument #4 ($cut_long_words) is true"))
 }
 
 // -------------------------------------- option_info -------------------------------------- MARK: option_info
 
+func TestLibUrl(t *testing.T) {
+	// base64_decode
+	testInputOutput(t, `<?php var_dump(base64_decode('SGVsbG8='));`, "string(5) \"Hello\"\n")
+	testInputOutput(t, `<?php var_dump(base64_decode('SGV!sbG8'));`, "string(5) \"Hello\"\n")
+	testInputOutput(t, `<?php var_dump(base64_decode('SGVs bG8=', true));`, "string(5) \"Hello\"\n")
+	testInputOutput(t, `<?php var_dump(base64_decode('SGV!sbG8=', true));`, "bool(false)\n")
+	testInputOutput(t, `<?php var_dump(base64_decode('SGVsbG8==', true));`, "bool(false)\n")
+	testInputOutput(t, `<?php var_dump(base64_decode('SGVsbG8=a', true));`, "bool(false)\n")
+	testInputOutput(t, `<?php var_dump(base64_decode('S', true));`, "bool(false)\n")
+
+	// base64_encode
+	testInputOutput(t, `<?php var_dump(base64_encode('This is an encoded string'));`, "string(36) \"VGhpcyBpcyBhbiBlbmNvZGVkIHN0cmluZw==\"\n")
+
+	// http_build_query
+	testInputOutput(t,
+		`<?php var_dump(http_build_query(['foo' => 'bar', 'null' => null, 'php' => 'hypertext processor', 'b' => true, 'c' => false]));`,
+		"string(39) \"foo=bar&php=hypertext+processor&b=1&c=0\"\n",
+	)
+	testInputOutput(t,
+		`<?php var_dump(http_build_query(['a', 'k' => ['x' => 1, 'y' => [2]]], 'p_', '&amp;'));`,
+		"string(42) \"p_0=a&amp;k%5Bx%5D=1&amp;k%5By%5D%5B0%5D=2\"\n",
+	)
+	testInputOutput(t, `<?php var_dump(http_build_query(['a b' => 'c d'], '', null, PHP_QUERY_RFC3986));`, "string(11) \"a%20b=c%20d\"\n")
+
+	// parse_url
+	testInputOutput(t,
+		`<?php var_dump(parse_url('https://user:pw@example.com:8080/path/to?x=1&y=2#frag'));`,
+		"array(8) {\n  [\"scheme\"]=>\n  string(5) \"https\"\n  [\"host\"]=>\n  string(11) \"example.com\"\n  [\"port\"]=>\n  int(8080)\n"+
+			"  [\"user\"]=>\n  string(4) \"user\"\n  [\"pass\"]=>\n  string(2) \"pw\"\n  [\"path\"]=>\n  string(8) \"/path/to\"\n"+
+			"  [\"query\"]=>\n  string(7) \"x=1&y=2\"\n  [\"fragment\"]=>\n  string(4) \"frag\"\n}\n",
+	)
+	testInputOutput(t,
+		`<?php var_dump(parse_url('//www.example.com/path?googleguy=googley'));`,
+		"array(3) {\n  [\"host\"]=>\n  string(15) \"www.example.com\"\n  [\"path\"]=>\n  string(5) \"/path\"\n  [\"query\"]=>\n  string(17) \"googleguy=googley\"\n}\n",
+	)
+	testInputOutput(t, `<?php var_dump(parse_url('mailto:a@b.c'));`, "array(2) {\n  [\"scheme\"]=>\n  string(6) \"mailto\"\n  [\"path\"]=>\n  string(5) \"a@b.c\"\n}\n")
+	testInputOutput(t, `<?php var_dump(parse_url('example.com:80'));`, "array(2) {\n  [\"host\"]=>\n  string(11) \"example.com\"\n  [\"port\"]=>\n  int(80)\n}\n")
+	testInputOutput(t, `<?php var_dump(parse_url('http://[::1]:80/', PHP_URL_HOST));`, "string(5) \"[::1]\"\n")
+	testInputOutput(t, `<?php var_dump(parse_url('http://example.com', PHP_URL_PORT));`, "NULL\n")
+	testInputOutput(t, `<?php var_dump(parse_url('http:///example.com'));`, "bool(false)\n")
+	testInputOutput(t, `<?php var_dump(parse_url('http://host:99999'));`, "bool(false)\n")
+	testForError(t, `<?php parse_url('http://host', 8);`, phpError.NewError("Uncaught ValueError: parse_url(): Argument #2 ($component) must be a valid URL component identifier, 8 given"))
+
+	// rawurldecode
+	testInputOutput(t, `<?php var_dump(rawurldecode('a+b%20c'));`, "string(5) \"a+b c\"\n")
+
+	// rawurlencode
+	testInputOutput(t, `<?php var_dump(rawurlencode('a b&c=d/~'));`, "string(17) \"a%20b%26c%3Dd%2F~\"\n")
+
+	// urldecode
+	testInputOutput(t, `<?php var_dump(urldecode('a+b%20c%zz%4'));`, "string(10) \"a b c%zz%4\"\n")
+
+	// urlencode
+	testInputOutput(t, `<?php var_dump(urlencode('a b&c=d/~'));`, "string(17) \"a+b%26c%3Dd%2F%7E\"\n")
+}
+
+func TestLibJson(t *testing.T) {
+	// json_decode
+	testInputOutput(t,
+		`<?php var_dump(json_decode('{"a":1,"b":[1,2.5,"x"],"c":{"d":null,"e":true},"0":false}'));`,
+		"object(stdClass)#1 (4) {\n"+
+			"  [\"a\":\"stdClass\":public]=>\n  int(1)\n"+
+			"  [\"b\":\"stdClass\":public]=>\n  array(3) {\n    [0]=>\n    int(1)\n    [1]=>\n    float(2.5)\n    [2]=>\n    string(1) \"x\"\n  }\n"+
+			"  [\"c\":\"stdClass\":public]=>\n  object(stdClass)#1 (2) {\n    [\"d\":\"stdClass\":public]=>\n    NULL\n    [\"e\":\"stdClass\":public]=>\n    bool(true)\n  }\n"+
+			"  [\"0\":\"stdClass\":public]=>\n  bool(false)\n}\n",
+	)
+	testInputOutput(t,
+		`<?php var_dump(json_decode('{"b":{"c":[]},"5":{}}', true));`,
+		"array(2) {\n  [\"b\"]=>\n  array(1) {\n    [\"c\"]=>\n    array(0) {\n    }\n  }\n  [5]=>\n  array(0) {\n  }\n}\n",
+	)
+	testInputOutput(t, `<?php var_dump(json_decode('{"a":{}}', null, 512, JSON_OBJECT_AS_ARRAY));`, "array(1) {\n  [\"a\"]=>\n  array(0) {\n  }\n}\n")
+	testInputOutput(t, `<?php var_dump(json_decode('{}', false, 512, JSON_OBJECT_AS_ARRAY));`, "object(stdClass)#1 (0) {\n}\n")
+	testInputOutput(t, `<?php var_dump(json_decode('12345678901234567890', false, 512, JSON_BIGINT_AS_STRING));`, "string(20) \"12345678901234567890\"\n")
+	testInputOutput(t, `<?php var_dump(json_decode('-9223372036854775808'), json_decode('-0'), json_decode('1.0'), json_decode('1E2'));`, "int(-9223372036854775808)\nint(0)\nfloat(1)\nfloat(100)\n")
+	testInputOutput(t, `<?php var_dump(json_decode('"é😀\/"'));`, "string(7) \"é😀/\"\n")
+	testInputOutput(t, `<?php var_dump(json_decode('"a' . chr(200) . '"', false, 512, JSON_INVALID_UTF8_IGNORE));`, "string(1) \"a\"\n")
+	testInputOutput(t, `<?php var_dump(json_decode('[1,]'), json_last_error(), json_last_error_msg());`, "NULL\nint(4)\nstring(12) \"Syntax error\"\n")
+	testInputOutput(t, `<?php var_dump(json_decode(''), json_last_error());`, "NULL\nint(4)\n")
+	testInputOutput(t, `<?php var_dump(json_decode('01'), json_last_error());`, "NULL\nint(4)\n")
+	testInputOutput(t, `<?php var_dump(json_decode('{"a":1]'), json_last_error_msg());`, "NULL\nstring(42) \"State mismatch (invalid or malformed JSON)\"\n")
+	testInputOutput(t, `<?php var_dump(json_decode('"abc'), json_last_error());`, "NULL\nint(3)\n")
+	testInputOutput(t, `<?php var_dump(json_decode('"\ud83d"'), json_last_error_msg());`, "NULL\nstring(50) \"Single unpaired UTF-16 surrogate in unicode escape\"\n")
+	testInputOutput(t, `<?php var_dump(json_decode('"' . chr(200) . '"'), json_last_error());`, "NULL\nint(5)\n")
+	testInputOutput(t, `<?php var_dump(json_decode('{"\u0000a":1}'), json_last_error_msg());`, "NULL\nstring(36) \"The decoded property name is invalid\"\n")
+	testInputOutput(t, `<?php var_dump(json_decode('[[1]]', true, 2) !== null, json_decode('[[1]]', true, 1), json_last_error_msg());`, "bool(true)\nNULL\nstring(28) \"Maximum stack depth exceeded\"\n")
+	testInputOutput(t,
+		`<?php try { json_decode('{', false, 512, JSON_THROW_ON_ERROR); } catch (JsonException $e) { var_dump($e->getMessage(), $e->getCode()); }`,
+		"string(12) \"Syntax error\"\nint(4)\n",
+	)
+	testForError(t, `<?php json_decode('1', false, 0);`, phpError.NewError("Uncaught ValueError: json_decode(): Argument #3 ($depth) must be greater than 0"))
+
+	// json_encode
+	testInputOutput(t,
+		`<?php var_dump(json_encode(['a' => 1, 'b' => [1, 2], 'c' => null, 'd' => 1.5, 'e' => "x/y\"é\n", 'f' => true, 'g' => [], 'h' => 10.0]));`,
+		"string(78) \"{\"a\":1,\"b\":[1,2],\"c\":null,\"d\":1.5,\"e\":\"x\\/y\\\"\\u00e9\\n\",\"f\":true,\"g\":[],\"h\":10}\"\n",
+	)
+	testInputOutput(t,
+		`<?php echo json_encode(['a' => 1, 'b' => [1, 2], 'c' => [], 'd' => ['x' => 'y']], JSON_PRETTY_PRINT);`,
+		"{\n    \"a\": 1,\n    \"b\": [\n        1,\n        2\n    ],\n    \"c\": [],\n    \"d\": {\n        \"x\": \"y\"\n    }\n}",
+	)
+	testInputOutput(t, `<?php echo json_encode("é/😀", JSON_UNESCAPED_UNICODE | JSON_UNESCAPED_SLASHES);`, "\"é/😀\"")
+	testInputOutput(t, `<?php echo json_encode("é/😀");`, `"\u00e9\/\ud83d\ude00"`)
+	testInputOutput(t, `<?php echo json_encode(10.0, JSON_PRESERVE_ZERO_FRACTION);`, "10.0")
+	testInputOutput(t, `<?php echo json_encode([0.1, 1e25, -0.0, 1e-7]);`, "[0.1,1.0e+25,-0,1.0e-7]")
+	testInputOutput(t, `<?php echo json_encode([1 => 'a', 2 => 'b']), json_encode(['a', 'b'], JSON_FORCE_OBJECT);`, `{"1":"a","2":"b"}{"0":"a","1":"b"}`)
+	testInputOutput(t,
+		`<?php echo json_encode("<a href='x'>&\"", JSON_HEX_TAG | JSON_HEX_APOS | JSON_HEX_QUOT | JSON_HEX_AMP);`,
+		`"\u003Ca href=\u0027x\u0027\u003E\u0026\u0022"`,
+	)
+	testInputOutput(t, `<?php echo json_encode(["12", "1.5", "abc", " 3", "0x1A", "1e3"], JSON_NUMERIC_CHECK);`, `[12,1.5,"abc",3,"0x1A",1000]`)
+	testInputOutput(t,
+		`<?php var_dump(json_encode("a" . chr(200)), json_last_error(), json_last_error_msg());`,
+		"bool(false)\nint(5)\nstring(56) \"Malformed UTF-8 characters, possibly incorrectly encoded\"\n",
+	)
+	testInputOutput(t, `<?php var_dump(json_encode(["a" . chr(200), 1], JSON_PARTIAL_OUTPUT_ON_ERROR), json_last_error());`, "string(8) \"[null,1]\"\nint(5)\n")
+	testInputOutput(t, `<?php var_dump(json_encode("a" . chr(200), JSON_INVALID_UTF8_SUBSTITUTE));`, "string(9) \"\"a\\ufffd\"\"\n")
+	testInputOutput(t, `<?php var_dump(json_encode(1e400), json_last_error_msg());`, "bool(false)\nstring(34) \"Inf and NaN cannot be JSON encoded\"\n")
+	testInputOutput(t, `<?php var_dump(json_encode([[1]], 0, 1), json_last_error_msg(), json_encode([1], 0, 1));`, "bool(false)\nstring(28) \"Maximum stack depth exceeded\"\nstring(3) \"[1]\"\n")
+	testInputOutput(t,
+		`<?php class P implements JsonSerializable { public $a = 1; protected $b = 2;
+		public function jsonSerialize(): mixed { return ['x' => $this->a, 'y' => $this->b]; } }
+		class Q { public $a = 1; protected $b = 2; private $c = 3; public $n = null; }
+		$o = new stdClass; $o->x = 1; $o->y = new Q;
+		echo json_encode([new P, new Q, $o, new stdClass]);`,
+		`[{"x":1,"y":2},{"a":1,"n":null},{"x":1,"y":{"a":1,"n":null}},{}]`,
+	)
+	testInputOutput(t,
+		`<?php class R implements JsonSerializable { public $a = 1; public function jsonSerialize(): mixed { return $this; } }
+		$o = new stdClass; $o->self = $o;
+		var_dump(json_encode(new R), json_encode($o), json_last_error_msg());`,
+		"string(7) \"{\"a\":1}\"\nbool(false)\nstring(18) \"Recursion detected\"\n",
+	)
+	testInputOutput(t,
+		`<?php json_encode(1e400);
+		try { json_encode(1e400, JSON_THROW_ON_ERROR); } catch (JsonException $e) { var_dump($e->getMessage(), $e->getCode()); }
+		var_dump(json_last_error());`,
+		"string(34) \"Inf and NaN cannot be JSON encoded\"\nint(7)\nint(7)\n",
+	)
+	testForError(t, `<?php json_encode(1, 0, 0);`, phpError.NewError("Uncaught ValueError: json_encode(): Argument #3 ($depth) must be greater than 0"))
+
+	// json_validate
+	testInputOutput(t, `<?php var_dump(json_validate('{"a":[1,2,{"b":null}]}'), json_last_error());`, "bool(true)\nint(0)\n")
+	testInputOutput(t, `<?php var_dump(json_validate('{"a":'), json_last_error_msg());`, "bool(false)\nstring(12) \"Syntax error\"\n")
+	testInputOutput(t, `<?php var_dump(json_validate('[[1]]', 1), json_last_error_msg());`, "bool(false)\nstring(28) \"Maximum stack depth exceeded\"\n")
+	testForError(t,
+		`<?php json_validate('1', 512, JSON_BIGINT_AS_STRING);`,
+		phpError.NewError("Uncaught ValueError: json_validate(): Argument #3 ($flags) must be a valid flag (allowed flags: JSON_INVALID_UTF8_IGNORE)"),
+	)
+}
+
+func TestLibPcre(t *testing.T) {
+	// preg_match
+	testInputOutput(t,
+		`<?php var_dump(preg_match('/(?<year>\d{4})-(\d\d)(x)?/', 'on 2024-05', $m)); echo json_encode($m);`,
+		`int(1)`+"\n"+`{"0":"2024-05","year":"2024","1":"2024","2":"05"}`,
+	)
+	testInputOutput(t,
+		`<?php preg_match('/(a)(b)?(c)?/', 'xa', $m, PREG_OFFSET_CAPTURE | PREG_UNMATCHED_AS_NULL); echo json_encode($m);`,
+		`[["a",1],["a",1],[null,-1],[null,-1]]`,
+	)
+	testInputOutput(t, `<?php var_dump(preg_match('/b/', 'abcb', $m, PREG_OFFSET_CAPTURE, -2)); echo json_encode($m);`, "int(1)\n[[\"b\",3]]")
+	testInputOutput(t, `<?php var_dump(preg_match('{a{2}}i', 'xAA'), preg_match('/^b/m', "a\nb"), preg_match('/a$/D', "a\n"));`, "int(1)\nint(1)\nint(0)\n")
+	testInputOutput(t, `<?php var_dump(preg_match('/a/', 'abc', $m, 0, 5), $m, preg_last_error());`, "bool(false)\narray(0) {\n}\nint(1)\n")
+	testInputOutput(t,
+		`<?php var_dump(preg_match('/abc', 'x'));`,
+		fmt.Sprintf("\nWarning: preg_match(): No ending delimiter '/' found in %s:1:16\nbool(false)\n", TEST_FILE_NAME),
+	)
+	testInputOutput(t,
+		`<?php var_dump(preg_match('/a(/', 'x'));`,
+		fmt.Sprintf("\nWarning: preg_match(): Compilation failed: missing closing parenthesis at offset 2 in %s:1:16\nbool(false)\n", TEST_FILE_NAME),
+	)
+	testInputOutput(t,
+		`<?php var_dump(preg_match('/a/k', 'x'));`,
+		fmt.Sprintf("\nWarning: preg_match(): Unknown modifier 'k' in %s:1:16\nbool(false)\n", TEST_FILE_NAME),
+	)
+	testForError(t, `<?php preg_match('/a/', 'a', $m, 2);`, phpError.NewError("Uncaught ValueError: preg_match(): Argument #4 ($flags) must be a PREG_* constant"))
+
+	// preg_match_all
+	testInputOutput(t, `<?php var_dump(preg_match_all('/(\w)(\d)?/', 'a1b', $m)); echo json_encode($m);`, "int(2)\n[[\"a1\",\"b\"],[\"a\",\"b\"],[\"1\",\"\"]]")
+	testInputOutput(t, `<?php var_dump(preg_match_all('/(\w)(\d)?/', 'a1b', $m, PREG_SET_ORDER)); echo json_encode($m);`, "int(2)\n[[\"a1\",\"a\",\"1\"],[\"b\",\"b\"]]")
+	testInputOutput(t, `<?php var_dump(preg_match_all('/(?<d>\d)/', 'abc', $m)); echo json_encode($m);`, "int(0)\n{\"0\":[],\"d\":[],\"1\":[]}")
+	testInputOutput(t, `<?php var_dump(preg_match_all('/x*/', 'ab'));`, "int(3)\n")
+	testInputOutput(t, `<?php var_dump(preg_match_all('/./u', 'äöü'));`, "int(3)\n")
+
+	// preg_replace, preg_filter
+	testInputOutput(t, `<?php var_dump(preg_replace('/(\w+) (\w+)/', '$2 ${1}\1 \\\\$1 \$1', 'hello world'));`, "string(26) \"world hellohello \\hello $1\"\n")
+	testInputOutput(t, `<?php var_dump(preg_replace('/x*/', '-', 'abc'));`, "string(7) \"-a-b-c-\"\n")
+	testInputOutput(t, `<?php var_dump(preg_replace('/a/', 'b', 'aaa', 2, $count), $count);`, "string(3) \"bba\"\nint(2)\n")
+	testInputOutput(t,
+		`<?php echo json_encode(preg_replace(['/a/', '/b/'], ['b'], ['k' => 'ab', 'x']));`,
+		`{"k":"","0":"x"}`,
+	)
+	testInputOutput(t, `<?php echo json_encode(preg_filter(['/\d/'], 'N', ['a1', 'b', 'c2'])); var_dump(preg_filter('/\d/', 'N', 'b'));`, "{\"0\":\"aN\",\"2\":\"cN\"}NULL\n")
+	testForError(t,
+		`<?php preg_replace('/a/', ['x'], 'a');`,
+		phpError.NewError("Uncaught TypeError: preg_replace(): Argument #1 ($pattern) must be of type array when argument #2 ($replacement) is an array, string given"),
+	)
+
+	// preg_replace_callback, preg_replace_callback_array
+	testInputOutput(t, `<?php var_dump(preg_replace_callback('/\d+/', fn($m) => $m[0] * 2, 'a1 b22', -1, $count), $count);`, "string(6) \"a2 b44\"\nint(2)\n")
+	testInputOutput(t,
+		`<?php echo preg_replace_callback('/(a)(b)?/', fn($m) => json_encode($m), 'a', -1, $count, PREG_UNMATCHED_AS_NULL);`,
+		`["a","a",null]`,
+	)
+	testInputOutput(t,
+		`<?php var_dump(preg_replace_callback_array(['/a/' => fn($m) => 'A', '/b/' => fn($m) => 'B'], 'aabb', -1, $count), $count);`,
+		"string(4) \"AABB\"\nint(4)\n",
+	)
+	testForError(t,
+		`<?php preg_replace_callback_array(['/a/' => 'unknownFunction'], 'a');`,
+		phpError.NewError("Uncaught TypeError: preg_replace_callback_array(): Argument #1 ($pattern) must contain only valid callbacks"),
+	)
+
+	// preg_split
+	testInputOutput(t, `<?php echo json_encode(preg_split('/[\s,]+/', "a, b  c,d"));`, `["a","b","c","d"]`)
+	testInputOutput(t, `<?php echo json_encode(preg_split('//', 'abc', -1, PREG_SPLIT_NO_EMPTY));`, `["a","b","c"]`)
+	testInputOutput(t, `<?php echo json_encode(preg_split('/(,)/', 'a,b,,c', 3, PREG_SPLIT_DELIM_CAPTURE));`, `["a",",","b",",",",c"]`)
+	testInputOutput(t, `<?php echo json_encode(preg_split('/ /', 'a b', -1, PREG_SPLIT_OFFSET_CAPTURE));`, `[["a",0],["b",2]]`)
+	testInputOutput(t, `<?php echo json_encode(preg_split('/,/', 'a,b,', 1));`, `["a,b,"]`)
+
+	// preg_quote, preg_grep
+	testInputOutput(t, `<?php var_dump(preg_quote('Hello.world?/#', '/'));`, "string(18) \"Hello\\.world\\?\\/\\#\"\n")
+	testInputOutput(t, `<?php echo json_encode(preg_grep('/^\d+$/', ['1', 'a', 5 => '22']));`, `{"0":"1","5":"22"}`)
+	testInputOutput(t, `<?php echo json_encode(preg_grep('/^\d+$/', ['1', 'a', 5 => '22'], PREG_GREP_INVERT));`, `{"1":"a"}`)
+
+	// preg_last_error, preg_last_error_msg
+	testInputOutput(t,
+		`<?php var_dump(preg_match('/(?:\D+|<\d+>)*[!?]/', 'foobar foobar foobar'), preg_last_error(), preg_last_error_msg());`,
+		"bool(false)\nint(2)\nstring(25) \"Backtrack limit exhausted\"\n",
+	)
+	testInputOutput(t, `<?php var_dump(preg_match('/a/u', chr(255)), preg_last_error_msg());`, "bool(false)\nstring(56) \"Malformed UTF-8 characters, possibly incorrectly encoded\"\n")
+	testInputOutput(t, `<?php preg_match('/a/u', chr(255)); preg_match('/a/', 'a'); var_dump(preg_last_error_msg());`, "string(8) \"No error\"\n")
+}
+
 func TestLibOptionInfo(t *testing.T) {
 	// ini_get
 	testInputOutput(t, `<?php var_dump(ini_get('none_existing'));`, "bool(false)\n")
@@ -302,6 +1487,16 @@ func TestLibOptionInfo(t *testing.T) {
 	testInputOutput(t, `<?php var_dump(ini_set('variables_order', true));`, "bool(false)\n")
 	testInputOutput(t, `<?php var_dump(ini_set('error_reporting', E_ERROR)); var_dump(ini_set('error_reporting', E_ERROR));`, "string(5) \"32767\"\nstring(1) \"1\"\n")
 
+	// disable_functions, disable_classes
+	disableIni := ini.NewDevIni()
+	disableIni.Set("disable_functions", "strlen, STRTOUPPER", ini.INI_ALL)
+	disableIni.Set("disable_classes", "ArrayObject", ini.INI_ALL)
+	testInputOutputCustomIni(t, disableIni, `<?php var_dump(function_exists('strlen'), function_exists('strtoupper'), function_exists('strtolower'));`, "bool(false)\nbool(false)\nbool(true)\n")
+	testInputOutputCustomIni(t, disableIni, `<?php function strlen($s) { return 42; } var_dump(strlen('a'));`, "int(42)\n")
+	testInputOutputCustomIni(t, disableIni, `<?php $o = new ArrayObject([]); var_dump(get_class($o), method_exists($o, 'count'));`,
+		fmt.Sprintf("\nWarning: ArrayObject() has been disabled for security reasons in %s:1:12\nstring(11) \"ArrayObject\"\nbool(false)\n", TEST_FILE_NAME))
+	testForErrorCustomIni(t, disableIni, `<?php strtoupper('a');`, phpError.NewError("Call to undefined function strtoupper() in %s:1:7", TEST_FILE_NAME))
+
 	// phpversion
 	testInputOutput(t, `<?= phpversion();`, config.Version)
 
@@ -328,6 +1523,189 @@ func TestLibErrorReporting(t *testing.T) {
 	testInputOutput(t, `<?php error_reporting(-1); echo error_reporting();`, "32767")
 }
 
+// -------------------------------------- error handling -------------------------------------- MARK: error handling
+
+func TestLibErrorHandling(t *testing.T) {
+	// set_error_handler
+	testInputOutput(t, `<?php
+		function handler($no, $str, $file, $line) { echo "$no: $str in line $line\n"; return true; }
+		var_dump(set_error_handler('handler'));
+		echo $a;
+		trigger_error("custom", E_USER_WARNING);`,
+		"NULL\n2: Undefined variable $a in line 4\n512: custom in line 5\n",
+	)
+	testInputOutput(t, `<?php
+		set_error_handler(function($no, $str) { echo "handled\n"; }, E_USER_NOTICE);
+		trigger_error("notice"); trigger_error("warning", E_USER_WARNING);`,
+		fmt.Sprintf("handled\n\nWarning: warning in %s:3:28\n", TEST_FILE_NAME),
+	)
+	testInputOutput(t, `<?php
+		set_error_handler(function($no, $str) { return false; });
+		trigger_error("notice");`,
+		fmt.Sprintf("\nNotice: notice in %s:3:3\n", TEST_FILE_NAME),
+	)
+	testInputOutput(t, `<?php
+		set_error_handler(function($no, $str) { throw new ErrorException($str, 0, $no); });
+		try { echo $a; echo "not reached"; } catch (ErrorException $e) { echo $e->getMessage() . " " . $e->getSeverity(); }`,
+		"Undefined variable $a 2",
+	)
+	testInputOutput(t, `<?php
+		set_error_handler(function($no, $str) { echo "first\n"; });
+		set_error_handler(function($no, $str) { echo "second\n"; });
+		trigger_error("a"); restore_error_handler(); trigger_error("b"); restore_error_handler(); trigger_error("c");`,
+		fmt.Sprintf("second\nfirst\n\nNotice: c in %s:4:93\n", TEST_FILE_NAME),
+	)
+
+	// trigger_error
+	testInputOutput(t, `<?php trigger_error("notice");`, fmt.Sprintf("\nNotice: notice in %s:1:7\n", TEST_FILE_NAME))
+	testInputOutput(t, `<?php user_error("deprecated", E_USER_DEPRECATED);`, fmt.Sprintf("\nDeprecated: deprecated in %s:1:7\n", TEST_FILE_NAME))
+	testForError(t, `<?php trigger_error("fatal", E_USER_ERROR); echo "not reached";`, phpError.NewUserError(phpError.UserPhpError, "fatal in %s:1:7", TEST_FILE_NAME))
+	testForError(t, `<?php trigger_error("error", E_ERROR);`, phpError.NewError("Uncaught ValueError: trigger_error(): Argument #2 ($error_level) must be one of E_USER_ERROR, E_USER_WARNING, E_USER_NOTICE, or E_USER_DEPRECATED in %s:1:7", TEST_FILE_NAME))
+
+	// error_get_last, error_clear_last
+	testInputOutput(t, `<?php var_dump(error_get_last());`, "NULL\n")
+	testInputOutput(t, `<?php @trigger_error("msg", E_USER_WARNING); $e = error_get_last(); echo $e["type"] . " " . $e["message"] . " " . $e["line"];`, "512 msg 1")
+	testInputOutput(t, `<?php @trigger_error("msg"); error_clear_last(); var_dump(error_get_last());`, "NULL\n")
+
+	// set_exception_handler
+	testInputOutput(t, `<?php
+		set_exception_handler(function($e) { echo "Uncaught: " . $e->getMessage(); });
+		throw new Exception("message");
+		echo "not reached";`,
+		"Uncaught: message",
+	)
+	testInputOutput(t, `<?php
+		set_exception_handler(function($e) { echo "first"; });
+		var_dump(set_exception_handler(function($e) { echo "second"; }) !== null);
+		restore_exception_handler();
+		throw new Exception("message");`,
+		"bool(true)\nfirst",
+	)
+	testForError(t, `<?php
+		set_exception_handler(function($e) { echo "first"; });
+		restore_exception_handler();
+		throw new Exception("message");`,
+		phpError.NewError("Uncaught Exception: message in %s:4", TEST_FILE_NAME),
+	)
+}
+
+// -------------------------------------- debug_backtrace -------------------------------------- MARK: debug_backtrace
+
+func TestLibDebugBacktrace(t *testing.T) {
+	testInputOutput(t, `<?php function f($a) { var_dump(debug_backtrace()); } f(1);`,
+		fmt.Sprintf("array(1) {\n  [0]=>\n  array(4) {\n    [\"file\"]=>\n    string(%d) \"%s\"\n    [\"line\"]=>\n    int(1)\n    [\"function\"]=>\n    string(1) \"f\"\n    [\"args\"]=>\n    array(1) {\n      [0]=>\n      int(1)\n    }\n  }\n}\n", len(TEST_FILE_NAME), TEST_FILE_NAME),
+	)
+	testInputOutput(t, `<?php
+		class C { function m() { $t = debug_backtrace(DEBUG_BACKTRACE_IGNORE_ARGS); echo $t[0]["class"], $t[0]["type"], $t[0]["function"], " ", isset($t[0]["object"]) ? "object" : "", isset($t[0]["args"]) ? "args" : ""; } }
+		$c = new C; $c->m();`,
+		"C->m ",
+	)
+	testInputOutput(t, `<?php
+		class C { static function m() { $t = debug_backtrace(); echo $t[0]["class"], $t[0]["type"], $t[0]["function"], " ", count($t); } }
+		function f() { C::m(); } f();`,
+		"C::m 2",
+	)
+	testInputOutput(t, `<?php function f() { g(); } function g() { echo count(debug_backtrace(0, 1)); } f();`, "1")
+	testInputOutput(t, `<?php function f($a) { g(); } function g() { debug_print_backtrace(); } f("a");`,
+		fmt.Sprintf("#0 %[1]s(1): g()\n#1 %[1]s(1): f('a')\n", TEST_FILE_NAME),
+	)
+	testInputOutput(t, `<?php function f($a) { debug_print_backtrace(DEBUG_BACKTRACE_IGNORE_ARGS); } f("a");`,
+		fmt.Sprintf("#0 %s(1): f()\n", TEST_FILE_NAME),
+	)
+}
+
+// -------------------------------------- function handling -------------------------------------- MARK: function handling
+
+func TestLibFunctionHandling(t *testing.T) {
+	// call_user_func
+	testInputOutput(t, `<?php echo call_user_func('strtoupper', 'abc');`, "ABC")
+	testInputOutput(t, `<?php function f($a, $b) { return $a . $b; } echo call_user_func('f', 'a', 'b');`, "ab")
+	testInputOutput(t, `<?php echo call_user_func(function ($x) { return $x * 2; }, 21);`, "42")
+	testInputOutput(t, `<?php class C { static function s($a) { return $a + 1; } } echo call_user_func('C::s', 1), call_user_func(['C', 's'], 2);`, "23")
+	testInputOutput(t, `<?php class C { public $v = 5; function m($a) { return $this->v + $a; } } $c = new C(); echo call_user_func([$c, 'm'], 1);`, "6")
+	testInputOutput(t, `<?php class C { function __invoke($a) { return "inv$a"; } } echo call_user_func(new C(), 1);`, "inv1")
+	testForError(t, `<?php call_user_func('nope');`, phpError.NewError(
+		`Uncaught TypeError: call_user_func(): Argument #1 ($callback) must be a valid callback, function "nope" not found or invalid function name in %s:1:7`, TEST_FILE_NAME,
+	))
+	testForError(t, `<?php class C { function m() {} } call_user_func('C::m');`, phpError.NewError(
+		`Uncaught TypeError: call_user_func(): Argument #1 ($callback) must be a valid callback, non-static method C::m() cannot be called statically in %s:1:35`, TEST_FILE_NAME,
+	))
+	testForError(t, `<?php class C {} call_user_func(['C', 'm']);`, phpError.NewError(
+		`Uncaught TypeError: call_user_func(): Argument #1 ($callback) must be a valid callback, class C does not have a method "m" in %s:1:18`, TEST_FILE_NAME,
+	))
+	testForError(t, `<?php call_user_func(['X', 'm']);`, phpError.NewError(
+		`Uncaught TypeError: call_user_func(): Argument #1 ($callback) must be a valid callback, class "X" not found in %s:1:7`, TEST_FILE_NAME,
+	))
+	testForError(t, `<?php call_user_func([1, 2, 3]);`, phpError.NewError(
+		`Uncaught TypeError: call_user_func(): Argument #1 ($callback) must be a valid callback, array callback must have exactly two members in %s:1:7`, TEST_FILE_NAME,
+	))
+	// call_user_func_array
+	testInputOutput(t, `<?php function f($a, $b) { return $a - $b; } echo call_user_func_array('f', [5, 2]);`, "3")
+	testInputOutput(t, `<?php function f(&$a) { $a++; } $x = 1; call_user_func_array('f', [$x]); echo $x;`, "1")
+	testInputOutput(t, `<?php function f($a, $b) { return $a - $b; } echo call_user_func_array('f', ['b' => 1, 'a' => 5]), call_user_func_array('f', [5, 'b' => 2]);`, "43")
+	testInputOutput(t, `<?php function f($a, $b = 10, $c = 100) { return $a + $b + $c; } echo call_user_func_array('f', [1, 'c' => 2]);`, "13")
+	testInputOutput(t, `<?php function f($a, ...$rest) { var_dump($rest); } call_user_func_array('f', [1, 2, 'x' => 3]); call_user_func_array('f', ['a' => 1, 'y' => 4]);`,
+		"array(2) {\n  [0]=>\n  int(2)\n  [\"x\"]=>\n  int(3)\n}\narray(1) {\n  [\"y\"]=>\n  int(4)\n}\n")
+	testInputOutput(t, `<?php class C { const D = 5; static function m($a, $b = self::D) { return $a + $b; } } echo call_user_func_array('C::m', ['a' => 1]);`, "6")
+	testForError(t, `<?php function f($a, $b) {} call_user_func_array('f', ['c' => 1]);`, phpError.NewError("Uncaught Error: Unknown named parameter $c"))
+	testForError(t, `<?php function f($a, $b) {} call_user_func_array('f', [1, 'a' => 1]);`, phpError.NewError("Uncaught Error: Named parameter $a overwrites previous argument"))
+	testForError(t, `<?php function f($a, $b) {} call_user_func_array('f', ['b' => 1]);`, phpError.NewError("Uncaught ArgumentCountError: f(): Argument #1 ($a) not passed"))
+	testForError(t, `<?php function f($a, $b) {} call_user_func_array('f', ['a' => 1, 2]);`, phpError.NewError(
+		"Uncaught Error: Cannot use positional argument after named argument during unpacking in %s:1:29", TEST_FILE_NAME,
+	))
+	// forward_static_call
+	testInputOutput(t,
+		`<?php class A { static function s($a) { return "A$a"; } } class B extends A { static function t() { return forward_static_call(['A', 's'], 1) . forward_static_call_array('A::s', [2]); } } echo B::t();`,
+		"A1A2")
+	testInputOutput(t,
+		`<?php class A { static function who($a) { return static::class_name() . $a; } static function class_name() { return "A"; } }
+        class B extends A { static function class_name() { return "B"; } static function t() { return forward_static_call(['A', 'who'], 1) . call_user_func(['A', 'who'], 2) . forward_static_call_array('A::who', ['a' => 3]); } }
+        echo B::t();`,
+		"B1A2B3")
+	testForError(t, `<?php function f() {} forward_static_call('f');`, phpError.NewError(
+		"Uncaught Error: Cannot call forward_static_call() when no class scope is active in %s:1:23", TEST_FILE_NAME,
+	))
+	// func_get_args, func_get_arg, func_num_args
+	testInputOutput(t, `<?php function f($a, $b = 2) { return implode(',', func_get_args()) . '|' . func_num_args(); } echo f(1), ' ', f(1, 2, 3);`, "1|1 1,2,3|3")
+	testInputOutput(t, `<?php function f($a) { $a = 10; return func_get_arg(0) . implode(',', func_get_args()); } echo f(1, 2);`, "1010,2")
+	testInputOutput(t, `<?php class C { function m() { return func_num_args() . func_get_arg(1); } } $c = new C(); echo $c->m('a', 'b');`, "2b")
+	testInputOutput(t, `<?php $f = function () { return func_get_args(); }; echo implode(',', $f(1, 2));`, "1,2")
+	testForError(t, `<?php func_get_args();`, phpError.NewError("Uncaught Error: func_get_args() cannot be called from the global scope in %s:1:7", TEST_FILE_NAME))
+	testForError(t, `<?php func_num_args();`, phpError.NewError("Uncaught Error: func_num_args() must be called from a function context in %s:1:7", TEST_FILE_NAME))
+	testForError(t, `<?php function f() { func_get_arg(1); } f(1);`, phpError.NewError(
+		"Uncaught ValueError: func_get_arg(): Argument #1 ($position) must be less than the number of the arguments passed to the currently executed function in %s:1:22",
+		TEST_FILE_NAME,
+	))
+	// is_callable
+	testInputOutput(t,
+		`<?php class C { function m() {} static function s() {} function __invoke() {} } function f() {} $c = new C();
+        var_dump(is_callable('f'), is_callable('strlen'), is_callable('nope'), is_callable('C::s'), is_callable('C::m'), is_callable([$c, 'm']),
+            is_callable(['C', 'x']), is_callable($c), is_callable(function () {}), is_callable(new stdClass()), is_callable(42));`,
+		"bool(true)\nbool(true)\nbool(false)\nbool(true)\nbool(false)\nbool(true)\nbool(false)\nbool(true)\nbool(true)\nbool(false)\nbool(false)\n")
+	testInputOutput(t, `<?php var_dump(is_callable('nope', true), is_callable(['X', 'y'], true), is_callable([1, 'y'], true));`, "bool(true)\nbool(true)\nbool(false)\n")
+	testInputOutput(t,
+		`<?php class C { function m() {} } $c = new C();
+        is_callable('strlen', false, $a); is_callable([$c, 'm'], false, $b); is_callable(function () {}, false, $d); var_dump(is_callable(42, false, $e)); echo "$a $b $d $e";`,
+		"bool(false)\nstrlen C::m Closure::__invoke 42")
+	// Callback validation of other functions
+	testForError(t, `<?php set_error_handler('nope');`, phpError.NewError(
+		`Uncaught TypeError: set_error_handler(): Argument #1 ($callback) must be a valid callback or null, function "nope" not found or invalid function name in %s:1:7`, TEST_FILE_NAME,
+	))
+	testInputOutput(t, `<?php var_dump(ob_start('nope'));`, fmt.Sprintf(
+		"\nWarning: ob_start(): function \"nope\" not found or invalid function name in %[1]s:1:16\n\nNotice: ob_start(): Failed to create buffer in %[1]s:1:16\nbool(false)\n", TEST_FILE_NAME,
+	))
+	// register_shutdown_function
+	testInputOutput(t,
+		`<?php register_shutdown_function(function ($a) { echo "s1$a,"; register_shutdown_function(function () { echo 's3,'; }); }, 'x');
+        register_shutdown_function('var_dump', 's2'); echo 'end,';`,
+		"end,s1x,string(2) \"s2\"\ns3,")
+	testInputOutput(t, `<?php register_shutdown_function(function () { echo 's1,'; exit(); }); register_shutdown_function(function () { echo 's2,'; }); exit('exit,');`, "exit,s1,")
+	testInputOutput(t,
+		`<?php class D { function __destruct() { echo 'destruct,'; } function m() { echo 'm,'; } }
+        register_shutdown_function([new D(), 'm']); register_shutdown_function(function () { echo 's2,'; });`,
+		"m,s2,destruct,")
+}
+
 // -------------------------------------- classes_object -------------------------------------- MARK: classesobject
 
 func TestLibClassesObject(t *testing.T) {
@@ -342,6 +1720,13 @@ func TestLibClassesObject(t *testing.T) {
 	testInputOutput(t, `<?php class C {} var_dump(class_exists('c'));`, "bool(true)\n")
 	testInputOutput(t, `<?php var_dump(class_exists('c'));`, "bool(false)\n")
 
+	// get_called_class
+	testInputOutput(t,
+		`<?php class A { static function s() { return get_called_class(); } function m() { return get_called_class(); } } class B extends A {} $b = new B;
+        echo A::s(), B::s(), $b->m();`,
+		"ABB")
+	testForError(t, `<?php get_called_class();`, phpError.NewError("Uncaught Error: get_called_class() must be called from within a class in %s:1:7", TEST_FILE_NAME))
+
 	// get_class
 	testInputOutput(t, `<?php class Foo {} $bar = new Foo; var_dump(get_class($bar));`, "string(3) \"Foo\"\n")
 	testInputOutput(t, `<?php namespace Space; class Foo {} $bar = new Foo; var_dump(get_class($bar));`, "string(9) \"Space\\Foo\"\n")
@@ -436,6 +1821,145 @@ func TestLibArray(t *testing.T) {
 	testInputOutput(t, `<?php $a = [1,2,3]; var_dump(array_rand($a, 3));`, "array(3) {\n  [0]=>\n  int(0)\n  [1]=>\n  int(1)\n  [2]=>\n  int(2)\n}\n")
 	testInputOutput(t, `<?php $a = [1,2,3]; echo gettype(array_rand($a, 1));`, "integer")
 	testInputOutput(t, `<?php $a = ["a" => 1, "b" => 2, "c" => 3]; echo gettype(array_rand($a, 1));`, "string")
+	testInputOutput(t, `<?php mt_srand(3); $a = ["a" => 1, "b" => 2, "c" => 3, "d" => 4]; echo implode(",", array_rand($a, 2));`, "a,c")
+
+	// array_pop / array_push / array_shift / array_unshift
+	testInputOutput(t, `<?php $a = [1, 2, 3]; echo array_pop($a); echo count($a);`, "32")
+	testInputOutput(t, `<?php $a = [1]; echo array_push($a, 2, 3); echo implode(",", $a);`, "31,2,3")
+	testInputOutput(t, `<?php $a = [5 => "a", "k" => "b", 7 => "c"]; echo array_shift($a); echo serialize($a);`, `aa:2:{s:1:"k";s:1:"b";i:0;s:1:"c";}`)
+	testInputOutput(t, `<?php $a = [5 => "a", "k" => "b"]; echo array_unshift($a, "x"); echo serialize($a);`, `3a:3:{i:0;s:1:"x";i:1;s:1:"a";s:1:"k";s:1:"b";}`)
+
+	// array_map / array_filter / array_reduce / array_walk
+	testInputOutput(t, `<?php echo serialize(array_map(fn($x) => $x * 2, ["a" => 1, "b" => 2]));`, `a:2:{s:1:"a";i:2;s:1:"b";i:4;}`)
+	testInputOutput(t, `<?php echo serialize(array_map(fn($x, $y) => $x . $y, [1, 2], ["a"]));`, `a:2:{i:0;s:2:"1a";i:1;s:1:"2";}`)
+	testInputOutput(t, `<?php echo serialize(array_map(null, [1], ["a"]));`, `a:1:{i:0;a:2:{i:0;i:1;i:1;s:1:"a";}}`)
+	testInputOutput(t, `<?php echo serialize(array_filter([1, 0, 2, null, 3]));`, `a:3:{i:0;i:1;i:2;i:2;i:4;i:3;}`)
+	testInputOutput(t, `<?php echo serialize(array_filter(["a" => 1, "b" => 2], fn($k) => $k == "b", ARRAY_FILTER_USE_KEY));`, `a:1:{s:1:"b";i:2;}`)
+	testInputOutput(t, `<?php echo serialize(array_filter(["a" => 1, "b" => 2], fn($v, $k) => $v == 1 && $k == "a", ARRAY_FILTER_USE_BOTH));`, `a:1:{s:1:"a";i:1;}`)
+	testInputOutput(t, `<?php echo array_reduce([1, 2, 3], fn($carry, $item) => $carry + $item, 10);`, "16")
+	testInputOutput(t, `<?php $a = ["a" => 1, "b" => 2]; array_walk($a, function(&$v, $k, $p) { $v = $p . $k . $v; }, "-"); echo serialize($a);`, `a:2:{s:1:"a";s:3:"-a1";s:1:"b";s:3:"-b2";}`)
+	testInputOutput(t, `<?php $a = [[1, 2], 3]; array_walk_recursive($a, function(&$v) { $v = $v * 10; }); echo serialize($a);`, `a:2:{i:0;a:2:{i:0;i:10;i:1;i:20;}i:1;i:30;}`)
+	testForError(t, `<?php array_map("unknown", [1]);`, phpError.NewError(`Uncaught TypeError: array_map(): Argument #1 ($callback) must be a valid callback or null, function "unknown" not found or invalid function name in %s:1:7`, TEST_FILE_NAME))
+
+	// array_merge / array_merge_recursive / array_replace / array_replace_recursive
+	testInputOutput(t, `<?php echo serialize(array_merge(["color" => "red", 2, 4], ["a", "color" => "green", 4]));`, `a:5:{s:5:"color";s:5:"green";i:0;i:2;i:1;i:4;i:2;s:1:"a";i:3;i:4;}`)
+	testInputOutput(t, `<?php echo serialize(array_merge_recursive(["c" => ["f" => "red"], 5], [10, "c" => ["f" => "green", "blue"]]));`, `a:3:{s:1:"c";a:2:{s:1:"f";a:2:{i:0;s:3:"red";i:1;s:5:"green";}i:0;s:4:"blue";}i:0;i:5;i:1;i:10;}`)
+	testInputOutput(t, `<?php echo serialize(array_replace(["a", "b", "c"], [1 => "x"], [3 => "y"]));`, `a:4:{i:0;s:1:"a";i:1;s:1:"x";i:2;s:1:"c";i:3;s:1:"y";}`)
+	testInputOutput(t, `<?php echo serialize(array_replace_recursive(["c" => ["o", "l"]], ["c" => [1 => "x"]]));`, `a:1:{s:1:"c";a:2:{i:0;s:1:"o";i:1;s:1:"x";}}`)
+
+	// array_slice / array_splice
+	testInputOutput(t, `<?php echo serialize(array_slice(["a", "b", "c", "d", "e"], 2, -1));`, `a:2:{i:0;s:1:"c";i:1;s:1:"d";}`)
+	testInputOutput(t, `<?php echo serialize(array_slice(["a", "b", "c", "d", "e"], -2, 1, true));`, `a:1:{i:3;s:1:"d";}`)
+	testInputOutput(t, `<?php $a = [1, 2, 3, 4]; $r = array_splice($a, 1, 2, ["x", "y", "z"]); echo implode(",", $a) . "|" . implode(",", $r);`, "1,x,y,z,4|2,3")
+	testInputOutput(t, `<?php $a = [1, 2, 3]; array_splice($a, -1, 1, "x"); echo implode(",", $a);`, "1,2,x")
+
+	// array_search / in_array
+	testInputOutput(t, `<?php var_dump(array_search("1", [0, 1, 2]));`, "int(1)\n")
+	testInputOutput(t, `<?php var_dump(array_search("1", [0, 1, 2], true));`, "bool(false)\n")
+	testInputOutput(t, `<?php var_dump(in_array("1e1", ["10"]));`, "bool(true)\n")
+	testInputOutput(t, `<?php var_dump(in_array("1e1", ["10"], true));`, "bool(false)\n")
+
+	// range
+	testInputOutput(t, `<?php echo implode(",", range(0, 12, 4));`, "0,4,8,12")
+	testInputOutput(t, `<?php echo implode(",", range(5, 1, 2));`, "5,3,1")
+	testInputOutput(t, `<?php echo implode(",", range("a", "e", 2));`, "a,c,e")
+	testInputOutput(t, `<?php echo implode(",", range(0, 1, 0.25));`, "0,0.25,0.5,0.75,1")
+	testForError(t, `<?php range(1, 2, 0);`, phpError.NewError("Uncaught ValueError: range(): Argument #3 ($step) cannot be 0 in %s:1:7", TEST_FILE_NAME))
+
+	// compact / extract
+	testInputOutput(t, `<?php $city = "SF"; $state = "CA"; echo serialize(compact("city", ["state"]));`, `a:2:{s:4:"city";s:2:"SF";s:5:"state";s:2:"CA";}`)
+	testInputOutput(t, `<?php function f() { $a = 1; return compact("a"); } echo serialize(f());`, `a:1:{s:1:"a";i:1;}`)
+	testInputOutput(t, `<?php $size = "large"; echo extract(["color" => "blue", "size" => "medium"], EXTR_PREFIX_SAME, "p"); echo $color . $size . $p_size;`, "2bluelargemedium")
+	testInputOutput(t, `<?php $a = 1; echo extract(["a" => 2, "b" => 3], EXTR_SKIP); echo $a . $b;`, "113")
+	testInputOutput(t, `<?php $arr = ["a" => 1]; extract($arr, EXTR_REFS); $a = 2; echo $arr["a"];`, "2")
+
+	// array_combine / array_fill / array_fill_keys / array_chunk / array_column / array_unique
+	testInputOutput(t, `<?php echo serialize(array_combine(["green", "red"], ["avocado", "apple"]));`, `a:2:{s:5:"green";s:7:"avocado";s:3:"red";s:5:"apple";}`)
+	testForError(t, `<?php array_combine([1], []);`, phpError.NewError("Uncaught ValueError: array_combine(): Argument #1 ($keys) and argument #2 ($values) must have the same number of elements in %s:1:7", TEST_FILE_NAME))
+	testInputOutput(t, `<?php echo serialize(array_fill(5, 2, "x"));`, `a:2:{i:5;s:1:"x";i:6;s:1:"x";}`)
+	testInputOutput(t, `<?php echo serialize(array_fill_keys(["a", 5], 0));`, `a:2:{s:1:"a";i:0;i:5;i:0;}`)
+	testInputOutput(t, `<?php echo serialize(array_chunk(["a", "b", "c"], 2, true));`, `a:2:{i:0;a:2:{i:0;s:1:"a";i:1;s:1:"b";}i:1;a:1:{i:2;s:1:"c";}}`)
+	testForError(t, `<?php array_chunk([1], 0);`, phpError.NewError("Uncaught ValueError: array_chunk(): Argument #2 ($length) must be greater than 0 in %s:1:7", TEST_FILE_NAME))
+	testInputOutput(t, `<?php echo serialize(array_column([["id" => 3, "n" => "a"], ["id" => 5, "n" => "b"]], "n", "id"));`, `a:2:{i:3;s:1:"a";i:5;s:1:"b";}`)
+	testInputOutput(t, `<?php echo serialize(array_unique(["a" => "green", "red", "b" => "green", "blue", "red"]));`, `a:3:{s:1:"a";s:5:"green";i:0;s:3:"red";i:1;s:4:"blue";}`)
+	testInputOutput(t, `<?php echo serialize(array_unique([4, "4", "3", 4, 3, "3"]));`, `a:2:{i:0;i:4;i:2;s:1:"3";}`)
+
+	// array_diff / array_intersect
+	testInputOutput(t, `<?php echo serialize(array_diff(["a" => "green", "red", "blue", "red"], ["b" => "green", "yellow", "red"]));`, `a:1:{i:1;s:4:"blue";}`)
+	testInputOutput(t, `<?php echo serialize(array_diff_assoc(["a" => "green", "b" => "brown", "red"], ["a" => "green", "yellow", "red"]));`, `a:2:{s:1:"b";s:5:"brown";i:0;s:3:"red";}`)
+	testInputOutput(t, `<?php echo serialize(array_diff_key(["blue" => 1, "red" => 2, "green" => 3], ["green" => 5]));`, `a:2:{s:4:"blue";i:1;s:3:"red";i:2;}`)
+	testInputOutput(t, `<?php echo serialize(array_diff_ukey(["blue" => 1, "red" => 2], ["blue" => 6], fn($a, $b) => $a <=> $b));`, `a:1:{s:3:"red";i:2;}`)
+	testInputOutput(t, `<?php echo serialize(array_diff_uassoc(["a" => 1, "b" => 2], ["A" => 1, "b" => 2], fn($a, $b) => $a <=> $b));`, `a:1:{s:1:"a";i:1;}`)
+	testInputOutput(t, `<?php echo serialize(array_udiff([1, 5, 3], [3, 4], fn($a, $b) => $a <=> $b));`, `a:2:{i:0;i:1;i:1;i:5;}`)
+	testInputOutput(t, `<?php echo serialize(array_intersect(["a" => "green", "red", "blue"], ["b" => "green", "yellow", "red"]));`, `a:2:{s:1:"a";s:5:"green";i:0;s:3:"red";}`)
+	testInputOutput(t, `<?php echo serialize(array_intersect_assoc(["a" => "green", "b" => "brown"], ["a" => "green", "b" => "yellow"]));`, `a:1:{s:1:"a";s:5:"green";}`)
+	testInputOutput(t, `<?php echo serialize(array_intersect_key(["blue" => 1, "red" => 2, "green" => 3], ["green" => 5, "blue" => 6]));`, `a:2:{s:4:"blue";i:1;s:5:"green";i:3;}`)
+	testInputOutput(t, `<?php echo serialize(array_intersect_ukey(["blue" => 1, "red" => 2], ["blue" => 6], fn($a, $b) => $a <=> $b));`, `a:1:{s:4:"blue";i:1;}`)
+	testInputOutput(t, `<?php echo serialize(array_uintersect_uassoc(["a" => "x", "b" => "y"], ["a" => "x", "b" => "z"], fn($a, $b) => $a <=> $b, fn($a, $b) => $a <=> $b));`, `a:1:{s:1:"a";s:1:"x";}`)
+	testForError(t, `<?php array_diff([1], 2);`, phpError.NewError("Uncaught TypeError: array_diff(): Argument #2 ($arrays) must be of type array, int given in %s:1:7", TEST_FILE_NAME))
+
+	// Other array functions
+	testInputOutput(t, `<?php echo serialize(array_values(["a" => 1, "b" => 2]));`, `a:2:{i:0;i:1;i:1;i:2;}`)
+	testInputOutput(t, `<?php echo serialize(array_reverse(["php", 4, "k" => "v"]));`, `a:3:{s:1:"k";s:1:"v";i:0;i:4;i:1;s:3:"php";}`)
+	testInputOutput(t, `<?php echo serialize(array_reverse(["php", 4], true));`, `a:2:{i:1;i:4;i:0;s:3:"php";}`)
+	testInputOutput(t, `<?php echo serialize(array_pad([1, 2], -4, 0));`, `a:4:{i:0;i:0;i:1;i:0;i:2;i:1;i:3;i:2;}`)
+	testInputOutput(t, `<?php var_dump(array_sum([1, 2.5, "3"]));`, "float(6.5)\n")
+	testInputOutput(t, `<?php var_dump(array_product([2, "3"]));`, "int(6)\n")
+	testInputOutput(t, `<?php echo serialize(array_count_values([1, "hello", 1, "world", "hello"]));`, `a:3:{i:1;i:2;s:5:"hello";i:2;s:5:"world";i:1;}`)
+	testInputOutput(t, `<?php var_dump(array_is_list([1, 2]), array_is_list([1 => 1]));`, "bool(true)\nbool(false)\n")
+	testInputOutput(t, `<?php echo serialize(array_keys([1, "1", 2, 1], 1));`, `a:3:{i:0;i:0;i:1;i:1;i:2;i:3;}`)
+	testInputOutput(t, `<?php echo serialize(array_change_key_case(["FirSt" => 1, 5 => 2], CASE_UPPER));`, `a:2:{s:5:"FIRST";i:1;i:5;i:2;}`)
+	testInputOutput(t, `<?php var_dump(array_find([1, 2, 3], fn($v) => $v > 1), array_find_key(["a" => 1, "b" => 2], fn($v) => $v > 1));`, "int(2)\nstring(1) \"b\"\n")
+	testInputOutput(t, `<?php var_dump(array_all([1, 2], fn($v) => $v > 0), array_any([1, 2], fn($v) => $v > 5));`, "bool(true)\nbool(false)\n")
+	testInputOutput(t, `<?php echo count([1, [2, 3]], COUNT_RECURSIVE) . sizeof([1, 2]);`, "42")
+	testInputOutput(t, `<?php $a = [1, 2, 3]; shuffle($a); sort($a); echo implode(",", $a);`, "1,2,3")
+	testInputOutput(t, `<?php mt_srand(3); $a = [1, 2, 3, 4, 5]; shuffle($a); echo implode(",", $a);`, "4,3,5,1,2")
+}
+
+// -------------------------------------- array sort -------------------------------------- MARK: array sort
+
+func TestLibArraySort(t *testing.T) {
+	// sort / rsort
+	testInputOutput(t, `<?php $a = [3, "10", 1, "9"]; var_dump(sort($a)); echo implode(",", $a);`, "bool(true)\n1,3,9,10")
+	testInputOutput(t, `<?php $a = [3, "10", 1, "9"]; sort($a, SORT_STRING); echo implode(",", $a);`, "1,10,3,9")
+	testInputOutput(t, `<?php $a = ["b", "A", "c"]; sort($a, SORT_STRING | SORT_FLAG_CASE); echo implode(",", $a);`, "A,b,c")
+	testInputOutput(t, `<?php $a = ["10", "9.5", "1e1"]; sort($a, SORT_NUMERIC); echo implode(",", $a);`, "9.5,10,1e1")
+	testInputOutput(t, `<?php $a = ["k" => 3, 1, 2]; rsort($a); echo serialize($a);`, `a:3:{i:0;i:3;i:1;i:2;i:2;i:1;}`)
+
+	// asort / arsort / ksort / krsort
+	testInputOutput(t, `<?php $a = ["b" => 2, "a" => 1, "c" => 3]; asort($a); echo serialize($a);`, `a:3:{s:1:"a";i:1;s:1:"b";i:2;s:1:"c";i:3;}`)
+	testInputOutput(t, `<?php $a = ["b" => 2, "a" => 1, "c" => 3]; arsort($a); echo implode(",", array_keys($a));`, "c,b,a")
+	testInputOutput(t, `<?php $a = ["b" => 2, "a" => 1, 10 => 0, 9 => 0]; ksort($a); echo implode(",", array_keys($a));`, "9,10,a,b")
+	testInputOutput(t, `<?php $a = ["b" => 2, "a" => 1, "c" => 3]; krsort($a); echo implode(",", array_keys($a));`, "c,b,a")
+	testInputOutput(t, `<?php $a = ["x10", "x9", "X1"]; ksort($a, SORT_NATURAL); sort($a, SORT_NATURAL | SORT_FLAG_CASE); echo implode(",", $a);`, "X1,x9,x10")
+
+	// Stable sort
+	testInputOutput(t, `<?php $a = ["b1" => 1, "a1" => 1, "c0" => 0, "a0" => 0]; asort($a); echo implode(",", array_keys($a));`, "c0,a0,b1,a1")
+	testInputOutput(t, `<?php $a = ["b1" => 1, "a1" => 1, "c0" => 0, "a0" => 0]; arsort($a); echo implode(",", array_keys($a));`, "b1,a1,c0,a0")
+
+	// usort / uasort / uksort
+	testInputOutput(t, `<?php $a = [3, 1, 2]; usort($a, fn($x, $y) => $y <=> $x); echo implode(",", $a);`, "3,2,1")
+	testInputOutput(t, `<?php $a = ["x" => 3, "y" => 1]; uasort($a, fn($x, $y) => $x <=> $y); echo serialize($a);`, `a:2:{s:1:"y";i:1;s:1:"x";i:3;}`)
+	testInputOutput(t, `<?php $a = ["b" => 1, "a" => 2]; uksort($a, fn($x, $y) => $x <=> $y); echo serialize($a);`, `a:2:{s:1:"a";i:2;s:1:"b";i:1;}`)
+	testInputOutput(t, `<?php class C { public $items = [3, 1, 2]; } $c = new C(); usort($c->items, fn($x, $y) => $x <=> $y); echo implode(",", $c->items);`, "1,2,3")
+	testInputOutput(t, `<?php $a = ["l" => [2, 1]]; sort($a["l"]); echo implode(",", $a["l"]);`, "1,2")
+	testInputOutput(t, `<?php $a = [3, 1, 2]; $b = $a; sort($b); echo implode(",", $a);`, "3,1,2")
+	testInputOutput(t, `<?php $a = [3, 1, 2]; usort($a, fn($x, $y) => $x > $y); echo implode(",", $a);`,
+		fmt.Sprintf("\nDeprecated: usort(): Returning bool from comparison function is deprecated, return an integer less than, equal to, or greater than zero in %s:1:23\n1,2,3", TEST_FILE_NAME),
+	)
+	testForError(t, `<?php $a = [1]; usort($a, "unknown");`, phpError.NewError(`Uncaught TypeError: usort(): Argument #2 ($callback) must be a valid callback, function "unknown" not found or invalid function name in %s:1:17`, TEST_FILE_NAME))
+
+	// natsort / natcasesort
+	testInputOutput(t, `<?php $a = ["img12.png", "img10.png", "IMG2.png", "img1.png"]; natsort($a); echo serialize($a);`, `a:4:{i:2;s:8:"IMG2.png";i:3;s:8:"img1.png";i:1;s:9:"img10.png";i:0;s:9:"img12.png";}`)
+	testInputOutput(t, `<?php $a = ["img12.png", "img10.png", "IMG2.png", "img1.png"]; natcasesort($a); echo implode(",", $a);`, "img1.png,IMG2.png,img10.png,img12.png")
+
+	// array_multisort
+	testInputOutput(t, `<?php $a = [3, 1, 2]; $b = ["c", "a", "b"]; var_dump(array_multisort($a, $b)); echo implode(",", $a) . implode(",", $b);`, "bool(true)\n1,2,3a,b,c")
+	testInputOutput(t, `<?php $a = [1, 1, 2]; $b = [1, 2, 3]; array_multisort($a, SORT_DESC, $b, SORT_ASC); echo implode(",", $a) . "|" . implode(",", $b);`, "2,1,1|3,1,2")
+	testInputOutput(t, `<?php $a = ["x" => 2, 5 => 1]; array_multisort($a); echo serialize($a);`, `a:2:{i:0;i:1;s:1:"x";i:2;}`)
+	testInputOutput(t, `<?php $a = ["10", 9, "1e1"]; array_multisort($a, SORT_DESC, SORT_STRING); echo implode(",", $a);`, "9,1e1,10")
+	testForError(t, `<?php $a = [1, 2]; $b = [1]; array_multisort($a, $b);`, phpError.NewError("Uncaught ValueError: Array sizes are inconsistent in %s:1:30", TEST_FILE_NAME))
+	testForError(t, `<?php $a = [1]; array_multisort($a, SORT_ASC, SORT_DESC);`, phpError.NewError("Uncaught TypeError: array_multisort(): Argument #3 ($rest) must be an array or a sort flag that has not already been specified in %s:1:17", TEST_FILE_NAME))
 }
 
 // -------------------------------------- directory -------------------------------------- MARK: directory
@@ -443,6 +1967,230 @@ func TestLibArray(t *testing.T) {
 func TestLibDirectory(t *testing.T) {
 	// getcwd
 	testInputOutput(t, `<?php echo getcwd();`, TEST_FILE_PATH)
+
+	dir := t.TempDir()
+	testInputOutput(t, fmt.Sprintf(`<?php mkdir('%s/sub'); touch('%s/b.txt'); touch('%s/a.txt');`, dir, dir, dir), "")
+
+	// chdir
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(chdir('%s/sub'), getcwd() === '%s/sub', chdir('..'), getcwd() === '%s', file_exists('a.txt'));`, dir, dir, dir),
+		"bool(true)\nbool(true)\nbool(true)\nbool(true)\nbool(true)\n")
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(chdir('%s/nope'));`, dir),
+		fmt.Sprintf("\nWarning: chdir(): No such file or directory (errno 2) in %s:1:16\nbool(false)\n", TEST_FILE_NAME))
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(chdir('%s/a.txt'));`, dir),
+		fmt.Sprintf("\nWarning: chdir(): Not a directory (errno 20) in %s:1:16\nbool(false)\n", TEST_FILE_NAME))
+
+	// opendir, readdir, rewinddir, closedir
+	testInputOutput(t, fmt.Sprintf(`<?php $d = opendir('%s'); while (($e = readdir($d)) !== false) { echo $e, ","; } rewinddir($d); echo readdir($d), readdir(), ","; closedir(); var_dump($d);`, dir),
+		".,..,a.txt,b.txt,sub,...,resource(4) of type (Unknown)\n")
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(opendir('%s/nope'));`, dir),
+		fmt.Sprintf("\nWarning: opendir(%s/nope): Failed to open directory: No such file or directory in %s:1:16\nbool(false)\n", dir, TEST_FILE_NAME))
+	testForError(t, `<?php readdir();`, phpError.NewError("Uncaught TypeError: No resource supplied"))
+	testForError(t, `<?php $f = fopen('php://memory', 'r'); readdir($f);`,
+		phpError.NewError("Uncaught TypeError: readdir(): Argument #1 ($dir_handle) must be a valid Directory resource"))
+	testForError(t, fmt.Sprintf(`<?php $d = opendir('%s'); closedir($d); readdir($d);`, dir),
+		phpError.NewError("Uncaught TypeError: readdir(): supplied resource is not a valid Directory resource"))
+
+	// scandir
+	testInputOutput(t, fmt.Sprintf(`<?php echo implode(",", scandir('%s')), "|", implode(",", scandir('%s', SCANDIR_SORT_DESCENDING)), "|", count(scandir('%s', SCANDIR_SORT_NONE));`, dir, dir, dir),
+		".,..,a.txt,b.txt,sub|sub,b.txt,a.txt,..,.|5")
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(scandir('%s/nope'));`, dir),
+		fmt.Sprintf("\nWarning: scandir(%s/nope): Failed to open directory: No such file or directory in %s:1:16\n\nWarning: scandir(): (errno 2): No such file or directory in %s:1:16\nbool(false)\n", dir, TEST_FILE_NAME, TEST_FILE_NAME))
+	testForError(t, `<?php scandir('');`, phpError.NewError("Uncaught ValueError: scandir(): Argument #1 ($directory) cannot be empty"))
+
+	// dir
+	testInputOutput(t, fmt.Sprintf(`<?php $d = dir('%s/sub'); echo get_class($d), $d->path === '%s/sub' ? "y" : "n", $d->read(), $d->read(); var_dump($d->read()); $d->rewind(); echo $d->read(); $d->close();`, dir, dir),
+		"Directoryy...bool(false)\n.")
+	testForError(t, `<?php $d = new Directory(); $d->read();`, phpError.NewError("Uncaught Error: Unable to find my handle property"))
+}
+
+// -------------------------------------- filesystem -------------------------------------- MARK: filesystem
+
+func TestLibFilesystem(t *testing.T) {
+	// fopen
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'r+'); var_dump($f);`, "resource(4) of type (stream)\n")
+	testInputOutput(t, `<?php var_dump(fopen('/nonexistent/file.txt', 'r'));`,
+		fmt.Sprintf("\nWarning: fopen(/nonexistent/file.txt): Failed to open stream: No such file or directory in %s:1:16\nbool(false)\n", TEST_FILE_NAME))
+	testInputOutput(t, `<?php var_dump(fopen('php://memory', 'z'));`,
+		fmt.Sprintf("\nWarning: fopen(php://memory): Failed to open stream: `z' is not a valid mode for fopen in %s:1:16\nbool(false)\n", TEST_FILE_NAME))
+	testForError(t, `<?php fopen('', 'r');`, phpError.NewError("Uncaught ValueError: Path cannot be empty"))
+
+	// fclose
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'r+'); var_dump(fclose($f)); var_dump($f);`, "bool(true)\nresource(4) of type (Unknown)\n")
+	testForError(t, `<?php $f = fopen('php://memory', 'r+'); fclose($f); fread($f, 1);`,
+		phpError.NewError("Uncaught TypeError: fread(): supplied resource is not a valid stream resource"))
+
+	// fwrite, fread, feof
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'w+'); var_dump(fwrite($f, "abcdef", 4)); rewind($f); var_dump(fread($f, 3), feof($f), fread($f, 3), feof($f));`,
+		"int(4)\nstring(3) \"abc\"\nbool(false)\nstring(1) \"d\"\nbool(true)\n")
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'r'); var_dump(fwrite($f, "abc"));`,
+		fmt.Sprintf("\nNotice: fwrite(): Write of 3 bytes failed with errno=9 Bad file descriptor in %s:1:49\nbool(false)\n", TEST_FILE_NAME))
+	testForError(t, `<?php $f = fopen('php://memory', 'r'); fread($f, 0);`,
+		phpError.NewError("Uncaught ValueError: fread(): Argument #2 ($length) must be greater than 0"))
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'w+'); fputs($f, "abc"); rewind($f); echo fread($f, 10);`, "abc")
+
+	// fgets, fgetc
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'w+'); fwrite($f, "ab\ncd"); rewind($f); var_dump(fgetc($f), fgets($f), fgets($f, 2), fgets($f), fgets($f));`,
+		"string(1) \"a\"\nstring(2) \"b\n\"\nstring(1) \"c\"\nstring(1) \"d\"\nbool(false)\n")
+
+	// fseek, ftell, rewind, ftruncate
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'w+'); fwrite($f, "abcdef"); var_dump(ftell($f), fseek($f, 2), fgetc($f), fseek($f, -2, SEEK_END), fgetc($f), fseek($f, -10, SEEK_CUR));`,
+		"int(6)\nint(0)\nstring(1) \"c\"\nint(0)\nstring(1) \"e\"\nint(-1)\n")
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'w+'); fwrite($f, "abcdef"); var_dump(ftruncate($f, 2)); rewind($f); var_dump(fread($f, 10));`,
+		"bool(true)\nstring(2) \"ab\"\n")
+	testInputOutput(t, `<?php $f = fopen('php://output', 'w'); var_dump(ftruncate($f, 0));`,
+		fmt.Sprintf("\nWarning: ftruncate(): Can't truncate this stream! in %s:1:49\nbool(false)\n", TEST_FILE_NAME))
+
+	// fputcsv, fgetcsv
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'w+'); fputcsv($f, ['a', 'b c', 'd"e', 'f,g', 1.5]); rewind($f); echo fgets($f);`,
+		"a,\"b c\",\"d\"\"e\",\"f,g\",1.5\n")
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'w+'); fwrite($f, "a,\"b\nc\",d\n\n1;2"); rewind($f); var_dump(fgetcsv($f), fgetcsv($f), fgetcsv($f, null, ';'), fgetcsv($f));`,
+		"array(3) {\n  [0]=>\n  string(1) \"a\"\n  [1]=>\n  string(3) \"b\nc\"\n  [2]=>\n  string(1) \"d\"\n}\narray(1) {\n  [0]=>\n  NULL\n}\n"+
+			"array(2) {\n  [0]=>\n  string(1) \"1\"\n  [1]=>\n  string(1) \"2\"\n}\nbool(false)\n")
+	testForError(t, `<?php $f = fopen('php://memory', 'r'); fgetcsv($f, null, ';;');`,
+		phpError.NewError("Uncaught ValueError: fgetcsv(): Argument #3 ($separator) must be a single character"))
+
+	// fpassthru
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'w+'); fwrite($f, "abc"); fseek($f, 1); var_dump(fpassthru($f));`, "bcint(2)\n")
+
+	// fprintf, vfprintf
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'w+'); var_dump(fprintf($f, "%s-%03d", "a", 7)); vfprintf($f, "|%s", ["b"]); rewind($f); echo fread($f, 20);`,
+		"int(5)\na-007|b")
+
+	// php://output
+	testInputOutput(t, `<?php ob_start(); $f = fopen('php://output', 'w'); fwrite($f, "abc"); $b = ob_get_clean(); var_dump($b);`, "string(3) \"abc\"\n")
+
+	// php://input
+	testInputOutput(t, `<?php $f = fopen('php://input', 'r'); var_dump(fread($f, 10));`, "string(0) \"\"\n")
+
+	// STDIN, STDOUT, STDERR
+	testInputOutput(t, `<?php var_dump(STDIN, STDOUT, STDERR);`,
+		"resource(1) of type (stream)\nresource(2) of type (stream)\nresource(3) of type (stream)\n")
+	testInputOutput(t, `<?php echo "a"; fwrite(STDOUT, "b"); echo "c"; $o = fopen('php://stdout', 'w'); fwrite($o, "d"); echo "e";`, "abcde")
+	testInputOutput(t, `<?php ob_start(); echo "a"; fwrite(STDOUT, "b"); ob_end_flush();`, "ba")
+
+	// Files
+	filename := filepath.Join(t.TempDir(), "test.txt")
+	testInputOutput(t, fmt.Sprintf(`<?php $f = fopen('%s', 'w'); fwrite($f, "abc\n"); var_dump(flock($f, LOCK_EX, $wb), $wb, flock($f, LOCK_UN)); fclose($f);`, filename),
+		"bool(true)\nint(0)\nbool(true)\n")
+	testInputOutput(t, fmt.Sprintf(`<?php $f = fopen('%s', 'a+'); fwrite($f, "def"); rewind($f); var_dump(fread($f, 100), feof($f)); fclose($f);`, filename),
+		"string(7) \"abc\ndef\"\nbool(true)\n")
+	testForError(t, fmt.Sprintf(`<?php $f = fopen('%s', 'r'); flock($f, 8);`, filename),
+		phpError.NewError("Uncaught ValueError: flock(): Argument #2 ($operation) must be one of LOCK_SH, LOCK_EX, or LOCK_UN"))
+
+	// basename
+	testInputOutput(t, `<?php var_dump(basename('/etc/sudoers.d'), basename('/etc/sudoers.d', '.d'), basename('/etc/'), basename('/'), basename('.d', '.d'));`,
+		"string(9) \"sudoers.d\"\nstring(7) \"sudoers\"\nstring(3) \"etc\"\nstring(0) \"\"\nstring(2) \".d\"\n")
+
+	// dirname
+	testInputOutput(t, `<?php var_dump(dirname('/etc/passwd'), dirname('/etc/'), dirname('.'), dirname('file'), dirname('/'), dirname('/usr/local/lib', 2), dirname('/usr/local/lib', 10));`,
+		"string(4) \"/etc\"\nstring(1) \"/\"\nstring(1) \".\"\nstring(1) \".\"\nstring(1) \"/\"\nstring(4) \"/usr\"\nstring(1) \"/\"\n")
+	testForError(t, `<?php dirname('/a', 0);`, phpError.NewError("Uncaught ValueError: dirname(): Argument #2 ($levels) must be greater than or equal to 1"))
+
+	// pathinfo
+	testInputOutput(t, `<?php print_r(pathinfo('/www/htdocs/inc/lib.inc.php'));`,
+		"Array\n(\n    [dirname] => /www/htdocs/inc\n    [basename] => lib.inc.php\n    [extension] => php\n    [filename] => lib.inc\n)\n")
+	testInputOutput(t, `<?php print_r(pathinfo('noext'));`, "Array\n(\n    [dirname] => .\n    [basename] => noext\n    [filename] => noext\n)\n")
+	testInputOutput(t, `<?php var_dump(pathinfo('/a/b.c', PATHINFO_EXTENSION), pathinfo('/a/b', PATHINFO_EXTENSION), pathinfo('/a/b.c', PATHINFO_FILENAME));`,
+		"string(1) \"c\"\nstring(0) \"\"\nstring(1) \"b\"\n")
+
+	// fnmatch
+	testInputOutput(t, `<?php var_dump(fnmatch('*gr[ae]y', 'dark grey'), fnmatch('*.txt', '.txt', FNM_PERIOD), fnmatch('a/*', 'a/b/c', FNM_PATHNAME), fnmatch('a/*', 'a/b/c'));`,
+		"bool(true)\nbool(false)\nbool(false)\nbool(true)\n")
+	testInputOutput(t, `<?php var_dump(fnmatch('A?C', 'abc', FNM_CASEFOLD), fnmatch('[!a-c]x', 'dx'), fnmatch('\\*', '*'), fnmatch('\\*', 'a'), fnmatch('[[:digit:]]', '5'));`,
+		"bool(true)\nbool(true)\nbool(true)\nbool(false)\nbool(true)\n")
+
+	// file_put_contents, file_get_contents, file
+	dir := t.TempDir()
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(file_put_contents('%s/a.txt', "l1\n\nl3\r\n"), file_put_contents('%s/a.txt', ['x', 1], FILE_APPEND | LOCK_EX));`, dir, dir),
+		"int(8)\nint(2)\n")
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(file_get_contents('%s/a.txt'), file_get_contents('%s/a.txt', false, null, 4, 2), file_get_contents('%s/a.txt', false, null, -2));`, dir, dir, dir),
+		"string(10) \"l1\n\nl3\r\nx1\"\nstring(2) \"l3\"\nstring(2) \"x1\"\n")
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(file('%s/a.txt', FILE_IGNORE_NEW_LINES | FILE_SKIP_EMPTY_LINES));`, dir),
+		"array(3) {\n  [0]=>\n  string(2) \"l1\"\n  [1]=>\n  string(2) \"l3\"\n  [2]=>\n  string(2) \"x1\"\n}\n")
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(count(file('%s/a.txt')));`, dir), "int(4)\n")
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(file_get_contents('%s/nope.txt'));`, dir),
+		fmt.Sprintf("\nWarning: file_get_contents(%s/nope.txt): Failed to open stream: No such file or directory in %s:1:16\nbool(false)\n", dir, TEST_FILE_NAME))
+	testForError(t, `<?php file('a', 64);`, phpError.NewError("Uncaught ValueError: file(): Argument #2 ($flags) must be a valid flag value"))
+
+	// readfile
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(readfile('%s/a.txt'));`, dir), "l1\n\nl3\r\nx1int(10)\n")
+
+	// copy, rename, unlink
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(copy('%s/a.txt', '%s/b.txt'), rename('%s/b.txt', '%s/c.txt'), unlink('%s/c.txt'), file_exists('%s/c.txt'));`, dir, dir, dir, dir, dir, dir),
+		"bool(true)\nbool(true)\nbool(true)\nbool(false)\n")
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(unlink('%s/nope.txt'));`, dir),
+		fmt.Sprintf("\nWarning: unlink(%s/nope.txt): No such file or directory in %s:1:16\nbool(false)\n", dir, TEST_FILE_NAME))
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(copy('%s/nope.txt', '%s/b.txt'));`, dir, dir),
+		fmt.Sprintf("\nWarning: copy(%s/nope.txt): Failed to open stream: No such file or directory in %s:1:16\nbool(false)\n", dir, TEST_FILE_NAME))
+
+	// mkdir, rmdir
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(mkdir('%s/x/y', 0777, true), is_dir('%s/x/y'), mkdir('%s/x'));`, dir, dir, dir),
+		fmt.Sprintf("\nWarning: mkdir(): File exists in %s:1:%d\nbool(true)\nbool(true)\nbool(false)\n", TEST_FILE_NAME, 2*len(dir)+59))
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(rmdir('%s/x'));`, dir),
+		fmt.Sprintf("\nWarning: rmdir(%s/x): Directory not empty in %s:1:16\nbool(false)\n", dir, TEST_FILE_NAME))
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(rmdir('%s/x/y'), is_dir('%s/x/y'));`, dir, dir), "bool(true)\nbool(false)\n")
+
+	// glob
+	testInputOutput(t, fmt.Sprintf(`<?php touch('%s/x/b.php'); var_dump(glob('%s/*'), glob('%s/{x/*.php,*.txt}', GLOB_BRACE), glob('%s/*', GLOB_ONLYDIR | GLOB_MARK), glob('%s/*.none'));`, dir, dir, dir, dir, dir),
+		fmt.Sprintf("array(2) {\n  [0]=>\n  string(%d) \"%s/a.txt\"\n  [1]=>\n  string(%d) \"%s/x\"\n}\n", len(dir)+6, dir, len(dir)+2, dir)+
+			fmt.Sprintf("array(2) {\n  [0]=>\n  string(%d) \"%s/x/b.php\"\n  [1]=>\n  string(%d) \"%s/a.txt\"\n}\n", len(dir)+8, dir, len(dir)+6, dir)+
+			fmt.Sprintf("array(1) {\n  [0]=>\n  string(%d) \"%s/x/\"\n}\narray(0) {\n}\n", len(dir)+3, dir))
+
+	// stat family
+	testInputOutput(t, fmt.Sprintf(`<?php $s = stat('%s/a.txt'); var_dump(count($s), $s['size'], $s[7], filesize('%s/a.txt'), filetype('%s/a.txt'), filetype('%s/x'));`, dir, dir, dir, dir),
+		"int(26)\nint(10)\nint(10)\nint(10)\nstring(4) \"file\"\nstring(3) \"dir\"\n")
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(filesize('%s/nope.txt'), is_file('%s/nope.txt'), is_readable('%s/a.txt'), is_writable('%s/nope.txt'));`, dir, dir, dir, dir),
+		fmt.Sprintf("\nWarning: filesize(): stat failed for %s/nope.txt in %s:1:16\nbool(false)\nbool(false)\nbool(true)\nbool(false)\n", dir, TEST_FILE_NAME))
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(touch('%s/a.txt', 1000), filemtime('%s/a.txt'), fileatime('%s/a.txt'));`, dir, dir, dir),
+		"bool(true)\nint(1000)\nint(1000)\n")
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(chmod('%s/a.txt', 0640), sprintf('%%o', fileperms('%s/a.txt')));`, dir, dir),
+		"bool(true)\nstring(6) \"100640\"\n")
+
+	// stat cache
+	testInputOutput(t, fmt.Sprintf(`<?php $f = '%s/a.txt'; filesize($f); file_put_contents($f, 'abc'); var_dump(filesize($f)); clearstatcache(); var_dump(filesize($f));`, dir),
+		"int(10)\nint(3)\n")
+
+	// symlink, readlink, realpath
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(symlink('a.txt', '%s/l.txt'), is_link('%s/l.txt'), readlink('%s/l.txt'), filetype('%s/l.txt'), realpath('%s/x/../l.txt') === '%s/a.txt', realpath('%s/nope'));`, dir, dir, dir, dir, dir, dir, dir),
+		"bool(true)\nbool(true)\nstring(5) \"a.txt\"\nstring(4) \"link\"\nbool(true)\nbool(false)\n")
+
+	// tempnam, tmpfile, sys_get_temp_dir
+	testInputOutput(t, fmt.Sprintf(`<?php $n = tempnam('%s', 'pre'); var_dump(dirname($n) === '%s', strlen(basename($n)), file_exists($n));`, dir, dir),
+		"bool(true)\nint(9)\nbool(true)\n")
+	testInputOutput(t, `<?php $f = tmpfile(); fwrite($f, "abc"); rewind($f); var_dump(fread($f, 10));`, "string(3) \"abc\"\n")
+	devIni := ini.NewDevIni()
+	devIni.Set("sys_temp_dir", dir+"/", ini.INI_ALL)
+	testInputOutputCustomIni(t, devIni, `<?php var_dump(sys_get_temp_dir());`, fmt.Sprintf("string(%d) \"%s\"\n", len(dir), dir))
+	testInputOutputCustomIni(t, devIni, `<?php var_dump(dirname(tempnam('/nonexistent', 'pre')));`,
+		fmt.Sprintf("\nNotice: tempnam(): file created in the system's temporary directory in %s:1:24\nstring(%d) \"%s\"\n", TEST_FILE_NAME, len(dir), dir))
+	testInputOutputCustomIni(t, devIni, `<?php var_dump(dirname(tempnam('', 'pre')));`, fmt.Sprintf("string(%d) \"%s\"\n", len(dir), dir))
+	testInputOutputCustomIni(t, devIni, `<?php $f = tmpfile(); echo count(glob(sys_get_temp_dir() . '/php*')); $f = null; echo count(glob(sys_get_temp_dir() . '/php*'));`, "10")
+	testInputOutputCustomIni(t, devIni, `<?php fwrite(tmpfile(), "a"); echo count(glob(sys_get_temp_dir() . '/php*'));`, "0")
+	testInputOutputCustomIni(t, devIni, `<?php function f() { $f = tmpfile(); return [$f]; } $a = f(); echo count(glob(sys_get_temp_dir() . '/php*')); unset($a); echo count(glob(sys_get_temp_dir() . '/php*'));`, "10")
+
+	// open_basedir
+	testInputOutput(t, fmt.Sprintf(`<?php var_dump(mkdir('%s/allowed'), file_put_contents('%s/allowed/a.txt', 'abc'));`, dir, dir), "bool(true)\nint(3)\n")
+	basedirIni := ini.NewDevIni()
+	basedirIni.Set("open_basedir", dir+"/allowed", ini.INI_ALL)
+	testInputOutputCustomIni(t, basedirIni, fmt.Sprintf(`<?php var_dump(file_get_contents('%s/allowed/a.txt'), file_exists('%s/allowed/../a.txt'));`, dir, dir),
+		"string(3) \"abc\"\nbool(false)\n")
+	testInputOutputCustomIni(t, basedirIni, fmt.Sprintf(`<?php var_dump(file_get_contents('%s/a.txt'));`, dir),
+		fmt.Sprintf("\nWarning: file_get_contents(): open_basedir restriction in effect. File(%s/a.txt) is not within the allowed path(s): (%s/allowed) in %s:1:16\n"+
+			"\nWarning: file_get_contents(%s/a.txt): Failed to open stream: Operation not permitted in %s:1:16\nbool(false)\n", dir, dir, TEST_FILE_NAME, dir, TEST_FILE_NAME))
+	testInputOutputCustomIni(t, basedirIni, fmt.Sprintf(`<?php var_dump(unlink('%s/a.txt'), file_exists('%s/a.txt'));`, dir, dir),
+		fmt.Sprintf("\nWarning: unlink(): open_basedir restriction in effect. File(%s/a.txt) is not within the allowed path(s): (%s/allowed) in %s:1:16\nbool(false)\nbool(false)\n", dir, dir, TEST_FILE_NAME))
+	testInputOutputCustomIni(t, basedirIni, fmt.Sprintf(`<?php var_dump(glob('%s/*.txt'), glob('%s/allowed/*') === ['%s/allowed/a.txt']);`, dir, dir, dir),
+		"bool(false)\nbool(true)\n")
+	// - native methods have no position
+	testInputOutputCustomIni(t, basedirIni, fmt.Sprintf(`<?php try { new SplFileObject('%s/a.txt'); } catch (RuntimeException $e) { echo $e->getMessage(); }`, dir),
+		fmt.Sprintf("\nWarning: SplFileObject::__construct(): open_basedir restriction in effect. File(%s/a.txt) is not within the allowed path(s): (%s/allowed)\n"+
+			"SplFileObject::__construct(%s/a.txt): Failed to open stream: Operation not permitted", dir, dir, dir))
+	testInputOutputCustomIni(t, basedirIni, fmt.Sprintf(`<?php try { new DirectoryIterator('%s'); } catch (UnexpectedValueException $e) { echo $e->getMessage(); }`, dir),
+		fmt.Sprintf("\nWarning: DirectoryIterator::__construct(): open_basedir restriction in effect. File(%s) is not within the allowed path(s): (%s/allowed)\n"+
+			"DirectoryIterator::__construct(%s): Failed to open directory: Operation not permitted", dir, dir, dir))
+	// - ini_set can only tighten open_basedir
+	testInputOutputCustomIni(t, basedirIni, fmt.Sprintf(`<?php var_dump(ini_set('open_basedir', '%s'), ini_set('open_basedir', '%s/allowed/sub'), ini_get('open_basedir'));`, dir, dir),
+		fmt.Sprintf("bool(false)\nstring(%d) \"%s/allowed\"\nstring(%d) \"%s/allowed/sub\"\n", len(dir)+8, dir, len(dir)+12, dir))
 }
 
 // -------------------------------------- misc -------------------------------------- MARK: misc
@@ -484,6 +2232,16 @@ func TestLibVariableHandling(t *testing.T) {
 	testInputOutput(t, `<?php echo get_debug_type([]);`, "array")
 	testInputOutput(t, `<?php echo get_debug_type([42]);`, "array")
 	testInputOutput(t, `<?php echo get_debug_type(null);`, "null")
+	testInputOutput(t, `<?php echo get_debug_type(STDIN);`, "resource (stream)")
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'r'); fclose($f); echo get_debug_type($f);`, "resource (closed)")
+
+	// get_resource_id
+	testInputOutput(t, `<?php var_dump(get_resource_id(STDERR));`, "int(3)\n")
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'r'); var_dump(get_resource_id($f), (int)$f, (string)$f);`, "int(4)\nint(4)\nstring(14) \"Resource id #4\"\n")
+
+	// get_resource_type
+	testInputOutput(t, `<?php var_dump(get_resource_type(STDIN));`, "string(6) \"stream\"\n")
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'r'); fclose($f); var_dump(get_resource_type($f));`, "string(7) \"Unknown\"\n")
 
 	// gettype
 	testInputOutput(t, `<?php echo gettype(false);`, "boolean")
@@ -499,6 +2257,8 @@ func TestLibVariableHandling(t *testing.T) {
 	testInputOutput(t, `<?php echo gettype([]);`, "array")
 	testInputOutput(t, `<?php echo gettype([42]);`, "array")
 	testInputOutput(t, `<?php echo gettype(null);`, "NULL")
+	testInputOutput(t, `<?php echo gettype(STDIN);`, "resource")
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'r'); fclose($f); echo gettype($f);`, "resource (closed)")
 
 	// is_array
 	testInputOutput(t, `<?php $a = [true]; var_dump(is_array($a));`, "bool(true)\n")
@@ -520,6 +2280,11 @@ func TestLibVariableHandling(t *testing.T) {
 	testInputOutput(t, `<?php $a = null; var_dump(is_null($a));`, "bool(true)\n")
 	testInputOutput(t, `<?php $a = 42; var_dump(is_null($a));`, "bool(false)\n")
 
+	// is_resource
+	testInputOutput(t, `<?php var_dump(is_resource(STDIN));`, "bool(true)\n")
+	testInputOutput(t, `<?php $f = fopen('php://memory', 'r'); fclose($f); var_dump(is_resource($f));`, "bool(false)\n")
+	testInputOutput(t, `<?php var_dump(is_resource(1));`, "bool(false)\n")
+
 	// is_scalar
 	testInputOutput(t, `<?php $a = true; var_dump(is_scalar($a));`, "bool(true)\n")
 	testInputOutput(t, `<?php $a = false; var_dump(is_scalar($a));`, "bool(true)\n")
@@ -588,6 +2353,7 @@ func TestLibVariableHandling(t *testing.T) {
 	testInputOutput(t, `<?php class C {}; $c = new C; var_dump($c);`, "object(C)#1 (0) {\n}\n")
 	testInputOutput(t, `<?php class C { private $p;}; $c = new C; var_dump($c);`, "object(C)#1 (1) {\n  [\"p\":\"C\":private]=>\n  NULL\n}\n")
 	testInputOutput(t, `<?php namespace Space; class C {}; $c = new C; var_dump($c);`, "object(Space\\C)#1 (0) {\n}\n")
+	testInputOutput(t, `<?php class C { private $p;}; $c = new C; $c->d = 1; var_dump($c);`, "object(C)#1 (2) {\n  [\"p\":\"C\":private]=>\n  NULL\n  [\"d\":\"C\":public]=>\n  int(1)\n}\n")
 
 	// var_export
 	testInputOutput(t, `<?php var_export(3.5);`, "3.5")
@@ -600,3 +2366,191 @@ func TestLibVariableHandling(t *testing.T) {
 	testInputOutput(t, `<?php var_export([1,2]);`, "array (\n  0 => 1,\n  1 => 2,\n)")
 	testInputOutput(t, `<?php var_export([1, [1]]);`, "array (\n  0 => 1,\n  1 => \n  array (\n    0 => 1,\n  ),\n)")
 }
+
+// -------------------------------------- spl -------------------------------------- MARK: spl
+
+func TestLibSpl(t *testing.T) {
+	// ArrayObject
+	testInputOutput(t, `<?php $a = new ArrayObject([1, 2, "a" => 3]); $a[] = 4; unset($a[0]); echo count($a), isset($a["a"]) ? "y" : "n", $a["a"];`, "3y3")
+	testInputOutput(t, `<?php $a = new ArrayObject(["a" => 1, "b" => 2]); foreach ($a as $k => $v) { echo "$k=$v,"; }`, "a=1,b=2,")
+	testInputOutput(t, `<?php $a = new ArrayObject([1]); $it = $a->getIterator(); $a[] = 2; echo get_class($it), count($it);`, "ArrayIterator2")
+	testInputOutput(t, `<?php $a = new ArrayObject([1]); $old = $a->exchangeArray([2, 3]); echo count($old), count($a), $a->getArrayCopy()[1];`, "123")
+
+	// ArrayIterator
+	testInputOutput(t, `<?php $it = new ArrayIterator([3, 4, 5]); $it->seek(2); echo $it->key(), $it->current();`, "25")
+	testInputOutput(t, `<?php $it = new ArrayIterator([]); var_dump($it->valid(), $it->current());`, "bool(false)\nNULL\n")
+	testForError(t, `<?php $it = new ArrayIterator([]); $it->seek(1);`, phpError.NewError("Uncaught OutOfBoundsException: Seek position 1 is out of range"))
+
+	// SplStack
+	testInputOutput(t, `<?php $s = new SplStack(); $s->push(1); $s->push(2); $s[] = 3; echo $s->top(), $s->pop(), count($s);`, "332")
+	testInputOutput(t, `<?php $s = new SplStack(); $s->push(1); $s->push(2); foreach ($s as $k => $v) { echo "$k=$v,"; }`, "1=2,0=1,")
+	testForError(t, `<?php $s = new SplStack(); $s->pop();`, phpError.NewError("Uncaught RuntimeException: Can't pop from an empty datastructure"))
+	testForError(t, `<?php $s = new SplStack(); $s->setIteratorMode(SplDoublyLinkedList::IT_MODE_FIFO);`, phpError.NewError("Uncaught RuntimeException: Iterators' LIFO/FIFO modes for SplStack/SplQueue objects are frozen"))
+
+	// SplQueue
+	testInputOutput(t, `<?php $q = new SplQueue(); $q->enqueue("a"); $q->enqueue("b"); foreach ($q as $v) { echo $v; } echo $q->dequeue(), count($q);`, "aba1")
+	testInputOutput(t, `<?php $q = new SplQueue(); $q->setIteratorMode(SplDoublyLinkedList::IT_MODE_DELETE); $q[] = 1; $q[] = 2; foreach ($q as $v) { echo $v; } echo count($q);`, "120")
+	testForError(t, `<?php $q = new SplQueue(); $q->dequeue();`, phpError.NewError("Uncaught RuntimeException: Can't shift from an empty datastructure"))
+	testForError(t, `<?php $q = new SplQueue(); echo $q[0];`, phpError.NewError("Uncaught OutOfRangeException: SplDoublyLinkedList::offsetGet(): Argument #1 ($index) is out of range"))
+
+	// SplObjectStorage
+	testInputOutput(t,
+		`<?php $s = new SplObjectStorage(); $a = new stdClass(); $b = new stdClass();
+		$s->attach($a, "a"); $s[$b] = "b"; $s->attach($a, "c");
+		echo count($s), $s[$a], $s->contains($b) ? "y" : "n";
+		$s->detach($b); echo count($s), $s->contains($b) ? "y" : "n";`,
+		"2cy1n",
+	)
+	testInputOutput(t,
+		`<?php $s = new SplObjectStorage(); $a = new stdClass(); $b = new stdClass(); $s[$a] = 1; $s[$b] = 2;
+		foreach ($s as $i => $o) { echo $i, $s->getInfo(), $o === $b ? "b" : "a"; }`,
+		"01a12b",
+	)
+	testForError(t, `<?php $s = new SplObjectStorage(); echo $s[new stdClass()];`, phpError.NewError("Uncaught UnexpectedValueException: Object not found"))
+
+	// SplFixedArray
+	testInputOutput(t, `<?php $a = new SplFixedArray(3); $a[0] = 1; $a[2] = 3; foreach ($a as $k => $v) { echo $k, var_export($v, true), ","; } echo $a->getSize();`, "01,1NULL,23,3")
+	testInputOutput(t, `<?php $a = SplFixedArray::fromArray([1 => "a", 3 => "b"]); echo $a->getSize(), $a[3];`, "4b")
+	testInputOutput(t, `<?php $a = SplFixedArray::fromArray([1 => "a", 3 => "b"], false); echo $a->getSize(), $a[1];`, "2b")
+	testForError(t, `<?php $a = new SplFixedArray(1); echo $a[1];`, phpError.NewError("Uncaught RuntimeException: Index invalid or out of range"))
+	testForError(t, `<?php $a = new SplFixedArray(-1);`, phpError.NewError("Uncaught ValueError: SplFixedArray::__construct(): Argument #1 ($size) must be greater than or equal to 0"))
+
+	// SplPriorityQueue
+	testInputOutput(t,
+		`<?php $q = new SplPriorityQueue(); $q->insert("lo", 1); $q->insert("hi", 10); $q->insert("mid", 5); $q->insert("mid2", 5);
+		echo count($q), $q->top(), ":"; while ($q->valid()) { echo $q->extract(), ","; }`,
+		"4hi:hi,mid,mid2,lo,",
+	)
+	testInputOutput(t, `<?php $q = new SplPriorityQueue(); $q->setExtractFlags(SplPriorityQueue::EXTR_BOTH); $q->insert("x", 3); echo serialize($q->extract());`, `a:2:{s:4:"data";s:1:"x";s:8:"priority";i:3;}`)
+	testInputOutput(t,
+		`<?php class MinQueue extends SplPriorityQueue { public function compare($a, $b): int { return $b <=> $a; } }
+		$q = new MinQueue(); $q->insert("a", 3); $q->insert("b", 1); $q->insert("c", 2); foreach ($q as $v) { echo $v; } echo count($q);`,
+		"bca0",
+	)
+	testForError(t, `<?php $q = new SplPriorityQueue(); $q->extract();`, phpError.NewError("Uncaught RuntimeException: Can't extract from an empty heap"))
+
+	// IteratorIterator
+	testInputOutput(t, `<?php $it = new IteratorIterator(new ArrayObject(["a" => 1, "b" => 2])); foreach ($it as $k => $v) { echo "$k=$v,"; } echo get_class($it->getInnerIterator());`, "a=1,b=2,ArrayIterator")
+	testForError(t, `<?php new IteratorIterator(new stdClass());`, phpError.NewError("Uncaught TypeError: IteratorIterator::__construct(): Argument #1 ($iterator) must be of type Traversable, object given"))
+
+	// LimitIterator
+	testInputOutput(t, `<?php $it = new LimitIterator(new ArrayIterator([1, 2, 3, 4, 5]), 1, 3); foreach ($it as $k => $v) { echo "$k=$v,"; } echo $it->getPosition();`, "1=2,2=3,3=4,4")
+	testInputOutput(t, `<?php $it = new LimitIterator(new ArrayIterator([1, 2, 3]), 2); foreach ($it as $v) { echo $v; } echo $it->seek(2);`, "32")
+	testForError(t, `<?php $it = new LimitIterator(new ArrayIterator([1, 2, 3]), 1, 1); $it->seek(2);`, phpError.NewError("Uncaught OutOfBoundsException: Cannot seek to 2 which is behind offset 1 plus count 1"))
+	testForError(t, `<?php new LimitIterator(new ArrayIterator([]), -1);`, phpError.NewError("Uncaught ValueError: LimitIterator::__construct(): Argument #2 ($offset) must be greater than or equal to 0"))
+
+	// FilterIterator and CallbackFilterIterator
+	testInputOutput(t, `<?php $it = new CallbackFilterIterator(new ArrayIterator([1, 2, 3, 4, 5, 6]), fn($v, $k) => $v % 2 == 0 && $k > 1); foreach ($it as $k => $v) { echo "$k=$v,"; }`, "3=4,5=6,")
+	testInputOutput(t, `<?php class Odd extends FilterIterator { public function accept(): bool { return $this->current() % 2 == 1; } }
+		foreach (new Odd(new ArrayIterator([1, 2, 3])) as $v) { echo $v; }`, "13")
+
+	// Lazy evaluation of infinite iterators
+	testInputOutput(t,
+		`<?php class Squares implements Iterator {
+			private $i = 0;
+			public function current(): mixed { return $this->i * $this->i; }
+			public function key(): mixed { return $this->i; }
+			public function next(): void { $this->i++; }
+			public function rewind(): void { $this->i = 0; }
+			public function valid(): bool { return true; }
+		}
+		foreach (new LimitIterator(new CallbackFilterIterator(new Squares(), fn($v) => $v % 2 == 1), 1, 2) as $k => $v) { echo "$k=$v,"; }`,
+		"3=9,5=25,",
+	)
+
+	// RecursiveArrayIterator and RecursiveIteratorIterator
+	testInputOutput(t, `<?php $it = new RecursiveIteratorIterator(new RecursiveArrayIterator([1, [2, [3]], 4])); foreach ($it as $k => $v) { echo $it->getDepth(), "$k=$v,"; }`, "00=1,10=2,20=3,02=4,")
+	testInputOutput(t, `<?php $it = new RecursiveIteratorIterator(new RecursiveArrayIterator(["a" => 1, "b" => ["c" => 2]]), RecursiveIteratorIterator::SELF_FIRST); foreach ($it as $k => $v) { echo $k; }`, "abc")
+	testInputOutput(t, `<?php $it = new RecursiveIteratorIterator(new RecursiveArrayIterator(["a" => 1, "b" => ["c" => 2]]), RecursiveIteratorIterator::CHILD_FIRST); foreach ($it as $k => $v) { echo $k; }`, "acb")
+	testInputOutput(t, `<?php $it = new RecursiveIteratorIterator(new RecursiveArrayIterator([1, [2, [3]]])); $it->setMaxDepth(1); echo iterator_count($it), $it->getMaxDepth();`, "31")
+	testInputOutput(t,
+		`<?php class Tree extends RecursiveIteratorIterator { function beginChildren(): void { echo "("; } function endChildren(): void { echo ")"; } }
+		foreach (new Tree(new RecursiveArrayIterator([1, [2, 3], 4])) as $v) { echo $v; }`,
+		"1(23)4",
+	)
+	testForError(t, `<?php new RecursiveIteratorIterator(new ArrayIterator([]));`, phpError.NewError("Uncaught InvalidArgumentException: An instance of RecursiveIterator or IteratorAggregate creating it is required"))
+
+	// WeakReference
+	testInputOutput(t, `<?php $a = new stdClass(); $a->n = 1; $r = WeakReference::create($a); var_dump($r === WeakReference::create($a), $r->get()->n);`, "bool(true)\nint(1)\n")
+	testInputOutput(t, `<?php var_dump(WeakReference::create(new stdClass())->get());`, "NULL\n")
+	testInputOutput(t, `<?php $a = new stdClass(); $r = WeakReference::create($a); unset($a); var_dump($r->get());`, "NULL\n")
+	testForError(t, `<?php new WeakReference();`, phpError.NewError("Uncaught Error: Cannot directly construct WeakReference, use WeakReference::create() instead"))
+
+	// WeakMap
+	testInputOutput(t,
+		`<?php $m = new WeakMap(); $a = new stdClass(); $a->n = 1; $b = new stdClass(); $b->n = 2; $m[$a] = "a"; $m[$b] = "b";
+		echo count($m), isset($m[$a]) ? "y" : "n", $m[$b]; foreach ($m as $k => $v) { echo ",", $k->n, "=", $v; }`,
+		"2yb,1=a,2=b",
+	)
+	testInputOutput(t, `<?php $m = new WeakMap(); $a = new stdClass(); $b = new stdClass(); $m[$a] = 1; $m[$b] = 2; unset($m[$a]); echo count($m); unset($b); echo count($m);`, "10")
+	testForError(t, `<?php $m = new WeakMap(); $m[1] = 2;`, phpError.NewError("Uncaught TypeError: WeakMap key must be an object"))
+
+	// iterator_to_array, iterator_count, iterator_apply
+	testInputOutput(t, `<?php echo serialize(iterator_to_array(new ArrayIterator(["a" => 1, 2]))), serialize(iterator_to_array(new ArrayIterator(["a" => 1, 2]), false));`, `a:2:{s:1:"a";i:1;i:0;i:2;}a:2:{i:0;i:1;i:1;i:2;}`)
+	testInputOutput(t, `<?php echo iterator_count(new ArrayIterator([1, 2, 3])), iterator_count([1, 2]);`, "32")
+	testInputOutput(t, `<?php $it = new ArrayIterator([1, 2, 3]); echo iterator_apply($it, function($it) { echo $it->current(); return $it->current() < 2; }, [$it]);`, "122")
+	testForError(t, `<?php iterator_count(new stdClass());`, phpError.NewError("Uncaught TypeError: iterator_count(): Argument #1 ($iterator) must be of type Traversable|array, stdClass given"))
+
+	// spl_object_id
+	testInputOutput(t, `<?php $a = new stdClass(); $b = new stdClass(); var_dump(spl_object_id($a) === spl_object_id($a), spl_object_id($a) === spl_object_id($b));`, "bool(true)\nbool(false)\n")
+	testInputOutput(t, `<?php $a = new stdClass(); echo spl_object_hash($a);`, "00000000000000010000000000000000")
+
+	// count
+	testForError(t, `<?php count(new stdClass());`, phpError.NewError("Uncaught TypeError: count(): Argument #1 ($value) must be of type Countable|array, stdClass given"))
+
+	dir := t.TempDir()
+	testInputOutput(t, fmt.Sprintf(`<?php mkdir('%s/sub'); file_put_contents('%s/a.txt', "l1\n\nl3\n"); touch('%s/sub/b.php'); symlink('%s/sub', '%s/link');`, dir, dir, dir, dir, dir), "")
+
+	// SplFileInfo
+	testInputOutput(t, fmt.Sprintf(`<?php $i = new SplFileInfo('%s/a.txt'); echo $i->getFilename(), "|", $i->getExtension(), "|", $i->getBasename(".txt"), "|", $i->getSize(), "|", $i->getType(), "|", $i->getPath() === '%s' ? "y" : "n";`, dir, dir),
+		"a.txt|txt|a|7|file|y")
+	testInputOutput(t, `<?php $i = new SplFileInfo("/a/b/"); echo $i->getPathname(), "|", $i->getFilename(), "|", $i->getPath(), "|", $i->getPathInfo()->getPathname();`, "/a/b|b|/a|/a")
+	testInputOutput(t, fmt.Sprintf(`<?php $i = new SplFileInfo('%s/link'); var_dump($i->isLink(), $i->isDir(), $i->getLinkTarget() === '%s/sub', $i->getRealPath() === '%s/sub');`, dir, dir, dir),
+		"bool(true)\nbool(true)\nbool(true)\nbool(true)\n")
+	testForError(t, `<?php $i = new SplFileInfo("/nonexistent"); $i->getSize();`, phpError.NewError("Uncaught RuntimeException: SplFileInfo::getSize(): stat failed for /nonexistent"))
+	testForError(t, `<?php $i = new SplFileInfo("a"); $i->setInfoClass("stdClass");`,
+		phpError.NewError("Uncaught TypeError: SplFileInfo::setInfoClass(): Argument #1 ($class) must be a class name derived from SplFileInfo, stdClass given"))
+
+	// DirectoryIterator
+	testInputOutput(t, fmt.Sprintf(`<?php foreach (new DirectoryIterator('%s') as $k => $f) { echo $k, "=", $f->getFilename(), $f->isDot() ? "(dot)" : "", ","; }`, dir),
+		"0=.(dot),1=..(dot),2=a.txt,3=link,4=sub,")
+	testInputOutput(t, fmt.Sprintf(`<?php $it = new DirectoryIterator('%s/'); $it->seek(2); echo $it->__toString(), $it->key(), $it->getPath() === '%s' ? "y" : "n";`, dir, dir), "a.txt2y")
+	testForError(t, fmt.Sprintf(`<?php $it = new DirectoryIterator('%s'); $it->seek(9);`, dir), phpError.NewError("Uncaught OutOfBoundsException: Seek position 9 is out of range"))
+	testForError(t, `<?php new DirectoryIterator("");`, phpError.NewError("Uncaught ValueError: DirectoryIterator::__construct(): Argument #1 ($directory) cannot be empty"))
+	testForError(t, fmt.Sprintf(`<?php new DirectoryIterator('%s/nope');`, dir),
+		phpError.NewError("Uncaught UnexpectedValueException: DirectoryIterator::__construct(%s/nope): Failed to open directory: No such file or directory", dir))
+
+	// FilesystemIterator
+	testInputOutput(t, fmt.Sprintf(`<?php foreach (new FilesystemIterator('%s') as $k => $f) { echo basename($k), "=", get_class($f), ","; }`, dir),
+		"a.txt=SplFileInfo,link=SplFileInfo,sub=SplFileInfo,")
+	testInputOutput(t, fmt.Sprintf(`<?php $it = new FilesystemIterator('%s', FilesystemIterator::KEY_AS_FILENAME | FilesystemIterator::CURRENT_AS_PATHNAME); foreach ($it as $k => $f) { echo $k, "=", basename($f), ","; } echo $it->getFlags();`, dir),
+		".=.,..=..,a.txt=a.txt,link=link,sub=sub,288")
+	testInputOutput(t, fmt.Sprintf(`<?php $it = new FilesystemIterator('%s', FilesystemIterator::CURRENT_AS_SELF | FilesystemIterator::SKIP_DOTS); foreach ($it as $f) { echo $f === $it ? "y" : "n"; }`, dir), "yyy")
+
+	// RecursiveDirectoryIterator
+	testInputOutput(t, fmt.Sprintf(`<?php $it = new RecursiveIteratorIterator(new RecursiveDirectoryIterator('%s', FilesystemIterator::SKIP_DOTS), RecursiveIteratorIterator::SELF_FIRST); foreach ($it as $f) { echo $it->getSubIterator()->getSubPathname(), ","; }`, dir),
+		"a.txt,link,sub,sub/b.php,")
+	testInputOutput(t, fmt.Sprintf(`<?php $it = new RecursiveIteratorIterator(new RecursiveDirectoryIterator('%s', FilesystemIterator::SKIP_DOTS | FilesystemIterator::FOLLOW_SYMLINKS)); foreach ($it as $f) { echo $it->getSubIterator()->getSubPath(), "/", $f->getFilename(), ","; }`, dir),
+		"/a.txt,link/b.php,sub/b.php,")
+
+	// SplFileObject
+	testInputOutput(t, fmt.Sprintf(`<?php foreach (new SplFileObject('%s/a.txt') as $n => $l) { echo $n, "=", json_encode($l), ","; }`, dir),
+		`0="l1\n",1="\n",2="l3\n",3="",`)
+	testInputOutput(t, fmt.Sprintf(`<?php $f = new SplFileObject('%s/a.txt'); $f->setFlags(SplFileObject::DROP_NEW_LINE | SplFileObject::SKIP_EMPTY | SplFileObject::READ_AHEAD); foreach ($f as $n => $l) { echo $n, "=", $l, ","; }`, dir),
+		"0=l1,1=l3,")
+	testInputOutput(t, fmt.Sprintf(`<?php $f = new SplFileObject('%s/a.txt'); $f->seek(2); echo $f->current(), $f->key(), $f->fgetc(), $f->ftell();`, dir), "l3\n27")
+	testInputOutput(t, fmt.Sprintf(`<?php $f = new SplFileObject('%s/a.txt'); echo $f->fgets(), $f->fgets(), $f->fgets(), $f->key(), $f->eof() ? "y" : "n"; $f->fgets();`, dir), "l1\n\nl3\n3n")
+	testForError(t, fmt.Sprintf(`<?php $f = new SplFileObject('%s/a.txt'); while (true) { $f->fgets(); }`, dir),
+		phpError.NewError("Uncaught RuntimeException: Cannot read from file %s/a.txt", dir))
+	testForError(t, fmt.Sprintf(`<?php new SplFileObject('%s');`, dir), phpError.NewError("Uncaught LogicException: Cannot use SplFileObject with directories"))
+	testForError(t, fmt.Sprintf(`<?php new SplFileObject('%s/nope');`, dir),
+		phpError.NewError("Uncaught RuntimeException: SplFileObject::__construct(%s/nope): Failed to open stream: No such file or directory", dir))
+	testForError(t, fmt.Sprintf(`<?php $f = new SplFileObject('%s/a.txt'); $f->setMaxLineLen(-1);`, dir),
+		phpError.NewError("Uncaught ValueError: SplFileObject::setMaxLineLen(): Argument #1 ($maxLength) must be greater than or equal to 0"))
+
+	// SplTempFileObject
+	testInputOutput(t, `<?php $f = new SplTempFileObject(); $f->fputcsv(["a", "b c", 'd"e']); $f->fwrite("1;2\n"); $f->rewind(); echo $f->fgets(); $f->setCsvControl(";"); var_dump($f->fgetcsv(), $f->getFilename());`,
+		"a,\"b c\",\"d\"\"e\"\narray(2) {\n  [0]=>\n  string(1) \"1\"\n  [1]=>\n  string(1) \"2\"\n}\nstring(10) \"php://temp\"\n")
+	testInputOutput(t, `<?php $f = new SplTempFileObject(-1); $f->fwrite("a,b\n\nc,d\n"); $f->rewind(); $f->setFlags(SplFileObject::READ_CSV | SplFileObject::SKIP_EMPTY | SplFileObject::READ_AHEAD); foreach ($f as $row) { echo implode("|", $row), ","; } echo $f->getFilename();`,
+		"a|b,c|d,php://memory")
+}
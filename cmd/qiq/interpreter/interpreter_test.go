@@ -179,6 +179,75 @@ func TestTryStmt(t *testing.T) {
 	testInputOutput(t, `<?php try { echo "try."; } finally { echo "finally."; }`, "try.finally.")
 
 	testForError(t, `<?php try {} finally { f1(); }`, phpError.NewError("Call to undefined function f1() in %s:1:24", TEST_FILE_NAME))
+
+	// Throw and catch
+	testInputOutput(t, `<?php try { throw new Exception("msg", 42); echo "not reached"; } catch (Exception $e) { echo get_class($e), ":", $e->getMessage(), ":", $e->getCode(), ":", $e->getLine(); }`, "Exception:msg:42:1")
+	testInputOutput(t, `<?php class MyEx extends LogicException {} try { throw new MyEx("a"); } catch (RuntimeException $e) { echo "runtime"; } catch (TypeError | LogicException $e) { echo "logic ", $e->getMessage(); } finally { echo " finally"; }`, "logic a finally")
+	testInputOutput(t, `<?php try { throw new Exception("a"); } catch (Exception) { echo "caught"; }`, "caught")
+	testInputOutput(t, `<?php try { try { throw new Exception("a"); } finally { echo "inner."; } } catch (Exception $e) { echo "outer ", $e->getMessage(); }`, "inner.outer a")
+	testInputOutput(t, `<?php function f() { try { return 1; } finally { echo "finally."; } } echo f();`, "finally.1")
+	testInputOutput(t, `<?php try { throw new Exception("a"); } catch (Exception $e) { try { throw new RuntimeException("b", 0, $e); } catch (Exception $e) { echo $e->getMessage(), $e->getPrevious()->getMessage(); } }`, "ba")
+	testInputOutput(t, `<?php function f() { throw new Exception("a"); } try { var_dump(@f()); } catch (Exception $e) { echo "caught ", $e->getMessage(); }`, "caught a")
+	testInputOutput(t, `<?php function f() { exit("exit"); } @f(); echo "not reached";`, "exit")
+	// Errors of natively implemented code
+	testInputOutput(t, `<?php try { $a = new SplFixedArray(1); $a[2] = 1; } catch (RuntimeException $e) { echo get_class($e), ": ", $e->getMessage(); }`, "RuntimeException: Index invalid or out of range")
+	testInputOutput(t, `<?php try { $f = 1; $f(); } catch (Error $e) { echo $e->getMessage(), ":", $e->getLine(); }`, "Value not callable:1")
+	// Stack traces
+	testInputOutput(t, `<?php
+		class C { function m($a) { return f($a, "a long string argument", [1], null, true); } }
+		function f($a, $b, $c, $d, $e) { throw new Exception("msg"); }
+		try { $c = new C; $c->m(1); } catch (Exception $e) { echo $e->getTraceAsString(), "\n", count($e->getTrace()), " ", $e->getTrace()[1]["class"], $e->getTrace()[1]["type"]; }`,
+		fmt.Sprintf("#0 %[1]s(2): f(1, 'a long string a...', Array, NULL, true)\n#1 %[1]s(4): C->m(1)\n#2 {main}\n2 C->", TEST_FILE_NAME),
+	)
+	testInputOutput(t, `<?php try { throw new Exception("b", 0, new Exception("a")); } catch (Exception $e) { echo $e->__toString(); }`,
+		fmt.Sprintf("Exception: a in %[1]s:1\nStack trace:\n#0 {main}\n\nNext Exception: b in %[1]s:1\nStack trace:\n#0 {main}", TEST_FILE_NAME),
+	)
+
+	testForError(t, `<?php throw new Exception("msg");`, phpError.NewError("Uncaught Exception: msg in %s:1", TEST_FILE_NAME))
+	testForError(t, `<?php try { throw new LogicException("msg"); } catch (RuntimeException $e) {}`, phpError.NewError("Uncaught LogicException: msg in %s:1", TEST_FILE_NAME))
+	testForError(t, `<?php throw 42;`, phpError.NewError("Uncaught Error: Can only throw objects in %s:1:7", TEST_FILE_NAME))
+	testForError(t, `<?php throw new stdClass();`, phpError.NewError("Uncaught Error: Cannot throw objects that do not implement Throwable in %s:1:7", TEST_FILE_NAME))
+
+	// Uncaught Throwables are printed with their stack trace
+	interpreter, _ := NewInterpreter(runtime.NewExecutionContext(), ini.NewDevIni(), &request.Request{}, TEST_FILE_NAME)
+	_, err := interpreter.Process(`<?php function f() { throw new Exception("msg"); } f();`)
+	expected := fmt.Sprintf("Fatal error: Uncaught Exception: msg in %[1]s:1\nStack trace:\n#0 %[1]s(1): f()\n#1 {main}\n  thrown in %[1]s on line 1", TEST_FILE_NAME)
+	if actual := interpreter.ErrorToString(err); actual != expected {
+		t.Errorf("\nExpected: \"%s\",\nGot:      \"%s\"", expected, actual)
+	}
+}
+
+func TestFibers(t *testing.T) {
+	testInputOutput(t,
+		`<?php $fiber = new Fiber(function (string $x) { $y = Fiber::suspend($x . "1"); echo "resumed ", $y, "."; return "done"; });
+		$v = $fiber->start("s"); echo "suspended ", $v, ".";
+		var_dump($fiber->isSuspended()); $fiber->resume("r"); var_dump($fiber->isTerminated()); echo $fiber->getReturn();`,
+		"suspended s1.bool(true)\nresumed r.bool(true)\ndone",
+	)
+	testInputOutput(t, `<?php var_dump(Fiber::getCurrent()); $f = new Fiber(function () { echo get_class(Fiber::getCurrent()); }); $f->start();`, "NULL\nFiber")
+	testInputOutput(t, `<?php $f = new Fiber(fn($a, $b) => $a + $b); var_dump($f->start(1, 2), $f->getReturn());`, "NULL\nint(3)\n")
+	// Nested fibers
+	testInputOutput(t,
+		`<?php $outer = new Fiber(function () { $inner = new Fiber(function () { Fiber::suspend("i"); echo "inner."; });
+		echo $inner->start(), "."; echo Fiber::suspend("o"), "."; $inner->resume(); });
+		echo $outer->start(), "."; $outer->resume("x");`,
+		"i.o.x.inner.",
+	)
+	// Exceptions
+	testInputOutput(t,
+		`<?php $f = new Fiber(function () { try { Fiber::suspend(); } catch (LogicException $e) { echo "fiber caught ", $e->getMessage(), "."; } throw new RuntimeException("out"); });
+		$f->start(); try { $f->throw(new LogicException("in")); } catch (RuntimeException $e) { echo "main caught ", $e->getMessage(); }`,
+		"fiber caught in.main caught out",
+	)
+	testInputOutput(t, `<?php $f = new Fiber(function () { try { Fiber::suspend(); } finally { echo "cleanup"; } }); $f->start(); echo "end.";`, "end.cleanup")
+	// Errors
+	testInputOutput(t, `<?php try { Fiber::suspend(); } catch (FiberError $e) { echo $e->getMessage(); }`, "Cannot suspend outside of fiber")
+	testInputOutput(t, `<?php $f = new Fiber(function () {}); $f->start(); try { $f->start(); } catch (FiberError $e) { echo $e->getMessage(); }`, "Cannot start a fiber that has already been started")
+	testInputOutput(t, `<?php $f = new Fiber(function () {}); try { $f->resume(); } catch (FiberError $e) { echo $e->getMessage(); }`, "Cannot resume a fiber that is not suspended")
+	testInputOutput(t, `<?php $f = new Fiber(function () { Fiber::suspend(); }); try { $f->getReturn(); } catch (FiberError $e) { echo $e->getMessage(), "."; } $f->start(); try { $f->getReturn(); } catch (FiberError $e) { echo $e->getMessage(); }`,
+		"Cannot get fiber return value: The fiber has not been started.Cannot get fiber return value: The fiber has not returned",
+	)
+	testForError(t, `<?php $f = new Fiber(function () { throw new Exception("msg"); }); $f->start();`, phpError.NewError("Uncaught Exception: msg in %s:1", TEST_FILE_NAME))
 }
 
 func TestConstants(t *testing.T) {
@@ -421,6 +490,7 @@ func TestIntrinsic(t *testing.T) {
 	// Eval
 	testInputOutput(t, `<?php var_dump(eval("return 12;"));`, "int(12)\n")
 	testInputOutput(t, `<?php var_dump(eval("12;"));`, "NULL\n")
+	testInputOutput(t, `<?php class C { function __destruct() { echo "destruct"; } } $c = new C; eval("echo 1;"); echo 2;`, "12destruct")
 
 	// Isset
 	testInputOutput(t, `<?php $a = 1; echo isset($a) ? "y" : "n";`, "y")
@@ -753,6 +823,16 @@ func TestOperators(t *testing.T) {
 	testInputOutput(t, `<?php $a = 43.0; echo $a /= 2;`, "21.5")
 	testInputOutput(t, `<?php echo 2.0 ** 4;`, "16")
 	testInputOutput(t, `<?php $a = 2.0; echo $a **= 4;`, "16")
+	// Integer overflow and non-integral results
+	testInputOutput(t, `<?php var_dump(7 / 2);`, "float(3.5)\n")
+	testInputOutput(t, `<?php var_dump(PHP_INT_MAX + 1);`, "float(9223372036854776000)\n")
+	testInputOutput(t, `<?php var_dump(PHP_INT_MIN - 1);`, "float(-9223372036854776000)\n")
+	testInputOutput(t, `<?php var_dump(PHP_INT_MAX * 2);`, "float(18446744073709552000)\n")
+	testInputOutput(t, `<?php var_dump(-PHP_INT_MIN);`, "float(9223372036854776000)\n")
+	testInputOutput(t, `<?php var_dump(PHP_INT_MIN / -1);`, "float(9223372036854776000)\n")
+	testInputOutput(t, `<?php var_dump(2 ** 62);`, "int(4611686018427387904)\n")
+	testInputOutput(t, `<?php var_dump(2 ** 63);`, "float(9223372036854776000)\n")
+	testInputOutput(t, `<?php $a = PHP_INT_MAX; $a++; var_dump($a);`, "float(9223372036854776000)\n")
 	// String
 	testInputOutput(t, `<?php echo "a" . "bc";`, "abc")
 	testInputOutput(t, `<?php $a = "a"; echo $a .= "bc";`, "abc")
@@ -1406,6 +1486,12 @@ func TestUserFunctions(t *testing.T) {
 	testInputOutput(t, `<?php function f($a = 2) { echo $a; } f();`, "2")
 	testInputOutput(t, `<?php function f($a = 2) { echo $a; } f(42);`, "42")
 
+	// Variadic parameter
+	testInputOutput(t, `<?php function f($a, ...$rest) { echo $a, count($rest), implode(",", $rest); } f(1); f(1, 2, 3);`, "10122,3")
+	testInputOutput(t, `<?php class C { function m(...$a) { return implode(",", $a); } } $c = new C; $f = fn(...$a) => count($a); echo $c->m(1, 2), $f(1, 2, 3);`, "1,23")
+	testForError(t, `<?php function f(...$a, $b) {}`, phpError.NewError("Only the last parameter can be variadic in %s:1:25", TEST_FILE_NAME))
+	testForError(t, `<?php function f(...$a = []) {}`, phpError.NewError("Variadic parameter cannot have a default value in %s:1:21", TEST_FILE_NAME))
+
 	// Redeclared function
 	testForError(t, "<?php function f1() {} function F1() {}", phpError.NewError("Cannot redeclare function F1() (previously declared in %s:1:7) in %s:1:24", TEST_FILE_NAME, TEST_FILE_NAME))
 	testForError(t, "<?php function f1() {} function f1() {}", phpError.NewError("Cannot redeclare function f1() (previously declared in %s:1:7) in %s:1:24", TEST_FILE_NAME, TEST_FILE_NAME))
@@ -1415,6 +1501,31 @@ func TestUserFunctions(t *testing.T) {
 	testInputOutput(t, "<?php var_dump(function_exists('intval'));", "bool(true)\n")
 	testInputOutput(t, "<?php var_dump(function_exists('someUndefinedFunc'));", "bool(false)\n")
 	testInputOutput(t, "<?php function myUserFunc() {} var_dump(function_exists('myUserFunc'));", "bool(true)\n")
+
+	// Anonymous functions
+	testInputOutput(t, `<?php $f = function($a, $b = 2) { return $a * $b; }; echo $f(3), $f(3, 3), get_class($f);`, "69Closure")
+	testInputOutput(t, `<?php $x = 1; $f = function() use ($x) { return $x; }; $x = 2; echo $f(), $x;`, "12")
+	testInputOutput(t, `<?php $x = 1; $f = function() use (&$x) { $x++; }; $f(); $f(); echo $x;`, "3")
+	testInputOutput(t, `<?php $f = function(&$a) { $a = 42; }; $b = 1; $f($b); echo $b;`, "42")
+	testInputOutput(t, `<?php $f = function() { return __FUNCTION__; }; echo $f();`, "{closure}")
+	testInputOutput(t, `<?php function apply($f, $v) { return $f($v); } echo apply(function($v) { return $v + 1; }, 1);`, "2")
+	testForError(t, `<?php $f = function($a) {}; $f();`, phpError.NewError(`Uncaught ArgumentCountError: {closure}() expects exactly 1 arguments, 0 given`))
+
+	// Arrow functions
+	testInputOutput(t, `<?php $y = 3; $f = fn($x) => $x * $y; $y = 4; echo $f(2);`, "6")
+	testInputOutput(t, `<?php $f = fn($x) => fn($y) => $x + $y; echo $f(1)(2);`, "3")
+
+	// Callables
+	testInputOutput(t, `<?php class C { function m($a) { return "m$a"; } static function s($a) { return "s$a"; } function __invoke($a) { return "i$a"; } }
+		$c = new C(); $m = [$c, "m"]; $s = ["C", "s"]; $t = "C::s"; echo $m(1), $s(2), $t(3), $c(4);`, "m1s2s3i4")
+	testForError(t, `<?php $f = 42; $f();`, phpError.NewError("Uncaught Error: Value not callable in %s:1:16", TEST_FILE_NAME))
+	testForError(t, `<?php $f = new stdClass(); $f();`, phpError.NewError("Uncaught Error: Object of type stdClass is not callable in %s:1:28", TEST_FILE_NAME))
+
+	// Closure binding
+	testInputOutput(t, `<?php class C { private $v = 5; function get() { return function() { return $this->v; }; } } $c = new C(); $f = $c->get(); echo $f();`, "5")
+	testInputOutput(t, `<?php class C { private $v = 5; } $f = function($m) { return $this->v * $m; };
+		echo Closure::bind($f, new C(), "C")(2), $f->bindTo(new C())(3), $f->call(new C(), 4);`, "101520")
+	testInputOutput(t, `<?php $f = Closure::fromCallable("strtoupper"); echo $f("abc"), $f->__invoke("d");`, "ABCD")
 }
 
 // -------------------------------------- classes and objects -------------------------------------- MARK: classes and objects
@@ -1448,6 +1559,9 @@ func TestClasses(t *testing.T) {
 	testInputOutput(t, `<?php class B { const constant = 41; } class C extends B { const constant = 42; function f() { echo self::constant; } } $c = new C; echo $c->f();`, "42")
 	testInputOutput(t, `<?php class B { const constant = 41; } class C extends B { const constant = 42; function f() { echo parent::constant; } } $c = new C; echo $c->f();`, "41")
 	testInputOutput(t, `<?php class B { const constant = 41; } class C extends B { const constant = 42; function f() { echo PARENT::constant; } } $c = new C; echo $c->f();`, "41")
+	testInputOutput(t, `<?php class B { const constant = 41; } class C extends B { } echo C::constant;`, "41")
+	testInputOutput(t, `<?php interface I { const constant = 42; } class C implements I { } echo I::constant, C::constant;`, "4242")
+	testInputOutput(t, `<?php interface I { const constant = 42; } interface J extends I { } class B implements J { } class C extends B { } echo J::constant, C::constant;`, "4242")
 	testForError(t, `<?php class C { function f() { echo PARENT::constant; } } $c = new C; echo $c->f();`, phpError.NewError(`Cannot use "parent" when current class scope has no parent in %s:1:45`, TEST_FILE_NAME))
 
 	// Member call
@@ -1459,6 +1573,14 @@ func TestClasses(t *testing.T) {
 	testInputOutput(t, `<?php class C { static function f(): void { echo "f()"; } } C::f();`, "f()")
 	testForError(t, `<?php class C { function f(): void { echo "f()"; } } C::f();`, phpError.NewError("Uncaught Error: Non-static method C::f() cannot be called statically in %s:1:57", TEST_FILE_NAME))
 
+	// Late static binding
+	testInputOutput(t,
+		`<?php class A { const N = "A"; static function n() { return static::N; } static function create() { return static::who(); } static function who() { return "A"; }
+            static function viaSelf() { return self::create(); } function m() { return static::who(); } }
+        class B extends A { const N = "B"; static function who() { return "B"; } static function viaParent() { return parent::create(); } }
+        $b = new B; echo A::n(), B::n(), A::create(), B::create(), B::viaSelf(), B::viaParent(), $b->m();`,
+		"ABABBBB")
+
 	// Destructor
 	testInputOutput(t, `<?php class c { function __destruct() { echo __METHOD__; } } $c = new c; echo "Done\n";`, "Done\nc::__destruct")
 	testInputOutput(t, `<?php class c { function __destruct() { echo __METHOD__ . "\n"; } } new c; echo "Done";`, "c::__destruct\nDone")
@@ -1482,6 +1604,38 @@ func TestClasses(t *testing.T) {
 		func1();`,
 		"1\nC::__construct\nC::__destruct\n2\n3\nC::__construct\n4\nC::__destruct\n",
 	)
+	testInputOutput(t, `<?php
+		class C { public $n; function __construct($n) { $this->n = $n; } function __destruct() { echo $this->n . "\n"; } }
+		function f() { $a = new C("a"); $b = new C("b"); echo "f\n"; }
+		f();
+		$x = new C("x"); $y = $x; $x = null; echo "y\n"; $y = null;
+		$arr = [new C("arr0"), new C("arr1")]; unset($arr[0]); echo "unset\n";
+		$p = new C("p"); $p->child = new C("child"); unset($p); echo "end\n";`,
+		"f\na\nb\ny\nx\narr0\nunset\np\nchild\nend\narr1\n",
+	)
+
+	// Garbage collection
+	testInputOutput(t, `<?php
+		class C { public $other; public $n; function __construct($n) { $this->n = $n; } function __destruct() { echo $this->n . "\n"; } }
+		$a = new C("a"); $b = new C("b"); $a->other = $b; $b->other = $a;
+		unset($a, $b); echo "unset\n";
+		var_dump(gc_collect_cycles()); echo "end\n";`,
+		"unset\na\nb\nint(2)\nend\n",
+	)
+	testInputOutput(t, `<?php
+		var_dump(gc_enabled()); gc_disable(); var_dump(gc_enabled()); gc_enable(); var_dump(gc_enabled());
+		var_dump(ini_get("zend.enable_gc"));`,
+		"bool(true)\nbool(false)\nbool(true)\nstring(1) \"1\"\n",
+	)
+	testInputOutput(t, `<?php echo implode(",", array_keys(gc_status()));`,
+		"runs,collected,threshold,roots,running,protected,full,buffer_size,application_time,collector_time,destructor_time,free_time",
+	)
+	testInputOutput(t, `<?php
+		class C { public $n; function __construct($n) { $this->n = $n; } function __destruct() { echo $this->n . "\n"; } }
+		$a = new C("a"); $b = new C("b"); $c = $a;
+		echo "end\n";`,
+		"end\nb\na\n",
+	)
 
 	// Class with namespace
 	testInputOutput(t, `<?php
@@ -1558,6 +1712,57 @@ func TestClasses(t *testing.T) {
 		"A::__construct",
 	)
 
+	// Parent method call bound to the current object
+	testInputOutput(t, `<?php
+		class A { public $p = ""; public function __construct() { $this->p = "A"; } }
+		class B extends A { public function __construct() { parent::__construct(); $this->p .= "B"; } }
+		$b = new B; echo $b->p;`,
+		"AB",
+	)
+
+	// Chained member access
+	testInputOutput(t, `<?php
+		class A { public $b; function __construct() { $this->b = new B(); } function self() { return $this; } }
+		class B { public $c = "c"; static function make() { return new B(); } function f() { return "f"; } }
+		$a = new A; $arr = [$a];
+		echo $a->b->c, $a->self()->b->f(), $arr[0]->b->c, B::make()->f();`,
+		"cfcf",
+	)
+
+	// Class implementing ArrayAccess and Countable
+	testInputOutput(t, `<?php
+		class C implements ArrayAccess, Countable {
+			private $items = [];
+			public function offsetExists(mixed $offset): bool { return isset($this->items[$offset]); }
+			public function offsetGet(mixed $offset): mixed { return $this->items[$offset]; }
+			public function offsetSet(mixed $offset, mixed $value): void { if ($offset === null) { $this->items[] = $value; } else { $this->items[$offset] = $value; } }
+			public function offsetUnset(mixed $offset): void { unset($this->items[$offset]); }
+			public function count(): int { return 42; }
+		}
+		$c = new C; $c[] = "a"; $c["k"] = "b";
+		echo $c[0], $c["k"], isset($c["k"]) ? "y" : "n", isset($c["x"]) ? "y" : "n", count($c);`,
+		"abyn42",
+	)
+	testForError(t, `<?php class C { } $c = new C; $c[0] = 1;`, phpError.NewError("Uncaught Error: Cannot use object of type C as array in %s:1:31", TEST_FILE_NAME))
+
+	// Class implementing Iterator and IteratorAggregate
+	testInputOutput(t, `<?php
+		class It implements Iterator {
+			private $pos = 0;
+			private $items = [];
+			public function __construct(array $items) { $this->items = $items; }
+			public function current(): mixed { return $this->items[$this->pos]; }
+			public function key(): mixed { return $this->pos; }
+			public function next(): void { $this->pos++; }
+			public function rewind(): void { $this->pos = 0; }
+			public function valid(): bool { return $this->pos < count($this->items); }
+		}
+		class Agg implements IteratorAggregate { public function getIterator(): Traversable { return new It(["x", "y"]); } }
+		foreach (new It(["a", "b"]) as $k => $v) { echo "$k=$v,"; }
+		foreach (new Agg as $k => $v) { if ($k == 1) { break; } echo "$k=$v,"; }`,
+		"0=a,1=b,0=x,",
+	)
+
 	// Class redeclaration
 	testForError(t, `<?php class C { } class c { }`,
 		phpError.NewError(`Cannot redeclare class C (previously declared in %s:1:7) in %s:1:19`, TEST_FILE_NAME, TEST_FILE_NAME),
@@ -2,19 +2,16 @@ package interpreter
 
 import (
 	"QIQ/cmd/qiq/common"
-	"QIQ/cmd/qiq/config"
 	"QIQ/cmd/qiq/ini"
 	"QIQ/cmd/qiq/phpError"
-	"QIQ/cmd/qiq/request"
 	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/stdlib/url"
 	"QIQ/cmd/qiq/runtime/values"
 	"fmt"
 	"io"
 	"math/rand"
-	"net/url"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
 	"golang.org/x/text/encoding/japanese"
@@ -55,15 +52,8 @@ func parseCookies(cookies string, interpreter runtime.Interpreter) *values.Array
 		if result.Contains(key) {
 			continue
 		}
-		// Escape plus sign so that it will not be replaced with space
-		value = strings.ReplaceAll(value, "+", "%2b")
-		value, err := url.QueryUnescape(fixPercentEscaping(value))
-		if err != nil {
-			if config.IsDevMode {
-				println("parseCookies: ", err)
-			}
-			continue
-		}
+		// The plus sign is not replaced with a space
+		value = url.Decode(value, true)
 		result.SetElement(key, values.NewStr(value))
 	}
 
@@ -278,104 +268,14 @@ func parseQuery(query string, interpreter runtime.Interpreter) (*values.Array, e
 
 		// Get parameter with key-value-pair
 		key, value, _ := strings.Cut(key, "=")
-
-		key, err := url.QueryUnescape(fixPercentEscaping(key))
-		if err != nil {
-			return result, err
-		}
-
-		value, err = url.QueryUnescape(value)
-		if err != nil {
+		if err := url.AddQueryVariable(result, key, value, interpreter.GetIni().GetInt("max_input_nesting_level")); err != nil {
 			return result, err
 		}
-		if strings.Contains(key, "[") && strings.Contains(key, "]") {
-			result, err = parseQueryKey(key, value, result, interpreter.GetIni())
-			if err != nil {
-				return result, err
-			}
-		} else {
-			key = strings.NewReplacer(
-				" ", "_",
-				"+", "_",
-				"[", "_",
-				".", "_",
-			).Replace(key)
-
-			var keyValue values.RuntimeValue
-			if common.IsIntegerLiteral(key, false) {
-				intValue, _ := common.IntegerLiteralToInt64(key, false)
-				keyValue = values.NewInt(intValue)
-			} else {
-				keyValue = values.NewStr(key)
-			}
-			result.SetElement(keyValue, values.NewStr(value))
-		}
 	}
 
 	return result, nil
 }
 
-func parseQueryKey(key string, value string, result *values.Array, curIni *ini.Ini) (*values.Array, error) {
-	// The parsing of a complex key with arrays is solved by using the interpreter itself:
-	// The key and value is transformed into valid PHP code and executed.
-	// Example:
-	//   Input: 123[][12][de]=abc
-	//   Key:   123[][12][de]
-	//   Value: abc
-	//   PHP:   $array[123][][12]["de"] = "abc";
-
-	firstKey, key, _ := strings.Cut(key, "[")
-	key = "[" + key
-
-	maxDepth := curIni.GetInt("max_input_nesting_level")
-
-	phpArrayKeys := []string{firstKey}
-
-	for key != "" {
-		key = strings.TrimPrefix(key, "[")
-		var nextKey string
-		nextKey, key, _ = strings.Cut(key, "]")
-		phpArrayKeys = append(phpArrayKeys, nextKey)
-		for key != "" && !strings.HasPrefix(key, "[") {
-			key = key[1:]
-		}
-	}
-
-	php := "<?php $array"
-	for depth, phpArrayKey := range phpArrayKeys {
-		if depth+1 >= int(maxDepth) {
-			return result, nil
-		}
-		if phpArrayKey == "" {
-			php += "[]"
-		} else if common.IsIntegerLiteral(phpArrayKey, false) {
-			phpArrayKeyInt, _ := common.IntegerLiteralToInt64(phpArrayKey, false)
-			php += fmt.Sprintf("[%d]", phpArrayKeyInt)
-		} else {
-			php += "['" + phpArrayKey + "']"
-		}
-	}
-	php += " = '" + value + "';"
-
-	interpreter, err := NewInterpreter(runtime.NewExecutionContext(), ini.NewDefaultIni(), &request.Request{}, "")
-	if err != nil {
-		return nil, err
-	}
-	interpreter.env.declareVariable("$array", result)
-	_, err = interpreter.Process(php)
-
-	return interpreter.env.variables["$array"].Value.(*values.Array), err
-}
-
-// This fix is required because "url.QueryUnescape()" cannot handle an unescaped percent
-func fixPercentEscaping(key string) string {
-	re, _ := regexp.Compile("%([^0-9A-Fa-f]|$)")
-	// Replace only the '%' character with '%25' without affecting the following character
-	return re.ReplaceAllStringFunc(key, func(match string) string {
-		return "%25" + match[1:]
-	})
-}
-
 func getMaxFilesize(ini *ini.Ini) int {
 	sizeStr := ini.GetStr("upload_max_filesize")
 	if common.IsDecimalLiteral(sizeStr, false) {
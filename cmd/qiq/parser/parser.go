@@ -1265,10 +1265,14 @@ func (parser *Parser) parseTryStmt() (ast.IStatement, phpError.Error) {
 
 		catchNames := []string{}
 		for {
-			if !common.IsQualifiedName(parser.at().Value) {
-				return ast.NewEmptyStmt(), phpError.NewParseError("Expected qualified name in %s", parser.at().GetPosString())
+			catchName, catchNamePos, err := parser.getClassReference()
+			if err != nil {
+				return ast.NewEmptyStmt(), err
 			}
-			catchNames = append(catchNames, parser.eat().Value)
+			if !common.IsQualifiedName(catchName) {
+				return ast.NewEmptyStmt(), phpError.NewParseError("Expected qualified name in %s", catchNamePos)
+			}
+			catchNames = append(catchNames, catchName)
 
 			if parser.isToken(lexer.OpOrPuncToken, "|", true) {
 				continue
@@ -1455,15 +1459,22 @@ func (parser *Parser) parseFunctionParameters() ([]ast.FunctionParameter, phpErr
 
 			byRef := parser.isToken(lexer.OpOrPuncToken, "&", true)
 
+			// variadic-parameter
+			isVariadic := parser.isToken(lexer.OpOrPuncToken, "...", true)
+
 			if parser.at().TokenType != lexer.VariableNameToken {
 				return parameters, phpError.NewParseError(`Expected variable. Got "%s" (%s) in %s`, parser.at().Value, parser.at().TokenType, parser.at().GetPosString())
 			}
 
+			paramPos := parser.at().GetPosString()
 			paramName := parser.eat().Value
 
 			// TODO parse constant-expression
 			var defaultValue ast.IExpression = nil
 			if parser.isToken(lexer.OpOrPuncToken, "=", true) {
+				if isVariadic {
+					return parameters, phpError.NewError("Variadic parameter cannot have a default value in %s", paramPos)
+				}
 				var err phpError.Error
 				defaultValue, err = parser.parseExpr()
 				if err != nil {
@@ -1471,9 +1482,14 @@ func (parser *Parser) parseFunctionParameters() ([]ast.FunctionParameter, phpErr
 				}
 			}
 
-			parameters = append(parameters, ast.NewFunctionParam(byRef, paramName, paramTypes, defaultValue))
+			param := ast.NewFunctionParam(byRef, paramName, paramTypes, defaultValue)
+			param.IsVariadic = isVariadic
+			parameters = append(parameters, param)
 
 			if parser.isToken(lexer.OpOrPuncToken, ",", true) {
+				if isVariadic && !parser.isToken(lexer.OpOrPuncToken, ")", false) {
+					return parameters, phpError.NewError("Only the last parameter can be variadic in %s", parser.at().GetPosString())
+				}
 				continue
 			}
 			if parser.isToken(lexer.OpOrPuncToken, ")", false) {
@@ -1481,8 +1497,6 @@ func (parser *Parser) parseFunctionParameters() ([]ast.FunctionParameter, phpErr
 			}
 			return parameters, phpError.NewParseError(`Expected "," or ")". Got %s`, parser.at())
 		}
-
-		// TODO function-definition - variadic-parameter
 	}
 
 	return parameters, nil
@@ -1506,12 +1520,18 @@ func (parser *Parser) parseAnonymousFunctionCreationExpression() (ast.IExpressio
 	// Spec: https://phplang.org/spec/10-expressions.html#anonymous-function-creation
 	// This operator returns an object of type Closure, or a derived type thereof, that encapsulates the anonymous function defined within.
 
+	// Spec: https://www.php.net/manual/en/functions.arrow.php
+	// arrow-function-creation-expression:
+	//    fn   &(opt)   (   parameter-declaration-list(opt)   )   return-type(opt)   =>   expression
+
 	// TODO anonymous-function-creation-expression - static
-	// Supported statement: anonymous function creation: `function ($param1) { ... }`
+	// Supported statement: anonymous function creation: `function ($param1) use ($var1) { ... }`
+	// Supported statement: arrow function creation: `fn ($param1) => $param1 * 2`
 	parser.PrintParserCallstack("anonymous-function-creation")
 	defer parser.PopParserCallstack()
 
-	if !parser.isToken(lexer.KeywordToken, "function", false) {
+	isArrowFunction := parser.isToken(lexer.KeywordToken, "fn", false)
+	if !isArrowFunction && !parser.isToken(lexer.KeywordToken, "function", false) {
 		return ast.NewEmptyStmt(), NewExpectedError("function", parser.at())
 	}
 
@@ -1532,7 +1552,25 @@ func (parser *Parser) parseAnonymousFunctionCreationExpression() (ast.IExpressio
 		return ast.NewEmptyStmt(), NewExpectedError(")", parser.at())
 	}
 
-	// TODO anonymous-function-creation-expression - anonymous-function-use-clause(opt)
+	uses := []ast.AnonymousFunctionUse{}
+	if !isArrowFunction && parser.isToken(lexer.KeywordToken, "use", true) {
+		if !parser.isToken(lexer.OpOrPuncToken, "(", true) {
+			return ast.NewEmptyStmt(), NewExpectedError("(", parser.at())
+		}
+		for !parser.isToken(lexer.OpOrPuncToken, ")", false) {
+			byRef := parser.isToken(lexer.OpOrPuncToken, "&", true)
+			if parser.at().TokenType != lexer.VariableNameToken {
+				return ast.NewEmptyStmt(), phpError.NewParseError("Expected variable. Got %s", parser.at())
+			}
+			uses = append(uses, ast.NewAnonymousFunctionUse(byRef, parser.eat().Value))
+			if !parser.isToken(lexer.OpOrPuncToken, ",", true) {
+				break
+			}
+		}
+		if !parser.isToken(lexer.OpOrPuncToken, ")", true) {
+			return ast.NewEmptyStmt(), NewExpectedError(")", parser.at())
+		}
+	}
 
 	returnTypes := []string{}
 	if parser.isToken(lexer.OpOrPuncToken, ":", true) {
@@ -1545,6 +1583,17 @@ func (parser *Parser) parseAnonymousFunctionCreationExpression() (ast.IExpressio
 		}
 	}
 
+	if isArrowFunction {
+		if !parser.isToken(lexer.OpOrPuncToken, "=>", true) {
+			return ast.NewEmptyStmt(), NewExpectedError("=>", parser.at())
+		}
+		expr, err := parser.parseExpr()
+		if err != nil {
+			return ast.NewEmptyStmt(), err
+		}
+		return ast.NewArrowFunctionCreationExpr(parser.nextId(), pos, parameters, expr, returnTypes), nil
+	}
+
 	body, err := parser.parseStmt()
 	if err != nil {
 		return ast.NewEmptyStmt(), err
@@ -1553,7 +1602,7 @@ func (parser *Parser) parseAnonymousFunctionCreationExpression() (ast.IExpressio
 		return ast.NewEmptyStmt(), phpError.NewParseError("Expected compound statement. Got %s", body.GetKind())
 	}
 
-	return ast.NewAnonymousFunctionCreationExpr(parser.nextId(), pos, parameters, body.(*ast.CompoundStatement), returnTypes), nil
+	return ast.NewAnonymousFunctionCreationExpr(parser.nextId(), pos, parameters, uses, body.(*ast.CompoundStatement), returnTypes), nil
 }
 
 func (parser *Parser) parseExpr() (ast.IExpression, phpError.Error) {
@@ -2439,140 +2488,15 @@ func (parser *Parser) parsePrimaryExpr() (ast.IExpression, phpError.Error) {
 		return variable, nil
 	}
 
-	// TODO The following expressions can occur multiple times: $a[0]()["abc"]()()...
-
-	// -------------------------------------- subscript-expression -------------------------------------- MARK: subscript-expression
-
-	// Spec: https://phplang.org/spec/10-expressions.html#grammar-subscript-expression
-
-	// subscript-expression:
-	//    dereferencable-expression   [   expression(opt)   ]
-	//    dereferencable-expression   {   expression   }   <b>[Deprecated form]</b>
-
-	// dereferencable-expression:
-	//    variable
-	//    (   expression   )
-	//    array-creation-expression
-	//    string-literal
-
-	// Supported expression: subscript expression: `$a[1];`
-	if ast.IsVariableExpr(variable) && parser.isToken(lexer.OpOrPuncToken, "[", false) {
-		parser.PrintParserCallstack("subscript-expression")
-		defer parser.PopParserCallstack()
-
-		for ast.IsVariableExpr(variable) && parser.isToken(lexer.OpOrPuncToken, "[", true) {
-			var err phpError.Error
-			var index ast.IExpression
-			if !parser.isToken(lexer.OpOrPuncToken, "]", false) {
-				index, err = parser.parseExpr()
-				if err != nil {
-					return ast.NewEmptyExpr(), err
-				}
-			}
-			if !parser.isToken(lexer.OpOrPuncToken, "]", true) {
-				return ast.NewEmptyExpr(), NewExpectedError("]", parser.at())
-			}
-			variable = ast.NewSubscriptExpr(parser.nextId(), variable, index)
-		}
-		return variable, nil
-	}
-
-	// -------------------------------------- function-call-expression -------------------------------------- MARK: function-call-expression
-
-	// Spec: https://phplang.org/spec/10-expressions.html#grammar-function-call-expression
-
-	// function-call-expression:
-	//    qualified-name   (   argument-expression-list(opt)   )
-	//    qualified-name   (   argument-expression-list   ,   )
-	//    callable-expression   (   argument-expression-list(opt)   )
-	//    callable-expression   (   argument-expression-list   ,   )
-
-	// argument-expression-list:
-	//    argument-expression
-	//    argument-expression-list   ,   argument-expression
-
-	// argument-expression:
-	//    variadic-unpacking
-	//    expression
-
-	// variadic-unpacking:
-	//    ...   expression
-
-	// Supported expression: function call expression: `func(42);`
-	if (parser.isTokenType(lexer.NameToken, false) && parser.next(0).TokenType == lexer.OpOrPuncToken && parser.next(0).Value == "(") ||
-		(ast.IsVariableExpr(variable) && parser.isToken(lexer.OpOrPuncToken, "(", false)) {
-		parser.PrintParserCallstack("function-call-expression")
-		defer parser.PopParserCallstack()
-
-		var pos *position.Position
-		var functionName ast.IExpression
-
-		if parser.isTokenType(lexer.NameToken, false) && parser.next(0).TokenType == lexer.OpOrPuncToken && parser.next(0).Value == "(" {
-			pos = parser.at().Position
-			functionName = ast.NewStringLiteralExpr(parser.nextId(), pos, parser.eat().Value, ast.SingleQuotedString)
-		} else {
-			pos = variable.GetPosition()
-			functionName = variable
-		}
-
-		args := []ast.IExpression{}
-		// Eat opening parentheses
-		parser.eat()
-		for {
-			if parser.isToken(lexer.OpOrPuncToken, ")", true) {
-				break
-			}
-
-			arg, err := parser.parseExpr()
-			if err != nil {
-				return ast.NewEmptyExpr(), err
-			}
-			args = append(args, arg)
-
-			if parser.isToken(lexer.OpOrPuncToken, ",", true) || parser.isToken(lexer.OpOrPuncToken, ")", false) {
-				continue
-			}
-			return ast.NewEmptyExpr(), phpError.NewParseError(`Expected "," or ")". Got: %s`, parser.at())
-		}
-		return ast.NewFunctionCallExpr(parser.nextId(), pos, functionName, args), nil
+	variable, err := parser.parsePostfixChain(variable)
+	if err != nil {
+		return ast.NewEmptyExpr(), err
 	}
 
-	// -------------------------------------- member-access-expression -------------------------------------- MARK: member-access-expression
-
-	// Spec: https://phplang.org/spec/10-expressions.html#member-access-operator
-
-	// member-access-expression:
-	//    dereferencable-expression   ->   member-name
-
-	// member-name:
-	//    name
-	//    simple-variable
-	//    {   expression   }
-
-	// Spec: https://phplang.org/spec/10-expressions.html#member-call-operator
-
-	// member-call-expression:
-	//    dereferencable-expression   ->   member-name   (   argument-expression-list(opt)   )
-	//    dereferencable-expression   ->   member-name   (   argument-expression-list   ,   )
-
-	// TODO member-access-expression - check if it is a "dereferencable-expression"
-
-	// Supported expression: member access expression: `$obj->member`
-	// Supported expression: member call expression: `$obj->func()`
-	if ast.IsVariableExpr(variable) && parser.isToken(lexer.OpOrPuncToken, "->", false) {
-		parser.PrintParserCallstack("member-access-expression")
-		defer parser.PopParserCallstack()
-
-		pos := parser.eat().Position
-
-		member, err := parser.parseExpr()
-		if err != nil {
-			return ast.NewEmptyExpr(), err
-		}
-
-		// TODO member-access-expression - check member name type
-
-		return ast.NewMemberAccessExpr(parser.nextId(), pos, variable, member), nil
+	if variable != nil && variable.GetKind() != ast.SimpleVariableExpr &&
+		!parser.isToken(lexer.OpOrPuncToken, "++", false) && !parser.isToken(lexer.OpOrPuncToken, "--", false) &&
+		!parser.isToken(lexer.OpOrPuncToken, "::", false) {
+		return variable, nil
 	}
 
 	// TODO class-constant-access-expression
@@ -2603,7 +2527,7 @@ func (parser *Parser) parsePrimaryExpr() (ast.IExpression, phpError.Error) {
 	}
 
 	// anonymous-function-creation-expression
-	if parser.isToken(lexer.KeywordToken, "function", false) {
+	if parser.isToken(lexer.KeywordToken, "function", false) || parser.isToken(lexer.KeywordToken, "fn", false) {
 		return parser.parseAnonymousFunctionCreationExpression()
 	}
 
@@ -2742,19 +2666,218 @@ func (parser *Parser) parsePrimaryExpr() (ast.IExpression, phpError.Error) {
 
 		pos := parser.eat().Position
 
-		member, err := parser.parseExpr()
+		member, err := parser.parseMemberName()
 		if err != nil {
 			return ast.NewEmptyExpr(), err
 		}
 
-		// TODO scoped-property-access-expression - check member name type
-
-		return ast.NewScopedPropertyAccessExpr(parser.nextId(), pos, variable, member), nil
+		return parser.parsePostfixChain(ast.NewScopedPropertyAccessExpr(parser.nextId(), pos, variable, member))
 	}
 
 	return ast.NewEmptyExpr(), phpError.NewParseError("Unsupported expression type '%s', value: '%s' in %s", parser.at().TokenType, parser.at().Value, parser.at().GetPosString())
 }
 
+// Parse the postfix operators that can follow a variable or a function name
+func (parser *Parser) parsePostfixChain(variable ast.IExpression) (ast.IExpression, phpError.Error) {
+	// The following expressions can occur multiple times: $a[0]()["abc"]->b->c()...
+	for {
+		// -------------------------------------- subscript-expression -------------------------------------- MARK: subscript-expression
+
+		// Spec: https://phplang.org/spec/10-expressions.html#grammar-subscript-expression
+
+		// subscript-expression:
+		//    dereferencable-expression   [   expression(opt)   ]
+		//    dereferencable-expression   {   expression   }   <b>[Deprecated form]</b>
+
+		// dereferencable-expression:
+		//    variable
+		//    (   expression   )
+		//    array-creation-expression
+		//    string-literal
+
+		// Supported expression: subscript expression: `$a[1];`
+		if ast.IsVariableExpr(variable) && parser.isToken(lexer.OpOrPuncToken, "[", false) {
+			parser.PrintParserCallstack("subscript-expression")
+
+			for ast.IsVariableExpr(variable) && parser.isToken(lexer.OpOrPuncToken, "[", true) {
+				var err phpError.Error
+				var index ast.IExpression
+				if !parser.isToken(lexer.OpOrPuncToken, "]", false) {
+					index, err = parser.parseExpr()
+					if err != nil {
+						parser.PopParserCallstack()
+						return ast.NewEmptyExpr(), err
+					}
+				}
+				if !parser.isToken(lexer.OpOrPuncToken, "]", true) {
+					parser.PopParserCallstack()
+					return ast.NewEmptyExpr(), NewExpectedError("]", parser.at())
+				}
+				variable = ast.NewSubscriptExpr(parser.nextId(), variable, index)
+			}
+			parser.PopParserCallstack()
+			continue
+		}
+
+		// -------------------------------------- function-call-expression -------------------------------------- MARK: function-call-expression
+
+		// Spec: https://phplang.org/spec/10-expressions.html#grammar-function-call-expression
+
+		// function-call-expression:
+		//    qualified-name   (   argument-expression-list(opt)   )
+		//    qualified-name   (   argument-expression-list   ,   )
+		//    callable-expression   (   argument-expression-list(opt)   )
+		//    callable-expression   (   argument-expression-list   ,   )
+
+		// Supported expression: function call expression: `func(42);`
+		if (variable == nil && parser.isTokenType(lexer.NameToken, false) && parser.next(0).TokenType == lexer.OpOrPuncToken && parser.next(0).Value == "(") ||
+			(ast.IsVariableExpr(variable) && parser.isToken(lexer.OpOrPuncToken, "(", false)) {
+			parser.PrintParserCallstack("function-call-expression")
+
+			var pos *position.Position
+			var functionName ast.IExpression
+
+			if variable == nil {
+				pos = parser.at().Position
+				functionName = ast.NewStringLiteralExpr(parser.nextId(), pos, parser.eat().Value, ast.SingleQuotedString)
+			} else {
+				pos = variable.GetPosition()
+				functionName = variable
+			}
+
+			args, err := parser.parseArgumentExpressionList()
+			parser.PopParserCallstack()
+			if err != nil {
+				return ast.NewEmptyExpr(), err
+			}
+			variable = ast.NewFunctionCallExpr(parser.nextId(), pos, functionName, args)
+			continue
+		}
+
+		// -------------------------------------- member-access-expression -------------------------------------- MARK: member-access-expression
+
+		// Spec: https://phplang.org/spec/10-expressions.html#member-access-operator
+
+		// member-access-expression:
+		//    dereferencable-expression   ->   member-name
+
+		// member-name:
+		//    name
+		//    simple-variable
+		//    {   expression   }
+
+		// Spec: https://phplang.org/spec/10-expressions.html#member-call-operator
+
+		// member-call-expression:
+		//    dereferencable-expression   ->   member-name   (   argument-expression-list(opt)   )
+		//    dereferencable-expression   ->   member-name   (   argument-expression-list   ,   )
+
+		// Supported expression: member access expression: `$obj->member`
+		// Supported expression: member call expression: `$obj->func()`
+		if ast.IsVariableExpr(variable) && parser.isToken(lexer.OpOrPuncToken, "->", false) {
+			parser.PrintParserCallstack("member-access-expression")
+
+			pos := parser.eat().Position
+
+			member, err := parser.parseMemberName()
+			parser.PopParserCallstack()
+			if err != nil {
+				return ast.NewEmptyExpr(), err
+			}
+
+			variable = ast.NewMemberAccessExpr(parser.nextId(), pos, variable, member)
+			continue
+		}
+
+		break
+	}
+
+	return variable, nil
+}
+
+func (parser *Parser) parseArgumentExpressionList() ([]ast.IExpression, phpError.Error) {
+	// Spec: https://phplang.org/spec/10-expressions.html#grammar-function-call-expression
+
+	// argument-expression-list:
+	//    argument-expression
+	//    argument-expression-list   ,   argument-expression
+
+	// argument-expression:
+	//    variadic-unpacking
+	//    expression
+
+	// variadic-unpacking:
+	//    ...   expression
+
+	args := []ast.IExpression{}
+	// Eat opening parentheses
+	parser.eat()
+	for {
+		if parser.isToken(lexer.OpOrPuncToken, ")", true) {
+			break
+		}
+
+		arg, err := parser.parseExpr()
+		if err != nil {
+			return args, err
+		}
+		args = append(args, arg)
+
+		if parser.isToken(lexer.OpOrPuncToken, ",", true) || parser.isToken(lexer.OpOrPuncToken, ")", false) {
+			continue
+		}
+		return args, phpError.NewParseError(`Expected "," or ")". Got: %s`, parser.at())
+	}
+	return args, nil
+}
+
+func (parser *Parser) parseMemberName() (ast.IExpression, phpError.Error) {
+	// Spec: https://phplang.org/spec/10-expressions.html#member-access-operator
+
+	// member-name:
+	//    name
+	//    simple-variable
+	//    {   expression   }
+
+	var member ast.IExpression
+	var pos *position.Position
+	isName := false
+	if parser.isTokenType(lexer.NameToken, false) || parser.isTokenType(lexer.KeywordToken, false) {
+		pos = parser.at().Position
+		member = ast.NewConstantAccessExpr(parser.nextId(), pos, parser.eat().Value)
+		isName = true
+	} else if parser.isTokenType(lexer.VariableNameToken, false) {
+		pos = parser.at().Position
+		member = ast.NewSimpleVariableExpr(parser.nextId(), ast.NewVariableNameExpr(parser.nextId(), pos, parser.eat().Value))
+	} else if parser.isToken(lexer.OpOrPuncToken, "{", false) {
+		pos = parser.eat().Position
+		expr, err := parser.parseExpr()
+		if err != nil {
+			return ast.NewEmptyExpr(), err
+		}
+		if !parser.isToken(lexer.OpOrPuncToken, "}", true) {
+			return ast.NewEmptyExpr(), NewExpectedError("}", parser.at())
+		}
+		member = expr
+	} else {
+		return ast.NewEmptyExpr(), phpError.NewParseError("Unsupported member name '%s' in %s", parser.at().Value, parser.at().GetPosString())
+	}
+
+	// Member call
+	if parser.isToken(lexer.OpOrPuncToken, "(", false) {
+		args, err := parser.parseArgumentExpressionList()
+		if err != nil {
+			return ast.NewEmptyExpr(), err
+		}
+		if isName {
+			member = ast.NewStringLiteralExpr(parser.nextId(), pos, member.(*ast.ConstantAccessExpression).ConstantName, ast.SingleQuotedString)
+		}
+		return ast.NewFunctionCallExpr(parser.nextId(), pos, member, args), nil
+	}
+
+	return member, nil
+}
+
 func (parser *Parser) parseLiteral() (ast.IExpression, phpError.Error) {
 	// -------------------------------------- literal -------------------------------------- MARK: literal
 
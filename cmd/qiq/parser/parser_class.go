@@ -124,9 +124,14 @@ func (parser *Parser) parseClassDeclaration() (ast.IStatement, phpError.Error) {
 
 	// class-base-clause
 	if parser.isToken(lexer.KeywordToken, "extends", true) {
-		namespace := class.GetPosition().File.GetNamespaceStr()
-		class.BaseClass = namespace + parser.at().Value
-		baseClassPos := parser.eat().GetPosString()
+		baseClass, baseClassPos, err := parser.getClassReference()
+		if err != nil {
+			return ast.NewEmptyStmt(), err
+		}
+		class.BaseClass = baseClass
+		if !strings.HasPrefix(baseClass, `\`) {
+			class.BaseClass = class.GetPosition().File.GetNamespaceStr() + baseClass
+		}
 		if !common.IsQualifiedName(class.BaseClass) {
 			return ast.NewEmptyStmt(), phpError.NewParseError(`"%s" is not a valid class name in %s`, class.BaseClass, baseClassPos)
 		}
@@ -135,8 +140,10 @@ func (parser *Parser) parseClassDeclaration() (ast.IStatement, phpError.Error) {
 	// class-interface-clause
 	if parser.isToken(lexer.KeywordToken, "implements", true) {
 		for {
-			interfaceName := parser.at().Value
-			interfaceNamePos := parser.eat().GetPosString()
+			interfaceName, interfaceNamePos, err := parser.getClassReference()
+			if err != nil {
+				return ast.NewEmptyStmt(), err
+			}
 			if !common.IsQualifiedName(interfaceName) {
 				return ast.NewEmptyStmt(), phpError.NewParseError(`"%s" is not a valid interface name in %s`, interfaceName, interfaceNamePos)
 			}
@@ -834,8 +841,10 @@ func (parser *Parser) parseInterfaceDeclaration() (ast.IStatement, phpError.Erro
 	// interface-base-clause
 	if parser.isToken(lexer.KeywordToken, "extends", true) {
 		for {
-			interfaceName := parser.at().Value
-			interfaceNamePos := parser.eat().GetPosString()
+			interfaceName, interfaceNamePos, err := parser.getClassReference()
+			if err != nil {
+				return ast.NewEmptyStmt(), err
+			}
 			if !common.IsQualifiedName(interfaceName) {
 				return ast.NewEmptyStmt(), phpError.NewParseError(`"%s" is not a valid interface name in %s`, interfaceName, interfaceNamePos)
 			}
@@ -908,3 +917,17 @@ func (parser *Parser) parseInterfaceMemberDeclaration(interfaceDecl *ast.Interfa
 		return phpError.NewParseError("parseInterfaceMemberDeclaration: Unexpected token: %s", parser.at())
 	}
 }
+
+// Get the (qualified) name of a referenced class or interface (e.g. in extends or implements) and its position.
+// If the current token does not start a name, the token itself is returned so that it can be reported as invalid name.
+func (parser *Parser) getClassReference() (string, string, phpError.Error) {
+	pos := parser.at().GetPosString()
+	name, err := parser.getQualifiedName(true)
+	if err != nil {
+		return "", pos, err
+	}
+	if name == "" {
+		name = parser.eat().Value
+	}
+	return name, pos, nil
+}
@@ -369,6 +369,10 @@ func TestTryStmt(t *testing.T) {
 	tryStmt = ast.NewTryStmt(0, nil, ast.NewCompoundStmt(0, []ast.IStatement{}))
 	tryStmt.Catches = append(tryStmt.Catches, ast.CatchStatement{ErrorType: []string{"Throwable", "Exception"}, VariableName: "$th", Body: ast.NewCompoundStmt(0, []ast.IStatement{})})
 	testStmt(t, `<?php try {} catch (Throwable|Exception $th) {}`, tryStmt)
+
+	tryStmt = ast.NewTryStmt(0, nil, ast.NewCompoundStmt(0, []ast.IStatement{}))
+	tryStmt.Catches = append(tryStmt.Catches, ast.CatchStatement{ErrorType: []string{`Random\RandomError`, `\Exception`}, VariableName: "$e", Body: ast.NewCompoundStmt(0, []ast.IStatement{})})
+	testStmt(t, `<?php try {} catch (Random\RandomError|\Exception $e) {}`, tryStmt)
 }
 
 // -------------------------------------- Loops -------------------------------------- MARK: Loops
@@ -533,6 +537,12 @@ func TestClassDeclaration(t *testing.T) {
 	class.Interfaces = append(class.Interfaces, "j")
 	testStmt(t, `<?php final class c implements i, j { }`, class)
 
+	// Class with qualified names
+	class = ast.NewClassDeclarationStmt(0, nil, "c", false, false)
+	class.BaseClass = `\a\b`
+	class.Interfaces = append(class.Interfaces, `Random\Engine`)
+	testStmt(t, `<?php class c extends \a\b implements Random\Engine { }`, class)
+
 	// Class with constants
 	class = ast.NewClassDeclarationStmt(0, nil, "c", false, true)
 	class.AddConst(ast.NewClassConstDeclarationStmt(0, nil, "a", ast.NewStringLiteralExpr(0, nil, "a", ast.DoubleQuotedString), "public"))
@@ -738,14 +748,14 @@ func TestAnonymousFunctions(t *testing.T) {
 	// Empty anonymous function
 	stmt := ast.NewExpressionStmt(0, ast.NewSimpleAssignmentExpr(0,
 		ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$f")),
-		ast.NewAnonymousFunctionCreationExpr(0, nil, []ast.FunctionParameter{}, ast.NewCompoundStmt(0, []ast.IStatement{}), []string{}),
+		ast.NewAnonymousFunctionCreationExpr(0, nil, []ast.FunctionParameter{}, []ast.AnonymousFunctionUse{}, ast.NewCompoundStmt(0, []ast.IStatement{}), []string{}),
 	))
 	testStmt(t, `<?php $f = function() {};`, stmt)
 
 	// Anonymous function
 	stmt = ast.NewExpressionStmt(0, ast.NewSimpleAssignmentExpr(0,
 		ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$f")),
-		ast.NewAnonymousFunctionCreationExpr(0, nil, []ast.FunctionParameter{}, ast.NewCompoundStmt(0, []ast.IStatement{
+		ast.NewAnonymousFunctionCreationExpr(0, nil, []ast.FunctionParameter{}, []ast.AnonymousFunctionUse{}, ast.NewCompoundStmt(0, []ast.IStatement{
 			ast.NewExpressionStmt(0, ast.NewFunctionCallExpr(0, nil, ast.NewStringLiteralExpr(0, nil, "do_smth", ast.SingleQuotedString), []ast.IExpression{})),
 		}), []string{}),
 	))
@@ -754,9 +764,29 @@ func TestAnonymousFunctions(t *testing.T) {
 	// Anonymous function with byRef param
 	stmt = ast.NewExpressionStmt(0, ast.NewSimpleAssignmentExpr(0,
 		ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$f")),
-		ast.NewAnonymousFunctionCreationExpr(0, nil, []ast.FunctionParameter{ast.NewFunctionParam(true, "$a", []string{}, nil)}, ast.NewCompoundStmt(0, []ast.IStatement{}), []string{}),
+		ast.NewAnonymousFunctionCreationExpr(0, nil, []ast.FunctionParameter{ast.NewFunctionParam(true, "$a", []string{}, nil)}, []ast.AnonymousFunctionUse{}, ast.NewCompoundStmt(0, []ast.IStatement{}), []string{}),
 	))
 	testStmt(t, `<?php $f = function(&$a) {};`, stmt)
+
+	// Anonymous function with use clause
+	stmt = ast.NewExpressionStmt(0, ast.NewSimpleAssignmentExpr(0,
+		ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$f")),
+		ast.NewAnonymousFunctionCreationExpr(0, nil, []ast.FunctionParameter{},
+			[]ast.AnonymousFunctionUse{ast.NewAnonymousFunctionUse(false, "$a"), ast.NewAnonymousFunctionUse(true, "$b")},
+			ast.NewCompoundStmt(0, []ast.IStatement{}), []string{"int"},
+		),
+	))
+	testStmt(t, `<?php $f = function() use ($a, &$b): int {};`, stmt)
+
+	// Arrow function
+	stmt = ast.NewExpressionStmt(0, ast.NewSimpleAssignmentExpr(0,
+		ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$f")),
+		ast.NewArrowFunctionCreationExpr(0, nil, []ast.FunctionParameter{ast.NewFunctionParam(false, "$a", []string{}, nil)},
+			ast.NewBinaryOpExpr(0, ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$a")), "*", ast.NewIntegerLiteralExpr(0, nil, 2)),
+			[]string{},
+		),
+	))
+	testStmt(t, `<?php $f = fn($a) => $a * 2;`, stmt)
 }
 
 func TestFunctions(t *testing.T) {
@@ -74,15 +74,15 @@ func (err *PhpError) Error() string { return err.String() }
 
 func (err *PhpError) String() string {
 	switch err.errorType {
-	case NoticePhpError:
+	case NoticePhpError, UserNoticePhpError:
 		return "Notice: " + err.message
-	case WarningPhpError:
+	case WarningPhpError, UserWarningPhpError:
 		return "Warning: " + err.message
-	case ErrorPhpError:
+	case ErrorPhpError, UserPhpError:
 		return "Fatal error: " + err.message
 	case ParsePhpError:
 		return "Parse error: " + err.message
-	case DeprecatedPhpError:
+	case DeprecatedPhpError, UserDeprecatedPhpError:
 		return "Deprecated: " + err.message
 	default:
 		return err.message
@@ -113,6 +113,45 @@ func NewDeprecatedError(format string, a ...any) Error {
 	return &PhpError{errorType: DeprecatedPhpError, message: fmt.Sprintf(format, a...)}
 }
 
+func NewUserError(errorType ErrorType, format string, a ...any) Error {
+	return &PhpError{errorType: errorType, message: fmt.Sprintf(format, a...)}
+}
+
+// MARK: Error levels
+
+var errorLevels = map[ErrorType]int64{
+	ErrorPhpError:          E_ERROR,
+	WarningPhpError:        E_WARNING,
+	ParsePhpError:          E_PARSE,
+	NoticePhpError:         E_NOTICE,
+	CorePhpError:           E_CORE_ERROR,
+	CoreWarningPhpError:    E_CORE_WARNING,
+	CompilePhpError:        E_COMPILE_ERROR,
+	CompileWarningPhpError: E_COMPILE_WARNING,
+	UserPhpError:           E_USER_ERROR,
+	UserWarningPhpError:    E_USER_WARNING,
+	UserNoticePhpError:     E_USER_NOTICE,
+	StrictPhpError:         E_STRICT,
+	RecoverablePhpError:    E_RECOVERABLE_ERROR,
+	DeprecatedPhpError:     E_DEPRECATED,
+	UserDeprecatedPhpError: E_USER_DEPRECATED,
+}
+
+// Get the E_* level of the given error type. Returns 0 for non-PHP error types.
+func GetErrorLevel(errorType ErrorType) int64 {
+	return errorLevels[errorType]
+}
+
+// Get the error type of the given E_* level
+func GetErrorType(level int64) (ErrorType, bool) {
+	for errorType, errorLevel := range errorLevels {
+		if errorLevel == level {
+			return errorType, true
+		}
+	}
+	return "", false
+}
+
 // MARK: ContinueEventError
 
 type ContinueEventError struct {
@@ -129,3 +168,32 @@ func NewBreakEvent(breakoutLevel int64) Error {
 func NewContinueEvent(breakoutLevel int64) Error {
 	return &ContinueEventError{PhpError: &PhpError{errorType: EventError, message: ContinueEvent}, breakoutLevel: breakoutLevel}
 }
+
+// MARK: ThrowError
+
+type ThrowError struct {
+	*PhpError
+	object any
+}
+
+// Get the thrown Throwable object
+func (err *ThrowError) GetObject() any { return err.object }
+
+func NewThrowError(object any, format string, a ...any) Error {
+	return &ThrowError{PhpError: &PhpError{errorType: ErrorPhpError, message: fmt.Sprintf(format, a...)}, object: object}
+}
+
+// MARK: CodedError
+
+type CodedError struct {
+	*PhpError
+	code int64
+}
+
+// Get the code of the Throwable that is created for the error
+func (err *CodedError) GetCode() int64 { return err.code }
+
+// Create an error of natively implemented code (e.g. "Uncaught JsonException: Syntax error") whose Throwable gets the given code
+func NewCodedError(code int64, format string, a ...any) Error {
+	return &CodedError{PhpError: &PhpError{errorType: ErrorPhpError, message: fmt.Sprintf(format, a...)}, code: code}
+}
@@ -0,0 +1,18 @@
+package runtime
+
+import (
+	"QIQ/cmd/qiq/position"
+	"QIQ/cmd/qiq/runtime/values"
+)
+
+// Frame of the call stack as used by debug_backtrace and the traces of Throwables
+type CallFrame struct {
+	Function string
+	Class    string
+	Object   *values.Object
+	// "->" for method calls and "::" for static method calls
+	Type string
+	Args []values.RuntimeValue
+	// Position of the call. Calls from natively implemented code have no position.
+	Position *position.Position
+}
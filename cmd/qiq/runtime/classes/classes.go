@@ -37,7 +37,7 @@ func RegisterDefaultClasses(interpreter runtime.Interpreter) {
 	ErrorException := ast.NewClassDeclarationStmt(0, nil, "ErrorException", false, false)
 	ErrorException.BaseClass = "Exception"
 	ErrorException.AddProperty(ast.NewPropertyDeclarationStmt(0, nil, "$severity", "protected", false, []string{"int"}, ast.NewConstantAccessExpr(0, nil, "E_ERROR")))
-	ErrorException.AddMethod(ast.NewMethodDefinitionStmt(0, nil, "__construct", []string{"public"}, []ast.FunctionParameter{{Name: "$message", Type: []string{"string"}, DefaultValue: ast.NewStringLiteralExpr(0, nil, "", ast.DoubleQuotedString)}, {Name: "$code", Type: []string{"int"}, DefaultValue: ast.NewIntegerLiteralExpr(0, nil, 0)}, {Name: "$severity", Type: []string{"int"}, DefaultValue: ast.NewConstantAccessExpr(0, nil, "E_ERROR")}, {Name: "$filename", Type: []string{"null", "string"}, DefaultValue: ast.NewConstantAccessExpr(0, nil, "NULL")}, {Name: "$line", Type: []string{"null", "int"}, DefaultValue: ast.NewConstantAccessExpr(0, nil, "NULL")}, {Name: "$previous", Type: []string{"null", "Throwable"}, DefaultValue: ast.NewConstantAccessExpr(0, nil, "NULL")}}, ast.NewCompoundStmt(0, []ast.IStatement{ast.NewExpressionStmt(0, ast.NewMemberAccessExpr(0, nil, ast.NewConstantAccessExpr(0, nil, "parent"), ast.NewFunctionCallExpr(0, nil, ast.NewStringLiteralExpr(0, nil, "__construct", ast.DoubleQuotedString), []ast.IExpression{ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$message")), ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$code")), ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$previous"))}))), ast.NewExpressionStmt(0, ast.NewSimpleAssignmentExpr(0, ast.NewMemberAccessExpr(0, nil, ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$this")), ast.NewConstantAccessExpr(0, nil, "severity")), ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$severity")))), ast.NewExpressionStmt(0, ast.NewSimpleAssignmentExpr(0, ast.NewMemberAccessExpr(0, nil, ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$this")), ast.NewConstantAccessExpr(0, nil, "file")), ast.NewCoalesceExpr(0, ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$filename")), ast.NewStringLiteralExpr(0, nil, "", ast.DoubleQuotedString)))), ast.NewExpressionStmt(0, ast.NewSimpleAssignmentExpr(0, ast.NewMemberAccessExpr(0, nil, ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$this")), ast.NewConstantAccessExpr(0, nil, "line")), ast.NewCoalesceExpr(0, ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$line")), ast.NewIntegerLiteralExpr(0, nil, 0))))}), []string{}))
+	ErrorException.AddMethod(ast.NewMethodDefinitionStmt(0, nil, "__construct", []string{"public"}, []ast.FunctionParameter{{Name: "$message", Type: []string{"string"}, DefaultValue: ast.NewStringLiteralExpr(0, nil, "", ast.DoubleQuotedString)}, {Name: "$code", Type: []string{"int"}, DefaultValue: ast.NewIntegerLiteralExpr(0, nil, 0)}, {Name: "$severity", Type: []string{"int"}, DefaultValue: ast.NewConstantAccessExpr(0, nil, "E_ERROR")}, {Name: "$filename", Type: []string{"null", "string"}, DefaultValue: ast.NewConstantAccessExpr(0, nil, "NULL")}, {Name: "$line", Type: []string{"null", "int"}, DefaultValue: ast.NewConstantAccessExpr(0, nil, "NULL")}, {Name: "$previous", Type: []string{"null", "Throwable"}, DefaultValue: ast.NewConstantAccessExpr(0, nil, "NULL")}}, ast.NewCompoundStmt(0, []ast.IStatement{ast.NewExpressionStmt(0, ast.NewScopedPropertyAccessExpr(0, nil, ast.NewConstantAccessExpr(0, nil, "parent"), ast.NewFunctionCallExpr(0, nil, ast.NewStringLiteralExpr(0, nil, "__construct", ast.DoubleQuotedString), []ast.IExpression{ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$message")), ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$code")), ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$previous"))}))), ast.NewExpressionStmt(0, ast.NewSimpleAssignmentExpr(0, ast.NewMemberAccessExpr(0, nil, ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$this")), ast.NewConstantAccessExpr(0, nil, "severity")), ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$severity")))), ast.NewExpressionStmt(0, ast.NewSimpleAssignmentExpr(0, ast.NewMemberAccessExpr(0, nil, ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$this")), ast.NewConstantAccessExpr(0, nil, "file")), ast.NewCoalesceExpr(0, ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$filename")), ast.NewStringLiteralExpr(0, nil, "", ast.DoubleQuotedString)))), ast.NewExpressionStmt(0, ast.NewSimpleAssignmentExpr(0, ast.NewMemberAccessExpr(0, nil, ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$this")), ast.NewConstantAccessExpr(0, nil, "line")), ast.NewCoalesceExpr(0, ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$line")), ast.NewIntegerLiteralExpr(0, nil, 0))))}), []string{}))
 	ErrorException.AddMethod(ast.NewMethodDefinitionStmt(0, nil, "getSeverity", []string{"public", "final"}, []ast.FunctionParameter{}, ast.NewCompoundStmt(0, []ast.IStatement{ast.NewReturnStmt(0, nil, ast.NewMemberAccessExpr(0, nil, ast.NewSimpleVariableExpr(0, ast.NewVariableNameExpr(0, nil, "$this")), ast.NewConstantAccessExpr(0, nil, "severity")))}), []string{"int"}))
 
 	interpreter.AddClass(ErrorException.Name, ErrorException)
@@ -163,6 +163,78 @@ func RegisterDefaultClasses(interpreter runtime.Interpreter) {
 
 	interpreter.AddClass(stdClass.Name, stdClass)
 
+	// -------------------------------------- DateError -------------------------------------- MARK: DateError
+
+	// Spec: https://www.php.net/manual/en/class.dateerror.php
+	DateError := ast.NewClassDeclarationStmt(0, nil, "DateError", false, false)
+	DateError.BaseClass = "Error"
+
+	interpreter.AddClass(DateError.Name, DateError)
+
+	// -------------------------------------- DateObjectError -------------------------------------- MARK: DateObjectError
+
+	// Spec: https://www.php.net/manual/en/class.dateobjecterror.php
+	DateObjectError := ast.NewClassDeclarationStmt(0, nil, "DateObjectError", false, false)
+	DateObjectError.BaseClass = "DateError"
+
+	interpreter.AddClass(DateObjectError.Name, DateObjectError)
+
+	// -------------------------------------- DateRangeError -------------------------------------- MARK: DateRangeError
+
+	// Spec: https://www.php.net/manual/en/class.daterangeerror.php
+	DateRangeError := ast.NewClassDeclarationStmt(0, nil, "DateRangeError", false, false)
+	DateRangeError.BaseClass = "DateError"
+
+	interpreter.AddClass(DateRangeError.Name, DateRangeError)
+
+	// -------------------------------------- DateException -------------------------------------- MARK: DateException
+
+	// Spec: https://www.php.net/manual/en/class.dateexception.php
+	DateException := ast.NewClassDeclarationStmt(0, nil, "DateException", false, false)
+	DateException.BaseClass = "Exception"
+
+	interpreter.AddClass(DateException.Name, DateException)
+
+	// -------------------------------------- DateInvalidTimeZoneException -------------------------------------- MARK: DateInvalidTimeZoneException
+
+	// Spec: https://www.php.net/manual/en/class.dateinvalidtimezoneexception.php
+	DateInvalidTimeZoneException := ast.NewClassDeclarationStmt(0, nil, "DateInvalidTimeZoneException", false, false)
+	DateInvalidTimeZoneException.BaseClass = "DateException"
+
+	interpreter.AddClass(DateInvalidTimeZoneException.Name, DateInvalidTimeZoneException)
+
+	// -------------------------------------- DateInvalidOperationException -------------------------------------- MARK: DateInvalidOperationException
+
+	// Spec: https://www.php.net/manual/en/class.dateinvalidoperationexception.php
+	DateInvalidOperationException := ast.NewClassDeclarationStmt(0, nil, "DateInvalidOperationException", false, false)
+	DateInvalidOperationException.BaseClass = "DateException"
+
+	interpreter.AddClass(DateInvalidOperationException.Name, DateInvalidOperationException)
+
+	// -------------------------------------- DateMalformedStringException -------------------------------------- MARK: DateMalformedStringException
+
+	// Spec: https://www.php.net/manual/en/class.datemalformedstringexception.php
+	DateMalformedStringException := ast.NewClassDeclarationStmt(0, nil, "DateMalformedStringException", false, false)
+	DateMalformedStringException.BaseClass = "DateException"
+
+	interpreter.AddClass(DateMalformedStringException.Name, DateMalformedStringException)
+
+	// -------------------------------------- DateMalformedIntervalStringException -------------------------------------- MARK: DateMalformedIntervalStringException
+
+	// Spec: https://www.php.net/manual/en/class.datemalformedintervalstringexception.php
+	DateMalformedIntervalStringException := ast.NewClassDeclarationStmt(0, nil, "DateMalformedIntervalStringException", false, false)
+	DateMalformedIntervalStringException.BaseClass = "DateException"
+
+	interpreter.AddClass(DateMalformedIntervalStringException.Name, DateMalformedIntervalStringException)
+
+	// -------------------------------------- DateMalformedPeriodStringException -------------------------------------- MARK: DateMalformedPeriodStringException
+
+	// Spec: https://www.php.net/manual/en/class.datemalformedperiodstringexception.php
+	DateMalformedPeriodStringException := ast.NewClassDeclarationStmt(0, nil, "DateMalformedPeriodStringException", false, false)
+	DateMalformedPeriodStringException.BaseClass = "DateException"
+
+	interpreter.AddClass(DateMalformedPeriodStringException.Name, DateMalformedPeriodStringException)
+
 	// -------------------------------------- JsonException -------------------------------------- MARK: JsonException
 
 	// Spec: https://www.php.net/manual/en/class.jsonexception.php
@@ -171,6 +243,30 @@ func RegisterDefaultClasses(interpreter runtime.Interpreter) {
 
 	interpreter.AddClass(JsonException.Name, JsonException)
 
+	// -------------------------------------- Random\RandomError -------------------------------------- MARK: Random\RandomError
+
+	// Spec: https://www.php.net/manual/en/class.random-randomerror.php
+	RandomError := ast.NewClassDeclarationStmt(0, nil, `Random\RandomError`, false, false)
+	RandomError.BaseClass = "Error"
+
+	interpreter.AddClass(RandomError.Name, RandomError)
+
+	// -------------------------------------- Random\BrokenRandomEngineError -------------------------------------- MARK: Random\BrokenRandomEngineError
+
+	// Spec: https://www.php.net/manual/en/class.random-brokenrandomengineerror.php
+	BrokenRandomEngineError := ast.NewClassDeclarationStmt(0, nil, `Random\BrokenRandomEngineError`, false, false)
+	BrokenRandomEngineError.BaseClass = `Random\RandomError`
+
+	interpreter.AddClass(BrokenRandomEngineError.Name, BrokenRandomEngineError)
+
+	// -------------------------------------- Random\RandomException -------------------------------------- MARK: Random\RandomException
+
+	// Spec: https://www.php.net/manual/en/class.random-randomexception.php
+	RandomException := ast.NewClassDeclarationStmt(0, nil, `Random\RandomException`, false, false)
+	RandomException.BaseClass = "Exception"
+
+	interpreter.AddClass(RandomException.Name, RandomException)
+
 	// -------------------------------------- ReflectionException -------------------------------------- MARK: ReflectionException
 
 	// Spec: https://www.php.net/manual/en/class.reflectionexception.php
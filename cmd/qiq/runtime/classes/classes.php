@@ -1,6 +1,6 @@
 <?php
 
-// TODO InternalIterator
+// InternalIterator is implemented natively in runtime/stdlib/spl
 
 // -------------------------------------- Exception -------------------------------------- MARK: Exception
 
@@ -34,6 +34,7 @@ class Exception implements Throwable {
 
     final public function getTrace(): array { return $this->trace; }
 
+    // getTraceAsString and __toString are implemented natively in interpreter/exception.go
     final public function getTraceAsString(): string { return implode(PHP_EOL, $this->trace); }
 
     public function __toString(): string { return $this->string; }
@@ -100,6 +101,7 @@ class Error implements Throwable {
 
     final public function getTrace(): array { return $this->trace; }
 
+    // getTraceAsString and __toString are implemented natively in interpreter/exception.go
     final public function getTraceAsString(): string { return implode(PHP_EOL, $this->trace); }
 
     public function __toString(): string { return $this->string; }
@@ -152,7 +154,7 @@ class UnhandledMatchError extends Error {}
 // Spec: https://www.php.net/manual/en/class.requestparsebodyexception.php
 class RequestParseBodyException extends Exception {}
 
-// TODO Closure
+// Closure is implemented natively in interpreter/closure.go
 // TODO Generator
 
 // -------------------------------------- ClosedGeneratorException -------------------------------------- MARK: ClosedGeneratorException
@@ -160,8 +162,7 @@ class RequestParseBodyException extends Exception {}
 // Spec: https://www.php.net/manual/en/class.closedgeneratorexception.php
 class ClosedGeneratorException extends Exception {}
 
-// TODO WeakReference
-// TODO WeakMap
+// WeakReference and WeakMap are implemented natively in runtime/stdlib/spl
 // TODO Attribute
 // TODO ReturnTypeWillChange
 // TODO AllowDynamicProperties
@@ -171,7 +172,7 @@ class ClosedGeneratorException extends Exception {}
 // TODO Deprecated
 // TODO NoDiscard
 // TODO DelayedTargetValidation
-// TODO Fiber
+// Fiber is implemented natively in interpreter/fiber.go
 
 // -------------------------------------- FiberError -------------------------------------- MARK: FiberError
 
@@ -184,20 +185,54 @@ class FiberError extends Error {}
 class stdClass {
 }
 
-// TODO DateTime
-// TODO DateTimeImmutable
-// TODO DateTimeZone
-// TODO DateInterval
-// TODO DatePeriod
-// TODO DateError
-// TODO DateObjectError
-// TODO DateRangeError
-// TODO DateException
-// TODO DateInvalidTimeZoneException
-// TODO DateInvalidOperationException
-// TODO DateMalformedStringException
-// TODO DateMalformedIntervalStringException
-// TODO DateMalformedPeriodStringException
+// DateTimeInterface, DateTime, DateTimeImmutable, DateTimeZone, DateInterval and DatePeriod
+// are implemented natively in runtime/stdlib/dateTime
+
+// -------------------------------------- DateError -------------------------------------- MARK: DateError
+
+// Spec: https://www.php.net/manual/en/class.dateerror.php
+class DateError extends Error {}
+
+// -------------------------------------- DateObjectError -------------------------------------- MARK: DateObjectError
+
+// Spec: https://www.php.net/manual/en/class.dateobjecterror.php
+class DateObjectError extends DateError {}
+
+// -------------------------------------- DateRangeError -------------------------------------- MARK: DateRangeError
+
+// Spec: https://www.php.net/manual/en/class.daterangeerror.php
+class DateRangeError extends DateError {}
+
+// -------------------------------------- DateException -------------------------------------- MARK: DateException
+
+// Spec: https://www.php.net/manual/en/class.dateexception.php
+class DateException extends Exception {}
+
+// -------------------------------------- DateInvalidTimeZoneException -------------------------------------- MARK: DateInvalidTimeZoneException
+
+// Spec: https://www.php.net/manual/en/class.dateinvalidtimezoneexception.php
+class DateInvalidTimeZoneException extends DateException {}
+
+// -------------------------------------- DateInvalidOperationException -------------------------------------- MARK: DateInvalidOperationException
+
+// Spec: https://www.php.net/manual/en/class.dateinvalidoperationexception.php
+class DateInvalidOperationException extends DateException {}
+
+// -------------------------------------- DateMalformedStringException -------------------------------------- MARK: DateMalformedStringException
+
+// Spec: https://www.php.net/manual/en/class.datemalformedstringexception.php
+class DateMalformedStringException extends DateException {}
+
+// -------------------------------------- DateMalformedIntervalStringException -------------------------------------- MARK: DateMalformedIntervalStringException
+
+// Spec: https://www.php.net/manual/en/class.datemalformedintervalstringexception.php
+class DateMalformedIntervalStringException extends DateException {}
+
+// -------------------------------------- DateMalformedPeriodStringException -------------------------------------- MARK: DateMalformedPeriodStringException
+
+// Spec: https://www.php.net/manual/en/class.datemalformedperiodstringexception.php
+class DateMalformedPeriodStringException extends DateException {}
+
 // TODO BcMath\Number
 // TODO Filter\FilterException
 // TODO Filter\FilterFailedException
@@ -208,14 +243,24 @@ class stdClass {
 // Spec: https://www.php.net/manual/en/class.jsonexception.php
 class JsonException extends Exception {}
 
-// TODO Random\RandomError
-// TODO Random\BrokenRandomEngineError
-// TODO Random\RandomException
-// TODO Random\Engine\Mt19937
-// TODO Random\Engine\PcgOneseq128XslRr64
-// TODO Random\Engine\Xoshiro256StarStar
-// TODO Random\Engine\Secure
-// TODO Random\Randomizer
+// Random\Engine\Mt19937, Random\Engine\PcgOneseq128XslRr64, Random\Engine\Xoshiro256StarStar, Random\Engine\Secure
+// and Random\Randomizer are implemented natively in runtime/stdlib/random
+
+// -------------------------------------- Random\RandomError -------------------------------------- MARK: Random\RandomError
+
+// Spec: https://www.php.net/manual/en/class.random-randomerror.php
+class Random\RandomError extends Error {}
+
+// -------------------------------------- Random\BrokenRandomEngineError -------------------------------------- MARK: Random\BrokenRandomEngineError
+
+// Spec: https://www.php.net/manual/en/class.random-brokenrandomengineerror.php
+class Random\BrokenRandomEngineError extends Random\RandomError {}
+
+// -------------------------------------- Random\RandomException -------------------------------------- MARK: Random\RandomException
+
+// Spec: https://www.php.net/manual/en/class.random-randomexception.php
+class Random\RandomException extends Exception {}
+
 // TODO Random\IntervalBoundary
 
 // -------------------------------------- ReflectionException -------------------------------------- MARK: ReflectionException
@@ -322,14 +367,13 @@ class UnderflowException extends RuntimeException {}
 // Spec: https://www.php.net/manual/en/class.unexpectedvalueexception.php
 class UnexpectedValueException extends RuntimeException {}
 
-// TODO RecursiveIteratorIterator
-// TODO IteratorIterator
-// TODO FilterIterator
+// ArrayObject, ArrayIterator, SplDoublyLinkedList, SplQueue, SplStack, SplPriorityQueue, SplFixedArray, SplObjectStorage,
+// IteratorIterator, FilterIterator, CallbackFilterIterator, LimitIterator, RecursiveArrayIterator and RecursiveIteratorIterator
+// are implemented natively in runtime/stdlib/spl
+
 // TODO RecursiveFilterIterator
-// TODO CallbackFilterIterator
 // TODO RecursiveCallbackFilterIterator
 // TODO ParentIterator
-// TODO LimitIterator
 // TODO CachingIterator
 // TODO RecursiveCachingIterator
 // TODO NoRewindIterator
@@ -339,9 +383,6 @@ class UnexpectedValueException extends RuntimeException {}
 // TODO RecursiveRegexIterator
 // TODO EmptyIterator
 // TODO RecursiveTreeIterator
-// TODO ArrayObject
-// TODO ArrayIterator
-// TODO RecursiveArrayIterator
 // TODO SplFileInfo
 // TODO DirectoryIterator
 // TODO FilesystemIterator
@@ -349,15 +390,9 @@ class UnexpectedValueException extends RuntimeException {}
 // TODO GlobIterator
 // TODO SplFileObject
 // TODO SplTempFileObject
-// TODO SplDoublyLinkedList
-// TODO SplQueue
-// TODO SplStack
 // TODO SplHeap
 // TODO SplMinHeap
 // TODO SplMaxHeap
-// TODO SplPriorityQueue
-// TODO SplFixedArray
-// TODO SplObjectStorage
 // TODO MultipleIterator
 // TODO SessionHandler
 // TODO PhpToken
@@ -1,13 +1,78 @@
 package runtime
 
-import "QIQ/cmd/qiq/ast"
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime/values"
+	"strings"
+)
 
 type Context struct {
 	Interpreter Interpreter
 	Env         Environment
 	Stmt        ast.IStatement
+	// Slots of the arguments passed by reference (nil for arguments passed by value)
+	RefArgs []*values.Slot
 }
 
 func NewContext(interpreter Interpreter, env Environment, stmt ast.IStatement) Context {
 	return Context{Interpreter: interpreter, Env: env, Stmt: stmt}
 }
+
+// Assign a value to the variable that is passed by reference at the given position (starting with 0)
+func (context Context) SetRefArg(index int, value values.RuntimeValue) {
+	if index < len(context.RefArgs) && context.RefArgs[index] != nil {
+		context.RefArgs[index].Assign(value)
+	}
+}
+
+// Check that the argument of a native function is a valid callback (e.g. "usort(): Argument #2 ($callback) must be a valid callback, ...")
+func (context Context) ValidateCallback(functionName string, argNum int, paramName string, callback values.RuntimeValue, allowNull bool) phpError.Error {
+	if allowNull && callback.GetType() == values.NullValue {
+		return nil
+	}
+	if _, reason, ok := context.Interpreter.IsCallable(callback, false, context.Env); !ok {
+		orNull := ""
+		if allowNull {
+			orNull = " or null"
+		}
+		return phpError.NewError("Uncaught TypeError: %s(): Argument #%d (%s) must be a valid callback%s, %s%s", functionName, argNum, paramName, orNull, reason, context.InPosition())
+	}
+	return nil
+}
+
+// Position suffix for error messages (e.g. " in file.php:3:1").
+// Empty for callbacks without statement and for native methods which are called without position.
+func (context Context) InPosition() string {
+	if context.Stmt == nil {
+		return ""
+	}
+	pos := context.Stmt.GetPosString()
+	if pos == "" {
+		return ""
+	}
+	return " in " + pos
+}
+
+// Check that the argument of a native function is an object of the given class or interface.
+// If the parameter is nullable, null is allowed and nil is returned.
+func (context Context) ObjectArg(functionName string, argNum int, paramName string, className string, nullable bool, arg values.RuntimeValue) (*values.Object, phpError.Error) {
+	if nullable && arg.GetType() == values.NullValue {
+		return nil, nil
+	}
+	if object, isObject := arg.(*values.Object); isObject && context.Interpreter.GetExectionContext().IsInstanceOf(object.Class, className) {
+		return object, nil
+	}
+
+	expectedType := className
+	if nullable {
+		expectedType = "?" + className
+	}
+	givenType := strings.ToLower(values.ToPhpType(arg))
+	if object, isObject := arg.(*values.Object); isObject {
+		givenType = object.Class.GetQualifiedName()
+	}
+	return nil, phpError.NewError(
+		"Uncaught TypeError: %s(): Argument #%d (%s) must be of type %s, %s given", functionName, argNum, paramName, expectedType, givenType,
+	)
+}
@@ -8,8 +8,11 @@ import (
 type Environment interface {
 	// Variables
 	LookupVariable(variableName string) (*values.Slot, phpError.Error)
+	DeclareVariable(variableName string, value values.RuntimeValue) (*values.Slot, phpError.Error)
+	DeclareVariableByRef(variableName string, slot *values.Slot) (*values.Slot, phpError.Error)
 	// Functions
 	AddNativeFunction(functionName string, function NativeFunction)
+	AddNativeFunctionByRef(functionName string, function NativeFunction, byRefParams ByRefParams)
 	FunctionExists(functionName string) bool
 	// Constants
 	LookupConstant(constantName string) (values.RuntimeValue, phpError.Error)
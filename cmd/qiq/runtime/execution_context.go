@@ -3,6 +3,8 @@ package runtime
 import (
 	"QIQ/cmd/qiq/ast"
 	"QIQ/cmd/qiq/runtime/values"
+	"os"
+	"slices"
 	"strings"
 )
 
@@ -10,11 +12,73 @@ type ExecutionContext struct {
 	// Classes
 	classNames        []string
 	classDeclarations map[string]*ast.ClassDeclarationStatement
+	// Case objects of natively implemented enums by "class::case"
+	enumCases map[string]*values.Object
 	// Interfaces
 	interfaceNames        []string
 	interfaceDeclarations map[string]*ast.InterfaceDeclarationStatement
 	// Objects
-	objects map[string][]*values.Object
+	objects      map[string][]*values.Object
+	objectIds    map[*values.Object]int64
+	lastObjectId int64
+	// Resources
+	lastResourceId int64
+	// Resources with a release function that are neither closed nor released yet
+	releasableResources []*values.Resource
+	// Weak references: The WeakReference object of an object and the objects that reference an object weakly
+	weakReferences map[*values.Object]*values.Object
+	weakHolders    map[*values.Object][]*values.Object
+	// Native methods
+	nativeMethods map[string]NativeMethod
+	// Operator handlers of native classes
+	operatorHandlers map[string]OperatorHandler
+	// Error handling
+	errorHandlers     []ErrorHandler
+	exceptionHandlers []values.RuntimeValue
+	lastError         *LastError
+	// JSON
+	jsonLastError int64
+	// PCRE
+	pregLastError int64
+	// Date: Warnings and errors of the last date parsing (nil if there were none)
+	dateLastErrors *values.Array
+	// Date: Timezone set with date_default_timezone_set (empty if not set)
+	defaultTimezone string
+	// Filesystem: Result of the last stat and lstat call
+	statCache  statCacheEntry
+	lstatCache statCacheEntry
+	// Directory: Last directory opened with opendir or dir
+	defaultDirectory *values.Resource
+	// Random: State of the Mt19937 engine used by mt_rand, rand, shuffle, str_shuffle and array_rand (nil until seeded)
+	randomState any
+	// Shutdown functions
+	shutdownFunctions []ShutdownFunction
+}
+
+// Error handler registered with set_error_handler
+type ErrorHandler struct {
+	Callback values.RuntimeValue
+	Levels   int64
+}
+
+// Function registered with register_shutdown_function
+type ShutdownFunction struct {
+	Callback values.RuntimeValue
+	Args     []values.RuntimeValue
+}
+
+// Cached file information of stat or lstat
+type statCacheEntry struct {
+	filename string
+	info     os.FileInfo
+}
+
+// Last occurred error as returned by error_get_last
+type LastError struct {
+	Type    int64
+	Message string
+	File    string
+	Line    int64
 }
 
 func NewExecutionContext() *ExecutionContext {
@@ -22,11 +86,27 @@ func NewExecutionContext() *ExecutionContext {
 		// Classes
 		classNames:        []string{},
 		classDeclarations: map[string]*ast.ClassDeclarationStatement{},
+		enumCases:         map[string]*values.Object{},
 		// Interfaces
 		interfaceNames:        []string{},
 		interfaceDeclarations: map[string]*ast.InterfaceDeclarationStatement{},
 		// Objects
-		objects: map[string][]*values.Object{},
+		objects:   map[string][]*values.Object{},
+		objectIds: map[*values.Object]int64{},
+		// Resources: The ids 1 to 3 are used by STDIN, STDOUT and STDERR
+		lastResourceId: 3,
+		// Weak references
+		weakReferences: map[*values.Object]*values.Object{},
+		weakHolders:    map[*values.Object][]*values.Object{},
+		// Native methods
+		nativeMethods: map[string]NativeMethod{},
+		// Operator handlers of native classes
+		operatorHandlers: map[string]OperatorHandler{},
+		// Error handling
+		errorHandlers:     []ErrorHandler{},
+		exceptionHandlers: []values.RuntimeValue{},
+		// Shutdown functions
+		shutdownFunctions: []ShutdownFunction{},
 	}
 }
 
@@ -39,7 +119,8 @@ func (executionContext *ExecutionContext) AddClass(class string, classDecl *ast.
 }
 
 func (executionContext *ExecutionContext) GetClass(class string) (*ast.ClassDeclarationStatement, bool) {
-	classDeclaration, found := executionContext.classDeclarations[strings.ToLower(class)]
+	// Fully qualified names (e.g. \Random\Randomizer) are looked up without the leading backslash
+	classDeclaration, found := executionContext.classDeclarations[strings.ToLower(strings.TrimPrefix(class, `\`))]
 	if !found {
 		return nil, false
 	}
@@ -48,6 +129,56 @@ func (executionContext *ExecutionContext) GetClass(class string) (*ast.ClassDecl
 
 func (executionContext *ExecutionContext) GetClasses() []string { return executionContext.classNames }
 
+// Check if the given class is the class or a subclass of the given class or interface name
+func (executionContext *ExecutionContext) IsInstanceOf(class *ast.ClassDeclarationStatement, name string) bool {
+	for class != nil {
+		if strings.EqualFold(class.GetQualifiedName(), name) {
+			return true
+		}
+		for _, interfaceName := range class.Interfaces {
+			if executionContext.isSubInterfaceOf(interfaceName, name) {
+				return true
+			}
+		}
+		if class.BaseClass == "" {
+			return false
+		}
+		class, _ = executionContext.GetClass(class.BaseClass)
+	}
+	return false
+}
+
+// Add the case of a natively implemented enum. Every access to the case returns the same object.
+// Enum cases are referenced by their class until the end of the script
+func (executionContext *ExecutionContext) AddEnumCase(class string, name string, object *values.Object) {
+	executionContext.enumCases[strings.ToLower(class)+"::"+name] = object
+	values.Retain(object)
+}
+
+func (executionContext *ExecutionContext) GetEnumCase(class string, name string) (*values.Object, bool) {
+	object, found := executionContext.enumCases[strings.ToLower(class)+"::"+name]
+	return object, found
+}
+
+// Find the constant in the class, its parent classes or its interfaces
+func (executionContext *ExecutionContext) GetClassConst(class *ast.ClassDeclarationStatement, name string) (*ast.ClassConstDeclarationStatement, bool) {
+	for class != nil {
+		if constant, found := class.GetConst(name); found {
+			return constant, true
+		}
+		for _, interfaceName := range class.Interfaces {
+			if constant, found := executionContext.GetInterfaceConst(interfaceName, name); found {
+				return constant, true
+			}
+		}
+		if class.BaseClass == "" {
+			break
+		}
+		class, _ = executionContext.GetClass(class.BaseClass)
+	}
+	return nil, false
+}
+
 // -------------------------------------- Interfaces -------------------------------------- MARK: Interfaces
 
 func (executionContext *ExecutionContext) AddInterface(interfaceName string, interfaceDecl *ast.InterfaceDeclarationStatement) {
@@ -57,7 +188,7 @@ func (executionContext *ExecutionContext) AddInterface(interfaceName string, int
 }
 
 func (executionContext *ExecutionContext) GetInterface(interfaceName string) (*ast.InterfaceDeclarationStatement, bool) {
-	interfaceDecl, found := executionContext.interfaceDeclarations[strings.ToLower(interfaceName)]
+	interfaceDecl, found := executionContext.interfaceDeclarations[strings.ToLower(strings.TrimPrefix(interfaceName, `\`))]
 	if !found {
 		return nil, false
 	}
@@ -68,6 +199,39 @@ func (executionContext *ExecutionContext) GetInterfaces() []string {
 	return executionContext.interfaceNames
 }
 
+// Find the constant in the interface or its parent interfaces
+func (executionContext *ExecutionContext) GetInterfaceConst(interfaceName string, name string) (*ast.ClassConstDeclarationStatement, bool) {
+	interfaceDecl, found := executionContext.GetInterface(interfaceName)
+	if !found {
+		return nil, false
+	}
+	if constant, found := interfaceDecl.GetConst(name); found {
+		return constant, true
+	}
+	for _, parent := range interfaceDecl.Parents {
+		if constant, found := executionContext.GetInterfaceConst(parent, name); found {
+			return constant, true
+		}
+	}
+	return nil, false
+}
+
+func (executionContext *ExecutionContext) isSubInterfaceOf(interfaceName string, name string) bool {
+	if strings.EqualFold(strings.TrimPrefix(interfaceName, `\`), name) {
+		return true
+	}
+	interfaceDecl, found := executionContext.GetInterface(interfaceName)
+	if !found {
+		return false
+	}
+	for _, parent := range interfaceDecl.Parents {
+		if executionContext.isSubInterfaceOf(parent, name) {
+			return true
+		}
+	}
+	return false
+}
+
 // -------------------------------------- Objects -------------------------------------- MARK: Objects
 
 func (executionContext *ExecutionContext) AddObject(className string, object *values.Object) {
@@ -86,3 +250,269 @@ func (executionContext *ExecutionContext) CountObjects(className string) int {
 	}
 	return len(executionContext.objects[className])
 }
+
+// Get the unique id of the given object. Ids are assigned on first request.
+func (executionContext *ExecutionContext) GetObjectId(object *values.Object) int64 {
+	if id, found := executionContext.objectIds[object]; found {
+		return id
+	}
+	executionContext.lastObjectId++
+	executionContext.objectIds[object] = executionContext.lastObjectId
+	return executionContext.lastObjectId
+}
+
+// -------------------------------------- Resources -------------------------------------- MARK: Resources
+
+// Create a resource with the next unique id
+func (executionContext *ExecutionContext) NewResource(resourceType string, internal any) *values.Resource {
+	executionContext.lastResourceId++
+	return values.NewResource(executionContext.lastResourceId, resourceType, internal)
+}
+
+// Create a resource whose release function is called if the resource is no longer referenced or at the end of the script (see values.Release)
+func (executionContext *ExecutionContext) NewReleasableResource(resourceType string, internal any, release func()) *values.Resource {
+	resource := executionContext.NewResource(resourceType, internal)
+	resource.Release = release
+	// Closed and released resources are no longer tracked
+	executionContext.releasableResources = slices.DeleteFunc(executionContext.releasableResources, func(resource *values.Resource) bool { return resource.IsClosed })
+	executionContext.releasableResources = append(executionContext.releasableResources, resource)
+	return resource
+}
+
+// Release the resources that are still open at the end of the script
+func (executionContext *ExecutionContext) ReleaseAllResources() {
+	for _, resource := range executionContext.releasableResources {
+		resource.Close()
+	}
+	executionContext.releasableResources = []*values.Resource{}
+}
+
+// -------------------------------------- Weak references -------------------------------------- MARK: Weak references
+
+// Get the WeakReference object that was created for the given object
+func (executionContext *ExecutionContext) GetWeakReference(object *values.Object) (*values.Object, bool) {
+	weakReference, found := executionContext.weakReferences[object]
+	return weakReference, found
+}
+
+func (executionContext *ExecutionContext) SetWeakReference(object *values.Object, weakReference *values.Object) {
+	executionContext.weakReferences[object] = weakReference
+	executionContext.AddWeakHolder(object, weakReference)
+}
+
+// Register an object whose internal state references the given object weakly (see values.WeakReferences)
+func (executionContext *ExecutionContext) AddWeakHolder(object *values.Object, holder *values.Object) {
+	if !slices.Contains(executionContext.weakHolders[object], holder) {
+		executionContext.weakHolders[object] = append(executionContext.weakHolders[object], holder)
+	}
+}
+
+// Remove all weak references to an object that is freed
+func (executionContext *ExecutionContext) ReleaseWeakReferences(object *values.Object) {
+	holders, found := executionContext.weakHolders[object]
+	if !found {
+		return
+	}
+	delete(executionContext.weakReferences, object)
+	delete(executionContext.weakHolders, object)
+	for _, holder := range holders {
+		if internal, ok := holder.Internal.(values.WeakReferences); ok && !holder.IsReleased {
+			internal.RemoveWeakReference(object)
+		}
+	}
+}
+
+// -------------------------------------- Native methods -------------------------------------- MARK: Native methods
+
+func (executionContext *ExecutionContext) AddNativeMethod(className string, methodName string, method NativeMethod) {
+	executionContext.nativeMethods[strings.ToLower(className+"::"+methodName)] = method
+}
+
+func (executionContext *ExecutionContext) GetNativeMethod(className string, methodName string) (NativeMethod, bool) {
+	method, found := executionContext.nativeMethods[strings.ToLower(className+"::"+methodName)]
+	return method, found
+}
+
+// -------------------------------------- Operator handlers -------------------------------------- MARK: Operator handlers
+
+func (executionContext *ExecutionContext) AddOperatorHandler(className string, handler OperatorHandler) {
+	executionContext.operatorHandlers[strings.ToLower(className)] = handler
+}
+
+// Get the operator handler if the value is an object of a class (or a subclass of a class) that overloads operators
+func (executionContext *ExecutionContext) GetOperatorHandler(value values.RuntimeValue) (OperatorHandler, bool) {
+	object, isObject := value.(*values.Object)
+	if !isObject {
+		return nil, false
+	}
+	class := object.Class
+	for class != nil {
+		if handler, found := executionContext.operatorHandlers[strings.ToLower(class.GetQualifiedName())]; found {
+			return handler, true
+		}
+		if class.BaseClass == "" {
+			return nil, false
+		}
+		class, _ = executionContext.GetClass(class.BaseClass)
+	}
+	return nil, false
+}
+
+// -------------------------------------- Error handling -------------------------------------- MARK: Error handling
+
+// Get the active error handler. The callback is null if the standard error handler is active.
+func (executionContext *ExecutionContext) GetErrorHandler() (ErrorHandler, bool) {
+	if len(executionContext.errorHandlers) == 0 {
+		return ErrorHandler{}, false
+	}
+	return executionContext.errorHandlers[len(executionContext.errorHandlers)-1], true
+}
+
+func (executionContext *ExecutionContext) PushErrorHandler(handler ErrorHandler) {
+	executionContext.errorHandlers = append(executionContext.errorHandlers, handler)
+	values.Retain(handler.Callback)
+}
+
+func (executionContext *ExecutionContext) PopErrorHandler() {
+	if len(executionContext.errorHandlers) > 0 {
+		values.Release(executionContext.errorHandlers[len(executionContext.errorHandlers)-1].Callback)
+		executionContext.errorHandlers = executionContext.errorHandlers[:len(executionContext.errorHandlers)-1]
+	}
+}
+
+// Get the active exception handler. The handler is null if the default exception handler is active.
+func (executionContext *ExecutionContext) GetExceptionHandler() (values.RuntimeValue, bool) {
+	if len(executionContext.exceptionHandlers) == 0 {
+		return nil, false
+	}
+	return executionContext.exceptionHandlers[len(executionContext.exceptionHandlers)-1], true
+}
+
+func (executionContext *ExecutionContext) PushExceptionHandler(handler values.RuntimeValue) {
+	executionContext.exceptionHandlers = append(executionContext.exceptionHandlers, handler)
+	values.Retain(handler)
+}
+
+func (executionContext *ExecutionContext) PopExceptionHandler() {
+	if len(executionContext.exceptionHandlers) > 0 {
+		values.Release(executionContext.exceptionHandlers[len(executionContext.exceptionHandlers)-1])
+		executionContext.exceptionHandlers = executionContext.exceptionHandlers[:len(executionContext.exceptionHandlers)-1]
+	}
+}
+
+func (executionContext *ExecutionContext) GetLastError() *LastError {
+	return executionContext.lastError
+}
+
+func (executionContext *ExecutionContext) SetLastError(lastError *LastError) {
+	executionContext.lastError = lastError
+}
+
+// -------------------------------------- JSON -------------------------------------- MARK: JSON
+
+// Get the JSON_ERROR_* code of the last json_encode, json_decode or json_validate call
+func (executionContext *ExecutionContext) GetJsonLastError() int64 {
+	return executionContext.jsonLastError
+}
+
+func (executionContext *ExecutionContext) SetJsonLastError(code int64) {
+	executionContext.jsonLastError = code
+}
+
+// -------------------------------------- PCRE -------------------------------------- MARK: PCRE
+
+// Get the PREG_*_ERROR code of the last PCRE function call
+func (executionContext *ExecutionContext) GetPregLastError() int64 {
+	return executionContext.pregLastError
+}
+
+func (executionContext *ExecutionContext) SetPregLastError(code int64) {
+	executionContext.pregLastError = code
+}
+
+// -------------------------------------- Date -------------------------------------- MARK: Date
+
+// Get the warnings and errors of the last parsed date string as returned by DateTime::getLastErrors
+func (executionContext *ExecutionContext) GetDateLastErrors() (*values.Array, bool) {
+	return executionContext.dateLastErrors, executionContext.dateLastErrors != nil
+}
+
+func (executionContext *ExecutionContext) SetDateLastErrors(errors *values.Array) {
+	executionContext.dateLastErrors = errors
+}
+
+// Get the timezone set with date_default_timezone_set. The result is empty if no timezone was set.
+func (executionContext *ExecutionContext) GetDefaultTimezone() string {
+	return executionContext.defaultTimezone
+}
+
+func (executionContext *ExecutionContext) SetDefaultTimezone(timezone string) {
+	executionContext.defaultTimezone = timezone
+}
+
+// -------------------------------------- Filesystem -------------------------------------- MARK: Filesystem
+
+// Get the cached information of the file. Like PHP, only the last stat and the last lstat call are cached.
+func (executionContext *ExecutionContext) GetStatCache(filename string, link bool) (os.FileInfo, bool) {
+	entry := executionContext.statCache
+	if link {
+		entry = executionContext.lstatCache
+	}
+	if entry.info == nil || entry.filename != filename {
+		return nil, false
+	}
+	return entry.info, true
+}
+
+func (executionContext *ExecutionContext) SetStatCache(filename string, link bool, info os.FileInfo) {
+	if link {
+		executionContext.lstatCache = statCacheEntry{filename: filename, info: info}
+	} else {
+		executionContext.statCache = statCacheEntry{filename: filename, info: info}
+	}
+}
+
+func (executionContext *ExecutionContext) ClearStatCache() {
+	executionContext.statCache = statCacheEntry{}
+	executionContext.lstatCache = statCacheEntry{}
+}
+
+// -------------------------------------- Directory -------------------------------------- MARK: Directory
+
+// Get the directory used by readdir, rewinddir and closedir if no directory handle is passed
+func (executionContext *ExecutionContext) GetDefaultDirectory() (*values.Resource, bool) {
+	return executionContext.defaultDirectory, executionContext.defaultDirectory != nil
+}
+
+func (executionContext *ExecutionContext) SetDefaultDirectory(directory *values.Resource) {
+	executionContext.defaultDirectory = directory
+}
+
+// -------------------------------------- Random -------------------------------------- MARK: Random
+
+// Get the state of the engine used by mt_rand and the other seedable functions
+func (executionContext *ExecutionContext) GetRandomState() any {
+	return executionContext.randomState
+}
+
+func (executionContext *ExecutionContext) SetRandomState(state any) {
+	executionContext.randomState = state
+}
+
+// -------------------------------------- Shutdown functions -------------------------------------- MARK: Shutdown functions
+
+func (executionContext *ExecutionContext) AddShutdownFunction(function ShutdownFunction) {
+	executionContext.shutdownFunctions = append(executionContext.shutdownFunctions, function)
+	values.Retain(function.Callback)
+	for _, arg := range function.Args {
+		values.Retain(arg)
+	}
+}
+
+// Get the shutdown function with the given index in the order of registration
+func (executionContext *ExecutionContext) GetShutdownFunction(index int) (ShutdownFunction, bool) {
+	if index >= len(executionContext.shutdownFunctions) {
+		return ShutdownFunction{}, false
+	}
+	return executionContext.shutdownFunctions[index], true
+}
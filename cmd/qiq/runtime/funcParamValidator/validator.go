@@ -86,6 +86,13 @@ func (validator *Validator) Validate(args []values.RuntimeValue) ([]values.Runti
 				continue
 			}
 
+			// Spec: https://www.php.net/manual/en/language.types.declarations.php#language.types.declarations.strict
+			// The only exception to strict typing: an int value passes a float type declaration and is widened to float.
+			if arg.GetType() == values.IntValue && slices.Contains(param.paramType, "float") {
+				validatedArgs = append(validatedArgs, values.NewFloat(float64(arg.(*values.Int).Value)))
+				continue
+			}
+
 			typeStr := values.ToPhpType(arg)
 			if typeStr == "" {
 				return args, phpError.NewError("validate: No mapping for type %s", arg.GetType())
@@ -127,3 +127,21 @@ func TestCorrectParamType(t *testing.T) {
 		t.Errorf("\nExpected: nil\nGot: \"%s\"", err)
 	}
 }
+
+func TestIntToFloatWidening(t *testing.T) {
+	validator := NewValidator("testFn").AddParam("paramA", []string{"float"}, nil)
+	args, err := validator.Validate([]values.RuntimeValue{values.NewInt(42)})
+	if err != nil {
+		t.Errorf("\nExpected: nil\nGot: \"%s\"", err)
+		return
+	}
+	if args[0].GetType() != values.FloatValue || args[0].(*values.Float).Value != 42 {
+		t.Errorf("\nExpected: float(42)\nGot: %s", args[0].GetType())
+	}
+
+	validator = NewValidator("testFn").AddParam("paramA", []string{"int", "float"}, nil)
+	args, _ = validator.Validate([]values.RuntimeValue{values.NewInt(42)})
+	if args[0].GetType() != values.IntValue {
+		t.Errorf("\nExpected: int(42)\nGot: %s", args[0].GetType())
+	}
+}
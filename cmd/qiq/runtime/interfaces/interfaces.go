@@ -35,6 +35,34 @@ func RegisterDefaultInterfaces(interpreter runtime.Interpreter) {
 
 	interpreter.AddInterface(Iterator.Name, Iterator)
 
+	// -------------------------------------- SeekableIterator -------------------------------------- MARK: SeekableIterator
+
+	// Spec: https://www.php.net/manual/en/class.seekableiterator.php
+	SeekableIterator := ast.NewInterfaceDeclarationStmt(0, nil, "SeekableIterator")
+	SeekableIterator.Parents = append(SeekableIterator.Parents, "Iterator")
+	SeekableIterator.AddMethod(ast.NewMethodDefinitionStmt(0, nil, "seek", []string{"public"}, []ast.FunctionParameter{{Name: "$offset", Type: []string{"int"}}}, nil, []string{"void"}))
+
+	interpreter.AddInterface(SeekableIterator.Name, SeekableIterator)
+
+	// -------------------------------------- OuterIterator -------------------------------------- MARK: OuterIterator
+
+	// Spec: https://www.php.net/manual/en/class.outeriterator.php
+	OuterIterator := ast.NewInterfaceDeclarationStmt(0, nil, "OuterIterator")
+	OuterIterator.Parents = append(OuterIterator.Parents, "Iterator")
+	OuterIterator.AddMethod(ast.NewMethodDefinitionStmt(0, nil, "getInnerIterator", []string{"public"}, []ast.FunctionParameter{}, nil, []string{"null", "Iterator"}))
+
+	interpreter.AddInterface(OuterIterator.Name, OuterIterator)
+
+	// -------------------------------------- RecursiveIterator -------------------------------------- MARK: RecursiveIterator
+
+	// Spec: https://www.php.net/manual/en/class.recursiveiterator.php
+	RecursiveIterator := ast.NewInterfaceDeclarationStmt(0, nil, "RecursiveIterator")
+	RecursiveIterator.Parents = append(RecursiveIterator.Parents, "Iterator")
+	RecursiveIterator.AddMethod(ast.NewMethodDefinitionStmt(0, nil, "getChildren", []string{"public"}, []ast.FunctionParameter{}, nil, []string{"null", "RecursiveIterator"}))
+	RecursiveIterator.AddMethod(ast.NewMethodDefinitionStmt(0, nil, "hasChildren", []string{"public"}, []ast.FunctionParameter{}, nil, []string{"bool"}))
+
+	interpreter.AddInterface(RecursiveIterator.Name, RecursiveIterator)
+
 	// -------------------------------------- Serializable -------------------------------------- MARK: Serializable
 
 	// Spec: https://www.php.net/manual/en/class.serializable.php
@@ -93,4 +121,55 @@ func RegisterDefaultInterfaces(interpreter runtime.Interpreter) {
 	UnitEnum.AddMethod(ast.NewMethodDefinitionStmt(0, nil, "cases", []string{"public", "static"}, []ast.FunctionParameter{}, nil, []string{"array"}))
 
 	interpreter.AddInterface(UnitEnum.Name, UnitEnum)
+
+	// -------------------------------------- JsonSerializable -------------------------------------- MARK: JsonSerializable
+
+	// Spec: https://www.php.net/manual/en/class.jsonserializable.php
+	JsonSerializable := ast.NewInterfaceDeclarationStmt(0, nil, "JsonSerializable")
+	JsonSerializable.AddMethod(ast.NewMethodDefinitionStmt(0, nil, "jsonSerialize", []string{"public"}, []ast.FunctionParameter{}, nil, []string{"mixed"}))
+
+	interpreter.AddInterface(JsonSerializable.Name, JsonSerializable)
+
+	// -------------------------------------- DateTimeInterface -------------------------------------- MARK: DateTimeInterface
+
+	// Spec: https://www.php.net/manual/en/class.datetimeinterface.php
+	DateTimeInterface := ast.NewInterfaceDeclarationStmt(0, nil, "DateTimeInterface")
+	DateTimeInterface.AddConst(ast.NewClassConstDeclarationStmt(0, nil, "ATOM", ast.NewStringLiteralExpr(0, nil, `Y-m-d\TH:i:sP`, ast.SingleQuotedString), "public"))
+	DateTimeInterface.AddConst(ast.NewClassConstDeclarationStmt(0, nil, "COOKIE", ast.NewStringLiteralExpr(0, nil, `l, d-M-Y H:i:s T`, ast.SingleQuotedString), "public"))
+	DateTimeInterface.AddConst(ast.NewClassConstDeclarationStmt(0, nil, "ISO8601", ast.NewStringLiteralExpr(0, nil, `Y-m-d\TH:i:sO`, ast.SingleQuotedString), "public"))
+	DateTimeInterface.AddConst(ast.NewClassConstDeclarationStmt(0, nil, "ISO8601_EXPANDED", ast.NewStringLiteralExpr(0, nil, `X-m-d\TH:i:sP`, ast.SingleQuotedString), "public"))
+	DateTimeInterface.AddConst(ast.NewClassConstDeclarationStmt(0, nil, "RFC822", ast.NewStringLiteralExpr(0, nil, `D, d M y H:i:s O`, ast.SingleQuotedString), "public"))
+	DateTimeInterface.AddConst(ast.NewClassConstDeclarationStmt(0, nil, "RFC850", ast.NewStringLiteralExpr(0, nil, `l, d-M-y H:i:s T`, ast.SingleQuotedString), "public"))
+	DateTimeInterface.AddConst(ast.NewClassConstDeclarationStmt(0, nil, "RFC1036", ast.NewStringLiteralExpr(0, nil, `D, d M y H:i:s O`, ast.SingleQuotedString), "public"))
+	DateTimeInterface.AddConst(ast.NewClassConstDeclarationStmt(0, nil, "RFC1123", ast.NewStringLiteralExpr(0, nil, `D, d M Y H:i:s O`, ast.SingleQuotedString), "public"))
+	DateTimeInterface.AddConst(ast.NewClassConstDeclarationStmt(0, nil, "RFC7231", ast.NewStringLiteralExpr(0, nil, `D, d M Y H:i:s \G\M\T`, ast.SingleQuotedString), "public"))
+	DateTimeInterface.AddConst(ast.NewClassConstDeclarationStmt(0, nil, "RFC2822", ast.NewStringLiteralExpr(0, nil, `D, d M Y H:i:s O`, ast.SingleQuotedString), "public"))
+	DateTimeInterface.AddConst(ast.NewClassConstDeclarationStmt(0, nil, "RFC3339", ast.NewStringLiteralExpr(0, nil, `Y-m-d\TH:i:sP`, ast.SingleQuotedString), "public"))
+	DateTimeInterface.AddConst(ast.NewClassConstDeclarationStmt(0, nil, "RFC3339_EXTENDED", ast.NewStringLiteralExpr(0, nil, `Y-m-d\TH:i:s.vP`, ast.SingleQuotedString), "public"))
+	DateTimeInterface.AddConst(ast.NewClassConstDeclarationStmt(0, nil, "RSS", ast.NewStringLiteralExpr(0, nil, `D, d M Y H:i:s O`, ast.SingleQuotedString), "public"))
+	DateTimeInterface.AddConst(ast.NewClassConstDeclarationStmt(0, nil, "W3C", ast.NewStringLiteralExpr(0, nil, `Y-m-d\TH:i:sP`, ast.SingleQuotedString), "public"))
+	DateTimeInterface.AddMethod(ast.NewMethodDefinitionStmt(0, nil, "diff", []string{"public"}, []ast.FunctionParameter{{Name: "$targetObject", Type: []string{"DateTimeInterface"}}, {Name: "$absolute", Type: []string{"bool"}, DefaultValue: ast.NewConstantAccessExpr(0, nil, "FALSE")}}, nil, []string{"DateInterval"}))
+	DateTimeInterface.AddMethod(ast.NewMethodDefinitionStmt(0, nil, "format", []string{"public"}, []ast.FunctionParameter{{Name: "$format", Type: []string{"string"}}}, nil, []string{"string"}))
+	DateTimeInterface.AddMethod(ast.NewMethodDefinitionStmt(0, nil, "getOffset", []string{"public"}, []ast.FunctionParameter{}, nil, []string{"int"}))
+	DateTimeInterface.AddMethod(ast.NewMethodDefinitionStmt(0, nil, "getTimestamp", []string{"public"}, []ast.FunctionParameter{}, nil, []string{"int"}))
+	DateTimeInterface.AddMethod(ast.NewMethodDefinitionStmt(0, nil, "getMicrosecond", []string{"public"}, []ast.FunctionParameter{}, nil, []string{"int"}))
+	DateTimeInterface.AddMethod(ast.NewMethodDefinitionStmt(0, nil, "getTimezone", []string{"public"}, []ast.FunctionParameter{}, nil, []string{"DateTimeZone", "false"}))
+
+	interpreter.AddInterface(DateTimeInterface.Name, DateTimeInterface)
+
+	// -------------------------------------- Random\Engine -------------------------------------- MARK: Random\Engine
+
+	// Spec: https://www.php.net/manual/en/class.random-engine.php
+	RandomEngine := ast.NewInterfaceDeclarationStmt(0, nil, `Random\Engine`)
+	RandomEngine.AddMethod(ast.NewMethodDefinitionStmt(0, nil, "generate", []string{"public"}, []ast.FunctionParameter{}, nil, []string{"string"}))
+
+	interpreter.AddInterface(RandomEngine.Name, RandomEngine)
+
+	// -------------------------------------- Random\CryptoSafeEngine -------------------------------------- MARK: Random\CryptoSafeEngine
+
+	// Spec: https://www.php.net/manual/en/class.random-cryptosafeengine.php
+	CryptoSafeEngine := ast.NewInterfaceDeclarationStmt(0, nil, `Random\CryptoSafeEngine`)
+	CryptoSafeEngine.Parents = append(CryptoSafeEngine.Parents, `Random\Engine`)
+
+	interpreter.AddInterface(CryptoSafeEngine.Name, CryptoSafeEngine)
 }
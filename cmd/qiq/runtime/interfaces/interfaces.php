@@ -20,6 +20,25 @@ interface Iterator extends Traversable {
     public function valid(): bool;
 }
 
+// Spec: https://www.php.net/manual/en/class.seekableiterator.php
+interface SeekableIterator extends Iterator {
+    /* Methods */
+    public function seek(int $offset): void;
+}
+
+// Spec: https://www.php.net/manual/en/class.outeriterator.php
+interface OuterIterator extends Iterator {
+    /* Methods */
+    public function getInnerIterator(): ?Iterator;
+}
+
+// Spec: https://www.php.net/manual/en/class.recursiveiterator.php
+interface RecursiveIterator extends Iterator {
+    /* Methods */
+    public function getChildren(): ?RecursiveIterator;
+    public function hasChildren(): bool;
+}
+
 // Spec: https://www.php.net/manual/en/class.serializable.php
 interface Serializable {
     /* Methods */
@@ -65,3 +84,44 @@ interface UnitEnum {
     /* Methods */
     public static function cases(): array;
 }
+
+// Spec: https://www.php.net/manual/en/class.jsonserializable.php
+interface JsonSerializable {
+    /* Methods */
+    public function jsonSerialize(): mixed;
+}
+
+// Spec: https://www.php.net/manual/en/class.datetimeinterface.php
+interface DateTimeInterface {
+    /* Constants */
+    const ATOM = 'Y-m-d\TH:i:sP';
+    const COOKIE = 'l, d-M-Y H:i:s T';
+    const ISO8601 = 'Y-m-d\TH:i:sO';
+    const ISO8601_EXPANDED = 'X-m-d\TH:i:sP';
+    const RFC822 = 'D, d M y H:i:s O';
+    const RFC850 = 'l, d-M-y H:i:s T';
+    const RFC1036 = 'D, d M y H:i:s O';
+    const RFC1123 = 'D, d M Y H:i:s O';
+    const RFC7231 = 'D, d M Y H:i:s \G\M\T';
+    const RFC2822 = 'D, d M Y H:i:s O';
+    const RFC3339 = 'Y-m-d\TH:i:sP';
+    const RFC3339_EXTENDED = 'Y-m-d\TH:i:s.vP';
+    const RSS = 'D, d M Y H:i:s O';
+    const W3C = 'Y-m-d\TH:i:sP';
+    /* Methods */
+    public function diff(DateTimeInterface $targetObject, bool $absolute = false): DateInterval;
+    public function format(string $format): string;
+    public function getOffset(): int;
+    public function getTimestamp(): int;
+    public function getMicrosecond(): int;
+    public function getTimezone(): DateTimeZone|false;
+}
+
+// Spec: https://www.php.net/manual/en/class.random-engine.php
+interface Random\Engine {
+    /* Methods */
+    public function generate(): string;
+}
+
+// Spec: https://www.php.net/manual/en/class.random-cryptosafeengine.php
+interface Random\CryptoSafeEngine extends Random\Engine {}
@@ -16,6 +16,7 @@ type Interpreter interface {
 	GetExectionContext() *ExecutionContext
 	GetFilename() string
 	GetWorkingDir() string
+	SetWorkingDir(workingDir string)
 	// Request
 	GetRequest() *request.Request
 	GetResponse() *request.Response
@@ -27,6 +28,29 @@ type Interpreter interface {
 	AddInterface(interfaceName string, interfaceDecl *ast.InterfaceDeclarationStatement)
 	GetInterface(interfaceName string) (*ast.InterfaceDeclarationStatement, bool)
 	GetInterfaces() []string
+	// Functions
+	CallFunction(function values.RuntimeValue, args []values.RuntimeValue, env any) (values.RuntimeValue, phpError.Error)
+	// Call the function with the given argument slots. Parameters declared by reference modify the slots.
+	CallFunctionWithSlots(function values.RuntimeValue, args []*values.Slot, env any) (values.RuntimeValue, phpError.Error)
+	// Check if the value is callable. Returns the name of the callable or the reason why it is not callable.
+	IsCallable(function values.RuntimeValue, syntaxOnly bool, env any) (name string, reason string, ok bool)
+	// Call the function with positional arguments followed by arguments for the named parameters
+	CallFunctionWithNamedArgs(function values.RuntimeValue, args []values.RuntimeValue, namedArgs *values.Array, env any) (values.RuntimeValue, phpError.Error)
+	// Call the function like CallFunctionWithNamedArgs but forward the called class of the given environment to static methods (late static binding)
+	ForwardStaticCall(function values.RuntimeValue, args []values.RuntimeValue, namedArgs *values.Array, env any) (values.RuntimeValue, phpError.Error)
+	// Get the arguments passed to the function that is executed in the given environment
+	GetFunctionArgs(env any) ([]values.RuntimeValue, bool)
+	// Get the class that is bound to "static" in the given environment
+	GetCalledClass(env any) (*ast.ClassDeclarationStatement, bool)
+	// Objects
+	CallMethod(object *values.Object, method string, args []values.RuntimeValue, env any) (values.RuntimeValue, phpError.Error)
+	// Garbage collection
+	CollectCycles(env any) int64
+	GetGcStatus() GcStatus
+	// Error handling
+	CallErrorHandler(err phpError.Error) bool
+	// Get the frames of the call stack starting with the innermost call
+	GetBacktrace() []CallFrame
 	// Output
 	GetOutputBufferStack() *outputBuffer.Stack
 	Print(str string)
@@ -34,3 +58,16 @@ type Interpreter interface {
 	PrintError(err phpError.Error)
 	WriteResult(str string)
 }
+
+// Status of the garbage collector as returned by gc_status
+type GcStatus struct {
+	Runs            int64
+	Collected       int64
+	Threshold       int64
+	Roots           int64
+	Running         bool
+	BufferSize      int64
+	ApplicationTime float64
+	CollectorTime   float64
+	DestructorTime  float64
+}
@@ -3,6 +3,38 @@ package runtime
 import (
 	"QIQ/cmd/qiq/phpError"
 	"QIQ/cmd/qiq/runtime/values"
+	"slices"
 )
 
 type NativeFunction func([]values.RuntimeValue, Context) (values.RuntimeValue, phpError.Error)
+
+type NativeMethod func(*values.Object, []values.RuntimeValue, Context) (values.RuntimeValue, phpError.Error)
+
+// Handler of a native class that overloads operators (e.g. BcMath\Number). At least one operand is an object of the class.
+// Comparisons are passed as operator "<=>" and return an int. If the operands are not supported,
+// handled is false and the operator is applied like for other objects.
+type OperatorHandler func(lhs values.RuntimeValue, operator string, rhs values.RuntimeValue, context Context) (result values.RuntimeValue, handled bool, err phpError.Error)
+
+// Parameters of a native function that are passed by reference
+type ByRefParams struct {
+	positions []int
+	// All parameters after the last position are passed by reference as well
+	variadic bool
+}
+
+// The parameters at the given positions (starting with 0) are passed by reference
+func NewByRefParams(positions ...int) ByRefParams {
+	return ByRefParams{positions: positions}
+}
+
+// All parameters starting with the given position are passed by reference (e.g. array_multisort)
+func NewVariadicByRefParams(position int) ByRefParams {
+	return ByRefParams{positions: []int{position}, variadic: true}
+}
+
+func (params ByRefParams) IsByRef(index int) bool {
+	if len(params.positions) == 0 {
+		return false
+	}
+	return slices.Contains(params.positions, index) || (params.variadic && index >= params.positions[len(params.positions)-1])
+}
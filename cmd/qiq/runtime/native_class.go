@@ -0,0 +1,124 @@
+package runtime
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime/values"
+)
+
+// Builder for classes whose methods are implemented in Go
+type NativeClass struct {
+	interpreter Interpreter
+	Decl        *ast.ClassDeclarationStatement
+	enumCases   []*values.Object
+}
+
+func NewNativeClass(interpreter Interpreter, name string) *NativeClass {
+	return &NativeClass{interpreter: interpreter, Decl: ast.NewClassDeclarationStmt(0, nil, name, false, false)}
+}
+
+// Builder for pure enums like RoundingMode. The cases are added with AddEnumCase.
+func NewNativeEnum(interpreter Interpreter, name string) *NativeClass {
+	return NewNativeClass(interpreter, name).Final().Implements("UnitEnum").AddProperty("$name", "public", []string{"string"}, nil)
+}
+
+func (class *NativeClass) Extends(baseClass string) *NativeClass {
+	class.Decl.BaseClass = baseClass
+	return class
+}
+
+func (class *NativeClass) Implements(interfaces ...string) *NativeClass {
+	class.Decl.Interfaces = append(class.Decl.Interfaces, interfaces...)
+	return class
+}
+
+func (class *NativeClass) Abstract() *NativeClass {
+	class.Decl.IsAbstract = true
+	return class
+}
+
+func (class *NativeClass) Final() *NativeClass {
+	class.Decl.IsFinal = true
+	return class
+}
+
+// Add a native method. The parameters are only used for the signature and the required argument count.
+// The native method itself has to validate the arguments.
+func (class *NativeClass) AddMethod(name string, params []ast.FunctionParameter, returnType []string, method NativeMethod) *NativeClass {
+	return class.addMethod(name, []string{"public"}, params, returnType, method)
+}
+
+func (class *NativeClass) AddStaticMethod(name string, params []ast.FunctionParameter, returnType []string, method NativeMethod) *NativeClass {
+	return class.addMethod(name, []string{"public", "static"}, params, returnType, method)
+}
+
+// Add an abstract method that has to be implemented by user defined subclasses
+func (class *NativeClass) AddAbstractMethod(name string, params []ast.FunctionParameter, returnType []string) *NativeClass {
+	class.Decl.AddMethod(ast.NewMethodDefinitionStmt(0, nil, name, []string{"public", "abstract"}, params, nil, returnType))
+	return class
+}
+
+func (class *NativeClass) addMethod(name string, modifiers []string, params []ast.FunctionParameter, returnType []string, method NativeMethod) *NativeClass {
+	class.Decl.AddMethod(ast.NewMethodDefinitionStmt(0, nil, name, modifiers, params, nil, returnType))
+	class.interpreter.GetExectionContext().AddNativeMethod(class.Decl.Name, name, method)
+	return class
+}
+
+// Overload the arithmetic and comparison operators for objects of the class
+func (class *NativeClass) OverloadOperators(handler OperatorHandler) *NativeClass {
+	class.interpreter.GetExectionContext().AddOperatorHandler(class.Decl.Name, handler)
+	return class
+}
+
+func (class *NativeClass) AddConst(name string, value ast.IExpression) *NativeClass {
+	class.Decl.AddConst(ast.NewClassConstDeclarationStmt(0, nil, name, value, "public"))
+	return class
+}
+
+// Add a case to an enum created with NewNativeEnum
+func (class *NativeClass) AddEnumCase(name string) *NativeClass {
+	object := values.NewObject(class.Decl)
+	object.SetProperty("$name", values.NewStr(name))
+	class.enumCases = append(class.enumCases, object)
+	class.interpreter.GetExectionContext().AddEnumCase(class.Decl.Name, name, object)
+	return class
+}
+
+func (class *NativeClass) AddProperty(name string, visibility string, propertyType []string, initialValue ast.IExpression) *NativeClass {
+	class.Decl.AddProperty(ast.NewPropertyDeclarationStmt(0, nil, name, visibility, false, propertyType, initialValue))
+	return class
+}
+
+// Add a property that can only be set by the native methods of the class
+func (class *NativeClass) AddReadonlyProperty(name string, visibility string, propertyType []string) *NativeClass {
+	property := ast.NewPropertyDeclarationStmt(0, nil, name, visibility, false, propertyType, nil)
+	property.IsReadonly = true
+	class.Decl.AddProperty(property)
+	return class
+}
+
+func (class *NativeClass) Register() {
+	if len(class.enumCases) > 0 {
+		// Spec: https://www.php.net/manual/en/unitenum.cases.php
+		class.AddStaticMethod("cases", []ast.FunctionParameter{}, []string{"array"},
+			func(_ *values.Object, _ []values.RuntimeValue, _ Context) (values.RuntimeValue, phpError.Error) {
+				cases := values.NewArray()
+				for _, object := range class.enumCases {
+					cases.SetElement(nil, object)
+				}
+				return cases, nil
+			},
+		)
+	}
+	class.interpreter.AddClass(class.Decl.Name, class.Decl)
+}
+
+// Create a required parameter for a native method signature
+func NewParam(name string, paramType ...string) ast.FunctionParameter {
+	return ast.NewFunctionParam(false, name, paramType, nil)
+}
+
+// Create an optional parameter for a native method signature
+func NewOptionalParam(name string, defaultValue ast.IExpression, paramType ...string) ast.FunctionParameter {
+	return ast.NewFunctionParam(false, name, paramType, defaultValue)
+}
@@ -1,7 +1,49 @@
 package outputBuffer
 
+import "QIQ/cmd/qiq/runtime/values"
+
+// Spec: https://www.php.net/manual/en/outcontrol.constants.php
+const (
+	// Control flags passed to the output handler
+	PHP_OUTPUT_HANDLER_START int64 = 1
+	PHP_OUTPUT_HANDLER_WRITE int64 = 0
+	PHP_OUTPUT_HANDLER_FLUSH int64 = 4
+	PHP_OUTPUT_HANDLER_CLEAN int64 = 2
+	PHP_OUTPUT_HANDLER_FINAL int64 = 8
+	PHP_OUTPUT_HANDLER_CONT  int64 = PHP_OUTPUT_HANDLER_WRITE
+	PHP_OUTPUT_HANDLER_END   int64 = PHP_OUTPUT_HANDLER_FINAL
+
+	// Flags controlling which operations are allowed on the buffer
+	PHP_OUTPUT_HANDLER_CLEANABLE int64 = 16
+	PHP_OUTPUT_HANDLER_FLUSHABLE int64 = 32
+	PHP_OUTPUT_HANDLER_REMOVABLE int64 = 64
+	PHP_OUTPUT_HANDLER_STDFLAGS  int64 = PHP_OUTPUT_HANDLER_CLEANABLE | PHP_OUTPUT_HANDLER_FLUSHABLE | PHP_OUTPUT_HANDLER_REMOVABLE
+
+	// Status flags of the output handler
+	PHP_OUTPUT_HANDLER_STARTED   int64 = 4096
+	PHP_OUTPUT_HANDLER_DISABLED  int64 = 8192
+	PHP_OUTPUT_HANDLER_PROCESSED int64 = 16384
+)
+
+const DefaultHandlerName = "default output handler"
+
 type Buffer struct {
 	Content string
+	// User-defined output handler or nil for the default output handler
+	Handler values.RuntimeValue
+	// Name of the output handler as listed by ob_list_handlers
+	Name string
+	// The handler is called with PHP_OUTPUT_HANDLER_WRITE as soon as the content reaches the chunk size (0 = unlimited)
+	ChunkSize int64
+	Flags     int64
 }
 
-func NewBuffer() *Buffer { return &Buffer{} }
+func NewBuffer(handler values.RuntimeValue, name string, chunkSize int64, flags int64) *Buffer {
+	return &Buffer{Handler: handler, Name: name, ChunkSize: chunkSize, Flags: flags}
+}
+
+func (buffer *Buffer) IsCleanable() bool { return buffer.Flags&PHP_OUTPUT_HANDLER_CLEANABLE != 0 }
+
+func (buffer *Buffer) IsFlushable() bool { return buffer.Flags&PHP_OUTPUT_HANDLER_FLUSHABLE != 0 }
+
+func (buffer *Buffer) IsRemovable() bool { return buffer.Flags&PHP_OUTPUT_HANDLER_REMOVABLE != 0 }
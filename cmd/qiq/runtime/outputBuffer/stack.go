@@ -1,14 +1,27 @@
 package outputBuffer
 
+import "QIQ/cmd/qiq/runtime/values"
+
 type Stack struct {
 	buffers []*Buffer
+	// An output handler is currently running
+	IsHandlerRunning bool
+	// Flush the output after every output block (ob_implicit_flush)
+	ImplicitFlush bool
 }
 
 func NewStack() *Stack { return &Stack{buffers: []*Buffer{}} }
 
-func (stack *Stack) Push() { stack.buffers = append(stack.buffers, NewBuffer()) }
+// The stack holds a reference to the output handler of each buffer
+func (stack *Stack) Push(buffer *Buffer) {
+	stack.buffers = append(stack.buffers, buffer)
+	values.Retain(buffer.Handler)
+}
 
-func (stack *Stack) Pop() { stack.buffers = stack.buffers[:stack.Len()-1] }
+func (stack *Stack) Pop() {
+	values.Release(stack.GetLast().Handler)
+	stack.buffers = stack.buffers[:stack.Len()-1]
+}
 
 func (stack *Stack) Get(index int) *Buffer { return stack.buffers[index] }
 
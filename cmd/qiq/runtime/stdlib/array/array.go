@@ -5,24 +5,527 @@ import (
 	"QIQ/cmd/qiq/phpError"
 	"QIQ/cmd/qiq/runtime"
 	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/random"
+	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
 	"QIQ/cmd/qiq/runtime/values"
+	goMath "math"
 	"slices"
+	"strings"
 )
 
 func Register(environment runtime.Environment) {
 	// Category: Array Functions
+	environment.AddNativeFunction("array_all", nativeFn_array_all)
+	environment.AddNativeFunction("array_any", nativeFn_array_any)
+	environment.AddNativeFunction("array_change_key_case", nativeFn_array_change_key_case)
+	environment.AddNativeFunction("array_chunk", nativeFn_array_chunk)
+	environment.AddNativeFunction("array_column", nativeFn_array_column)
+	environment.AddNativeFunction("array_combine", nativeFn_array_combine)
+	environment.AddNativeFunction("array_count_values", nativeFn_array_count_values)
+	environment.AddNativeFunction("array_diff", nativeFn_array_diff)
+	environment.AddNativeFunction("array_diff_assoc", nativeFn_array_diff_assoc)
+	environment.AddNativeFunction("array_diff_key", nativeFn_array_diff_key)
+	environment.AddNativeFunction("array_diff_uassoc", nativeFn_array_diff_uassoc)
+	environment.AddNativeFunction("array_diff_ukey", nativeFn_array_diff_ukey)
+	environment.AddNativeFunction("array_fill", nativeFn_array_fill)
+	environment.AddNativeFunction("array_fill_keys", nativeFn_array_fill_keys)
+	environment.AddNativeFunction("array_filter", nativeFn_array_filter)
+	environment.AddNativeFunction("array_find", nativeFn_array_find)
+	environment.AddNativeFunction("array_find_key", nativeFn_array_find_key)
 	environment.AddNativeFunction("array_first", nativeFn_array_first)
 	environment.AddNativeFunction("array_flip", nativeFn_array_flip)
+	environment.AddNativeFunction("array_intersect", nativeFn_array_intersect)
+	environment.AddNativeFunction("array_intersect_assoc", nativeFn_array_intersect_assoc)
+	environment.AddNativeFunction("array_intersect_key", nativeFn_array_intersect_key)
+	environment.AddNativeFunction("array_intersect_uassoc", nativeFn_array_intersect_uassoc)
+	environment.AddNativeFunction("array_intersect_ukey", nativeFn_array_intersect_ukey)
+	environment.AddNativeFunction("array_is_list", nativeFn_array_is_list)
 	environment.AddNativeFunction("array_key_exists", nativeFn_array_key_exists)
 	environment.AddNativeFunction("array_key_first", nativeFn_array_key_first)
 	environment.AddNativeFunction("array_key_last", nativeFn_array_key_last)
 	environment.AddNativeFunction("array_keys", nativeFn_array_keys)
 	environment.AddNativeFunction("array_last", nativeFn_array_last)
-	environment.AddNativeFunction("array_pop", nativeFn_array_pop)
-	environment.AddNativeFunction("array_push", nativeFn_array_push)
+	environment.AddNativeFunction("array_map", nativeFn_array_map)
+	environment.AddNativeFunction("array_merge", nativeFn_array_merge)
+	environment.AddNativeFunction("array_merge_recursive", nativeFn_array_merge_recursive)
+	environment.AddNativeFunctionByRef("array_multisort", nativeFn_array_multisort, runtime.NewVariadicByRefParams(0))
+	environment.AddNativeFunction("array_pad", nativeFn_array_pad)
+	environment.AddNativeFunctionByRef("array_pop", nativeFn_array_pop, runtime.NewByRefParams(0))
+	environment.AddNativeFunction("array_product", nativeFn_array_product)
+	environment.AddNativeFunctionByRef("array_push", nativeFn_array_push, runtime.NewByRefParams(0))
 	environment.AddNativeFunction("array_rand", nativeFn_array_rand)
+	environment.AddNativeFunction("array_reduce", nativeFn_array_reduce)
+	environment.AddNativeFunction("array_replace", nativeFn_array_replace)
+	environment.AddNativeFunction("array_replace_recursive", nativeFn_array_replace_recursive)
+	environment.AddNativeFunction("array_reverse", nativeFn_array_reverse)
+	environment.AddNativeFunction("array_search", nativeFn_array_search)
+	environment.AddNativeFunctionByRef("array_shift", nativeFn_array_shift, runtime.NewByRefParams(0))
+	environment.AddNativeFunction("array_slice", nativeFn_array_slice)
+	environment.AddNativeFunctionByRef("array_splice", nativeFn_array_splice, runtime.NewByRefParams(0))
+	environment.AddNativeFunction("array_sum", nativeFn_array_sum)
+	environment.AddNativeFunction("array_udiff", nativeFn_array_udiff)
+	environment.AddNativeFunction("array_udiff_assoc", nativeFn_array_udiff_assoc)
+	environment.AddNativeFunction("array_udiff_uassoc", nativeFn_array_udiff_uassoc)
+	environment.AddNativeFunction("array_uintersect", nativeFn_array_uintersect)
+	environment.AddNativeFunction("array_uintersect_assoc", nativeFn_array_uintersect_assoc)
+	environment.AddNativeFunction("array_uintersect_uassoc", nativeFn_array_uintersect_uassoc)
+	environment.AddNativeFunction("array_unique", nativeFn_array_unique)
+	environment.AddNativeFunctionByRef("array_unshift", nativeFn_array_unshift, runtime.NewByRefParams(0))
+	environment.AddNativeFunction("array_values", nativeFn_array_values)
+	environment.AddNativeFunctionByRef("array_walk", nativeFn_array_walk, runtime.NewByRefParams(0))
+	environment.AddNativeFunctionByRef("array_walk_recursive", nativeFn_array_walk_recursive, runtime.NewByRefParams(0))
+	environment.AddNativeFunctionByRef("arsort", nativeFn_arsort, runtime.NewByRefParams(0))
+	environment.AddNativeFunctionByRef("asort", nativeFn_asort, runtime.NewByRefParams(0))
+	environment.AddNativeFunction("compact", nativeFn_compact)
 	environment.AddNativeFunction("count", nativeFn_count)
+	environment.AddNativeFunctionByRef("extract", nativeFn_extract, runtime.NewByRefParams(0))
+	environment.AddNativeFunction("in_array", nativeFn_in_array)
 	environment.AddNativeFunction("key_exists", nativeFn_array_key_exists)
+	environment.AddNativeFunctionByRef("krsort", nativeFn_krsort, runtime.NewByRefParams(0))
+	environment.AddNativeFunctionByRef("ksort", nativeFn_ksort, runtime.NewByRefParams(0))
+	environment.AddNativeFunctionByRef("natcasesort", nativeFn_natcasesort, runtime.NewByRefParams(0))
+	environment.AddNativeFunctionByRef("natsort", nativeFn_natsort, runtime.NewByRefParams(0))
+	environment.AddNativeFunction("range", nativeFn_range)
+	environment.AddNativeFunctionByRef("rsort", nativeFn_rsort, runtime.NewByRefParams(0))
+	environment.AddNativeFunctionByRef("shuffle", nativeFn_shuffle, runtime.NewByRefParams(0))
+	environment.AddNativeFunction("sizeof", nativeFn_count)
+	environment.AddNativeFunctionByRef("sort", nativeFn_sort, runtime.NewByRefParams(0))
+	environment.AddNativeFunctionByRef("uasort", nativeFn_uasort, runtime.NewByRefParams(0))
+	environment.AddNativeFunctionByRef("uksort", nativeFn_uksort, runtime.NewByRefParams(0))
+	environment.AddNativeFunctionByRef("usort", nativeFn_usort, runtime.NewByRefParams(0))
+
+	// Const Category: Array Constants
+	// Spec: https://www.php.net/manual/en/array.constants.php
+	environment.AddPredefinedConstant("ARRAY_FILTER_USE_BOTH", values.NewInt(ARRAY_FILTER_USE_BOTH))
+	environment.AddPredefinedConstant("ARRAY_FILTER_USE_KEY", values.NewInt(ARRAY_FILTER_USE_KEY))
+	environment.AddPredefinedConstant("CASE_LOWER", values.NewInt(CASE_LOWER))
+	environment.AddPredefinedConstant("CASE_UPPER", values.NewInt(CASE_UPPER))
+	environment.AddPredefinedConstant("COUNT_NORMAL", values.NewInt(COUNT_NORMAL))
+	environment.AddPredefinedConstant("COUNT_RECURSIVE", values.NewInt(COUNT_RECURSIVE))
+	environment.AddPredefinedConstant("EXTR_OVERWRITE", values.NewInt(EXTR_OVERWRITE))
+	environment.AddPredefinedConstant("EXTR_SKIP", values.NewInt(EXTR_SKIP))
+	environment.AddPredefinedConstant("EXTR_PREFIX_SAME", values.NewInt(EXTR_PREFIX_SAME))
+	environment.AddPredefinedConstant("EXTR_PREFIX_ALL", values.NewInt(EXTR_PREFIX_ALL))
+	environment.AddPredefinedConstant("EXTR_PREFIX_INVALID", values.NewInt(EXTR_PREFIX_INVALID))
+	environment.AddPredefinedConstant("EXTR_PREFIX_IF_EXISTS", values.NewInt(EXTR_PREFIX_IF_EXISTS))
+	environment.AddPredefinedConstant("EXTR_IF_EXISTS", values.NewInt(EXTR_IF_EXISTS))
+	environment.AddPredefinedConstant("EXTR_REFS", values.NewInt(EXTR_REFS))
+	environment.AddPredefinedConstant("SORT_ASC", values.NewInt(SORT_ASC))
+	environment.AddPredefinedConstant("SORT_DESC", values.NewInt(SORT_DESC))
+	environment.AddPredefinedConstant("SORT_REGULAR", values.NewInt(SORT_REGULAR))
+	environment.AddPredefinedConstant("SORT_NUMERIC", values.NewInt(SORT_NUMERIC))
+	environment.AddPredefinedConstant("SORT_STRING", values.NewInt(SORT_STRING))
+	environment.AddPredefinedConstant("SORT_LOCALE_STRING", values.NewInt(SORT_LOCALE_STRING))
+	environment.AddPredefinedConstant("SORT_NATURAL", values.NewInt(SORT_NATURAL))
+	environment.AddPredefinedConstant("SORT_FLAG_CASE", values.NewInt(SORT_FLAG_CASE))
+}
+
+// Spec: https://www.php.net/manual/en/array.constants.php
+const (
+	ARRAY_FILTER_USE_BOTH int64 = 1
+	ARRAY_FILTER_USE_KEY  int64 = 2
+	CASE_LOWER            int64 = 0
+	CASE_UPPER            int64 = 1
+	COUNT_NORMAL          int64 = 0
+	COUNT_RECURSIVE       int64 = 1
+	EXTR_OVERWRITE        int64 = 0
+	EXTR_SKIP             int64 = 1
+	EXTR_PREFIX_SAME      int64 = 2
+	EXTR_PREFIX_ALL       int64 = 3
+	EXTR_PREFIX_INVALID   int64 = 4
+	EXTR_PREFIX_IF_EXISTS int64 = 5
+	EXTR_IF_EXISTS        int64 = 6
+	EXTR_REFS             int64 = 256
+)
+
+// -------------------------------------- array_all -------------------------------------- MARK: array_all
+
+func nativeFn_array_all(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-all.php
+	array, callback, err := validateArrayAndCallback("array_all", args, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	for _, key := range array.Keys {
+		matches, err := callPredicate(callback, []values.RuntimeValue{getValue(array, key), key}, context)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		if !matches {
+			return values.NewBool(false), nil
+		}
+	}
+	return values.NewBool(true), nil
+}
+
+// Validate the arguments of a function with the signature "fn(array $array, callable $callback)"
+func validateArrayAndCallback(functionName string, args []values.RuntimeValue, context runtime.Context) (*values.Array, values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$array", []string{"array"}, nil).
+		AddParam("$callback", []string{"mixed"}, nil).
+		Validate(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := context.ValidateCallback(functionName, 2, "$callback", args[1], false); err != nil {
+		return nil, nil, err
+	}
+
+	return args[0].(*values.Array), args[1], nil
+}
+
+// -------------------------------------- array_any -------------------------------------- MARK: array_any
+
+func nativeFn_array_any(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-any.php
+	array, callback, err := validateArrayAndCallback("array_any", args, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	for _, key := range array.Keys {
+		matches, err := callPredicate(callback, []values.RuntimeValue{getValue(array, key), key}, context)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		if matches {
+			return values.NewBool(true), nil
+		}
+	}
+	return values.NewBool(false), nil
+}
+
+// -------------------------------------- array_change_key_case -------------------------------------- MARK: array_change_key_case
+
+func nativeFn_array_change_key_case(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-change-key-case.php
+	args, err := funcParamValidator.NewValidator("array_change_key_case").
+		AddParam("$array", []string{"array"}, nil).
+		AddParam("$case", []string{"int"}, values.NewInt(CASE_LOWER)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	array := args[0].(*values.Array)
+	result := values.NewArray()
+	for _, key := range array.Keys {
+		newKey := key
+		if key.GetType() == values.StrValue {
+			if args[1].(*values.Int).Value == CASE_LOWER {
+				newKey = values.NewStr(strings.ToLower(key.(*values.Str).Value))
+			} else {
+				newKey = values.NewStr(strings.ToUpper(key.(*values.Str).Value))
+			}
+		}
+		if err := result.SetElement(newKey, getValue(array, key)); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- array_chunk -------------------------------------- MARK: array_chunk
+
+func nativeFn_array_chunk(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-chunk.php
+	args, err := funcParamValidator.NewValidator("array_chunk").
+		AddParam("$array", []string{"array"}, nil).
+		AddParam("$length", []string{"int"}, nil).
+		AddParam("$preserve_keys", []string{"bool"}, values.NewBool(false)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	length := int(args[1].(*values.Int).Value)
+	if length < 1 {
+		return values.NewVoid(), argError(context, "Uncaught ValueError: array_chunk(): Argument #2 ($length) must be greater than 0")
+	}
+	preserveKeys := args[2].(*values.Bool).Value
+
+	array := args[0].(*values.Array)
+	result := values.NewArray()
+	var chunk *values.Array
+	for index, key := range array.Keys {
+		if index%length == 0 {
+			chunk = values.NewArray()
+			if err := result.SetElement(nil, chunk); err != nil {
+				return values.NewVoid(), err
+			}
+		}
+		var chunkKey values.RuntimeValue
+		if preserveKeys {
+			chunkKey = key
+		}
+		if err := chunk.SetElement(chunkKey, getValue(array, key)); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- array_column -------------------------------------- MARK: array_column
+
+func nativeFn_array_column(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-column.php
+	args, err := funcParamValidator.NewValidator("array_column").
+		AddParam("$array", []string{"array"}, nil).
+		AddParam("$column_key", []string{"int", "string", "null"}, nil).
+		AddParam("$index_key", []string{"int", "string", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// TODO array_column - support arrays of objects
+	array := args[0].(*values.Array)
+	columnKey := args[1]
+	indexKey := args[2]
+	result := values.NewArray()
+	for _, key := range array.Keys {
+		row := getValue(array, key)
+		if row.GetType() != values.ArrayValue {
+			continue
+		}
+		rowArray := row.(*values.Array)
+
+		// If column_key is null, the complete row is returned
+		value := row
+		if columnKey.GetType() != values.NullValue {
+			slot, found := rowArray.GetElement(columnKey)
+			if !found {
+				continue
+			}
+			value = slot.Value
+		}
+
+		var resultKey values.RuntimeValue
+		if indexKey.GetType() != values.NullValue {
+			if slot, found := rowArray.GetElement(indexKey); found {
+				resultKey, err = toArrayKey(slot.Value)
+				if err != nil {
+					return values.NewVoid(), err
+				}
+			}
+		}
+		if err := result.SetElement(resultKey, value); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- array_combine -------------------------------------- MARK: array_combine
+
+func nativeFn_array_combine(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-combine.php
+	args, err := funcParamValidator.NewValidator("array_combine").
+		AddParam("$keys", []string{"array"}, nil).
+		AddParam("$values", []string{"array"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	keys := args[0].(*values.Array)
+	arrayValues := args[1].(*values.Array)
+	if len(keys.Keys) != len(arrayValues.Keys) {
+		return values.NewVoid(), argError(context,
+			"Uncaught ValueError: array_combine(): Argument #1 ($keys) and argument #2 ($values) must have the same number of elements",
+		)
+	}
+
+	result := values.NewArray()
+	for index, key := range keys.Keys {
+		resultKey, err := toArrayKey(getValue(keys, key))
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		if err := result.SetElement(resultKey, getValue(arrayValues, arrayValues.Keys[index])); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- array_count_values -------------------------------------- MARK: array_count_values
+
+func nativeFn_array_count_values(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-count-values.php
+	args, err := funcParamValidator.NewValidator("array_count_values").AddParam("$array", []string{"array"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	array := args[0].(*values.Array)
+	result := values.NewArray()
+	for _, key := range array.Keys {
+		value := getValue(array, key)
+		if value.GetType() != values.IntValue && value.GetType() != values.StrValue {
+			context.Interpreter.PrintError(phpError.NewWarning(
+				"array_count_values(): Can only count string and integer values, entry skipped%s", context.InPosition(),
+			))
+			continue
+		}
+		count := int64(0)
+		if slot, found := result.GetElement(value); found {
+			count = slot.Value.(*values.Int).Value
+		}
+		if err := result.SetElement(value, values.NewInt(count+1)); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- array_fill -------------------------------------- MARK: array_fill
+
+func nativeFn_array_fill(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-fill.php
+	args, err := funcParamValidator.NewValidator("array_fill").
+		AddParam("$start_index", []string{"int"}, nil).
+		AddParam("$count", []string{"int"}, nil).
+		AddParam("$value", []string{"mixed"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	startIndex := args[0].(*values.Int).Value
+	count := args[1].(*values.Int).Value
+	if count < 0 {
+		return values.NewVoid(), argError(context, "Uncaught ValueError: array_fill(): Argument #2 ($count) must be greater than or equal to 0")
+	}
+
+	result := values.NewArray()
+	for index := range count {
+		if err := result.SetElement(values.NewInt(startIndex+index), values.DeepCopy(values.NewSlot(args[2])).Value); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- array_fill_keys -------------------------------------- MARK: array_fill_keys
+
+func nativeFn_array_fill_keys(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-fill-keys.php
+	args, err := funcParamValidator.NewValidator("array_fill_keys").
+		AddParam("$keys", []string{"array"}, nil).
+		AddParam("$value", []string{"mixed"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	keys := args[0].(*values.Array)
+	result := values.NewArray()
+	for _, key := range keys.Keys {
+		resultKey, err := toArrayKey(getValue(keys, key))
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		if err := result.SetElement(resultKey, values.DeepCopy(values.NewSlot(args[1])).Value); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- array_filter -------------------------------------- MARK: array_filter
+
+func nativeFn_array_filter(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-filter.php
+	args, err := funcParamValidator.NewValidator("array_filter").
+		AddParam("$array", []string{"array"}, nil).
+		AddParam("$callback", []string{"mixed"}, values.NewNull()).
+		AddParam("$mode", []string{"int"}, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if err := context.ValidateCallback("array_filter", 2, "$callback", args[1], true); err != nil {
+		return values.NewVoid(), err
+	}
+
+	array := args[0].(*values.Array)
+	callback := args[1]
+	mode := args[2].(*values.Int).Value
+	result := values.NewArray()
+	for _, key := range array.Keys {
+		value := getValue(array, key)
+
+		var keep bool
+		if callback.GetType() == values.NullValue {
+			// If no callback is supplied, all empty entries of array will be removed
+			keep, err = variableHandling.BoolVal(value)
+		} else {
+			switch mode {
+			case ARRAY_FILTER_USE_KEY:
+				keep, err = callPredicate(callback, []values.RuntimeValue{key}, context)
+			case ARRAY_FILTER_USE_BOTH:
+				keep, err = callPredicate(callback, []values.RuntimeValue{value, key}, context)
+			default:
+				keep, err = callPredicate(callback, []values.RuntimeValue{value}, context)
+			}
+		}
+		if err != nil {
+			return values.NewVoid(), err
+		}
+
+		if keep {
+			if err := result.SetElement(key, value); err != nil {
+				return values.NewVoid(), err
+			}
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- array_find -------------------------------------- MARK: array_find
+
+func nativeFn_array_find(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-find.php
+	array, callback, err := validateArrayAndCallback("array_find", args, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	for _, key := range array.Keys {
+		matches, err := callPredicate(callback, []values.RuntimeValue{getValue(array, key), key}, context)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		if matches {
+			return getValue(array, key), nil
+		}
+	}
+	return values.NewNull(), nil
+}
+
+// -------------------------------------- array_find_key -------------------------------------- MARK: array_find_key
+
+func nativeFn_array_find_key(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-find-key.php
+	array, callback, err := validateArrayAndCallback("array_find_key", args, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	for _, key := range array.Keys {
+		matches, err := callPredicate(callback, []values.RuntimeValue{getValue(array, key), key}, context)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		if matches {
+			return key, nil
+		}
+	}
+	return values.NewNull(), nil
 }
 
 // -------------------------------------- array_first -------------------------------------- MARK: array_first
@@ -69,6 +572,24 @@ func nativeFn_array_flip(args []values.RuntimeValue, _ runtime.Context) (values.
 	return result, nil
 }
 
+// -------------------------------------- array_is_list -------------------------------------- MARK: array_is_list
+
+func nativeFn_array_is_list(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-is-list.php
+	args, err := funcParamValidator.NewValidator("array_is_list").AddParam("$array", []string{"array"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// An array is considered a list if its keys consist of consecutive numbers from 0 to count($array)-1
+	for index, key := range args[0].(*values.Array).Keys {
+		if key.GetType() != values.IntValue || key.(*values.Int).Value != int64(index) {
+			return values.NewBool(false), nil
+		}
+	}
+	return values.NewBool(true), nil
+}
+
 // -------------------------------------- array_key_exists -------------------------------------- MARK: array_key_exists
 
 func nativeFn_array_key_exists(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -151,15 +672,36 @@ func nativeFn_array_keys(args []values.RuntimeValue, _ runtime.Context) (values.
 	// Spec: https://www.php.net/manual/en/function.array-keys.php
 	args, err := funcParamValidator.NewValidator("array_keys").
 		AddParam("$array", []string{"array"}, nil).
+		AddParam("$filter_value", []string{"mixed"}, values.NewVoid()).
+		AddParam("$strict", []string{"bool"}, values.NewBool(false)).
 		Validate(args)
 	if err != nil {
 		return values.NewVoid(), err
 	}
 
-	// TODO add support for alternative signature: array_keys(array $array, mixed $filter_value, bool $strict = false): array
-
-	result := values.NewArrayFromSlice(args[0].(*values.Array).Keys)
+	array := args[0].(*values.Array)
+	if args[1].GetType() == values.VoidValue {
+		return values.NewArrayFromSlice(array.Keys), nil
+	}
 
+	// Only the keys containing the filter value are returned
+	result := values.NewArray()
+	for _, key := range array.Keys {
+		var equal bool
+		if args[2].(*values.Bool).Value {
+			equal, err = strictEquals(getValue(array, key), args[1])
+		} else {
+			equal, err = looseEquals(getValue(array, key), args[1])
+		}
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		if equal {
+			if err := result.SetElement(nil, key); err != nil {
+				return values.NewVoid(), err
+			}
+		}
+	}
 	return result, nil
 }
 
@@ -183,37 +725,328 @@ func nativeFn_array_last(args []values.RuntimeValue, _ runtime.Context) (values.
 	return value.Value, nil
 }
 
-// -------------------------------------- array_pop -------------------------------------- MARK: array_pop
+// -------------------------------------- array_map -------------------------------------- MARK: array_map
 
-func nativeFn_array_pop(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
-	// Spec: https://www.php.net/manual/en/function.array-pop.php
-	args, err := funcParamValidator.NewValidator("array_pop").
+func nativeFn_array_map(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-map.php
+	args, err := funcParamValidator.NewValidator("array_map").
+		AddParam("$callback", []string{"mixed"}, nil).
 		AddParam("$array", []string{"array"}, nil).
+		AddVariableLenParam("$arrays", []string{"mixed"}).
 		Validate(args)
 	if err != nil {
 		return values.NewVoid(), err
 	}
 
-	array := args[0].(*values.Array)
-	if array.IsEmpty() {
-		return values.NewNull(), nil
+	if err := context.ValidateCallback("array_map", 1, "$callback", args[0], true); err != nil {
+		return values.NewVoid(), err
 	}
 
-	lastKey := LastKey(array)
-	value, _ := array.GetElement(lastKey)
-	if err := RemoveByKey(array, lastKey); err != nil {
-		return values.NewVoid(), err
+	callback := args[0]
+	arrays := []*values.Array{args[1].(*values.Array)}
+	additionalArrays := args[2].(*values.Array)
+	for index, key := range additionalArrays.Keys {
+		array := getValue(additionalArrays, key)
+		if array.GetType() != values.ArrayValue {
+			return values.NewVoid(), argError(context,
+				"Uncaught TypeError: array_map(): Argument #%d must be of type array, %s given", index+3, values.ToPhpType(array),
+			)
+		}
+		arrays = append(arrays, array.(*values.Array))
 	}
 
-	return value.Value, nil
-}
+	result := values.NewArray()
 
-// -------------------------------------- array_push -------------------------------------- MARK: array_push
+	// A single array preserves the keys
+	if len(arrays) == 1 {
+		if callback.GetType() == values.NullValue {
+			return arrays[0], nil
+		}
+		for _, key := range arrays[0].Keys {
+			value, err := context.Interpreter.CallFunction(callback, []values.RuntimeValue{getValue(arrays[0], key)}, context.Env)
+			if err != nil {
+				return values.NewVoid(), err
+			}
+			if err := result.SetElement(key, value); err != nil {
+				return values.NewVoid(), err
+			}
+		}
+		return result, nil
+	}
 
-func nativeFn_array_push(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
-	// Spec: https://www.php.net/manual/en/function.array-push.php
-	args, err := funcParamValidator.NewValidator("array_push").
-		AddParam("$array", []string{"array"}, nil).
+	// Multiple arrays are iterated in parallel. Shorter arrays are extended with null.
+	length := 0
+	for _, array := range arrays {
+		length = max(length, len(array.Keys))
+	}
+	for index := range length {
+		callbackArgs := make([]values.RuntimeValue, len(arrays))
+		for arrayIndex, array := range arrays {
+			if index < len(array.Keys) {
+				callbackArgs[arrayIndex] = getValue(array, array.Keys[index])
+			} else {
+				callbackArgs[arrayIndex] = values.NewNull()
+			}
+		}
+
+		var value values.RuntimeValue
+		if callback.GetType() == values.NullValue {
+			value = values.NewArrayFromSlice(callbackArgs)
+		} else {
+			value, err = context.Interpreter.CallFunction(callback, callbackArgs, context.Env)
+			if err != nil {
+				return values.NewVoid(), err
+			}
+		}
+		if err := result.SetElement(nil, value); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- array_merge -------------------------------------- MARK: array_merge
+
+func nativeFn_array_merge(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-merge.php
+	arrays, err := validateArrays("array_merge", args, 0, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Values with string keys overwrite previous values, values with integer keys are appended
+	result := values.NewArray()
+	for _, array := range arrays {
+		for _, key := range array.Keys {
+			if err := appendElement(result, key, getValue(array, key)); err != nil {
+				return values.NewVoid(), err
+			}
+		}
+	}
+	return result, nil
+}
+
+// Validate the arguments of a function with the signature "fn(array ...$arrays)"
+func validateArrays(functionName string, args []values.RuntimeValue, minArgs int, context runtime.Context) ([]*values.Array, phpError.Error) {
+	if len(args) < minArgs {
+		return nil, phpError.NewError(
+			"Uncaught ArgumentCountError: %s() expects at least %d argument, %d given", functionName, minArgs, len(args),
+		)
+	}
+
+	arrays := make([]*values.Array, len(args))
+	for index, arg := range args {
+		if arg.GetType() != values.ArrayValue {
+			return nil, argError(context,
+				"Uncaught TypeError: %s(): Argument #%d must be of type array, %s given", functionName, index+1, values.ToPhpType(arg),
+			)
+		}
+		arrays[index] = arg.(*values.Array)
+	}
+	return arrays, nil
+}
+
+// -------------------------------------- array_merge_recursive -------------------------------------- MARK: array_merge_recursive
+
+func nativeFn_array_merge_recursive(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-merge-recursive.php
+	arrays, err := validateArrays("array_merge_recursive", args, 0, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	result := values.NewArray()
+	for _, array := range arrays {
+		if err := mergeRecursive(result, array); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// If the arrays have the same string keys, the values for these keys are merged together into an array
+func mergeRecursive(target *values.Array, source *values.Array) phpError.Error {
+	for _, key := range source.Keys {
+		value := getValue(source, key)
+		existing, found := target.GetElement(key)
+		if key.GetType() == values.IntValue || !found {
+			if err := appendElement(target, key, value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		merged, ok := existing.Value.(*values.Array)
+		if !ok {
+			merged = values.NewArrayFromSlice([]values.RuntimeValue{existing.Value})
+		}
+		if valueArray, ok := value.(*values.Array); ok {
+			if err := mergeRecursive(merged, valueArray); err != nil {
+				return err
+			}
+		} else if err := merged.SetElement(nil, value); err != nil {
+			return err
+		}
+		existing.Assign(merged)
+	}
+	return nil
+}
+
+// -------------------------------------- array_pad -------------------------------------- MARK: array_pad
+
+func nativeFn_array_pad(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-pad.php
+	args, err := funcParamValidator.NewValidator("array_pad").
+		AddParam("$array", []string{"array"}, nil).
+		AddParam("$length", []string{"int"}, nil).
+		AddParam("$value", []string{"mixed"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	array := args[0].(*values.Array)
+	length := int(args[1].(*values.Int).Value)
+	padSize := max(length, -length) - len(array.Keys)
+	if padSize <= 0 {
+		return array, nil
+	}
+
+	result := values.NewArray()
+	pad := func() phpError.Error {
+		for range padSize {
+			if err := result.SetElement(nil, args[2]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// If length is negative, the array is padded on the left
+	if length < 0 {
+		if err := pad(); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	for _, key := range array.Keys {
+		if err := appendElement(result, key, getValue(array, key)); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	if length > 0 {
+		if err := pad(); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- array_pop -------------------------------------- MARK: array_pop
+
+func nativeFn_array_pop(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-pop.php
+	args, err := funcParamValidator.NewValidator("array_pop").
+		AddParam("$array", []string{"array"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	array := args[0].(*values.Array)
+	if array.IsEmpty() {
+		return values.NewNull(), nil
+	}
+
+	lastKey := LastKey(array)
+	value, _ := array.GetElement(lastKey)
+	if err := RemoveByKey(array, lastKey); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return value.Value, nil
+}
+
+// -------------------------------------- array_product -------------------------------------- MARK: array_product
+
+func nativeFn_array_product(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-product.php
+	args, err := funcParamValidator.NewValidator("array_product").AddParam("$array", []string{"array"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return computeArithmetic("array_product", "Multiplication", args[0].(*values.Array), values.NewInt(1), context,
+		func(lhs int64, rhs int64) (int64, bool) {
+			result := lhs * rhs
+			return result, lhs == 0 || (result/lhs == rhs && !(lhs == -1 && rhs == goMath.MinInt64))
+		},
+		func(lhs float64, rhs float64) float64 { return lhs * rhs },
+	)
+}
+
+// Combine the values of the array with the given operation.
+// The result is an integer as long as all values are integers and no overflow occurs.
+func computeArithmetic(
+	functionName string, operationName string, array *values.Array, initial values.RuntimeValue, context runtime.Context,
+	intOperation func(int64, int64) (int64, bool), floatOperation func(float64, float64) float64,
+) (values.RuntimeValue, phpError.Error) {
+	result := initial
+	for _, key := range array.Keys {
+		value := getValue(array, key)
+		if value.GetType() == values.ArrayValue || value.GetType() == values.ObjectValue {
+			context.Interpreter.PrintError(phpError.NewWarning(
+				"%s(): %s is not supported on type %s%s", functionName, operationName, values.ToPhpType(value), context.InPosition(),
+			))
+			continue
+		}
+
+		number, err := toNumber(value)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+
+		if result.GetType() == values.IntValue && number.GetType() == values.IntValue {
+			if intResult, ok := intOperation(result.(*values.Int).Value, number.(*values.Int).Value); ok {
+				result = values.NewInt(intResult)
+				continue
+			}
+		}
+
+		lhs, err := variableHandling.FloatVal(result, false)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		rhs, err := variableHandling.FloatVal(number, false)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		result = values.NewFloat(floatOperation(lhs, rhs))
+	}
+	return result, nil
+}
+
+// Convert a scalar value to int or float
+func toNumber(value values.RuntimeValue) (values.RuntimeValue, phpError.Error) {
+	switch value.GetType() {
+	case values.IntValue, values.FloatValue:
+		return value, nil
+	case values.StrValue:
+		str := strings.TrimSpace(value.(*values.Str).Value)
+		if common.IsFloatingLiteralWithSign(str, true) {
+			return variableHandling.ToValueType(values.FloatValue, value, true)
+		}
+		return variableHandling.ToValueType(values.IntValue, value, true)
+	default:
+		return variableHandling.ToValueType(values.IntValue, value, false)
+	}
+}
+
+// -------------------------------------- array_push -------------------------------------- MARK: array_push
+
+func nativeFn_array_push(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-push.php
+	args, err := funcParamValidator.NewValidator("array_push").
+		AddParam("$array", []string{"array"}, nil).
 		AddVariableLenParam("$values", []string{"mixed"}).
 		Validate(args)
 	if err != nil {
@@ -256,112 +1089,755 @@ func nativeFn_array_rand(args []values.RuntimeValue, context runtime.Context) (v
 		return values.NewVoid(), phpError.NewError("Uncaught ValueError: array_rand(): Argument #2 ($num) must be between 1 and the number of elements in argument #1 ($array) in %s", context.Stmt.GetPosString())
 	}
 
-	if num == arrayLen {
-		return values.NewArrayFromSlice(array.Keys), nil
+	keys, err := random.PickArrayKeys(array, num, context)
+	if err != nil {
+		return values.NewVoid(), err
 	}
+	if num == 1 {
+		return keys[0], nil
+	}
+	return values.NewArrayFromSlice(keys), nil
+}
 
-	random := common.NewUniqueRand(arrayLen)
+// -------------------------------------- array_reduce -------------------------------------- MARK: array_reduce
 
-	if num == 1 {
-		return array.Keys[random.Int()], nil
+func nativeFn_array_reduce(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-reduce.php
+	args, err := funcParamValidator.NewValidator("array_reduce").
+		AddParam("$array", []string{"array"}, nil).
+		AddParam("$callback", []string{"mixed"}, nil).
+		AddParam("$initial", []string{"mixed"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if err := context.ValidateCallback("array_reduce", 2, "$callback", args[1], false); err != nil {
+		return values.NewVoid(), err
+	}
+
+	array := args[0].(*values.Array)
+	carry := args[2]
+	for _, key := range array.Keys {
+		carry, err = context.Interpreter.CallFunction(args[1], []values.RuntimeValue{carry, getValue(array, key)}, context.Env)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return carry, nil
+}
+
+// -------------------------------------- array_replace -------------------------------------- MARK: array_replace
+
+func nativeFn_array_replace(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-replace.php
+	arrays, err := validateArrays("array_replace", args, 1, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	result := values.DeepCopy(values.NewSlot(arrays[0])).Value.(*values.Array)
+	for _, array := range arrays[1:] {
+		if err := replaceRecursive(result, array, false); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// Replace the values of the target array with the values of the source array with the same keys
+func replaceRecursive(target *values.Array, source *values.Array, recursive bool) phpError.Error {
+	for _, key := range source.Keys {
+		value := getValue(source, key)
+		if recursive && value.GetType() == values.ArrayValue {
+			if existing, found := target.GetElement(key); found && existing.GetType() == values.ArrayValue {
+				if err := replaceRecursive(existing.Value.(*values.Array), value.(*values.Array), true); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if err := target.SetElement(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// -------------------------------------- array_replace_recursive -------------------------------------- MARK: array_replace_recursive
+
+func nativeFn_array_replace_recursive(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-replace-recursive.php
+	arrays, err := validateArrays("array_replace_recursive", args, 1, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	result := values.DeepCopy(values.NewSlot(arrays[0])).Value.(*values.Array)
+	for _, array := range arrays[1:] {
+		if err := replaceRecursive(result, array, true); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- array_reverse -------------------------------------- MARK: array_reverse
+
+func nativeFn_array_reverse(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-reverse.php
+	args, err := funcParamValidator.NewValidator("array_reverse").
+		AddParam("$array", []string{"array"}, nil).
+		AddParam("$preserve_keys", []string{"bool"}, values.NewBool(false)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	array := args[0].(*values.Array)
+	preserveKeys := args[1].(*values.Bool).Value
+	result := values.NewArray()
+	for index := len(array.Keys) - 1; index >= 0; index-- {
+		key := array.Keys[index]
+		// Non-numeric keys are not affected by the preserve_keys setting and will always be preserved
+		if preserveKeys {
+			err = result.SetElement(key, getValue(array, key))
+		} else {
+			err = appendElement(result, key, getValue(array, key))
+		}
+		if err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- array_search -------------------------------------- MARK: array_search
+
+func nativeFn_array_search(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-search.php
+	args, err := funcParamValidator.NewValidator("array_search").
+		AddParam("$needle", []string{"mixed"}, nil).
+		AddParam("$haystack", []string{"array"}, nil).
+		AddParam("$strict", []string{"bool"}, values.NewBool(false)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	key, found, err := searchValue(args[1].(*values.Array), args[0], args[2].(*values.Bool).Value)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if !found {
+		return values.NewBool(false), nil
+	}
+	return key, nil
+}
+
+// -------------------------------------- array_shift -------------------------------------- MARK: array_shift
+
+func nativeFn_array_shift(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-shift.php
+	args, err := funcParamValidator.NewValidator("array_shift").AddParam("$array", []string{"array"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	array := args[0].(*values.Array)
+	if array.IsEmpty() {
+		return values.NewNull(), nil
+	}
+
+	value := getValue(array, array.Keys[0])
+	// All numerical array keys will be modified to start counting from zero while literal keys won't be affected
+	result := values.NewArray()
+	for _, key := range array.Keys[1:] {
+		if err := appendElement(result, key, getValue(array, key)); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	context.SetRefArg(0, result)
+
+	return value, nil
+}
+
+// -------------------------------------- array_slice -------------------------------------- MARK: array_slice
+
+func nativeFn_array_slice(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-slice.php
+	args, err := funcParamValidator.NewValidator("array_slice").
+		AddParam("$array", []string{"array"}, nil).
+		AddParam("$offset", []string{"int"}, nil).
+		AddParam("$length", []string{"int", "null"}, values.NewNull()).
+		AddParam("$preserve_keys", []string{"bool"}, values.NewBool(false)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	array := args[0].(*values.Array)
+	start, end := sliceBounds(len(array.Keys), args[1], args[2])
+	preserveKeys := args[3].(*values.Bool).Value
+
+	result := values.NewArray()
+	for _, key := range array.Keys[start:end] {
+		if preserveKeys {
+			err = result.SetElement(key, getValue(array, key))
+		} else {
+			err = appendElement(result, key, getValue(array, key))
+		}
+		if err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// Get the start and end index for the given offset and length as used by array_slice and array_splice.
+// A negative offset or length is counted from the end of the array.
+func sliceBounds(count int, offsetValue values.RuntimeValue, lengthValue values.RuntimeValue) (int, int) {
+	offset := int(offsetValue.(*values.Int).Value)
+	if offset < 0 {
+		offset = max(0, count+offset)
+	}
+	offset = min(offset, count)
+
+	end := count
+	if lengthValue.GetType() == values.IntValue {
+		length := int(lengthValue.(*values.Int).Value)
+		if length < 0 {
+			end = max(offset, count+length)
+		} else {
+			end = min(count, offset+length)
+		}
+	}
+	return offset, end
+}
+
+// -------------------------------------- array_splice -------------------------------------- MARK: array_splice
+
+func nativeFn_array_splice(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-splice.php
+	args, err := funcParamValidator.NewValidator("array_splice").
+		AddParam("$array", []string{"array"}, nil).
+		AddParam("$offset", []string{"int"}, nil).
+		AddParam("$length", []string{"int", "null"}, values.NewNull()).
+		AddParam("$replacement", []string{"mixed"}, values.NewArray()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	array := args[0].(*values.Array)
+	start, end := sliceBounds(len(array.Keys), args[1], args[2])
+
+	// If replacement is not an array, it will be typecast to one
+	replacement, ok := args[3].(*values.Array)
+	if !ok {
+		replacement = values.NewArrayFromSlice([]values.RuntimeValue{args[3]})
+	}
+
+	// Keys in the array are not preserved, string keys of the remaining elements are
+	removed := values.NewArray()
+	result := values.NewArray()
+	for index, key := range array.Keys {
+		if index == start {
+			for _, replacementKey := range replacement.Keys {
+				if err := result.SetElement(nil, getValue(replacement, replacementKey)); err != nil {
+					return values.NewVoid(), err
+				}
+			}
+		}
+		if index >= start && index < end {
+			err = removed.SetElement(nil, getValue(array, key))
+		} else {
+			err = appendElement(result, key, getValue(array, key))
+		}
+		if err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	if start >= len(array.Keys) {
+		for _, replacementKey := range replacement.Keys {
+			if err := result.SetElement(nil, getValue(replacement, replacementKey)); err != nil {
+				return values.NewVoid(), err
+			}
+		}
+	}
+	context.SetRefArg(0, result)
+
+	return removed, nil
+}
+
+// -------------------------------------- array_sum -------------------------------------- MARK: array_sum
+
+func nativeFn_array_sum(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-sum.php
+	args, err := funcParamValidator.NewValidator("array_sum").AddParam("$array", []string{"array"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return computeArithmetic("array_sum", "Addition", args[0].(*values.Array), values.NewInt(0), context,
+		func(lhs int64, rhs int64) (int64, bool) {
+			result := lhs + rhs
+			return result, (result > lhs) == (rhs > 0)
+		},
+		func(lhs float64, rhs float64) float64 { return lhs + rhs },
+	)
+}
+
+// -------------------------------------- array_unique -------------------------------------- MARK: array_unique
+
+func nativeFn_array_unique(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-unique.php
+	args, err := funcParamValidator.NewValidator("array_unique").
+		AddParam("$array", []string{"array"}, nil).
+		AddParam("$flags", []string{"int"}, values.NewInt(SORT_STRING)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// The first key of each value is kept
+	array := args[0].(*values.Array)
+	compare := getCompareFunc(args[1].(*values.Int).Value)
+	result := values.NewArray()
+	for _, key := range array.Keys {
+		value := getValue(array, key)
+		isDuplicate := false
+		for _, resultKey := range result.Keys {
+			comparison, err := compare(value, getValue(result, resultKey))
+			if err != nil {
+				return values.NewVoid(), err
+			}
+			if comparison == 0 {
+				isDuplicate = true
+				break
+			}
+		}
+		if !isDuplicate {
+			if err := result.SetElement(key, value); err != nil {
+				return values.NewVoid(), err
+			}
+		}
 	}
+	return result, nil
+}
+
+// -------------------------------------- array_unshift -------------------------------------- MARK: array_unshift
 
+func nativeFn_array_unshift(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-unshift.php
+	args, err := funcParamValidator.NewValidator("array_unshift").
+		AddParam("$array", []string{"array"}, nil).
+		AddVariableLenParam("$values", []string{"mixed"}).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// All numerical array keys will be modified to start counting from zero while literal keys won't be changed
 	result := values.NewArray()
-	for i := 0; i < int(num); i++ {
-		result.SetElement(nil, array.Keys[random.Int()])
+	for _, array := range []*values.Array{args[1].(*values.Array), args[0].(*values.Array)} {
+		for _, key := range array.Keys {
+			if err := appendElement(result, key, getValue(array, key)); err != nil {
+				return values.NewVoid(), err
+			}
+		}
+	}
+	context.SetRefArg(0, result)
+
+	return values.NewInt(int64(len(result.Keys))), nil
+}
+
+// -------------------------------------- array_values -------------------------------------- MARK: array_values
+
+func nativeFn_array_values(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-values.php
+	args, err := funcParamValidator.NewValidator("array_values").AddParam("$array", []string{"array"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
 	}
 
+	array := args[0].(*values.Array)
+	result := values.NewArray()
+	for _, key := range array.Keys {
+		if err := result.SetElement(nil, getValue(array, key)); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- array_walk -------------------------------------- MARK: array_walk
+
+func nativeFn_array_walk(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-walk.php
+	return walkArray("array_walk", args, context, false)
+}
+
+func walkArray(functionName string, args []values.RuntimeValue, context runtime.Context, recursive bool) (values.RuntimeValue, phpError.Error) {
+	// The argument is only passed to the callback if it is given
+	hasArg := len(args) > 2
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$array", []string{"array"}, nil).
+		AddParam("$callback", []string{"mixed"}, nil).
+		AddParam("$arg", []string{"mixed"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if err := context.ValidateCallback(functionName, 2, "$callback", args[1], false); err != nil {
+		return values.NewVoid(), err
+	}
+
+	var walk func(array *values.Array) phpError.Error
+	walk = func(array *values.Array) phpError.Error {
+		for _, key := range slices.Clone(array.Keys) {
+			// The value is passed as slot so that the callback can modify it by reference
+			slot, found := array.GetElement(key)
+			if !found {
+				continue
+			}
+			if recursive && slot.GetType() == values.ArrayValue {
+				if err := walk(slot.Value.(*values.Array)); err != nil {
+					return err
+				}
+				continue
+			}
+			callbackArgs := []*values.Slot{slot, values.NewSlot(key)}
+			if hasArg {
+				callbackArgs = append(callbackArgs, values.NewSlot(args[2]))
+			}
+			if _, err := context.Interpreter.CallFunctionWithSlots(args[1], callbackArgs, context.Env); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(args[0].(*values.Array)); err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewBool(true), nil
+}
+
+// -------------------------------------- array_walk_recursive -------------------------------------- MARK: array_walk_recursive
+
+func nativeFn_array_walk_recursive(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-walk-recursive.php
+	return walkArray("array_walk_recursive", args, context, true)
+}
+
+// -------------------------------------- compact -------------------------------------- MARK: compact
+
+func nativeFn_compact(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.compact.php
+	args, err := funcParamValidator.NewValidator("compact").
+		AddParam("$var_name", []string{"array", "string"}, nil).
+		AddVariableLenParam("$var_names", []string{"array", "string"}).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	result := values.NewArray()
+	var addVariables func(value values.RuntimeValue) phpError.Error
+	addVariables = func(value values.RuntimeValue) phpError.Error {
+		// Arrays can contain other arrays of variable names inside it
+		if array, ok := value.(*values.Array); ok {
+			for _, key := range array.Keys {
+				if err := addVariables(getValue(array, key)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		name, err := variableHandling.StrVal(value)
+		if err != nil {
+			return err
+		}
+		slot, err := context.Env.LookupVariable("$" + name)
+		if err != nil {
+			context.Interpreter.PrintError(phpError.NewWarning("compact(): Undefined variable $%s%s", name, context.InPosition()))
+			return nil
+		}
+		return result.SetElement(values.NewStr(name), values.DeepCopy(slot).Value)
+	}
+
+	if err := addVariables(args[0]); err != nil {
+		return values.NewVoid(), err
+	}
+	if err := addVariables(args[1]); err != nil {
+		return values.NewVoid(), err
+	}
 	return result, nil
 }
 
 // -------------------------------------- count -------------------------------------- MARK: count
 
-func nativeFn_count(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+func nativeFn_count(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
 	// Spec: https://www.php.net/manual/en/function.count.php
+	if len(args) == 1 && args[0].GetType() == values.ObjectValue {
+		object := args[0].(*values.Object)
+		if !context.Interpreter.GetExectionContext().IsInstanceOf(object.Class, "Countable") {
+			return values.NewVoid(), phpError.NewError(
+				"Uncaught TypeError: count(): Argument #1 ($value) must be of type Countable|array, %s given",
+				object.Class.GetQualifiedName(),
+			)
+		}
+		return context.Interpreter.CallMethod(object, "count", []values.RuntimeValue{}, context.Env)
+	}
+
 	args, err := funcParamValidator.NewValidator("count").
-		AddParam("$array", []string{"Countable", "array"}, nil).
+		AddParam("$value", []string{"Countable", "array"}, nil).
+		AddParam("$mode", []string{"int"}, values.NewInt(COUNT_NORMAL)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	mode := args[1].(*values.Int).Value
+	if mode != COUNT_NORMAL && mode != COUNT_RECURSIVE {
+		return values.NewVoid(), argError(context, "Uncaught ValueError: count(): Argument #2 ($mode) must be either COUNT_NORMAL or COUNT_RECURSIVE")
+	}
+
+	return values.NewInt(countElements(args[0].(*values.Array), mode == COUNT_RECURSIVE)), nil
+}
+
+func countElements(array *values.Array, recursive bool) int64 {
+	count := int64(len(array.Elements))
+	if recursive {
+		for _, slot := range array.Elements {
+			if slot.GetType() == values.ArrayValue {
+				count += countElements(slot.Value.(*values.Array), true)
+			}
+		}
+	}
+	return count
+}
+
+// -------------------------------------- extract -------------------------------------- MARK: extract
+
+func nativeFn_extract(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.extract.php
+	hasPrefix := len(args) > 2
+	args, err := funcParamValidator.NewValidator("extract").
+		AddParam("$array", []string{"array"}, nil).
+		AddParam("$flags", []string{"int"}, values.NewInt(EXTR_OVERWRITE)).
+		AddParam("$prefix", []string{"string"}, values.NewStr("")).
 		Validate(args)
-		// TOOD count param mode
 	if err != nil {
 		return values.NewVoid(), err
 	}
 
 	array := args[0].(*values.Array)
-	return values.NewInt(int64(len(array.Elements))), nil
+	flags := args[1].(*values.Int).Value
+	byRef := flags&EXTR_REFS != 0
+	mode := flags &^ EXTR_REFS
+	prefix := args[2].(*values.Str).Value
+
+	if mode < EXTR_OVERWRITE || mode > EXTR_IF_EXISTS {
+		return values.NewVoid(), argError(context, "Uncaught ValueError: extract(): Argument #2 ($flags) must be a valid extract type")
+	}
+	if mode > EXTR_SKIP && mode <= EXTR_PREFIX_IF_EXISTS && !hasPrefix {
+		return values.NewVoid(), argError(context, "Uncaught ValueError: extract(): Argument #3 ($prefix) is required when using this extract type")
+	}
+	if prefix != "" && !common.IsVariableName("$"+prefix) {
+		return values.NewVoid(), argError(context, "Uncaught ValueError: extract(): Argument #3 ($prefix) must be a valid identifier")
+	}
+
+	count := int64(0)
+	for _, key := range array.Keys {
+		name, err := variableHandling.StrVal(key)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		_, lookupErr := context.Env.LookupVariable("$" + name)
+		exists := lookupErr == nil
+
+		switch mode {
+		case EXTR_SKIP:
+			if exists {
+				continue
+			}
+		case EXTR_PREFIX_SAME:
+			if exists {
+				name = prefix + "_" + name
+			}
+		case EXTR_PREFIX_ALL:
+			name = prefix + "_" + name
+		case EXTR_PREFIX_INVALID:
+			if !common.IsVariableName("$" + name) {
+				name = prefix + "_" + name
+			}
+		case EXTR_IF_EXISTS:
+			if !exists {
+				continue
+			}
+		case EXTR_PREFIX_IF_EXISTS:
+			if !exists {
+				continue
+			}
+			name = prefix + "_" + name
+		}
+
+		// Only valid variable names are imported
+		if !common.IsVariableName("$"+name) || name == "this" {
+			continue
+		}
+
+		if byRef {
+			slot, _ := array.GetElement(key)
+			_, err = context.Env.DeclareVariableByRef("$"+name, slot)
+		} else {
+			_, err = context.Env.DeclareVariable("$"+name, getValue(array, key))
+		}
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		count++
+	}
+
+	return values.NewInt(count), nil
+}
+
+// -------------------------------------- in_array -------------------------------------- MARK: in_array
+
+func nativeFn_in_array(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.in-array.php
+	args, err := funcParamValidator.NewValidator("in_array").
+		AddParam("$needle", []string{"mixed"}, nil).
+		AddParam("$haystack", []string{"array"}, nil).
+		AddParam("$strict", []string{"bool"}, values.NewBool(false)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	_, found, err := searchValue(args[1].(*values.Array), args[0], args[2].(*values.Bool).Value)
+	return values.NewBool(found), err
+}
+
+// -------------------------------------- range -------------------------------------- MARK: range
+
+func nativeFn_range(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.range.php
+	args, err := funcParamValidator.NewValidator("range").
+		AddParam("$start", []string{"string", "int", "float"}, nil).
+		AddParam("$end", []string{"string", "int", "float"}, nil).
+		AddParam("$step", []string{"int", "float"}, values.NewInt(1)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	step, err := variableHandling.FloatVal(args[2], false)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	step = goMath.Abs(step)
+	if step == 0 {
+		return values.NewVoid(), argError(context, "Uncaught ValueError: range(): Argument #3 ($step) cannot be 0")
+	}
+
+	// If both start and end are strings with one byte, a range of characters is created
+	isChar := func(value values.RuntimeValue) bool {
+		return value.GetType() == values.StrValue && len(value.(*values.Str).Value) == 1 && !common.IsDecimalLiteral(value.(*values.Str).Value, false)
+	}
+	if isChar(args[0]) && isChar(args[1]) && args[2].GetType() == values.IntValue {
+		start, end := int64(args[0].(*values.Str).Value[0]), int64(args[1].(*values.Str).Value[0])
+		return createRange(start, end, int64(step), context, func(value int64) values.RuntimeValue {
+			return values.NewStr(string(byte(value)))
+		})
+	}
+
+	start, err := toNumber(args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	end, err := toNumber(args[1])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if start.GetType() == values.IntValue && end.GetType() == values.IntValue && step == goMath.Trunc(step) {
+		return createRange(start.(*values.Int).Value, end.(*values.Int).Value, int64(step), context, func(value int64) values.RuntimeValue {
+			return values.NewInt(value)
+		})
+	}
+
+	startFloat, err := variableHandling.FloatVal(start, false)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	endFloat, err := variableHandling.FloatVal(end, false)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if startFloat != endFloat && step > goMath.Abs(endFloat-startFloat) {
+		return values.NewVoid(), argError(context, "Uncaught ValueError: range(): Argument #3 ($step) must not exceed the specified range")
+	}
+	count := int64(goMath.Floor(goMath.Abs(endFloat-startFloat)/step + 1e-9))
+	if startFloat > endFloat {
+		step = -step
+	}
+	result := values.NewArray()
+	for index := range count + 1 {
+		if err := result.SetElement(nil, values.NewFloat(startFloat+float64(index)*step)); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// Create an array with the integer values from start to end
+func createRange(start int64, end int64, step int64, context runtime.Context, toValue func(int64) values.RuntimeValue) (values.RuntimeValue, phpError.Error) {
+	if start != end && (step > end-start && step > start-end) {
+		return values.NewVoid(), argError(context, "Uncaught ValueError: range(): Argument #3 ($step) must not exceed the specified range")
+	}
+	if start > end {
+		step = -step
+	}
+
+	result := values.NewArray()
+	for value := start; (step > 0 && value <= end) || (step < 0 && value >= end); value += step {
+		if err := result.SetElement(nil, toValue(value)); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- shuffle -------------------------------------- MARK: shuffle
+
+func nativeFn_shuffle(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.shuffle.php
+	args, err := funcParamValidator.NewValidator("shuffle").AddParam("$array", []string{"array"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// This function assigns new keys to the elements in array
+	result, err := random.ShuffleArray(args[0].(*values.Array), context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	context.SetRefArg(0, result)
+
+	return values.NewBool(true), nil
 }
 
 // TODO array
-// TODO array_all
-// TODO array_any
-// TODO array_change_key_case
-// TODO array_chunk
-// TODO array_column
-// TODO array_combine
-// TODO array_count_values
-// TODO array_diff
-// TODO array_diff_assoc
-// TODO array_diff_key
-// TODO array_diff_uassoc
-// TODO array_diff_ukey
-// TODO array_fill
-// TODO array_fill_keys
-// TODO array_filter
-// TODO array_find
-// TODO array_find_key
-// TODO array_intersect
-// TODO array_intersect_assoc
-// TODO array_intersect_key
-// TODO array_intersect_uassoc
-// TODO array_intersect_ukey
-// TODO array_is_list
-// TODO array_map
-// TODO array_merge
-// TODO array_merge_recursive
-// TODO array_multisort
-// TODO array_pad
-// TODO array_product
-// TODO array_reduce
-// TODO array_replace
-// TODO array_replace_recursive
-// TODO array_reverse
-// TODO array_search
-// TODO array_shift
-// TODO array_slice
-// TODO array_splice
-// TODO array_sum
-// TODO array_udiff
-// TODO array_udiff_assoc
-// TODO array_udiff_uassoc
-// TODO array_uintersect
-// TODO array_uintersect_assoc
-// TODO array_uintersect_uassoc
-// TODO array_unique
-// TODO array_unshift
-// TODO array_values
-// TODO array_walk
-// TODO array_walk_recursive
-// TODO arsort
-// TODO asort
-// TODO compact
 // TODO current
 // TODO end
-// TODO extract
-// TODO in_array
 // TODO key
-// TODO krsort
-// TODO ksort
 // TODO list
-// TODO natcasesort
-// TODO natsort
 // TODO next
 // TODO pos
 // TODO prev
-// TODO range
 // TODO reset
-// TODO rsort
-// TODO shuffle
-// TODO sizeof
-// TODO sort
-// TODO uasort
-// TODO uksort
-// TODO usort
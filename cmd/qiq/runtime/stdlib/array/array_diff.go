@@ -0,0 +1,263 @@
+package array
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/values"
+)
+
+// How the keys or values of the arrays are compared by the array_diff and array_intersect functions
+type compareMode int
+
+const (
+	compareNone     compareMode = iota // Not compared
+	compareInternal                    // Compared by the internal function
+	compareUser                        // Compared by a user supplied callback
+)
+
+// Compute the difference or intersection of arrays.
+// The user supplied callbacks are passed as last arguments: first the value callback, then the key callback.
+func computeSetOperation(functionName string, args []values.RuntimeValue, context runtime.Context, intersect bool, valueMode compareMode, keyMode compareMode) (values.RuntimeValue, phpError.Error) {
+	callbackCount := 0
+	if valueMode == compareUser {
+		callbackCount++
+	}
+	if keyMode == compareUser {
+		callbackCount++
+	}
+
+	if len(args) < 1+callbackCount {
+		if callbackCount == 0 {
+			return values.NewVoid(), phpError.NewError(
+				"Uncaught ArgumentCountError: %s() expects at least 1 argument, 0 given", functionName,
+			)
+		}
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ArgumentCountError: At least %d arguments are required, %d given", 1+callbackCount, len(args),
+		)
+	}
+
+	// Get the callbacks
+	arrayArgs := args[:len(args)-callbackCount]
+	var valueCompare, keyCompare compareFunc
+	callbackIndex := len(arrayArgs)
+	if valueMode == compareUser {
+		if err := context.ValidateCallback(functionName, callbackIndex+1, "$value_compare_func", args[callbackIndex], false); err != nil {
+			return values.NewVoid(), err
+		}
+		valueCompare = getUserCompareFunc(functionName, args[callbackIndex], context)
+		callbackIndex++
+	} else {
+		valueCompare = compareAsStrings
+	}
+	if keyMode == compareUser {
+		if err := context.ValidateCallback(functionName, callbackIndex+1, "$key_compare_func", args[callbackIndex], false); err != nil {
+			return values.NewVoid(), err
+		}
+		keyCompare = getUserCompareFunc(functionName, args[callbackIndex], context)
+	}
+
+	// Get the arrays
+	arrays := make([]*values.Array, len(arrayArgs))
+	for index, arg := range arrayArgs {
+		if arg.GetType() != values.ArrayValue {
+			paramName := "$arrays"
+			if index == 0 {
+				paramName = "$array"
+			}
+			return values.NewVoid(), argError(context,
+				"Uncaught TypeError: %s(): Argument #%d (%s) must be of type array, %s given", functionName, index+1, paramName, values.ToPhpType(arg),
+			)
+		}
+		arrays[index] = arg.(*values.Array)
+	}
+
+	// Check if the element is contained in the other array
+	containsElement := func(array *values.Array, key values.RuntimeValue, value values.RuntimeValue) (bool, phpError.Error) {
+		if keyMode == compareInternal {
+			slot, found := array.GetElement(key)
+			if !found || valueMode == compareNone {
+				return found, nil
+			}
+			result, err := valueCompare(value, slot.Value)
+			return result == 0, err
+		}
+
+		for _, otherKey := range array.Keys {
+			if keyMode == compareUser {
+				result, err := keyCompare(key, otherKey)
+				if err != nil {
+					return false, err
+				}
+				if result != 0 {
+					continue
+				}
+				if valueMode == compareNone {
+					return true, nil
+				}
+			}
+			result, err := valueCompare(value, getValue(array, otherKey))
+			if err != nil {
+				return false, err
+			}
+			if result == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	result := values.NewArray()
+	for _, key := range arrays[0].Keys {
+		value := getValue(arrays[0], key)
+		// Difference: Keep the elements not contained in any other array
+		// Intersection: Keep the elements contained in all other arrays
+		keep := true
+		for _, array := range arrays[1:] {
+			contained, err := containsElement(array, key, value)
+			if err != nil {
+				return values.NewVoid(), err
+			}
+			if contained != intersect {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			if err := result.SetElement(key, value); err != nil {
+				return values.NewVoid(), err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Two elements are considered equal if their string representations are identical: (string) $elem1 === (string) $elem2
+func compareAsStrings(lhs values.RuntimeValue, rhs values.RuntimeValue) (int, phpError.Error) {
+	lhsStr, err := variableHandling.StrVal(lhs)
+	if err != nil {
+		return 0, err
+	}
+	rhsStr, err := variableHandling.StrVal(rhs)
+	if err != nil {
+		return 0, err
+	}
+	if lhsStr == rhsStr {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// -------------------------------------- array_diff -------------------------------------- MARK: array_diff
+
+func nativeFn_array_diff(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-diff.php
+	return computeSetOperation("array_diff", args, context, false, compareInternal, compareNone)
+}
+
+// -------------------------------------- array_diff_assoc -------------------------------------- MARK: array_diff_assoc
+
+func nativeFn_array_diff_assoc(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-diff-assoc.php
+	return computeSetOperation("array_diff_assoc", args, context, false, compareInternal, compareInternal)
+}
+
+// -------------------------------------- array_diff_key -------------------------------------- MARK: array_diff_key
+
+func nativeFn_array_diff_key(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-diff-key.php
+	return computeSetOperation("array_diff_key", args, context, false, compareNone, compareInternal)
+}
+
+// -------------------------------------- array_diff_uassoc -------------------------------------- MARK: array_diff_uassoc
+
+func nativeFn_array_diff_uassoc(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-diff-uassoc.php
+	return computeSetOperation("array_diff_uassoc", args, context, false, compareInternal, compareUser)
+}
+
+// -------------------------------------- array_diff_ukey -------------------------------------- MARK: array_diff_ukey
+
+func nativeFn_array_diff_ukey(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-diff-ukey.php
+	return computeSetOperation("array_diff_ukey", args, context, false, compareNone, compareUser)
+}
+
+// -------------------------------------- array_intersect -------------------------------------- MARK: array_intersect
+
+func nativeFn_array_intersect(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-intersect.php
+	return computeSetOperation("array_intersect", args, context, true, compareInternal, compareNone)
+}
+
+// -------------------------------------- array_intersect_assoc -------------------------------------- MARK: array_intersect_assoc
+
+func nativeFn_array_intersect_assoc(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-intersect-assoc.php
+	return computeSetOperation("array_intersect_assoc", args, context, true, compareInternal, compareInternal)
+}
+
+// -------------------------------------- array_intersect_key -------------------------------------- MARK: array_intersect_key
+
+func nativeFn_array_intersect_key(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-intersect-key.php
+	return computeSetOperation("array_intersect_key", args, context, true, compareNone, compareInternal)
+}
+
+// -------------------------------------- array_intersect_uassoc -------------------------------------- MARK: array_intersect_uassoc
+
+func nativeFn_array_intersect_uassoc(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-intersect-uassoc.php
+	return computeSetOperation("array_intersect_uassoc", args, context, true, compareInternal, compareUser)
+}
+
+// -------------------------------------- array_intersect_ukey -------------------------------------- MARK: array_intersect_ukey
+
+func nativeFn_array_intersect_ukey(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-intersect-ukey.php
+	return computeSetOperation("array_intersect_ukey", args, context, true, compareNone, compareUser)
+}
+
+// -------------------------------------- array_udiff -------------------------------------- MARK: array_udiff
+
+func nativeFn_array_udiff(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-udiff.php
+	return computeSetOperation("array_udiff", args, context, false, compareUser, compareNone)
+}
+
+// -------------------------------------- array_udiff_assoc -------------------------------------- MARK: array_udiff_assoc
+
+func nativeFn_array_udiff_assoc(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-udiff-assoc.php
+	return computeSetOperation("array_udiff_assoc", args, context, false, compareUser, compareInternal)
+}
+
+// -------------------------------------- array_udiff_uassoc -------------------------------------- MARK: array_udiff_uassoc
+
+func nativeFn_array_udiff_uassoc(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-udiff-uassoc.php
+	return computeSetOperation("array_udiff_uassoc", args, context, false, compareUser, compareUser)
+}
+
+// -------------------------------------- array_uintersect -------------------------------------- MARK: array_uintersect
+
+func nativeFn_array_uintersect(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-uintersect.php
+	return computeSetOperation("array_uintersect", args, context, true, compareUser, compareNone)
+}
+
+// -------------------------------------- array_uintersect_assoc -------------------------------------- MARK: array_uintersect_assoc
+
+func nativeFn_array_uintersect_assoc(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-uintersect-assoc.php
+	return computeSetOperation("array_uintersect_assoc", args, context, true, compareUser, compareInternal)
+}
+
+// -------------------------------------- array_uintersect_uassoc -------------------------------------- MARK: array_uintersect_uassoc
+
+func nativeFn_array_uintersect_uassoc(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-uintersect-uassoc.php
+	return computeSetOperation("array_uintersect_uassoc", args, context, true, compareUser, compareUser)
+}
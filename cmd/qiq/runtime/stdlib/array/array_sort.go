@@ -0,0 +1,435 @@
+package array
+
+import (
+	"QIQ/cmd/qiq/common"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/values"
+	"slices"
+	"strings"
+)
+
+// Spec: https://www.php.net/manual/en/array.constants.php
+const (
+	SORT_REGULAR       int64 = 0
+	SORT_NUMERIC       int64 = 1
+	SORT_STRING        int64 = 2
+	SORT_DESC          int64 = 3
+	SORT_ASC           int64 = 4
+	SORT_LOCALE_STRING int64 = 5
+	SORT_NATURAL       int64 = 6
+	SORT_FLAG_CASE     int64 = 8
+)
+
+type compareFunc func(lhs values.RuntimeValue, rhs values.RuntimeValue) (int, phpError.Error)
+
+// Get the function comparing two values according to the given SORT_* flags
+func getCompareFunc(flags int64) compareFunc {
+	ignoreCase := flags&SORT_FLAG_CASE != 0
+
+	switch flags &^ SORT_FLAG_CASE {
+	case SORT_NUMERIC:
+		return func(lhs values.RuntimeValue, rhs values.RuntimeValue) (int, phpError.Error) {
+			lhsFloat, err := variableHandling.FloatVal(lhs, true)
+			if err != nil {
+				return 0, err
+			}
+			rhsFloat, err := variableHandling.FloatVal(rhs, true)
+			if err != nil {
+				return 0, err
+			}
+			switch {
+			case lhsFloat < rhsFloat:
+				return -1, nil
+			case lhsFloat > rhsFloat:
+				return 1, nil
+			default:
+				return 0, nil
+			}
+		}
+
+	case SORT_STRING, SORT_LOCALE_STRING:
+		// TODO SORT_LOCALE_STRING - compare strings based on the current locale
+		return func(lhs values.RuntimeValue, rhs values.RuntimeValue) (int, phpError.Error) {
+			lhsStr, rhsStr, err := toStrings(lhs, rhs, ignoreCase)
+			if err != nil {
+				return 0, err
+			}
+			return strings.Compare(lhsStr, rhsStr), nil
+		}
+
+	case SORT_NATURAL:
+		return func(lhs values.RuntimeValue, rhs values.RuntimeValue) (int, phpError.Error) {
+			lhsStr, rhsStr, err := toStrings(lhs, rhs, false)
+			if err != nil {
+				return 0, err
+			}
+			return common.NaturalCompare(lhsStr, rhsStr, ignoreCase), nil
+		}
+
+	default:
+		return func(lhs values.RuntimeValue, rhs values.RuntimeValue) (int, phpError.Error) {
+			result, err := variableHandling.CompareRelation(lhs, "<=>", rhs, false)
+			if err != nil {
+				return 0, err
+			}
+			return int(result.Value.(*values.Int).Value), nil
+		}
+	}
+}
+
+func toStrings(lhs values.RuntimeValue, rhs values.RuntimeValue, toLower bool) (string, string, phpError.Error) {
+	lhsStr, err := variableHandling.StrVal(lhs)
+	if err != nil {
+		return "", "", err
+	}
+	rhsStr, err := variableHandling.StrVal(rhs)
+	if err != nil {
+		return "", "", err
+	}
+	if toLower {
+		return strings.ToLower(lhsStr), strings.ToLower(rhsStr), nil
+	}
+	return lhsStr, rhsStr, nil
+}
+
+// Get the function comparing two values with a user defined callback
+func getUserCompareFunc(functionName string, callback values.RuntimeValue, context runtime.Context) compareFunc {
+	deprecationShown := false
+	call := func(lhs values.RuntimeValue, rhs values.RuntimeValue) (values.RuntimeValue, phpError.Error) {
+		return context.Interpreter.CallFunction(callback, []values.RuntimeValue{lhs, rhs}, context.Env)
+	}
+
+	return func(lhs values.RuntimeValue, rhs values.RuntimeValue) (int, phpError.Error) {
+		result, err := call(lhs, rhs)
+		if err != nil {
+			return 0, err
+		}
+
+		if result.GetType() == values.BoolValue {
+			if !deprecationShown {
+				deprecationShown = true
+				context.Interpreter.PrintError(phpError.NewDeprecatedError(
+					"%s(): Returning bool from comparison function is deprecated, return an integer less than, equal to, or greater than zero%s",
+					functionName, context.InPosition(),
+				))
+			}
+			if result.(*values.Bool).Value {
+				return 1, nil
+			}
+			// "false" can mean "less than" or "equal", so the values are compared again the other way round
+			result, err = call(rhs, lhs)
+			if err != nil {
+				return 0, err
+			}
+			isGreater, err := variableHandling.BoolVal(result)
+			if err != nil || !isGreater {
+				return 0, err
+			}
+			return -1, nil
+		}
+
+		resultInt, err := variableHandling.IntVal(result, false)
+		if err != nil {
+			return 0, err
+		}
+		return int(max(-1, min(1, resultInt))), nil
+	}
+}
+
+type sortElement struct {
+	key   values.RuntimeValue
+	value values.RuntimeValue
+}
+
+// Sort the elements of the array. The sort is stable, i.e. elements that compare as equal keep their order.
+func sortArray(array *values.Array, compare compareFunc, byKey bool, reverse bool, keepKeys bool) (*values.Array, phpError.Error) {
+	elements := make([]sortElement, len(array.Keys))
+	for index, key := range array.Keys {
+		elements[index] = sortElement{key: key, value: getValue(array, key)}
+	}
+
+	var sortErr phpError.Error
+	slices.SortStableFunc(elements, func(lhs sortElement, rhs sortElement) int {
+		if sortErr != nil {
+			return 0
+		}
+		if reverse {
+			lhs, rhs = rhs, lhs
+		}
+		var result int
+		if byKey {
+			result, sortErr = compare(lhs.key, rhs.key)
+		} else {
+			result, sortErr = compare(lhs.value, rhs.value)
+		}
+		return result
+	})
+	if sortErr != nil {
+		return array, sortErr
+	}
+
+	result := values.NewArray()
+	for _, element := range elements {
+		var key values.RuntimeValue
+		if keepKeys {
+			key = element.key
+		}
+		if err := result.SetElement(key, element.value); err != nil {
+			return array, err
+		}
+	}
+	return result, nil
+}
+
+// Validate the arguments of a sort function with the signature "sort(array &$array, int $flags = SORT_REGULAR): true",
+// sort the array and assign it to the variable passed by reference
+func sortWithFlags(functionName string, args []values.RuntimeValue, context runtime.Context, byKey bool, reverse bool, keepKeys bool) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$array", []string{"array"}, nil).
+		AddParam("$flags", []string{"int"}, values.NewInt(SORT_REGULAR)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	result, err := sortArray(args[0].(*values.Array), getCompareFunc(args[1].(*values.Int).Value), byKey, reverse, keepKeys)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	context.SetRefArg(0, result)
+	return values.NewBool(true), nil
+}
+
+// Validate the arguments of a sort function with the signature "usort(array &$array, callable $callback): true",
+// sort the array and assign it to the variable passed by reference
+func sortWithCallback(functionName string, args []values.RuntimeValue, context runtime.Context, byKey bool, keepKeys bool) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$array", []string{"array"}, nil).
+		AddParam("$callback", []string{"mixed"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if err := context.ValidateCallback(functionName, 2, "$callback", args[1], false); err != nil {
+		return values.NewVoid(), err
+	}
+
+	result, err := sortArray(args[0].(*values.Array), getUserCompareFunc(functionName, args[1], context), byKey, false, keepKeys)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	context.SetRefArg(0, result)
+	return values.NewBool(true), nil
+}
+
+// -------------------------------------- array_multisort -------------------------------------- MARK: array_multisort
+
+func nativeFn_array_multisort(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.array-multisort.php
+	if len(args) == 0 {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ArgumentCountError: Too few arguments to function array_multisort(), 0 passed and at least 1 expected",
+		)
+	}
+
+	type column struct {
+		argIndex    int
+		array       *values.Array
+		order       int64
+		flags       int64
+		orderIsSet  bool
+		flagsIsSet  bool
+		compareFunc compareFunc
+	}
+
+	paramName := func(index int) string {
+		if index == 0 {
+			return "$array"
+		}
+		return "$rest"
+	}
+
+	columns := []*column{}
+	for index, arg := range args {
+		if arg.GetType() == values.ArrayValue {
+			columns = append(columns, &column{argIndex: index, array: arg.(*values.Array), order: SORT_ASC, flags: SORT_REGULAR})
+			continue
+		}
+
+		if arg.GetType() != values.IntValue || len(columns) == 0 {
+			return values.NewVoid(), argError(context,
+				"Uncaught TypeError: array_multisort(): Argument #%d (%s) must be an array or a sort flag", index+1, paramName(index),
+			)
+		}
+
+		current := columns[len(columns)-1]
+		flag := arg.(*values.Int).Value
+		switch flag &^ SORT_FLAG_CASE {
+		case SORT_ASC, SORT_DESC:
+			if current.orderIsSet {
+				return values.NewVoid(), argError(context,
+					"Uncaught TypeError: array_multisort(): Argument #%d (%s) must be an array or a sort flag that has not already been specified",
+					index+1, paramName(index),
+				)
+			}
+			current.order = flag
+			current.orderIsSet = true
+		case SORT_REGULAR, SORT_NUMERIC, SORT_STRING, SORT_LOCALE_STRING, SORT_NATURAL:
+			if current.flagsIsSet {
+				return values.NewVoid(), argError(context,
+					"Uncaught TypeError: array_multisort(): Argument #%d (%s) must be an array or a sort flag that has not already been specified",
+					index+1, paramName(index),
+				)
+			}
+			current.flags = flag
+			current.flagsIsSet = true
+		default:
+			return values.NewVoid(), argError(context,
+				"Uncaught ValueError: array_multisort(): Argument #%d (%s) must be a valid sort flag", index+1, paramName(index),
+			)
+		}
+	}
+
+	size := len(columns[0].array.Keys)
+	for _, column := range columns {
+		if len(column.array.Keys) != size {
+			return values.NewVoid(), argError(context, "Uncaught ValueError: Array sizes are inconsistent")
+		}
+		column.compareFunc = getCompareFunc(column.flags)
+	}
+
+	// Sort the row indices by comparing the columns one after another
+	rows := make([]int, size)
+	for index := range rows {
+		rows[index] = index
+	}
+	var sortErr phpError.Error
+	slices.SortStableFunc(rows, func(lhs int, rhs int) int {
+		for _, column := range columns {
+			if sortErr != nil {
+				return 0
+			}
+			var result int
+			result, sortErr = column.compareFunc(getValue(column.array, column.array.Keys[lhs]), getValue(column.array, column.array.Keys[rhs]))
+			if result != 0 {
+				if column.order == SORT_DESC {
+					return -result
+				}
+				return result
+			}
+		}
+		return 0
+	})
+	if sortErr != nil {
+		return values.NewVoid(), sortErr
+	}
+
+	// Rebuild the arrays in the new order. String keys are preserved, integer keys are renumbered.
+	for _, column := range columns {
+		result := values.NewArray()
+		for _, row := range rows {
+			key := column.array.Keys[row]
+			if err := appendElement(result, key, getValue(column.array, key)); err != nil {
+				return values.NewVoid(), err
+			}
+		}
+		context.SetRefArg(column.argIndex, result)
+	}
+
+	return values.NewBool(true), nil
+}
+
+// -------------------------------------- arsort -------------------------------------- MARK: arsort
+
+func nativeFn_arsort(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.arsort.php
+	return sortWithFlags("arsort", args, context, false, true, true)
+}
+
+// -------------------------------------- asort -------------------------------------- MARK: asort
+
+func nativeFn_asort(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.asort.php
+	return sortWithFlags("asort", args, context, false, false, true)
+}
+
+// -------------------------------------- krsort -------------------------------------- MARK: krsort
+
+func nativeFn_krsort(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.krsort.php
+	return sortWithFlags("krsort", args, context, true, true, true)
+}
+
+// -------------------------------------- ksort -------------------------------------- MARK: ksort
+
+func nativeFn_ksort(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.ksort.php
+	return sortWithFlags("ksort", args, context, true, false, true)
+}
+
+// -------------------------------------- natcasesort -------------------------------------- MARK: natcasesort
+
+func nativeFn_natcasesort(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.natcasesort.php
+	return naturalSort("natcasesort", args, context, SORT_NATURAL|SORT_FLAG_CASE)
+}
+
+// -------------------------------------- natsort -------------------------------------- MARK: natsort
+
+func nativeFn_natsort(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.natsort.php
+	return naturalSort("natsort", args, context, SORT_NATURAL)
+}
+
+func naturalSort(functionName string, args []values.RuntimeValue, context runtime.Context, flags int64) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).AddParam("$array", []string{"array"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	result, err := sortArray(args[0].(*values.Array), getCompareFunc(flags), false, false, true)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	context.SetRefArg(0, result)
+	return values.NewBool(true), nil
+}
+
+// -------------------------------------- rsort -------------------------------------- MARK: rsort
+
+func nativeFn_rsort(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.rsort.php
+	return sortWithFlags("rsort", args, context, false, true, false)
+}
+
+// -------------------------------------- sort -------------------------------------- MARK: sort
+
+func nativeFn_sort(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.sort.php
+	return sortWithFlags("sort", args, context, false, false, false)
+}
+
+// -------------------------------------- uasort -------------------------------------- MARK: uasort
+
+func nativeFn_uasort(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.uasort.php
+	return sortWithCallback("uasort", args, context, false, true)
+}
+
+// -------------------------------------- uksort -------------------------------------- MARK: uksort
+
+func nativeFn_uksort(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.uksort.php
+	return sortWithCallback("uksort", args, context, true, true)
+}
+
+// -------------------------------------- usort -------------------------------------- MARK: usort
+
+func nativeFn_usort(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.usort.php
+	return sortWithCallback("usort", args, context, false, false)
+}
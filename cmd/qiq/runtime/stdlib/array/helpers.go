@@ -2,8 +2,10 @@ package array
 
 import (
 	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
 	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
 	"QIQ/cmd/qiq/runtime/values"
+	"fmt"
 	"slices"
 )
 
@@ -19,10 +21,100 @@ func RemoveByKey(array *values.Array, key values.RuntimeValue) phpError.Error {
 		return phpError.NewError("Array.RemoveByKey: Key %s not found", values.ToPhpType(key))
 	}
 
-	delete(array.Elements, mapKey)
+	array.RemoveElement(mapKey)
 	array.Keys = slices.DeleteFunc(array.Keys, func(k values.RuntimeValue) bool {
 		match, _ := variableHandling.Compare(k, "===", key)
 		return match.Value.(*values.Bool).Value
 	})
 	return nil
 }
+
+// Get the value of the element with the given key
+func getValue(array *values.Array, key values.RuntimeValue) values.RuntimeValue {
+	slot, found := array.GetElement(key)
+	if !found {
+		return values.NewNull()
+	}
+	return slot.Value
+}
+
+// Append the value to the result. Integer keys are renumbered, string keys are preserved.
+func appendElement(result *values.Array, key values.RuntimeValue, value values.RuntimeValue) phpError.Error {
+	if key.GetType() == values.IntValue {
+		return result.SetElement(nil, value)
+	}
+	return result.SetElement(key, value)
+}
+
+// Get a copy of the array with renumbered integer keys. String keys are preserved.
+func reindex(array *values.Array) (*values.Array, phpError.Error) {
+	result := values.NewArray()
+	for _, key := range array.Keys {
+		if err := appendElement(result, key, getValue(array, key)); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// Convert a value to an array key. Integers are used as they are, all other values are converted to string.
+func toArrayKey(value values.RuntimeValue) (values.RuntimeValue, phpError.Error) {
+	if value.GetType() == values.IntValue {
+		return value, nil
+	}
+	str, err := variableHandling.StrVal(value)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewStr(str), nil
+}
+
+func looseEquals(lhs values.RuntimeValue, rhs values.RuntimeValue) (bool, phpError.Error) {
+	result, err := variableHandling.Compare(lhs, "==", rhs)
+	if err != nil {
+		return false, err
+	}
+	return result.Value.(*values.Bool).Value, nil
+}
+
+func strictEquals(lhs values.RuntimeValue, rhs values.RuntimeValue) (bool, phpError.Error) {
+	result, err := variableHandling.Compare(lhs, "===", rhs)
+	if err != nil {
+		return false, err
+	}
+	return result.Value.(*values.Bool).Value, nil
+}
+
+// Search the value in the array and return the key of the first match
+func searchValue(array *values.Array, needle values.RuntimeValue, strict bool) (values.RuntimeValue, bool, phpError.Error) {
+	for _, key := range array.Keys {
+		var equal bool
+		var err phpError.Error
+		if strict {
+			equal, err = strictEquals(getValue(array, key), needle)
+		} else {
+			equal, err = looseEquals(getValue(array, key), needle)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if equal {
+			return key, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// Call the callback and convert the result to bool
+func callPredicate(callback values.RuntimeValue, args []values.RuntimeValue, context runtime.Context) (bool, phpError.Error) {
+	result, err := context.Interpreter.CallFunction(callback, args, context.Env)
+	if err != nil {
+		return false, err
+	}
+	return variableHandling.BoolVal(result)
+}
+
+// Create an error with the position of the function call (e.g. "Uncaught ValueError: array_chunk(): ... in file:1:1")
+func argError(context runtime.Context, format string, args ...any) phpError.Error {
+	return phpError.NewError("%s%s", fmt.Sprintf(format, args...), context.InPosition())
+}
@@ -0,0 +1,388 @@
+package bcmath
+
+import (
+	"QIQ/cmd/qiq/ini"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	phpMath "QIQ/cmd/qiq/runtime/stdlib/math"
+	"QIQ/cmd/qiq/runtime/values"
+	goMath "math"
+	"strconv"
+)
+
+func Register(environment runtime.Environment) {
+	// Category: BCMath Functions
+	environment.AddNativeFunction("bcadd", nativeFn_bcadd)
+	environment.AddNativeFunction("bcceil", nativeFn_bcceil)
+	environment.AddNativeFunction("bccomp", nativeFn_bccomp)
+	environment.AddNativeFunction("bcdiv", nativeFn_bcdiv)
+	environment.AddNativeFunction("bcdivmod", nativeFn_bcdivmod)
+	environment.AddNativeFunction("bcfloor", nativeFn_bcfloor)
+	environment.AddNativeFunction("bcmod", nativeFn_bcmod)
+	environment.AddNativeFunction("bcmul", nativeFn_bcmul)
+	environment.AddNativeFunction("bcpow", nativeFn_bcpow)
+	environment.AddNativeFunction("bcpowmod", nativeFn_bcpowmod)
+	environment.AddNativeFunction("bcround", nativeFn_bcround)
+	environment.AddNativeFunction("bcscale", nativeFn_bcscale)
+	environment.AddNativeFunction("bcsqrt", nativeFn_bcsqrt)
+	environment.AddNativeFunction("bcsub", nativeFn_bcsub)
+}
+
+func RegisterClasses(interpreter runtime.Interpreter) {
+	registerNumber(interpreter)
+}
+
+var (
+	arrayType     = []string{"array"}
+	intType       = []string{"int"}
+	nullOrIntType = []string{"int", "null"}
+	stringType    = []string{"string"}
+)
+
+// Validate a ?int $scale argument. If the scale is null, the default scale set with bcscale is used.
+func scaleArg(functionName string, argNum int, arg values.RuntimeValue, context runtime.Context) (int, phpError.Error) {
+	if arg.GetType() == values.NullValue {
+		return int(context.Interpreter.GetIni().GetInt("bcmath.scale")), nil
+	}
+	scale := arg.(*values.Int).Value
+	if scale < 0 || scale > goMath.MaxInt32 {
+		return 0, phpError.NewError("Uncaught ValueError: %s(): Argument #%d ($scale) must be between 0 and %d", functionName, argNum, goMath.MaxInt32)
+	}
+	return int(scale), nil
+}
+
+// Parse a string argument that must be a well-formed number
+func numberArg(functionName string, argNum int, paramName string, arg values.RuntimeValue) (*number, phpError.Error) {
+	num, ok := parseNumber(arg.(*values.Str).Value)
+	if !ok {
+		return nil, phpError.NewError("Uncaught ValueError: %s(): Argument #%d (%s) is not well-formed", functionName, argNum, paramName)
+	}
+	return num, nil
+}
+
+// Parse an argument that must be a well-formed integer number (e.g. the exponent of bcpow)
+func integerArg(functionName string, argNum int, paramName string, arg values.RuntimeValue) (*number, phpError.Error) {
+	num, err := numberArg(functionName, argNum, paramName, arg)
+	if err != nil {
+		return nil, err
+	}
+	if !num.isInteger() {
+		return nil, phpError.NewError("Uncaught ValueError: %s(): Argument #%d (%s) cannot have a fractional part", functionName, argNum, paramName)
+	}
+	return num, nil
+}
+
+// Validate the arguments of functions like bcadd: two numbers and an optional scale
+func binaryArgs(functionName string, args []values.RuntimeValue, context runtime.Context) (*number, *number, int, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$num1", stringType, nil).
+		AddParam("$num2", stringType, nil).
+		AddParam("$scale", nullOrIntType, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	num1, err := numberArg(functionName, 1, "$num1", args[0])
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	num2, err := numberArg(functionName, 2, "$num2", args[1])
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	scale, err := scaleArg(functionName, 3, args[2], context)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return num1, num2, scale, nil
+}
+
+// Validate the arguments of functions like bcfloor: a single number
+func unaryArg(functionName string, args []values.RuntimeValue) (*number, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).AddParam("$num", stringType, nil).Validate(args)
+	if err != nil {
+		return nil, err
+	}
+	return numberArg(functionName, 1, "$num", args[0])
+}
+
+func divisionByZeroError() phpError.Error {
+	return phpError.NewError("Uncaught DivisionByZeroError: Division by zero")
+}
+
+func moduloByZeroError() phpError.Error {
+	return phpError.NewError("Uncaught DivisionByZeroError: Modulo by zero")
+}
+
+// Convert an integer exponent to int64 like bcpow
+func exponentToInt(functionName string, argNum int, exponent *number) (int64, phpError.Error) {
+	integer := exponent.integer()
+	if !integer.IsInt64() {
+		return 0, phpError.NewError("Uncaught ValueError: %s(): Argument #%d ($exponent) is too large", functionName, argNum)
+	}
+	return integer.Int64(), nil
+}
+
+// -------------------------------------- bcadd -------------------------------------- MARK: bcadd
+
+func nativeFn_bcadd(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.bcadd.php
+	num1, num2, scale, err := binaryArgs("bcadd", args, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewStr(num1.add(num2).format(scale)), nil
+}
+
+// -------------------------------------- bcceil -------------------------------------- MARK: bcceil
+
+func nativeFn_bcceil(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.bcceil.php
+	num, err := unaryArg("bcceil", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewStr(num.ceil().String()), nil
+}
+
+// -------------------------------------- bccomp -------------------------------------- MARK: bccomp
+
+func nativeFn_bccomp(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.bccomp.php
+	num1, num2, scale, err := binaryArgs("bccomp", args, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	// Only the given number of decimal digits are compared
+	return values.NewInt(int64(num1.withScale(min(scale, num1.scale)).compare(num2.withScale(min(scale, num2.scale))))), nil
+}
+
+// -------------------------------------- bcdiv -------------------------------------- MARK: bcdiv
+
+func nativeFn_bcdiv(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.bcdiv.php
+	num1, num2, scale, err := binaryArgs("bcdiv", args, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if num2.isZero() {
+		return values.NewVoid(), divisionByZeroError()
+	}
+	return values.NewStr(num1.div(num2, scale).String()), nil
+}
+
+// -------------------------------------- bcdivmod -------------------------------------- MARK: bcdivmod
+
+func nativeFn_bcdivmod(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.bcdivmod.php
+	num1, num2, scale, err := binaryArgs("bcdivmod", args, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if num2.isZero() {
+		return values.NewVoid(), divisionByZeroError()
+	}
+	quotient, remainder := num1.divmod(num2)
+	return values.NewArrayFromSlice([]values.RuntimeValue{values.NewStr(quotient.String()), values.NewStr(remainder.format(scale))}), nil
+}
+
+// -------------------------------------- bcfloor -------------------------------------- MARK: bcfloor
+
+func nativeFn_bcfloor(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.bcfloor.php
+	num, err := unaryArg("bcfloor", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewStr(num.floor().String()), nil
+}
+
+// -------------------------------------- bcmod -------------------------------------- MARK: bcmod
+
+func nativeFn_bcmod(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.bcmod.php
+	num1, num2, scale, err := binaryArgs("bcmod", args, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if num2.isZero() {
+		return values.NewVoid(), moduloByZeroError()
+	}
+	_, remainder := num1.divmod(num2)
+	return values.NewStr(remainder.format(scale)), nil
+}
+
+// -------------------------------------- bcmul -------------------------------------- MARK: bcmul
+
+func nativeFn_bcmul(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.bcmul.php
+	num1, num2, scale, err := binaryArgs("bcmul", args, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewStr(num1.mul(num2).format(scale)), nil
+}
+
+// -------------------------------------- bcpow -------------------------------------- MARK: bcpow
+
+func nativeFn_bcpow(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.bcpow.php
+	args, err := funcParamValidator.NewValidator("bcpow").
+		AddParam("$num", stringType, nil).
+		AddParam("$exponent", stringType, nil).
+		AddParam("$scale", nullOrIntType, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	num, err := numberArg("bcpow", 1, "$num", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	exponentNum, err := integerArg("bcpow", 2, "$exponent", args[1])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	exponent, err := exponentToInt("bcpow", 2, exponentNum)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	scale, err := scaleArg("bcpow", 3, args[2], context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if exponent < 0 && num.isZero() {
+		return values.NewVoid(), phpError.NewError("Uncaught DivisionByZeroError: Negative power of zero")
+	}
+	return values.NewStr(num.pow(exponent, scale).format(scale)), nil
+}
+
+// -------------------------------------- bcpowmod -------------------------------------- MARK: bcpowmod
+
+func nativeFn_bcpowmod(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.bcpowmod.php
+	args, err := funcParamValidator.NewValidator("bcpowmod").
+		AddParam("$num", stringType, nil).
+		AddParam("$exponent", stringType, nil).
+		AddParam("$modulus", stringType, nil).
+		AddParam("$scale", nullOrIntType, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	num, err := integerArg("bcpowmod", 1, "$num", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	exponent, err := integerArg("bcpowmod", 2, "$exponent", args[1])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if exponent.value.Sign() < 0 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: bcpowmod(): Argument #2 ($exponent) must be greater than or equal to 0")
+	}
+	modulus, err := integerArg("bcpowmod", 3, "$modulus", args[2])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if modulus.isZero() {
+		return values.NewVoid(), moduloByZeroError()
+	}
+	scale, err := scaleArg("bcpowmod", 4, args[3], context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(num.powmod(exponent, modulus).format(scale)), nil
+}
+
+// -------------------------------------- bcround -------------------------------------- MARK: bcround
+
+func nativeFn_bcround(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.bcround.php
+	args, err := funcParamValidator.NewValidator("bcround").
+		AddParam("$num", stringType, nil).
+		AddParam("$precision", intType, values.NewInt(0)).
+		AddParam("$mode", []string{"int", "object"}, values.NewInt(phpMath.RoundHalfUp)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	num, err := numberArg("bcround", 1, "$num", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	mode, err := phpMath.RoundingModeArg("bcround", 3, args[2])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewStr(num.round(int(args[1].(*values.Int).Value), mode).String()), nil
+}
+
+// -------------------------------------- bcscale -------------------------------------- MARK: bcscale
+
+func nativeFn_bcscale(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.bcscale.php
+	args, err := funcParamValidator.NewValidator("bcscale").AddParam("$scale", nullOrIntType, values.NewNull()).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	oldScale := context.Interpreter.GetIni().GetInt("bcmath.scale")
+	if args[0].GetType() == values.NullValue {
+		return values.NewInt(oldScale), nil
+	}
+
+	scale, err := scaleArg("bcscale", 1, args[0], context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	// The default scale is stored in the ini directive like in PHP, so that ini_get("bcmath.scale") returns the new scale
+	if err := context.Interpreter.GetIni().Set("bcmath.scale", strconv.Itoa(scale), ini.INI_USER); err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(oldScale), nil
+}
+
+// -------------------------------------- bcsqrt -------------------------------------- MARK: bcsqrt
+
+func nativeFn_bcsqrt(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.bcsqrt.php
+	args, err := funcParamValidator.NewValidator("bcsqrt").
+		AddParam("$num", stringType, nil).
+		AddParam("$scale", nullOrIntType, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	num, err := numberArg("bcsqrt", 1, "$num", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if num.value.Sign() < 0 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: bcsqrt(): Argument #1 ($num) must be greater than or equal to 0")
+	}
+	scale, err := scaleArg("bcsqrt", 2, args[1], context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewStr(num.sqrt(scale).String()), nil
+}
+
+// -------------------------------------- bcsub -------------------------------------- MARK: bcsub
+
+func nativeFn_bcsub(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.bcsub.php
+	num1, num2, scale, err := binaryArgs("bcsub", args, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewStr(num1.sub(num2).format(scale)), nil
+}
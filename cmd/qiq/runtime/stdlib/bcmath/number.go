@@ -0,0 +1,243 @@
+package bcmath
+
+import (
+	phpMath "QIQ/cmd/qiq/runtime/stdlib/math"
+	"math/big"
+	"strings"
+)
+
+// Arbitrary precision decimal number with the value "value / 10^scale".
+// The results of the operations are truncated (not rounded) to the requested scale like in PHP.
+type number struct {
+	value *big.Int
+	scale int
+}
+
+var (
+	bigOne = big.NewInt(1)
+	bigTwo = big.NewInt(2)
+	bigTen = big.NewInt(10)
+)
+
+func newNumber(value *big.Int, scale int) *number { return &number{value: value, scale: scale} }
+
+func newIntNumber(value int64) *number { return newNumber(big.NewInt(value), 0) }
+
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(exponent)), nil)
+}
+
+// Parse a well-formed number (e.g. "-12.340"): an optional sign, digits and optional decimal digits after a dot.
+// An empty string is zero.
+func parseNumber(str string) (*number, bool) {
+	digits := str
+	negative := false
+	if len(digits) > 0 && (digits[0] == '+' || digits[0] == '-') {
+		negative = digits[0] == '-'
+		digits = digits[1:]
+	}
+
+	integer, fraction, hasDot := strings.Cut(digits, ".")
+	if hasDot && integer == "" && fraction == "" {
+		return nil, false
+	}
+	for _, part := range []string{integer, fraction} {
+		for i := 0; i < len(part); i++ {
+			if part[i] < '0' || part[i] > '9' {
+				return nil, false
+			}
+		}
+	}
+
+	value, _ := new(big.Int).SetString("0"+integer+fraction, 10)
+	if negative {
+		value.Neg(value)
+	}
+	return newNumber(value, len(fraction)), true
+}
+
+// Format the number with exactly the given number of decimal digits. Additional digits are truncated.
+// Zero is never formatted with a minus sign (e.g. "-0.001" with scale 2 is "0.00").
+func (n *number) format(scale int) string {
+	value := n.withScale(scale).value
+	digits := new(big.Int).Abs(value).String()
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+
+	result := digits
+	if scale > 0 {
+		result = digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	}
+	if value.Sign() < 0 {
+		result = "-" + result
+	}
+	return result
+}
+
+func (n *number) String() string { return n.format(n.scale) }
+
+// Get the number with the given scale. Additional digits are truncated.
+func (n *number) withScale(scale int) *number {
+	if scale == n.scale {
+		return n
+	}
+	if scale > n.scale {
+		return newNumber(new(big.Int).Mul(n.value, pow10(scale-n.scale)), scale)
+	}
+	return newNumber(new(big.Int).Quo(n.value, pow10(n.scale-scale)), scale)
+}
+
+// Remove trailing zeros of the decimal digits but keep at least minScale digits
+func (n *number) trimZeros(minScale int) *number {
+	result := n
+	for result.scale > minScale {
+		quotient, remainder := new(big.Int).QuoRem(result.value, bigTen, new(big.Int))
+		if remainder.Sign() != 0 {
+			break
+		}
+		result = newNumber(quotient, result.scale-1)
+	}
+	return result
+}
+
+func (n *number) isZero() bool { return n.value.Sign() == 0 }
+
+// Check if the number has no decimal digits other than zeros
+func (n *number) isInteger() bool {
+	return new(big.Int).Rem(n.value, pow10(n.scale)).Sign() == 0
+}
+
+// Get the integer part of the number
+func (n *number) integer() *big.Int { return n.withScale(0).value }
+
+// -------------------------------------- Operations -------------------------------------- MARK: Operations
+
+// Bring both numbers to the larger scale
+func align(n1 *number, n2 *number) (*big.Int, *big.Int, int) {
+	scale := max(n1.scale, n2.scale)
+	return n1.withScale(scale).value, n2.withScale(scale).value, scale
+}
+
+func (n *number) add(other *number) *number {
+	v1, v2, scale := align(n, other)
+	return newNumber(new(big.Int).Add(v1, v2), scale)
+}
+
+func (n *number) sub(other *number) *number {
+	v1, v2, scale := align(n, other)
+	return newNumber(new(big.Int).Sub(v1, v2), scale)
+}
+
+func (n *number) mul(other *number) *number {
+	return newNumber(new(big.Int).Mul(n.value, other.value), n.scale+other.scale)
+}
+
+func (n *number) compare(other *number) int {
+	v1, v2, _ := align(n, other)
+	return v1.Cmp(v2)
+}
+
+// Divide the numbers with the given scale. The divisor must not be zero.
+func (n *number) div(other *number, scale int) *number {
+	// n / other = (n.value * 10^other.scale) / (other.value * 10^n.scale)
+	dividend := new(big.Int).Mul(n.value, pow10(scale+other.scale))
+	divisor := new(big.Int).Mul(other.value, pow10(n.scale))
+	return newNumber(dividend.Quo(dividend, divisor), scale)
+}
+
+// Get the truncated integer quotient and the remainder (with the sign of the dividend) like bcdivmod.
+// The divisor must not be zero.
+func (n *number) divmod(other *number) (*number, *number) {
+	quotient := n.div(other, 0)
+	return quotient, n.sub(quotient.mul(other))
+}
+
+// Raise the number to the power of an integer exponent with the given scale for negative exponents.
+// The base must not be zero for negative exponents.
+func (n *number) pow(exponent int64, scale int) *number {
+	if exponent < 0 {
+		power := n.pow(-exponent, 0)
+		return newIntNumber(1).div(power, scale)
+	}
+	return newNumber(new(big.Int).Exp(n.value, big.NewInt(exponent), nil), n.scale*int(exponent))
+}
+
+// Get the square root truncated to the given scale. The number must not be negative.
+func (n *number) sqrt(scale int) *number {
+	// sqrt(value / 10^n.scale) * 10^scale = sqrt(value * 10^(2*scale - n.scale))
+	radicand := n.withScale(2 * scale).value
+	return newNumber(new(big.Int).Sqrt(radicand), scale)
+}
+
+// Compute (n ^ exponent) mod modulus for integers. The result has the sign of the base like the modulo operator.
+func (n *number) powmod(exponent *number, modulus *number) *number {
+	base := n.integer()
+	result := new(big.Int).Exp(new(big.Int).Abs(base), exponent.integer(), new(big.Int).Abs(modulus.integer()))
+	if base.Sign() < 0 && exponent.integer().Bit(0) == 1 {
+		result.Neg(result)
+	}
+	return newNumber(result, 0)
+}
+
+func (n *number) floor() *number {
+	quotient, remainder := new(big.Int).QuoRem(n.value, pow10(n.scale), new(big.Int))
+	if remainder.Sign() < 0 {
+		quotient.Sub(quotient, bigOne)
+	}
+	return newNumber(quotient, 0)
+}
+
+func (n *number) ceil() *number {
+	quotient, remainder := new(big.Int).QuoRem(n.value, pow10(n.scale), new(big.Int))
+	if remainder.Sign() > 0 {
+		quotient.Add(quotient, bigOne)
+	}
+	return newNumber(quotient, 0)
+}
+
+// Round the number to the given number of decimal digits with one of the rounding modes of round().
+// A negative precision rounds to tens, hundreds, ... The result has the scale of the precision (at least 0).
+func (n *number) round(precision int, mode int64) *number {
+	scale := max(precision, 0)
+	if precision >= n.scale {
+		return n.withScale(scale)
+	}
+
+	divisor := pow10(n.scale - precision)
+	quotient, remainder := new(big.Int).QuoRem(n.value, divisor, new(big.Int))
+
+	// Compare the dropped digits with the half of the divisor
+	half := new(big.Int).Mul(new(big.Int).Abs(remainder), bigTwo).Cmp(divisor)
+	awayFromZero := false
+	switch mode {
+	case phpMath.RoundHalfUp:
+		awayFromZero = half >= 0
+	case phpMath.RoundHalfDown:
+		awayFromZero = half > 0
+	case phpMath.RoundHalfEven:
+		awayFromZero = half > 0 || (half == 0 && quotient.Bit(0) == 1)
+	case phpMath.RoundHalfOdd:
+		awayFromZero = half > 0 || (half == 0 && quotient.Bit(0) == 0)
+	case phpMath.RoundCeiling:
+		awayFromZero = remainder.Sign() > 0
+	case phpMath.RoundFloor:
+		awayFromZero = remainder.Sign() < 0
+	case phpMath.RoundTowardZero:
+		awayFromZero = false
+	case phpMath.RoundAwayFromZero:
+		awayFromZero = remainder.Sign() != 0
+	}
+	if awayFromZero {
+		if remainder.Sign() < 0 {
+			quotient.Sub(quotient, bigOne)
+		} else {
+			quotient.Add(quotient, bigOne)
+		}
+	}
+
+	if precision < 0 {
+		return newNumber(quotient.Mul(quotient, pow10(-precision)), 0)
+	}
+	return newNumber(quotient, precision)
+}
@@ -0,0 +1,442 @@
+package bcmath
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	phpMath "QIQ/cmd/qiq/runtime/stdlib/math"
+	"QIQ/cmd/qiq/runtime/values"
+	goMath "math"
+	"slices"
+	"strings"
+)
+
+const numberClassName = `BcMath\Number`
+
+// Number of additional decimal digits of divisions and square roots if the scale is not given
+const expandScale = 10
+
+func registerNumber(interpreter runtime.Interpreter) {
+	numParam := runtime.NewParam("$num", numberClassName, "string", "int")
+	scaleParam := runtime.NewOptionalParam("$scale", ast.NewConstantAccessExpr(0, nil, "NULL"), "int", "null")
+	numberType := []string{numberClassName}
+
+	// Spec: https://www.php.net/manual/en/class.bcmath-number.php
+	runtime.NewNativeClass(interpreter, numberClassName).
+		Final().
+		Implements("Stringable").
+		AddReadonlyProperty("$value", "public", []string{"string"}).
+		AddReadonlyProperty("$scale", "public", []string{"int"}).
+		AddMethod("__construct", []ast.FunctionParameter{runtime.NewParam("$num", "string", "int")}, nil, numberConstruct).
+		AddMethod("__toString", []ast.FunctionParameter{}, stringType, numberToString).
+		AddMethod("add", []ast.FunctionParameter{numParam, scaleParam}, numberType, numberArithmetic("add", "+")).
+		AddMethod("ceil", []ast.FunctionParameter{}, numberType, numberCeil).
+		AddMethod("compare", []ast.FunctionParameter{numParam, scaleParam}, intType, numberCompare).
+		AddMethod("div", []ast.FunctionParameter{numParam, scaleParam}, numberType, numberArithmetic("div", "/")).
+		AddMethod("divmod", []ast.FunctionParameter{numParam, scaleParam}, arrayType, numberDivmod).
+		AddMethod("floor", []ast.FunctionParameter{}, numberType, numberFloor).
+		AddMethod("mod", []ast.FunctionParameter{numParam, scaleParam}, numberType, numberArithmetic("mod", "%")).
+		AddMethod("mul", []ast.FunctionParameter{numParam, scaleParam}, numberType, numberArithmetic("mul", "*")).
+		AddMethod("pow", []ast.FunctionParameter{runtime.NewParam("$exponent", numberClassName, "string", "int"), scaleParam}, numberType, numberArithmetic("pow", "**")).
+		AddMethod("powmod", []ast.FunctionParameter{
+			runtime.NewParam("$exponent", numberClassName, "string", "int"),
+			runtime.NewParam("$modulus", numberClassName, "string", "int"),
+			scaleParam,
+		}, numberType, numberPowmod).
+		AddMethod("round", []ast.FunctionParameter{
+			runtime.NewOptionalParam("$precision", ast.NewIntegerLiteralExpr(0, nil, 0), "int"),
+			runtime.NewOptionalParam("$mode", ast.NewConstantAccessExpr(0, nil, "PHP_ROUND_HALF_UP"), "RoundingMode"),
+		}, numberType, numberRound).
+		AddMethod("sqrt", []ast.FunctionParameter{scaleParam}, numberType, numberSqrt).
+		AddMethod("sub", []ast.FunctionParameter{numParam, scaleParam}, numberType, numberArithmetic("sub", "-")).
+		OverloadOperators(numberOperator).
+		Register()
+}
+
+func getNumber(object *values.Object) *number {
+	if num, ok := object.Internal.(*number); ok {
+		return num
+	}
+	return newIntNumber(0)
+}
+
+// Set the internal number and the properties shown by var_dump
+func setNumber(object *values.Object, num *number) {
+	object.Internal = num
+	object.SetProperty("$value", values.NewStr(num.String()))
+	object.SetProperty("$scale", values.NewInt(int64(num.scale)))
+}
+
+func newNumberObject(context runtime.Context, num *number) (*values.Object, phpError.Error) {
+	classDecl, found := context.Interpreter.GetClass(numberClassName)
+	if !found {
+		return nil, phpError.NewError(`Class "%s" not found`, numberClassName)
+	}
+	object := values.NewObject(classDecl)
+	setNumber(object, num)
+	return object, nil
+}
+
+// Get the number of a BcMath\Number|string|int argument
+func numberOperandArg(functionName string, argNum int, paramName string, arg values.RuntimeValue) (*number, phpError.Error) {
+	switch arg := arg.(type) {
+	case *values.Object:
+		if strings.EqualFold(arg.Class.GetQualifiedName(), numberClassName) {
+			return getNumber(arg), nil
+		}
+	case *values.Int:
+		return newIntNumber(arg.Value), nil
+	case *values.Str:
+		return numberArg(functionName, argNum, paramName, arg)
+	}
+
+	givenType := strings.ToLower(values.ToPhpType(arg))
+	if object, isObject := arg.(*values.Object); isObject {
+		givenType = object.Class.GetQualifiedName()
+	}
+	return nil, phpError.NewError(
+		"Uncaught TypeError: %s(): Argument #%d (%s) must be of type %s|string|int, %s given", functionName, argNum, paramName, numberClassName, givenType,
+	)
+}
+
+// Get the scale of a ?int $scale argument. Nil is returned if the scale is null and the scale of the result is chosen automatically.
+func optionalScaleArg(functionName string, argNum int, arg values.RuntimeValue) (*int, phpError.Error) {
+	if arg.GetType() == values.NullValue {
+		return nil, nil
+	}
+	scale := arg.(*values.Int).Value
+	if scale < 0 || scale > goMath.MaxInt32 {
+		return nil, phpError.NewError("Uncaught ValueError: %s(): Argument #%d ($scale) must be between 0 and %d", functionName, argNum, goMath.MaxInt32)
+	}
+	result := int(scale)
+	return &result, nil
+}
+
+// Compute an arithmetic operation of BcMath\Number.
+// If no scale is given, the scale of the result is:
+//   - "+", "-", "%": the larger scale of the operands
+//   - "*": the sum of the scales of the operands
+//   - "/": the scale of the dividend + 10 without trailing zeros (but at least the scale of the dividend)
+//   - "**": the scale of the base multiplied by the exponent or like "/" for negative exponents
+//
+// The function name is used for error messages and is empty if the operation is an operator.
+func calculate(functionName string, num1 *number, operator string, num2 *number, scale *int) (*number, phpError.Error) {
+	// Compute the result with the given scale or the automatic scale and remove trailing zeros up to the min scale
+	withScale := func(result func(scale int) *number, autoScale int, minScale int) *number {
+		if scale != nil {
+			return result(*scale).withScale(*scale)
+		}
+		return result(autoScale).trimZeros(minScale)
+	}
+
+	switch operator {
+	case "+":
+		return withScale(func(int) *number { return num1.add(num2) }, max(num1.scale, num2.scale), max(num1.scale, num2.scale)), nil
+	case "-":
+		return withScale(func(int) *number { return num1.sub(num2) }, max(num1.scale, num2.scale), max(num1.scale, num2.scale)), nil
+	case "*":
+		return withScale(func(int) *number { return num1.mul(num2) }, num1.scale+num2.scale, num1.scale+num2.scale), nil
+	case "/":
+		if num2.isZero() {
+			return nil, divisionByZeroError()
+		}
+		return withScale(func(scale int) *number { return num1.div(num2, scale) }, num1.scale+expandScale, num1.scale), nil
+	case "%":
+		if num2.isZero() {
+			return nil, moduloByZeroError()
+		}
+		_, remainder := num1.divmod(num2)
+		return withScale(func(int) *number { return remainder }, max(num1.scale, num2.scale), max(num1.scale, num2.scale)), nil
+	case "**":
+		exponentError := func(reason string) phpError.Error {
+			if functionName == "" {
+				return phpError.NewError("Uncaught ValueError: exponent %s", reason)
+			}
+			return phpError.NewError("Uncaught ValueError: %s(): Argument #1 ($exponent) %s", functionName, reason)
+		}
+		if !num2.isInteger() {
+			return nil, exponentError("cannot have a fractional part")
+		}
+		if !num2.integer().IsInt64() {
+			return nil, exponentError("is too large")
+		}
+		exponent := num2.integer().Int64()
+		if exponent < 0 && num1.isZero() {
+			return nil, phpError.NewError("Uncaught DivisionByZeroError: Negative power of zero")
+		}
+		if exponent < 0 {
+			return withScale(func(scale int) *number { return num1.pow(exponent, scale) }, num1.scale+expandScale, num1.scale), nil
+		}
+		autoScale := num1.scale * int(exponent)
+		return withScale(func(int) *number { return num1.pow(exponent, 0) }, autoScale, autoScale), nil
+	default:
+		return nil, phpError.NewError(`calculate: Operator "%s" not implemented`, operator)
+	}
+}
+
+// -------------------------------------- Operators -------------------------------------- MARK: Operators
+
+// Get the number of an operand of an overloaded operator
+func operandNumber(value values.RuntimeValue) (*number, bool) {
+	switch value := value.(type) {
+	case *values.Object:
+		if strings.EqualFold(value.Class.GetQualifiedName(), numberClassName) {
+			return getNumber(value), true
+		}
+	case *values.Int:
+		return newIntNumber(value.Value), true
+	case *values.Str:
+		return parseNumber(value.Value)
+	}
+	return nil, false
+}
+
+func operandType(value values.RuntimeValue) string {
+	if object, isObject := value.(*values.Object); isObject {
+		return object.Class.GetQualifiedName()
+	}
+	return strings.ToLower(values.ToPhpType(value))
+}
+
+// Overloaded arithmetic and comparison operators. The operands can be BcMath\Number objects, integers and well-formed strings.
+func numberOperator(lhs values.RuntimeValue, operator string, rhs values.RuntimeValue, context runtime.Context) (values.RuntimeValue, bool, phpError.Error) {
+	if operator == "<=>" {
+		num1, ok1 := operandNumber(lhs)
+		num2, ok2 := operandNumber(rhs)
+		// Operands that are not numbers are uncomparable
+		if !ok1 || !ok2 {
+			return values.NewInt(1), true, nil
+		}
+		return values.NewInt(int64(num1.compare(num2))), true, nil
+	}
+
+	if !slices.Contains([]string{"+", "-", "*", "/", "%", "**"}, operator) {
+		return nil, false, nil
+	}
+
+	operands := []*number{}
+	for _, value := range []values.RuntimeValue{lhs, rhs} {
+		num, ok := operandNumber(value)
+		if !ok {
+			if value.GetType() == values.StrValue {
+				return nil, true, phpError.NewError("Uncaught ValueError: Number is not well-formed")
+			}
+			return nil, true, phpError.NewError("Uncaught TypeError: Unsupported operand types: %s %s %s", operandType(lhs), operator, operandType(rhs))
+		}
+		operands = append(operands, num)
+	}
+
+	result, err := calculate("", operands[0], operator, operands[1], nil)
+	if err != nil {
+		return nil, true, err
+	}
+	object, err := newNumberObject(context, result)
+	return object, true, err
+}
+
+// -------------------------------------- Methods -------------------------------------- MARK: Methods
+
+func numberConstruct(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	functionName := numberClassName + "::__construct"
+	args, err := funcParamValidator.NewValidator(functionName).AddParam("$num", []string{"string", "int"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	num, err := numberOperandArg(functionName, 1, "$num", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	// The readonly properties can only be initialized once
+	if _, initialized := object.Internal.(*number); initialized {
+		return values.NewVoid(), phpError.NewError("Uncaught Error: Cannot modify readonly property %s::$value", numberClassName)
+	}
+	setNumber(object, num)
+	return values.NewVoid(), nil
+}
+
+func numberToString(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator(numberClassName + "::__toString").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewStr(getNumber(object).String()), nil
+}
+
+// Validate the arguments of methods like add: a number and an optional scale
+func numberBinaryArgs(functionName string, args []values.RuntimeValue) (*number, *int, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$num", []string{"mixed"}, nil).
+		AddParam("$scale", nullOrIntType, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	num, err := numberOperandArg(functionName, 1, "$num", args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	scale, err := optionalScaleArg(functionName, 2, args[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	return num, scale, nil
+}
+
+// Methods add, sub, mul, div, mod and pow
+func numberArithmetic(methodName string, operator string) runtime.NativeMethod {
+	return func(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+		functionName := numberClassName + "::" + methodName
+		num, scale, err := numberBinaryArgs(functionName, args)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		result, err := calculate(functionName, getNumber(object), operator, num, scale)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		return newNumberObject(context, result)
+	}
+}
+
+func numberCeil(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator(numberClassName + "::ceil").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	return newNumberObject(context, getNumber(object).ceil())
+}
+
+func numberCompare(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	num, scale, err := numberBinaryArgs(numberClassName+"::compare", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	self := getNumber(object)
+	// Only the given number of decimal digits are compared
+	if scale != nil {
+		self = self.withScale(min(*scale, self.scale))
+		num = num.withScale(min(*scale, num.scale))
+	}
+	return values.NewInt(int64(self.compare(num))), nil
+}
+
+func numberDivmod(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	num, scale, err := numberBinaryArgs(numberClassName+"::divmod", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	self := getNumber(object)
+	if num.isZero() {
+		return values.NewVoid(), divisionByZeroError()
+	}
+
+	quotient, remainder := self.divmod(num)
+	if scale != nil {
+		remainder = remainder.withScale(*scale)
+	} else {
+		remainder = remainder.withScale(max(self.scale, num.scale))
+	}
+	quotientObject, err := newNumberObject(context, quotient)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	remainderObject, err := newNumberObject(context, remainder)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewArrayFromSlice([]values.RuntimeValue{quotientObject, remainderObject}), nil
+}
+
+func numberFloor(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator(numberClassName + "::floor").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	return newNumberObject(context, getNumber(object).floor())
+}
+
+func numberPowmod(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	functionName := numberClassName + "::powmod"
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$exponent", []string{"mixed"}, nil).
+		AddParam("$modulus", []string{"mixed"}, nil).
+		AddParam("$scale", nullOrIntType, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	self := getNumber(object)
+	if !self.isInteger() {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: Base number cannot have a fractional part")
+	}
+	exponent, err := numberOperandArg(functionName, 1, "$exponent", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if !exponent.isInteger() {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: %s(): Argument #1 ($exponent) cannot have a fractional part", functionName)
+	}
+	if exponent.value.Sign() < 0 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: %s(): Argument #1 ($exponent) must be greater than or equal to 0", functionName)
+	}
+	modulus, err := numberOperandArg(functionName, 2, "$modulus", args[1])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if !modulus.isInteger() {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: %s(): Argument #2 ($modulus) cannot have a fractional part", functionName)
+	}
+	if modulus.isZero() {
+		return values.NewVoid(), moduloByZeroError()
+	}
+	scale, err := optionalScaleArg(functionName, 3, args[2])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	result := self.powmod(exponent, modulus)
+	if scale != nil {
+		result = result.withScale(*scale)
+	}
+	return newNumberObject(context, result)
+}
+
+func numberRound(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	functionName := numberClassName + "::round"
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$precision", intType, values.NewInt(0)).
+		AddParam("$mode", []string{"int", "object"}, values.NewInt(phpMath.RoundHalfUp)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	mode, err := phpMath.RoundingModeArg(functionName, 2, args[1])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	self := getNumber(object)
+	precision := int(args[0].(*values.Int).Value)
+	// The scale of the number is not increased
+	return newNumberObject(context, self.round(min(precision, self.scale), mode))
+}
+
+func numberSqrt(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	functionName := numberClassName + "::sqrt"
+	args, err := funcParamValidator.NewValidator(functionName).AddParam("$scale", nullOrIntType, values.NewNull()).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	scale, err := optionalScaleArg(functionName, 1, args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	self := getNumber(object)
+	if self.value.Sign() < 0 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: Base number must be greater than or equal to 0")
+	}
+	if scale != nil {
+		return newNumberObject(context, self.sqrt(*scale))
+	}
+	return newNumberObject(context, self.sqrt(self.scale+expandScale).trimZeros(self.scale))
+}
@@ -13,6 +13,7 @@ func Register(environment runtime.Environment) {
 	// Category: Classes/Object Functions
 	environment.AddNativeFunction("class_alias", nativeFn_class_alias)
 	environment.AddNativeFunction("class_exists", nativeFn_class_exists)
+	environment.AddNativeFunction("get_called_class", nativeFn_get_called_class)
 	environment.AddNativeFunction("get_class", nativeFn_get_class)
 	environment.AddNativeFunction("get_class_methods", nativeFn_get_class_methods)
 	environment.AddNativeFunction("get_class_vars", nativeFn_get_class_vars)
@@ -69,6 +70,24 @@ func nativeFn_class_exists(args []values.RuntimeValue, context runtime.Context)
 	return values.NewBool(found), nil
 }
 
+// -------------------------------------- get_called_class -------------------------------------- MARK: get_called_class
+
+func nativeFn_get_called_class(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.get-called-class.php
+
+	_, err := funcParamValidator.NewValidator("get_called_class").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Gets the name of the class the static method is called in.
+	calledClass, found := context.Interpreter.GetCalledClass(context.Env)
+	if !found {
+		return values.NewVoid(), phpError.NewError("Uncaught Error: get_called_class() must be called from within a class in %s", context.Stmt.GetPosString())
+	}
+	return values.NewStr(calledClass.GetQualifiedName()), nil
+}
+
 // -------------------------------------- get_class -------------------------------------- MARK: get_class
 
 func nativeFn_get_class(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -395,7 +414,6 @@ func nativeFn_property_exists(args []values.RuntimeValue, context runtime.Contex
 }
 
 // TODO enum_exists
-// TODO get_called_class
 // TODO get_declared_traits
 // TODO get_mangled_object_vars
 // TODO get_object_vars
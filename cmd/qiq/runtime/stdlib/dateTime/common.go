@@ -1,6 +1,19 @@
 package dateTime
 
-import "time"
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/values"
+	"time"
+)
+
+var (
+	mixedType  = []string{"mixed"}
+	boolType   = []string{"bool"}
+	intType    = []string{"int"}
+	stringType = []string{"string"}
+)
 
 func DaysIn(m time.Month, year int) int {
 	return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
@@ -18,3 +31,33 @@ func Iso8601Weekday(weekday time.Weekday) int {
 		return int(weekday)
 	}
 }
+
+func isDigit(char byte) bool { return char >= '0' && char <= '9' }
+
+func isLetter(char byte) bool { return char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' }
+
+// Convert a string of digits into an integer
+func atoi(digits string) int {
+	result := 0
+	for i := 0; i < len(digits); i++ {
+		result = result*10 + int(digits[i]-'0')
+	}
+	return result
+}
+
+func newException(className string, format string, a ...any) phpError.Error {
+	return phpError.NewError("Uncaught "+className+": "+format, a...)
+}
+
+// Create a new object of the given (native) class without calling the constructor
+func newObject(interpreter runtime.Interpreter, className string) (*values.Object, phpError.Error) {
+	classDecl, found := interpreter.GetClass(className)
+	if !found {
+		return nil, phpError.NewError(`Class "%s" not found`, className)
+	}
+	return values.NewObject(classDecl), nil
+}
+
+func newIntLiteral(value int64) ast.IExpression { return ast.NewIntegerLiteralExpr(0, nil, value) }
+
+func newNullLiteral() ast.IExpression { return ast.NewConstantAccessExpr(0, nil, "NULL") }
@@ -7,19 +7,80 @@ import (
 	"QIQ/cmd/qiq/runtime/values"
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 	"time"
 )
 
 func Register(environment runtime.Environment) {
+	// Const Category: Date/Time Constants
+	environment.AddPredefinedConstant("DATE_ATOM", values.NewStr(`Y-m-d\TH:i:sP`))
+	environment.AddPredefinedConstant("DATE_COOKIE", values.NewStr(`l, d-M-Y H:i:s T`))
+	environment.AddPredefinedConstant("DATE_ISO8601", values.NewStr(`Y-m-d\TH:i:sO`))
+	environment.AddPredefinedConstant("DATE_ISO8601_EXPANDED", values.NewStr(`X-m-d\TH:i:sP`))
+	environment.AddPredefinedConstant("DATE_RFC822", values.NewStr(`D, d M y H:i:s O`))
+	environment.AddPredefinedConstant("DATE_RFC850", values.NewStr(`l, d-M-y H:i:s T`))
+	environment.AddPredefinedConstant("DATE_RFC1036", values.NewStr(`D, d M y H:i:s O`))
+	environment.AddPredefinedConstant("DATE_RFC1123", values.NewStr(`D, d M Y H:i:s O`))
+	environment.AddPredefinedConstant("DATE_RFC7231", values.NewStr(`D, d M Y H:i:s \G\M\T`))
+	environment.AddPredefinedConstant("DATE_RFC2822", values.NewStr(`D, d M Y H:i:s O`))
+	environment.AddPredefinedConstant("DATE_RFC3339", values.NewStr(`Y-m-d\TH:i:sP`))
+	environment.AddPredefinedConstant("DATE_RFC3339_EXTENDED", values.NewStr(`Y-m-d\TH:i:s.vP`))
+	environment.AddPredefinedConstant("DATE_RSS", values.NewStr(`D, d M Y H:i:s O`))
+	environment.AddPredefinedConstant("DATE_W3C", values.NewStr(`Y-m-d\TH:i:sP`))
+
 	// Category: Date/Time Functions
 	environment.AddNativeFunction("checkdate", nativeFn_checkdate)
 	environment.AddNativeFunction("date", nativeFn_date)
+	environment.AddNativeFunction("date_add", nativeFn_date_add)
+	environment.AddNativeFunction("date_create", nativeFn_date_create)
+	environment.AddNativeFunction("date_create_from_format", nativeFn_date_create_from_format)
+	environment.AddNativeFunction("date_create_immutable", nativeFn_date_create_immutable)
+	environment.AddNativeFunction("date_create_immutable_from_format", nativeFn_date_create_immutable_from_format)
+	environment.AddNativeFunction("date_date_set", nativeFn_date_date_set)
+	environment.AddNativeFunction("date_default_timezone_get", nativeFn_date_default_timezone_get)
+	environment.AddNativeFunction("date_default_timezone_set", nativeFn_date_default_timezone_set)
+	environment.AddNativeFunction("date_diff", nativeFn_date_diff)
+	environment.AddNativeFunction("date_format", nativeFn_date_format)
+	environment.AddNativeFunction("date_get_last_errors", nativeFn_date_get_last_errors)
+	environment.AddNativeFunction("date_interval_create_from_date_string", nativeFn_date_interval_create_from_date_string)
+	environment.AddNativeFunction("date_interval_format", nativeFn_date_interval_format)
+	environment.AddNativeFunction("date_isodate_set", nativeFn_date_isodate_set)
+	environment.AddNativeFunction("date_modify", nativeFn_date_modify)
+	environment.AddNativeFunction("date_offset_get", nativeFn_date_offset_get)
+	environment.AddNativeFunction("date_parse", nativeFn_date_parse)
+	environment.AddNativeFunction("date_parse_from_format", nativeFn_date_parse_from_format)
+	environment.AddNativeFunction("date_sub", nativeFn_date_sub)
+	environment.AddNativeFunction("date_time_set", nativeFn_date_time_set)
+	environment.AddNativeFunction("date_timestamp_get", nativeFn_date_timestamp_get)
+	environment.AddNativeFunction("date_timestamp_set", nativeFn_date_timestamp_set)
+	environment.AddNativeFunction("date_timezone_get", nativeFn_date_timezone_get)
+	environment.AddNativeFunction("date_timezone_set", nativeFn_date_timezone_set)
 	environment.AddNativeFunction("getdate", nativeFn_getdate)
+	environment.AddNativeFunction("gettimeofday", nativeFn_gettimeofday)
+	environment.AddNativeFunction("gmdate", nativeFn_gmdate)
+	environment.AddNativeFunction("gmmktime", nativeFn_gmmktime)
+	environment.AddNativeFunction("gmstrftime", nativeFn_gmstrftime)
+	environment.AddNativeFunction("idate", nativeFn_idate)
 	environment.AddNativeFunction("localtime", nativeFn_localtime)
 	environment.AddNativeFunction("microtime", nativeFn_microtime)
 	environment.AddNativeFunction("mktime", nativeFn_mktime)
+	environment.AddNativeFunction("strftime", nativeFn_strftime)
+	environment.AddNativeFunction("strtotime", nativeFn_strtotime)
 	environment.AddNativeFunction("time", nativeFn_time)
+	environment.AddNativeFunction("timezone_identifiers_list", nativeFn_timezone_identifiers_list)
+	environment.AddNativeFunction("timezone_location_get", nativeFn_timezone_location_get)
+	environment.AddNativeFunction("timezone_name_get", nativeFn_timezone_name_get)
+	environment.AddNativeFunction("timezone_offset_get", nativeFn_timezone_offset_get)
+	environment.AddNativeFunction("timezone_open", nativeFn_timezone_open)
+}
+
+func RegisterClasses(interpreter runtime.Interpreter) {
+	registerDateTimeZone(interpreter)
+	registerDateTime(interpreter, "DateTime")
+	registerDateTime(interpreter, "DateTimeImmutable")
+	registerDateInterval(interpreter)
+	registerDatePeriod(interpreter)
 }
 
 // -------------------------------------- checkdate -------------------------------------- MARK: checkdate
@@ -56,8 +117,13 @@ func nativeFn_checkdate(args []values.RuntimeValue, _ runtime.Context) (values.R
 
 // -------------------------------------- date -------------------------------------- MARK: date
 
-func nativeFn_date(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
-	args, err := funcParamValidator.NewValidator("date").
+func nativeFn_date(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date.php
+	return lib_date("date", args, getDefaultTimezone(context))
+}
+
+func lib_date(functionName string, args []values.RuntimeValue, timezone *timezone) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
 		AddParam("$format", []string{"string"}, nil).AddParam("$timestamp", []string{"int"}, values.NewNull()).
 		Validate(args)
 	if err != nil {
@@ -69,142 +135,154 @@ func nativeFn_date(args []values.RuntimeValue, _ runtime.Context) (values.Runtim
 		timestamp = time.Unix(args[1].(*values.Int).Value, 0)
 	}
 
-	format := args[0].(*values.Str).Value
+	return values.NewStr(formatDate(args[0].(*values.Str).Value, timestamp.In(timezone.location), timezone)), nil
+}
 
-	// Spec: https://www.php.net/manual/en/datetime.format.php
-	// Day
-	if strings.Contains(format, "d") {
-		// d 	Day of the month, 2 digits with leading zeros 	01 to 31
-		format = strings.ReplaceAll(format, "d", fmt.Sprintf("%02d", timestamp.Day()))
-	}
-	if strings.Contains(format, "j") {
-		// j 	Day of the month without leading zeros 	1 to 31
-		format = strings.ReplaceAll(format, "j", fmt.Sprintf("%d", timestamp.Day()))
+// -------------------------------------- date_default_timezone_get -------------------------------------- MARK: date_default_timezone_get
+
+func nativeFn_date_default_timezone_get(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	_, err := funcParamValidator.NewValidator("date_default_timezone_get").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
 	}
-	if strings.Contains(format, "z") {
-		// z 	The day of the year (starting from 0) 	0 through 365
-		format = strings.ReplaceAll(format, "z", fmt.Sprintf("%d", timestamp.YearDay()-1))
+
+	// Spec: https://www.php.net/manual/en/function.date-default-timezone-get.php
+	// In order of preference, this function returns the default timezone by:
+	// - Reading the timezone set using the date_default_timezone_set() function (if any)
+	// - Reading the value of the date.timezone ini option (if set)
+	// If none of the above succeed, date_default_timezone_get() will return a default timezone of UTC.
+	return values.NewStr(getDefaultTimezone(context).name), nil
+}
+
+// -------------------------------------- date_default_timezone_set -------------------------------------- MARK: date_default_timezone_set
+
+func nativeFn_date_default_timezone_set(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("date_default_timezone_set").AddParam("$timezoneId", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
 	}
-	if strings.Contains(format, "w") {
-		// w 	Numeric representation of the day of the week 	0 (for Sunday) through 6 (for Saturday)
-		format = strings.ReplaceAll(format, "w", fmt.Sprintf("%d", timestamp.Weekday()))
+
+	// Spec: https://www.php.net/manual/en/function.date-default-timezone-set.php
+	// Returns false if the timezoneId isn't valid, or true otherwise.
+	timezoneId := args[0].(*values.Str).Value
+	timezone, found := loadTimezoneIdentifier(timezoneId)
+	if !found {
+		context.Interpreter.PrintError(phpError.NewNotice("date_default_timezone_set(): Timezone ID '%s' is invalid%s", timezoneId, context.InPosition()))
+		return values.NewBool(false), nil
 	}
-	if strings.Contains(format, "N") {
-		// N 	ISO 8601 numeric representation of the day of the week 	1 (for Monday) through 7 (for Sunday)
-		format = strings.ReplaceAll(format, "N", fmt.Sprintf("%d", Iso8601Weekday(timestamp.Weekday())))
+	context.Interpreter.GetExectionContext().SetDefaultTimezone(timezone.name)
+	return values.NewBool(true), nil
+}
+
+// -------------------------------------- date_parse -------------------------------------- MARK: date_parse
+
+func nativeFn_date_parse(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("date_parse").AddParam("$datetime", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
 	}
 
-	// Spec: https://www.php.net/manual/en/datetime.format.php
-	// Week
-	if strings.Contains(format, "W") {
-		// W 	ISO 8601 week number of year, weeks starting on Monday 	Example: 42 (the 42nd week in the year)
-		_, week := timestamp.ISOWeek()
-		format = strings.ReplaceAll(format, "W", fmt.Sprintf("%d", week))
+	// Spec: https://www.php.net/manual/en/function.date-parse.php
+	return parsedTimeToArray(parseTime(args[0].(*values.Str).Value)), nil
+}
+
+// -------------------------------------- date_parse_from_format -------------------------------------- MARK: date_parse_from_format
+
+func nativeFn_date_parse_from_format(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("date_parse_from_format").
+		AddParam("$format", []string{"string"}, nil).AddParam("$datetime", []string{"string"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
 	}
 
-	// Spec: https://www.php.net/manual/en/datetime.format.php
-	// Month
-	if strings.Contains(format, "m") {
-		// m 	Numeric representation of a month, with leading zeros 	01 through 12
-		format = strings.ReplaceAll(format, "m", fmt.Sprintf("%02d", timestamp.Month()))
+	// Spec: https://www.php.net/manual/en/function.date-parse-from-format.php
+	parsed, _ := parseFromFormat(args[0].(*values.Str).Value, args[1].(*values.Str).Value)
+	return parsedTimeToArray(parsed), nil
+}
+
+// Convert the parsed fields, errors, timezone and relative offset into the array returned by date_parse()
+func parsedTimeToArray(parsed *parsedTime) *values.Array {
+	field := func(value int) values.RuntimeValue {
+		if value == unset {
+			return values.NewBool(false)
+		}
+		return values.NewInt(int64(value))
 	}
-	if strings.Contains(format, "n") {
-		// n 	Numeric representation of a month, without leading zeros 	1 through 12
-		format = strings.ReplaceAll(format, "n", fmt.Sprintf("%d", timestamp.Month()))
+	messages := func(list []parseMessage) *values.Array {
+		array := values.NewArray()
+		for _, message := range list {
+			array.SetElement(values.NewInt(int64(message.position)), values.NewStr(message.message))
+		}
+		return array
 	}
-	if strings.Contains(format, "t") {
-		// t 	Number of days in the given month 	28 through 31
-		format = strings.ReplaceAll(format, "t", fmt.Sprintf("%d", DaysIn(timestamp.Month(), timestamp.Year())))
+
+	array := values.NewArray()
+	array.SetElement(values.NewStr("year"), field(parsed.year))
+	array.SetElement(values.NewStr("month"), field(parsed.month))
+	array.SetElement(values.NewStr("day"), field(parsed.day))
+	array.SetElement(values.NewStr("hour"), field(parsed.hour))
+	array.SetElement(values.NewStr("minute"), field(parsed.minute))
+	array.SetElement(values.NewStr("second"), field(parsed.second))
+	if parsed.microsecond == unset {
+		array.SetElement(values.NewStr("fraction"), values.NewBool(false))
+	} else {
+		array.SetElement(values.NewStr("fraction"), values.NewFloat(float64(parsed.microsecond)/1000000))
+	}
+	array.SetElement(values.NewStr("warning_count"), values.NewInt(int64(len(parsed.warnings))))
+	array.SetElement(values.NewStr("warnings"), messages(parsed.warnings))
+	array.SetElement(values.NewStr("error_count"), values.NewInt(int64(len(parsed.errors))))
+	array.SetElement(values.NewStr("errors"), messages(parsed.errors))
+
+	array.SetElement(values.NewStr("is_localtime"), values.NewBool(parsed.timezone != nil))
+	if timezone := parsed.timezone; timezone != nil {
+		array.SetElement(values.NewStr("zone_type"), values.NewInt(int64(timezone.kind)))
+		switch timezone.kind {
+		case timezoneTypeOffset, timezoneTypeAbbreviation:
+			_, offset := time.Time{}.In(timezone.location).Zone()
+			array.SetElement(values.NewStr("zone"), values.NewInt(int64(offset)))
+			array.SetElement(values.NewStr("is_dst"), values.NewBool(timezone.isDst))
+			if timezone.kind == timezoneTypeAbbreviation {
+				array.SetElement(values.NewStr("tz_abbr"), values.NewStr(timezone.name))
+			}
+		case timezoneTypeIdentifier:
+			// "UTC" is found as abbreviation and as identifier
+			if timezone.name == "UTC" {
+				array.SetElement(values.NewStr("tz_abbr"), values.NewStr(timezone.name))
+			}
+			array.SetElement(values.NewStr("tz_id"), values.NewStr(timezone.name))
+		}
 	}
 
-	// Spec: https://www.php.net/manual/en/datetime.format.php
-	// Year
-	if strings.Contains(format, "L") {
-		// L 	Whether it's a leap year 	1 if it is a leap year, 0 otherwise.
-		leap := "0"
-		if IsLeapYear(timestamp.Year()) {
-			leap = "1"
+	if parsed.haveRelative {
+		relative := values.NewArray()
+		relative.SetElement(values.NewStr("year"), values.NewInt(int64(parsed.relative.year)))
+		relative.SetElement(values.NewStr("month"), values.NewInt(int64(parsed.relative.month)))
+		relative.SetElement(values.NewStr("day"), values.NewInt(int64(parsed.relative.day)))
+		relative.SetElement(values.NewStr("hour"), values.NewInt(int64(parsed.relative.hour)))
+		relative.SetElement(values.NewStr("minute"), values.NewInt(int64(parsed.relative.minute)))
+		relative.SetElement(values.NewStr("second"), values.NewInt(int64(parsed.relative.second)))
+		if parsed.relative.haveWeekday {
+			relative.SetElement(values.NewStr("weekday"), values.NewInt(int64(parsed.relative.weekday)))
+		}
+		if parsed.relative.haveWeekdays {
+			relative.SetElement(values.NewStr("weekdays"), values.NewInt(int64(parsed.relative.weekdays)))
 		}
-		format = strings.ReplaceAll(format, "L", leap)
-	}
-	if strings.Contains(format, "Y") {
-		// Y 	A full numeric representation of a year, at least 4 digits, with - for years BCE. 	Examples: -0055, 0787, 1999, 2003, 10191
-		format = strings.ReplaceAll(format, "Y", fmt.Sprintf("%d", timestamp.Year()))
-	}
-	if strings.Contains(format, "y") {
-		// y 	A two digit representation of a year 	Examples: 99 or 03
-		format = strings.ReplaceAll(format, "y", fmt.Sprintf("%02d", timestamp.Year()%100))
-	}
-
-	// Spec: https://www.php.net/manual/en/datetime.format.php
-	// Time
-	if strings.Contains(format, "i") {
-		// i 	Minutes with leading zeros 	00 to 59
-		format = strings.ReplaceAll(format, "i", fmt.Sprintf("%02d", timestamp.Minute()))
-	}
-	if strings.Contains(format, "s") {
-		// s 	Seconds with leading zeros 	00 through 59
-		format = strings.ReplaceAll(format, "s", fmt.Sprintf("%02d", timestamp.Second()))
-	}
-	if strings.Contains(format, "G") {
-		// G 	24-hour format of an hour without leading zeros 	0 through 23
-		format = strings.ReplaceAll(format, "G", fmt.Sprintf("%d", timestamp.Hour()))
-	}
-	if strings.Contains(format, "g") {
-		// g 	12-hour format of an hour without leading zeros 	1 through 12
-		format = strings.ReplaceAll(format, "g", timestamp.Format("3"))
-	}
-	if strings.Contains(format, "h") {
-		// h 	12-hour format of an hour with leading zeros 	01 through 12
-		format = strings.ReplaceAll(format, "h", timestamp.Format("03"))
-	}
-	if strings.Contains(format, "H") {
-		// H 	24-hour format of an hour with leading zeros 	00 through 23
-		format = strings.ReplaceAll(format, "H", fmt.Sprintf("%02d", timestamp.Hour()))
-	}
-
-	return values.NewStr(format), nil
-
-	// TODO date() missing formats
-	/*
-		Day 	--- 	---
-		D 	A textual representation of a day, three letters 	Mon through Sun
-		l (lowercase 'L') 	A full textual representation of the day of the week 	Sunday through Saturday
-		S 	English ordinal suffix for the day of the month, 2 characters 	st, nd, rd or th. Works well with j
-
-		Month 	--- 	---
-		F 	A full textual representation of a month, such as January or March 	January through December
-		M 	A short textual representation of a month, three letters 	Jan through Dec
-
-		Year 	--- 	---
-		o 	ISO 8601 week-numbering year. This has the same value as Y, except that if the ISO week number (W) belongs to the previous or next year, that year is used instead. 	Examples: 1999 or 2003
-		X 	An expanded full numeric representation of a year, at least 4 digits, with - for years BCE, and + for years CE. 	Examples: -0055, +0787, +1999, +10191
-		x 	An expanded full numeric representation if required, or a standard full numeral representation if possible (like Y). At least four digits. Years BCE are prefixed with a -. Years beyond (and including) 10000 are prefixed by a +. 	Examples: -0055, 0787, 1999, +10191
-
-		Time 	--- 	---
-		a 	Lowercase Ante meridiem and Post meridiem 	am or pm
-		A 	Uppercase Ante meridiem and Post meridiem 	AM or PM
-		B 	Swatch Internet time 	000 through 999
-		u 	Microseconds. Note that date() will always generate 000000 since it takes an int parameter, whereas DateTimeInterface::format() does support microseconds if an object of type DateTimeInterface was created with microseconds. 	Example: 654321
-		v 	Milliseconds. Same note applies as for u. 	Example: 654
-		Timezone 	--- 	---
-		e 	Timezone identifier 	Examples: UTC, GMT, Atlantic/Azores
-		I (capital i) 	Whether or not the date is in daylight saving time 	1 if Daylight Saving Time, 0 otherwise.
-		O 	Difference to Greenwich time (GMT) without colon between hours and minutes 	Example: +0200
-		P 	Difference to Greenwich time (GMT) with colon between hours and minutes 	Example: +02:00
-		p 	The same as P, but returns Z instead of +00:00 (available as of PHP 8.0.0) 	Examples: Z or +02:00
-		T 	Timezone abbreviation, if known; otherwise the GMT offset. 	Examples: EST, MDT, +05
-		Z 	Timezone offset in seconds. The offset for timezones west of UTC is always negative, and for those east of UTC is always positive. 	-43200 through 50400
-		Full Date/Time 	--- 	---
-		c 	ISO 8601 date 	2004-02-12T15:19:21+00:00
-		r 	» RFC 2822/» RFC 5322 formatted date 	Example: Thu, 21 Dec 2000 16:01:07 +0200
-		U 	Seconds since the Unix Epoch (January 1 1970 00:00:00 GMT) 	See also time()
-	*/
+		switch parsed.relative.dayOfMonth {
+		case firstDayOfMonth:
+			relative.SetElement(values.NewStr("first_day_of_month"), values.NewBool(true))
+		case lastDayOfMonth:
+			relative.SetElement(values.NewStr("last_day_of_month"), values.NewBool(true))
+		}
+		array.SetElement(values.NewStr("relative"), relative)
+	}
+
+	return array
 }
 
 // -------------------------------------- getdate -------------------------------------- MARK: getdate
 
-func nativeFn_getdate(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+func nativeFn_getdate(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
 	args, err := funcParamValidator.NewValidator("getdate").AddParam("$timestamp", []string{"int"}, values.NewNull()).Validate(args)
 	if err != nil {
 		return values.NewVoid(), err
@@ -217,26 +295,111 @@ func nativeFn_getdate(args []values.RuntimeValue, _ runtime.Context) (values.Run
 		args[0] = lib_time()
 	}
 
-	timestamp := time.Unix(args[0].(*values.Int).Value, 0)
+	timestamp := time.Unix(args[0].(*values.Int).Value, 0).In(getDefaultTimezone(context).location)
 	array := values.NewArray()
-	array.SetElement(values.NewStr("seconds"), values.NewInt(int64(timestamp.UTC().Second())))
-	array.SetElement(values.NewStr("minutes"), values.NewInt(int64(timestamp.UTC().Minute())))
-	array.SetElement(values.NewStr("hours"), values.NewInt(int64(timestamp.UTC().Hour())))
-	array.SetElement(values.NewStr("mday"), values.NewInt(int64(timestamp.UTC().Day())))
-	array.SetElement(values.NewStr("wday"), values.NewInt(int64(timestamp.UTC().Weekday())))
-	array.SetElement(values.NewStr("mon"), values.NewInt(int64(timestamp.UTC().Month())))
-	array.SetElement(values.NewStr("year"), values.NewInt(int64(timestamp.UTC().Year())))
-	array.SetElement(values.NewStr("yday"), values.NewInt(int64(timestamp.UTC().YearDay()-1)))
-	array.SetElement(values.NewStr("weekday"), values.NewStr(timestamp.UTC().Weekday().String()))
-	array.SetElement(values.NewStr("month"), values.NewStr(timestamp.UTC().Month().String()))
-	array.SetElement(nil, values.NewInt(timestamp.UTC().Unix()))
+	array.SetElement(values.NewStr("seconds"), values.NewInt(int64(timestamp.Second())))
+	array.SetElement(values.NewStr("minutes"), values.NewInt(int64(timestamp.Minute())))
+	array.SetElement(values.NewStr("hours"), values.NewInt(int64(timestamp.Hour())))
+	array.SetElement(values.NewStr("mday"), values.NewInt(int64(timestamp.Day())))
+	array.SetElement(values.NewStr("wday"), values.NewInt(int64(timestamp.Weekday())))
+	array.SetElement(values.NewStr("mon"), values.NewInt(int64(timestamp.Month())))
+	array.SetElement(values.NewStr("year"), values.NewInt(int64(timestamp.Year())))
+	array.SetElement(values.NewStr("yday"), values.NewInt(int64(timestamp.YearDay()-1)))
+	array.SetElement(values.NewStr("weekday"), values.NewStr(timestamp.Weekday().String()))
+	array.SetElement(values.NewStr("month"), values.NewStr(timestamp.Month().String()))
+	array.SetElement(nil, values.NewInt(timestamp.Unix()))
 
 	return array, nil
 }
 
+// -------------------------------------- gettimeofday -------------------------------------- MARK: gettimeofday
+
+func nativeFn_gettimeofday(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("gettimeofday").AddParam("$as_float", []string{"bool"}, values.NewBool(false)).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.gettimeofday.php
+	now := time.Now()
+
+	// When set to true, a float instead of an array is returned.
+	if args[0].(*values.Bool).Value {
+		return values.NewFloat(float64(now.UnixMicro()) / math.Pow(10, 6)), nil
+	}
+
+	// By default an array is returned with the keys "sec", "usec", "minuteswest" and "dsttime"
+	timezone := getDefaultTimezone(context)
+	_, offset := now.In(timezone.location).Zone()
+	var dstTime int64
+	if timezone.isDstAt(now) {
+		dstTime = 1
+	}
+	array := values.NewArray()
+	array.SetElement(values.NewStr("sec"), values.NewInt(now.Unix()))
+	array.SetElement(values.NewStr("usec"), values.NewInt(int64(now.Nanosecond()/1000)))
+	array.SetElement(values.NewStr("minuteswest"), values.NewInt(int64(-offset/60)))
+	array.SetElement(values.NewStr("dsttime"), values.NewInt(dstTime))
+	return array, nil
+}
+
+// -------------------------------------- gmdate -------------------------------------- MARK: gmdate
+
+func nativeFn_gmdate(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.gmdate.php
+	// Identical to the date() function except that the time returned is Greenwich Mean Time (GMT).
+	return lib_date("gmdate", args, gmtTimezone)
+}
+
+// -------------------------------------- gmmktime -------------------------------------- MARK: gmmktime
+
+func nativeFn_gmmktime(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.gmmktime.php
+	// Identical to mktime() except the passed parameters represents a GMT date.
+	return lib_mktime("gmmktime", args, time.UTC)
+}
+
+// -------------------------------------- gmstrftime -------------------------------------- MARK: gmstrftime
+
+func nativeFn_gmstrftime(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.gmstrftime.php
+	// Behaves the same as strftime() except that the time returned is Greenwich Mean Time (GMT).
+	return lib_strftime("gmstrftime", args, gmtTimezone, context)
+}
+
+// -------------------------------------- idate -------------------------------------- MARK: idate
+
+func nativeFn_idate(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("idate").
+		AddParam("$format", []string{"string"}, nil).AddParam("$timestamp", []string{"int"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.idate.php
+	format := args[0].(*values.Str).Value
+	if len(format) != 1 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: idate(): Argument #1 ($format) must be one character")
+	}
+	if !strings.Contains("BdhHiILmNostUwWyYzZ", format) {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: idate(): Argument #1 ($format) must be a valid date format character")
+	}
+
+	timezone := getDefaultTimezone(context)
+	timestamp := time.Now()
+	if args[1].GetType() != values.NullValue {
+		timestamp = time.Unix(args[1].(*values.Int).Value, 0)
+	}
+
+	// The characters share their meaning with date(), but the result is an integer (e.g. "y" of 2005 is 5)
+	result, _ := strconv.ParseInt(formatDate(format, timestamp.In(timezone.location), timezone), 10, 64)
+	return values.NewInt(result), nil
+}
+
 // -------------------------------------- localtime -------------------------------------- MARK: localtime
 
-func nativeFn_localtime(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+func nativeFn_localtime(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
 	args, err := funcParamValidator.NewValidator("localtime").
 		AddParam("$timestamp", []string{"int"}, values.NewNull()).
 		AddParam("associative", []string{"bool"}, values.NewBool(false)).
@@ -252,35 +415,35 @@ func nativeFn_localtime(args []values.RuntimeValue, _ runtime.Context) (values.R
 		args[0] = lib_time()
 	}
 
-	timestamp := time.Unix(args[0].(*values.Int).Value, 0)
+	timestamp := time.Unix(args[0].(*values.Int).Value, 0).In(getDefaultTimezone(context).location)
 	array := values.NewArray()
 	var isDst int64
-	if timestamp.Local().IsDST() {
+	if timestamp.IsDST() {
 		isDst = 1
 	}
-	year := int64(timestamp.Local().Year()) - 1900
+	year := int64(timestamp.Year()) - 1900
 
 	if args[1].(*values.Bool).Value {
 		// Associative array
-		array.SetElement(values.NewStr("tm_sec"), values.NewInt(int64(timestamp.Local().Second())))
-		array.SetElement(values.NewStr("tm_min"), values.NewInt(int64(timestamp.Local().Minute())))
-		array.SetElement(values.NewStr("tm_hour"), values.NewInt(int64(timestamp.Local().Hour())))
-		array.SetElement(values.NewStr("tm_mday"), values.NewInt(int64(timestamp.Local().Day())))
-		array.SetElement(values.NewStr("tm_mon"), values.NewInt(int64(timestamp.Local().Month())))
+		array.SetElement(values.NewStr("tm_sec"), values.NewInt(int64(timestamp.Second())))
+		array.SetElement(values.NewStr("tm_min"), values.NewInt(int64(timestamp.Minute())))
+		array.SetElement(values.NewStr("tm_hour"), values.NewInt(int64(timestamp.Hour())))
+		array.SetElement(values.NewStr("tm_mday"), values.NewInt(int64(timestamp.Day())))
+		array.SetElement(values.NewStr("tm_mon"), values.NewInt(int64(timestamp.Month())-1))
 		array.SetElement(values.NewStr("tm_year"), values.NewInt(year))
-		array.SetElement(values.NewStr("tm_wday"), values.NewInt(int64(timestamp.Local().Weekday())))
-		array.SetElement(values.NewStr("tm_yday"), values.NewInt(int64(timestamp.Local().YearDay()-1)))
+		array.SetElement(values.NewStr("tm_wday"), values.NewInt(int64(timestamp.Weekday())))
+		array.SetElement(values.NewStr("tm_yday"), values.NewInt(int64(timestamp.YearDay()-1)))
 		array.SetElement(values.NewStr("tm_isdst"), values.NewInt(isDst))
 	} else {
 		//Numerically index array
-		array.SetElement(nil, values.NewInt(int64(timestamp.Local().Second())))
-		array.SetElement(nil, values.NewInt(int64(timestamp.Local().Minute())))
-		array.SetElement(nil, values.NewInt(int64(timestamp.Local().Hour())))
-		array.SetElement(nil, values.NewInt(int64(timestamp.Local().Day())))
-		array.SetElement(nil, values.NewInt(int64(timestamp.Local().Month())))
+		array.SetElement(nil, values.NewInt(int64(timestamp.Second())))
+		array.SetElement(nil, values.NewInt(int64(timestamp.Minute())))
+		array.SetElement(nil, values.NewInt(int64(timestamp.Hour())))
+		array.SetElement(nil, values.NewInt(int64(timestamp.Day())))
+		array.SetElement(nil, values.NewInt(int64(timestamp.Month())-1))
 		array.SetElement(nil, values.NewInt(year))
-		array.SetElement(nil, values.NewInt(int64(timestamp.Local().Weekday())))
-		array.SetElement(nil, values.NewInt(int64(timestamp.Local().YearDay()-1)))
+		array.SetElement(nil, values.NewInt(int64(timestamp.Weekday())))
+		array.SetElement(nil, values.NewInt(int64(timestamp.YearDay()-1)))
 		array.SetElement(nil, values.NewInt(isDst))
 	}
 
@@ -310,8 +473,13 @@ func nativeFn_microtime(args []values.RuntimeValue, _ runtime.Context) (values.R
 
 // -------------------------------------- mktime -------------------------------------- MARK: mktime
 
-func nativeFn_mktime(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
-	args, err := funcParamValidator.NewValidator("mktime").
+func nativeFn_mktime(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.mktime.php
+	return lib_mktime("mktime", args, getDefaultTimezone(context).location)
+}
+
+func lib_mktime(functionName string, args []values.RuntimeValue, location *time.Location) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
 		AddParam("$hour", []string{"int"}, nil).
 		AddParam("$minute", []string{"int"}, values.NewNull()).
 		AddParam("$second", []string{"int"}, values.NewNull()).
@@ -323,9 +491,7 @@ func nativeFn_mktime(args []values.RuntimeValue, _ runtime.Context) (values.Runt
 		return values.NewVoid(), err
 	}
 
-	// Spec: https://www.php.net/manual/en/function.mktime.php
-
-	now := time.Now().Local()
+	now := time.Now().In(location)
 
 	hour := int(args[0].(*values.Int).Value)
 
@@ -360,8 +526,66 @@ func nativeFn_mktime(args []values.RuntimeValue, _ runtime.Context) (values.Runt
 		year = 1900 + year
 	}
 
-	timestamp := time.Date(year, month, day, hour, minute, second, 0, time.Local)
+	timestamp := time.Date(year, month, day, hour, minute, second, 0, location)
+
+	return values.NewInt(timestamp.Unix()), nil
+}
+
+// -------------------------------------- strftime -------------------------------------- MARK: strftime
+
+func nativeFn_strftime(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strftime.php
+	return lib_strftime("strftime", args, getDefaultTimezone(context), context)
+}
+
+func lib_strftime(functionName string, args []values.RuntimeValue, timezone *timezone, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$format", []string{"string"}, nil).AddParam("$timestamp", []string{"int"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.strftime.php
+	// This function has been DEPRECATED as of PHP 8.1.0.
+	context.Interpreter.PrintError(phpError.NewDeprecatedError(
+		"Function %s() is deprecated since 8.1, use IntlDateFormatter::format() instead%s", functionName, context.InPosition(),
+	))
+
+	format := args[0].(*values.Str).Value
+	if format == "" {
+		return values.NewBool(false), nil
+	}
 
+	timestamp := time.Now()
+	if args[1].GetType() != values.NullValue {
+		timestamp = time.Unix(args[1].(*values.Int).Value, 0)
+	}
+
+	return values.NewStr(formatStrftime(format, timestamp.In(timezone.location), timezone)), nil
+}
+
+// -------------------------------------- strtotime -------------------------------------- MARK: strtotime
+
+func nativeFn_strtotime(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("strtotime").
+		AddParam("$datetime", []string{"string"}, nil).AddParam("$baseTimestamp", []string{"int"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.strtotime.php
+	parsed := parseTime(args[0].(*values.Str).Value)
+	if len(parsed.errors) > 0 {
+		return values.NewBool(false), nil
+	}
+
+	base := time.Now()
+	if args[1].GetType() != values.NullValue {
+		base = time.Unix(args[1].(*values.Int).Value, 0)
+	}
+	timestamp, _ := parsed.resolve(base, getDefaultTimezone(context))
 	return values.NewInt(timestamp.Unix()), nil
 }
 
@@ -381,47 +605,11 @@ func lib_time() *values.Int {
 	return values.NewInt(time.Now().UTC().Unix())
 }
 
-// TODO date_add
-// TODO date_create
-// TODO date_create_from_format
-// TODO date_create_immutable
-// TODO date_create_immutable_from_format
-// TODO date_date_set
-// TODO date_default_timezone_get
-// TODO date_default_timezone_set
-// TODO date_diff
-// TODO date_format
-// TODO date_get_last_errors
-// TODO date_interval_create_from_date_string
-// TODO date_interval_format
-// TODO date_isodate_set
-// TODO date_modify
-// TODO date_offset_get
-// TODO date_parse
-// TODO date_parse_from_format
-// TODO date_sub
 // TODO date_sun_info
 // TODO date_sunrise
 // TODO date_sunset
-// TODO date_time_set
-// TODO date_timestamp_get
-// TODO date_timestamp_set
-// TODO date_timezone_get
-// TODO date_timezone_set
-// TODO gettimeofday
-// TODO gmdate
-// TODO gmmktime
-// TODO gmstrftime
-// TODO idate
-// TODO strftime - DEPRECATED
 // TODO strptime - DEPRECATED
-// TODO strtotime
 // TODO timezone_abbreviations_list
-// TODO timezone_identifiers_list
-// TODO timezone_location_get
 // TODO timezone_name_from_abbr
-// TODO timezone_name_get
-// TODO timezone_offset_get
-// TODO timezone_open
 // TODO timezone_transitions_get
 // TODO timezone_version_get
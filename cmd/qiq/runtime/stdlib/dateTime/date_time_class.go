@@ -0,0 +1,708 @@
+package dateTime
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+	"time"
+)
+
+// Internal state of DateTime and DateTimeImmutable.
+// The state is never modified: Changing a DateTime replaces its state.
+type dateTime struct {
+	time     time.Time
+	timezone *timezone
+}
+
+func getDateTime(object *values.Object) (*dateTime, phpError.Error) {
+	date, ok := object.Internal.(*dateTime)
+	if !ok {
+		return nil, phpError.NewError("Uncaught Error: The DateTimeInterface object has not been correctly initialized by its constructor")
+	}
+	return date, nil
+}
+
+// Set the internal state and the properties shown by var_dump
+func setDateTime(object *values.Object, date *dateTime) {
+	object.Internal = date
+	object.SetProperty("$date", values.NewStr(formatDate("Y-m-d H:i:s.u", date.time, date.timezone)))
+	object.SetProperty("$timezone_type", values.NewInt(int64(date.timezone.kind)))
+	object.SetProperty("$timezone", values.NewStr(date.timezone.name))
+}
+
+func newDateTimeObject(context runtime.Context, className string, date *dateTime) (*values.Object, phpError.Error) {
+	object, err := newObject(context.Interpreter, className)
+	if err != nil {
+		return nil, err
+	}
+	setDateTime(object, date)
+	return object, nil
+}
+
+func isImmutable(object *values.Object, context runtime.Context) bool {
+	return context.Interpreter.GetExectionContext().IsInstanceOf(object.Class, "DateTimeImmutable")
+}
+
+// Get the name of the native class for messages
+func dateTimeClassName(object *values.Object, context runtime.Context) string {
+	if isImmutable(object, context) {
+		return "DateTimeImmutable"
+	}
+	return "DateTime"
+}
+
+// Apply the new state: DateTime is modified and DateTimeImmutable returns a modified copy
+func modifyDateTime(object *values.Object, date *dateTime, context runtime.Context) *values.Object {
+	if isImmutable(object, context) {
+		copy := values.NewObject(object.Class)
+		for _, name := range object.PropertyNames {
+			if value, found := object.GetProperty(name); found {
+				copy.SetProperty(name, value)
+			}
+		}
+		object = copy
+	}
+	setDateTime(object, date)
+	return object
+}
+
+// Parse the date/time string relative to the current time.
+// The result is nil if the string could not be parsed.
+func parseDateTime(input string, timezone *timezone, context runtime.Context) (*dateTime, *parsedTime) {
+	// An empty string is the current time
+	if input == "" {
+		input = "now"
+	}
+	parsed := parseTime(input)
+	setLastErrors(context, parsed.warnings, parsed.errors)
+	if len(parsed.errors) > 0 {
+		return nil, parsed
+	}
+	resolvedTime, resolvedTimezone := parsed.resolve(time.Now(), timezone)
+	return &dateTime{time: resolvedTime, timezone: resolvedTimezone}, parsed
+}
+
+// Store the warnings and errors returned by DateTime::getLastErrors()
+func setLastErrors(context runtime.Context, warnings []parseMessage, errors []parseMessage) {
+	if len(warnings) == 0 && len(errors) == 0 {
+		context.Interpreter.GetExectionContext().SetDateLastErrors(nil)
+		return
+	}
+	messages := func(list []parseMessage) *values.Array {
+		array := values.NewArray()
+		for _, message := range list {
+			array.SetElement(values.NewInt(int64(message.position)), values.NewStr(message.message))
+		}
+		return array
+	}
+	lastErrors := values.NewArray()
+	lastErrors.SetElement(values.NewStr("warning_count"), values.NewInt(int64(len(warnings))))
+	lastErrors.SetElement(values.NewStr("warnings"), messages(warnings))
+	lastErrors.SetElement(values.NewStr("error_count"), values.NewInt(int64(len(errors))))
+	lastErrors.SetElement(values.NewStr("errors"), messages(errors))
+	context.Interpreter.GetExectionContext().SetDateLastErrors(lastErrors)
+}
+
+func registerDateTime(interpreter runtime.Interpreter, className string) {
+	// Spec: https://www.php.net/manual/en/class.datetime.php
+	// Spec: https://www.php.net/manual/en/class.datetimeimmutable.php
+	class := runtime.NewNativeClass(interpreter, className).
+		Implements("DateTimeInterface").
+		AddMethod("__construct", []ast.FunctionParameter{
+			runtime.NewOptionalParam("$datetime", ast.NewStringLiteralExpr(0, nil, "now", ast.SingleQuotedString), "string"),
+			runtime.NewOptionalParam("$timezone", newNullLiteral(), "DateTimeZone", "null"),
+		}, nil, dateTimeConstruct).
+		AddMethod("add", []ast.FunctionParameter{runtime.NewParam("$interval", "DateInterval")}, []string{className}, dateTimeAdd).
+		AddMethod("diff", []ast.FunctionParameter{
+			runtime.NewParam("$targetObject", "DateTimeInterface"), runtime.NewOptionalParam("$absolute", ast.NewBooleanLiteralExpr(0, nil, false), "bool"),
+		}, []string{"DateInterval"}, dateTimeDiff).
+		AddMethod("format", []ast.FunctionParameter{runtime.NewParam("$format", "string")}, stringType, dateTimeFormat).
+		AddMethod("getMicrosecond", []ast.FunctionParameter{}, intType, dateTimeGetMicrosecond).
+		AddMethod("getOffset", []ast.FunctionParameter{}, intType, dateTimeGetOffset).
+		AddMethod("getTimestamp", []ast.FunctionParameter{}, intType, dateTimeGetTimestamp).
+		AddMethod("getTimezone", []ast.FunctionParameter{}, []string{"DateTimeZone", "false"}, dateTimeGetTimezone).
+		AddMethod("modify", []ast.FunctionParameter{runtime.NewParam("$modifier", "string")}, []string{className, "false"}, dateTimeModify).
+		AddMethod("setDate", []ast.FunctionParameter{
+			runtime.NewParam("$year", "int"), runtime.NewParam("$month", "int"), runtime.NewParam("$day", "int"),
+		}, []string{className}, dateTimeSetDate).
+		AddMethod("setISODate", []ast.FunctionParameter{
+			runtime.NewParam("$year", "int"), runtime.NewParam("$week", "int"), runtime.NewOptionalParam("$dayOfWeek", newIntLiteral(1), "int"),
+		}, []string{className}, dateTimeSetISODate).
+		AddMethod("setMicrosecond", []ast.FunctionParameter{runtime.NewParam("$microsecond", "int")}, []string{className}, dateTimeSetMicrosecond).
+		AddMethod("setTime", []ast.FunctionParameter{
+			runtime.NewParam("$hour", "int"), runtime.NewParam("$minute", "int"),
+			runtime.NewOptionalParam("$second", newIntLiteral(0), "int"), runtime.NewOptionalParam("$microsecond", newIntLiteral(0), "int"),
+		}, []string{className}, dateTimeSetTime).
+		AddMethod("setTimestamp", []ast.FunctionParameter{runtime.NewParam("$timestamp", "int")}, []string{className}, dateTimeSetTimestamp).
+		AddMethod("setTimezone", []ast.FunctionParameter{runtime.NewParam("$timezone", "DateTimeZone")}, []string{className}, dateTimeSetTimezone).
+		AddMethod("sub", []ast.FunctionParameter{runtime.NewParam("$interval", "DateInterval")}, []string{className}, dateTimeSub).
+		AddStaticMethod("getLastErrors", []ast.FunctionParameter{}, []string{"array", "false"}, dateTimeGetLastErrors).
+		AddStaticMethod("createFromFormat", []ast.FunctionParameter{
+			runtime.NewParam("$format", "string"), runtime.NewParam("$datetime", "string"),
+			runtime.NewOptionalParam("$timezone", newNullLiteral(), "DateTimeZone", "null"),
+		}, []string{className, "false"}, staticDateTimeMethod(className, dateTimeCreateFromFormat)).
+		AddStaticMethod("createFromInterface", []ast.FunctionParameter{runtime.NewParam("$object", "DateTimeInterface")}, []string{className}, staticDateTimeMethod(className, dateTimeCreateFromInterface)).
+		OverloadOperators(dateTimeCompare)
+
+	if className == "DateTime" {
+		class.AddStaticMethod("createFromImmutable", []ast.FunctionParameter{runtime.NewParam("$object", "DateTimeImmutable")}, []string{className}, staticDateTimeMethod(className, dateTimeCreateFromImmutable))
+	} else {
+		class.AddStaticMethod("createFromMutable", []ast.FunctionParameter{runtime.NewParam("$object", "DateTime")}, []string{className}, staticDateTimeMethod(className, dateTimeCreateFromMutable))
+	}
+	class.Register()
+}
+
+// Compare two DateTimeInterface objects by the instant (Unix timestamp and microseconds) regardless of their timezones.
+// Other operators and operands use the default behavior.
+func dateTimeCompare(lhs values.RuntimeValue, operator string, rhs values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, bool, phpError.Error) {
+	if operator != "<=>" {
+		return nil, false, nil
+	}
+	lhsObject, lhsIsObject := lhs.(*values.Object)
+	rhsObject, rhsIsObject := rhs.(*values.Object)
+	if !lhsIsObject || !rhsIsObject {
+		return nil, false, nil
+	}
+	lhsDate, lhsIsDate := lhsObject.Internal.(*dateTime)
+	rhsDate, rhsIsDate := rhsObject.Internal.(*dateTime)
+	if !lhsIsDate || !rhsIsDate {
+		return nil, false, nil
+	}
+	return values.NewInt(int64(lhsDate.time.Compare(rhsDate.time))), true, nil
+}
+
+// Static methods receive no object, so the name of the class is passed to them
+func staticDateTimeMethod(
+	className string, method func(className string, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error),
+) runtime.NativeMethod {
+	return func(_ *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+		return method(className, args, context)
+	}
+}
+
+func dateTimeConstruct(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	className := dateTimeClassName(object, context)
+	args, err := funcParamValidator.NewValidator(className+"::__construct").
+		AddParam("$datetime", stringType, values.NewStr("now")).AddParam("$timezone", mixedType, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	timezoneObject, err := context.ObjectArg(className+"::__construct", 2, "$timezone", "DateTimeZone", true, args[1])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	timezone, err := timezoneArg(timezoneObject, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	date, parsed := parseDateTime(args[0].(*values.Str).Value, timezone, context)
+	if date == nil {
+		return values.NewVoid(), newException("DateMalformedStringException", "%s", parsed.errorMessage())
+	}
+	setDateTime(object, date)
+	return values.NewVoid(), nil
+}
+
+func dateTimeAdd(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	return addInterval(object, args, context, "add", 1)
+}
+
+func dateTimeSub(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	return addInterval(object, args, context, "sub", -1)
+}
+
+func addInterval(object *values.Object, args []values.RuntimeValue, context runtime.Context, methodName string, sign int) (values.RuntimeValue, phpError.Error) {
+	functionName := dateTimeClassName(object, context) + "::" + methodName
+	args, err := funcParamValidator.NewValidator(functionName).AddParam("$interval", mixedType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	intervalObject, err := context.ObjectArg(functionName, 1, "$interval", "DateInterval", false, args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	interval := getInterval(intervalObject)
+	return modifyDateTime(object, &dateTime{time: interval.addTo(date.time, sign), timezone: date.timezone}, context), nil
+}
+
+func dateTimeDiff(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	functionName := dateTimeClassName(object, context) + "::diff"
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$targetObject", mixedType, nil).AddParam("$absolute", boolType, values.NewBool(false)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	targetObject, err := context.ObjectArg(functionName, 1, "$targetObject", "DateTimeInterface", false, args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	target, err := getDateTime(targetObject)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	interval := diff(date, target)
+	if args[1].(*values.Bool).Value {
+		interval.invert = false
+	}
+	return newIntervalObject(context, interval)
+}
+
+func dateTimeFormat(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(dateTimeClassName(object, context)+"::format").AddParam("$format", stringType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(formatDate(args[0].(*values.Str).Value, date.time, date.timezone)), nil
+}
+
+func dateTimeGetMicrosecond(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator(dateTimeClassName(object, context) + "::getMicrosecond").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(int64(date.time.Nanosecond() / 1000)), nil
+}
+
+func dateTimeGetOffset(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator(dateTimeClassName(object, context) + "::getOffset").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	_, offset := date.time.Zone()
+	return values.NewInt(int64(offset)), nil
+}
+
+func dateTimeGetTimestamp(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator(dateTimeClassName(object, context) + "::getTimestamp").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(date.time.Unix()), nil
+}
+
+func dateTimeGetTimezone(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator(dateTimeClassName(object, context) + "::getTimezone").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return newTimezoneObject(context, date.timezone)
+}
+
+func dateTimeModify(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	className := dateTimeClassName(object, context)
+	args, err := funcParamValidator.NewValidator(className+"::modify").AddParam("$modifier", stringType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	modified, parsed := modifyTime(date, args[0].(*values.Str).Value, context)
+	if modified == nil {
+		return values.NewVoid(), newException("DateMalformedStringException", "%s::modify(): %s", className, parsed.errorMessage())
+	}
+	return modifyDateTime(object, modified, context), nil
+}
+
+// Apply a modifier like "+1 day" or "next month" to the date
+func modifyTime(date *dateTime, modifier string, context runtime.Context) (*dateTime, *parsedTime) {
+	parsed := parseTime(modifier)
+	setLastErrors(context, parsed.warnings, parsed.errors)
+	if len(parsed.errors) > 0 {
+		return nil, parsed
+	}
+	modifiedTime, modifiedTimezone := parsed.apply(date.time, date.timezone)
+	return &dateTime{time: modifiedTime, timezone: modifiedTimezone}, parsed
+}
+
+func dateTimeSetDate(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(dateTimeClassName(object, context)+"::setDate").
+		AddParam("$year", intType, nil).AddParam("$month", intType, nil).AddParam("$day", intType, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	t := date.time
+	year, month, day := int(args[0].(*values.Int).Value), int(args[1].(*values.Int).Value), int(args[2].(*values.Int).Value)
+	t = time.Date(year, time.Month(month), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	return modifyDateTime(object, &dateTime{time: t, timezone: date.timezone}, context), nil
+}
+
+func dateTimeSetISODate(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(dateTimeClassName(object, context)+"::setISODate").
+		AddParam("$year", intType, nil).AddParam("$week", intType, nil).AddParam("$dayOfWeek", intType, values.NewInt(1)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	t := date.time
+	year, week, dayOfWeek := int(args[0].(*values.Int).Value), int(args[1].(*values.Int).Value), int(args[2].(*values.Int).Value)
+	// The 4th of January is always in the first week of the ISO year
+	fourth := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	day := 4 - (Iso8601Weekday(fourth.Weekday()) - 1) + (week-1)*7 + (dayOfWeek - 1)
+	t = time.Date(year, time.January, day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	return modifyDateTime(object, &dateTime{time: t, timezone: date.timezone}, context), nil
+}
+
+func dateTimeSetMicrosecond(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	functionName := dateTimeClassName(object, context) + "::setMicrosecond"
+	args, err := funcParamValidator.NewValidator(functionName).AddParam("$microsecond", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	microsecond := args[0].(*values.Int).Value
+	if microsecond < 0 || microsecond > 999999 {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: %s(): Argument #1 ($microsecond) must be between 0 and 999999, %d given", functionName, microsecond,
+		)
+	}
+	t := date.time
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), int(microsecond)*1000, t.Location())
+	return modifyDateTime(object, &dateTime{time: t, timezone: date.timezone}, context), nil
+}
+
+func dateTimeSetTime(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(dateTimeClassName(object, context)+"::setTime").
+		AddParam("$hour", intType, nil).AddParam("$minute", intType, nil).
+		AddParam("$second", intType, values.NewInt(0)).AddParam("$microsecond", intType, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	t := date.time
+	hour, minute := int(args[0].(*values.Int).Value), int(args[1].(*values.Int).Value)
+	second, microsecond := int(args[2].(*values.Int).Value), int(args[3].(*values.Int).Value)
+	t = time.Date(t.Year(), t.Month(), t.Day(), hour, minute, second, microsecond*1000, t.Location())
+	return modifyDateTime(object, &dateTime{time: t, timezone: date.timezone}, context), nil
+}
+
+func dateTimeSetTimestamp(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(dateTimeClassName(object, context)+"::setTimestamp").AddParam("$timestamp", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	t := time.Unix(args[0].(*values.Int).Value, 0).In(date.timezone.location)
+	return modifyDateTime(object, &dateTime{time: t, timezone: date.timezone}, context), nil
+}
+
+func dateTimeSetTimezone(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	functionName := dateTimeClassName(object, context) + "::setTimezone"
+	args, err := funcParamValidator.NewValidator(functionName).AddParam("$timezone", mixedType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	timezoneObject, err := context.ObjectArg(functionName, 1, "$timezone", "DateTimeZone", false, args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	timezone, err := getTimezone(timezoneObject)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return modifyDateTime(object, &dateTime{time: date.time.In(timezone.location), timezone: timezone}, context), nil
+}
+
+func dateTimeGetLastErrors(_ *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("DateTime::getLastErrors").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return lib_date_get_last_errors(context), nil
+}
+
+func dateTimeCreateFromInterface(className string, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	return createFromObject(className, "createFromInterface", "DateTimeInterface", args, context)
+}
+
+func dateTimeCreateFromImmutable(className string, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	return createFromObject(className, "createFromImmutable", "DateTimeImmutable", args, context)
+}
+
+func dateTimeCreateFromMutable(className string, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	return createFromObject(className, "createFromMutable", "DateTime", args, context)
+}
+
+func createFromObject(className string, methodName string, paramClass string, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	functionName := className + "::" + methodName
+	args, err := funcParamValidator.NewValidator(functionName).AddParam("$object", mixedType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	object, err := context.ObjectArg(functionName, 1, "$object", paramClass, false, args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return newDateTimeObject(context, className, date)
+}
+
+// -------------------------------------- date_add -------------------------------------- MARK: date_add
+
+func nativeFn_date_add(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-add.php
+	return callDateTimeMethod("date_add", "DateTime", dateTimeAdd, args, context, "$interval")
+}
+
+// -------------------------------------- date_create -------------------------------------- MARK: date_create
+
+func nativeFn_date_create(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-create.php
+	return lib_date_create("date_create", "DateTime", args, context)
+}
+
+func nativeFn_date_create_immutable(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-create-immutable.php
+	return lib_date_create("date_create_immutable", "DateTimeImmutable", args, context)
+}
+
+func lib_date_create(functionName string, className string, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$datetime", stringType, values.NewStr("now")).AddParam("$timezone", mixedType, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	timezoneObject, err := context.ObjectArg(functionName, 2, "$timezone", "DateTimeZone", true, args[1])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	timezone, err := timezoneArg(timezoneObject, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	date, _ := parseDateTime(args[0].(*values.Str).Value, timezone, context)
+	if date == nil {
+		return values.NewBool(false), nil
+	}
+	return newDateTimeObject(context, className, date)
+}
+
+// -------------------------------------- date_date_set -------------------------------------- MARK: date_date_set
+
+func nativeFn_date_date_set(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-date-set.php
+	return callDateTimeMethod("date_date_set", "DateTime", dateTimeSetDate, args, context, "$year", "$month", "$day")
+}
+
+// -------------------------------------- date_diff -------------------------------------- MARK: date_diff
+
+func nativeFn_date_diff(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-diff.php
+	return callDateTimeMethod("date_diff", "DateTimeInterface", dateTimeDiff, args, context, "$targetObject", "?$absolute")
+}
+
+// -------------------------------------- date_format -------------------------------------- MARK: date_format
+
+func nativeFn_date_format(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-format.php
+	return callDateTimeMethod("date_format", "DateTimeInterface", dateTimeFormat, args, context, "$format")
+}
+
+// -------------------------------------- date_get_last_errors -------------------------------------- MARK: date_get_last_errors
+
+func nativeFn_date_get_last_errors(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-get-last-errors.php
+	if _, err := funcParamValidator.NewValidator("date_get_last_errors").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return lib_date_get_last_errors(context), nil
+}
+
+func lib_date_get_last_errors(context runtime.Context) values.RuntimeValue {
+	lastErrors, found := context.Interpreter.GetExectionContext().GetDateLastErrors()
+	if !found {
+		return values.NewBool(false)
+	}
+	return lastErrors
+}
+
+// -------------------------------------- date_isodate_set -------------------------------------- MARK: date_isodate_set
+
+func nativeFn_date_isodate_set(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-isodate-set.php
+	return callDateTimeMethod("date_isodate_set", "DateTime", dateTimeSetISODate, args, context, "$year", "$week", "?$dayOfWeek")
+}
+
+// -------------------------------------- date_modify -------------------------------------- MARK: date_modify
+
+func nativeFn_date_modify(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-modify.php
+	args, err := funcParamValidator.NewValidator("date_modify").
+		AddParam("$object", mixedType, nil).AddParam("$modifier", stringType, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	object, err := context.ObjectArg("date_modify", 1, "$object", "DateTime", false, args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Unlike DateTime::modify(), date_modify() does not throw an exception
+	modified, parsed := modifyTime(date, args[1].(*values.Str).Value, context)
+	if modified == nil {
+		context.Interpreter.PrintError(phpError.NewWarning("date_modify(): %s%s", parsed.errorMessage(), context.InPosition()))
+		return values.NewBool(false), nil
+	}
+	return modifyDateTime(object, modified, context), nil
+}
+
+// -------------------------------------- date_offset_get -------------------------------------- MARK: date_offset_get
+
+func nativeFn_date_offset_get(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-offset-get.php
+	return callDateTimeMethod("date_offset_get", "DateTimeInterface", dateTimeGetOffset, args, context)
+}
+
+// -------------------------------------- date_sub -------------------------------------- MARK: date_sub
+
+func nativeFn_date_sub(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-sub.php
+	return callDateTimeMethod("date_sub", "DateTime", dateTimeSub, args, context, "$interval")
+}
+
+// -------------------------------------- date_time_set -------------------------------------- MARK: date_time_set
+
+func nativeFn_date_time_set(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-time-set.php
+	return callDateTimeMethod("date_time_set", "DateTime", dateTimeSetTime, args, context, "$hour", "$minute", "?$second", "?$microsecond")
+}
+
+// -------------------------------------- date_timestamp_get -------------------------------------- MARK: date_timestamp_get
+
+func nativeFn_date_timestamp_get(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-timestamp-get.php
+	return callDateTimeMethod("date_timestamp_get", "DateTimeInterface", dateTimeGetTimestamp, args, context)
+}
+
+// -------------------------------------- date_timestamp_set -------------------------------------- MARK: date_timestamp_set
+
+func nativeFn_date_timestamp_set(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-timestamp-set.php
+	return callDateTimeMethod("date_timestamp_set", "DateTime", dateTimeSetTimestamp, args, context, "$timestamp")
+}
+
+// -------------------------------------- date_timezone_get -------------------------------------- MARK: date_timezone_get
+
+func nativeFn_date_timezone_get(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-timezone-get.php
+	return callDateTimeMethod("date_timezone_get", "DateTimeInterface", dateTimeGetTimezone, args, context)
+}
+
+// -------------------------------------- date_timezone_set -------------------------------------- MARK: date_timezone_set
+
+func nativeFn_date_timezone_set(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-timezone-set.php
+	return callDateTimeMethod("date_timezone_set", "DateTime", dateTimeSetTimezone, args, context, "$timezone")
+}
+
+// Call the method of a DateTime object for a procedural alias like date_format($object, $format).
+// The parameters are the names of the arguments following the object. Optional parameters are prefixed with "?".
+func callDateTimeMethod(
+	functionName string, className string, method runtime.NativeMethod, args []values.RuntimeValue, context runtime.Context, params ...string,
+) (values.RuntimeValue, phpError.Error) {
+	validator := funcParamValidator.NewValidator(functionName).AddParam("$object", mixedType, nil)
+	for _, param := range params {
+		if param[0] == '?' {
+			validator.AddParam(param[1:], mixedType, values.NewVoid())
+		} else {
+			validator.AddParam(param, mixedType, nil)
+		}
+	}
+	args, err := validator.Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	object, err := context.ObjectArg(functionName, 1, "$object", className, false, args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Optional arguments that are not given are left to the defaults of the method
+	methodArgs := []values.RuntimeValue{}
+	for _, arg := range args[1:] {
+		if arg.GetType() == values.VoidValue {
+			break
+		}
+		methodArgs = append(methodArgs, arg)
+	}
+	return method(object, methodArgs, context)
+}
@@ -0,0 +1,364 @@
+package dateTime
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var dayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+var monthNames = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+
+// Format the time according to the format characters of date() and DateTimeInterface::format()
+func formatDate(format string, t time.Time, timezone *timezone) string {
+	// Spec: https://www.php.net/manual/en/datetime.format.php
+	var result strings.Builder
+	for i := 0; i < len(format); i++ {
+		switch format[i] {
+		// Day
+		case 'd':
+			// Day of the month, 2 digits with leading zeros
+			fmt.Fprintf(&result, "%02d", t.Day())
+		case 'D':
+			// A textual representation of a day, three letters
+			result.WriteString(dayNames[t.Weekday()][:3])
+		case 'j':
+			// Day of the month without leading zeros
+			result.WriteString(strconv.Itoa(t.Day()))
+		case 'l':
+			// A full textual representation of the day of the week
+			result.WriteString(dayNames[t.Weekday()])
+		case 'N':
+			// ISO 8601 numeric representation of the day of the week: 1 (for Monday) through 7 (for Sunday)
+			result.WriteString(strconv.Itoa(Iso8601Weekday(t.Weekday())))
+		case 'S':
+			// English ordinal suffix for the day of the month, 2 characters
+			result.WriteString(ordinalSuffix(t.Day()))
+		case 'w':
+			// Numeric representation of the day of the week: 0 (for Sunday) through 6 (for Saturday)
+			result.WriteString(strconv.Itoa(int(t.Weekday())))
+		case 'z':
+			// The day of the year (starting from 0)
+			result.WriteString(strconv.Itoa(t.YearDay() - 1))
+
+		// Week
+		case 'W':
+			// ISO 8601 week number of year, weeks starting on Monday
+			_, week := t.ISOWeek()
+			fmt.Fprintf(&result, "%02d", week)
+
+		// Month
+		case 'F':
+			// A full textual representation of a month
+			result.WriteString(monthNames[t.Month()-1])
+		case 'm':
+			// Numeric representation of a month, with leading zeros
+			fmt.Fprintf(&result, "%02d", int(t.Month()))
+		case 'M':
+			// A short textual representation of a month, three letters
+			result.WriteString(monthNames[t.Month()-1][:3])
+		case 'n':
+			// Numeric representation of a month, without leading zeros
+			result.WriteString(strconv.Itoa(int(t.Month())))
+		case 't':
+			// Number of days in the given month
+			result.WriteString(strconv.Itoa(DaysIn(t.Month(), t.Year())))
+
+		// Year
+		case 'L':
+			// Whether it's a leap year
+			result.WriteString(boolToDigit(IsLeapYear(t.Year())))
+		case 'o':
+			// ISO 8601 week-numbering year
+			year, _ := t.ISOWeek()
+			result.WriteString(strconv.Itoa(year))
+		case 'X':
+			// An expanded full numeric representation of a year, at least 4 digits, with - for years BCE, and + for years CE
+			result.WriteString(formatYear(t.Year(), true))
+		case 'x':
+			// An expanded full numeric representation if required, or a standard full numeral representation if possible (like Y)
+			result.WriteString(formatYear(t.Year(), t.Year() >= 10000))
+		case 'Y':
+			// A full numeric representation of a year, at least 4 digits, with - for years BCE
+			result.WriteString(formatYear(t.Year(), false))
+		case 'y':
+			// A two digit representation of a year
+			fmt.Fprintf(&result, "%02d", (t.Year()%100+100)%100)
+
+		// Time
+		case 'a':
+			// Lowercase Ante meridiem and Post meridiem
+			result.WriteString(strings.ToLower(t.Format("PM")))
+		case 'A':
+			// Uppercase Ante meridiem and Post meridiem
+			result.WriteString(t.Format("PM"))
+		case 'B':
+			// Swatch Internet time
+			seconds := ((t.Unix()+3600)%86400 + 86400) % 86400
+			fmt.Fprintf(&result, "%03d", int(float64(seconds)/86.4)%1000)
+		case 'g':
+			// 12-hour format of an hour without leading zeros
+			result.WriteString(t.Format("3"))
+		case 'G':
+			// 24-hour format of an hour without leading zeros
+			result.WriteString(strconv.Itoa(t.Hour()))
+		case 'h':
+			// 12-hour format of an hour with leading zeros
+			result.WriteString(t.Format("03"))
+		case 'H':
+			// 24-hour format of an hour with leading zeros
+			fmt.Fprintf(&result, "%02d", t.Hour())
+		case 'i':
+			// Minutes with leading zeros
+			fmt.Fprintf(&result, "%02d", t.Minute())
+		case 's':
+			// Seconds with leading zeros
+			fmt.Fprintf(&result, "%02d", t.Second())
+		case 'u':
+			// Microseconds
+			fmt.Fprintf(&result, "%06d", t.Nanosecond()/1000)
+		case 'v':
+			// Milliseconds
+			fmt.Fprintf(&result, "%03d", t.Nanosecond()/1000000)
+
+		// Timezone
+		case 'e':
+			// Timezone identifier
+			result.WriteString(timezone.name)
+		case 'I':
+			// Whether or not the date is in daylight saving time
+			result.WriteString(boolToDigit(timezone.isDstAt(t)))
+		case 'O':
+			// Difference to Greenwich time (GMT) without colon between hours and minutes
+			_, offset := t.Zone()
+			result.WriteString(formatOffset(offset, false))
+		case 'P':
+			// Difference to Greenwich time (GMT) with colon between hours and minutes
+			_, offset := t.Zone()
+			result.WriteString(formatOffset(offset, true))
+		case 'p':
+			// The same as P, but returns Z instead of +00:00
+			_, offset := t.Zone()
+			if offset == 0 {
+				result.WriteString("Z")
+			} else {
+				result.WriteString(formatOffset(offset, true))
+			}
+		case 'T':
+			// Timezone abbreviation, if known; otherwise the GMT offset
+			result.WriteString(timezone.abbreviation(t))
+		case 'Z':
+			// Timezone offset in seconds
+			_, offset := t.Zone()
+			result.WriteString(strconv.Itoa(offset))
+
+		// Full Date/Time
+		case 'c':
+			// ISO 8601 date
+			result.WriteString(formatDate(`Y-m-d\TH:i:sP`, t, timezone))
+		case 'r':
+			// RFC 2822/RFC 5322 formatted date
+			result.WriteString(formatDate("D, d M Y H:i:s O", t, timezone))
+		case 'U':
+			// Seconds since the Unix Epoch
+			result.WriteString(strconv.FormatInt(t.Unix(), 10))
+
+		case '\\':
+			// Escaped character
+			if i+1 < len(format) {
+				i++
+				result.WriteByte(format[i])
+			}
+		default:
+			result.WriteByte(format[i])
+		}
+	}
+	return result.String()
+}
+
+func formatStrftime(format string, t time.Time, timezone *timezone) string {
+	// Spec: https://www.php.net/manual/en/function.strftime.php
+	// The names and the representations of %c, %x and %X are the ones of the "C" locale.
+	var result strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			result.WriteByte(format[i])
+			continue
+		}
+		i++
+		switch format[i] {
+		// Day
+		case 'a':
+			// An abbreviated textual representation of the day
+			result.WriteString(dayNames[t.Weekday()][:3])
+		case 'A':
+			// A full textual representation of the day
+			result.WriteString(dayNames[t.Weekday()])
+		case 'd':
+			// Two-digit day of the month (with leading zeros)
+			fmt.Fprintf(&result, "%02d", t.Day())
+		case 'e':
+			// Day of the month, with a space preceding single digits
+			fmt.Fprintf(&result, "%2d", t.Day())
+		case 'j':
+			// Day of the year, 3 digits with leading zeros
+			fmt.Fprintf(&result, "%03d", t.YearDay())
+		case 'u':
+			// ISO-8601 numeric representation of the day of the week: 1 (for Monday) through 7 (for Sunday)
+			result.WriteString(strconv.Itoa(Iso8601Weekday(t.Weekday())))
+		case 'w':
+			// Numeric representation of the day of the week: 0 (for Sunday) through 6 (for Saturday)
+			result.WriteString(strconv.Itoa(int(t.Weekday())))
+
+		// Week
+		case 'U':
+			// Week number of the given year, starting with the first Sunday as the first week
+			fmt.Fprintf(&result, "%02d", (t.YearDay()+6-int(t.Weekday()))/7)
+		case 'V':
+			// ISO-8601:1988 week number of the given year, starting with the first week of the year with at least 4 weekdays
+			_, week := t.ISOWeek()
+			fmt.Fprintf(&result, "%02d", week)
+		case 'W':
+			// A numeric representation of the week of the year, starting with the first Monday as the first week
+			fmt.Fprintf(&result, "%02d", (t.YearDay()+6-(int(t.Weekday())+6)%7)/7)
+
+		// Month
+		case 'b', 'h':
+			// Abbreviated month name
+			result.WriteString(monthNames[t.Month()-1][:3])
+		case 'B':
+			// Full month name
+			result.WriteString(monthNames[t.Month()-1])
+		case 'm':
+			// Two digit representation of the month
+			fmt.Fprintf(&result, "%02d", int(t.Month()))
+
+		// Year
+		case 'C':
+			// Two digit representation of the century (year divided by 100, truncated to an integer)
+			fmt.Fprintf(&result, "%02d", t.Year()/100)
+		case 'g':
+			// Two digit representation of the year going by ISO-8601:1988 standards
+			year, _ := t.ISOWeek()
+			fmt.Fprintf(&result, "%02d", (year%100+100)%100)
+		case 'G':
+			// The full four-digit version of %g
+			year, _ := t.ISOWeek()
+			result.WriteString(strconv.Itoa(year))
+		case 'y':
+			// Two digit representation of the year
+			fmt.Fprintf(&result, "%02d", (t.Year()%100+100)%100)
+		case 'Y':
+			// Four digit representation for the year
+			result.WriteString(strconv.Itoa(t.Year()))
+
+		// Time
+		case 'H':
+			// Two digit representation of the hour in 24-hour format
+			fmt.Fprintf(&result, "%02d", t.Hour())
+		case 'k':
+			// Hour in 24-hour format, with a space preceding single digits
+			fmt.Fprintf(&result, "%2d", t.Hour())
+		case 'I':
+			// Two digit representation of the hour in 12-hour format
+			result.WriteString(t.Format("03"))
+		case 'l':
+			// Hour in 12-hour format, with a space preceding single digits
+			fmt.Fprintf(&result, "%2s", t.Format("3"))
+		case 'M':
+			// Two digit representation of the minute
+			fmt.Fprintf(&result, "%02d", t.Minute())
+		case 'p':
+			// UPPER-CASE 'AM' or 'PM' based on the given time
+			result.WriteString(t.Format("PM"))
+		case 'P':
+			// lower-case 'am' or 'pm' based on the given time
+			result.WriteString(strings.ToLower(t.Format("PM")))
+		case 'r':
+			// Same as "%I:%M:%S %p"
+			result.WriteString(formatStrftime("%I:%M:%S %p", t, timezone))
+		case 'R':
+			// Same as "%H:%M"
+			result.WriteString(formatStrftime("%H:%M", t, timezone))
+		case 'S':
+			// Two digit representation of the second
+			fmt.Fprintf(&result, "%02d", t.Second())
+		case 'T', 'X':
+			// Same as "%H:%M:%S"
+			result.WriteString(formatStrftime("%H:%M:%S", t, timezone))
+		case 'z':
+			// The time zone offset
+			_, offset := t.Zone()
+			result.WriteString(formatOffset(offset, false))
+		case 'Z':
+			// The time zone abbreviation
+			result.WriteString(timezone.abbreviation(t))
+
+		// Time and Date Stamps
+		case 'c':
+			// Preferred date and time stamp
+			result.WriteString(formatStrftime("%a %b %e %H:%M:%S %Y", t, timezone))
+		case 'D', 'x':
+			// Same as "%m/%d/%y"
+			result.WriteString(formatStrftime("%m/%d/%y", t, timezone))
+		case 'F':
+			// Same as "%Y-%m-%d"
+			result.WriteString(formatStrftime("%Y-%m-%d", t, timezone))
+		case 's':
+			// Unix Epoch Time timestamp
+			result.WriteString(strconv.FormatInt(t.Unix(), 10))
+
+		// Miscellaneous
+		case 'n':
+			// A newline character
+			result.WriteByte('\n')
+		case 't':
+			// A Tab character
+			result.WriteByte('\t')
+		case '%':
+			// A literal percentage character
+			result.WriteByte('%')
+		default:
+			// Unknown conversions are kept as they are
+			result.WriteByte('%')
+			result.WriteByte(format[i])
+		}
+	}
+	return result.String()
+}
+
+func ordinalSuffix(day int) string {
+	if day%100 >= 11 && day%100 <= 13 {
+		return "th"
+	}
+	switch day % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	default:
+		return "th"
+	}
+}
+
+func formatYear(year int, withPlus bool) string {
+	sign := ""
+	if year < 0 {
+		sign = "-"
+		year = -year
+	} else if withPlus {
+		sign = "+"
+	}
+	return fmt.Sprintf("%s%04d", sign, year)
+}
+
+func boolToDigit(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,383 @@
+package dateTime
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+	"math"
+	"strings"
+	"time"
+)
+
+type formatParser struct {
+	parsed *parsedTime
+	input  string
+	pos    int
+	// "am" or "pm", if the meridian is given
+	meridian  string
+	dayOfYear int
+	// Fields that are not given are reset to the Unix epoch ("|" or "!")
+	resetUnset bool
+}
+
+// Parse the date/time string according to the format of DateTime::createFromFormat()
+func parseFromFormat(format string, input string) (*parsedTime, bool) {
+	// Spec: https://www.php.net/manual/en/datetime.createfromformat.php
+	parser := &formatParser{
+		input: input,
+		parsed: &parsedTime{
+			input: input,
+			year:  unset, month: unset, day: unset,
+			hour: unset, minute: unset, second: unset, microsecond: unset,
+		},
+		dayOfYear: unset,
+	}
+
+	for i := 0; i < len(format) && len(parser.parsed.errors) == 0; i++ {
+		char := format[i]
+		if parser.pos >= len(input) && !strings.ContainsRune("!|+*", rune(char)) {
+			parser.addError("Not enough data available to satisfy format")
+			break
+		}
+		switch char {
+		case 'd', 'j':
+			parser.readNumber(2, &parser.parsed.day, "A two digit day could not be found")
+		case 'D', 'l':
+			if !parser.readName(dayNames) {
+				parser.addError("A textual day could not be found")
+			}
+		case 'S':
+			if suffix := strings.ToLower(parser.rest(2)); suffix == "st" || suffix == "nd" || suffix == "rd" || suffix == "th" {
+				parser.pos += 2
+			} else {
+				parser.addError("The ordinal suffix could not be found")
+			}
+		case 'z':
+			parser.readNumber(3, &parser.dayOfYear, "A three digit day-of-year could not be found")
+		case 'F', 'M':
+			month := 0
+			for index, name := range monthNames {
+				if parser.matchName(name) {
+					month = index + 1
+					break
+				}
+			}
+			if month == 0 {
+				parser.addError("A textual month could not be found")
+			} else {
+				parser.parsed.month = month
+			}
+		case 'm', 'n':
+			parser.readNumber(2, &parser.parsed.month, "A two digit month could not be found")
+		case 'Y':
+			parser.readNumber(4, &parser.parsed.year, "A four digit year could not be found")
+		case 'y':
+			year := unset
+			if parser.readExactNumber(2, &year, "A two digit year could not be found") {
+				parser.parsed.year = expandYear(parser.input[parser.pos-2 : parser.pos])
+			}
+		case 'a', 'A':
+			if parser.parsed.hour == unset {
+				parser.addError("Meridian can only come after an hour has been found")
+				break
+			}
+			if meridian := strings.ToLower(parser.rest(2)); meridian == "am" || meridian == "pm" {
+				parser.meridian = meridian
+				parser.pos += 2
+			} else {
+				parser.addError("A meridian could not be found")
+			}
+		case 'g', 'h', 'G', 'H':
+			parser.readNumber(2, &parser.parsed.hour, "A two digit hour could not be found")
+		case 'i':
+			parser.readExactNumber(2, &parser.parsed.minute, "A two digit minute could not be found")
+		case 's':
+			parser.readExactNumber(2, &parser.parsed.second, "A two digit second could not be found")
+		case 'v':
+			milliseconds := unset
+			if parser.readExactNumber(3, &milliseconds, "A three digit millisecond could not be found") {
+				parser.parsed.microsecond = milliseconds * 1000
+			}
+		case 'u':
+			start := parser.pos
+			microsecond := unset
+			if parser.readNumber(6, &microsecond, "A six digit microsecond could not be found") {
+				parser.parsed.microsecond = atoi((parser.input[start:parser.pos] + "000000")[:6])
+			}
+		case 'e', 'T', 'O', 'P', 'p':
+			parser.readTimezone()
+		case 'U':
+			parser.readTimestamp()
+		case ' ':
+			for parser.pos < len(input) && (input[parser.pos] == ' ' || input[parser.pos] == '\t') {
+				parser.pos++
+			}
+		case '#':
+			if strings.ContainsRune(";:/.,-()", rune(input[parser.pos])) {
+				parser.pos++
+			} else {
+				parser.addError("The separation symbol ([;:/.,-]) could not be found")
+			}
+		case ';', ':', '/', '.', ',', '-', '(', ')':
+			if input[parser.pos] == char {
+				parser.pos++
+			} else {
+				parser.addError("The separation symbol could not be found")
+			}
+		case '?':
+			parser.pos++
+		case '*':
+			for parser.pos < len(input) && !strings.ContainsRune(" ,;:/.-()", rune(input[parser.pos])) && !isDigit(input[parser.pos]) {
+				parser.pos++
+			}
+		case '!':
+			parser.resetAll()
+		case '|':
+			parser.resetUnset = true
+		case '+':
+			if parser.pos < len(input) {
+				parser.parsed.warnings = append(parser.parsed.warnings, parseMessage{position: parser.pos, char: input[parser.pos], message: "Trailing data"})
+				parser.pos = len(input)
+			}
+		case '\\':
+			if i+1 < len(format) {
+				i++
+				char = format[i]
+			}
+			if input[parser.pos] == char {
+				parser.pos++
+			} else {
+				parser.addError("The escaped character could not be found")
+			}
+		default:
+			if input[parser.pos] == char {
+				parser.pos++
+			} else {
+				parser.addError("The format separator does not match")
+			}
+		}
+	}
+	if len(parser.parsed.errors) == 0 && parser.pos < len(input) {
+		parser.addError("Trailing data")
+	}
+	if len(parser.parsed.errors) == 0 {
+		parser.finish()
+	}
+	return parser.parsed, len(parser.parsed.errors) == 0
+}
+
+func (parser *formatParser) addError(message string) {
+	var char byte
+	if parser.pos < len(parser.input) {
+		char = parser.input[parser.pos]
+	}
+	parser.parsed.errors = append(parser.parsed.errors, parseMessage{position: parser.pos, char: char, message: message})
+}
+
+func (parser *formatParser) rest(length int) string {
+	if parser.pos+length > len(parser.input) {
+		return parser.input[parser.pos:]
+	}
+	return parser.input[parser.pos : parser.pos+length]
+}
+
+// Read a number with up to maxLength digits
+func (parser *formatParser) readNumber(maxLength int, field *int, message string) bool {
+	start := parser.pos
+	for parser.pos < len(parser.input) && isDigit(parser.input[parser.pos]) && parser.pos-start < maxLength {
+		parser.pos++
+	}
+	if start == parser.pos {
+		parser.addError(message)
+		return false
+	}
+	*field = atoi(parser.input[start:parser.pos])
+	return true
+}
+
+// Read a number with exactly the given number of digits
+func (parser *formatParser) readExactNumber(length int, field *int, message string) bool {
+	for i := 0; i < length; i++ {
+		if parser.pos+i >= len(parser.input) || !isDigit(parser.input[parser.pos+i]) {
+			parser.addError(message)
+			return false
+		}
+	}
+	*field = atoi(parser.input[parser.pos : parser.pos+length])
+	parser.pos += length
+	return true
+}
+
+// Match a full (e.g. "January") or abbreviated (e.g. "Jan") name
+func (parser *formatParser) matchName(name string) bool {
+	if strings.EqualFold(parser.rest(len(name)), name) {
+		parser.pos += len(name)
+		return true
+	}
+	if strings.EqualFold(parser.rest(3), name[:3]) {
+		parser.pos += 3
+		return true
+	}
+	return false
+}
+
+func (parser *formatParser) readName(names []string) bool {
+	for _, name := range names {
+		if parser.matchName(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (parser *formatParser) readTimezone() {
+	start := parser.pos
+	if char := parser.input[start]; char == '+' || char == '-' {
+		if offset, length, ok := parseOffset(parser.input[start:]); ok {
+			parser.pos += length
+			parser.parsed.timezone = newOffsetTimezone(offset)
+			return
+		}
+	}
+	end := start
+	for end < len(parser.input) && (isLetter(parser.input[end]) || strings.ContainsRune("_/+-", rune(parser.input[end])) ||
+		(end > start && isDigit(parser.input[end]))) {
+		end++
+	}
+	timezone, found := loadTimezone(parser.input[start:end])
+	if !found {
+		parser.addError("The timezone could not be found in the database")
+		return
+	}
+	parser.pos = end
+	parser.parsed.timezone = timezone
+}
+
+func (parser *formatParser) readTimestamp() {
+	start := parser.pos
+	sign := 1
+	if parser.input[parser.pos] == '-' || parser.input[parser.pos] == '+' {
+		if parser.input[parser.pos] == '-' {
+			sign = -1
+		}
+		parser.pos++
+	}
+	timestamp := unset
+	if !parser.readNumber(math.MaxInt32, &timestamp, "A unix timestamp could not be found") {
+		parser.pos = start
+		return
+	}
+	utc := time.Unix(int64(sign*timestamp), 0).UTC()
+	parser.parsed.year, parser.parsed.month, parser.parsed.day = utc.Year(), int(utc.Month()), utc.Day()
+	parser.parsed.hour, parser.parsed.minute, parser.parsed.second = utc.Hour(), utc.Minute(), utc.Second()
+	if parser.parsed.microsecond == unset {
+		parser.parsed.microsecond = 0
+	}
+	if parser.parsed.timezone == nil {
+		parser.parsed.timezone = newOffsetTimezone(0)
+	}
+}
+
+// "!" resets all fields to the Unix epoch
+func (parser *formatParser) resetAll() {
+	parsed := parser.parsed
+	parsed.year, parsed.month, parsed.day = 1970, 1, 1
+	parsed.hour, parsed.minute, parsed.second, parsed.microsecond = 0, 0, 0, 0
+	parsed.timezone = nil
+	parser.meridian = ""
+	parser.dayOfYear = unset
+}
+
+// Apply the meridian, the day of the year and the rules for fields that are not given
+func (parser *formatParser) finish() {
+	parsed := parser.parsed
+	if parser.resetUnset {
+		parsed.year, parsed.month, parsed.day = valueOr(parsed.year, 1970), valueOr(parsed.month, 1), valueOr(parsed.day, 1)
+		parsed.hour, parsed.minute = valueOr(parsed.hour, 0), valueOr(parsed.minute, 0)
+		parsed.second, parsed.microsecond = valueOr(parsed.second, 0), valueOr(parsed.microsecond, 0)
+	}
+	if parser.meridian != "" {
+		if parsed.hour > 12 {
+			parsed.errors = append(parsed.errors, parseMessage{position: len(parser.input), message: "Hour cannot be higher than 12"})
+			return
+		}
+		if parsed.hour == 12 {
+			parsed.hour = 0
+		}
+		if parser.meridian == "pm" {
+			parsed.hour += 12
+		}
+	}
+	if parser.dayOfYear != unset {
+		parsed.month, parsed.day = 1, parser.dayOfYear+1
+	}
+	// If any time field is given, the other time fields are zero
+	if parsed.hour != unset || parsed.minute != unset || parsed.second != unset || parsed.microsecond != unset {
+		parsed.hour, parsed.minute = valueOr(parsed.hour, 0), valueOr(parsed.minute, 0)
+		parsed.second, parsed.microsecond = valueOr(parsed.second, 0), valueOr(parsed.microsecond, 0)
+	}
+
+	if parsed.year != unset && parsed.month != unset && parsed.day != unset &&
+		(parsed.month < 1 || parsed.month > 12 || parsed.day < 1 || parsed.day > DaysIn(time.Month(parsed.month), parsed.year)) {
+		parsed.warnings = append(parsed.warnings, parseMessage{position: len(parser.input), message: "The parsed date was invalid"})
+	}
+	if parsed.hour != unset && (parsed.hour > 24 || parsed.minute > 59 || parsed.second > 59) {
+		parsed.warnings = append(parsed.warnings, parseMessage{position: len(parser.input), message: "The parsed time was invalid"})
+	}
+}
+
+// Compute the time of the parsed format. Fields that are not given are taken from the current time.
+func (parsed *parsedTime) resolveFromFormat(now time.Time, timezone *timezone) (time.Time, *timezone) {
+	if parsed.timezone != nil {
+		timezone = parsed.timezone
+	}
+	now = now.In(timezone.location)
+	t := time.Date(
+		valueOr(parsed.year, now.Year()), time.Month(valueOr(parsed.month, int(now.Month()))), valueOr(parsed.day, now.Day()),
+		valueOr(parsed.hour, now.Hour()), valueOr(parsed.minute, now.Minute()), valueOr(parsed.second, now.Second()),
+		valueOr(parsed.microsecond, now.Nanosecond()/1000)*1000, timezone.location,
+	)
+	return t, timezone
+}
+
+func dateTimeCreateFromFormat(className string, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	return lib_date_create_from_format(className+"::createFromFormat", className, args, context)
+}
+
+// -------------------------------------- date_create_from_format -------------------------------------- MARK: date_create_from_format
+
+func nativeFn_date_create_from_format(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-create-from-format.php
+	return lib_date_create_from_format("date_create_from_format", "DateTime", args, context)
+}
+
+func nativeFn_date_create_immutable_from_format(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-create-immutable-from-format.php
+	return lib_date_create_from_format("date_create_immutable_from_format", "DateTimeImmutable", args, context)
+}
+
+func lib_date_create_from_format(functionName string, className string, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$format", stringType, nil).AddParam("$datetime", stringType, nil).AddParam("$timezone", mixedType, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	timezoneObject, err := context.ObjectArg(functionName, 3, "$timezone", "DateTimeZone", true, args[2])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	timezone, err := timezoneArg(timezoneObject, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	parsed, ok := parseFromFormat(args[0].(*values.Str).Value, args[1].(*values.Str).Value)
+	setLastErrors(context, parsed.warnings, parsed.errors)
+	if !ok {
+		return values.NewBool(false), nil
+	}
+	resolvedTime, resolvedTimezone := parsed.resolveFromFormat(time.Now(), timezone)
+	return newDateTimeObject(context, className, &dateTime{time: resolvedTime, timezone: resolvedTimezone})
+}
@@ -0,0 +1,390 @@
+package dateTime
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// State of a DateInterval.
+// The state is stored in the public properties of the object, so that changes of the properties are respected.
+type interval struct {
+	y, m, d, h, i, s, us int
+	invert               bool
+	// Total number of days, if the interval was created by DateTime::diff(), otherwise unset
+	days int
+}
+
+func getInterval(object *values.Object) interval {
+	property := func(name string) int {
+		value, _ := object.GetProperty(name)
+		switch value := value.(type) {
+		case *values.Int:
+			return int(value.Value)
+		case *values.Float:
+			return int(value.Value)
+		case *values.Bool:
+			if value.Value {
+				return 1
+			}
+		}
+		return 0
+	}
+
+	result := interval{
+		y: property("$y"), m: property("$m"), d: property("$d"), h: property("$h"), i: property("$i"), s: property("$s"),
+		invert: property("$invert") != 0, days: unset,
+	}
+	if fraction, found := object.GetProperty("$f"); found {
+		if fraction, isFloat := fraction.(*values.Float); isFloat {
+			result.us = int(fraction.Value*1000000 + 0.5)
+		}
+	}
+	if days, found := object.GetProperty("$days"); found && days.GetType() == values.IntValue {
+		result.days = int(days.(*values.Int).Value)
+	}
+	return result
+}
+
+func setInterval(object *values.Object, interval interval) {
+	object.SetProperty("$y", values.NewInt(int64(interval.y)))
+	object.SetProperty("$m", values.NewInt(int64(interval.m)))
+	object.SetProperty("$d", values.NewInt(int64(interval.d)))
+	object.SetProperty("$h", values.NewInt(int64(interval.h)))
+	object.SetProperty("$i", values.NewInt(int64(interval.i)))
+	object.SetProperty("$s", values.NewInt(int64(interval.s)))
+	object.SetProperty("$f", values.NewFloat(float64(interval.us)/1000000))
+	invert := 0
+	if interval.invert {
+		invert = 1
+	}
+	object.SetProperty("$invert", values.NewInt(int64(invert)))
+	if interval.days == unset {
+		object.SetProperty("$days", values.NewBool(false))
+	} else {
+		object.SetProperty("$days", values.NewInt(int64(interval.days)))
+	}
+}
+
+func newIntervalObject(context runtime.Context, interval interval) (*values.Object, phpError.Error) {
+	object, err := newObject(context.Interpreter, "DateInterval")
+	if err != nil {
+		return nil, err
+	}
+	// Objects that are not created with "new" have no default property values
+	object.SetProperty("$from_string", values.NewBool(false))
+	setInterval(object, interval)
+	return object, nil
+}
+
+// Add the interval to the time. With sign -1 the interval is subtracted.
+func (interval interval) addTo(t time.Time, sign int) time.Time {
+	if interval.invert {
+		sign = -sign
+	}
+	// Years, months and days change the wall clock time, hours, minutes and seconds are elapsed time
+	t = time.Date(t.Year()+sign*interval.y, t.Month()+time.Month(sign*interval.m), t.Day()+sign*interval.d,
+		t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	seconds := int64(sign) * (int64(interval.h)*3600 + int64(interval.i)*60 + int64(interval.s))
+	if seconds != 0 || interval.us != 0 {
+		t = time.Unix(t.Unix()+seconds, int64(t.Nanosecond())+int64(sign*interval.us)*1000).In(t.Location())
+	}
+	return t
+}
+
+// Compute the difference between two dates like DateTime::diff()
+func diff(from *dateTime, to *dateTime) interval {
+	result := interval{}
+	earlier, later := from.time, to.time
+	if later.Before(earlier) {
+		earlier, later = later, earlier
+		result.invert = true
+	}
+
+	// Dates in the same timezone are compared by their wall clock time, others in UTC
+	location := time.UTC
+	if from.timezone.equals(to.timezone) {
+		location = from.timezone.location
+	}
+	earlier, later = earlier.In(location), later.In(location)
+
+	result.y = later.Year() - earlier.Year()
+	result.m = int(later.Month()) - int(earlier.Month())
+	result.d = later.Day() - earlier.Day()
+	result.h = later.Hour() - earlier.Hour()
+	result.i = later.Minute() - earlier.Minute()
+	result.s = later.Second() - earlier.Second()
+	result.us = (later.Nanosecond() - earlier.Nanosecond()) / 1000
+
+	if result.us < 0 {
+		result.us += 1000000
+		result.s--
+	}
+	if result.s < 0 {
+		result.s += 60
+		result.i--
+	}
+	if result.i < 0 {
+		result.i += 60
+		result.h--
+	}
+	if result.h < 0 {
+		result.h += 24
+		result.d--
+	}
+	if result.m < 0 {
+		result.m += 12
+		result.y--
+	}
+
+	// Borrow the days of the month from the base date
+	base := later
+	if result.invert {
+		base = earlier
+	}
+	year, month := base.Year(), base.Month()
+	for result.d < 0 {
+		if !result.invert {
+			month--
+			if month < time.January {
+				month, year = time.December, year-1
+			}
+			result.d += DaysIn(month, year)
+		} else {
+			result.d += DaysIn(month, year)
+			month++
+			if month > time.December {
+				month, year = time.January, year+1
+			}
+		}
+		result.m--
+	}
+	if result.m < 0 {
+		result.m += 12
+		result.y--
+	}
+
+	// Number of full days between the dates
+	earlierDate := time.Date(earlier.Year(), earlier.Month(), earlier.Day(), 0, 0, 0, 0, time.UTC)
+	laterDate := time.Date(later.Year(), later.Month(), later.Day(), 0, 0, 0, 0, time.UTC)
+	result.days = int(laterDate.Sub(earlierDate).Hours() / 24)
+	if timeOfDay(later) < timeOfDay(earlier) {
+		result.days--
+	}
+	return result
+}
+
+func timeOfDay(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+// Parse an ISO 8601 duration like "P1Y2M3DT4H5M6S" or "P2W"
+func parseIsoDuration(duration string) (interval, bool) {
+	result := interval{days: unset}
+	if len(duration) < 2 || duration[0] != 'P' {
+		return result, false
+	}
+
+	isTime := false
+	hasElement := false
+	for pos := 1; pos < len(duration); {
+		if duration[pos] == 'T' {
+			if isTime {
+				return result, false
+			}
+			isTime = true
+			pos++
+			continue
+		}
+		start := pos
+		for pos < len(duration) && isDigit(duration[pos]) {
+			pos++
+		}
+		if start == pos || pos == len(duration) {
+			return result, false
+		}
+		value := atoi(duration[start:pos])
+		switch designator := duration[pos]; {
+		case !isTime && designator == 'Y':
+			result.y = value
+		case !isTime && designator == 'M':
+			result.m = value
+		case !isTime && designator == 'W':
+			result.d += value * 7
+		case !isTime && designator == 'D':
+			result.d += value
+		case isTime && designator == 'H':
+			result.h = value
+		case isTime && designator == 'M':
+			result.i = value
+		case isTime && designator == 'S':
+			result.s = value
+		default:
+			return result, false
+		}
+		hasElement = true
+		pos++
+	}
+	return result, hasElement
+}
+
+// -------------------------------------- DateInterval -------------------------------------- MARK: DateInterval
+
+func registerDateInterval(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.dateinterval.php
+	runtime.NewNativeClass(interpreter, "DateInterval").
+		AddProperty("$y", "public", intType, newIntLiteral(0)).
+		AddProperty("$m", "public", intType, newIntLiteral(0)).
+		AddProperty("$d", "public", intType, newIntLiteral(0)).
+		AddProperty("$h", "public", intType, newIntLiteral(0)).
+		AddProperty("$i", "public", intType, newIntLiteral(0)).
+		AddProperty("$s", "public", intType, newIntLiteral(0)).
+		AddProperty("$f", "public", []string{"float"}, ast.NewFloatingLiteralExpr(0, nil, 0)).
+		AddProperty("$invert", "public", intType, newIntLiteral(0)).
+		AddProperty("$days", "public", []string{"mixed"}, ast.NewBooleanLiteralExpr(0, nil, false)).
+		AddProperty("$from_string", "public", boolType, ast.NewBooleanLiteralExpr(0, nil, false)).
+		AddMethod("__construct", []ast.FunctionParameter{runtime.NewParam("$duration", "string")}, nil, dateIntervalConstruct).
+		AddMethod("format", []ast.FunctionParameter{runtime.NewParam("$format", "string")}, stringType, dateIntervalFormat).
+		AddStaticMethod("createFromDateString", []ast.FunctionParameter{runtime.NewParam("$datetime", "string")}, []string{"DateInterval", "false"}, dateIntervalCreateFromDateString).
+		Register()
+}
+
+func dateIntervalConstruct(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("DateInterval::__construct").AddParam("$duration", stringType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	duration := args[0].(*values.Str).Value
+	interval, ok := parseIsoDuration(duration)
+	if !ok {
+		return values.NewVoid(), newException("DateMalformedIntervalStringException", "Unknown or bad format (%s)", duration)
+	}
+	setInterval(object, interval)
+	return values.NewVoid(), nil
+}
+
+func dateIntervalFormat(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("DateInterval::format").AddParam("$format", stringType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(formatInterval(args[0].(*values.Str).Value, getInterval(object))), nil
+}
+
+func formatInterval(format string, interval interval) string {
+	// Spec: https://www.php.net/manual/en/dateinterval.format.php
+	var result strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			result.WriteByte(format[i])
+			continue
+		}
+		i++
+		switch format[i] {
+		case 'Y':
+			fmt.Fprintf(&result, "%02d", interval.y)
+		case 'y':
+			result.WriteString(strconv.Itoa(interval.y))
+		case 'M':
+			fmt.Fprintf(&result, "%02d", interval.m)
+		case 'm':
+			result.WriteString(strconv.Itoa(interval.m))
+		case 'D':
+			fmt.Fprintf(&result, "%02d", interval.d)
+		case 'd':
+			result.WriteString(strconv.Itoa(interval.d))
+		case 'a':
+			if interval.days == unset {
+				result.WriteString("(unknown)")
+			} else {
+				result.WriteString(strconv.Itoa(interval.days))
+			}
+		case 'H':
+			fmt.Fprintf(&result, "%02d", interval.h)
+		case 'h':
+			result.WriteString(strconv.Itoa(interval.h))
+		case 'I':
+			fmt.Fprintf(&result, "%02d", interval.i)
+		case 'i':
+			result.WriteString(strconv.Itoa(interval.i))
+		case 'S':
+			fmt.Fprintf(&result, "%02d", interval.s)
+		case 's':
+			result.WriteString(strconv.Itoa(interval.s))
+		case 'F':
+			fmt.Fprintf(&result, "%06d", interval.us)
+		case 'f':
+			result.WriteString(strconv.Itoa(interval.us))
+		case 'R':
+			if interval.invert {
+				result.WriteByte('-')
+			} else {
+				result.WriteByte('+')
+			}
+		case 'r':
+			if interval.invert {
+				result.WriteByte('-')
+			}
+		case '%':
+			result.WriteByte('%')
+		default:
+			result.WriteByte('%')
+			result.WriteByte(format[i])
+		}
+	}
+	return result.String()
+}
+
+func dateIntervalCreateFromDateString(_ *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("DateInterval::createFromDateString").AddParam("$datetime", stringType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return lib_date_interval_create_from_date_string("DateInterval::createFromDateString", args[0].(*values.Str).Value, context)
+}
+
+func lib_date_interval_create_from_date_string(functionName string, datetime string, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	parsed := parseTime(datetime)
+	if len(parsed.errors) > 0 {
+		err := parsed.errors[0]
+		context.Interpreter.PrintError(phpError.NewWarning(
+			"%s(): Unknown or bad format (%s) at position %d (%c): %s%s", functionName, datetime, err.position, err.char, err.message, context.InPosition(),
+		))
+		return values.NewBool(false), nil
+	}
+
+	relative := parsed.relative
+	return newIntervalObject(context, interval{
+		y: relative.year, m: relative.month, d: relative.day, h: relative.hour, i: relative.minute, s: relative.second, us: relative.microsecond,
+		days: unset,
+	})
+}
+
+// -------------------------------------- date_interval_create_from_date_string -------------------------------------- MARK: date_interval_create_from_date_string
+
+func nativeFn_date_interval_create_from_date_string(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-interval-create-from-date-string.php
+	args, err := funcParamValidator.NewValidator("date_interval_create_from_date_string").AddParam("$datetime", stringType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return lib_date_interval_create_from_date_string("date_interval_create_from_date_string", args[0].(*values.Str).Value, context)
+}
+
+// -------------------------------------- date_interval_format -------------------------------------- MARK: date_interval_format
+
+func nativeFn_date_interval_format(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.date-interval-format.php
+	return callDateTimeMethod("date_interval_format", "DateInterval", dateIntervalFormat, args, context, "$format")
+}
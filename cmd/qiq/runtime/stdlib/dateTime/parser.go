@@ -0,0 +1,1000 @@
+package dateTime
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Marks a date or time field that is not given in the parsed string
+const unset = math.MinInt
+
+// "first day of" and "last day of" the month
+const (
+	firstDayOfMonth = 1
+	lastDayOfMonth  = 2
+)
+
+// "second monday of" and "last monday of" the month
+const (
+	nthWeekdayOfMonth  = 1
+	lastWeekdayOfMonth = 2
+)
+
+// Relative offset that is applied after the absolute date and time are resolved (e.g. "+1 day")
+type relativeTime struct {
+	year, month, day, hour, minute, second, microsecond int
+	// "monday" or "next friday": The day of the week (0 = Sunday) to move to.
+	// The behavior defines if the current day is skipped (0), is kept (1) or if the day is in the current week (2).
+	haveWeekday     bool
+	weekday         int
+	weekdayBehavior int
+	// "+3 weekdays": Number of business days
+	haveWeekdays   bool
+	weekdays       int
+	dayOfMonth     int
+	weekdayOfMonth int
+}
+
+type parseMessage struct {
+	position int
+	char     byte
+	message  string
+}
+
+// Result of parsing a date/time string like "2024-01-31 10:00:00 Europe/Berlin +1 day".
+// Fields that are not given in the string are unset and filled from the base time when the result is resolved.
+type parsedTime struct {
+	input                             string
+	year, month, day                  int
+	hour, minute, second, microsecond int
+	timezone                          *timezone
+	relative                          relativeTime
+	haveDate, haveTime, haveRelative  bool
+	// The string is a Unix timestamp like "@1700000000"
+	isTimestamp bool
+	errors      []parseMessage
+	warnings    []parseMessage
+}
+
+type timeParser struct {
+	parsed *parsedTime
+	input  string
+	pos    int
+}
+
+// Units of relative offsets and their factor applied to the field of relativeTime
+var relativeUnits = map[string]struct {
+	field  string
+	factor int
+}{
+	"usec": {"microsecond", 1}, "usecs": {"microsecond", 1}, "µsec": {"microsecond", 1}, "µsecs": {"microsecond", 1},
+	"microsecond": {"microsecond", 1}, "microseconds": {"microsecond", 1},
+	"msec": {"microsecond", 1000}, "msecs": {"microsecond", 1000}, "millisecond": {"microsecond", 1000}, "milliseconds": {"microsecond", 1000},
+	"sec": {"second", 1}, "secs": {"second", 1}, "second": {"second", 1}, "seconds": {"second", 1},
+	"min": {"minute", 1}, "mins": {"minute", 1}, "minute": {"minute", 1}, "minutes": {"minute", 1},
+	"hour": {"hour", 1}, "hours": {"hour", 1},
+	"day": {"day", 1}, "days": {"day", 1},
+	"week": {"day", 7}, "weeks": {"day", 7},
+	"fortnight": {"day", 14}, "fortnights": {"day", 14}, "forthnight": {"day", 14}, "forthnights": {"day", 14},
+	"month": {"month", 1}, "months": {"month", 1},
+	"year": {"year", 1}, "years": {"year", 1},
+	"weekday": {"weekday", 1}, "weekdays": {"weekday", 1},
+}
+
+// Relative texts like "next" and "third" and their amount
+var relativeTexts = map[string]int{
+	"last": -1, "previous": -1, "this": 0, "next": 1,
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "sixth": 6,
+	"seventh": 7, "eight": 8, "eighth": 8, "ninth": 9, "tenth": 10, "eleventh": 11, "twelfth": 12,
+}
+
+var weekdayNumbers = map[string]int{
+	"sunday": 0, "sun": 0, "monday": 1, "mon": 1, "tuesday": 2, "tue": 2, "tues": 2,
+	"wednesday": 3, "wed": 3, "wednes": 3, "thursday": 4, "thu": 4, "thur": 4, "thurs": 4,
+	"friday": 5, "fri": 5, "saturday": 6, "sat": 6,
+}
+
+var monthNumbers = map[string]int{
+	"january": 1, "jan": 1, "february": 2, "feb": 2, "march": 3, "mar": 3, "april": 4, "apr": 4,
+	"may": 5, "june": 6, "jun": 6, "july": 7, "jul": 7, "august": 8, "aug": 8,
+	"september": 9, "sep": 9, "sept": 9, "october": 10, "oct": 10, "november": 11, "nov": 11, "december": 12, "dec": 12,
+}
+
+// Parse a date/time string in one of the formats supported by strtotime(), DateTime::__construct() and DateTime::modify()
+func parseTime(input string) *parsedTime {
+	// Spec: https://www.php.net/manual/en/datetime.formats.php
+	parser := &timeParser{
+		input: input,
+		parsed: &parsedTime{
+			input: input,
+			year:  unset, month: unset, day: unset,
+			hour: unset, minute: unset, second: unset, microsecond: unset,
+		},
+	}
+	if strings.TrimSpace(input) == "" {
+		parser.addError(0, "Empty string")
+		return parser.parsed
+	}
+	parser.parse()
+
+	parsed := parser.parsed
+	if parsed.haveDate && parsed.year != unset && parsed.month != unset && parsed.day != unset &&
+		(parsed.month < 1 || parsed.month > 12 || parsed.day < 1 || parsed.day > DaysIn(time.Month(parsed.month), parsed.year)) {
+		parsed.warnings = append(parsed.warnings, parseMessage{position: len(input), message: "The parsed date was invalid"})
+	}
+	return parsed
+}
+
+func (parser *timeParser) parse() {
+	for parser.pos < len(parser.input) {
+		char := parser.input[parser.pos]
+		switch {
+		case char == ' ' || char == '\t' || char == '\n' || char == ',':
+			parser.pos++
+		case char == '@':
+			parser.parseTimestamp()
+		case isDigit(char):
+			parser.parseNumber()
+		case char == '+' || char == '-':
+			parser.parseSigned()
+		case isLetter(char):
+			parser.parseWord()
+		default:
+			parser.addError(parser.pos, "Unexpected character")
+			parser.pos++
+		}
+	}
+}
+
+func (parser *timeParser) addError(position int, message string) {
+	var char byte
+	if position < len(parser.input) {
+		char = parser.input[position]
+	}
+	parser.parsed.errors = append(parser.parsed.errors, parseMessage{position: position, char: char, message: message})
+}
+
+func (parser *timeParser) peek(offset int) byte {
+	if parser.pos+offset < len(parser.input) {
+		return parser.input[parser.pos+offset]
+	}
+	return 0
+}
+
+// Read a run of digits and return them with their count
+func (parser *timeParser) readDigits(maxLength int) (string, int) {
+	start := parser.pos
+	for parser.pos < len(parser.input) && isDigit(parser.input[parser.pos]) && parser.pos-start < maxLength {
+		parser.pos++
+	}
+	return parser.input[start:parser.pos], parser.pos - start
+}
+
+// Count the digits at the current position without consuming them
+func (parser *timeParser) countDigits(offset int) int {
+	count := 0
+	for isDigit(parser.peek(offset + count)) {
+		count++
+	}
+	return count
+}
+
+func (parser *timeParser) skipSpaces() {
+	for parser.pos < len(parser.input) && (parser.input[parser.pos] == ' ' || parser.input[parser.pos] == '\t') {
+		parser.pos++
+	}
+}
+
+// Skip the separators between the parts of a textual date like "5-Jan-2024" or "Jan. 5"
+func (parser *timeParser) skipDateSeparators() {
+	for char := parser.peek(0); char == ' ' || char == '\t' || char == '.' || char == '-'; char = parser.peek(0) {
+		parser.pos++
+	}
+}
+
+// Skip the suffix of an ordinal day like "1st" or "22nd"
+func (parser *timeParser) skipOrdinalSuffix() {
+	if parser.pos+2 > len(parser.input) || isLetter(parser.peek(2)) {
+		return
+	}
+	switch strings.ToLower(parser.input[parser.pos : parser.pos+2]) {
+	case "st", "nd", "rd", "th":
+		parser.pos += 2
+	}
+}
+
+// Read a word of letters without consuming it
+func (parser *timeParser) peekWord() string {
+	end := parser.pos
+	for end < len(parser.input) && (isLetter(parser.input[end]) || parser.input[end] >= 0x80) {
+		end++
+	}
+	return strings.ToLower(parser.input[parser.pos:end])
+}
+
+func (parser *timeParser) setDate(position int, year, month, day int) {
+	if parser.parsed.haveDate {
+		parser.addError(position, "Double date specification")
+		return
+	}
+	parser.parsed.haveDate = true
+	parser.parsed.year, parser.parsed.month, parser.parsed.day = year, month, day
+}
+
+func (parser *timeParser) setTime(position int, hour, minute, second, microsecond int) {
+	if parser.parsed.haveTime {
+		parser.addError(position, "Double time specification")
+		return
+	}
+	parser.parsed.haveTime = true
+	parser.parsed.hour, parser.parsed.minute, parser.parsed.second, parser.parsed.microsecond = hour, minute, second, microsecond
+}
+
+// Reset the time to midnight for keywords like "today" and "monday". A time can still be given (e.g. "tomorrow 10:00").
+func (parser *timeParser) unhaveTime() {
+	parser.parsed.haveTime = false
+	parser.parsed.hour, parser.parsed.minute, parser.parsed.second, parser.parsed.microsecond = 0, 0, 0, 0
+}
+
+func (parser *timeParser) setTimezone(position int, timezone *timezone) {
+	if parser.parsed.timezone != nil {
+		parser.addError(position, "Double timezone specification")
+		return
+	}
+	parser.parsed.timezone = timezone
+}
+
+func (parser *timeParser) addRelative(unit string, amount int) {
+	parser.parsed.haveRelative = true
+	relative := &parser.parsed.relative
+	switch relativeUnits[unit].field {
+	case "microsecond":
+		relative.microsecond += amount * relativeUnits[unit].factor
+	case "second":
+		relative.second += amount * relativeUnits[unit].factor
+	case "minute":
+		relative.minute += amount * relativeUnits[unit].factor
+	case "hour":
+		relative.hour += amount * relativeUnits[unit].factor
+	case "day":
+		relative.day += amount * relativeUnits[unit].factor
+	case "month":
+		relative.month += amount * relativeUnits[unit].factor
+	case "year":
+		relative.year += amount * relativeUnits[unit].factor
+	case "weekday":
+		relative.haveWeekdays = true
+		relative.weekdays += amount
+	}
+}
+
+// Move to the given day of the week: "next monday" is the amount 1, "last monday" the amount -1
+func (parser *timeParser) setWeekdayRelative(weekday int, amount int, behavior int) {
+	parser.parsed.haveRelative = true
+	relative := &parser.parsed.relative
+	if amount > 0 {
+		amount--
+	}
+	relative.day += amount * 7
+	relative.haveWeekday = true
+	relative.weekday = weekday
+	relative.weekdayBehavior = behavior
+}
+
+// Read the unit of a relative offset like "days" in "+3 days" or "friday" in "+1 friday"
+func (parser *timeParser) parseUnit(amount int) bool {
+	position := parser.pos
+	parser.skipSpaces()
+	word := parser.peekWord()
+	if _, isUnit := relativeUnits[word]; isUnit {
+		parser.pos += len(word)
+		parser.addRelative(word, amount)
+		return true
+	}
+	if weekday, isWeekday := weekdayNumbers[word]; isWeekday {
+		parser.pos += len(word)
+		parser.setWeekdayRelative(weekday, amount, 0)
+		return true
+	}
+	parser.pos = position
+	return false
+}
+
+// Convert a two digit year like "99" into 1999 and "05" into 2005
+func expandYear(digits string) int {
+	year := atoi(digits)
+	if len(digits) > 2 {
+		return year
+	}
+	if year < 70 {
+		return 2000 + year
+	}
+	return 1900 + year
+}
+
+// Parse the fraction of a second (e.g. ".5" is 500000 microseconds)
+func (parser *timeParser) parseFraction() int {
+	digits, count := parser.readDigits(math.MaxInt)
+	if count > 6 {
+		digits = digits[:6]
+	}
+	return atoi((digits + "000000")[:6])
+}
+
+// "@1700000000" or "@1700000000.5": Unix timestamp in UTC
+func (parser *timeParser) parseTimestamp() {
+	start := parser.pos
+	parser.pos++
+	sign := 1
+	if parser.peek(0) == '-' || parser.peek(0) == '+' {
+		if parser.peek(0) == '-' {
+			sign = -1
+		}
+		parser.pos++
+	}
+	digits, count := parser.readDigits(math.MaxInt)
+	if count == 0 {
+		parser.addError(start, "Unexpected character")
+		return
+	}
+	microsecond := 0
+	if parser.peek(0) == '.' && isDigit(parser.peek(1)) {
+		parser.pos++
+		microsecond = sign * parser.parseFraction()
+	}
+	// The timestamp is a relative offset to the Unix epoch
+	parser.setDate(start, 1970, 1, 1)
+	parser.setTime(start, 0, 0, 0, 0)
+	parser.setTimezone(start, newOffsetTimezone(0))
+	parser.parsed.isTimestamp = true
+	parser.parsed.haveRelative = true
+	parser.parsed.relative.second += sign * atoi(digits)
+	parser.parsed.relative.microsecond += microsecond
+}
+
+// Date, time or relative offset starting with a number
+func (parser *timeParser) parseNumber() {
+	start := parser.pos
+	digits, count := parser.readDigits(math.MaxInt)
+	next := parser.peek(0)
+
+	switch {
+	case count == 4 && (next == 'W' || (next == '-' && parser.peek(1) == 'W')):
+		// ISO week date: "2024W05", "2024-W05-3" and "2024W053"
+		parser.parseIsoWeek(start, atoi(digits))
+
+	case count == 4 && next == '.' && parser.countDigits(1) == 3:
+		// Day of the year: "2024.032"
+		parser.pos++
+		dayOfYear, _ := parser.readDigits(3)
+		parser.setDate(start, atoi(digits), 1, atoi(dayOfYear))
+
+	case count == 4 && next == '-' && isLetter(parser.peek(1)):
+		// "2024-Jan-05"
+		parser.pos++
+		month, isMonth := monthNumbers[parser.peekWord()]
+		if !isMonth {
+			parser.addError(parser.pos, "Unexpected character")
+			return
+		}
+		parser.pos += len(parser.peekWord())
+		if parser.peek(0) != '-' || !isDigit(parser.peek(1)) {
+			parser.addError(parser.pos, "Unexpected character")
+			return
+		}
+		parser.pos++
+		day, _ := parser.readDigits(2)
+		parser.setDate(start, atoi(digits), month, atoi(day))
+
+	case count == 4 && (next == '-' || next == '/') && isDigit(parser.peek(1)):
+		// "2024-01-31", "2024-01" and "2024/01/31"
+		parser.pos++
+		month, _ := parser.readDigits(2)
+		day := "1"
+		if parser.peek(0) == next && isDigit(parser.peek(1)) {
+			parser.pos++
+			day, _ = parser.readDigits(2)
+		} else if next == '/' {
+			parser.addError(parser.pos, "Unexpected character")
+			return
+		}
+		parser.setDate(start, atoi(digits), atoi(month), atoi(day))
+
+	case count <= 2 && next == '/' && isDigit(parser.peek(1)):
+		// American "1/31/2024" and "1/31"
+		parser.pos++
+		day, _ := parser.readDigits(2)
+		year := unset
+		if parser.peek(0) == '/' && isDigit(parser.peek(1)) {
+			parser.pos++
+			yearDigits, _ := parser.readDigits(4)
+			year = expandYear(yearDigits)
+		}
+		parser.setDate(start, year, atoi(digits), atoi(day))
+
+	case count <= 2 && (next == '.' || next == '-') && isDigit(parser.peek(1)) && parser.isThreePartDate(next):
+		parser.pos++
+		month, _ := parser.readDigits(2)
+		parser.pos++
+		year, yearLength := parser.readDigits(4)
+		if next == '-' && yearLength != 4 {
+			// "24-01-31" (year, month and day)
+			parser.setDate(start, expandYear(digits), atoi(month), atoi(year))
+			return
+		}
+		// "31.01.2024", "31.01.24" and "31-01-2024"
+		parser.setDate(start, expandYear(year), atoi(month), atoi(digits))
+
+	case count == 8 && !isDigit(next) && next != ':':
+		// "20240131"
+		parser.setDate(start, atoi(digits[:4]), atoi(digits[4:6]), atoi(digits[6:]))
+
+	case count == 7 && !isDigit(next):
+		// Day of the year: "2024032"
+		parser.setDate(start, atoi(digits[:4]), 1, atoi(digits[4:]))
+
+	case count <= 2 && (next == ':' || next == '.') && isDigit(parser.peek(1)):
+		// "10:30", "10:30:15", "10:30:15.5" and "10.30"
+		parser.pos = start
+		parser.parseClock()
+
+	default:
+		afterNumber := parser.pos
+		parser.skipSpaces()
+		word := parser.peekWord()
+		if (word == "am" || word == "pm" || word == "a" || word == "p") && count <= 2 {
+			// "10am" and "10 pm"
+			parser.pos = start
+			parser.parseClock()
+			return
+		}
+		if parser.parseUnit(atoi(digits)) {
+			// "3 days"
+			return
+		}
+		if count <= 2 && parser.parseDayAndTextualMonth(start, atoi(digits)) {
+			return
+		}
+		parser.pos = afterNumber
+		if count == 4 {
+			parser.parseYearOrTime(start, digits)
+			return
+		}
+		parser.addError(start, "Unexpected character")
+	}
+}
+
+// Check if the date has three parts like "31.01.2024" and not two like "10.30" (time)
+func (parser *timeParser) isThreePartDate(separator byte) bool {
+	offset := 1
+	for offset < 3 && isDigit(parser.peek(offset)) {
+		offset++
+	}
+	return parser.peek(offset) == separator && isDigit(parser.peek(offset+1))
+}
+
+// Four digits without separator are a time like "1030" or a year like "2024" if the time is already given
+func (parser *timeParser) parseYearOrTime(start int, digits string) {
+	hour, minute := atoi(digits[:2]), atoi(digits[2:])
+	if parser.parsed.haveTime || hour > 24 || minute > 59 {
+		parser.parsed.year = atoi(digits)
+		return
+	}
+	parser.setTime(start, hour, minute, 0, 0)
+}
+
+// "2024W05", "2024-W05", "2024W053" and "2024-W05-3": The day of the ISO week is applied as relative offset to January 1st
+func (parser *timeParser) parseIsoWeek(start int, year int) {
+	if parser.peek(0) == '-' {
+		parser.pos++
+	}
+	parser.pos++
+	week, count := parser.readDigits(2)
+	if count != 2 {
+		parser.addError(parser.pos, "Unexpected character")
+		return
+	}
+	weekday := 1
+	if parser.peek(0) == '-' && isDigit(parser.peek(1)) {
+		parser.pos++
+	}
+	if isDigit(parser.peek(0)) {
+		day, _ := parser.readDigits(1)
+		weekday = atoi(day)
+	}
+	parser.setDate(start, year, 1, 1)
+
+	// The first ISO week is the week with the first Thursday of the year
+	firstWeekday := int(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC).Weekday())
+	offset := -firstWeekday
+	if firstWeekday > 4 {
+		offset = 7 - firstWeekday
+	}
+	parser.parsed.haveRelative = true
+	parser.parsed.relative.day += offset + (atoi(week)-1)*7 + weekday
+}
+
+// "5 January 2024", "5th Jan", "05-Jan-2024" and "5 January"
+func (parser *timeParser) parseDayAndTextualMonth(start int, day int) bool {
+	position := parser.pos
+	parser.skipOrdinalSuffix()
+	parser.skipDateSeparators()
+	word := parser.peekWord()
+	month, isMonth := monthNumbers[word]
+	if !isMonth {
+		parser.pos = position
+		return false
+	}
+	parser.pos += len(word)
+	parser.setDate(start, parser.readTextualYear(), month, day)
+	return true
+}
+
+// "January", "Jan 2024", "January 5th", "Jan 5, 2024" and "Jan-05-2024"
+func (parser *timeParser) parseTextualMonth(start int, month int) {
+	afterMonth := parser.pos
+	parser.skipDateSeparators()
+	digits, count := parser.readDigits(math.MaxInt)
+	switch {
+	case count == 4 && parser.peek(0) != ':':
+		parser.setDate(start, atoi(digits), month, 1)
+	case count >= 1 && count <= 2 && parser.peek(0) != ':' && parser.peek(0) != '.':
+		parser.skipOrdinalSuffix()
+		parser.setDate(start, parser.readTextualYear(), month, atoi(digits))
+	default:
+		parser.pos = afterMonth
+		parser.setDate(start, unset, month, unset)
+	}
+}
+
+// Read the year after a textual date like "2024" in "Jan 5, 2024".
+// Numbers that belong to a time (e.g. "Jan 5 10:00" and "Jan 5 10am") or to a relative offset are not read.
+func (parser *timeParser) readTextualYear() int {
+	position := parser.pos
+	for char := parser.peek(0); char == ' ' || char == '\t' || char == ',' || char == '.' || char == '-'; char = parser.peek(0) {
+		parser.pos++
+	}
+	digits, count := parser.readDigits(4)
+	if count == 0 || isDigit(parser.peek(0)) || parser.peek(0) == ':' || (parser.peek(0) == '.' && isDigit(parser.peek(1))) {
+		parser.pos = position
+		return unset
+	}
+	afterYear := parser.pos
+	parser.skipSpaces()
+	word := parser.peekWord()
+	_, isUnit := relativeUnits[word]
+	if isUnit || word == "am" || word == "pm" || word == "a" || word == "p" {
+		parser.pos = position
+		return unset
+	}
+	parser.pos = afterYear
+	return expandYear(digits)
+}
+
+// "10:30", "10:30:15", "10:30:15.123456", "10am" and "10:30 pm"
+func (parser *timeParser) parseClock() {
+	start := parser.pos
+	hourDigits, _ := parser.readDigits(2)
+	hour, minute, second, microsecond := atoi(hourDigits), 0, 0, 0
+
+	if separator := parser.peek(0); (separator == ':' || separator == '.') && isDigit(parser.peek(1)) {
+		parser.pos++
+		minuteDigits, _ := parser.readDigits(2)
+		minute = atoi(minuteDigits)
+		if parser.peek(0) == separator && isDigit(parser.peek(1)) {
+			parser.pos++
+			secondDigits, _ := parser.readDigits(2)
+			second = atoi(secondDigits)
+			if (parser.peek(0) == '.' || parser.peek(0) == ',') && isDigit(parser.peek(1)) {
+				parser.pos++
+				microsecond = parser.parseFraction()
+			}
+		}
+	}
+
+	// 12-hour clock
+	afterTime := parser.pos
+	parser.skipSpaces()
+	word := parser.peekWord()
+	switch word {
+	case "am", "a", "pm", "p":
+		if hour < 1 || hour > 12 {
+			parser.addError(start, "Unexpected character")
+			return
+		}
+		parser.pos += len(word)
+		if parser.peek(0) == '.' && (word == "a" || word == "p") && (parser.peek(1) == 'm' || parser.peek(1) == 'M') {
+			// "10 a.m."
+			parser.pos += 2
+			if parser.peek(0) == '.' {
+				parser.pos++
+			}
+		}
+		if hour == 12 {
+			hour = 0
+		}
+		if word[0] == 'p' {
+			hour += 12
+		}
+	default:
+		parser.pos = afterTime
+		if hour > 24 || minute > 59 || second > 60 {
+			parser.addError(start, "Unexpected character")
+			return
+		}
+	}
+	parser.setTime(start, hour, minute, second, microsecond)
+}
+
+// ISO 8601 time without colons after the "T": "T1030" and "T103015.5"
+func (parser *timeParser) parseCompactClock() {
+	start := parser.pos
+	hour, _ := parser.readDigits(2)
+	minute, _ := parser.readDigits(2)
+	second := "0"
+	if parser.countDigits(0) >= 2 {
+		second, _ = parser.readDigits(2)
+	}
+	microsecond := 0
+	if (parser.peek(0) == '.' || parser.peek(0) == ',') && isDigit(parser.peek(1)) {
+		parser.pos++
+		microsecond = parser.parseFraction()
+	}
+	if atoi(hour) > 24 || atoi(minute) > 59 || atoi(second) > 60 {
+		parser.addError(start, "Unexpected character")
+		return
+	}
+	parser.setTime(start, atoi(hour), atoi(minute), atoi(second), microsecond)
+}
+
+// Relative offset like "+1 day" and "-2 weeks" or an UTC offset like "+02:00"
+func (parser *timeParser) parseSigned() {
+	start := parser.pos
+	sign := 1
+	for parser.peek(0) == '+' || parser.peek(0) == '-' {
+		if parser.peek(0) == '-' {
+			sign = -sign
+		}
+		parser.pos++
+	}
+	afterSign := parser.pos
+	digits, count := parser.readDigits(math.MaxInt)
+	if count > 0 && parser.parseUnit(sign*atoi(digits)) {
+		return
+	}
+
+	parser.pos = afterSign
+	if offset, length, ok := parseOffset(parser.input[afterSign-1:]); ok {
+		parser.pos = afterSign - 1 + length
+		parser.setTimezone(start, newOffsetTimezone(offset))
+		return
+	}
+	parser.addError(start, "Unexpected character")
+}
+
+// Keyword like "now" and "tomorrow", relative offset like "next month", day of the week,
+// textual month or timezone like "Europe/Berlin"
+func (parser *timeParser) parseWord() {
+	start := parser.pos
+
+	// ISO 8601 separator between date and time: "2024-01-31T10:00:00" and "20240131T103000"
+	if (parser.input[start] == 'T' || parser.input[start] == 't') && isDigit(parser.peek(1)) {
+		parser.pos++
+		if parser.countDigits(0) >= 4 {
+			parser.parseCompactClock()
+		} else {
+			parser.parseClock()
+		}
+		return
+	}
+
+	word := parser.peekWord()
+	switch word {
+	case "now":
+		parser.pos += len(word)
+		return
+	case "today", "midnight":
+		parser.pos += len(word)
+		parser.unhaveTime()
+		return
+	case "noon":
+		parser.pos += len(word)
+		parser.unhaveTime()
+		parser.setTime(start, 12, 0, 0, 0)
+		return
+	case "tomorrow", "yesterday":
+		parser.pos += len(word)
+		parser.parsed.haveRelative = true
+		if word == "tomorrow" {
+			parser.parsed.relative.day = 1
+		} else {
+			parser.parsed.relative.day = -1
+		}
+		parser.unhaveTime()
+		return
+	case "ago":
+		parser.pos += len(word)
+		relative := &parser.parsed.relative
+		relative.year, relative.month, relative.day = -relative.year, -relative.month, -relative.day
+		relative.hour, relative.minute, relative.second, relative.microsecond = -relative.hour, -relative.minute, -relative.second, -relative.microsecond
+		relative.weekdays = -relative.weekdays
+		return
+	}
+
+	if parser.parseRelativeText(word) {
+		return
+	}
+	if weekday, isWeekday := weekdayNumbers[word]; isWeekday {
+		// "monday" is today if today is a Monday
+		parser.pos += len(word)
+		parser.parsed.haveRelative = true
+		relative := &parser.parsed.relative
+		relative.haveWeekday = true
+		relative.weekday = weekday
+		if relative.weekdayBehavior != 2 {
+			relative.weekdayBehavior = 1
+		}
+		parser.unhaveTime()
+		return
+	}
+	if month, isMonth := monthNumbers[word]; isMonth {
+		parser.pos += len(word)
+		parser.parseTextualMonth(start, month)
+		return
+	}
+
+	parser.parseTimezone()
+}
+
+// Relative text like "next month", "last friday", "this week", "third day", "first day of" and "last monday of"
+func (parser *timeParser) parseRelativeText(word string) bool {
+	start := parser.pos
+	amount, isRelativeText := relativeTexts[word]
+	if !isRelativeText {
+		return false
+	}
+	behavior := 0
+	if word == "this" {
+		behavior = 1
+	}
+
+	parser.pos += len(word)
+	parser.skipSpaces()
+	unit := parser.peekWord()
+	afterUnit := parser.pos + len(unit)
+	parser.pos = afterUnit
+	parser.skipSpaces()
+	isFollowedByOf := parser.peekWord() == "of"
+	weekday, isWeekday := weekdayNumbers[unit]
+	_, isUnit := relativeUnits[unit]
+
+	switch {
+	case unit == "day" && isFollowedByOf && (word == "first" || word == "last"):
+		// "first day of" and "last day of" the month
+		parser.pos += len("of")
+		parser.parsed.haveRelative = true
+		if word == "first" {
+			parser.parsed.relative.dayOfMonth = firstDayOfMonth
+		} else {
+			parser.parsed.relative.dayOfMonth = lastDayOfMonth
+		}
+
+	case isWeekday && isFollowedByOf && (amount > 0 || word == "last"):
+		// "first monday of" and "last friday of" the month
+		parser.pos += len("of")
+		if amount > 0 {
+			parser.parsed.relative.weekdayOfMonth = nthWeekdayOfMonth
+			parser.setWeekdayRelative(weekday, amount, 1)
+		} else {
+			parser.parsed.relative.weekdayOfMonth = lastWeekdayOfMonth
+			parser.setWeekdayRelative(weekday, amount, behavior)
+		}
+		parser.unhaveTime()
+
+	case unit == "week":
+		// "next week" is relative to the Monday of the week
+		parser.pos = afterUnit
+		parser.addRelative(unit, amount)
+		relative := &parser.parsed.relative
+		relative.weekdayBehavior = 2
+		if !relative.haveWeekday {
+			relative.haveWeekday = true
+			relative.weekday = 1
+		}
+
+	case isWeekday:
+		// "next monday" and "last friday"
+		parser.pos = afterUnit
+		parser.setWeekdayRelative(weekday, amount, behavior)
+		parser.unhaveTime()
+
+	case isUnit:
+		// "next month" and "third day"
+		parser.pos = afterUnit
+		parser.addRelative(unit, amount)
+		if relativeUnits[unit].field == "weekday" {
+			parser.unhaveTime()
+		}
+
+	default:
+		parser.pos = start
+		return false
+	}
+	return true
+}
+
+// Timezone abbreviation like "CEST" or identifier like "America/Argentina/Buenos_Aires"
+func (parser *timeParser) parseTimezone() {
+	start := parser.pos
+	for parser.pos < len(parser.input) {
+		char := parser.input[parser.pos]
+		if isLetter(char) || char == '_' || char == '/' ||
+			(parser.pos > start && (isDigit(char) || char == '-' || char == '+') && strings.Contains(parser.input[start:parser.pos], "/")) {
+			parser.pos++
+			continue
+		}
+		break
+	}
+	name := parser.input[start:parser.pos]
+
+	timezone, found := loadTimezone(name)
+	if !found {
+		parser.addError(start, "The timezone could not be found in the database")
+		return
+	}
+	// Abbreviations and "UTC" can be followed by an offset: "GMT+02:00"
+	if timezone.kind != timezoneTypeIdentifier || name == "UTC" {
+		if offset, length, ok := parseOffset(parser.input[parser.pos:]); ok {
+			parser.pos += length
+			timezone = newOffsetTimezone(offset)
+		}
+	}
+	parser.setTimezone(start, timezone)
+}
+
+// Get the message of the first error in the form of PHP's parse errors
+func (parsed *parsedTime) errorMessage() string {
+	err := parsed.errors[0]
+	char := string(err.char)
+	if err.position >= len(parsed.input) {
+		char = "\x00"
+	}
+	return fmt.Sprintf("Failed to parse time string (%s) at position %d (%s): %s", parsed.input, err.position, char, err.message)
+}
+
+// Compute the time of the parsed string for strtotime() and DateTime::__construct().
+// Fields that are not given are taken from the base time in the given timezone. A date without a time is at midnight.
+// If the string contains a timezone, it replaces the given timezone.
+func (parsed *parsedTime) resolve(base time.Time, timezone *timezone) (time.Time, *timezone) {
+	if parsed.timezone != nil {
+		timezone = parsed.timezone
+	}
+	base = base.In(timezone.location)
+
+	hour, minute, second, microsecond := parsed.hour, parsed.minute, parsed.second, parsed.microsecond
+	if parsed.haveDate && !parsed.haveTime {
+		hour, minute, second, microsecond = 0, 0, 0, 0
+	}
+	// The microseconds of the base time are only kept if no field is given (e.g. "+1 day")
+	if microsecond == unset && (parsed.year != unset || parsed.month != unset || parsed.day != unset || hour != unset || minute != unset || second != unset) {
+		microsecond = 0
+	}
+
+	year, month, day := base.Date()
+	return parsed.compute(
+		valueOr(parsed.year, year), valueOr(parsed.month, int(month)), valueOr(parsed.day, day),
+		valueOr(hour, base.Hour()), valueOr(minute, base.Minute()), valueOr(second, base.Second()),
+		valueOr(microsecond, base.Nanosecond()/1000), timezone.location,
+	), timezone
+}
+
+// Apply the parsed string to the date for DateTime::modify().
+// Unlike resolve(), a date without a time keeps the time and a timezone is ignored, except for Unix timestamps ("@1700000000").
+func (parsed *parsedTime) apply(date time.Time, timezone *timezone) (time.Time, *timezone) {
+	if parsed.isTimestamp {
+		timezone = parsed.timezone
+	}
+	date = date.In(timezone.location)
+
+	year, month, day := date.Date()
+	hour, minute, second, microsecond := date.Hour(), date.Minute(), date.Second(), date.Nanosecond()/1000
+	if parsed.hour != unset {
+		hour, minute, second = parsed.hour, valueOr(parsed.minute, 0), valueOr(parsed.second, 0)
+		if parsed.minute == unset {
+			second = 0
+		}
+	}
+	return parsed.compute(
+		valueOr(parsed.year, year), valueOr(parsed.month, int(month)), valueOr(parsed.day, day),
+		hour, minute, second, valueOr(parsed.microsecond, microsecond), timezone.location,
+	), timezone
+}
+
+// Compute the time from the wall clock fields and apply the relative offset in the same order as PHP
+func (parsed *parsedTime) compute(year, month, day, hour, minute, second, microsecond int, location *time.Location) time.Time {
+	relative := parsed.relative
+
+	// "first monday of next month" starts at the first day of the month
+	switch relative.weekdayOfMonth {
+	case nthWeekdayOfMonth:
+		day, month, relative.month = 1, month+relative.month, 0
+	case lastWeekdayOfMonth:
+		day, month, relative.month = 1, month+relative.month+1, 0
+	}
+	// The day of the base date must not overflow the month: "last day of february" on January 31st
+	if relative.dayOfMonth != 0 {
+		day = 1
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if relative.haveWeekday {
+		date = relative.adjustWeekday(date)
+	}
+
+	// The day of the month is replaced before the date is normalized: "last day of next month" on January 31st is in February
+	year, month, day = date.Year()+relative.year, int(date.Month())+relative.month, date.Day()+relative.day
+	switch relative.dayOfMonth {
+	case firstDayOfMonth:
+		day = 1
+	case lastDayOfMonth:
+		day, month = 0, month+1
+	}
+	date = time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if relative.haveWeekdays {
+		date = addWeekdays(date, relative.weekdays)
+	}
+
+	result := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, second, microsecond*1000, location)
+	// Units of time are added as elapsed time
+	seconds := int64(relative.hour)*3600 + int64(relative.minute)*60 + int64(relative.second)
+	if seconds != 0 || relative.microsecond != 0 {
+		result = time.Unix(result.Unix()+seconds, int64(result.Nanosecond())+int64(relative.microsecond)*1000).In(location)
+	}
+	return result
+}
+
+// Move the date to the day of the week of "monday", "next friday" or "sunday this week"
+func (relative relativeTime) adjustWeekday(date time.Time) time.Time {
+	currentWeekday := int(date.Weekday())
+	weekday := relative.weekday
+	if relative.weekdayBehavior == 2 {
+		// The week starts on Monday, so "this week" on a Sunday is the week that ends today
+		if currentWeekday == 0 && weekday != 0 {
+			weekday -= 7
+		}
+		if weekday == 0 && currentWeekday != 0 {
+			weekday = 7
+		}
+		return date.AddDate(0, 0, weekday-currentWeekday)
+	}
+
+	difference := weekday - currentWeekday
+	if (relative.day < 0 && difference < 0) || (relative.day >= 0 && difference <= -relative.weekdayBehavior) {
+		difference += 7
+	}
+	return date.AddDate(0, 0, difference)
+}
+
+// Add business days, skipping Saturdays and Sundays
+func addWeekdays(date time.Time, weekdays int) time.Time {
+	step := 1
+	if weekdays < 0 {
+		step, weekdays = -1, -weekdays
+	}
+	for weekdays > 0 {
+		date = date.AddDate(0, 0, step)
+		if date.Weekday() != time.Saturday && date.Weekday() != time.Sunday {
+			weekdays--
+		}
+	}
+	return date
+}
+
+func valueOr(value int, defaultValue int) int {
+	if value == unset {
+		return defaultValue
+	}
+	return value
+}
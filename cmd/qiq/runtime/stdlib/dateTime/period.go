@@ -0,0 +1,319 @@
+package dateTime
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/spl"
+	"QIQ/cmd/qiq/runtime/values"
+	"strings"
+	"time"
+)
+
+const (
+	periodExcludeStartDate = 1
+	periodIncludeEndDate   = 2
+)
+
+// Internal state of DatePeriod
+type datePeriod struct {
+	start *dateTime
+	// Class of the start date that is used for the iterated dates
+	startClass  string
+	interval    interval
+	end         *dateTime
+	recurrences int
+	options     int
+}
+
+func getDatePeriod(object *values.Object) (*datePeriod, phpError.Error) {
+	period, ok := object.Internal.(*datePeriod)
+	if !ok {
+		return nil, phpError.NewError("Uncaught Error: The DatePeriod object has not been correctly initialized by its constructor")
+	}
+	return period, nil
+}
+
+// Get the dates of the period
+func (period *datePeriod) dates() []*dateTime {
+	dates := []*dateTime{}
+	current := period.start
+	if period.options&periodExcludeStartDate == 0 {
+		if period.end == nil || period.isBeforeEnd(current) {
+			dates = append(dates, current)
+		}
+	}
+	for i := 0; period.end != nil || i < period.recurrences; i++ {
+		next := &dateTime{time: period.interval.addTo(current.time, 1), timezone: current.timezone}
+		// Stop at the end date and on intervals that do not move forward
+		if period.end != nil && !period.isBeforeEnd(next) || !next.time.After(current.time) {
+			break
+		}
+		dates = append(dates, next)
+		current = next
+	}
+	return dates
+}
+
+func (period *datePeriod) isBeforeEnd(date *dateTime) bool {
+	if period.options&periodIncludeEndDate != 0 {
+		return !date.time.After(period.end.time)
+	}
+	return date.time.Before(period.end.time)
+}
+
+// Set the internal state and the properties shown by var_dump
+func setDatePeriod(object *values.Object, period *datePeriod, context runtime.Context) phpError.Error {
+	object.Internal = period
+
+	start, err := newDateTimeObject(context, period.startClass, period.start)
+	if err != nil {
+		return err
+	}
+	object.SetProperty("$start", start)
+	object.SetProperty("$current", values.NewNull())
+	if period.end == nil {
+		object.SetProperty("$end", values.NewNull())
+	} else {
+		end, err := newDateTimeObject(context, period.startClass, period.end)
+		if err != nil {
+			return err
+		}
+		object.SetProperty("$end", end)
+	}
+	intervalObject, err := newIntervalObject(context, period.interval)
+	if err != nil {
+		return err
+	}
+	object.SetProperty("$interval", intervalObject)
+	recurrences := 1
+	if period.end == nil {
+		recurrences = period.recurrences
+		if period.options&periodExcludeStartDate == 0 {
+			recurrences++
+		}
+	}
+	object.SetProperty("$recurrences", values.NewInt(int64(recurrences)))
+	object.SetProperty("$include_start_date", values.NewBool(period.options&periodExcludeStartDate == 0))
+	object.SetProperty("$include_end_date", values.NewBool(period.options&periodIncludeEndDate != 0))
+	return nil
+}
+
+// Parse an ISO 8601 repeating interval like "R4/2012-07-01T00:00:00Z/P7D" or "R2/2012-07-01T00:00:00Z/P7D/2012-08-01T00:00:00Z"
+func parseIsoPeriod(isoString string, options int) (*datePeriod, bool) {
+	parts := strings.Split(isoString, "/")
+	if len(parts) < 3 || len(parts) > 4 || len(parts[0]) < 2 || parts[0][0] != 'R' {
+		return nil, false
+	}
+	for i := 1; i < len(parts[0]); i++ {
+		if !isDigit(parts[0][i]) {
+			return nil, false
+		}
+	}
+
+	period := &datePeriod{startClass: "DateTime", recurrences: atoi(parts[0][1:]), options: options}
+	parsed := parseTime(parts[1])
+	if len(parsed.errors) > 0 {
+		return nil, false
+	}
+	startTime, startTimezone := parsed.resolve(time.Now(), utcTimezone)
+	period.start = &dateTime{time: startTime, timezone: startTimezone}
+
+	interval, ok := parseIsoDuration(parts[2])
+	if !ok {
+		return nil, false
+	}
+	period.interval = interval
+
+	if len(parts) == 4 {
+		parsed := parseTime(parts[3])
+		if len(parsed.errors) > 0 {
+			return nil, false
+		}
+		endTime, endTimezone := parsed.resolve(time.Now(), utcTimezone)
+		period.end = &dateTime{time: endTime, timezone: endTimezone}
+	}
+	return period, true
+}
+
+// -------------------------------------- DatePeriod -------------------------------------- MARK: DatePeriod
+
+func registerDatePeriod(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.dateperiod.php
+	runtime.NewNativeClass(interpreter, "DatePeriod").
+		Implements("IteratorAggregate").
+		AddConst("EXCLUDE_START_DATE", newIntLiteral(periodExcludeStartDate)).
+		AddConst("INCLUDE_END_DATE", newIntLiteral(periodIncludeEndDate)).
+		AddMethod("__construct", []ast.FunctionParameter{
+			runtime.NewParam("$start", "DateTimeInterface", "string"), runtime.NewOptionalParam("$interval", newNullLiteral(), "DateInterval", "int"),
+			runtime.NewOptionalParam("$end", newNullLiteral(), "DateTimeInterface", "int"), runtime.NewOptionalParam("$options", newNullLiteral(), "int"),
+		}, nil, datePeriodConstruct).
+		AddMethod("getDateInterval", []ast.FunctionParameter{}, []string{"DateInterval"}, datePeriodGetDateInterval).
+		AddMethod("getEndDate", []ast.FunctionParameter{}, []string{"DateTimeInterface", "null"}, datePeriodGetEndDate).
+		AddMethod("getIterator", []ast.FunctionParameter{}, []string{"Iterator"}, datePeriodGetIterator).
+		AddMethod("getRecurrences", []ast.FunctionParameter{}, []string{"int", "null"}, datePeriodGetRecurrences).
+		AddMethod("getStartDate", []ast.FunctionParameter{}, []string{"DateTimeInterface"}, datePeriodGetStartDate).
+		AddStaticMethod("createFromISO8601String", []ast.FunctionParameter{
+			runtime.NewParam("$specification", "string"), runtime.NewOptionalParam("$options", newIntLiteral(0), "int"),
+		}, []string{"DatePeriod"}, datePeriodCreateFromISO8601String).
+		Register()
+}
+
+func datePeriodConstruct(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// ISO 8601 repeating interval
+	if len(args) > 0 && args[0].GetType() == values.StrValue {
+		args, err := funcParamValidator.NewValidator("DatePeriod::__construct").
+			AddParam("$isostr", stringType, nil).AddParam("$options", intType, values.NewInt(0)).
+			Validate(args)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		isoString := args[0].(*values.Str).Value
+		period, ok := parseIsoPeriod(isoString, int(args[1].(*values.Int).Value))
+		if !ok {
+			return values.NewVoid(), newException("DateMalformedPeriodStringException", "DatePeriod::__construct(): Unknown or bad format (%s)", isoString)
+		}
+		return values.NewVoid(), setDatePeriod(object, period, context)
+	}
+
+	args, err := funcParamValidator.NewValidator("DatePeriod::__construct").
+		AddParam("$start", mixedType, nil).AddParam("$interval", mixedType, nil).
+		AddParam("$end", mixedType, nil).AddParam("$options", intType, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	startObject, err := context.ObjectArg("DatePeriod::__construct", 1, "$start", "DateTimeInterface", false, args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	start, err := getDateTime(startObject)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	intervalObject, err := context.ObjectArg("DatePeriod::__construct", 2, "$interval", "DateInterval", false, args[1])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	period := &datePeriod{
+		start: start, startClass: dateTimeClassName(startObject, context), interval: getInterval(intervalObject),
+		options: int(args[3].(*values.Int).Value),
+	}
+	if recurrences, isInt := args[2].(*values.Int); isInt {
+		if recurrences.Value < 1 {
+			return values.NewVoid(), newException("DateMalformedPeriodStringException", "DatePeriod::__construct(): Recurrence count must be greater than 0")
+		}
+		period.recurrences = int(recurrences.Value)
+	} else {
+		endObject, err := context.ObjectArg("DatePeriod::__construct", 3, "$end", "DateTimeInterface", false, args[2])
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		period.end, err = getDateTime(endObject)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return values.NewVoid(), setDatePeriod(object, period, context)
+}
+
+func datePeriodCreateFromISO8601String(_ *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("DatePeriod::createFromISO8601String").
+		AddParam("$specification", stringType, nil).AddParam("$options", intType, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	specification := args[0].(*values.Str).Value
+	period, ok := parseIsoPeriod(specification, int(args[1].(*values.Int).Value))
+	if !ok {
+		return values.NewVoid(), newException("DateMalformedPeriodStringException", "DatePeriod::createFromISO8601String(): Unknown or bad format (%s)", specification)
+	}
+	object, err := newObject(context.Interpreter, "DatePeriod")
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return object, setDatePeriod(object, period, context)
+}
+
+func datePeriodGetDateInterval(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("DatePeriod::getDateInterval").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	period, err := getDatePeriod(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return newIntervalObject(context, period.interval)
+}
+
+func datePeriodGetEndDate(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("DatePeriod::getEndDate").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	period, err := getDatePeriod(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if period.end == nil {
+		return values.NewNull(), nil
+	}
+	return newDateTimeObject(context, period.startClass, period.end)
+}
+
+func datePeriodGetIterator(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("DatePeriod::getIterator").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	period, err := getDatePeriod(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	dates := []values.RuntimeValue{}
+	for _, date := range period.dates() {
+		dateObject, err := newDateTimeObject(context, period.startClass, date)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		dates = append(dates, dateObject)
+	}
+	return spl.NewInternalIterator(
+		context.Interpreter,
+		func() int { return len(dates) },
+		func(index int) values.RuntimeValue { return values.NewInt(int64(index)) },
+		func(index int) values.RuntimeValue { return dates[index] },
+	)
+}
+
+func datePeriodGetRecurrences(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("DatePeriod::getRecurrences").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	period, err := getDatePeriod(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if period.end != nil {
+		return values.NewNull(), nil
+	}
+	return values.NewInt(int64(period.recurrences)), nil
+}
+
+func datePeriodGetStartDate(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("DatePeriod::getStartDate").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	period, err := getDatePeriod(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return newDateTimeObject(context, period.startClass, period.start)
+}
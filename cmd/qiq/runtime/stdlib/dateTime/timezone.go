@@ -0,0 +1,501 @@
+package dateTime
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The timezone database is embedded so that timezones also work on systems without tzdata
+import _ "time/tzdata"
+
+// Spec: https://www.php.net/manual/en/datetime.format.php - timezone_type
+const (
+	timezoneTypeOffset       = 1
+	timezoneTypeAbbreviation = 2
+	timezoneTypeIdentifier   = 3
+)
+
+// Timezone of a date. Like in PHP, a timezone is either an UTC offset (e.g. "+02:00"),
+// an abbreviation (e.g. "EST") or an identifier of the timezone database (e.g. "Europe/Berlin").
+type timezone struct {
+	kind     int
+	name     string
+	location *time.Location
+	// Abbreviations only: The abbreviation is a daylight saving time (e.g. "CEST")
+	isDst bool
+}
+
+type abbreviation struct {
+	offset int
+	isDst  bool
+}
+
+// Commonly used timezone abbreviations and their UTC offset in seconds
+var abbreviations = map[string]abbreviation{
+	"gmt": {0, false}, "ut": {0, false}, "z": {0, false}, "wet": {0, false}, "west": {3600, true}, "bst": {3600, true},
+	"cet": {3600, false}, "cest": {7200, true}, "met": {3600, false}, "mest": {7200, true}, "eet": {7200, false}, "eest": {10800, true},
+	"msk": {10800, false}, "wat": {3600, false}, "cat": {7200, false}, "eat": {10800, false}, "sast": {7200, false},
+	"est": {-18000, false}, "edt": {-14400, true}, "cst": {-21600, false}, "cdt": {-18000, true},
+	"mst": {-25200, false}, "mdt": {-21600, true}, "pst": {-28800, false}, "pdt": {-25200, true},
+	"akst": {-32400, false}, "akdt": {-28800, true}, "hst": {-36000, false}, "ast": {-14400, false}, "adt": {-10800, true},
+	"nst": {-12600, false}, "ndt": {-9000, true}, "jst": {32400, false}, "kst": {32400, false}, "hkt": {28800, false},
+	"awst": {28800, false}, "acst": {34200, false}, "acdt": {37800, true}, "aest": {36000, false}, "aedt": {39600, true},
+	"nzst": {43200, false}, "nzdt": {46800, true},
+}
+
+var utcTimezone = &timezone{kind: timezoneTypeIdentifier, name: "UTC", location: time.UTC}
+
+// The timezone of gmdate() and friends: the identifier is "UTC", but the abbreviation is "GMT"
+var gmtTimezone = &timezone{kind: timezoneTypeIdentifier, name: "UTC", location: time.FixedZone("GMT", 0)}
+
+func newOffsetTimezone(offset int) *timezone {
+	name := formatOffset(offset, true)
+	return &timezone{kind: timezoneTypeOffset, name: name, location: time.FixedZone(name, offset)}
+}
+
+func newAbbreviationTimezone(name string, abbr abbreviation) *timezone {
+	name = strings.ToUpper(name)
+	return &timezone{kind: timezoneTypeAbbreviation, name: name, location: time.FixedZone(name, abbr.offset), isDst: abbr.isDst}
+}
+
+// Find the timezone with the given offset (e.g. "+02:00"), abbreviation (e.g. "EST") or identifier (e.g. "Europe/Berlin")
+func loadTimezone(name string) (*timezone, bool) {
+	if name == "" {
+		return nil, false
+	}
+	if offset, length, ok := parseOffset(name); ok && length == len(name) {
+		return newOffsetTimezone(offset), true
+	}
+	if strings.ToLower(name) == "utc" {
+		return utcTimezone, true
+	}
+	if abbr, found := abbreviations[strings.ToLower(name)]; found {
+		return newAbbreviationTimezone(name, abbr), true
+	}
+	return loadTimezoneIdentifier(name)
+}
+
+// Loaded locations of the timezone database. Locations are immutable, so they are shared by all requests.
+var locationCache sync.Map
+
+// Find the timezone with the given identifier of the timezone database (e.g. "Europe/Berlin")
+func loadTimezoneIdentifier(name string) (*timezone, bool) {
+	// "Local" is the timezone of the host in Go, but not a valid identifier in PHP
+	if name == "" || name == "Local" || strings.ContainsAny(name, " \t") {
+		return nil, false
+	}
+	if strings.EqualFold(name, "UTC") {
+		return utcTimezone, true
+	}
+	// Identifiers are case-insensitive, but the canonical spelling is reported
+	if _, found := zoneLocations[name]; !found {
+		for identifier := range zoneLocations {
+			if strings.EqualFold(identifier, name) {
+				name = identifier
+				break
+			}
+		}
+	}
+	if location, found := locationCache.Load(name); found {
+		return &timezone{kind: timezoneTypeIdentifier, name: name, location: location.(*time.Location)}, true
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, false
+	}
+	locationCache.Store(name, location)
+	return &timezone{kind: timezoneTypeIdentifier, name: name, location: location}, true
+}
+
+// Parse an UTC offset like "+2", "+02", "+0200" or "+02:00". Returns the offset in seconds and the length of the parsed string.
+func parseOffset(str string) (offset int, length int, ok bool) {
+	if len(str) < 2 || (str[0] != '+' && str[0] != '-') {
+		return 0, 0, false
+	}
+	digits := 0
+	for 1+digits < len(str) && isDigit(str[1+digits]) {
+		digits++
+	}
+	hours, minutes, seconds := 0, 0, 0
+	length = 1 + digits
+	switch digits {
+	case 1, 2:
+		hours = atoi(str[1 : 1+digits])
+		// "+02:00"
+		if length+2 < len(str) && str[length] == ':' && isDigit(str[length+1]) && isDigit(str[length+2]) {
+			minutes = atoi(str[length+1 : length+3])
+			length += 3
+		}
+	case 3:
+		hours, minutes = atoi(str[1:2]), atoi(str[2:4])
+	case 4:
+		hours, minutes = atoi(str[1:3]), atoi(str[3:5])
+	case 6:
+		hours, minutes, seconds = atoi(str[1:3]), atoi(str[3:5]), atoi(str[5:7])
+	default:
+		return 0, 0, false
+	}
+	if hours > 99 || minutes > 59 {
+		return 0, 0, false
+	}
+	offset = hours*3600 + minutes*60 + seconds
+	if str[0] == '-' {
+		offset = -offset
+	}
+	return offset, length, true
+}
+
+// Format the offset in seconds as "+0200" or with colon as "+02:00"
+func formatOffset(offset int, withColon bool) string {
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	separator := ""
+	if withColon {
+		separator = ":"
+	}
+	return fmt.Sprintf("%c%02d%s%02d", sign, offset/3600, separator, offset%3600/60)
+}
+
+// Get the abbreviation of the timezone at the given time (e.g. "CEST" for Europe/Berlin in summer)
+func (timezone *timezone) abbreviation(t time.Time) string {
+	switch timezone.kind {
+	case timezoneTypeOffset, timezoneTypeAbbreviation:
+		return timezone.name
+	default:
+		name, _ := t.Zone()
+		return name
+	}
+}
+
+func (timezone *timezone) isDstAt(t time.Time) bool {
+	switch timezone.kind {
+	case timezoneTypeOffset:
+		return false
+	case timezoneTypeAbbreviation:
+		return timezone.isDst
+	default:
+		return t.IsDST()
+	}
+}
+
+func (timezone *timezone) equals(other *timezone) bool {
+	if timezone.kind != other.kind {
+		return false
+	}
+	if timezone.kind == timezoneTypeIdentifier {
+		return timezone.name == other.name
+	}
+	_, offset := time.Time{}.In(timezone.location).Zone()
+	_, otherOffset := time.Time{}.In(other.location).Zone()
+	return offset == otherOffset && timezone.isDst == other.isDst
+}
+
+// Get the default timezone that is used if no timezone is given: The timezone set with date_default_timezone_set(),
+// the timezone of the ini directive date.timezone or UTC
+func getDefaultTimezone(context runtime.Context) *timezone {
+	if name := context.Interpreter.GetExectionContext().GetDefaultTimezone(); name != "" {
+		if timezone, found := loadTimezoneIdentifier(name); found {
+			return timezone
+		}
+	}
+	if name := context.Interpreter.GetIni().GetStr("date.timezone"); name != "" {
+		if timezone, found := loadTimezoneIdentifier(name); found {
+			return timezone
+		}
+		context.Interpreter.PrintError(phpError.NewWarning("Invalid date.timezone value '%s', using 'UTC' instead%s", name, context.InPosition()))
+	}
+	return utcTimezone
+}
+
+// -------------------------------------- DateTimeZone -------------------------------------- MARK: DateTimeZone
+
+func newTimezoneObject(context runtime.Context, timezone *timezone) (*values.Object, phpError.Error) {
+	object, err := newObject(context.Interpreter, "DateTimeZone")
+	if err != nil {
+		return nil, err
+	}
+	setTimezone(object, timezone)
+	return object, nil
+}
+
+// Set the internal timezone and the properties shown by var_dump
+func setTimezone(object *values.Object, timezone *timezone) {
+	object.Internal = timezone
+	object.SetProperty("$timezone_type", values.NewInt(int64(timezone.kind)))
+	object.SetProperty("$timezone", values.NewStr(timezone.name))
+}
+
+func getTimezone(object *values.Object) (*timezone, phpError.Error) {
+	timezone, ok := object.Internal.(*timezone)
+	if !ok {
+		return nil, phpError.NewError("Uncaught Error: The DateTimeZone object has not been correctly initialized by its constructor")
+	}
+	return timezone, nil
+}
+
+// Get the timezone of an optional DateTimeZone argument. Without argument, the default timezone is used.
+func timezoneArg(arg *values.Object, context runtime.Context) (*timezone, phpError.Error) {
+	if arg == nil {
+		return getDefaultTimezone(context), nil
+	}
+	return getTimezone(arg)
+}
+
+func registerDateTimeZone(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.datetimezone.php
+	runtime.NewNativeClass(interpreter, "DateTimeZone").
+		AddConst("AFRICA", newIntLiteral(1)).
+		AddConst("AMERICA", newIntLiteral(2)).
+		AddConst("ANTARCTICA", newIntLiteral(4)).
+		AddConst("ARCTIC", newIntLiteral(8)).
+		AddConst("ASIA", newIntLiteral(16)).
+		AddConst("ATLANTIC", newIntLiteral(32)).
+		AddConst("AUSTRALIA", newIntLiteral(64)).
+		AddConst("EUROPE", newIntLiteral(128)).
+		AddConst("INDIAN", newIntLiteral(256)).
+		AddConst("PACIFIC", newIntLiteral(512)).
+		AddConst("UTC", newIntLiteral(1024)).
+		AddConst("ALL", newIntLiteral(2047)).
+		AddConst("ALL_WITH_BC", newIntLiteral(4095)).
+		AddConst("PER_COUNTRY", newIntLiteral(4096)).
+		AddMethod("__construct", []ast.FunctionParameter{runtime.NewParam("$timezone", "string")}, nil, dateTimeZoneConstruct).
+		AddMethod("getLocation", []ast.FunctionParameter{}, []string{"array", "false"}, dateTimeZoneGetLocation).
+		AddMethod("getName", []ast.FunctionParameter{}, stringType, dateTimeZoneGetName).
+		AddMethod("getOffset", []ast.FunctionParameter{runtime.NewParam("$datetime", "DateTimeInterface")}, intType, dateTimeZoneGetOffset).
+		AddStaticMethod("listIdentifiers", []ast.FunctionParameter{
+			runtime.NewOptionalParam("$timezoneGroup", newIntLiteral(2047), "int"), runtime.NewOptionalParam("$countryCode", newNullLiteral(), "string", "null"),
+		}, []string{"array"}, dateTimeZoneListIdentifiers).
+		Register()
+}
+
+func dateTimeZoneConstruct(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("DateTimeZone::__construct").AddParam("$timezone", stringType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	name := args[0].(*values.Str).Value
+	timezone, found := loadTimezone(name)
+	if !found {
+		return values.NewVoid(), newException("DateInvalidTimeZoneException", "DateTimeZone::__construct(): Unknown or bad timezone (%s)", name)
+	}
+	setTimezone(object, timezone)
+	return values.NewVoid(), nil
+}
+
+func dateTimeZoneGetLocation(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("DateTimeZone::getLocation").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	timezone, err := getTimezone(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return lib_timezone_location_get(timezone), nil
+}
+
+func dateTimeZoneGetName(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("DateTimeZone::getName").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	timezone, err := getTimezone(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(timezone.name), nil
+}
+
+func dateTimeZoneGetOffset(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("DateTimeZone::getOffset").AddParam("$datetime", mixedType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	timezone, err := getTimezone(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	dateTimeObject, err := context.ObjectArg("DateTimeZone::getOffset", 1, "$datetime", "DateTimeInterface", false, args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	date, err := getDateTime(dateTimeObject)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	_, offset := date.time.In(timezone.location).Zone()
+	return values.NewInt(int64(offset)), nil
+}
+
+func dateTimeZoneListIdentifiers(_ *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	return lib_timezone_identifiers_list("DateTimeZone::listIdentifiers", args, context)
+}
+
+// -------------------------------------- timezone_identifiers_list -------------------------------------- MARK: timezone_identifiers_list
+
+func nativeFn_timezone_identifiers_list(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.timezone-identifiers-list.php
+	return lib_timezone_identifiers_list("timezone_identifiers_list", args, context)
+}
+
+// Groups of DateTimeZone::listIdentifiers() and the prefix of their identifiers
+var timezoneGroups = map[string]int64{
+	"Africa/": 1, "America/": 2, "Antarctica/": 4, "Arctic/": 8, "Asia/": 16, "Atlantic/": 32,
+	"Australia/": 64, "Europe/": 128, "Indian/": 256, "Pacific/": 512,
+}
+
+func lib_timezone_identifiers_list(functionName string, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$timezoneGroup", intType, values.NewInt(2047)).AddParam("$countryCode", []string{"string", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	group := args[0].(*values.Int).Value
+	countryCode := ""
+	if args[1].GetType() != values.NullValue {
+		countryCode = strings.ToUpper(args[1].(*values.Str).Value)
+	}
+	if group < 1 || (group > 4095 && group != 4096) {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: %s(): Argument #1 ($timezoneGroup) must be one of the DateTimeZone group constants", functionName,
+		)
+	}
+	if group == 4096 && len(countryCode) != 2 {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: %s(): Argument #2 ($countryCode) must be a two-letter ISO 3166-1 compatible country code "+
+				"when argument #1 ($timezoneGroup) is DateTimeZone::PER_COUNTRY", functionName,
+		)
+	}
+
+	// Only the canonical identifiers of zone.tab are known, so ALL_WITH_BC lists the same identifiers as ALL
+	names := []string{}
+	for name, location := range zoneLocations {
+		if group == 4096 {
+			if location.countryCode == countryCode {
+				names = append(names, name)
+			}
+			continue
+		}
+		for prefix, prefixGroup := range timezoneGroups {
+			if strings.HasPrefix(name, prefix) && group&prefixGroup != 0 {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	if group != 4096 && group&1024 != 0 {
+		names = append(names, "UTC")
+	}
+	slices.Sort(names)
+
+	result := values.NewArray()
+	for _, name := range names {
+		result.SetElement(nil, values.NewStr(name))
+	}
+	return result, nil
+}
+
+// -------------------------------------- timezone_location_get -------------------------------------- MARK: timezone_location_get
+
+func nativeFn_timezone_location_get(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.timezone-location-get.php
+	args, err := funcParamValidator.NewValidator("timezone_location_get").AddParam("$object", mixedType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	object, err := context.ObjectArg("timezone_location_get", 1, "$object", "DateTimeZone", false, args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	timezone, err := getTimezone(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return lib_timezone_location_get(timezone), nil
+}
+
+func lib_timezone_location_get(timezone *timezone) values.RuntimeValue {
+	if timezone.kind != timezoneTypeIdentifier {
+		return values.NewBool(false)
+	}
+	// Timezones that are not in zone.tab (e.g. "UTC") have no location
+	zone, found := zoneLocations[timezone.name]
+	if !found {
+		zone = zoneLocation{countryCode: "??"}
+	}
+	location := values.NewArray()
+	location.SetElement(values.NewStr("country_code"), values.NewStr(zone.countryCode))
+	location.SetElement(values.NewStr("latitude"), values.NewFloat(zone.latitude))
+	location.SetElement(values.NewStr("longitude"), values.NewFloat(zone.longitude))
+	location.SetElement(values.NewStr("comments"), values.NewStr(zone.comments))
+	return location
+}
+
+// -------------------------------------- timezone_name_get -------------------------------------- MARK: timezone_name_get
+
+func nativeFn_timezone_name_get(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.timezone-name-get.php
+	args, err := funcParamValidator.NewValidator("timezone_name_get").AddParam("$object", mixedType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	object, err := context.ObjectArg("timezone_name_get", 1, "$object", "DateTimeZone", false, args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return dateTimeZoneGetName(object, []values.RuntimeValue{}, context)
+}
+
+// -------------------------------------- timezone_offset_get -------------------------------------- MARK: timezone_offset_get
+
+func nativeFn_timezone_offset_get(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.timezone-offset-get.php
+	args, err := funcParamValidator.NewValidator("timezone_offset_get").
+		AddParam("$object", mixedType, nil).AddParam("$datetime", mixedType, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	object, err := context.ObjectArg("timezone_offset_get", 1, "$object", "DateTimeZone", false, args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if _, err := context.ObjectArg("timezone_offset_get", 2, "$datetime", "DateTimeInterface", false, args[1]); err != nil {
+		return values.NewVoid(), err
+	}
+	return dateTimeZoneGetOffset(object, args[1:], context)
+}
+
+// -------------------------------------- timezone_open -------------------------------------- MARK: timezone_open
+
+func nativeFn_timezone_open(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.timezone-open.php
+	args, err := funcParamValidator.NewValidator("timezone_open").AddParam("$timezone", stringType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	name := args[0].(*values.Str).Value
+	timezone, found := loadTimezone(name)
+	if !found {
+		context.Interpreter.PrintError(phpError.NewWarning("timezone_open(): Unknown or bad timezone (%s)%s", name, context.InPosition()))
+		return values.NewBool(false), nil
+	}
+	return newTimezoneObject(context, timezone)
+}
@@ -1,15 +1,180 @@
 package directory
 
 import (
+	"QIQ/cmd/qiq/ast"
 	"QIQ/cmd/qiq/phpError"
 	"QIQ/cmd/qiq/runtime"
 	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stream"
 	"QIQ/cmd/qiq/runtime/values"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+)
+
+// Spec: https://www.php.net/manual/en/function.scandir.php
+const (
+	SCANDIR_SORT_ASCENDING  int64 = 0
+	SCANDIR_SORT_DESCENDING int64 = 1
+	SCANDIR_SORT_NONE       int64 = 2
 )
 
 func Register(environment runtime.Environment) {
 	// Category: Directory Functions
+	environment.AddNativeFunction("chdir", nativeFn_chdir)
+	environment.AddNativeFunction("closedir", nativeFn_closedir)
+	environment.AddNativeFunction("dir", nativeFn_dir)
 	environment.AddNativeFunction("getcwd", nativeFn_getcwd)
+	environment.AddNativeFunction("opendir", nativeFn_opendir)
+	environment.AddNativeFunction("readdir", nativeFn_readdir)
+	environment.AddNativeFunction("rewinddir", nativeFn_rewinddir)
+	environment.AddNativeFunction("scandir", nativeFn_scandir)
+
+	// Const Category: Directory Constants
+	// Spec: https://www.php.net/manual/en/dir.constants.php
+	environment.AddPredefinedConstant("PATH_SEPARATOR", values.NewStr(string(os.PathListSeparator)))
+	environment.AddPredefinedConstant("SCANDIR_SORT_ASCENDING", values.NewInt(SCANDIR_SORT_ASCENDING))
+	environment.AddPredefinedConstant("SCANDIR_SORT_DESCENDING", values.NewInt(SCANDIR_SORT_DESCENDING))
+	environment.AddPredefinedConstant("SCANDIR_SORT_NONE", values.NewInt(SCANDIR_SORT_NONE))
+}
+
+func RegisterClasses(interpreter runtime.Interpreter) {
+	registerDirectory(interpreter)
+}
+
+// Get the directory of the handle argument. Without handle, the last opened directory is used.
+func getDirectory(functionName string, handle values.RuntimeValue, context runtime.Context) (*stream.Directory, *values.Resource, phpError.Error) {
+	var resource *values.Resource
+	if handle.GetType() == values.NullValue {
+		defaultDirectory, found := context.Interpreter.GetExectionContext().GetDefaultDirectory()
+		if !found {
+			return nil, nil, phpError.NewError("Uncaught TypeError: No resource supplied")
+		}
+		resource = defaultDirectory
+	} else {
+		resource = handle.(*values.Resource)
+	}
+
+	if resource.IsClosed || resource.Type != "stream" {
+		return nil, nil, phpError.NewError("Uncaught TypeError: %s(): supplied resource is not a valid Directory resource", functionName)
+	}
+	directory, isDirectory := resource.Internal.(*stream.Directory)
+	if !isDirectory {
+		return nil, nil, phpError.NewError("Uncaught TypeError: %s(): Argument #1 ($dir_handle) must be a valid Directory resource", functionName)
+	}
+	return directory, resource, nil
+}
+
+// Open the directory and make it the default directory. Returns false if the directory cannot be opened.
+func openDirectory(functionName string, path string, context runtime.Context) (*values.Resource, bool) {
+	directory, err := stream.OpenDir(path, context)
+	if err != nil {
+		context.Interpreter.PrintError(phpError.NewWarning("%s(%s): Failed to open directory: %s%s", functionName, path, err, context.InPosition()))
+		return nil, false
+	}
+	resource := context.Interpreter.GetExectionContext().NewResource("stream", directory)
+	context.Interpreter.GetExectionContext().SetDefaultDirectory(resource)
+	return resource, true
+}
+
+func closeDirectory(directory *stream.Directory, resource *values.Resource, context runtime.Context) {
+	directory.Close()
+	resource.IsClosed = true
+	if defaultDirectory, found := context.Interpreter.GetExectionContext().GetDefaultDirectory(); found && defaultDirectory == resource {
+		context.Interpreter.GetExectionContext().SetDefaultDirectory(nil)
+	}
+}
+
+func readDirectory(directory *stream.Directory) values.RuntimeValue {
+	name, ok := directory.Read()
+	if !ok {
+		return values.NewBool(false)
+	}
+	return values.NewStr(name)
+}
+
+// -------------------------------------- chdir -------------------------------------- MARK: chdir
+
+func nativeFn_chdir(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.chdir.php
+	args, err := funcParamValidator.NewValidator("chdir").AddParam("$directory", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if !stream.CheckOpenBasedir("chdir", args[0].(*values.Str).Value, context) {
+		return values.NewBool(false), nil
+	}
+
+	path, goErr := filepath.Abs(stream.ResolvePath(args[0].(*values.Str).Value, context))
+	if goErr == nil {
+		var info os.FileInfo
+		info, goErr = os.Stat(path)
+		if goErr == nil && !info.IsDir() {
+			goErr = syscall.ENOTDIR
+		}
+	}
+	if goErr != nil {
+		errno := syscall.ENOENT
+		errors.As(goErr, &errno)
+		context.Interpreter.PrintError(phpError.NewWarning("chdir(): %s (errno %d)%s", stream.ErrorMessage(errno), int(errno), context.InPosition()))
+		return values.NewBool(false), nil
+	}
+
+	context.Interpreter.SetWorkingDir(path)
+	// The cached results of relative paths are no longer valid
+	context.Interpreter.GetExectionContext().ClearStatCache()
+	return values.NewBool(true), nil
+}
+
+// -------------------------------------- closedir -------------------------------------- MARK: closedir
+
+func nativeFn_closedir(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.closedir.php
+	args, err := funcParamValidator.NewValidator("closedir").
+		AddParam("$dir_handle", []string{"resource", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	directory, resource, err := getDirectory("closedir", args[0], context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	closeDirectory(directory, resource, context)
+	return values.NewVoid(), nil
+}
+
+// -------------------------------------- dir -------------------------------------- MARK: dir
+
+func nativeFn_dir(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.dir.php
+	args, err := funcParamValidator.NewValidator("dir").
+		AddParam("$directory", []string{"string"}, nil).
+		AddParam("$context", []string{"resource", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// TODO dir: Add support for context
+
+	path := args[0].(*values.Str).Value
+	resource, ok := openDirectory("dir", path, context)
+	if !ok {
+		return values.NewBool(false), nil
+	}
+
+	classDecl, found := context.Interpreter.GetClass("Directory")
+	if !found {
+		return values.NewVoid(), phpError.NewError(`Class "Directory" not found`)
+	}
+	object := values.NewObject(classDecl)
+	object.SetProperty("$path", values.NewStr(path))
+	object.SetProperty("$handle", resource)
+	return object, nil
 }
 
 // -------------------------------------- getcwd -------------------------------------- MARK: getcwd
@@ -29,11 +194,174 @@ func nativeFn_getcwd(args []values.RuntimeValue, context runtime.Context) (value
 	return values.NewStr(context.Interpreter.GetWorkingDir()), nil
 }
 
-// TODO chdir
+// -------------------------------------- opendir -------------------------------------- MARK: opendir
+
+func nativeFn_opendir(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.opendir.php
+	args, err := funcParamValidator.NewValidator("opendir").
+		AddParam("$directory", []string{"string"}, nil).
+		AddParam("$context", []string{"resource", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// TODO opendir: Add support for context
+
+	resource, ok := openDirectory("opendir", args[0].(*values.Str).Value, context)
+	if !ok {
+		return values.NewBool(false), nil
+	}
+	return resource, nil
+}
+
+// -------------------------------------- readdir -------------------------------------- MARK: readdir
+
+func nativeFn_readdir(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.readdir.php
+	args, err := funcParamValidator.NewValidator("readdir").
+		AddParam("$dir_handle", []string{"resource", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	directory, _, err := getDirectory("readdir", args[0], context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return readDirectory(directory), nil
+}
+
+// -------------------------------------- rewinddir -------------------------------------- MARK: rewinddir
+
+func nativeFn_rewinddir(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.rewinddir.php
+	args, err := funcParamValidator.NewValidator("rewinddir").
+		AddParam("$dir_handle", []string{"resource", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	directory, _, err := getDirectory("rewinddir", args[0], context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	directory.Rewind()
+	return values.NewVoid(), nil
+}
+
+// -------------------------------------- scandir -------------------------------------- MARK: scandir
+
+func nativeFn_scandir(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.scandir.php
+	args, err := funcParamValidator.NewValidator("scandir").
+		AddParam("$directory", []string{"string"}, nil).
+		AddParam("$sorting_order", []string{"int"}, values.NewInt(SCANDIR_SORT_ASCENDING)).
+		AddParam("$context", []string{"resource", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// TODO scandir: Add support for context
+
+	path := args[0].(*values.Str).Value
+	if path == "" {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: scandir(): Argument #1 ($directory) cannot be empty")
+	}
+
+	names, goErr := stream.ReadDir(path, context)
+	if goErr != nil {
+		context.Interpreter.PrintError(phpError.NewWarning("scandir(%s): Failed to open directory: %s%s", path, goErr, context.InPosition()))
+		errno := syscall.ENOENT
+		if !stream.IsAllowedPath(path, context) {
+			errno = syscall.EPERM
+		} else if _, statErr := os.Stat(stream.ResolvePath(path, context)); statErr != nil {
+			errors.As(statErr, &errno)
+		} else {
+			errno = syscall.ENOTDIR
+		}
+		context.Interpreter.PrintError(phpError.NewWarning("scandir(): (errno %d): %s%s", int(errno), stream.ErrorMessage(errno), context.InPosition()))
+		return values.NewBool(false), nil
+	}
+
+	switch args[1].(*values.Int).Value {
+	case SCANDIR_SORT_NONE:
+	case SCANDIR_SORT_ASCENDING:
+		slices.Sort(names)
+	default:
+		slices.Sort(names)
+		slices.Reverse(names)
+	}
+
+	result := values.NewArray()
+	for _, name := range names {
+		result.SetElement(nil, values.NewStr(name))
+	}
+	return result, nil
+}
+
 // TODO chroot
-// TODO closedir
-// TODO dir
-// TODO opendir
-// TODO readdir
-// TODO rewinddir
-// TODO scandir
+
+// -------------------------------------- Directory -------------------------------------- MARK: Directory
+
+func registerDirectory(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.directory.php
+	runtime.NewNativeClass(interpreter, "Directory").
+		Final().
+		AddProperty("$path", "public", []string{"string"}, nil).
+		AddProperty("$handle", "public", []string{"mixed"}, nil).
+		AddMethod("close", []ast.FunctionParameter{}, []string{"void"}, directoryClose).
+		AddMethod("read", []ast.FunctionParameter{}, []string{"string", "false"}, directoryRead).
+		AddMethod("rewind", []ast.FunctionParameter{}, []string{"void"}, directoryRewind).
+		Register()
+}
+
+// Get the directory of the handle property
+func getDirectoryOfObject(object *values.Object, methodName string, context runtime.Context) (*stream.Directory, *values.Resource, phpError.Error) {
+	handle, found := object.GetProperty("$handle")
+	if !found || handle.GetType() != values.ResourceValue {
+		return nil, nil, phpError.NewError("Uncaught Error: Unable to find my handle property")
+	}
+	return getDirectory("Directory::"+methodName, handle, context)
+}
+
+func directoryClose(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("Directory::close").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	directory, resource, err := getDirectoryOfObject(object, "close", context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	closeDirectory(directory, resource, context)
+	return values.NewVoid(), nil
+}
+
+func directoryRead(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("Directory::read").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	directory, _, err := getDirectoryOfObject(object, "read", context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return readDirectory(directory), nil
+}
+
+func directoryRewind(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("Directory::rewind").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	directory, _, err := getDirectoryOfObject(object, "rewind", context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	directory.Rewind()
+	return values.NewVoid(), nil
+}
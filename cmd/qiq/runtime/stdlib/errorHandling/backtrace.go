@@ -0,0 +1,155 @@
+package errorHandling
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/values"
+	"fmt"
+	"strings"
+)
+
+const (
+	DEBUG_BACKTRACE_PROVIDE_OBJECT int64 = 1
+	DEBUG_BACKTRACE_IGNORE_ARGS    int64 = 2
+)
+
+// Convert the frames of the call stack into the array returned by debug_backtrace
+func BacktraceToArray(frames []runtime.CallFrame, options int64, limit int64) *values.Array {
+	result := values.NewArray()
+	for index, frame := range frames {
+		if limit > 0 && int64(index) >= limit {
+			break
+		}
+
+		frameArray := values.NewArray()
+		if frame.Position != nil && frame.Position.File != nil {
+			frameArray.SetElement(values.NewStr("file"), values.NewStr(frame.Position.File.Filename))
+			frameArray.SetElement(values.NewStr("line"), values.NewInt(int64(frame.Position.Line)))
+		}
+		frameArray.SetElement(values.NewStr("function"), values.NewStr(frame.Function))
+		if frame.Class != "" {
+			frameArray.SetElement(values.NewStr("class"), values.NewStr(frame.Class))
+			if frame.Object != nil && options&DEBUG_BACKTRACE_PROVIDE_OBJECT != 0 {
+				frameArray.SetElement(values.NewStr("object"), frame.Object)
+			}
+			frameArray.SetElement(values.NewStr("type"), values.NewStr(frame.Type))
+		}
+		if options&DEBUG_BACKTRACE_IGNORE_ARGS == 0 {
+			frameArray.SetElement(values.NewStr("args"), values.NewArrayFromSlice(frame.Args))
+		}
+		result.SetElement(nil, frameArray)
+	}
+	return result
+}
+
+// Format the frames of a trace array like "#0 file.php(12): C->method('string', 1)"
+func TraceToLines(trace *values.Array, paramMaxLen int64) []string {
+	lines := make([]string, 0, len(trace.Keys))
+	for index, key := range trace.Keys {
+		slot, _ := trace.GetElement(key)
+		if slot.GetType() != values.ArrayValue {
+			continue
+		}
+		frame := slot.Value.(*values.Array)
+
+		var line strings.Builder
+		fmt.Fprintf(&line, "#%d ", index)
+		if file, found := frame.GetElement(values.NewStr("file")); found {
+			lineNum, _ := frame.GetElement(values.NewStr("line"))
+			fileStr, _ := variableHandling.StrVal(file.Value)
+			lineStr, _ := variableHandling.StrVal(lineNum.Value)
+			fmt.Fprintf(&line, "%s(%s): ", fileStr, lineStr)
+		} else {
+			line.WriteString("[internal function]: ")
+		}
+		if class, found := frame.GetElement(values.NewStr("class")); found {
+			callType, _ := frame.GetElement(values.NewStr("type"))
+			classStr, _ := variableHandling.StrVal(class.Value)
+			callTypeStr, _ := variableHandling.StrVal(callType.Value)
+			line.WriteString(classStr + callTypeStr)
+		}
+		if function, found := frame.GetElement(values.NewStr("function")); found {
+			functionStr, _ := variableHandling.StrVal(function.Value)
+			line.WriteString(functionStr)
+		}
+		line.WriteString("(")
+		if args, found := frame.GetElement(values.NewStr("args")); found && args.GetType() == values.ArrayValue {
+			argStrs := []string{}
+			for _, argKey := range args.Value.(*values.Array).Keys {
+				arg, _ := args.Value.(*values.Array).GetElement(argKey)
+				argStrs = append(argStrs, traceArgToString(arg.Value, paramMaxLen))
+			}
+			line.WriteString(strings.Join(argStrs, ", "))
+		}
+		line.WriteString(")")
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+func traceArgToString(arg values.RuntimeValue, paramMaxLen int64) string {
+	switch arg.GetType() {
+	case values.NullValue:
+		return "NULL"
+	case values.BoolValue:
+		if arg.(*values.Bool).Value {
+			return "true"
+		}
+		return "false"
+	case values.StrValue:
+		str := arg.(*values.Str).Value
+		if int64(len(str)) > paramMaxLen {
+			return "'" + str[:paramMaxLen] + "...'"
+		}
+		return "'" + str + "'"
+	case values.ArrayValue:
+		return "Array"
+	case values.ObjectValue:
+		return "Object(" + arg.(*values.Object).Class.GetQualifiedName() + ")"
+	default:
+		str, _ := variableHandling.StrVal(arg)
+		return str
+	}
+}
+
+// -------------------------------------- debug_backtrace -------------------------------------- MARK: debug_backtrace
+
+func nativeFn_debug_backtrace(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.debug-backtrace.php
+
+	args, err := funcParamValidator.NewValidator("debug_backtrace").
+		AddParam("$options", []string{"int"}, values.NewInt(DEBUG_BACKTRACE_PROVIDE_OBJECT)).
+		AddParam("$limit", []string{"int"}, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// The frame of debug_backtrace itself is not part of the backtrace
+	frames := context.Interpreter.GetBacktrace()[1:]
+	return BacktraceToArray(frames, args[0].(*values.Int).Value, args[1].(*values.Int).Value), nil
+}
+
+// -------------------------------------- debug_print_backtrace -------------------------------------- MARK: debug_print_backtrace
+
+func nativeFn_debug_print_backtrace(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.debug-print-backtrace.php
+
+	args, err := funcParamValidator.NewValidator("debug_print_backtrace").
+		AddParam("$options", []string{"int"}, values.NewInt(0)).
+		AddParam("$limit", []string{"int"}, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// The frame of debug_print_backtrace itself is not part of the backtrace
+	frames := context.Interpreter.GetBacktrace()[1:]
+	trace := BacktraceToArray(frames, args[0].(*values.Int).Value&DEBUG_BACKTRACE_IGNORE_ARGS, args[1].(*values.Int).Value)
+	for _, line := range TraceToLines(trace, context.Interpreter.GetIni().GetInt("zend.exception_string_param_max_len")) {
+		context.Interpreter.Println(line)
+	}
+	return values.NewVoid(), nil
+}
@@ -11,7 +11,17 @@ import (
 
 func Register(environment runtime.Environment) {
 	// Category: Error Handling Functions
+	environment.AddNativeFunction("debug_backtrace", nativeFn_debug_backtrace)
+	environment.AddNativeFunction("debug_print_backtrace", nativeFn_debug_print_backtrace)
+	environment.AddNativeFunction("error_clear_last", nativeFn_error_clear_last)
+	environment.AddNativeFunction("error_get_last", nativeFn_error_get_last)
 	environment.AddNativeFunction("error_reporting", nativeFn_error_reporting)
+	environment.AddNativeFunction("restore_error_handler", nativeFn_restore_error_handler)
+	environment.AddNativeFunction("restore_exception_handler", nativeFn_restore_exception_handler)
+	environment.AddNativeFunction("set_error_handler", nativeFn_set_error_handler)
+	environment.AddNativeFunction("set_exception_handler", nativeFn_set_exception_handler)
+	environment.AddNativeFunction("trigger_error", nativeFn_trigger_error)
+	environment.AddNativeFunction("user_error", nativeFn_trigger_error)
 
 	// Const Category: Error Handling Constants
 	// Spec: https://www.php.net/manual/en/errorfunc.constants.php
@@ -31,6 +41,45 @@ func Register(environment runtime.Environment) {
 	environment.AddPredefinedConstant("E_DEPRECATED", values.NewInt(phpError.E_DEPRECATED))
 	environment.AddPredefinedConstant("E_USER_DEPRECATED", values.NewInt(phpError.E_USER_DEPRECATED))
 	environment.AddPredefinedConstant("E_ALL", values.NewInt(phpError.E_ALL))
+	environment.AddPredefinedConstant("DEBUG_BACKTRACE_PROVIDE_OBJECT", values.NewInt(DEBUG_BACKTRACE_PROVIDE_OBJECT))
+	environment.AddPredefinedConstant("DEBUG_BACKTRACE_IGNORE_ARGS", values.NewInt(DEBUG_BACKTRACE_IGNORE_ARGS))
+}
+
+// -------------------------------------- error_clear_last -------------------------------------- MARK: error_clear_last
+
+func nativeFn_error_clear_last(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.error-clear-last.php
+
+	_, err := funcParamValidator.NewValidator("error_clear_last").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	context.Interpreter.GetExectionContext().SetLastError(nil)
+	return values.NewVoid(), nil
+}
+
+// -------------------------------------- error_get_last -------------------------------------- MARK: error_get_last
+
+func nativeFn_error_get_last(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.error-get-last.php
+
+	_, err := funcParamValidator.NewValidator("error_get_last").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	lastError := context.Interpreter.GetExectionContext().GetLastError()
+	if lastError == nil {
+		return values.NewNull(), nil
+	}
+
+	result := values.NewArray()
+	result.SetElement(values.NewStr("type"), values.NewInt(lastError.Type))
+	result.SetElement(values.NewStr("message"), values.NewStr(lastError.Message))
+	result.SetElement(values.NewStr("file"), values.NewStr(lastError.File))
+	result.SetElement(values.NewStr("line"), values.NewInt(lastError.Line))
+	return result, nil
 }
 
 // -------------------------------------- error_reporting -------------------------------------- MARK: error_reporting
@@ -57,3 +106,122 @@ func nativeFn_error_reporting(args []values.RuntimeValue, context runtime.Contex
 
 	return values.NewInt(previous), nil
 }
+
+// -------------------------------------- restore_error_handler -------------------------------------- MARK: restore_error_handler
+
+func nativeFn_restore_error_handler(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.restore-error-handler.php
+
+	_, err := funcParamValidator.NewValidator("restore_error_handler").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	context.Interpreter.GetExectionContext().PopErrorHandler()
+	return values.NewBool(true), nil
+}
+
+// -------------------------------------- restore_exception_handler -------------------------------------- MARK: restore_exception_handler
+
+func nativeFn_restore_exception_handler(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.restore-exception-handler.php
+
+	_, err := funcParamValidator.NewValidator("restore_exception_handler").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	context.Interpreter.GetExectionContext().PopExceptionHandler()
+	return values.NewBool(true), nil
+}
+
+// -------------------------------------- set_error_handler -------------------------------------- MARK: set_error_handler
+
+func nativeFn_set_error_handler(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.set-error-handler.php
+
+	args, err := funcParamValidator.NewValidator("set_error_handler").
+		AddParam("$callback", []string{"mixed"}, nil).
+		AddParam("$error_levels", []string{"int"}, values.NewInt(phpError.E_ALL)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if err := context.ValidateCallback("set_error_handler", 1, "$callback", args[0], true); err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Returns the previously defined error handler (if any). If the built-in error handler is used null is returned.
+	executionContext := context.Interpreter.GetExectionContext()
+	var previous values.RuntimeValue = values.NewNull()
+	if handler, found := executionContext.GetErrorHandler(); found {
+		previous = handler.Callback
+	}
+
+	// A null callback resets the handler to its default state
+	executionContext.PushErrorHandler(runtime.ErrorHandler{Callback: args[0], Levels: args[1].(*values.Int).Value})
+	return previous, nil
+}
+
+// -------------------------------------- set_exception_handler -------------------------------------- MARK: set_exception_handler
+
+func nativeFn_set_exception_handler(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.set-exception-handler.php
+
+	args, err := funcParamValidator.NewValidator("set_exception_handler").AddParam("$callback", []string{"mixed"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if err := context.ValidateCallback("set_exception_handler", 1, "$callback", args[0], true); err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Returns the previously defined exception handler, or null on error. If no previous handler was defined, null is also returned.
+	executionContext := context.Interpreter.GetExectionContext()
+	var previous values.RuntimeValue = values.NewNull()
+	if handler, found := executionContext.GetExceptionHandler(); found {
+		previous = handler
+	}
+
+	executionContext.PushExceptionHandler(args[0])
+	return previous, nil
+}
+
+// -------------------------------------- trigger_error -------------------------------------- MARK: trigger_error
+
+func nativeFn_trigger_error(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.trigger-error.php
+	// Spec: https://www.php.net/manual/en/function.user-error.php
+
+	args, err := funcParamValidator.NewValidator("trigger_error").
+		AddParam("$message", []string{"string"}, nil).
+		AddParam("$error_level", []string{"int"}, values.NewInt(phpError.E_USER_NOTICE)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	level := args[1].(*values.Int).Value
+	if level != phpError.E_USER_ERROR && level != phpError.E_USER_WARNING && level != phpError.E_USER_NOTICE && level != phpError.E_USER_DEPRECATED {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: trigger_error(): Argument #2 ($error_level) must be one of E_USER_ERROR, E_USER_WARNING, E_USER_NOTICE, or E_USER_DEPRECATED in %s",
+			context.Stmt.GetPosString(),
+		)
+	}
+
+	errorType, _ := phpError.GetErrorType(level)
+	userErr := phpError.NewUserError(errorType, "%s in %s", args[0].(*values.Str).Value, context.Stmt.GetPosString())
+
+	// An unhandled E_USER_ERROR terminates the script
+	if level == phpError.E_USER_ERROR {
+		if !context.Interpreter.CallErrorHandler(userErr) {
+			return values.NewVoid(), userErr
+		}
+		return values.NewBool(true), nil
+	}
+
+	context.Interpreter.PrintError(userErr)
+	return values.NewBool(true), nil
+}
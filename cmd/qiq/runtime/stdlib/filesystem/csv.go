@@ -0,0 +1,119 @@
+package filesystem
+
+import (
+	"QIQ/cmd/qiq/runtime/values"
+	"strings"
+)
+
+// No escape character is used if the escape argument is an empty string
+const NoEscape = -1
+
+// Remove the line ending "\n", "\r\n" or "\r"
+func TrimLineEnding(str string) string {
+	if strings.HasSuffix(str, "\n") {
+		str = str[:len(str)-1]
+	}
+	return strings.TrimSuffix(str, "\r")
+}
+
+func isCsvSpace(char byte) bool { return char == ' ' || (char >= '\t' && char <= '\r') }
+
+// Parse the CSV line into fields like fgetcsv.
+// Lines are appended with nextLine as long as an enclosed field continues in the next line.
+// An empty line results in an array with a single null.
+func ParseCsv(line string, separator byte, enclosure byte, escape int, nextLine func() (string, bool)) *values.Array {
+	result := values.NewArray()
+	if TrimLineEnding(line) == "" {
+		result.SetElement(nil, values.NewNull())
+		return result
+	}
+
+	pos := 0
+	for {
+		var field strings.Builder
+
+		// Whitespace before an enclosure is skipped
+		start := pos
+		for start < len(line) && line[start] != separator && isCsvSpace(line[start]) {
+			start++
+		}
+		if start < len(line) && line[start] == enclosure {
+			pos = start + 1
+			for {
+				if pos >= len(line) {
+					// The enclosed field continues in the next line
+					next, ok := nextLine()
+					if !ok {
+						break
+					}
+					line += next
+					continue
+				}
+				char := line[pos]
+				if escape != NoEscape && int(char) == escape && escape != int(enclosure) && pos+1 < len(line) {
+					// The escape character and the escaped character are kept
+					field.WriteString(line[pos : pos+2])
+					pos += 2
+					continue
+				}
+				if char == enclosure {
+					if pos+1 < len(line) && line[pos+1] == enclosure {
+						field.WriteByte(enclosure)
+						pos += 2
+						continue
+					}
+					pos++
+					break
+				}
+				field.WriteByte(char)
+				pos++
+			}
+		}
+
+		// Unenclosed field or the text after the closing enclosure
+		end := strings.IndexByte(line[pos:], separator)
+		if end >= 0 {
+			field.WriteString(line[pos : pos+end])
+			result.SetElement(nil, values.NewStr(field.String()))
+			pos += end + 1
+			continue
+		}
+		field.WriteString(TrimLineEnding(line[pos:]))
+		result.SetElement(nil, values.NewStr(field.String()))
+		return result
+	}
+}
+
+// Format the fields as CSV line like fputcsv.
+// Fields containing the separator, the enclosure, the escape character, or whitespace are enclosed.
+func FormatCsv(fields []string, separator byte, enclosure byte, escape int, eol string) string {
+	var builder strings.Builder
+	for index, field := range fields {
+		if index > 0 {
+			builder.WriteByte(separator)
+		}
+		needsEnclosure := strings.ContainsAny(field, string([]byte{separator, enclosure})+"\n\r\t ") ||
+			(escape != NoEscape && strings.IndexByte(field, byte(escape)) >= 0)
+		if !needsEnclosure {
+			builder.WriteString(field)
+			continue
+		}
+
+		builder.WriteByte(enclosure)
+		escaped := false
+		for i := 0; i < len(field); i++ {
+			char := field[i]
+			if escape != NoEscape && int(char) == escape {
+				escaped = true
+			} else if !escaped && char == enclosure {
+				builder.WriteByte(enclosure)
+			} else {
+				escaped = false
+			}
+			builder.WriteByte(char)
+		}
+		builder.WriteByte(enclosure)
+	}
+	builder.WriteString(eol)
+	return builder.String()
+}
@@ -1,91 +1,435 @@
 package filesystem
 
 import (
-	"QIQ/cmd/qiq/common"
 	"QIQ/cmd/qiq/phpError"
 	"QIQ/cmd/qiq/runtime"
 	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/stream"
 	"QIQ/cmd/qiq/runtime/values"
+	"io/fs"
+	"math/rand/v2"
 	"os"
+	"path/filepath"
 	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Spec: https://www.php.net/manual/en/filesystem.constants.php
+const (
+	FILE_USE_INCLUDE_PATH   int64 = 1
+	FILE_IGNORE_NEW_LINES   int64 = 2
+	FILE_SKIP_EMPTY_LINES   int64 = 4
+	FILE_APPEND             int64 = 8
+	FILE_NO_DEFAULT_CONTEXT int64 = 16
 )
 
 func Register(environment runtime.Environment) {
 	// Category: Filesystem Functions
+	environment.AddNativeFunction("basename", nativeFn_basename)
+	environment.AddNativeFunction("chmod", nativeFn_chmod)
+	environment.AddNativeFunction("clearstatcache", nativeFn_clearstatcache)
+	environment.AddNativeFunction("copy", nativeFn_copy)
+	environment.AddNativeFunction("dirname", nativeFn_dirname)
+	environment.AddNativeFunction("file", nativeFn_file)
+	environment.AddNativeFunction("file_exists", nativeFn_file_exists)
 	environment.AddNativeFunction("file_get_contents", nativeFn_file_get_contents)
+	environment.AddNativeFunction("file_put_contents", nativeFn_file_put_contents)
+	environment.AddNativeFunction("fileatime", nativeFn_fileatime)
+	environment.AddNativeFunction("filectime", nativeFn_filectime)
+	environment.AddNativeFunction("filegroup", nativeFn_filegroup)
+	environment.AddNativeFunction("fileinode", nativeFn_fileinode)
+	environment.AddNativeFunction("filemtime", nativeFn_filemtime)
+	environment.AddNativeFunction("fileowner", nativeFn_fileowner)
+	environment.AddNativeFunction("fileperms", nativeFn_fileperms)
+	environment.AddNativeFunction("filesize", nativeFn_filesize)
+	environment.AddNativeFunction("filetype", nativeFn_filetype)
+	environment.AddNativeFunction("fnmatch", nativeFn_fnmatch)
+	environment.AddNativeFunction("fstat", nativeFn_fstat)
+	environment.AddNativeFunction("glob", nativeFn_glob)
 	environment.AddNativeFunction("is_dir", nativeFn_is_dir)
+	environment.AddNativeFunction("is_executable", nativeFn_is_executable)
 	environment.AddNativeFunction("is_file", nativeFn_is_file)
+	environment.AddNativeFunction("is_link", nativeFn_is_link)
+	environment.AddNativeFunction("is_readable", nativeFn_is_readable)
 	environment.AddNativeFunction("is_uploaded_file", nativeFn_is_uploaded_file)
-	environment.AddNativeFunction("file_exists", nativeFn_file_exists)
+	environment.AddNativeFunction("is_writable", nativeFn_is_writable)
+	environment.AddNativeFunction("is_writeable", nativeFn_is_writeable)
+	environment.AddNativeFunction("link", nativeFn_link)
+	environment.AddNativeFunction("lstat", nativeFn_lstat)
+	environment.AddNativeFunction("mkdir", nativeFn_mkdir)
+	environment.AddNativeFunction("pathinfo", nativeFn_pathinfo)
+	environment.AddNativeFunction("readfile", nativeFn_readfile)
+	environment.AddNativeFunction("readlink", nativeFn_readlink)
+	environment.AddNativeFunction("realpath", nativeFn_realpath)
 	environment.AddNativeFunction("rename", nativeFn_rename)
+	environment.AddNativeFunction("rmdir", nativeFn_rmdir)
+	environment.AddNativeFunction("stat", nativeFn_stat)
+	environment.AddNativeFunction("symlink", nativeFn_symlink)
+	environment.AddNativeFunction("tempnam", nativeFn_tempnam)
+	environment.AddNativeFunction("tmpfile", nativeFn_tmpfile)
+	environment.AddNativeFunction("touch", nativeFn_touch)
+	environment.AddNativeFunction("unlink", nativeFn_unlink)
+	// Streams
+	environment.AddNativeFunction("fclose", nativeFn_fclose)
+	environment.AddNativeFunction("feof", nativeFn_feof)
+	environment.AddNativeFunction("fflush", nativeFn_fflush)
+	environment.AddNativeFunction("fgetc", nativeFn_fgetc)
+	environment.AddNativeFunction("fgetcsv", nativeFn_fgetcsv)
+	environment.AddNativeFunction("fgets", nativeFn_fgets)
+	environment.AddNativeFunctionByRef("flock", nativeFn_flock, runtime.NewByRefParams(2))
+	environment.AddNativeFunction("fopen", nativeFn_fopen)
+	environment.AddNativeFunction("fpassthru", nativeFn_fpassthru)
+	environment.AddNativeFunction("fputcsv", nativeFn_fputcsv)
+	environment.AddNativeFunction("fputs", nativeFn_fputs)
+	environment.AddNativeFunction("fread", nativeFn_fread)
+	environment.AddNativeFunction("fseek", nativeFn_fseek)
+	environment.AddNativeFunction("ftell", nativeFn_ftell)
+	environment.AddNativeFunction("ftruncate", nativeFn_ftruncate)
+	environment.AddNativeFunction("fwrite", nativeFn_fwrite)
+	environment.AddNativeFunction("rewind", nativeFn_rewind)
+
+	// Const Category: Filesystem Constants
+	// Spec: https://www.php.net/manual/en/filesystem.constants.php
+	environment.AddPredefinedConstant("SEEK_SET", values.NewInt(int64(stream.SEEK_SET)))
+	environment.AddPredefinedConstant("SEEK_CUR", values.NewInt(int64(stream.SEEK_CUR)))
+	environment.AddPredefinedConstant("SEEK_END", values.NewInt(int64(stream.SEEK_END)))
+	environment.AddPredefinedConstant("LOCK_SH", values.NewInt(int64(stream.LOCK_SH)))
+	environment.AddPredefinedConstant("LOCK_EX", values.NewInt(int64(stream.LOCK_EX)))
+	environment.AddPredefinedConstant("LOCK_UN", values.NewInt(int64(stream.LOCK_UN)))
+	environment.AddPredefinedConstant("LOCK_NB", values.NewInt(int64(stream.LOCK_NB)))
+	environment.AddPredefinedConstant("FILE_USE_INCLUDE_PATH", values.NewInt(FILE_USE_INCLUDE_PATH))
+	environment.AddPredefinedConstant("FILE_IGNORE_NEW_LINES", values.NewInt(FILE_IGNORE_NEW_LINES))
+	environment.AddPredefinedConstant("FILE_SKIP_EMPTY_LINES", values.NewInt(FILE_SKIP_EMPTY_LINES))
+	environment.AddPredefinedConstant("FILE_APPEND", values.NewInt(FILE_APPEND))
+	environment.AddPredefinedConstant("FILE_NO_DEFAULT_CONTEXT", values.NewInt(FILE_NO_DEFAULT_CONTEXT))
+	environment.AddPredefinedConstant("FNM_NOESCAPE", values.NewInt(int64(FNM_NOESCAPE)))
+	environment.AddPredefinedConstant("FNM_PATHNAME", values.NewInt(int64(FNM_PATHNAME)))
+	environment.AddPredefinedConstant("FNM_PERIOD", values.NewInt(int64(FNM_PERIOD)))
+	environment.AddPredefinedConstant("FNM_CASEFOLD", values.NewInt(int64(FNM_CASEFOLD)))
+	environment.AddPredefinedConstant("GLOB_AVAILABLE_FLAGS", values.NewInt(int64(GLOB_AVAILABLE_FLAGS)))
+	environment.AddPredefinedConstant("GLOB_BRACE", values.NewInt(int64(GLOB_BRACE)))
+	environment.AddPredefinedConstant("GLOB_ERR", values.NewInt(int64(GLOB_ERR)))
+	environment.AddPredefinedConstant("GLOB_MARK", values.NewInt(int64(GLOB_MARK)))
+	environment.AddPredefinedConstant("GLOB_NOCHECK", values.NewInt(int64(GLOB_NOCHECK)))
+	environment.AddPredefinedConstant("GLOB_NOESCAPE", values.NewInt(int64(GLOB_NOESCAPE)))
+	environment.AddPredefinedConstant("GLOB_NOSORT", values.NewInt(int64(GLOB_NOSORT)))
+	environment.AddPredefinedConstant("GLOB_ONLYDIR", values.NewInt(int64(GLOB_ONLYDIR)))
+	environment.AddPredefinedConstant("PATHINFO_DIRNAME", values.NewInt(PATHINFO_DIRNAME))
+	environment.AddPredefinedConstant("PATHINFO_BASENAME", values.NewInt(PATHINFO_BASENAME))
+	environment.AddPredefinedConstant("PATHINFO_EXTENSION", values.NewInt(PATHINFO_EXTENSION))
+	environment.AddPredefinedConstant("PATHINFO_FILENAME", values.NewInt(PATHINFO_FILENAME))
+	environment.AddPredefinedConstant("PATHINFO_ALL", values.NewInt(PATHINFO_ALL))
+	// Spec: https://www.php.net/manual/en/features.commandline.io-streams.php
+	environment.AddPredefinedConstant("STDIN", values.NewResource(1, "stream", stream.NewStdioStream(os.Stdin, true, false)))
+	environment.AddPredefinedConstant("STDOUT", values.NewResource(2, "stream", stream.NewStdioStream(os.Stdout, false, true)))
+	environment.AddPredefinedConstant("STDERR", values.NewResource(3, "stream", stream.NewStdioStream(os.Stderr, false, true)))
 }
 
-// -------------------------------------- file_get_contents -------------------------------------- MARK: file_get_contents
+// Open the stream. If the stream cannot be opened, the warning "Failed to open stream" is printed.
+func openStream(functionName string, filename string, mode string, context runtime.Context) (*stream.Stream, bool) {
+	fileStream, err := stream.Open(filename, mode, context)
+	if err != nil {
+		context.Interpreter.PrintError(phpError.NewWarning("%s(%s): Failed to open stream: %s%s", functionName, filename, err, context.InPosition()))
+		return nil, false
+	}
+	return fileStream, true
+}
 
-func nativeFn_file_get_contents(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
-	// Spec: https://www.php.net/manual/function.file-get-contents.php
+// Print the warning with the message of the system error (e.g. "mkdir(): File exists")
+func printSystemWarning(prefix string, err error, context runtime.Context) {
+	context.Interpreter.PrintError(phpError.NewWarning("%s: %s%s", prefix, stream.ErrorMessage(err), context.InPosition()))
+}
+
+// -------------------------------------- chmod -------------------------------------- MARK: chmod
 
-	args, err := funcParamValidator.NewValidator("file_get_contents").AddParam("$filename", []string{"string"}, nil).Validate(args)
+func nativeFn_chmod(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.chmod.php
+	args, err := funcParamValidator.NewValidator("chmod").
+		AddParam("$filename", []string{"string"}, nil).
+		AddParam("$permissions", []string{"int"}, nil).
+		Validate(args)
 	if err != nil {
 		return values.NewVoid(), err
 	}
 
-	// TODO file_get_contents: Add support for other params
-	//  file_get_contents(
-	// string $filename,
-	// bool $use_include_path = false,
-	// ?resource $context = null,
-	// int $offset = 0,
-	// ?int $length = null
-	// ): string|false
+	// The bits setuid, setgid and sticky have a different representation in Go
+	permissions := args[1].(*values.Int).Value
+	mode := fs.FileMode(permissions & 0777)
+	if permissions&04000 != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if permissions&02000 != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if permissions&01000 != 0 {
+		mode |= fs.ModeSticky
+	}
 
 	filename := args[0].(*values.Str).Value
+	if !stream.CheckOpenBasedir("chmod", filename, context) {
+		return values.NewBool(false), nil
+	}
+	context.Interpreter.GetExectionContext().ClearStatCache()
+	if goErr := os.Chmod(stream.ResolvePath(filename, context), mode); goErr != nil {
+		printSystemWarning("chmod()", goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewBool(true), nil
+}
+
+// -------------------------------------- copy -------------------------------------- MARK: copy
+
+func nativeFn_copy(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.copy.php
+	args, err := funcParamValidator.NewValidator("copy").
+		AddParam("$from", []string{"string"}, nil).
+		AddParam("$to", []string{"string"}, nil).
+		AddParam("$context", []string{"resource", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	from := args[0].(*values.Str).Value
+	to := args[1].(*values.Str).Value
+	if info, goErr := StatFile(from, false, context); goErr == nil && info.IsDir() {
+		context.Interpreter.PrintError(phpError.NewWarning("copy(): The first argument to copy() function cannot be a directory%s", context.InPosition()))
+		return values.NewBool(false), nil
+	}
+
+	source, ok := openStream("copy", from, "rb", context)
+	if !ok {
+		return values.NewBool(false), nil
+	}
+	defer source.Close()
+
+	if info, goErr := StatFile(to, false, context); goErr == nil && info.IsDir() {
+		context.Interpreter.PrintError(phpError.NewWarning("copy(): The second argument to copy() function cannot be a directory%s", context.InPosition()))
+		return values.NewBool(false), nil
+	}
 
-	if !common.PathExists(filename) {
-		return values.NewBool(false), phpError.NewWarning("could not find file %s", filename)
+	target, ok := openStream("copy", to, "wb", context)
+	if !ok {
+		return values.NewBool(false), nil
 	}
+	defer target.Close()
 
-	content, goErr := common.ReadFile(filename)
+	content, goErr := source.ReadAll()
 	if goErr != nil {
-		return values.NewBool(false), phpError.NewError("%s", goErr)
+		PrintIoNotice("copy", "Read", 8192, goErr, context)
+		return values.NewBool(false), nil
 	}
-	return values.NewStr(content), nil
+	if _, goErr := target.Write(content); goErr != nil {
+		PrintIoNotice("copy", "Write", len(content), goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewBool(true), nil
 }
 
-// -------------------------------------- is_dir -------------------------------------- MARK: is_dir
-
-func nativeFn_is_dir(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
-	// Spec: https://www.php.net/manual/en/function.is-dir.php
+// -------------------------------------- file -------------------------------------- MARK: file
 
-	args, err := funcParamValidator.NewValidator("is_dir").AddParam("$filename", []string{"string"}, nil).Validate(args)
+func nativeFn_file(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.file.php
+	args, err := funcParamValidator.NewValidator("file").
+		AddParam("$filename", []string{"string"}, nil).
+		AddParam("$flags", []string{"int"}, values.NewInt(0)).
+		AddParam("$context", []string{"resource", "null"}, values.NewNull()).
+		Validate(args)
 	if err != nil {
 		return values.NewVoid(), err
 	}
 
-	info, goErr := os.Stat(args[0].(*values.Str).Value)
-	if goErr != nil {
-		return values.NewVoid(), phpError.NewError("%s", goErr)
+	// TODO file: Add support for FILE_USE_INCLUDE_PATH
+
+	flags := args[1].(*values.Int).Value
+	if flags < 0 || flags&^(FILE_USE_INCLUDE_PATH|FILE_IGNORE_NEW_LINES|FILE_SKIP_EMPTY_LINES|FILE_NO_DEFAULT_CONTEXT) != 0 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: file(): Argument #2 ($flags) must be a valid flag value")
 	}
 
-	return values.NewBool(info.IsDir()), nil
+	content, ok := readContent("file", args[0].(*values.Str).Value, context)
+	if !ok {
+		return values.NewBool(false), nil
+	}
+
+	lines := values.NewArray()
+	for content != "" {
+		line := content
+		if index := strings.IndexByte(content, '\n'); index >= 0 {
+			line = content[:index+1]
+		}
+		content = content[len(line):]
+
+		if flags&FILE_IGNORE_NEW_LINES != 0 {
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+			// Empty lines can only be skipped if the new lines are ignored
+			if line == "" && flags&FILE_SKIP_EMPTY_LINES != 0 {
+				continue
+			}
+		}
+		lines.SetElement(nil, values.NewStr(line))
+	}
+	return lines, nil
 }
 
-// -------------------------------------- is_file -------------------------------------- MARK: is_file
+// Read the content of the file. Warnings are printed if the file cannot be opened or read.
+func readContent(functionName string, filename string, context runtime.Context) (string, bool) {
+	fileStream, ok := openStream(functionName, filename, "rb", context)
+	if !ok {
+		return "", false
+	}
+	defer fileStream.Close()
 
-func nativeFn_is_file(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
-	// Spec: https://www.php.net/manual/en/function.is-file.php
+	content, err := fileStream.ReadAll()
+	if err != nil {
+		PrintIoNotice(functionName, "Read", 8192, err, context)
+		return "", true
+	}
+	return content, true
+}
 
-	args, err := funcParamValidator.NewValidator("is_file").AddParam("$filename", []string{"string"}, nil).Validate(args)
+// -------------------------------------- file_get_contents -------------------------------------- MARK: file_get_contents
+
+func nativeFn_file_get_contents(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/function.file-get-contents.php
+	args, err := funcParamValidator.NewValidator("file_get_contents").
+		AddParam("$filename", []string{"string"}, nil).
+		AddParam("$use_include_path", []string{"bool"}, values.NewBool(false)).
+		AddParam("$context", []string{"resource", "null"}, values.NewNull()).
+		AddParam("$offset", []string{"int"}, values.NewInt(0)).
+		AddParam("$length", []string{"int", "null"}, values.NewNull()).
+		Validate(args)
 	if err != nil {
 		return values.NewVoid(), err
 	}
 
-	info, goErr := os.Stat(args[0].(*values.Str).Value)
+	// TODO file_get_contents: Add support for use_include_path and context
+
+	filename := args[0].(*values.Str).Value
+	if filename == "" {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: Path cannot be empty")
+	}
+	length := int64(-1)
+	if args[4].GetType() == values.IntValue {
+		length = args[4].(*values.Int).Value
+		if length < 0 {
+			return values.NewVoid(), phpError.NewError("Uncaught ValueError: file_get_contents(): Argument #5 ($length) must be greater than or equal to 0")
+		}
+	}
+
+	fileStream, ok := openStream("file_get_contents", filename, "rb", context)
+	if !ok {
+		return values.NewBool(false), nil
+	}
+	defer fileStream.Close()
+
+	// A negative offset is counted from the end of the stream
+	offset := args[3].(*values.Int).Value
+	if offset != 0 {
+		whence := stream.SEEK_SET
+		if offset < 0 {
+			whence = stream.SEEK_END
+		}
+		if !fileStream.SetPosition(offset, whence) {
+			context.Interpreter.PrintError(phpError.NewWarning("file_get_contents(): Failed to seek to position %d in the stream%s", offset, context.InPosition()))
+			return values.NewBool(false), nil
+		}
+	}
+
+	var content string
+	var goErr error
+	if length >= 0 {
+		content, goErr = fileStream.Read(int(length))
+	} else {
+		content, goErr = fileStream.ReadAll()
+	}
 	if goErr != nil {
-		return values.NewVoid(), phpError.NewError("%s", goErr)
+		PrintIoNotice("file_get_contents", "Read", 8192, goErr, context)
+		return values.NewStr(""), nil
 	}
+	return values.NewStr(content), nil
+}
+
+// -------------------------------------- file_put_contents -------------------------------------- MARK: file_put_contents
 
-	return values.NewBool(!info.IsDir()), nil
+func nativeFn_file_put_contents(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.file-put-contents.php
+	args, err := funcParamValidator.NewValidator("file_put_contents").
+		AddParam("$filename", []string{"string"}, nil).
+		AddParam("$data", []string{"mixed"}, nil).
+		AddParam("$flags", []string{"int"}, values.NewInt(0)).
+		AddParam("$context", []string{"resource", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	var data string
+	switch value := args[1].(type) {
+	case *values.Array:
+		// The elements of an array are written one after another
+		var builder strings.Builder
+		for _, key := range value.Keys {
+			slot, _ := value.GetElement(key)
+			str, err := variableHandling.StrVal(slot.Value)
+			if err != nil {
+				return values.NewVoid(), err
+			}
+			builder.WriteString(str)
+		}
+		data = builder.String()
+	case *values.Resource:
+		// The remaining data of a stream is copied
+		source, err := GetStream("file_put_contents", value)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		var goErr error
+		if data, goErr = source.ReadAll(); goErr != nil {
+			PrintIoNotice("file_put_contents", "Read", 8192, goErr, context)
+			return values.NewBool(false), nil
+		}
+	default:
+		data, err = variableHandling.StrVal(value)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+	}
+
+	filename := args[0].(*values.Str).Value
+	flags := args[2].(*values.Int).Value
+	mode := "wb"
+	if flags&FILE_APPEND != 0 {
+		mode = "ab"
+	} else if flags&int64(stream.LOCK_EX) != 0 {
+		// The file is truncated after the lock is acquired
+		mode = "cb"
+	}
+
+	fileStream, ok := openStream("file_put_contents", filename, mode, context)
+	if !ok {
+		return values.NewBool(false), nil
+	}
+	defer fileStream.Close()
+
+	if flags&int64(stream.LOCK_EX) != 0 {
+		if ok, _ := fileStream.Lock(stream.LOCK_EX); !ok {
+			context.Interpreter.PrintError(phpError.NewWarning("file_put_contents(): Exclusive locks are not supported for this stream%s", context.InPosition()))
+			return values.NewBool(false), nil
+		}
+		if flags&FILE_APPEND == 0 {
+			fileStream.Truncate(0)
+		}
+	}
+
+	written, goErr := fileStream.Write(data)
+	if goErr != nil {
+		PrintIoNotice("file_put_contents", "Write", len(data), goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewInt(int64(written)), nil
 }
 
 // -------------------------------------- is_uploaded_file -------------------------------------- MARK: is_uploaded_file
@@ -101,116 +445,348 @@ func nativeFn_is_uploaded_file(args []values.RuntimeValue, context runtime.Conte
 	return values.NewBool(slices.Contains(context.Interpreter.GetRequest().UploadedFiles, args[0].(*values.Str).Value)), nil
 }
 
-// -------------------------------------- file_exists -------------------------------------- MARK: file_exists
+// -------------------------------------- link -------------------------------------- MARK: link
+
+func nativeFn_link(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.link.php
+	args, err := funcParamValidator.NewValidator("link").
+		AddParam("$target", []string{"string"}, nil).
+		AddParam("$link", []string{"string"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	target := args[0].(*values.Str).Value
+	link := args[1].(*values.Str).Value
+	if !stream.CheckOpenBasedir("link", link, context) || !stream.CheckOpenBasedir("link", target, context) {
+		return values.NewBool(false), nil
+	}
+	if goErr := os.Link(stream.ResolvePath(target, context), stream.ResolvePath(link, context)); goErr != nil {
+		printSystemWarning("link()", goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewBool(true), nil
+}
 
-func nativeFn_file_exists(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
-	// Spec: https://www.php.net/manual/en/function.file-exists.php
+// -------------------------------------- mkdir -------------------------------------- MARK: mkdir
 
-	args, err := funcParamValidator.NewValidator("file_exists").AddParam("$filename", []string{"string"}, nil).Validate(args)
+func nativeFn_mkdir(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.mkdir.php
+	args, err := funcParamValidator.NewValidator("mkdir").
+		AddParam("$directory", []string{"string"}, nil).
+		AddParam("$permissions", []string{"int"}, values.NewInt(0777)).
+		AddParam("$recursive", []string{"bool"}, values.NewBool(false)).
+		AddParam("$context", []string{"resource", "null"}, values.NewNull()).
+		Validate(args)
 	if err != nil {
 		return values.NewVoid(), err
 	}
 
-	return values.NewBool(Exists(args[0].(*values.Str).Value)), nil
+	if !stream.CheckOpenBasedir("mkdir", args[0].(*values.Str).Value, context) {
+		return values.NewBool(false), nil
+	}
+	directory := stream.ResolvePath(args[0].(*values.Str).Value, context)
+	permissions := fs.FileMode(args[1].(*values.Int).Value & 0777)
+
+	var goErr error
+	if args[2].(*values.Bool).Value {
+		// MkdirAll does not fail for existing directories
+		if _, statErr := os.Stat(directory); statErr == nil {
+			goErr = syscall.EEXIST
+		} else {
+			goErr = os.MkdirAll(directory, permissions)
+		}
+	} else {
+		goErr = os.Mkdir(directory, permissions)
+	}
+	if goErr != nil {
+		printSystemWarning("mkdir()", goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewBool(true), nil
 }
 
-func Exists(filename string) bool {
-	_, err := os.Stat(filename)
-	return err == nil
+// -------------------------------------- readfile -------------------------------------- MARK: readfile
+
+func nativeFn_readfile(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.readfile.php
+	args, err := funcParamValidator.NewValidator("readfile").
+		AddParam("$filename", []string{"string"}, nil).
+		AddParam("$use_include_path", []string{"bool"}, values.NewBool(false)).
+		AddParam("$context", []string{"resource", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// TODO readfile: Add support for use_include_path
+
+	content, ok := readContent("readfile", args[0].(*values.Str).Value, context)
+	if !ok {
+		return values.NewBool(false), nil
+	}
+	context.Interpreter.Print(content)
+	return values.NewInt(int64(len(content))), nil
+}
+
+// -------------------------------------- readlink -------------------------------------- MARK: readlink
+
+func nativeFn_readlink(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.readlink.php
+	args, err := funcParamValidator.NewValidator("readlink").AddParam("$path", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	path := args[0].(*values.Str).Value
+	if !stream.CheckOpenBasedir("readlink", path, context) {
+		return values.NewBool(false), nil
+	}
+	target, goErr := os.Readlink(stream.ResolvePath(path, context))
+	if goErr != nil {
+		printSystemWarning("readlink()", goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewStr(target), nil
 }
 
 // -------------------------------------- rename -------------------------------------- MARK: rename
 
-func nativeFn_rename(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+func nativeFn_rename(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
 	// Spec: https://www.php.net/manual/en/function.rename.php
 
 	args, err := funcParamValidator.NewValidator("rename").
 		AddParam("$from", []string{"string"}, nil).
 		AddParam("$to", []string{"string"}, nil).
+		AddParam("$context", []string{"resource", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	from := args[0].(*values.Str).Value
+	to := args[1].(*values.Str).Value
+	if !stream.CheckOpenBasedir("rename", from, context) || !stream.CheckOpenBasedir("rename", to, context) {
+		return values.NewBool(false), nil
+	}
+
+	context.Interpreter.GetExectionContext().ClearStatCache()
+	if goErr := os.Rename(stream.ResolvePath(from, context), stream.ResolvePath(to, context)); goErr != nil {
+		printSystemWarning("rename("+from+","+to+")", goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewBool(true), nil
+}
+
+// -------------------------------------- rmdir -------------------------------------- MARK: rmdir
+
+func nativeFn_rmdir(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.rmdir.php
+	args, err := funcParamValidator.NewValidator("rmdir").
+		AddParam("$directory", []string{"string"}, nil).
+		AddParam("$context", []string{"resource", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	directory := args[0].(*values.Str).Value
+	if !stream.CheckOpenBasedir("rmdir", directory, context) {
+		return values.NewBool(false), nil
+	}
+	context.Interpreter.GetExectionContext().ClearStatCache()
+	if goErr := syscall.Rmdir(stream.ResolvePath(directory, context)); goErr != nil {
+		printSystemWarning("rmdir("+directory+")", goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewBool(true), nil
+}
+
+// -------------------------------------- symlink -------------------------------------- MARK: symlink
+
+func nativeFn_symlink(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.symlink.php
+	args, err := funcParamValidator.NewValidator("symlink").
+		AddParam("$target", []string{"string"}, nil).
+		AddParam("$link", []string{"string"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// The target is stored as given because relative targets are relative to the link
+	target := args[0].(*values.Str).Value
+	link := args[1].(*values.Str).Value
+	resolvedTarget := target
+	if !filepath.IsAbs(target) {
+		resolvedTarget = filepath.Join(filepath.Dir(stream.ResolvePath(link, context)), target)
+	}
+	if !stream.CheckOpenBasedir("symlink", link, context) || !stream.CheckOpenBasedir("symlink", resolvedTarget, context) {
+		return values.NewBool(false), nil
+	}
+	if goErr := os.Symlink(target, stream.ResolvePath(link, context)); goErr != nil {
+		printSystemWarning("symlink()", goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewBool(true), nil
+}
+
+// -------------------------------------- tempnam -------------------------------------- MARK: tempnam
+
+func nativeFn_tempnam(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.tempnam.php
+	args, err := funcParamValidator.NewValidator("tempnam").
+		AddParam("$directory", []string{"string"}, nil).
+		AddParam("$prefix", []string{"string"}, nil).
 		Validate(args)
 	if err != nil {
 		return values.NewVoid(), err
 	}
 
-	goErr := os.Rename(args[0].(*values.Str).Value, args[1].(*values.Str).Value)
-	return values.NewBool(goErr == nil), nil
+	prefix := Basename(args[1].(*values.Str).Value, "")
+	if len(prefix) > 63 {
+		prefix = prefix[:63]
+	}
+
+	// The temporary directory is used if no directory is given or with a notice if the directory does not exist
+	directory := args[0].(*values.Str).Value
+	if directory == "" {
+		directory = TempDir(context)
+	} else if info, goErr := os.Stat(stream.ResolvePath(directory, context)); goErr != nil || !info.IsDir() {
+		context.Interpreter.PrintError(phpError.NewNotice("tempnam(): file created in the system's temporary directory%s", context.InPosition()))
+		directory = TempDir(context)
+	}
+	directory, ok := Realpath(directory, context)
+	if !ok || !stream.CheckOpenBasedir("tempnam", directory, context) {
+		return values.NewBool(false), nil
+	}
+
+	const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	for range 100 {
+		suffix := make([]byte, 6)
+		for index := range suffix {
+			suffix[index] = chars[rand.IntN(len(chars))]
+		}
+		filename := filepath.Join(directory, prefix+string(suffix))
+		file, goErr := os.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if goErr == nil {
+			file.Close()
+			return values.NewStr(filename), nil
+		}
+		if !os.IsExist(goErr) {
+			break
+		}
+	}
+	return values.NewBool(false), nil
+}
+
+// -------------------------------------- tmpfile -------------------------------------- MARK: tmpfile
+
+func nativeFn_tmpfile(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.tmpfile.php
+	_, err := funcParamValidator.NewValidator("tmpfile").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, goErr := stream.OpenTempFile(TempDir(context))
+	if goErr != nil {
+		context.Interpreter.PrintError(phpError.NewWarning("tmpfile(): %s%s", goErr, context.InPosition()))
+		return values.NewBool(false), nil
+	}
+	// The file is deleted when the stream is closed, the resource is released or the script ends
+	return context.Interpreter.GetExectionContext().NewReleasableResource("stream", fileStream, func() { fileStream.Close() }), nil
+}
+
+// -------------------------------------- touch -------------------------------------- MARK: touch
+
+func nativeFn_touch(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.touch.php
+	args, err := funcParamValidator.NewValidator("touch").
+		AddParam("$filename", []string{"string"}, nil).
+		AddParam("$mtime", []string{"int", "null"}, values.NewNull()).
+		AddParam("$atime", []string{"int", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Without atime, the access time is set to mtime
+	mtime := time.Now()
+	if args[1].GetType() == values.IntValue {
+		mtime = time.Unix(args[1].(*values.Int).Value, 0)
+	}
+	atime := mtime
+	if args[2].GetType() == values.IntValue {
+		atime = time.Unix(args[2].(*values.Int).Value, 0)
+	}
+
+	filename := args[0].(*values.Str).Value
+	if !stream.CheckOpenBasedir("touch", filename, context) {
+		return values.NewBool(false), nil
+	}
+	path := stream.ResolvePath(filename, context)
+	context.Interpreter.GetExectionContext().ClearStatCache()
+	if _, goErr := os.Stat(path); goErr != nil {
+		file, goErr := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0666)
+		if goErr != nil {
+			context.Interpreter.PrintError(phpError.NewWarning("touch(): Unable to create file %s because %s%s", filename, stream.ErrorMessage(goErr), context.InPosition()))
+			return values.NewBool(false), nil
+		}
+		file.Close()
+	}
+	if goErr := os.Chtimes(path, atime, mtime); goErr != nil {
+		printSystemWarning("touch(): Utime failed", goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewBool(true), nil
+}
+
+// -------------------------------------- unlink -------------------------------------- MARK: unlink
+
+func nativeFn_unlink(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.unlink.php
+	args, err := funcParamValidator.NewValidator("unlink").
+		AddParam("$filename", []string{"string"}, nil).
+		AddParam("$context", []string{"resource", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// syscall.Unlink is used because os.Remove would also remove empty directories
+	filename := args[0].(*values.Str).Value
+	if !stream.CheckOpenBasedir("unlink", filename, context) {
+		return values.NewBool(false), nil
+	}
+	context.Interpreter.GetExectionContext().ClearStatCache()
+	if goErr := syscall.Unlink(stream.ResolvePath(filename, context)); goErr != nil {
+		printSystemWarning("unlink("+filename+")", goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewBool(true), nil
 }
 
-// TODO basename
 // TODO chgrp
-// TODO chmod
 // TODO chown
-// TODO clearstatcache
-// TODO copy
 // TODO delete
-// TODO dirname
 // TODO disk_​free_​space
 // TODO disk_​total_​space
 // TODO diskfreespace
-// TODO fclose
 // TODO fdatasync
-// TODO feof
-// TODO fflush
-// TODO fgetc
-// TODO fgetcsv
-// TODO fgets
 // TODO fgetss
-// TODO file
-// TODO file_​get_​contents
-// TODO file_​put_​contents
-// TODO fileatime
-// TODO filectime
-// TODO filegroup
-// TODO fileinode
-// TODO filemtime
-// TODO fileowner
-// TODO fileperms
-// TODO filesize
-// TODO filetype
-// TODO flock
-// TODO fnmatch
-// TODO fopen
-// TODO fpassthru
-// TODO fputcsv
-// TODO fputs
-// TODO fread
 // TODO fscanf
-// TODO fseek
-// TODO fstat
 // TODO fsync
-// TODO ftell
-// TODO ftruncate
-// TODO fwrite
-// TODO glob
-// TODO is_​executable
-// TODO is_​link
-// TODO is_​readable
-// TODO is_​writable
-// TODO is_​writeable
 // TODO lchgrp
 // TODO lchown
-// TODO link
 // TODO linkinfo
-// TODO lstat
-// TODO mkdir
 // TODO move_​uploaded_​file
 // TODO parse_​ini_​file
 // TODO parse_​ini_​string
-// TODO pathinfo
 // TODO pclose
 // TODO popen
-// TODO readfile
-// TODO readlink
-// TODO realpath
 // TODO realpath_​cache_​get
 // TODO realpath_​cache_​size
-// TODO rewind
-// TODO rmdir
 // TODO set_​file_​buffer
-// TODO stat
-// TODO symlink
-// TODO tempnam
-// TODO tmpfile
-// TODO touch
 // TODO umask
-// TODO unlink
@@ -0,0 +1,398 @@
+package filesystem
+
+import (
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/stream"
+	"os"
+	"strings"
+)
+
+// Maximum length of a path (MAXPATHLEN)
+const maxPathLength = 4096
+
+// Spec: https://www.php.net/manual/en/function.fnmatch.php
+const (
+	FNM_PATHNAME int = 1
+	FNM_NOESCAPE int = 2
+	FNM_PERIOD   int = 4
+	FNM_CASEFOLD int = 16
+)
+
+// Spec: https://www.php.net/manual/en/function.glob.php
+const (
+	GLOB_ERR             int = 1
+	GLOB_MARK            int = 2
+	GLOB_NOSORT          int = 4
+	GLOB_NOCHECK         int = 16
+	GLOB_NOESCAPE        int = 64
+	GLOB_BRACE           int = 1024
+	GLOB_ONLYDIR         int = 8192
+	GLOB_AVAILABLE_FLAGS int = GLOB_ERR | GLOB_MARK | GLOB_NOSORT | GLOB_NOCHECK | GLOB_NOESCAPE | GLOB_BRACE | GLOB_ONLYDIR
+)
+
+// -------------------------------------- Fnmatch -------------------------------------- MARK: Fnmatch
+
+// Check if the name matches the shell wildcard pattern with the FNM_* flags (like the C function fnmatch)
+func Fnmatch(pattern string, name string, flags int) bool {
+	matcher := &wildcardMatcher{pattern: pattern, name: name, flags: flags}
+	return matcher.match(0, 0)
+}
+
+type wildcardMatcher struct {
+	pattern string
+	name    string
+	flags   int
+}
+
+func (matcher *wildcardMatcher) hasFlag(flag int) bool { return matcher.flags&flag != 0 }
+
+// A leading period must be matched explicitly with FNM_PERIOD
+func (matcher *wildcardMatcher) isLeadingPeriod(pos int) bool {
+	if !matcher.hasFlag(FNM_PERIOD) || pos >= len(matcher.name) || matcher.name[pos] != '.' {
+		return false
+	}
+	return pos == 0 || (matcher.hasFlag(FNM_PATHNAME) && matcher.name[pos-1] == '/')
+}
+
+// Wildcards do not match a slash with FNM_PATHNAME
+func (matcher *wildcardMatcher) isProtectedSlash(pos int) bool {
+	return matcher.hasFlag(FNM_PATHNAME) && matcher.name[pos] == '/'
+}
+
+func (matcher *wildcardMatcher) equalChars(a byte, b byte) bool {
+	if matcher.hasFlag(FNM_CASEFOLD) {
+		return toLower(a) == toLower(b)
+	}
+	return a == b
+}
+
+func (matcher *wildcardMatcher) match(patternPos int, namePos int) bool {
+	pattern, name := matcher.pattern, matcher.name
+	for patternPos < len(pattern) {
+		char := pattern[patternPos]
+		switch {
+		case char == '*':
+			for patternPos < len(pattern) && pattern[patternPos] == '*' {
+				patternPos++
+			}
+			if matcher.isLeadingPeriod(namePos) {
+				return false
+			}
+			for pos := namePos; pos <= len(name); pos++ {
+				if matcher.match(patternPos, pos) {
+					return true
+				}
+				if pos < len(name) && matcher.isProtectedSlash(pos) {
+					return false
+				}
+			}
+			return false
+
+		case char == '?':
+			if namePos >= len(name) || matcher.isProtectedSlash(namePos) || matcher.isLeadingPeriod(namePos) {
+				return false
+			}
+			patternPos++
+			namePos++
+
+		case char == '[':
+			if namePos >= len(name) || matcher.isProtectedSlash(namePos) || matcher.isLeadingPeriod(namePos) {
+				return false
+			}
+			matched, next, ok := matcher.matchBracket(patternPos, name[namePos])
+			if !ok {
+				// Without closing bracket, the bracket is a normal character
+				if name[namePos] != '[' {
+					return false
+				}
+				patternPos++
+				namePos++
+				continue
+			}
+			if !matched {
+				return false
+			}
+			patternPos = next
+			namePos++
+
+		default:
+			if char == '\\' && !matcher.hasFlag(FNM_NOESCAPE) && patternPos+1 < len(pattern) {
+				patternPos++
+				char = pattern[patternPos]
+			}
+			if namePos >= len(name) || !matcher.equalChars(char, name[namePos]) {
+				return false
+			}
+			patternPos++
+			namePos++
+		}
+	}
+	return namePos == len(name)
+}
+
+// Match the character against the bracket expression starting at the position (e.g. "[!a-z]").
+// Returns the position after the expression or false if the expression is not closed.
+func (matcher *wildcardMatcher) matchBracket(start int, char byte) (matched bool, next int, ok bool) {
+	pattern := matcher.pattern
+	pos := start + 1
+	negate := pos < len(pattern) && (pattern[pos] == '!' || pattern[pos] == '^')
+	if negate {
+		pos++
+	}
+
+	first := true
+	for pos < len(pattern) {
+		current := pattern[pos]
+		if current == ']' && !first {
+			return matched != negate, pos + 1, true
+		}
+		first = false
+
+		// Character class (e.g. "[:alpha:]")
+		if current == '[' && pos+1 < len(pattern) && pattern[pos+1] == ':' {
+			if end := strings.Index(pattern[pos+2:], ":]"); end >= 0 {
+				if matchCharClass(pattern[pos+2:pos+2+end], char) {
+					matched = true
+				}
+				pos += end + 4
+				continue
+			}
+		}
+
+		if current == '\\' && !matcher.hasFlag(FNM_NOESCAPE) && pos+1 < len(pattern) {
+			pos++
+			current = pattern[pos]
+		}
+		pos++
+
+		// Range (e.g. "a-z")
+		if pos+1 < len(pattern) && pattern[pos] == '-' && pattern[pos+1] != ']' {
+			last := pattern[pos+1]
+			pos += 2
+			if matcher.hasFlag(FNM_CASEFOLD) {
+				lowerChar := toLower(char)
+				if lowerChar >= toLower(current) && lowerChar <= toLower(last) {
+					matched = true
+				}
+			} else if char >= current && char <= last {
+				matched = true
+			}
+			continue
+		}
+
+		if matcher.equalChars(current, char) {
+			matched = true
+		}
+	}
+	return false, 0, false
+}
+
+func matchCharClass(class string, char byte) bool {
+	switch class {
+	case "alnum":
+		return isAlpha(char) || isDigit(char)
+	case "alpha":
+		return isAlpha(char)
+	case "blank":
+		return char == ' ' || char == '\t'
+	case "digit":
+		return isDigit(char)
+	case "lower":
+		return char >= 'a' && char <= 'z'
+	case "punct":
+		return char > ' ' && char < 127 && !isAlpha(char) && !isDigit(char)
+	case "space":
+		return char == ' ' || (char >= '\t' && char <= '\r')
+	case "upper":
+		return char >= 'A' && char <= 'Z'
+	case "xdigit":
+		return isDigit(char) || (char >= 'a' && char <= 'f') || (char >= 'A' && char <= 'F')
+	default:
+		return false
+	}
+}
+
+func isAlpha(char byte) bool { return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') }
+
+func isDigit(char byte) bool { return char >= '0' && char <= '9' }
+
+func toLower(char byte) byte {
+	if char >= 'A' && char <= 'Z' {
+		return char + 'a' - 'A'
+	}
+	return char
+}
+
+// -------------------------------------- Glob -------------------------------------- MARK: Glob
+
+// Find the paths matching the pattern with the GLOB_* flags (like the C function glob).
+// Relative patterns are resolved against the working directory, but the paths are returned relative.
+func Glob(pattern string, flags int, context runtime.Context) []string {
+	patterns := []string{pattern}
+	if flags&GLOB_BRACE != 0 {
+		patterns = expandBraces(pattern)
+	}
+
+	result := []string{}
+	for _, pattern := range patterns {
+		result = append(result, globPattern(pattern, flags, context)...)
+	}
+	if len(result) == 0 && flags&GLOB_NOCHECK != 0 {
+		return []string{pattern}
+	}
+	return result
+}
+
+func globPattern(pattern string, flags int, context runtime.Context) []string {
+	if pattern == "" {
+		return []string{}
+	}
+
+	candidates := []string{""}
+	if pattern[0] == '/' {
+		candidates = []string{"/"}
+	}
+	components := strings.Split(strings.Trim(pattern, "/"), "/")
+	for index, component := range components {
+		if component == "" {
+			continue
+		}
+		isLast := index == len(components)-1
+		literal := ""
+		if !hasWildcard(component, flags) {
+			literal = component
+			if flags&GLOB_NOESCAPE == 0 {
+				literal = unescapeWildcards(component)
+			}
+		}
+		next := []string{}
+		for _, candidate := range candidates {
+			if literal != "" {
+				path := candidate + literal
+				if isLast {
+					if _, err := os.Lstat(stream.ResolvePath(path, context)); err != nil {
+						continue
+					}
+				}
+				next = append(next, path)
+				continue
+			}
+
+			matchFlags := FNM_PERIOD
+			if flags&GLOB_NOESCAPE != 0 {
+				matchFlags |= FNM_NOESCAPE
+			}
+			for _, name := range readDirNames(stream.ResolvePath(globDir(candidate), context), component) {
+				if Fnmatch(component, name, matchFlags) {
+					next = append(next, candidate+name)
+				}
+			}
+		}
+		if !isLast {
+			for index := range next {
+				next[index] += "/"
+			}
+		}
+		candidates = next
+	}
+
+	result := []string{}
+	for _, path := range candidates {
+		isDir := false
+		if info, err := os.Stat(stream.ResolvePath(path, context)); err == nil {
+			isDir = info.IsDir()
+		}
+		if flags&GLOB_ONLYDIR != 0 && !isDir {
+			continue
+		}
+		if flags&GLOB_MARK != 0 && isDir && !strings.HasSuffix(path, "/") {
+			path += "/"
+		}
+		result = append(result, path)
+	}
+	return result
+}
+
+// Get the directory of the candidate path for reading the entries ("" is the working directory)
+func globDir(candidate string) string {
+	if candidate == "" {
+		return "."
+	}
+	return candidate
+}
+
+// Get the sorted names of the directory entries. "." and ".." are included if the pattern starts with a period.
+func readDirNames(directory string, pattern string) []string {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return []string{}
+	}
+	names := []string{}
+	if strings.HasPrefix(pattern, ".") {
+		names = append(names, ".", "..")
+	}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func hasWildcard(pattern string, flags int) bool {
+	for pos := 0; pos < len(pattern); pos++ {
+		switch pattern[pos] {
+		case '*', '?', '[':
+			return true
+		case '\\':
+			if flags&GLOB_NOESCAPE == 0 {
+				pos++
+			}
+		}
+	}
+	return false
+}
+
+func unescapeWildcards(pattern string) string {
+	var result strings.Builder
+	for pos := 0; pos < len(pattern); pos++ {
+		if pattern[pos] == '\\' && pos+1 < len(pattern) {
+			pos++
+		}
+		result.WriteByte(pattern[pos])
+	}
+	return result.String()
+}
+
+// Expand the braces of the pattern (e.g. "{a,b}.txt" => "a.txt", "b.txt")
+func expandBraces(pattern string) []string {
+	start := strings.IndexByte(pattern, '{')
+	if start < 0 {
+		return []string{pattern}
+	}
+
+	depth := 0
+	alternatives := []string{}
+	alternativeStart := start + 1
+	for pos := start; pos < len(pattern); pos++ {
+		switch pattern[pos] {
+		case '{':
+			depth++
+		case ',':
+			if depth == 1 {
+				alternatives = append(alternatives, pattern[alternativeStart:pos])
+				alternativeStart = pos + 1
+			}
+		case '}':
+			depth--
+			if depth == 0 {
+				alternatives = append(alternatives, pattern[alternativeStart:pos])
+				result := []string{}
+				for _, alternative := range alternatives {
+					result = append(result, expandBraces(pattern[:start]+alternative+pattern[pos+1:])...)
+				}
+				return result
+			}
+		}
+	}
+	// Braces without closing brace are not expanded
+	return []string{pattern}
+}
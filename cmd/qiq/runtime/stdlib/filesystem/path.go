@@ -0,0 +1,265 @@
+package filesystem
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stream"
+	"QIQ/cmd/qiq/runtime/values"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Spec: https://www.php.net/manual/en/function.pathinfo.php
+const (
+	PATHINFO_DIRNAME   int64 = 1
+	PATHINFO_BASENAME  int64 = 2
+	PATHINFO_EXTENSION int64 = 4
+	PATHINFO_FILENAME  int64 = 8
+	PATHINFO_ALL       int64 = 15
+)
+
+func isPathSeparator(char byte) bool {
+	return char == '/' || (os.PathSeparator == '\\' && char == '\\')
+}
+
+// Get the temporary directory (ini directive "sys_temp_dir" or the directory of the system) without trailing separator
+func TempDir(context runtime.Context) string {
+	directory := context.Interpreter.GetIni().GetStr("sys_temp_dir")
+	if directory == "" {
+		directory = os.TempDir()
+	}
+	if trimmed := strings.TrimRight(directory, `/\`); trimmed != "" {
+		return trimmed
+	}
+	return directory
+}
+
+// -------------------------------------- basename -------------------------------------- MARK: basename
+
+func nativeFn_basename(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.basename.php
+	args, err := funcParamValidator.NewValidator("basename").
+		AddParam("$path", []string{"string"}, nil).
+		AddParam("$suffix", []string{"string"}, values.NewStr("")).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(Basename(args[0].(*values.Str).Value, args[1].(*values.Str).Value)), nil
+}
+
+// Get the trailing name component of the path. The suffix is removed if the name is not only the suffix.
+func Basename(path string, suffix string) string {
+	end := len(path)
+	for end > 0 && isPathSeparator(path[end-1]) {
+		end--
+	}
+	start := end
+	for start > 0 && !isPathSeparator(path[start-1]) {
+		start--
+	}
+	name := path[start:end]
+	if suffix != "" && len(suffix) < len(name) && strings.HasSuffix(name, suffix) {
+		name = name[:len(name)-len(suffix)]
+	}
+	return name
+}
+
+// -------------------------------------- dirname -------------------------------------- MARK: dirname
+
+func nativeFn_dirname(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.dirname.php
+	args, err := funcParamValidator.NewValidator("dirname").
+		AddParam("$path", []string{"string"}, nil).
+		AddParam("$levels", []string{"int"}, values.NewInt(1)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	levels := args[1].(*values.Int).Value
+	if levels < 1 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: dirname(): Argument #2 ($levels) must be greater than or equal to 1")
+	}
+
+	// The parent directories are determined until the root is reached
+	path := args[0].(*values.Str).Value
+	for ; levels > 0; levels-- {
+		parent := Dirname(path)
+		reachedRoot := len(parent) >= len(path)
+		path = parent
+		if reachedRoot {
+			break
+		}
+	}
+	return values.NewStr(path), nil
+}
+
+// Get the path of the parent directory (e.g. "/etc" for "/etc/passwd" or "." for "file.txt")
+func Dirname(path string) string {
+	if path == "" {
+		return ""
+	}
+	end := len(path) - 1
+	// Strip trailing separators
+	for end >= 0 && isPathSeparator(path[end]) {
+		end--
+	}
+	if end < 0 {
+		return path[:1]
+	}
+	// Strip the name
+	for end >= 0 && !isPathSeparator(path[end]) {
+		end--
+	}
+	if end < 0 {
+		return "."
+	}
+	// Strip the separators before the name
+	for end >= 0 && isPathSeparator(path[end]) {
+		end--
+	}
+	if end < 0 {
+		return path[:1]
+	}
+	return path[:end+1]
+}
+
+// -------------------------------------- fnmatch -------------------------------------- MARK: fnmatch
+
+func nativeFn_fnmatch(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fnmatch.php
+	args, err := funcParamValidator.NewValidator("fnmatch").
+		AddParam("$pattern", []string{"string"}, nil).
+		AddParam("$filename", []string{"string"}, nil).
+		AddParam("$flags", []string{"int"}, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	filename := args[1].(*values.Str).Value
+	if len(filename) >= maxPathLength {
+		context.Interpreter.PrintError(phpError.NewWarning(
+			"fnmatch(): Filename exceeds the maximum allowed length of %d characters%s", maxPathLength, context.InPosition(),
+		))
+		return values.NewBool(false), nil
+	}
+	return values.NewBool(Fnmatch(args[0].(*values.Str).Value, filename, int(args[2].(*values.Int).Value))), nil
+}
+
+// -------------------------------------- glob -------------------------------------- MARK: glob
+
+func nativeFn_glob(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.glob.php
+	args, err := funcParamValidator.NewValidator("glob").
+		AddParam("$pattern", []string{"string"}, nil).
+		AddParam("$flags", []string{"int"}, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	flags := int(args[1].(*values.Int).Value)
+	if flags&^GLOB_AVAILABLE_FLAGS != 0 {
+		context.Interpreter.PrintError(phpError.NewWarning("glob(): At least one of the passed flags is invalid or not supported on this platform%s", context.InPosition()))
+		return values.NewBool(false), nil
+	}
+
+	// Paths outside of open_basedir are skipped. If all paths were skipped, false is returned.
+	array := values.NewArray()
+	restricted := false
+	for _, path := range Glob(args[0].(*values.Str).Value, flags, context) {
+		if !stream.IsAllowedPath(path, context) {
+			restricted = true
+			continue
+		}
+		array.SetElement(nil, values.NewStr(path))
+	}
+	if restricted && len(array.Keys) == 0 {
+		return values.NewBool(false), nil
+	}
+	return array, nil
+}
+
+// -------------------------------------- pathinfo -------------------------------------- MARK: pathinfo
+
+func nativeFn_pathinfo(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.pathinfo.php
+	args, err := funcParamValidator.NewValidator("pathinfo").
+		AddParam("$path", []string{"string"}, nil).
+		AddParam("$flags", []string{"int"}, values.NewInt(PATHINFO_ALL)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	path := args[0].(*values.Str).Value
+	flags := args[1].(*values.Int).Value
+
+	result := values.NewArray()
+	if flags&PATHINFO_DIRNAME != 0 {
+		if dirname := Dirname(path); dirname != "" {
+			result.SetElement(values.NewStr("dirname"), values.NewStr(dirname))
+		}
+	}
+	basename := Basename(path, "")
+	if flags&PATHINFO_BASENAME != 0 {
+		result.SetElement(values.NewStr("basename"), values.NewStr(basename))
+	}
+	extensionPos := strings.LastIndexByte(basename, '.')
+	if flags&PATHINFO_EXTENSION != 0 && extensionPos >= 0 {
+		result.SetElement(values.NewStr("extension"), values.NewStr(basename[extensionPos+1:]))
+	}
+	if flags&PATHINFO_FILENAME != 0 {
+		if extensionPos < 0 {
+			extensionPos = len(basename)
+		}
+		result.SetElement(values.NewStr("filename"), values.NewStr(basename[:extensionPos]))
+	}
+
+	if flags == PATHINFO_ALL {
+		return result, nil
+	}
+	// For other flags, the first element is returned
+	if len(result.Keys) == 0 {
+		return values.NewStr(""), nil
+	}
+	slot, _ := result.GetElement(result.Keys[0])
+	return slot.Value, nil
+}
+
+// -------------------------------------- realpath -------------------------------------- MARK: realpath
+
+func nativeFn_realpath(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.realpath.php
+	args, err := funcParamValidator.NewValidator("realpath").AddParam("$path", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	path, ok := Realpath(args[0].(*values.Str).Value, context)
+	if !ok || !stream.CheckOpenBasedir("realpath", path, context) {
+		return values.NewBool(false), nil
+	}
+	return values.NewStr(path), nil
+}
+
+// Get the absolute path with resolved symbolic links. Returns false if the path does not exist.
+func Realpath(path string, context runtime.Context) (string, bool) {
+	if path == "" {
+		path = "."
+	}
+	path, err := filepath.Abs(stream.ResolvePath(path, context))
+	if err != nil {
+		return "", false
+	}
+	path, err = filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
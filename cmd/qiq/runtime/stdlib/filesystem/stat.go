@@ -0,0 +1,400 @@
+package filesystem
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stream"
+	"QIQ/cmd/qiq/runtime/values"
+	"os"
+)
+
+// Access modes of the access system call
+const (
+	X_OK uint32 = 1
+	W_OK uint32 = 2
+	R_OK uint32 = 4
+)
+
+// File type bits of the mode as returned by stat
+const (
+	S_IFIFO  int64 = 0010000
+	S_IFCHR  int64 = 0020000
+	S_IFDIR  int64 = 0040000
+	S_IFBLK  int64 = 0060000
+	S_IFREG  int64 = 0100000
+	S_IFLNK  int64 = 0120000
+	S_IFSOCK int64 = 0140000
+	S_IFMT   int64 = 0170000
+)
+
+// Information of a file that is not available in os.FileInfo
+type sysStat struct {
+	dev     int64
+	ino     int64
+	mode    int64
+	nlink   int64
+	uid     int64
+	gid     int64
+	rdev    int64
+	atime   int64
+	ctime   int64
+	blksize int64
+	blocks  int64
+}
+
+// Create the system information from the portable file information. Unknown values are -1 (like PHP on Windows).
+func newSysStat(info os.FileInfo) sysStat {
+	mode := int64(info.Mode().Perm())
+	switch fileMode := info.Mode(); {
+	case fileMode.IsDir():
+		mode |= S_IFDIR
+	case fileMode&os.ModeSymlink != 0:
+		mode |= S_IFLNK
+	case fileMode&os.ModeNamedPipe != 0:
+		mode |= S_IFIFO
+	case fileMode&os.ModeSocket != 0:
+		mode |= S_IFSOCK
+	case fileMode&os.ModeCharDevice != 0:
+		mode |= S_IFCHR
+	case fileMode&os.ModeDevice != 0:
+		mode |= S_IFBLK
+	default:
+		mode |= S_IFREG
+	}
+	return sysStat{
+		mode: mode, nlink: 1, atime: info.ModTime().Unix(), ctime: info.ModTime().Unix(), blksize: -1, blocks: -1,
+	}
+}
+
+// Get the information of the file. Like PHP, the result of the last stat and lstat is cached until clearstatcache is called.
+// Files outside of open_basedir cannot be accessed (no warning is printed).
+func StatFile(filename string, link bool, context runtime.Context) (os.FileInfo, error) {
+	if !stream.IsAllowedPath(filename, context) {
+		return nil, os.ErrPermission
+	}
+	path := stream.ResolvePath(filename, context)
+	executionContext := context.Interpreter.GetExectionContext()
+	if info, found := executionContext.GetStatCache(path, link); found {
+		return info, nil
+	}
+
+	var info os.FileInfo
+	var err error
+	if link {
+		info, err = os.Lstat(path)
+	} else {
+		info, err = os.Stat(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	executionContext.SetStatCache(path, link, info)
+	return info, nil
+}
+
+type statEntry struct {
+	name  string
+	value int64
+}
+
+// Get the values of the file information in the order of the stat array
+func statEntries(info os.FileInfo) []statEntry {
+	sys := getSysStat(info)
+	return []statEntry{
+		{"dev", sys.dev}, {"ino", sys.ino}, {"mode", sys.mode}, {"nlink", sys.nlink}, {"uid", sys.uid}, {"gid", sys.gid},
+		{"rdev", sys.rdev}, {"size", info.Size()}, {"atime", sys.atime}, {"mtime", info.ModTime().Unix()}, {"ctime", sys.ctime},
+		{"blksize", sys.blksize}, {"blocks", sys.blocks},
+	}
+}
+
+// Convert the file information to the array returned by stat with numeric and named keys
+func StatToArray(info os.FileInfo) *values.Array {
+	entries := statEntries(info)
+	array := values.NewArray()
+	for index, entry := range entries {
+		array.SetElement(values.NewInt(int64(index)), values.NewInt(entry.value))
+	}
+	for _, entry := range entries {
+		array.SetElement(values.NewStr(entry.name), values.NewInt(entry.value))
+	}
+	return array
+}
+
+// Get a value of the file information by its name in the stat array (e.g. "size" or "mtime")
+func StatValue(info os.FileInfo, name string) int64 {
+	for _, entry := range statEntries(info) {
+		if entry.name == name {
+			return entry.value
+		}
+	}
+	return 0
+}
+
+// Get the file type as returned by filetype (e.g. "file" or "dir")
+func FileType(info os.FileInfo) string {
+	switch getSysStat(info).mode & S_IFMT {
+	case S_IFIFO:
+		return "fifo"
+	case S_IFCHR:
+		return "char"
+	case S_IFDIR:
+		return "dir"
+	case S_IFBLK:
+		return "block"
+	case S_IFLNK:
+		return "link"
+	case S_IFREG:
+		return "file"
+	case S_IFSOCK:
+		return "socket"
+	default:
+		return "unknown"
+	}
+}
+
+// Get a value of the file information. A warning is printed if the file does not exist.
+func lib_filestat(functionName string, args []values.RuntimeValue, link bool, getValue func(info os.FileInfo) values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).AddParam("$filename", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	filename := args[0].(*values.Str).Value
+	if filename == "" {
+		return values.NewBool(false), nil
+	}
+
+	if !stream.CheckOpenBasedir(functionName, filename, context) {
+		return values.NewBool(false), nil
+	}
+	info, goErr := StatFile(filename, link, context)
+	if goErr != nil {
+		if link {
+			context.Interpreter.PrintError(phpError.NewWarning("%s(): Lstat failed for %s%s", functionName, filename, context.InPosition()))
+		} else {
+			context.Interpreter.PrintError(phpError.NewWarning("%s(): stat failed for %s%s", functionName, filename, context.InPosition()))
+		}
+		return values.NewBool(false), nil
+	}
+	return getValue(info), nil
+}
+
+// Check the file information. No warning is printed if the file does not exist.
+func lib_is(functionName string, args []values.RuntimeValue, link bool, check func(info os.FileInfo) bool, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).AddParam("$filename", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	info, goErr := StatFile(args[0].(*values.Str).Value, link, context)
+	if goErr != nil {
+		return values.NewBool(false), nil
+	}
+	return values.NewBool(check(info)), nil
+}
+
+// Check the access to the file with R_OK, W_OK or X_OK
+func lib_access(functionName string, args []values.RuntimeValue, mode uint32, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).AddParam("$filename", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	filename := args[0].(*values.Str).Value
+	if filename == "" {
+		return values.NewBool(false), nil
+	}
+	return values.NewBool(stream.IsAllowedPath(filename, context) && HasAccess(stream.ResolvePath(filename, context), mode)), nil
+}
+
+// -------------------------------------- clearstatcache -------------------------------------- MARK: clearstatcache
+
+func nativeFn_clearstatcache(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.clearstatcache.php
+	_, err := funcParamValidator.NewValidator("clearstatcache").
+		AddParam("$clear_realpath_cache", []string{"bool"}, values.NewBool(false)).
+		AddParam("$filename", []string{"string"}, values.NewStr("")).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Paths are not cached by realpath. Therefore, only the stat cache is cleared.
+	context.Interpreter.GetExectionContext().ClearStatCache()
+	return values.NewVoid(), nil
+}
+
+// -------------------------------------- file_exists -------------------------------------- MARK: file_exists
+
+func nativeFn_file_exists(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.file-exists.php
+	return lib_is("file_exists", args, false, func(info os.FileInfo) bool { return true }, context)
+}
+
+// -------------------------------------- fileatime -------------------------------------- MARK: fileatime
+
+func nativeFn_fileatime(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fileatime.php
+	return lib_filestat("fileatime", args, false, func(info os.FileInfo) values.RuntimeValue {
+		return values.NewInt(getSysStat(info).atime)
+	}, context)
+}
+
+// -------------------------------------- filectime -------------------------------------- MARK: filectime
+
+func nativeFn_filectime(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.filectime.php
+	return lib_filestat("filectime", args, false, func(info os.FileInfo) values.RuntimeValue {
+		return values.NewInt(getSysStat(info).ctime)
+	}, context)
+}
+
+// -------------------------------------- filegroup -------------------------------------- MARK: filegroup
+
+func nativeFn_filegroup(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.filegroup.php
+	return lib_filestat("filegroup", args, false, func(info os.FileInfo) values.RuntimeValue {
+		return values.NewInt(getSysStat(info).gid)
+	}, context)
+}
+
+// -------------------------------------- fileinode -------------------------------------- MARK: fileinode
+
+func nativeFn_fileinode(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fileinode.php
+	return lib_filestat("fileinode", args, false, func(info os.FileInfo) values.RuntimeValue {
+		return values.NewInt(getSysStat(info).ino)
+	}, context)
+}
+
+// -------------------------------------- filemtime -------------------------------------- MARK: filemtime
+
+func nativeFn_filemtime(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.filemtime.php
+	return lib_filestat("filemtime", args, false, func(info os.FileInfo) values.RuntimeValue {
+		return values.NewInt(info.ModTime().Unix())
+	}, context)
+}
+
+// -------------------------------------- fileowner -------------------------------------- MARK: fileowner
+
+func nativeFn_fileowner(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fileowner.php
+	return lib_filestat("fileowner", args, false, func(info os.FileInfo) values.RuntimeValue {
+		return values.NewInt(getSysStat(info).uid)
+	}, context)
+}
+
+// -------------------------------------- fileperms -------------------------------------- MARK: fileperms
+
+func nativeFn_fileperms(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fileperms.php
+	return lib_filestat("fileperms", args, false, func(info os.FileInfo) values.RuntimeValue {
+		return values.NewInt(getSysStat(info).mode)
+	}, context)
+}
+
+// -------------------------------------- filesize -------------------------------------- MARK: filesize
+
+func nativeFn_filesize(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.filesize.php
+	return lib_filestat("filesize", args, false, func(info os.FileInfo) values.RuntimeValue {
+		return values.NewInt(info.Size())
+	}, context)
+}
+
+// -------------------------------------- filetype -------------------------------------- MARK: filetype
+
+func nativeFn_filetype(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.filetype.php
+	return lib_filestat("filetype", args, true, func(info os.FileInfo) values.RuntimeValue {
+		return values.NewStr(FileType(info))
+	}, context)
+}
+
+// -------------------------------------- fstat -------------------------------------- MARK: fstat
+
+func nativeFn_fstat(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fstat.php
+	args, err := funcParamValidator.NewValidator("fstat").AddParam("$stream", []string{"resource"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := GetStream("fstat", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	info, ok := fileStream.Stat()
+	if !ok {
+		return values.NewBool(false), nil
+	}
+	return StatToArray(info), nil
+}
+
+// -------------------------------------- is_dir -------------------------------------- MARK: is_dir
+
+func nativeFn_is_dir(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.is-dir.php
+	return lib_is("is_dir", args, false, func(info os.FileInfo) bool { return info.IsDir() }, context)
+}
+
+// -------------------------------------- is_executable -------------------------------------- MARK: is_executable
+
+func nativeFn_is_executable(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.is-executable.php
+	return lib_access("is_executable", args, X_OK, context)
+}
+
+// -------------------------------------- is_file -------------------------------------- MARK: is_file
+
+func nativeFn_is_file(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.is-file.php
+	return lib_is("is_file", args, false, func(info os.FileInfo) bool { return info.Mode().IsRegular() }, context)
+}
+
+// -------------------------------------- is_link -------------------------------------- MARK: is_link
+
+func nativeFn_is_link(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.is-link.php
+	return lib_is("is_link", args, true, func(info os.FileInfo) bool { return info.Mode()&os.ModeSymlink != 0 }, context)
+}
+
+// -------------------------------------- is_readable -------------------------------------- MARK: is_readable
+
+func nativeFn_is_readable(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.is-readable.php
+	return lib_access("is_readable", args, R_OK, context)
+}
+
+// -------------------------------------- is_writable -------------------------------------- MARK: is_writable
+
+func nativeFn_is_writable(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.is-writable.php
+	return lib_access("is_writable", args, W_OK, context)
+}
+
+func nativeFn_is_writeable(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.is-writeable.php
+	return lib_access("is_writeable", args, W_OK, context)
+}
+
+// -------------------------------------- lstat -------------------------------------- MARK: lstat
+
+func nativeFn_lstat(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.lstat.php
+	return lib_filestat("lstat", args, true, func(info os.FileInfo) values.RuntimeValue {
+		return StatToArray(info)
+	}, context)
+}
+
+// -------------------------------------- stat -------------------------------------- MARK: stat
+
+func nativeFn_stat(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.stat.php
+	return lib_filestat("stat", args, false, func(info os.FileInfo) values.RuntimeValue {
+		return StatToArray(info)
+	}, context)
+}
@@ -0,0 +1,34 @@
+//go:build linux
+
+package filesystem
+
+import (
+	"os"
+	"syscall"
+)
+
+// Get the system specific information of the file as returned by stat
+func getSysStat(info os.FileInfo) sysStat {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return newSysStat(info)
+	}
+	return sysStat{
+		dev:     int64(stat.Dev),
+		ino:     int64(stat.Ino),
+		mode:    int64(stat.Mode),
+		nlink:   int64(stat.Nlink),
+		uid:     int64(stat.Uid),
+		gid:     int64(stat.Gid),
+		rdev:    int64(stat.Rdev),
+		atime:   int64(stat.Atim.Sec),
+		ctime:   int64(stat.Ctim.Sec),
+		blksize: int64(stat.Blksize),
+		blocks:  int64(stat.Blocks),
+	}
+}
+
+// Check the access of the real user to the file with R_OK, W_OK or X_OK
+func HasAccess(path string, mode uint32) bool {
+	return syscall.Access(path, mode) == nil
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+
+package filesystem
+
+import (
+	"os"
+)
+
+// Get the system specific information of the file as returned by stat
+func getSysStat(info os.FileInfo) sysStat {
+	return newSysStat(info)
+}
+
+// Check the access to the file with R_OK, W_OK or X_OK based on the permissions of the owner
+func HasAccess(path string, mode uint32) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return uint32(info.Mode().Perm()>>6)&mode == mode
+}
@@ -0,0 +1,527 @@
+package filesystem
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/stream"
+	"QIQ/cmd/qiq/runtime/values"
+	"math"
+	"syscall"
+)
+
+// Get the stream of a resource argument. Closed resources, directory streams and resources of other types are rejected.
+func GetStream(functionName string, resource values.RuntimeValue) (*stream.Stream, phpError.Error) {
+	res := resource.(*values.Resource)
+	fileStream, isStream := res.Internal.(*stream.Stream)
+	if res.IsClosed || res.Type != "stream" || !isStream {
+		return nil, phpError.NewError("Uncaught TypeError: %s(): supplied resource is not a valid stream resource", functionName)
+	}
+	return fileStream, nil
+}
+
+// Print the notice of a failed read or write (e.g. "fread(): Read of 8192 bytes failed with errno=9 Bad file descriptor")
+func PrintIoNotice(functionName string, operation string, length int, err error, context runtime.Context) {
+	errno := syscall.EIO
+	if value, ok := err.(syscall.Errno); ok {
+		errno = value
+	}
+	context.Interpreter.PrintError(phpError.NewNotice(
+		"%s(): %s of %d bytes failed with errno=%d %s%s", functionName, operation, length, int(errno), stream.ErrorMessage(errno), context.InPosition(),
+	))
+}
+
+// Get the character of a CSV argument like $separator
+func CsvCharArg(functionName string, argNum int, name string, value string, allowEmpty bool) (int, phpError.Error) {
+	if len(value) == 1 {
+		return int(value[0]), nil
+	}
+	if allowEmpty {
+		if value == "" {
+			return NoEscape, nil
+		}
+		return 0, phpError.NewError("Uncaught ValueError: %s(): Argument #%d (%s) must be empty or a single character", functionName, argNum, name)
+	}
+	return 0, phpError.NewError("Uncaught ValueError: %s(): Argument #%d (%s) must be a single character", functionName, argNum, name)
+}
+
+// -------------------------------------- fclose -------------------------------------- MARK: fclose
+
+func nativeFn_fclose(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fclose.php
+	args, err := funcParamValidator.NewValidator("fclose").AddParam("$stream", []string{"resource"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := GetStream("fclose", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	args[0].(*values.Resource).IsClosed = true
+	return values.NewBool(fileStream.Close() == nil), nil
+}
+
+// -------------------------------------- feof -------------------------------------- MARK: feof
+
+func nativeFn_feof(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.feof.php
+	args, err := funcParamValidator.NewValidator("feof").AddParam("$stream", []string{"resource"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := GetStream("feof", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(fileStream.Eof()), nil
+}
+
+// -------------------------------------- fflush -------------------------------------- MARK: fflush
+
+func nativeFn_fflush(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fflush.php
+	args, err := funcParamValidator.NewValidator("fflush").AddParam("$stream", []string{"resource"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := GetStream("fflush", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(fileStream.Flush()), nil
+}
+
+// -------------------------------------- fgetc -------------------------------------- MARK: fgetc
+
+func nativeFn_fgetc(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fgetc.php
+	args, err := funcParamValidator.NewValidator("fgetc").AddParam("$stream", []string{"resource"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := GetStream("fgetc", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	char, goErr := fileStream.Read(1)
+	if goErr != nil {
+		PrintIoNotice("fgetc", "Read", 1, goErr, context)
+		return values.NewBool(false), nil
+	}
+	if char == "" {
+		return values.NewBool(false), nil
+	}
+	return values.NewStr(char), nil
+}
+
+// -------------------------------------- fgetcsv -------------------------------------- MARK: fgetcsv
+
+func nativeFn_fgetcsv(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fgetcsv.php
+	args, err := funcParamValidator.NewValidator("fgetcsv").
+		AddParam("$stream", []string{"resource"}, nil).
+		AddParam("$length", []string{"int", "null"}, values.NewNull()).
+		AddParam("$separator", []string{"string"}, values.NewStr(",")).
+		AddParam("$enclosure", []string{"string"}, values.NewStr(`"`)).
+		AddParam("$escape", []string{"string"}, values.NewStr(`\`)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := GetStream("fgetcsv", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	maxLength := -1
+	if args[1].GetType() == values.IntValue {
+		length := args[1].(*values.Int).Value
+		if length < 0 {
+			return values.NewVoid(), phpError.NewError("Uncaught ValueError: fgetcsv(): Argument #2 ($length) must be between 0 and %d", math.MaxInt64)
+		}
+		if length > 0 {
+			maxLength = int(length)
+		}
+	}
+	separator, err := CsvCharArg("fgetcsv", 3, "$separator", args[2].(*values.Str).Value, false)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	enclosure, err := CsvCharArg("fgetcsv", 4, "$enclosure", args[3].(*values.Str).Value, false)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	escape, err := CsvCharArg("fgetcsv", 5, "$escape", args[4].(*values.Str).Value, true)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	line, ok, goErr := fileStream.ReadLine(maxLength)
+	if goErr != nil {
+		PrintIoNotice("fgetcsv", "Read", chunkLength(maxLength), goErr, context)
+		return values.NewBool(false), nil
+	}
+	if !ok {
+		return values.NewBool(false), nil
+	}
+
+	nextLine := func() (string, bool) {
+		line, ok, _ := fileStream.ReadLine(maxLength)
+		return line, ok
+	}
+	return ParseCsv(line, byte(separator), byte(enclosure), escape, nextLine), nil
+}
+
+// Get the number of bytes of a read for the notice
+func chunkLength(maxLength int) int {
+	if maxLength < 0 {
+		return 8192
+	}
+	return maxLength
+}
+
+// -------------------------------------- fgets -------------------------------------- MARK: fgets
+
+func nativeFn_fgets(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fgets.php
+	args, err := funcParamValidator.NewValidator("fgets").
+		AddParam("$stream", []string{"resource"}, nil).
+		AddParam("$length", []string{"int", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := GetStream("fgets", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// The line is read until length - 1 bytes are read
+	maxLength := -1
+	if args[1].GetType() == values.IntValue {
+		length := args[1].(*values.Int).Value
+		if length <= 0 {
+			return values.NewVoid(), phpError.NewError("Uncaught ValueError: fgets(): Argument #2 ($length) must be greater than 0")
+		}
+		maxLength = int(length - 1)
+	}
+
+	line, ok, goErr := fileStream.ReadLine(maxLength)
+	if goErr != nil {
+		PrintIoNotice("fgets", "Read", chunkLength(maxLength), goErr, context)
+		return values.NewBool(false), nil
+	}
+	if !ok {
+		return values.NewBool(false), nil
+	}
+	return values.NewStr(line), nil
+}
+
+// -------------------------------------- flock -------------------------------------- MARK: flock
+
+func nativeFn_flock(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.flock.php
+	args, err := funcParamValidator.NewValidator("flock").
+		AddParam("$stream", []string{"resource"}, nil).
+		AddParam("$operation", []string{"int"}, nil).
+		AddParam("$would_block", []string{"mixed"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := GetStream("flock", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	operation := int(args[1].(*values.Int).Value)
+	switch operation &^ stream.LOCK_NB {
+	case stream.LOCK_SH, stream.LOCK_EX, stream.LOCK_UN:
+	default:
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: flock(): Argument #2 ($operation) must be one of LOCK_SH, LOCK_EX, or LOCK_UN")
+	}
+
+	ok, wouldBlock := fileStream.Lock(operation)
+	if wouldBlock {
+		context.SetRefArg(2, values.NewInt(1))
+	} else {
+		context.SetRefArg(2, values.NewInt(0))
+	}
+	return values.NewBool(ok), nil
+}
+
+// -------------------------------------- fopen -------------------------------------- MARK: fopen
+
+func nativeFn_fopen(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fopen.php
+	args, err := funcParamValidator.NewValidator("fopen").
+		AddParam("$filename", []string{"string"}, nil).
+		AddParam("$mode", []string{"string"}, nil).
+		AddParam("$use_include_path", []string{"bool"}, values.NewBool(false)).
+		AddParam("$context", []string{"resource", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// TODO fopen: Add support for use_include_path and context
+
+	filename := args[0].(*values.Str).Value
+	if filename == "" {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: Path cannot be empty")
+	}
+
+	fileStream, goErr := stream.Open(filename, args[1].(*values.Str).Value, context)
+	if goErr != nil {
+		context.Interpreter.PrintError(phpError.NewWarning("fopen(%s): Failed to open stream: %s%s", filename, goErr, context.InPosition()))
+		return values.NewBool(false), nil
+	}
+	return context.Interpreter.GetExectionContext().NewResource("stream", fileStream), nil
+}
+
+// -------------------------------------- fpassthru -------------------------------------- MARK: fpassthru
+
+func nativeFn_fpassthru(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fpassthru.php
+	args, err := funcParamValidator.NewValidator("fpassthru").AddParam("$stream", []string{"resource"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := GetStream("fpassthru", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	data, goErr := fileStream.ReadAll()
+	if goErr != nil {
+		PrintIoNotice("fpassthru", "Read", 8192, goErr, context)
+		return values.NewInt(0), nil
+	}
+	context.Interpreter.Print(data)
+	return values.NewInt(int64(len(data))), nil
+}
+
+// -------------------------------------- fputcsv -------------------------------------- MARK: fputcsv
+
+func nativeFn_fputcsv(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fputcsv.php
+	args, err := funcParamValidator.NewValidator("fputcsv").
+		AddParam("$stream", []string{"resource"}, nil).
+		AddParam("$fields", []string{"array"}, nil).
+		AddParam("$separator", []string{"string"}, values.NewStr(",")).
+		AddParam("$enclosure", []string{"string"}, values.NewStr(`"`)).
+		AddParam("$escape", []string{"string"}, values.NewStr(`\`)).
+		AddParam("$eol", []string{"string"}, values.NewStr("\n")).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := GetStream("fputcsv", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	separator, err := CsvCharArg("fputcsv", 3, "$separator", args[2].(*values.Str).Value, false)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	enclosure, err := CsvCharArg("fputcsv", 4, "$enclosure", args[3].(*values.Str).Value, false)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	escape, err := CsvCharArg("fputcsv", 5, "$escape", args[4].(*values.Str).Value, true)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	array := args[1].(*values.Array)
+	fields := make([]string, len(array.Keys))
+	for index, key := range array.Keys {
+		slot, _ := array.GetElement(key)
+		fields[index], err = variableHandling.StrVal(slot.Value)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+	}
+
+	line := FormatCsv(fields, byte(separator), byte(enclosure), escape, args[5].(*values.Str).Value)
+	written, goErr := fileStream.Write(line)
+	if goErr != nil {
+		PrintIoNotice("fputcsv", "Write", len(line), goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewInt(int64(written)), nil
+}
+
+// -------------------------------------- fread -------------------------------------- MARK: fread
+
+func nativeFn_fread(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fread.php
+	args, err := funcParamValidator.NewValidator("fread").
+		AddParam("$stream", []string{"resource"}, nil).
+		AddParam("$length", []string{"int"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := GetStream("fread", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	length := args[1].(*values.Int).Value
+	if length <= 0 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: fread(): Argument #2 ($length) must be greater than 0")
+	}
+
+	data, goErr := fileStream.Read(int(length))
+	if goErr != nil {
+		PrintIoNotice("fread", "Read", int(length), goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewStr(data), nil
+}
+
+// -------------------------------------- fseek -------------------------------------- MARK: fseek
+
+func nativeFn_fseek(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fseek.php
+	args, err := funcParamValidator.NewValidator("fseek").
+		AddParam("$stream", []string{"resource"}, nil).
+		AddParam("$offset", []string{"int"}, nil).
+		AddParam("$whence", []string{"int"}, values.NewInt(int64(stream.SEEK_SET))).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := GetStream("fseek", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if !fileStream.SetPosition(args[1].(*values.Int).Value, int(args[2].(*values.Int).Value)) {
+		return values.NewInt(-1), nil
+	}
+	return values.NewInt(0), nil
+}
+
+// -------------------------------------- ftell -------------------------------------- MARK: ftell
+
+func nativeFn_ftell(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.ftell.php
+	args, err := funcParamValidator.NewValidator("ftell").AddParam("$stream", []string{"resource"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := GetStream("ftell", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(fileStream.GetPosition()), nil
+}
+
+// -------------------------------------- ftruncate -------------------------------------- MARK: ftruncate
+
+func nativeFn_ftruncate(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.ftruncate.php
+	args, err := funcParamValidator.NewValidator("ftruncate").
+		AddParam("$stream", []string{"resource"}, nil).
+		AddParam("$size", []string{"int"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := GetStream("ftruncate", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	size := args[1].(*values.Int).Value
+	if size < 0 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: ftruncate(): Argument #2 ($size) must be greater than or equal to 0")
+	}
+	if !fileStream.IsTruncatable() {
+		context.Interpreter.PrintError(phpError.NewWarning("ftruncate(): Can't truncate this stream!%s", context.InPosition()))
+		return values.NewBool(false), nil
+	}
+	return values.NewBool(fileStream.Truncate(size)), nil
+}
+
+// -------------------------------------- fwrite -------------------------------------- MARK: fwrite
+
+func nativeFn_fwrite(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fwrite.php
+	return lib_fwrite("fwrite", args, context)
+}
+
+func nativeFn_fputs(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fputs.php
+	return lib_fwrite("fputs", args, context)
+}
+
+func lib_fwrite(functionName string, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$stream", []string{"resource"}, nil).
+		AddParam("$data", []string{"string"}, nil).
+		AddParam("$length", []string{"int", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := GetStream(functionName, args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	data := args[1].(*values.Str).Value
+	if args[2].GetType() == values.IntValue {
+		data = data[:max(0, min(int64(len(data)), args[2].(*values.Int).Value))]
+	}
+	if data == "" {
+		return values.NewInt(0), nil
+	}
+
+	written, goErr := fileStream.Write(data)
+	if goErr != nil {
+		PrintIoNotice(functionName, "Write", len(data), goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewInt(int64(written)), nil
+}
+
+// -------------------------------------- rewind -------------------------------------- MARK: rewind
+
+func nativeFn_rewind(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.rewind.php
+	args, err := funcParamValidator.NewValidator("rewind").AddParam("$stream", []string{"resource"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := GetStream("rewind", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(fileStream.SetPosition(0, stream.SEEK_SET)), nil
+}
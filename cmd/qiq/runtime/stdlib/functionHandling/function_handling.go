@@ -9,7 +9,212 @@ import (
 
 func Register(environment runtime.Environment) {
 	// Category: Function Handling Functions
+	environment.AddNativeFunction("call_user_func", nativeFn_call_user_func)
+	environment.AddNativeFunction("call_user_func_array", nativeFn_call_user_func_array)
+	environment.AddNativeFunction("forward_static_call", nativeFn_forward_static_call)
+	environment.AddNativeFunction("forward_static_call_array", nativeFn_forward_static_call_array)
+	environment.AddNativeFunction("func_get_arg", nativeFn_func_get_arg)
+	environment.AddNativeFunction("func_get_args", nativeFn_func_get_args)
+	environment.AddNativeFunction("func_num_args", nativeFn_func_num_args)
 	environment.AddNativeFunction("function_exists", nativeFn_function_exists)
+	environment.AddNativeFunctionByRef("is_callable", nativeFn_is_callable, runtime.NewByRefParams(2))
+	environment.AddNativeFunction("register_shutdown_function", nativeFn_register_shutdown_function)
+}
+
+// Convert the array of arguments into a list of arguments
+func arrayToArgs(array *values.Array) []values.RuntimeValue {
+	args := make([]values.RuntimeValue, len(array.Keys))
+	for index, key := range array.Keys {
+		slot, _ := array.GetElement(key)
+		args[index] = slot.Value
+	}
+	return args
+}
+
+// Convert the array of arguments into a list of positional arguments and an array of the arguments for named parameters (string keys)
+func arrayToNamedArgs(array *values.Array, context runtime.Context) ([]values.RuntimeValue, *values.Array, phpError.Error) {
+	args := []values.RuntimeValue{}
+	namedArgs := values.NewArray()
+	for _, key := range array.Keys {
+		slot, _ := array.GetElement(key)
+		if key.GetType() == values.StrValue {
+			namedArgs.SetElement(key, slot.Value)
+			continue
+		}
+		if len(namedArgs.Keys) > 0 {
+			return args, namedArgs, phpError.NewError(
+				"Uncaught Error: Cannot use positional argument after named argument during unpacking in %s", context.Stmt.GetPosString(),
+			)
+		}
+		args = append(args, slot.Value)
+	}
+	return args, namedArgs, nil
+}
+
+// -------------------------------------- call_user_func -------------------------------------- MARK: call_user_func
+
+func nativeFn_call_user_func(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.call-user-func.php
+
+	args, err := funcParamValidator.NewValidator("call_user_func").
+		AddParam("$callback", []string{"mixed"}, nil).
+		AddVariableLenParam("$args", []string{"mixed"}).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Calls the callback given by the first parameter and passes the remaining parameters as arguments.
+	if err := context.ValidateCallback("call_user_func", 1, "$callback", args[0], false); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return context.Interpreter.CallFunction(args[0], arrayToArgs(args[1].(*values.Array)), context.Env)
+}
+
+// -------------------------------------- call_user_func_array -------------------------------------- MARK: call_user_func_array
+
+func nativeFn_call_user_func_array(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.call-user-func-array.php
+
+	args, err := funcParamValidator.NewValidator("call_user_func_array").
+		AddParam("$callback", []string{"mixed"}, nil).
+		AddParam("$args", []string{"array"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Calls the callback given by the first parameter with the parameters in args.
+	if err := context.ValidateCallback("call_user_func_array", 1, "$callback", args[0], false); err != nil {
+		return values.NewVoid(), err
+	}
+
+	callArgs, namedArgs, err := arrayToNamedArgs(args[1].(*values.Array), context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return context.Interpreter.CallFunctionWithNamedArgs(args[0], callArgs, namedArgs, context.Env)
+}
+
+// -------------------------------------- forward_static_call -------------------------------------- MARK: forward_static_call
+
+func nativeFn_forward_static_call(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.forward-static-call.php
+
+	args, err := funcParamValidator.NewValidator("forward_static_call").
+		AddParam("$callback", []string{"mixed"}, nil).
+		AddVariableLenParam("$args", []string{"mixed"}).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return forwardStaticCall("forward_static_call", args[0], arrayToArgs(args[1].(*values.Array)), nil, context)
+}
+
+// -------------------------------------- forward_static_call_array -------------------------------------- MARK: forward_static_call_array
+
+func nativeFn_forward_static_call_array(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.forward-static-call-array.php
+
+	args, err := funcParamValidator.NewValidator("forward_static_call_array").
+		AddParam("$callback", []string{"mixed"}, nil).
+		AddParam("$args", []string{"array"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	callArgs, namedArgs, err := arrayToNamedArgs(args[1].(*values.Array), context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return forwardStaticCall("forward_static_call_array", args[0], callArgs, namedArgs, context)
+}
+
+func forwardStaticCall(functionName string, callback values.RuntimeValue, args []values.RuntimeValue, namedArgs *values.Array, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Calls a user defined function or method given by the callback parameter.
+	// This function must be called within a method context, it can't be used outside a class.
+
+	// The innermost frame is the frame of forward_static_call itself
+	frames := context.Interpreter.GetBacktrace()
+	if len(frames) < 2 || frames[1].Class == "" {
+		return values.NewVoid(), phpError.NewError("Uncaught Error: Cannot call %s() when no class scope is active in %s", functionName, context.Stmt.GetPosString())
+	}
+
+	if err := context.ValidateCallback(functionName, 1, "$callback", callback, false); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return context.Interpreter.ForwardStaticCall(callback, args, namedArgs, context.Env)
+}
+
+// -------------------------------------- func_get_arg -------------------------------------- MARK: func_get_arg
+
+func nativeFn_func_get_arg(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.func-get-arg.php
+
+	args, err := funcParamValidator.NewValidator("func_get_arg").AddParam("$position", []string{"int"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	functionArgs, found := context.Interpreter.GetFunctionArgs(context.Env)
+	if !found {
+		return values.NewVoid(), phpError.NewError("Uncaught Error: func_get_arg() cannot be called from the global scope in %s", context.Stmt.GetPosString())
+	}
+
+	position := args[0].(*values.Int).Value
+	if position < 0 {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: func_get_arg(): Argument #1 ($position) must be greater than or equal to 0 in %s", context.Stmt.GetPosString(),
+		)
+	}
+	if position >= int64(len(functionArgs)) {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: func_get_arg(): Argument #1 ($position) must be less than the number of the arguments passed to the currently executed function in %s",
+			context.Stmt.GetPosString(),
+		)
+	}
+
+	return functionArgs[position], nil
+}
+
+// -------------------------------------- func_get_args -------------------------------------- MARK: func_get_args
+
+func nativeFn_func_get_args(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.func-get-args.php
+
+	_, err := funcParamValidator.NewValidator("func_get_args").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	functionArgs, found := context.Interpreter.GetFunctionArgs(context.Env)
+	if !found {
+		return values.NewVoid(), phpError.NewError("Uncaught Error: func_get_args() cannot be called from the global scope in %s", context.Stmt.GetPosString())
+	}
+
+	return values.NewArrayFromSlice(functionArgs), nil
+}
+
+// -------------------------------------- func_num_args -------------------------------------- MARK: func_num_args
+
+func nativeFn_func_num_args(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.func-num-args.php
+
+	_, err := funcParamValidator.NewValidator("func_num_args").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	functionArgs, found := context.Interpreter.GetFunctionArgs(context.Env)
+	if !found {
+		return values.NewVoid(), phpError.NewError("Uncaught Error: func_num_args() must be called from a function context in %s", context.Stmt.GetPosString())
+	}
+
+	return values.NewInt(int64(len(functionArgs))), nil
 }
 
 // -------------------------------------- function_exists -------------------------------------- MARK: function_exists
@@ -24,3 +229,50 @@ func nativeFn_function_exists(args []values.RuntimeValue, context runtime.Contex
 
 	return values.NewBool(context.Env.FunctionExists(args[0].(*values.Str).Value)), nil
 }
+
+// -------------------------------------- is_callable -------------------------------------- MARK: is_callable
+
+func nativeFn_is_callable(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.is-callable.php
+
+	args, err := funcParamValidator.NewValidator("is_callable").
+		AddParam("$value", []string{"mixed"}, nil).
+		AddParam("$syntax_only", []string{"bool"}, values.NewBool(false)).
+		AddParam("$callable_name", []string{"mixed"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Verify that a value can be called as a function from the current scope.
+	// If the syntax_only argument is set to true the function only verifies that value might be a function or method.
+	// The callable name (e.g. "Class::method") is assigned to callable_name.
+	name, _, ok := context.Interpreter.IsCallable(args[0], args[1].(*values.Bool).Value, context.Env)
+	context.SetRefArg(2, values.NewStr(name))
+	return values.NewBool(ok), nil
+}
+
+// -------------------------------------- register_shutdown_function -------------------------------------- MARK: register_shutdown_function
+
+func nativeFn_register_shutdown_function(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.register-shutdown-function.php
+
+	args, err := funcParamValidator.NewValidator("register_shutdown_function").
+		AddParam("$callback", []string{"mixed"}, nil).
+		AddVariableLenParam("$args", []string{"mixed"}).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Registers a callback to be executed after script execution finishes or exit() is called.
+	// Multiple calls to register_shutdown_function() can be made, and each will be called in the same order as they were registered.
+	if err := context.ValidateCallback("register_shutdown_function", 1, "$callback", args[0], false); err != nil {
+		return values.NewVoid(), err
+	}
+
+	context.Interpreter.GetExectionContext().AddShutdownFunction(runtime.ShutdownFunction{
+		Callback: args[0], Args: arrayToArgs(args[1].(*values.Array)),
+	})
+	return values.NewVoid(), nil
+}
@@ -0,0 +1,425 @@
+package json
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/values"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+type decoder struct {
+	input    string
+	pos      int
+	flags    int64
+	maxDepth int64
+	depth    int64
+	// Class of the decoded objects. The decoder only validates the input if it is nil.
+	stdClass  *ast.ClassDeclarationStatement
+	errorCode int64
+}
+
+// Decode the JSON string like json_decode.
+// Returns the decoded value and the JSON_ERROR_* code of the error that stopped the decoding.
+func Decode(json string, depth int64, flags int64, context runtime.Context) (values.RuntimeValue, int64, phpError.Error) {
+	stdClass, found := context.Interpreter.GetClass("stdClass")
+	if !found {
+		return values.NewVoid(), JSON_ERROR_NONE, phpError.NewError(`Class "stdClass" not found`)
+	}
+	decoder := &decoder{input: json, flags: flags, maxDepth: depth, stdClass: stdClass}
+	result := decoder.decode()
+	if decoder.errorCode != JSON_ERROR_NONE {
+		return values.NewNull(), decoder.errorCode, nil
+	}
+	return result, JSON_ERROR_NONE, nil
+}
+
+// Check if the string is valid JSON like json_validate.
+// Returns the JSON_ERROR_* code of the first error.
+func Validate(json string, depth int64, flags int64) int64 {
+	decoder := &decoder{input: json, flags: flags, maxDepth: depth}
+	decoder.decode()
+	return decoder.errorCode
+}
+
+func (decoder *decoder) decode() values.RuntimeValue {
+	result, ok := decoder.parseValue()
+	if !ok {
+		return nil
+	}
+	decoder.skipWhitespace()
+	if decoder.pos < len(decoder.input) {
+		decoder.unexpectedChar()
+		return nil
+	}
+	return result
+}
+
+func (decoder *decoder) isValidating() bool { return decoder.stdClass == nil }
+
+func (decoder *decoder) setError(code int64) {
+	if decoder.errorCode == JSON_ERROR_NONE {
+		decoder.errorCode = code
+	}
+}
+
+// Set the error for the character at the current position that is not expected by the grammar
+func (decoder *decoder) unexpectedChar() {
+	if decoder.pos < len(decoder.input) && decoder.input[decoder.pos] == 0 {
+		decoder.setError(JSON_ERROR_CTRL_CHAR)
+	} else {
+		decoder.setError(JSON_ERROR_SYNTAX)
+	}
+}
+
+func (decoder *decoder) skipWhitespace() {
+	for decoder.pos < len(decoder.input) {
+		switch decoder.input[decoder.pos] {
+		case ' ', '\t', '\n', '\r':
+			decoder.pos++
+		default:
+			return
+		}
+	}
+}
+
+// Get the next character after whitespaces or 0 at the end of the input
+func (decoder *decoder) peek() byte {
+	decoder.skipWhitespace()
+	if decoder.pos >= len(decoder.input) {
+		return 0
+	}
+	return decoder.input[decoder.pos]
+}
+
+func (decoder *decoder) parseValue() (values.RuntimeValue, bool) {
+	switch char := decoder.peek(); {
+	case char == '{':
+		return decoder.parseObject()
+	case char == '[':
+		return decoder.parseArray()
+	case char == '"':
+		str, ok := decoder.parseString()
+		if !ok || decoder.isValidating() {
+			return nil, ok
+		}
+		return values.NewStr(str), true
+	case char == '-' || (char >= '0' && char <= '9'):
+		return decoder.parseNumber()
+	case strings.HasPrefix(decoder.input[decoder.pos:], "true"):
+		decoder.pos += 4
+		return values.NewBool(true), true
+	case strings.HasPrefix(decoder.input[decoder.pos:], "false"):
+		decoder.pos += 5
+		return values.NewBool(false), true
+	case strings.HasPrefix(decoder.input[decoder.pos:], "null"):
+		decoder.pos += 4
+		return values.NewNull(), true
+	default:
+		decoder.unexpectedChar()
+		return nil, false
+	}
+}
+
+// Increase the nesting depth. Returns false if the maximum depth is exceeded.
+func (decoder *decoder) enter() bool {
+	decoder.depth++
+	if decoder.depth > decoder.maxDepth {
+		decoder.setError(JSON_ERROR_DEPTH)
+		return false
+	}
+	return true
+}
+
+// Consume the closing character of an array or object.
+// The closing character of the other type is a state mismatch (e.g. "[1}").
+func (decoder *decoder) parseEnd(expected byte, other byte) bool {
+	switch decoder.peek() {
+	case expected:
+		decoder.pos++
+		decoder.depth--
+		return true
+	case other:
+		decoder.setError(JSON_ERROR_STATE_MISMATCH)
+	default:
+		decoder.unexpectedChar()
+	}
+	return false
+}
+
+func (decoder *decoder) parseArray() (values.RuntimeValue, bool) {
+	decoder.pos++
+	if !decoder.enter() {
+		return nil, false
+	}
+
+	var array *values.Array
+	if !decoder.isValidating() {
+		array = values.NewArray()
+	}
+	if char := decoder.peek(); char != ']' && char != '}' {
+		for {
+			element, ok := decoder.parseValue()
+			if !ok {
+				return nil, false
+			}
+			if array != nil {
+				array.SetElement(nil, element)
+			}
+			if decoder.peek() != ',' {
+				break
+			}
+			decoder.pos++
+		}
+	}
+	if !decoder.parseEnd(']', '}') {
+		return nil, false
+	}
+	return array, true
+}
+
+func (decoder *decoder) parseObject() (values.RuntimeValue, bool) {
+	decoder.pos++
+	if !decoder.enter() {
+		return nil, false
+	}
+
+	var result values.RuntimeValue
+	asArray := decoder.flags&JSON_OBJECT_AS_ARRAY != 0
+	if !decoder.isValidating() {
+		if asArray {
+			result = values.NewArray()
+		} else {
+			result = values.NewObject(decoder.stdClass)
+		}
+	}
+	if char := decoder.peek(); char != '}' && char != ']' {
+		for {
+			if decoder.peek() != '"' {
+				decoder.unexpectedChar()
+				return nil, false
+			}
+			key, ok := decoder.parseString()
+			if !ok {
+				return nil, false
+			}
+			if decoder.peek() != ':' {
+				decoder.unexpectedChar()
+				return nil, false
+			}
+			decoder.pos++
+			value, ok := decoder.parseValue()
+			if !ok {
+				return nil, false
+			}
+
+			switch result := result.(type) {
+			case *values.Array:
+				if err := result.SetElement(values.NewStr(key), value); err != nil {
+					decoder.setError(JSON_ERROR_SYNTAX)
+					return nil, false
+				}
+			case *values.Object:
+				if strings.HasPrefix(key, "\x00") {
+					decoder.setError(JSON_ERROR_INVALID_PROPERTY_NAME)
+					return nil, false
+				}
+				result.SetProperty("$"+key, value)
+			}
+
+			if decoder.peek() != ',' {
+				break
+			}
+			decoder.pos++
+		}
+	}
+	if !decoder.parseEnd('}', ']') {
+		return nil, false
+	}
+	return result, true
+}
+
+func (decoder *decoder) parseNumber() (values.RuntimeValue, bool) {
+	start := decoder.pos
+	input := decoder.input
+	isFloat := false
+
+	skipDigits := func() int {
+		digitsStart := decoder.pos
+		for decoder.pos < len(input) && input[decoder.pos] >= '0' && input[decoder.pos] <= '9' {
+			decoder.pos++
+		}
+		return decoder.pos - digitsStart
+	}
+
+	if input[decoder.pos] == '-' {
+		decoder.pos++
+	}
+	// A leading zero must not be followed by other digits
+	if decoder.pos < len(input) && input[decoder.pos] == '0' {
+		decoder.pos++
+	} else if skipDigits() == 0 {
+		decoder.unexpectedChar()
+		return nil, false
+	}
+	if decoder.pos < len(input) && input[decoder.pos] == '.' {
+		decoder.pos++
+		if skipDigits() == 0 {
+			decoder.unexpectedChar()
+			return nil, false
+		}
+		isFloat = true
+	}
+	if decoder.pos < len(input) && (input[decoder.pos] == 'e' || input[decoder.pos] == 'E') {
+		decoder.pos++
+		if decoder.pos < len(input) && (input[decoder.pos] == '+' || input[decoder.pos] == '-') {
+			decoder.pos++
+		}
+		if skipDigits() == 0 {
+			decoder.unexpectedChar()
+			return nil, false
+		}
+		isFloat = true
+	}
+
+	if decoder.isValidating() {
+		return nil, true
+	}
+	number := input[start:decoder.pos]
+	if !isFloat {
+		if intValue, err := strconv.ParseInt(number, 10, 64); err == nil {
+			return values.NewInt(intValue), true
+		}
+		if decoder.flags&JSON_BIGINT_AS_STRING != 0 {
+			return values.NewStr(number), true
+		}
+	}
+	// Numbers out of range are parsed as +/-INF
+	floatValue, _ := strconv.ParseFloat(number, 64)
+	return values.NewFloat(floatValue), true
+}
+
+func (decoder *decoder) parseString() (string, bool) {
+	decoder.pos++
+	input := decoder.input
+	var builder strings.Builder
+	for {
+		if decoder.pos >= len(input) {
+			// Unterminated strings are reported like PHP that reads the terminating NUL byte
+			decoder.setError(JSON_ERROR_CTRL_CHAR)
+			return "", false
+		}
+		char := input[decoder.pos]
+		switch {
+		case char == '"':
+			decoder.pos++
+			return builder.String(), true
+
+		case char < 0x20:
+			decoder.setError(JSON_ERROR_CTRL_CHAR)
+			return "", false
+
+		case char == '\\':
+			if decoder.pos+1 >= len(input) {
+				decoder.setError(JSON_ERROR_SYNTAX)
+				return "", false
+			}
+			decoder.pos += 2
+			switch input[decoder.pos-1] {
+			case '"':
+				builder.WriteByte('"')
+			case '\\':
+				builder.WriteByte('\\')
+			case '/':
+				builder.WriteByte('/')
+			case 'b':
+				builder.WriteByte('\b')
+			case 'f':
+				builder.WriteByte('\f')
+			case 'n':
+				builder.WriteByte('\n')
+			case 'r':
+				builder.WriteByte('\r')
+			case 't':
+				builder.WriteByte('\t')
+			case 'u':
+				r, ok := decoder.parseUnicodeEscape()
+				if !ok {
+					return "", false
+				}
+				builder.WriteRune(r)
+			default:
+				decoder.setError(JSON_ERROR_SYNTAX)
+				return "", false
+			}
+
+		case char >= utf8.RuneSelf:
+			r, size := utf8.DecodeRuneInString(input[decoder.pos:])
+			if r == utf8.RuneError && size == 1 {
+				decoder.pos++
+				switch {
+				case decoder.flags&JSON_INVALID_UTF8_IGNORE != 0:
+				case decoder.flags&JSON_INVALID_UTF8_SUBSTITUTE != 0:
+					builder.WriteRune(utf8.RuneError)
+				default:
+					decoder.setError(JSON_ERROR_UTF8)
+					return "", false
+				}
+				continue
+			}
+			builder.WriteString(input[decoder.pos : decoder.pos+size])
+			decoder.pos += size
+
+		default:
+			builder.WriteByte(char)
+			decoder.pos++
+		}
+	}
+}
+
+// Parse the four hex digits of an "\u" escape (and the low surrogate of a surrogate pair)
+func (decoder *decoder) parseUnicodeEscape() (rune, bool) {
+	readCodeUnit := func() (rune, bool) {
+		if decoder.pos+4 > len(decoder.input) {
+			decoder.setError(JSON_ERROR_SYNTAX)
+			return 0, false
+		}
+		codeUnit, err := strconv.ParseUint(decoder.input[decoder.pos:decoder.pos+4], 16, 16)
+		if err != nil {
+			decoder.setError(JSON_ERROR_SYNTAX)
+			return 0, false
+		}
+		decoder.pos += 4
+		return rune(codeUnit), true
+	}
+
+	codeUnit, ok := readCodeUnit()
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case codeUnit >= 0xDC00 && codeUnit <= 0xDFFF:
+		decoder.setError(JSON_ERROR_UTF16)
+		return 0, false
+	case codeUnit >= 0xD800 && codeUnit <= 0xDBFF:
+		if !strings.HasPrefix(decoder.input[decoder.pos:], `\u`) {
+			decoder.setError(JSON_ERROR_UTF16)
+			return 0, false
+		}
+		decoder.pos += 2
+		lowSurrogate, ok := readCodeUnit()
+		if !ok {
+			return 0, false
+		}
+		if lowSurrogate < 0xDC00 || lowSurrogate > 0xDFFF {
+			decoder.setError(JSON_ERROR_UTF16)
+			return 0, false
+		}
+		return utf16.DecodeRune(codeUnit, lowSurrogate), true
+	default:
+		return codeUnit, true
+	}
+}
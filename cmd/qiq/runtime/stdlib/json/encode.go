@@ -0,0 +1,291 @@
+package json
+
+import (
+	"QIQ/cmd/qiq/common"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/values"
+	"bytes"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Matches numeric strings like " 12", "-1.5" or "1e3" that are encoded as numbers with JSON_NUMERIC_CHECK
+var numericStringRegex = regexp.MustCompile(`^[ \t\n\r\v\f]*[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?[ \t\n\r\v\f]*$`)
+
+type encoder struct {
+	context  runtime.Context
+	flags    int64
+	maxDepth int64
+	depth    int64
+	// Objects that are currently encoded (for the recursion detection)
+	visited   map[*values.Object]bool
+	errorCode int64
+	builder   bytes.Buffer
+}
+
+// Encode the value as JSON like json_encode.
+// Returns the (partial) result and the JSON_ERROR_* code of the first error.
+func Encode(value values.RuntimeValue, flags int64, depth int64, context runtime.Context) (string, int64, phpError.Error) {
+	encoder := &encoder{context: context, flags: flags, maxDepth: depth, visited: map[*values.Object]bool{}}
+	if err := encoder.encodeValue(value); err != nil {
+		return "", JSON_ERROR_NONE, err
+	}
+	return encoder.builder.String(), encoder.errorCode, nil
+}
+
+func (encoder *encoder) setError(code int64) {
+	if encoder.errorCode == JSON_ERROR_NONE {
+		encoder.errorCode = code
+	}
+}
+
+func (encoder *encoder) hasFlag(flag int64) bool { return encoder.flags&flag != 0 }
+
+func (encoder *encoder) encodeValue(value values.RuntimeValue) phpError.Error {
+	switch value := value.(type) {
+	case *values.Null:
+		encoder.builder.WriteString("null")
+	case *values.Bool:
+		encoder.builder.WriteString(strconv.FormatBool(value.Value))
+	case *values.Int:
+		encoder.builder.WriteString(strconv.FormatInt(value.Value, 10))
+	case *values.Float:
+		encoder.encodeFloat(value.Value)
+	case *values.Str:
+		encoder.encodeString(value.Value, encoder.hasFlag(JSON_NUMERIC_CHECK))
+	case *values.Array:
+		keys := value.Keys
+		elements := make([]values.RuntimeValue, len(keys))
+		for i, key := range keys {
+			slot, _ := value.GetElement(key)
+			elements[i] = slot.Value
+		}
+		return encoder.encodeArray(keys, elements, !encoder.hasFlag(JSON_FORCE_OBJECT) && isList(keys))
+	case *values.Object:
+		return encoder.encodeObject(value)
+	default:
+		encoder.setError(JSON_ERROR_UNSUPPORTED_TYPE)
+		encoder.builder.WriteString("null")
+	}
+	return nil
+}
+
+func (encoder *encoder) encodeFloat(value float64) {
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		encoder.setError(JSON_ERROR_INF_OR_NAN)
+		encoder.builder.WriteString("0")
+		return
+	}
+	str := common.FormatFloatWithPrecision(value, int(encoder.context.Interpreter.GetIni().GetInt("serialize_precision")), 'e')
+	if value == 0 && math.Signbit(value) {
+		str = "-" + str
+	}
+	if encoder.hasFlag(JSON_PRESERVE_ZERO_FRACTION) && !strings.ContainsAny(str, ".e") {
+		str += ".0"
+	}
+	encoder.builder.WriteString(str)
+}
+
+// Check if the keys are the consecutive integers starting with 0
+func isList(keys []values.RuntimeValue) bool {
+	for i, key := range keys {
+		if key.GetType() != values.IntValue || key.(*values.Int).Value != int64(i) {
+			return false
+		}
+	}
+	return true
+}
+
+func (encoder *encoder) encodeObject(object *values.Object) phpError.Error {
+	if encoder.visited[object] {
+		encoder.setError(JSON_ERROR_RECURSION)
+		encoder.builder.WriteString("null")
+		return nil
+	}
+
+	if encoder.context.Interpreter.GetExectionContext().IsInstanceOf(object.Class, "JsonSerializable") {
+		encoder.visited[object] = true
+		result, err := encoder.context.Interpreter.CallMethod(object, "jsonSerialize", []values.RuntimeValue{}, encoder.context.Env)
+		if err != nil {
+			return err
+		}
+		// An object returning itself is encoded with its properties
+		if result != object {
+			err = encoder.encodeValue(result)
+			delete(encoder.visited, object)
+			return err
+		}
+		delete(encoder.visited, object)
+	}
+
+	keys := []values.RuntimeValue{}
+	elements := []values.RuntimeValue{}
+	for _, propertyName := range object.PropertyNames {
+		if property, found := object.Class.Properties[propertyName]; found && property.Visibility != "public" {
+			continue
+		}
+		value, found := object.GetProperty(propertyName)
+		if !found {
+			continue
+		}
+		keys = append(keys, values.NewStr(strings.TrimPrefix(propertyName, "$")))
+		elements = append(elements, value)
+	}
+
+	encoder.visited[object] = true
+	err := encoder.encodeArray(keys, elements, false)
+	delete(encoder.visited, object)
+	return err
+}
+
+func (encoder *encoder) encodeArray(keys []values.RuntimeValue, elements []values.RuntimeValue, asList bool) phpError.Error {
+	open, close := "{", "}"
+	if asList {
+		open, close = "[", "]"
+	}
+
+	encoder.depth++
+	if encoder.depth > encoder.maxDepth {
+		encoder.setError(JSON_ERROR_DEPTH)
+		if !encoder.hasFlag(JSON_PARTIAL_OUTPUT_ON_ERROR) {
+			encoder.depth--
+			encoder.builder.WriteString(open + close)
+			return nil
+		}
+	}
+
+	encoder.builder.WriteString(open)
+	for i, key := range keys {
+		if i > 0 {
+			encoder.builder.WriteByte(',')
+		}
+		encoder.writeNewLineAndIndent()
+		if !asList {
+			if key.GetType() == values.IntValue {
+				encoder.builder.WriteString(`"` + strconv.FormatInt(key.(*values.Int).Value, 10) + `"`)
+			} else {
+				encoder.encodeString(key.(*values.Str).Value, false)
+			}
+			encoder.builder.WriteByte(':')
+			if encoder.hasFlag(JSON_PRETTY_PRINT) {
+				encoder.builder.WriteByte(' ')
+			}
+		}
+		if err := encoder.encodeValue(elements[i]); err != nil {
+			return err
+		}
+	}
+	encoder.depth--
+	if len(keys) > 0 {
+		encoder.writeNewLineAndIndent()
+	}
+	encoder.builder.WriteString(close)
+	return nil
+}
+
+func (encoder *encoder) writeNewLineAndIndent() {
+	if !encoder.hasFlag(JSON_PRETTY_PRINT) {
+		return
+	}
+	encoder.builder.WriteByte('\n')
+	encoder.builder.WriteString(strings.Repeat("    ", int(encoder.depth)))
+}
+
+func (encoder *encoder) encodeString(str string, numericCheck bool) {
+	if numericCheck && numericStringRegex.MatchString(str) {
+		str = strings.Trim(str, " \t\n\r\v\f")
+		if intValue, err := strconv.ParseInt(str, 10, 64); err == nil {
+			encoder.builder.WriteString(strconv.FormatInt(intValue, 10))
+			return
+		}
+		floatValue, _ := strconv.ParseFloat(str, 64)
+		encoder.encodeFloat(floatValue)
+		return
+	}
+
+	const hexDigits = "0123456789abcdef"
+	start := encoder.builder.Len()
+	encoder.builder.WriteByte('"')
+	for i := 0; i < len(str); {
+		char := str[i]
+		if char >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(str[i:])
+			if r == utf8.RuneError && size == 1 {
+				i++
+				switch {
+				case encoder.hasFlag(JSON_INVALID_UTF8_IGNORE):
+					continue
+				case encoder.hasFlag(JSON_INVALID_UTF8_SUBSTITUTE):
+					r = utf8.RuneError
+				default:
+					encoder.setError(JSON_ERROR_UTF8)
+					// The partial output contains null instead of the string
+					encoder.builder.Truncate(start)
+					encoder.builder.WriteString("null")
+					return
+				}
+			} else {
+				i += size
+			}
+
+			if encoder.hasFlag(JSON_UNESCAPED_UNICODE) &&
+				((r != 0x2028 && r != 0x2029) || encoder.hasFlag(JSON_UNESCAPED_LINE_TERMINATORS)) {
+				encoder.builder.WriteRune(r)
+				continue
+			}
+			codeUnits := []rune{r}
+			if r >= 0x10000 {
+				r -= 0x10000
+				codeUnits = []rune{0xD800 | (r >> 10), 0xDC00 | (r & 0x3FF)}
+			}
+			for _, codeUnit := range codeUnits {
+				encoder.builder.WriteString(`\u`)
+				for shift := 12; shift >= 0; shift -= 4 {
+					encoder.builder.WriteByte(hexDigits[(codeUnit>>shift)&0xF])
+				}
+			}
+			continue
+		}
+
+		i++
+		switch {
+		case char == '"' && encoder.hasFlag(JSON_HEX_QUOT):
+			encoder.builder.WriteString(`\u0022`)
+		case char == '"':
+			encoder.builder.WriteString(`\"`)
+		case char == '\\':
+			encoder.builder.WriteString(`\\`)
+		case char == '/' && !encoder.hasFlag(JSON_UNESCAPED_SLASHES):
+			encoder.builder.WriteString(`\/`)
+		case char == '\b':
+			encoder.builder.WriteString(`\b`)
+		case char == '\f':
+			encoder.builder.WriteString(`\f`)
+		case char == '\n':
+			encoder.builder.WriteString(`\n`)
+		case char == '\r':
+			encoder.builder.WriteString(`\r`)
+		case char == '\t':
+			encoder.builder.WriteString(`\t`)
+		case char == '<' && encoder.hasFlag(JSON_HEX_TAG):
+			encoder.builder.WriteString(`\u003C`)
+		case char == '>' && encoder.hasFlag(JSON_HEX_TAG):
+			encoder.builder.WriteString(`\u003E`)
+		case char == '&' && encoder.hasFlag(JSON_HEX_AMP):
+			encoder.builder.WriteString(`\u0026`)
+		case char == '\'' && encoder.hasFlag(JSON_HEX_APOS):
+			encoder.builder.WriteString(`\u0027`)
+		case char < 0x20:
+			encoder.builder.WriteString(`\u00`)
+			encoder.builder.WriteByte(hexDigits[char>>4])
+			encoder.builder.WriteByte(hexDigits[char&0xF])
+		default:
+			encoder.builder.WriteByte(char)
+		}
+	}
+	encoder.builder.WriteByte('"')
+}
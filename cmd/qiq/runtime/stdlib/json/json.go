@@ -0,0 +1,257 @@
+package json
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+	"math"
+)
+
+func Register(environment runtime.Environment) {
+	// Category: JSON Functions
+	environment.AddNativeFunction("json_decode", nativeFn_json_decode)
+	environment.AddNativeFunction("json_encode", nativeFn_json_encode)
+	environment.AddNativeFunction("json_last_error", nativeFn_json_last_error)
+	environment.AddNativeFunction("json_last_error_msg", nativeFn_json_last_error_msg)
+	environment.AddNativeFunction("json_validate", nativeFn_json_validate)
+
+	// Const Category: JSON Constants
+	// Spec: https://www.php.net/manual/en/json.constants.php
+	environment.AddPredefinedConstant("JSON_HEX_TAG", values.NewInt(JSON_HEX_TAG))
+	environment.AddPredefinedConstant("JSON_HEX_AMP", values.NewInt(JSON_HEX_AMP))
+	environment.AddPredefinedConstant("JSON_HEX_APOS", values.NewInt(JSON_HEX_APOS))
+	environment.AddPredefinedConstant("JSON_HEX_QUOT", values.NewInt(JSON_HEX_QUOT))
+	environment.AddPredefinedConstant("JSON_FORCE_OBJECT", values.NewInt(JSON_FORCE_OBJECT))
+	environment.AddPredefinedConstant("JSON_NUMERIC_CHECK", values.NewInt(JSON_NUMERIC_CHECK))
+	environment.AddPredefinedConstant("JSON_UNESCAPED_SLASHES", values.NewInt(JSON_UNESCAPED_SLASHES))
+	environment.AddPredefinedConstant("JSON_PRETTY_PRINT", values.NewInt(JSON_PRETTY_PRINT))
+	environment.AddPredefinedConstant("JSON_UNESCAPED_UNICODE", values.NewInt(JSON_UNESCAPED_UNICODE))
+	environment.AddPredefinedConstant("JSON_PARTIAL_OUTPUT_ON_ERROR", values.NewInt(JSON_PARTIAL_OUTPUT_ON_ERROR))
+	environment.AddPredefinedConstant("JSON_PRESERVE_ZERO_FRACTION", values.NewInt(JSON_PRESERVE_ZERO_FRACTION))
+	environment.AddPredefinedConstant("JSON_UNESCAPED_LINE_TERMINATORS", values.NewInt(JSON_UNESCAPED_LINE_TERMINATORS))
+	environment.AddPredefinedConstant("JSON_OBJECT_AS_ARRAY", values.NewInt(JSON_OBJECT_AS_ARRAY))
+	environment.AddPredefinedConstant("JSON_BIGINT_AS_STRING", values.NewInt(JSON_BIGINT_AS_STRING))
+	environment.AddPredefinedConstant("JSON_INVALID_UTF8_IGNORE", values.NewInt(JSON_INVALID_UTF8_IGNORE))
+	environment.AddPredefinedConstant("JSON_INVALID_UTF8_SUBSTITUTE", values.NewInt(JSON_INVALID_UTF8_SUBSTITUTE))
+	environment.AddPredefinedConstant("JSON_THROW_ON_ERROR", values.NewInt(JSON_THROW_ON_ERROR))
+	environment.AddPredefinedConstant("JSON_ERROR_NONE", values.NewInt(JSON_ERROR_NONE))
+	environment.AddPredefinedConstant("JSON_ERROR_DEPTH", values.NewInt(JSON_ERROR_DEPTH))
+	environment.AddPredefinedConstant("JSON_ERROR_STATE_MISMATCH", values.NewInt(JSON_ERROR_STATE_MISMATCH))
+	environment.AddPredefinedConstant("JSON_ERROR_CTRL_CHAR", values.NewInt(JSON_ERROR_CTRL_CHAR))
+	environment.AddPredefinedConstant("JSON_ERROR_SYNTAX", values.NewInt(JSON_ERROR_SYNTAX))
+	environment.AddPredefinedConstant("JSON_ERROR_UTF8", values.NewInt(JSON_ERROR_UTF8))
+	environment.AddPredefinedConstant("JSON_ERROR_RECURSION", values.NewInt(JSON_ERROR_RECURSION))
+	environment.AddPredefinedConstant("JSON_ERROR_INF_OR_NAN", values.NewInt(JSON_ERROR_INF_OR_NAN))
+	environment.AddPredefinedConstant("JSON_ERROR_UNSUPPORTED_TYPE", values.NewInt(JSON_ERROR_UNSUPPORTED_TYPE))
+	environment.AddPredefinedConstant("JSON_ERROR_INVALID_PROPERTY_NAME", values.NewInt(JSON_ERROR_INVALID_PROPERTY_NAME))
+	environment.AddPredefinedConstant("JSON_ERROR_UTF16", values.NewInt(JSON_ERROR_UTF16))
+	environment.AddPredefinedConstant("JSON_ERROR_NON_BACKED_ENUM", values.NewInt(JSON_ERROR_NON_BACKED_ENUM))
+}
+
+const (
+	JSON_HEX_TAG                    int64 = 1
+	JSON_HEX_AMP                    int64 = 2
+	JSON_HEX_APOS                   int64 = 4
+	JSON_HEX_QUOT                   int64 = 8
+	JSON_FORCE_OBJECT               int64 = 16
+	JSON_NUMERIC_CHECK              int64 = 32
+	JSON_UNESCAPED_SLASHES          int64 = 64
+	JSON_PRETTY_PRINT               int64 = 128
+	JSON_UNESCAPED_UNICODE          int64 = 256
+	JSON_PARTIAL_OUTPUT_ON_ERROR    int64 = 512
+	JSON_PRESERVE_ZERO_FRACTION     int64 = 1024
+	JSON_UNESCAPED_LINE_TERMINATORS int64 = 2048
+	JSON_OBJECT_AS_ARRAY            int64 = 1
+	JSON_BIGINT_AS_STRING           int64 = 2
+	JSON_INVALID_UTF8_IGNORE        int64 = 1048576
+	JSON_INVALID_UTF8_SUBSTITUTE    int64 = 2097152
+	JSON_THROW_ON_ERROR             int64 = 4194304
+
+	JSON_ERROR_NONE                  int64 = 0
+	JSON_ERROR_DEPTH                 int64 = 1
+	JSON_ERROR_STATE_MISMATCH        int64 = 2
+	JSON_ERROR_CTRL_CHAR             int64 = 3
+	JSON_ERROR_SYNTAX                int64 = 4
+	JSON_ERROR_UTF8                  int64 = 5
+	JSON_ERROR_RECURSION             int64 = 6
+	JSON_ERROR_INF_OR_NAN            int64 = 7
+	JSON_ERROR_UNSUPPORTED_TYPE      int64 = 8
+	JSON_ERROR_INVALID_PROPERTY_NAME int64 = 9
+	JSON_ERROR_UTF16                 int64 = 10
+	JSON_ERROR_NON_BACKED_ENUM       int64 = 11
+)
+
+var errorMessages = map[int64]string{
+	JSON_ERROR_NONE:                  "No error",
+	JSON_ERROR_DEPTH:                 "Maximum stack depth exceeded",
+	JSON_ERROR_STATE_MISMATCH:        "State mismatch (invalid or malformed JSON)",
+	JSON_ERROR_CTRL_CHAR:             "Control character error, possibly incorrectly encoded",
+	JSON_ERROR_SYNTAX:                "Syntax error",
+	JSON_ERROR_UTF8:                  "Malformed UTF-8 characters, possibly incorrectly encoded",
+	JSON_ERROR_RECURSION:             "Recursion detected",
+	JSON_ERROR_INF_OR_NAN:            "Inf and NaN cannot be JSON encoded",
+	JSON_ERROR_UNSUPPORTED_TYPE:      "Type is not supported",
+	JSON_ERROR_INVALID_PROPERTY_NAME: "The decoded property name is invalid",
+	JSON_ERROR_UTF16:                 "Single unpaired UTF-16 surrogate in unicode escape",
+	JSON_ERROR_NON_BACKED_ENUM:       "Non-backed enums have no default serialization",
+}
+
+// Get the message of the JSON_ERROR_* code as returned by json_last_error_msg
+func ErrorMessage(code int64) string {
+	if message, found := errorMessages[code]; found {
+		return message
+	}
+	return "Unknown error"
+}
+
+// Report the result of a JSON function.
+// With JSON_THROW_ON_ERROR an error is thrown as JsonException and the last error is not changed.
+func reportError(code int64, flags int64, context runtime.Context) phpError.Error {
+	if flags&JSON_THROW_ON_ERROR != 0 {
+		if code == JSON_ERROR_NONE {
+			return nil
+		}
+		return phpError.NewCodedError(code, "Uncaught JsonException: %s", ErrorMessage(code))
+	}
+	context.Interpreter.GetExectionContext().SetJsonLastError(code)
+	return nil
+}
+
+// Validate the $depth argument of the JSON functions
+func validateDepth(functionName string, argument int, depth int64) phpError.Error {
+	if depth <= 0 {
+		return phpError.NewError("Uncaught ValueError: %s(): Argument #%d ($depth) must be greater than 0", functionName, argument)
+	}
+	if depth > math.MaxInt32 {
+		return phpError.NewError("Uncaught ValueError: %s(): Argument #%d ($depth) must be less than %d", functionName, argument, math.MaxInt32)
+	}
+	return nil
+}
+
+// -------------------------------------- json_decode -------------------------------------- MARK: json_decode
+
+func nativeFn_json_decode(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.json-decode.php
+	args, err := funcParamValidator.NewValidator("json_decode").
+		AddParam("$json", []string{"string"}, nil).
+		AddParam("$associative", []string{"bool", "null"}, values.NewNull()).
+		AddParam("$depth", []string{"int"}, values.NewInt(512)).
+		AddParam("$flags", []string{"int"}, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	depth := args[2].(*values.Int).Value
+	if err := validateDepth("json_decode", 3, depth); err != nil {
+		return values.NewVoid(), err
+	}
+
+	flags := args[3].(*values.Int).Value
+	if args[1].GetType() == values.BoolValue {
+		if args[1].(*values.Bool).Value {
+			flags |= JSON_OBJECT_AS_ARRAY
+		} else {
+			flags &^= JSON_OBJECT_AS_ARRAY
+		}
+	}
+
+	result, code, err := Decode(args[0].(*values.Str).Value, depth, flags, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if err := reportError(code, flags, context); err != nil {
+		return values.NewVoid(), err
+	}
+	if code != JSON_ERROR_NONE {
+		return values.NewNull(), nil
+	}
+	return result, nil
+}
+
+// -------------------------------------- json_encode -------------------------------------- MARK: json_encode
+
+func nativeFn_json_encode(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.json-encode.php
+	args, err := funcParamValidator.NewValidator("json_encode").
+		AddParam("$value", []string{"mixed"}, nil).
+		AddParam("$flags", []string{"int"}, values.NewInt(0)).
+		AddParam("$depth", []string{"int"}, values.NewInt(512)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	depth := args[2].(*values.Int).Value
+	if err := validateDepth("json_encode", 3, depth); err != nil {
+		return values.NewVoid(), err
+	}
+
+	flags := args[1].(*values.Int).Value
+	result, code, err := Encode(args[0], flags, depth, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if err := reportError(code, flags, context); err != nil {
+		return values.NewVoid(), err
+	}
+	if code != JSON_ERROR_NONE && flags&JSON_PARTIAL_OUTPUT_ON_ERROR == 0 {
+		return values.NewBool(false), nil
+	}
+	return values.NewStr(result), nil
+}
+
+// -------------------------------------- json_last_error -------------------------------------- MARK: json_last_error
+
+func nativeFn_json_last_error(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.json-last-error.php
+	_, err := funcParamValidator.NewValidator("json_last_error").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(context.Interpreter.GetExectionContext().GetJsonLastError()), nil
+}
+
+// -------------------------------------- json_last_error_msg -------------------------------------- MARK: json_last_error_msg
+
+func nativeFn_json_last_error_msg(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.json-last-error-msg.php
+	_, err := funcParamValidator.NewValidator("json_last_error_msg").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(ErrorMessage(context.Interpreter.GetExectionContext().GetJsonLastError())), nil
+}
+
+// -------------------------------------- json_validate -------------------------------------- MARK: json_validate
+
+func nativeFn_json_validate(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.json-validate.php
+	args, err := funcParamValidator.NewValidator("json_validate").
+		AddParam("$json", []string{"string"}, nil).
+		AddParam("$depth", []string{"int"}, values.NewInt(512)).
+		AddParam("$flags", []string{"int"}, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	flags := args[2].(*values.Int).Value
+	if flags&^JSON_INVALID_UTF8_IGNORE != 0 {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: json_validate(): Argument #3 ($flags) must be a valid flag (allowed flags: JSON_INVALID_UTF8_IGNORE)",
+		)
+	}
+
+	depth := args[1].(*values.Int).Value
+	if err := validateDepth("json_validate", 2, depth); err != nil {
+		return values.NewVoid(), err
+	}
+
+	code := Validate(args[0].(*values.Str).Value, depth, flags)
+	context.Interpreter.GetExectionContext().SetJsonLastError(code)
+	return values.NewBool(code == JSON_ERROR_NONE), nil
+}
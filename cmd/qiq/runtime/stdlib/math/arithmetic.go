@@ -0,0 +1,78 @@
+package math
+
+import (
+	"QIQ/cmd/qiq/runtime/values"
+	goMath "math"
+)
+
+// Spec: https://www.php.net/manual/en/language.types.integer.php#language.types.integer.overflow
+// If PHP encounters a number beyond the bounds of the int type, it will be interpreted as a float instead.
+// Also, an operation which results in a number beyond the bounds of the int type will return a float instead.
+
+// Add two integers. The result is a float if the sum overflows.
+func AddInt(lhs int64, rhs int64) values.RuntimeValue {
+	result := lhs + rhs
+	if (result > lhs) != (rhs > 0) {
+		return values.NewFloat(float64(lhs) + float64(rhs))
+	}
+	return values.NewInt(result)
+}
+
+// Subtract two integers. The result is a float if the difference overflows.
+func SubInt(lhs int64, rhs int64) values.RuntimeValue {
+	result := lhs - rhs
+	if (result < lhs) != (rhs > 0) {
+		return values.NewFloat(float64(lhs) - float64(rhs))
+	}
+	return values.NewInt(result)
+}
+
+// Multiply two integers. The result is a float if the product overflows.
+func MulInt(lhs int64, rhs int64) values.RuntimeValue {
+	if lhs == 0 || rhs == 0 {
+		return values.NewInt(0)
+	}
+	result := lhs * rhs
+	if result/rhs != lhs || (lhs == -1 && rhs == goMath.MinInt64) || (rhs == -1 && lhs == goMath.MinInt64) {
+		return values.NewFloat(float64(lhs) * float64(rhs))
+	}
+	return values.NewInt(result)
+}
+
+// Raise an integer to the power of an integer.
+// The result is a float if the exponent is negative or the power overflows.
+func PowInt(base int64, exponent int64) values.RuntimeValue {
+	if exponent < 0 {
+		return values.NewFloat(goMath.Pow(float64(base), float64(exponent)))
+	}
+
+	// Exponentiation by squaring
+	result := int64(1)
+	for exponent > 0 {
+		if exponent%2 == 1 {
+			product := MulInt(result, base)
+			if product.GetType() != values.IntValue {
+				return values.NewFloat(goMath.Pow(float64(base), float64(exponent)) * float64(result))
+			}
+			result = product.(*values.Int).Value
+		}
+		exponent /= 2
+		if exponent > 0 {
+			square := MulInt(base, base)
+			if square.GetType() != values.IntValue {
+				return values.NewFloat(goMath.Pow(float64(base), float64(exponent*2)) * float64(result))
+			}
+			base = square.(*values.Int).Value
+		}
+	}
+	return values.NewInt(result)
+}
+
+// Divide two integers. The result is an integer if the division is exact and a float otherwise.
+// The divisor must not be zero.
+func DivInt(lhs int64, rhs int64) values.RuntimeValue {
+	if lhs%rhs == 0 && !(lhs == goMath.MinInt64 && rhs == -1) {
+		return values.NewInt(lhs / rhs)
+	}
+	return values.NewFloat(float64(lhs) / float64(rhs))
+}
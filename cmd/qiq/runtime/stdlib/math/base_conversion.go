@@ -0,0 +1,170 @@
+package math
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+	goMath "math"
+	"strconv"
+	"strings"
+)
+
+// -------------------------------------- base_convert -------------------------------------- MARK: base_convert
+
+func nativeFn_base_convert(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("base_convert").
+		AddParam("$num", []string{"string"}, nil).
+		AddParam("$frombase", []string{"int"}, nil).
+		AddParam("$tobase", []string{"int"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.base-convert.php
+	fromBase := args[1].(*values.Int).Value
+	if fromBase < 2 || fromBase > 36 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: base_convert(): Argument #2 ($frombase) must be between 2 and 36 (inclusive)")
+	}
+	toBase := args[2].(*values.Int).Value
+	if toBase < 2 || toBase > 36 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: base_convert(): Argument #3 ($tobase) must be between 2 and 36 (inclusive)")
+	}
+
+	number := baseToNumber(args[0].(*values.Str).Value, fromBase, context)
+	if number.GetType() == values.IntValue {
+		return values.NewStr(strconv.FormatUint(uint64(number.(*values.Int).Value), int(toBase))), nil
+	}
+
+	// Numbers beyond the int range are converted digit by digit
+	value := goMath.Floor(number.(*values.Float).Value)
+	if goMath.IsInf(value, 0) {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: An infinite value cannot be converted to base %d", toBase)
+	}
+	digits := []byte{}
+	for {
+		digits = append(digits, strconv.FormatInt(int64(goMath.Mod(value, float64(toBase))), int(toBase))[0])
+		value /= float64(toBase)
+		if goMath.Abs(value) < 1 {
+			break
+		}
+	}
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return values.NewStr(string(digits)), nil
+}
+
+// -------------------------------------- bindec -------------------------------------- MARK: bindec
+
+func nativeFn_bindec(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("bindec").AddParam("$binary_string", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.bindec.php
+	return baseToNumber(args[0].(*values.Str).Value, 2, context), nil
+}
+
+// -------------------------------------- decbin -------------------------------------- MARK: decbin
+
+func nativeFn_decbin(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.decbin.php
+	return lib_decToBase("decbin", args, 2)
+}
+
+// -------------------------------------- dechex -------------------------------------- MARK: dechex
+
+func nativeFn_dechex(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.dechex.php
+	return lib_decToBase("dechex", args, 16)
+}
+
+// -------------------------------------- decoct -------------------------------------- MARK: decoct
+
+func nativeFn_decoct(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.decoct.php
+	return lib_decToBase("decoct", args, 8)
+}
+
+// Negative numbers are converted as unsigned integers, e.g. decbin(-1) returns 64 ones
+func lib_decToBase(functionName string, args []values.RuntimeValue, base int) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).AddParam("$num", []string{"int"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(strconv.FormatUint(uint64(args[0].(*values.Int).Value), base)), nil
+}
+
+// -------------------------------------- hexdec -------------------------------------- MARK: hexdec
+
+func nativeFn_hexdec(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("hexdec").AddParam("$hex_string", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.hexdec.php
+	return baseToNumber(args[0].(*values.Str).Value, 16, context), nil
+}
+
+// -------------------------------------- octdec -------------------------------------- MARK: octdec
+
+func nativeFn_octdec(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("octdec").AddParam("$octal_string", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.octdec.php
+	return baseToNumber(args[0].(*values.Str).Value, 8, context), nil
+}
+
+// Convert the digits of the given base to a number. Invalid characters are ignored with a deprecation notice.
+// The result is a float if the number is too large for an integer.
+func baseToNumber(str string, base int64, context runtime.Context) values.RuntimeValue {
+	str = strings.TrimSpace(str)
+	// Skip the prefix of the base
+	if len(str) >= 2 && str[0] == '0' {
+		prefix := strings.ToLower(str[1:2])
+		if (base == 16 && prefix == "x") || (base == 8 && prefix == "o") || (base == 2 && prefix == "b") {
+			str = str[2:]
+		}
+	}
+
+	var intValue int64
+	var floatValue float64
+	isFloat := false
+	invalidChars := false
+	cutoff := goMath.MaxInt64 / base
+	cutLimit := goMath.MaxInt64 % base
+	for _, char := range []byte(str) {
+		digit, err := strconv.ParseInt(string(char), 36, 64)
+		if err != nil || digit >= base {
+			invalidChars = true
+			continue
+		}
+		if !isFloat {
+			if intValue < cutoff || (intValue == cutoff && digit <= cutLimit) {
+				intValue = intValue*base + digit
+				continue
+			}
+			isFloat = true
+			floatValue = float64(intValue)
+		}
+		floatValue = floatValue*float64(base) + float64(digit)
+	}
+
+	if invalidChars {
+		context.Interpreter.PrintError(phpError.NewDeprecatedError(
+			"Invalid characters passed for attempted conversion, these have been ignored%s", context.InPosition(),
+		))
+	}
+	if isFloat {
+		return values.NewFloat(floatValue)
+	}
+	return values.NewInt(intValue)
+}
@@ -0,0 +1,77 @@
+package math
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Apply the function to the float argument "$num"
+func lib_floatFunction(functionName string, args []values.RuntimeValue, function func(float64) float64) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).AddParam("$num", []string{"float"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewFloat(function(args[0].(*values.Float).Value)), nil
+}
+
+// Check the float argument "$num" with the predicate
+func lib_floatPredicate(functionName string, args []values.RuntimeValue, predicate func(float64) bool) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).AddParam("$num", []string{"float"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(predicate(args[0].(*values.Float).Value)), nil
+}
+
+func arrayValues(array *values.Array) []values.RuntimeValue {
+	result := make([]values.RuntimeValue, 0, len(array.Keys))
+	for _, key := range array.Keys {
+		slot, _ := array.GetElement(key)
+		result = append(result, slot.Value)
+	}
+	return result
+}
+
+// Convert an operand of an arithmetic operation to int or float.
+// Returns false for arrays, objects, resources and non-numeric strings.
+func toNumber(value values.RuntimeValue) (values.RuntimeValue, bool) {
+	switch value.GetType() {
+	case values.IntValue, values.FloatValue:
+		return value, true
+	case values.BoolValue:
+		if value.(*values.Bool).Value {
+			return values.NewInt(1), true
+		}
+		return values.NewInt(0), true
+	case values.NullValue:
+		return values.NewInt(0), true
+	case values.StrValue:
+		// Spec: https://www.php.net/manual/en/language.types.numeric-strings.php
+		str := strings.Trim(value.(*values.Str).Value, " \t\n\r\v\f")
+		if str == "" || strings.Trim(str, "+-.0123456789eE") != "" {
+			return nil, false
+		}
+		if intValue, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return values.NewInt(intValue), true
+		}
+		if floatValue, err := strconv.ParseFloat(str, 64); err == nil || errors.Is(err, strconv.ErrRange) {
+			return values.NewFloat(floatValue), true
+		}
+		return nil, false
+	default:
+		return nil, false
+	}
+}
+
+func toFloat(number values.RuntimeValue) float64 {
+	if number.GetType() == values.IntValue {
+		return float64(number.(*values.Int).Value)
+	}
+	return number.(*values.Float).Value
+}
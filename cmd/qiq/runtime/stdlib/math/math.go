@@ -16,16 +16,55 @@ func Register(environment runtime.Environment) {
 	environment.AddNativeFunction("acosh", nativeFn_acosh)
 	environment.AddNativeFunction("asin", nativeFn_asin)
 	environment.AddNativeFunction("asinh", nativeFn_asinh)
+	environment.AddNativeFunction("atan", nativeFn_atan)
+	environment.AddNativeFunction("atan2", nativeFn_atan2)
+	environment.AddNativeFunction("atanh", nativeFn_atanh)
+	environment.AddNativeFunction("base_convert", nativeFn_base_convert)
+	environment.AddNativeFunction("bindec", nativeFn_bindec)
+	environment.AddNativeFunction("ceil", nativeFn_ceil)
 	environment.AddNativeFunction("clamp", nativeFn_clamp)
+	environment.AddNativeFunction("cos", nativeFn_cos)
+	environment.AddNativeFunction("cosh", nativeFn_cosh)
+	environment.AddNativeFunction("decbin", nativeFn_decbin)
+	environment.AddNativeFunction("dechex", nativeFn_dechex)
+	environment.AddNativeFunction("decoct", nativeFn_decoct)
+	environment.AddNativeFunction("deg2rad", nativeFn_deg2rad)
+	environment.AddNativeFunction("exp", nativeFn_exp)
+	environment.AddNativeFunction("expm1", nativeFn_expm1)
+	environment.AddNativeFunction("fdiv", nativeFn_fdiv)
+	environment.AddNativeFunction("floor", nativeFn_floor)
+	environment.AddNativeFunction("fmod", nativeFn_fmod)
+	environment.AddNativeFunction("fpow", nativeFn_fpow)
+	environment.AddNativeFunction("hexdec", nativeFn_hexdec)
+	environment.AddNativeFunction("hypot", nativeFn_hypot)
+	environment.AddNativeFunction("intdiv", nativeFn_intdiv)
+	environment.AddNativeFunction("is_finite", nativeFn_is_finite)
+	environment.AddNativeFunction("is_infinite", nativeFn_is_infinite)
+	environment.AddNativeFunction("is_nan", nativeFn_is_nan)
+	environment.AddNativeFunction("log", nativeFn_log)
+	environment.AddNativeFunction("log10", nativeFn_log10)
+	environment.AddNativeFunction("log1p", nativeFn_log1p)
+	environment.AddNativeFunction("max", nativeFn_max)
+	environment.AddNativeFunction("min", nativeFn_min)
+	environment.AddNativeFunction("octdec", nativeFn_octdec)
 	environment.AddNativeFunction("pi", nativeFn_pi)
+	environment.AddNativeFunction("pow", nativeFn_pow)
+	environment.AddNativeFunction("rad2deg", nativeFn_rad2deg)
+	environment.AddNativeFunction("round", nativeFn_round)
+	environment.AddNativeFunction("sin", nativeFn_sin)
+	environment.AddNativeFunction("sinh", nativeFn_sinh)
+	environment.AddNativeFunction("sqrt", nativeFn_sqrt)
+	environment.AddNativeFunction("tan", nativeFn_tan)
+	environment.AddNativeFunction("tanh", nativeFn_tanh)
 
 	// Const Category: Mathematical Constants
 	// Spec: https://www.php.net/manual/en/math.constants.php
+	environment.AddPredefinedConstant("INF", values.NewFloat(goMath.Inf(1)))
 	environment.AddPredefinedConstant("M_1_PI", values.NewFloat(1/goMath.Pi))
 	environment.AddPredefinedConstant("M_2_PI", values.NewFloat(2/goMath.Pi))
 	environment.AddPredefinedConstant("M_2_SQRTPI", values.NewFloat(2/goMath.SqrtPi))
 	environment.AddPredefinedConstant("M_E", values.NewFloat(goMath.E))
-	environment.AddPredefinedConstant("M_EULER", values.NewFloat(goMath.E))
+	environment.AddPredefinedConstant("M_EULER", values.NewFloat(0.57721566490153286061))
 	environment.AddPredefinedConstant("M_LN10", values.NewFloat(goMath.Ln10))
 	environment.AddPredefinedConstant("M_LN2", values.NewFloat(goMath.Ln2))
 	environment.AddPredefinedConstant("M_LNPI", values.NewFloat(goMath.Log(goMath.Pi)))
@@ -38,10 +77,15 @@ func Register(environment runtime.Environment) {
 	environment.AddPredefinedConstant("M_SQRT2", values.NewFloat(goMath.Sqrt2))
 	environment.AddPredefinedConstant("M_SQRT3", values.NewFloat(goMath.Sqrt(3)))
 	environment.AddPredefinedConstant("M_SQRTPI", values.NewFloat(goMath.SqrtPi))
-	environment.AddPredefinedConstant("PHP_ROUND_HALF_UP", values.NewInt(1))
-	environment.AddPredefinedConstant("PHP_ROUND_HALF_DOWN", values.NewInt(2))
-	environment.AddPredefinedConstant("PHP_ROUND_HALF_EVEN", values.NewInt(3))
-	environment.AddPredefinedConstant("PHP_ROUND_HALF_ODD", values.NewInt(4))
+	environment.AddPredefinedConstant("NAN", values.NewFloat(goMath.NaN()))
+	environment.AddPredefinedConstant("PHP_ROUND_HALF_UP", values.NewInt(RoundHalfUp))
+	environment.AddPredefinedConstant("PHP_ROUND_HALF_DOWN", values.NewInt(RoundHalfDown))
+	environment.AddPredefinedConstant("PHP_ROUND_HALF_EVEN", values.NewInt(RoundHalfEven))
+	environment.AddPredefinedConstant("PHP_ROUND_HALF_ODD", values.NewInt(RoundHalfOdd))
+}
+
+func RegisterClasses(interpreter runtime.Interpreter) {
+	registerRoundingMode(interpreter)
 }
 
 // -------------------------------------- abs -------------------------------------- MARK: abs
@@ -53,73 +97,71 @@ func nativeFn_abs(args []values.RuntimeValue, _ runtime.Context) (values.Runtime
 	}
 
 	// Spec: https://www.php.net/manual/en/function.abs.php
-
-	numType := args[0].GetType()
-
-	var numValue float64 = 0
-	if numType == values.FloatValue {
-		numValue = args[0].(*values.Float).Value
-	}
-	if numType == values.IntValue {
-		numValue = float64(args[0].(*values.Int).Value)
+	if args[0].GetType() == values.FloatValue {
+		return values.NewFloat(goMath.Abs(args[0].(*values.Float).Value)), nil
 	}
-	numValue = goMath.Abs(numValue)
-	if numType == values.FloatValue {
-		return values.NewFloat(numValue), nil
+	// The absolute value of PHP_INT_MIN is not an integer
+	num := args[0].(*values.Int).Value
+	if num < 0 {
+		return SubInt(0, num), nil
 	}
-	if numType == values.IntValue {
-		return values.NewInt(int64(numValue)), nil
-	}
-
-	return values.NewVoid(), phpError.NewError("Unsupported value type %s", numType)
+	return values.NewInt(num), nil
 }
 
 // -------------------------------------- acos -------------------------------------- MARK: acos
 
 func nativeFn_acos(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
-	args, err := funcParamValidator.NewValidator("acos").AddParam("$num", []string{"float"}, nil).Validate(args)
-	if err != nil {
-		return values.NewVoid(), err
-	}
-
 	// Spec: https://www.php.net/manual/en/function.acos.php
-	return values.NewFloat(goMath.Acos(args[0].(*values.Float).Value)), nil
+	return lib_floatFunction("acos", args, goMath.Acos)
 }
 
 // -------------------------------------- acosh -------------------------------------- MARK: acosh
 
 func nativeFn_acosh(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
-	args, err := funcParamValidator.NewValidator("acosh").AddParam("$num", []string{"float"}, nil).Validate(args)
-	if err != nil {
-		return values.NewVoid(), err
-	}
-
 	// Spec: https://www.php.net/manual/en/function.acosh.php
-	return values.NewFloat(goMath.Acosh(args[0].(*values.Float).Value)), nil
+	return lib_floatFunction("acosh", args, goMath.Acosh)
 }
 
 // -------------------------------------- asin -------------------------------------- MARK: asin
 
 func nativeFn_asin(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
-	args, err := funcParamValidator.NewValidator("asin").AddParam("$num", []string{"float"}, nil).Validate(args)
-	if err != nil {
-		return values.NewVoid(), err
-	}
-
-	// Spec: https://www.php.net/manual/en/function.sin.php
-	return values.NewFloat(goMath.Asin(args[0].(*values.Float).Value)), nil
+	// Spec: https://www.php.net/manual/en/function.asin.php
+	return lib_floatFunction("asin", args, goMath.Asin)
 }
 
 // -------------------------------------- asinh -------------------------------------- MARK: asinh
 
 func nativeFn_asinh(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
-	args, err := funcParamValidator.NewValidator("asinh").AddParam("$num", []string{"float"}, nil).Validate(args)
+	// Spec: https://www.php.net/manual/en/function.asinh.php
+	return lib_floatFunction("asinh", args, goMath.Asinh)
+}
+
+// -------------------------------------- atan -------------------------------------- MARK: atan
+
+func nativeFn_atan(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.atan.php
+	return lib_floatFunction("atan", args, goMath.Atan)
+}
+
+// -------------------------------------- atan2 -------------------------------------- MARK: atan2
+
+func nativeFn_atan2(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("atan2").
+		AddParam("$y", []string{"float"}, nil).AddParam("$x", []string{"float"}, nil).
+		Validate(args)
 	if err != nil {
 		return values.NewVoid(), err
 	}
 
-	// Spec: https://www.php.net/manual/en/function.sinh.php
-	return values.NewFloat(goMath.Asinh(args[0].(*values.Float).Value)), nil
+	// Spec: https://www.php.net/manual/en/function.atan2.php
+	return values.NewFloat(goMath.Atan2(args[0].(*values.Float).Value, args[1].(*values.Float).Value)), nil
+}
+
+// -------------------------------------- atanh -------------------------------------- MARK: atanh
+
+func nativeFn_atanh(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.atanh.php
+	return lib_floatFunction("atanh", args, goMath.Atanh)
 }
 
 // -------------------------------------- clamp -------------------------------------- MARK: clamp
@@ -139,16 +181,12 @@ func nativeFn_clamp(args []values.RuntimeValue, context runtime.Context) (values
 	maxValue := args[2]
 
 	// Spec: https://php.watch/versions/8.6/clamp#polyfill
-	// TODO clamp - is_nan
-	/*
-	   if (\is_float($min) && \is_nan($min)) {
-	     throw new \ValueError('clamp(): Argument #2 ($min) must not be NAN');
-	   }
-
-	   if (\is_float($max) && \is_nan($max)) {
-	     throw new \ValueError('clamp(): Argument #3 ($max) must not be NAN');
-	   }
-	*/
+	if minValue.GetType() == values.FloatValue && goMath.IsNaN(minValue.(*values.Float).Value) {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: clamp(): Argument #2 ($min) cannot be NAN")
+	}
+	if maxValue.GetType() == values.FloatValue && goMath.IsNaN(maxValue.(*values.Float).Value) {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: clamp(): Argument #3 ($max) cannot be NAN")
+	}
 
 	// if ($max < $min) throw new ValueError(...)
 	slot, err := variableHandling.CompareRelation(maxValue, "<", minValue, true)
@@ -180,6 +218,234 @@ func nativeFn_clamp(args []values.RuntimeValue, context runtime.Context) (values
 	return value, nil
 }
 
+// -------------------------------------- cos -------------------------------------- MARK: cos
+
+func nativeFn_cos(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.cos.php
+	return lib_floatFunction("cos", args, goMath.Cos)
+}
+
+// -------------------------------------- cosh -------------------------------------- MARK: cosh
+
+func nativeFn_cosh(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.cosh.php
+	return lib_floatFunction("cosh", args, goMath.Cosh)
+}
+
+// -------------------------------------- deg2rad -------------------------------------- MARK: deg2rad
+
+func nativeFn_deg2rad(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.deg2rad.php
+	return lib_floatFunction("deg2rad", args, func(num float64) float64 { return num / 180 * goMath.Pi })
+}
+
+// -------------------------------------- exp -------------------------------------- MARK: exp
+
+func nativeFn_exp(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.exp.php
+	return lib_floatFunction("exp", args, goMath.Exp)
+}
+
+// -------------------------------------- expm1 -------------------------------------- MARK: expm1
+
+func nativeFn_expm1(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.expm1.php
+	return lib_floatFunction("expm1", args, goMath.Expm1)
+}
+
+// -------------------------------------- fdiv -------------------------------------- MARK: fdiv
+
+func nativeFn_fdiv(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("fdiv").
+		AddParam("$num1", []string{"float"}, nil).AddParam("$num2", []string{"float"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.fdiv.php
+	return values.NewFloat(func(num1 float64, num2 float64) float64 { return num1 / num2 }(args[0].(*values.Float).Value, args[1].(*values.Float).Value)), nil
+}
+
+// -------------------------------------- fmod -------------------------------------- MARK: fmod
+
+func nativeFn_fmod(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("fmod").
+		AddParam("$num1", []string{"float"}, nil).AddParam("$num2", []string{"float"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.fmod.php
+	return values.NewFloat(goMath.Mod(args[0].(*values.Float).Value, args[1].(*values.Float).Value)), nil
+}
+
+// -------------------------------------- fpow -------------------------------------- MARK: fpow
+
+func nativeFn_fpow(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("fpow").
+		AddParam("$num", []string{"float"}, nil).AddParam("$exponent", []string{"float"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.fpow.php
+	return values.NewFloat(goMath.Pow(args[0].(*values.Float).Value, args[1].(*values.Float).Value)), nil
+}
+
+// -------------------------------------- hypot -------------------------------------- MARK: hypot
+
+func nativeFn_hypot(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("hypot").
+		AddParam("$x", []string{"float"}, nil).AddParam("$y", []string{"float"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.hypot.php
+	return values.NewFloat(goMath.Hypot(args[0].(*values.Float).Value, args[1].(*values.Float).Value)), nil
+}
+
+// -------------------------------------- intdiv -------------------------------------- MARK: intdiv
+
+func nativeFn_intdiv(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("intdiv").
+		AddParam("$num1", []string{"int"}, nil).AddParam("$num2", []string{"int"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.intdiv.php
+	num1 := args[0].(*values.Int).Value
+	num2 := args[1].(*values.Int).Value
+	if num2 == 0 {
+		return values.NewVoid(), phpError.NewError("Uncaught DivisionByZeroError: Division by zero")
+	}
+	if num1 == goMath.MinInt64 && num2 == -1 {
+		return values.NewVoid(), phpError.NewError("Uncaught ArithmeticError: Division of PHP_INT_MIN by -1 is not an integer")
+	}
+	return values.NewInt(num1 / num2), nil
+}
+
+// -------------------------------------- is_finite -------------------------------------- MARK: is_finite
+
+func nativeFn_is_finite(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.is-finite.php
+	return lib_floatPredicate("is_finite", args, func(num float64) bool { return !goMath.IsInf(num, 0) && !goMath.IsNaN(num) })
+}
+
+// -------------------------------------- is_infinite -------------------------------------- MARK: is_infinite
+
+func nativeFn_is_infinite(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.is-infinite.php
+	return lib_floatPredicate("is_infinite", args, func(num float64) bool { return goMath.IsInf(num, 0) })
+}
+
+// -------------------------------------- is_nan -------------------------------------- MARK: is_nan
+
+func nativeFn_is_nan(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.is-nan.php
+	return lib_floatPredicate("is_nan", args, goMath.IsNaN)
+}
+
+// -------------------------------------- log -------------------------------------- MARK: log
+
+func nativeFn_log(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("log").
+		AddParam("$num", []string{"float"}, nil).AddParam("$base", []string{"float"}, values.NewFloat(goMath.E)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.log.php
+	num := args[0].(*values.Float).Value
+	base := args[1].(*values.Float).Value
+	switch {
+	case base == 2:
+		return values.NewFloat(goMath.Log2(num)), nil
+	case base == 10:
+		return values.NewFloat(goMath.Log10(num)), nil
+	case base == 1:
+		return values.NewFloat(goMath.NaN()), nil
+	case base <= 0:
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: log(): Argument #2 ($base) must be greater than 0")
+	default:
+		return values.NewFloat(goMath.Log(num) / goMath.Log(base)), nil
+	}
+}
+
+// -------------------------------------- log10 -------------------------------------- MARK: log10
+
+func nativeFn_log10(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.log10.php
+	return lib_floatFunction("log10", args, goMath.Log10)
+}
+
+// -------------------------------------- log1p -------------------------------------- MARK: log1p
+
+func nativeFn_log1p(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.log1p.php
+	return lib_floatFunction("log1p", args, goMath.Log1p)
+}
+
+// -------------------------------------- max -------------------------------------- MARK: max
+
+func nativeFn_max(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.max.php
+	return lib_minMax("max", args, 1)
+}
+
+// -------------------------------------- min -------------------------------------- MARK: min
+
+func nativeFn_min(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.min.php
+	return lib_minMax("min", args, -1)
+}
+
+// Find the greatest (direction 1) or lowest (direction -1) value with the standard comparison rules.
+// If multiple values compare as equal, the first one is returned.
+func lib_minMax(functionName string, args []values.RuntimeValue, direction int64) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$value", []string{"mixed"}, nil).AddVariableLenParam("$values", []string{"mixed"}).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// If only one argument is passed, it has to be an array of values
+	candidates := []values.RuntimeValue{args[0]}
+	if others := args[1].(*values.Array); len(others.Keys) > 0 {
+		candidates = append(candidates, arrayValues(others)...)
+	} else {
+		if args[0].GetType() != values.ArrayValue {
+			return values.NewVoid(), phpError.NewError(
+				"Uncaught TypeError: %s(): Argument #1 ($value) must be of type array, %s given", functionName, values.ToPhpType(args[0]),
+			)
+		}
+		candidates = arrayValues(args[0].(*values.Array))
+		if len(candidates) == 0 {
+			return values.NewVoid(), phpError.NewError("Uncaught ValueError: %s(): Argument #1 ($value) must contain at least one element", functionName)
+		}
+	}
+
+	result := candidates[0]
+	for _, candidate := range candidates[1:] {
+		comparison, err := variableHandling.CompareRelation(candidate, "<=>", result, false)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		if comparison.Value.(*values.Int).Value*direction > 0 {
+			result = candidate
+		}
+	}
+	return result, nil
+}
+
 // -------------------------------------- pi -------------------------------------- MARK: pi
 
 func nativeFn_pi(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -192,40 +458,70 @@ func nativeFn_pi(args []values.RuntimeValue, context runtime.Context) (values.Ru
 	return context.Env.LookupConstant("M_PI")
 }
 
-// TODO atan
-// TODO atan2
-// TODO atanh
-// TODO base_convert
-// TODO bindec
-// TODO ceil
-// TODO cos
-// TODO cosh
-// TODO decbin
-// TODO dechex
-// TODO decoct
-// TODO deg2rad
-// TODO exp
-// TODO expm1
-// TODO fdiv
-// TODO floor
-// TODO fmod
-// TODO hexdec
-// TODO hypot
-// TODO intdiv
-// TODO is_finite
-// TODO is_infinite
-// TODO is_nan
-// TODO log
-// TODO log10
-// TODO log1p
-// TODO max
-// TODO min
-// TODO octdec
-// TODO pow
-// TODO rad2deg
-// TODO round
-// TODO sin
-// TODO sinh
-// TODO sqrt
-// TODO tan
-// TODO tanh
+// -------------------------------------- pow -------------------------------------- MARK: pow
+
+func nativeFn_pow(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("pow").
+		AddParam("$num", []string{"mixed"}, nil).AddParam("$exponent", []string{"mixed"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.pow.php
+	// Returns num raised to the power of exponent. If both arguments are non-negative integers and the result can be
+	// represented as an integer, the result will be returned with int type, otherwise it will be returned as a float.
+	num, numOk := toNumber(args[0])
+	exponent, exponentOk := toNumber(args[1])
+	if !numOk || !exponentOk {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught TypeError: Unsupported operand types: %s ** %s", values.ToPhpType(args[0]), values.ToPhpType(args[1]),
+		)
+	}
+	if num.GetType() == values.IntValue && exponent.GetType() == values.IntValue {
+		return PowInt(num.(*values.Int).Value, exponent.(*values.Int).Value), nil
+	}
+	return values.NewFloat(goMath.Pow(toFloat(num), toFloat(exponent))), nil
+}
+
+// -------------------------------------- rad2deg -------------------------------------- MARK: rad2deg
+
+func nativeFn_rad2deg(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.rad2deg.php
+	return lib_floatFunction("rad2deg", args, func(num float64) float64 { return num / goMath.Pi * 180 })
+}
+
+// -------------------------------------- sin -------------------------------------- MARK: sin
+
+func nativeFn_sin(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.sin.php
+	return lib_floatFunction("sin", args, goMath.Sin)
+}
+
+// -------------------------------------- sinh -------------------------------------- MARK: sinh
+
+func nativeFn_sinh(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.sinh.php
+	return lib_floatFunction("sinh", args, goMath.Sinh)
+}
+
+// -------------------------------------- sqrt -------------------------------------- MARK: sqrt
+
+func nativeFn_sqrt(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.sqrt.php
+	return lib_floatFunction("sqrt", args, goMath.Sqrt)
+}
+
+// -------------------------------------- tan -------------------------------------- MARK: tan
+
+func nativeFn_tan(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.tan.php
+	return lib_floatFunction("tan", args, goMath.Tan)
+}
+
+// -------------------------------------- tanh -------------------------------------- MARK: tanh
+
+func nativeFn_tanh(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.tanh.php
+	return lib_floatFunction("tanh", args, goMath.Tanh)
+}
@@ -0,0 +1,220 @@
+package math
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+	goMath "math"
+)
+
+// Rounding modes of round(). The first four are also available as PHP_ROUND_* constants.
+const (
+	RoundHalfUp       int64 = 1
+	RoundHalfDown     int64 = 2
+	RoundHalfEven     int64 = 3
+	RoundHalfOdd      int64 = 4
+	RoundCeiling      int64 = 5
+	RoundFloor        int64 = 6
+	RoundTowardZero   int64 = 7
+	RoundAwayFromZero int64 = 8
+)
+
+const roundingModeEnum = "RoundingMode"
+
+// Rounding modes of the cases of the RoundingMode enum
+var roundingModes = map[string]int64{
+	"HalfAwayFromZero": RoundHalfUp,
+	"HalfTowardsZero":  RoundHalfDown,
+	"HalfEven":         RoundHalfEven,
+	"HalfOdd":          RoundHalfOdd,
+	"TowardsZero":      RoundTowardZero,
+	"AwayFromZero":     RoundAwayFromZero,
+	"NegativeInfinity": RoundFloor,
+	"PositiveInfinity": RoundCeiling,
+}
+
+func registerRoundingMode(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/enum.roundingmode.php
+	runtime.NewNativeEnum(interpreter, roundingModeEnum).
+		AddEnumCase("HalfAwayFromZero").
+		AddEnumCase("HalfTowardsZero").
+		AddEnumCase("HalfEven").
+		AddEnumCase("HalfOdd").
+		AddEnumCase("TowardsZero").
+		AddEnumCase("AwayFromZero").
+		AddEnumCase("NegativeInfinity").
+		AddEnumCase("PositiveInfinity").
+		Register()
+}
+
+// -------------------------------------- ceil -------------------------------------- MARK: ceil
+
+func nativeFn_ceil(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("ceil").AddParam("$num", []string{"int", "float"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.ceil.php
+	return values.NewFloat(goMath.Ceil(toFloat(args[0]))), nil
+}
+
+// -------------------------------------- floor -------------------------------------- MARK: floor
+
+func nativeFn_floor(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("floor").AddParam("$num", []string{"int", "float"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.floor.php
+	return values.NewFloat(goMath.Floor(toFloat(args[0]))), nil
+}
+
+// -------------------------------------- round -------------------------------------- MARK: round
+
+func nativeFn_round(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("round").
+		AddParam("$num", []string{"int", "float"}, nil).
+		AddParam("$precision", []string{"int"}, values.NewInt(0)).
+		AddParam("$mode", []string{"int", "object"}, values.NewInt(RoundHalfUp)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.round.php
+	precision := args[1].(*values.Int).Value
+
+	mode, err := RoundingModeArg("round", 3, args[2])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Integers that don't need to be rounded
+	if args[0].GetType() == values.IntValue && precision >= 0 {
+		return values.NewFloat(float64(args[0].(*values.Int).Value)), nil
+	}
+
+	return values.NewFloat(Round(toFloat(args[0]), precision, mode)), nil
+}
+
+// Get the rounding mode of a RoundingMode|int argument (e.g. the mode of round)
+func RoundingModeArg(functionName string, argNum int, arg values.RuntimeValue) (int64, phpError.Error) {
+	var mode int64
+	if arg.GetType() == values.ObjectValue {
+		object := arg.(*values.Object)
+		if object.Class.GetQualifiedName() != roundingModeEnum {
+			return 0, phpError.NewError(
+				"Uncaught TypeError: %s(): Argument #%d ($mode) must be of type RoundingMode|int, %s given", functionName, argNum, object.Class.GetQualifiedName(),
+			)
+		}
+		name, _ := object.GetProperty("$name")
+		mode = roundingModes[name.(*values.Str).Value]
+	} else {
+		mode = arg.(*values.Int).Value
+	}
+	if mode < RoundHalfUp || mode > RoundAwayFromZero {
+		return 0, phpError.NewError("Uncaught ValueError: %s(): Argument #%d ($mode) must be a valid rounding mode (RoundingMode::*)", functionName, argNum)
+	}
+	return mode, nil
+}
+
+// Round the value to the given number of decimal digits (negative precision rounds to tens, hundreds, ...).
+// The algorithm is the one of PHP: pre-rounding errors like 0.285 * 100 = 28.499999999999996 are compensated by comparing
+// the value with the edge case, which is computed by dividing instead of multiplying.
+func Round(value float64, precision int64, mode int64) float64 {
+	if goMath.IsInf(value, 0) || goMath.IsNaN(value) || value == 0 {
+		return value
+	}
+
+	places := max(precision, goMath.MinInt32+1)
+	exponent := goMath.Pow(10, float64(abs(places)))
+
+	// Integral part of the scaled value
+	var integral float64
+	if value >= 0 {
+		integral = goMath.Floor(scale(value, exponent, places))
+	} else {
+		integral = goMath.Ceil(scale(value, exponent, places))
+	}
+	if unscale(integral, exponent, places) == value {
+		return value
+	}
+
+	// This value is beyond the precision of a float, so rounding it is pointless
+	if goMath.Abs(integral) >= 1e16 {
+		return value
+	}
+
+	integral = roundHelper(integral, value, exponent, places, mode)
+	result := unscale(integral, exponent, places)
+	if goMath.IsInf(result, 0) || goMath.IsNaN(result) {
+		return value
+	}
+	return result
+}
+
+func roundHelper(integral float64, value float64, exponent float64, places int64, mode int64) float64 {
+	valueAbs := goMath.Abs(value)
+	// The edge case between rounding down and up, e.g. 0.285 for 0.28 and 0.29
+	halfEdge := goMath.Abs(unscale(integral+goMath.Copysign(0.5, integral), exponent, places))
+	// The edge case where the value is exactly the integral part
+	zeroEdge := goMath.Abs(unscale(integral, exponent, places))
+	awayFromZero := integral + goMath.Copysign(1, integral)
+
+	switch mode {
+	case RoundHalfUp:
+		if valueAbs >= halfEdge {
+			return awayFromZero
+		}
+	case RoundHalfDown:
+		if valueAbs > halfEdge {
+			return awayFromZero
+		}
+	case RoundHalfEven, RoundHalfOdd:
+		if valueAbs > halfEdge {
+			return awayFromZero
+		}
+		isEven := goMath.Mod(integral, 2) == 0
+		if valueAbs == halfEdge && isEven != (mode == RoundHalfEven) {
+			return awayFromZero
+		}
+	case RoundCeiling:
+		if value > 0 && valueAbs > zeroEdge {
+			return integral + 1
+		}
+	case RoundFloor:
+		if value < 0 && valueAbs > zeroEdge {
+			return integral - 1
+		}
+	case RoundAwayFromZero:
+		if valueAbs > zeroEdge {
+			return awayFromZero
+		}
+	}
+	return integral
+}
+
+// Scale the value by the exponent: positive places multiply, negative places divide
+func scale(value float64, exponent float64, places int64) float64 {
+	if places > 0 {
+		return value * exponent
+	}
+	return value / exponent
+}
+
+func unscale(value float64, exponent float64, places int64) float64 {
+	if places > 0 {
+		return value / exponent
+	}
+	return value * exponent
+}
+
+func abs(value int64) int64 {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -7,7 +7,9 @@ import (
 	"QIQ/cmd/qiq/phpError"
 	"QIQ/cmd/qiq/runtime"
 	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/filesystem"
 	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/stream"
 	"QIQ/cmd/qiq/runtime/values"
 	"fmt"
 	goOs "os"
@@ -18,6 +20,12 @@ import (
 
 func Register(environment runtime.Environment) {
 	// Category: Options/Info Functions
+	environment.AddNativeFunction("gc_collect_cycles", nativeFn_gc_collect_cycles)
+	environment.AddNativeFunction("gc_disable", nativeFn_gc_disable)
+	environment.AddNativeFunction("gc_enable", nativeFn_gc_enable)
+	environment.AddNativeFunction("gc_enabled", nativeFn_gc_enabled)
+	environment.AddNativeFunction("gc_mem_caches", nativeFn_gc_mem_caches)
+	environment.AddNativeFunction("gc_status", nativeFn_gc_status)
 	environment.AddNativeFunction("getenv", nativeFn_getenv)
 	environment.AddNativeFunction("getmygid", nativeFn_getmygid)
 	environment.AddNativeFunction("getmypid", nativeFn_getmypid)
@@ -26,6 +34,7 @@ func Register(environment runtime.Environment) {
 	environment.AddNativeFunction("ini_set", nativeFn_ini_set)
 	environment.AddNativeFunction("phpinfo", nativeFn_phpinfo)
 	environment.AddNativeFunction("phpversion", nativeFn_phpversion)
+	environment.AddNativeFunction("sys_get_temp_dir", nativeFn_sys_get_temp_dir)
 	environment.AddNativeFunction("zend_thread_id", nativeFn_zend_thread_id)
 	environment.AddNativeFunction("zend_version", nativeFn_zend_version)
 
@@ -37,6 +46,111 @@ func Register(environment runtime.Environment) {
 	environment.AddPredefinedConstant("INI_ALL", values.NewInt(int64(ini.INI_ALL)))
 }
 
+// -------------------------------------- gc_collect_cycles -------------------------------------- MARK: gc_collect_cycles
+
+func nativeFn_gc_collect_cycles(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.gc-collect-cycles.php
+
+	// Forces collection of any existing garbage cycles. Returns number of collected cycles.
+
+	_, err := funcParamValidator.NewValidator("gc_collect_cycles").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(context.Interpreter.CollectCycles(context.Env)), nil
+}
+
+// -------------------------------------- gc_disable -------------------------------------- MARK: gc_disable
+
+func nativeFn_gc_disable(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.gc-disable.php
+
+	// Deactivates the circular reference collector, setting zend.enable_gc to 0.
+
+	_, err := funcParamValidator.NewValidator("gc_disable").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewVoid(), context.Interpreter.GetIni().Set("zend.enable_gc", "0", ini.INI_USER)
+}
+
+// -------------------------------------- gc_enable -------------------------------------- MARK: gc_enable
+
+func nativeFn_gc_enable(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.gc-enable.php
+
+	// Activates the circular reference collector, setting zend.enable_gc to 1.
+
+	_, err := funcParamValidator.NewValidator("gc_enable").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewVoid(), context.Interpreter.GetIni().Set("zend.enable_gc", "1", ini.INI_USER)
+}
+
+// -------------------------------------- gc_enabled -------------------------------------- MARK: gc_enabled
+
+func nativeFn_gc_enabled(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.gc-enabled.php
+
+	// Returns status of the circular reference collector.
+
+	_, err := funcParamValidator.NewValidator("gc_enabled").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(context.Interpreter.GetIni().GetBool("zend.enable_gc")), nil
+}
+
+// -------------------------------------- gc_mem_caches -------------------------------------- MARK: gc_mem_caches
+
+func nativeFn_gc_mem_caches(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.gc-mem-caches.php
+
+	// Reclaims memory used by the Zend Engine memory manager. Returns the number of bytes freed.
+
+	_, err := funcParamValidator.NewValidator("gc_mem_caches").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// The memory is managed by the Go runtime
+	return values.NewInt(0), nil
+}
+
+// -------------------------------------- gc_status -------------------------------------- MARK: gc_status
+
+func nativeFn_gc_status(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.gc-status.php
+
+	// Gets information about the garbage collector.
+
+	_, err := funcParamValidator.NewValidator("gc_status").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	status := context.Interpreter.GetGcStatus()
+	result := values.NewArray()
+	result.SetElement(values.NewStr("runs"), values.NewInt(status.Runs))
+	result.SetElement(values.NewStr("collected"), values.NewInt(status.Collected))
+	result.SetElement(values.NewStr("threshold"), values.NewInt(status.Threshold))
+	result.SetElement(values.NewStr("roots"), values.NewInt(status.Roots))
+	result.SetElement(values.NewStr("running"), values.NewBool(status.Running))
+	result.SetElement(values.NewStr("protected"), values.NewBool(false))
+	result.SetElement(values.NewStr("full"), values.NewBool(false))
+	result.SetElement(values.NewStr("buffer_size"), values.NewInt(status.BufferSize))
+	result.SetElement(values.NewStr("application_time"), values.NewFloat(status.ApplicationTime))
+	result.SetElement(values.NewStr("collector_time"), values.NewFloat(status.CollectorTime))
+	result.SetElement(values.NewStr("destructor_time"), values.NewFloat(status.DestructorTime))
+	result.SetElement(values.NewStr("free_time"), values.NewFloat(0))
+	return result, nil
+}
+
 // -------------------------------------- getenv -------------------------------------- MARK: getenv
 
 func nativeFn_getenv(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -158,6 +272,10 @@ func nativeFn_ini_set(args []values.RuntimeValue, context runtime.Context) (valu
 	if err != nil {
 		return values.NewBool(false), nil
 	}
+	// open_basedir can only be tightened at runtime
+	if option == "open_basedir" && !stream.IsOpenBasedirTightened(value, context) {
+		return values.NewBool(false), nil
+	}
 	err = context.Interpreter.GetIni().Set(option, value, ini.INI_USER)
 	if err != nil {
 		return values.NewBool(false), nil
@@ -200,6 +318,19 @@ func nativeFn_phpversion(args []values.RuntimeValue, _ runtime.Context) (values.
 	return values.NewStr(config.Version), nil
 }
 
+// -------------------------------------- sys_get_temp_dir -------------------------------------- MARK: sys_get_temp_dir
+
+func nativeFn_sys_get_temp_dir(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.sys-get-temp-dir.php
+
+	_, err := funcParamValidator.NewValidator("sys_get_temp_dir").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(filesystem.TempDir(context)), nil
+}
+
 // -------------------------------------- zend_thread_id -------------------------------------- MARK: zend_thread_id
 
 func nativeFn_zend_thread_id(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -244,12 +375,6 @@ func nativeFn_zend_version(args []values.RuntimeValue, _ runtime.Context) (value
 // TODO cli_set_process_title
 // TODO dl
 // TODO extension_loaded
-// TODO gc_collect_cycles
-// TODO gc_disable
-// TODO gc_enable
-// TODO gc_enabled
-// TODO gc_mem_caches
-// TODO gc_status
 // TODO get_cfg_var
 // TODO get_current_user
 // TODO get_defined_constants
@@ -278,5 +403,4 @@ func nativeFn_zend_version(args []values.RuntimeValue, _ runtime.Context) (value
 // TODO putenv
 // TODO set_include_path
 // TODO set_time_limit
-// TODO sys_get_temp_dir
 // TODO version_compare
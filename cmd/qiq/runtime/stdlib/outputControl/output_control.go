@@ -4,11 +4,13 @@ import (
 	"QIQ/cmd/qiq/phpError"
 	"QIQ/cmd/qiq/runtime"
 	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/outputBuffer"
 	"QIQ/cmd/qiq/runtime/values"
 )
 
 func Register(environment runtime.Environment) {
 	// Category: Output Control Functions
+	environment.AddNativeFunction("flush", nativeFn_flush)
 	environment.AddNativeFunction("ob_clean", nativeFn_ob_clean)
 	environment.AddNativeFunction("ob_end_clean", nativeFn_ob_end_clean)
 	environment.AddNativeFunction("ob_end_flush", nativeFn_ob_end_flush)
@@ -16,8 +18,46 @@ func Register(environment runtime.Environment) {
 	environment.AddNativeFunction("ob_get_clean", nativeFn_ob_get_clean)
 	environment.AddNativeFunction("ob_get_contents", nativeFn_ob_get_contents)
 	environment.AddNativeFunction("ob_get_flush", nativeFn_ob_get_flush)
+	environment.AddNativeFunction("ob_get_length", nativeFn_ob_get_length)
 	environment.AddNativeFunction("ob_get_level", nativeFn_ob_get_level)
+	environment.AddNativeFunction("ob_get_status", nativeFn_ob_get_status)
+	environment.AddNativeFunction("ob_implicit_flush", nativeFn_ob_implicit_flush)
+	environment.AddNativeFunction("ob_list_handlers", nativeFn_ob_list_handlers)
 	environment.AddNativeFunction("ob_start", nativeFn_ob_start)
+
+	// Const Category: Output Control Constants
+	// Spec: https://www.php.net/manual/en/outcontrol.constants.php
+	environment.AddPredefinedConstant("PHP_OUTPUT_HANDLER_START", values.NewInt(outputBuffer.PHP_OUTPUT_HANDLER_START))
+	environment.AddPredefinedConstant("PHP_OUTPUT_HANDLER_WRITE", values.NewInt(outputBuffer.PHP_OUTPUT_HANDLER_WRITE))
+	environment.AddPredefinedConstant("PHP_OUTPUT_HANDLER_FLUSH", values.NewInt(outputBuffer.PHP_OUTPUT_HANDLER_FLUSH))
+	environment.AddPredefinedConstant("PHP_OUTPUT_HANDLER_CLEAN", values.NewInt(outputBuffer.PHP_OUTPUT_HANDLER_CLEAN))
+	environment.AddPredefinedConstant("PHP_OUTPUT_HANDLER_FINAL", values.NewInt(outputBuffer.PHP_OUTPUT_HANDLER_FINAL))
+	environment.AddPredefinedConstant("PHP_OUTPUT_HANDLER_CONT", values.NewInt(outputBuffer.PHP_OUTPUT_HANDLER_CONT))
+	environment.AddPredefinedConstant("PHP_OUTPUT_HANDLER_END", values.NewInt(outputBuffer.PHP_OUTPUT_HANDLER_END))
+	environment.AddPredefinedConstant("PHP_OUTPUT_HANDLER_CLEANABLE", values.NewInt(outputBuffer.PHP_OUTPUT_HANDLER_CLEANABLE))
+	environment.AddPredefinedConstant("PHP_OUTPUT_HANDLER_FLUSHABLE", values.NewInt(outputBuffer.PHP_OUTPUT_HANDLER_FLUSHABLE))
+	environment.AddPredefinedConstant("PHP_OUTPUT_HANDLER_REMOVABLE", values.NewInt(outputBuffer.PHP_OUTPUT_HANDLER_REMOVABLE))
+	environment.AddPredefinedConstant("PHP_OUTPUT_HANDLER_STDFLAGS", values.NewInt(outputBuffer.PHP_OUTPUT_HANDLER_STDFLAGS))
+	environment.AddPredefinedConstant("PHP_OUTPUT_HANDLER_STARTED", values.NewInt(outputBuffer.PHP_OUTPUT_HANDLER_STARTED))
+	environment.AddPredefinedConstant("PHP_OUTPUT_HANDLER_DISABLED", values.NewInt(outputBuffer.PHP_OUTPUT_HANDLER_DISABLED))
+	environment.AddPredefinedConstant("PHP_OUTPUT_HANDLER_PROCESSED", values.NewInt(outputBuffer.PHP_OUTPUT_HANDLER_PROCESSED))
+}
+
+// -------------------------------------- flush -------------------------------------- MARK: flush
+
+func nativeFn_flush(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.flush.php
+
+	_, err := funcParamValidator.NewValidator("flush").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Flushes the system write buffers of PHP. This attempts to push current output all the way to the browser.
+	// flush() may not be able to override the buffering scheme of the web server and it has no effect on
+	// the output buffers started with ob_start(). The output of QIQ is sent when the script has finished.
+
+	return values.NewVoid(), nil
 }
 
 // -------------------------------------- ob_clean -------------------------------------- MARK: ob_clean
@@ -30,17 +70,28 @@ func nativeFn_ob_clean(args []values.RuntimeValue, context runtime.Context) (val
 		return values.NewVoid(), err
 	}
 
-	// TODO Call output handler
-	// Spec: https://www.php.net/manual/en/function.ob-clean.php
 	// This function calls the output handler (with the PHP_OUTPUT_HANDLER_CLEAN flag),
 	// discards it's return value and cleans (erases) the contents of the active output buffer.
 
-	if context.Interpreter.GetOutputBufferStack().Len() == 0 {
+	stack := context.Interpreter.GetOutputBufferStack()
+	if stack.Len() == 0 {
 		context.Interpreter.PrintError(phpError.NewNotice("ob_clean(): Failed to delete buffer. No buffer to delete in %s", context.Stmt.GetPosString()))
 		return values.NewBool(false), nil
 	}
 
-	context.Interpreter.GetOutputBufferStack().GetLast().Content = ""
+	if !stack.GetLast().IsCleanable() {
+		context.Interpreter.PrintError(phpError.NewNotice(
+			"ob_clean(): Failed to delete buffer of %s (%d) in %s", stack.GetLast().Name, stack.Len()-1, context.Stmt.GetPosString(),
+		))
+		return values.NewBool(false), nil
+	}
+
+	if err := checkHandlerNotRunning("ob_clean", context); err != nil {
+		return values.NewVoid(), err
+	}
+	if _, err := processBuffer(stack.Len()-1, outputBuffer.PHP_OUTPUT_HANDLER_CLEAN, context); err != nil {
+		return values.NewVoid(), err
+	}
 	return values.NewBool(true), nil
 }
 
@@ -54,17 +105,28 @@ func nativeFn_ob_end_clean(args []values.RuntimeValue, context runtime.Context)
 		return values.NewVoid(), err
 	}
 
-	// TODO Call output handler
-	// Spec: https://www.php.net/manual/en/function.ob-end-clean.php
 	// This function calls the output handler (with the PHP_OUTPUT_HANDLER_CLEAN and PHP_OUTPUT_HANDLER_FINAL flags),
 	// discards it's return value, discards the contents of the active output buffer and turns off the active output buffer.
 
-	if context.Interpreter.GetOutputBufferStack().Len() == 0 {
+	stack := context.Interpreter.GetOutputBufferStack()
+	if stack.Len() == 0 {
 		context.Interpreter.PrintError(phpError.NewNotice("ob_end_clean(): Failed to delete buffer. No buffer to delete in %s", context.Stmt.GetPosString()))
 		return values.NewBool(false), nil
 	}
 
-	context.Interpreter.GetOutputBufferStack().Pop()
+	if !stack.GetLast().IsRemovable() {
+		context.Interpreter.PrintError(phpError.NewNotice(
+			"ob_end_clean(): Failed to discard buffer of %s (%d) in %s", stack.GetLast().Name, stack.Len()-1, context.Stmt.GetPosString(),
+		))
+		return values.NewBool(false), nil
+	}
+
+	if err := checkHandlerNotRunning("ob_end_clean", context); err != nil {
+		return values.NewVoid(), err
+	}
+	if err := endBuffer(outputBuffer.PHP_OUTPUT_HANDLER_CLEAN|outputBuffer.PHP_OUTPUT_HANDLER_FINAL, false, context); err != nil {
+		return values.NewVoid(), err
+	}
 	return values.NewBool(true), nil
 }
 
@@ -78,22 +140,28 @@ func nativeFn_ob_end_flush(args []values.RuntimeValue, context runtime.Context)
 		return values.NewVoid(), err
 	}
 
-	return ObEndFlush(context)
-}
-
-func ObEndFlush(context runtime.Context) (values.RuntimeValue, phpError.Error) {
-	// TODO Call output handler
-	// Spec: https://www.php.net/manual/en/function.ob-end-flush.php
 	// This function calls the output handler (with the PHP_OUTPUT_HANDLER_FINAL flag),
 	// flushes (sends) it's return value, discards the contents of the active output buffer and turns off the active output buffer.
 
-	if context.Interpreter.GetOutputBufferStack().Len() == 0 {
+	stack := context.Interpreter.GetOutputBufferStack()
+	if stack.Len() == 0 {
 		context.Interpreter.PrintError(phpError.NewNotice("ob_end_flush(): Failed to delete and flush buffer. No buffer to delete or flush in %s", context.Stmt.GetPosString()))
 		return values.NewBool(false), nil
 	}
 
-	nativeFn_ob_flush([]values.RuntimeValue{}, context)
-	context.Interpreter.GetOutputBufferStack().Pop()
+	if !stack.GetLast().IsRemovable() {
+		context.Interpreter.PrintError(phpError.NewNotice(
+			"ob_end_flush(): Failed to send buffer of %s (%d) in %s", stack.GetLast().Name, stack.Len()-1, context.Stmt.GetPosString(),
+		))
+		return values.NewBool(false), nil
+	}
+
+	if err := checkHandlerNotRunning("ob_end_flush", context); err != nil {
+		return values.NewVoid(), err
+	}
+	if err := endBuffer(outputBuffer.PHP_OUTPUT_HANDLER_FINAL, true, context); err != nil {
+		return values.NewVoid(), err
+	}
 	return values.NewBool(true), nil
 }
 
@@ -107,23 +175,28 @@ func nativeFn_ob_flush(args []values.RuntimeValue, context runtime.Context) (val
 		return values.NewVoid(), err
 	}
 
-	// TODO Call output handler
-	// Spec: https://www.php.net/manual/en/function.ob-flush.php
 	// This function calls the output handler (with the PHP_OUTPUT_HANDLER_FLUSH flag),
-	// discards it's return value and flushs (erases) the contents of the active output buffer.
+	// flushes (sends) its return value and discards the contents of the active output buffer.
 
-	if context.Interpreter.GetOutputBufferStack().Len() == 0 {
+	stack := context.Interpreter.GetOutputBufferStack()
+	if stack.Len() == 0 {
 		context.Interpreter.PrintError(phpError.NewNotice("ob_flush(): Failed to flush buffer. No buffer to flush in %s", context.Stmt.GetPosString()))
 		return values.NewBool(false), nil
 	}
 
-	if context.Interpreter.GetOutputBufferStack().Len() == 1 {
-		context.Interpreter.WriteResult(context.Interpreter.GetOutputBufferStack().GetLast().Content)
-	} else {
-		context.Interpreter.GetOutputBufferStack().Get(context.Interpreter.GetOutputBufferStack().Len() - 2).Content += context.Interpreter.GetOutputBufferStack().GetLast().Content
+	if !stack.GetLast().IsFlushable() {
+		context.Interpreter.PrintError(phpError.NewNotice(
+			"ob_flush(): Failed to flush buffer of %s (%d) in %s", stack.GetLast().Name, stack.Len()-1, context.Stmt.GetPosString(),
+		))
+		return values.NewBool(false), nil
 	}
 
-	nativeFn_ob_clean(args, context)
+	if err := checkHandlerNotRunning("ob_flush", context); err != nil {
+		return values.NewVoid(), err
+	}
+	if err := flushLevel(stack.Len()-1, outputBuffer.PHP_OUTPUT_HANDLER_FLUSH, context); err != nil {
+		return values.NewVoid(), err
+	}
 	return values.NewBool(true), nil
 }
 
@@ -137,17 +210,31 @@ func nativeFn_ob_get_clean(args []values.RuntimeValue, context runtime.Context)
 		return values.NewVoid(), err
 	}
 
-	// TODO Call output handler
-	// Spec: https://www.php.net/manual/en/function.ob-get-clean.php
 	// This function calls the output handler (with the PHP_OUTPUT_HANDLER_CLEAN and PHP_OUTPUT_HANDLER_FINAL flags),
 	// discards it's return value, returns the contents of the active output buffer and turns off the active output buffer.
 
-	if context.Interpreter.GetOutputBufferStack().Len() == 0 {
+	stack := context.Interpreter.GetOutputBufferStack()
+	if stack.Len() == 0 {
 		return values.NewBool(false), nil
 	}
 
-	content := context.Interpreter.GetOutputBufferStack().GetLast().Content
-	context.Interpreter.GetOutputBufferStack().Pop()
+	content := stack.GetLast().Content
+	if !stack.GetLast().IsRemovable() {
+		context.Interpreter.PrintError(phpError.NewNotice(
+			"ob_get_clean(): Failed to discard buffer of %s (%d) in %s", stack.GetLast().Name, stack.Len()-1, context.Stmt.GetPosString(),
+		))
+		context.Interpreter.PrintError(phpError.NewNotice(
+			"ob_get_clean(): Failed to delete buffer of %s (%d) in %s", stack.GetLast().Name, stack.Len()-1, context.Stmt.GetPosString(),
+		))
+		return values.NewStr(content), nil
+	}
+
+	if err := checkHandlerNotRunning("ob_get_clean", context); err != nil {
+		return values.NewVoid(), err
+	}
+	if err := endBuffer(outputBuffer.PHP_OUTPUT_HANDLER_CLEAN|outputBuffer.PHP_OUTPUT_HANDLER_FINAL, false, context); err != nil {
+		return values.NewVoid(), err
+	}
 	return values.NewStr(content), nil
 }
 
@@ -178,21 +265,53 @@ func nativeFn_ob_get_flush(args []values.RuntimeValue, context runtime.Context)
 		return values.NewVoid(), err
 	}
 
-	// TODO Call output handler
-	// Spec: https://www.php.net/manual/en/function.ob-get-flush.php
 	// This function calls the output handler (with the PHP_OUTPUT_HANDLER_FINAL flag),
 	// flushes (sends) it's return value, returns the contents of the active output buffer and turns off the active output buffer.
 
-	if context.Interpreter.GetOutputBufferStack().Len() == 0 {
+	stack := context.Interpreter.GetOutputBufferStack()
+	if stack.Len() == 0 {
 		context.Interpreter.PrintError(phpError.NewNotice("ob_get_flush(): Failed to delete buffer. No buffer to delete in %s", context.Stmt.GetPosString()))
 		return values.NewBool(false), nil
 	}
 
-	content := context.Interpreter.GetOutputBufferStack().GetLast().Content
-	nativeFn_ob_end_flush(args, context)
+	content := stack.GetLast().Content
+	if !stack.GetLast().IsRemovable() {
+		context.Interpreter.PrintError(phpError.NewNotice(
+			"ob_get_flush(): Failed to send buffer of %s (%d) in %s", stack.GetLast().Name, stack.Len()-1, context.Stmt.GetPosString(),
+		))
+		context.Interpreter.PrintError(phpError.NewNotice(
+			"ob_get_flush(): Failed to delete buffer of %s (%d) in %s", stack.GetLast().Name, stack.Len()-1, context.Stmt.GetPosString(),
+		))
+		return values.NewStr(content), nil
+	}
+
+	if err := checkHandlerNotRunning("ob_get_flush", context); err != nil {
+		return values.NewVoid(), err
+	}
+	if err := endBuffer(outputBuffer.PHP_OUTPUT_HANDLER_FINAL, true, context); err != nil {
+		return values.NewVoid(), err
+	}
 	return values.NewStr(content), nil
 }
 
+// -------------------------------------- ob_get_length -------------------------------------- MARK: ob_get_length
+
+func nativeFn_ob_get_length(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.ob-get-length.php
+
+	_, err := funcParamValidator.NewValidator("ob_get_length").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Returns the length of the output buffer contents, in bytes, or false if no buffering is active.
+	if context.Interpreter.GetOutputBufferStack().Len() == 0 {
+		return values.NewBool(false), nil
+	}
+
+	return values.NewInt(int64(len(context.Interpreter.GetOutputBufferStack().GetLast().Content))), nil
+}
+
 // -------------------------------------- ob_get_level -------------------------------------- MARK: ob_get_level
 
 func nativeFn_ob_get_level(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -206,28 +325,150 @@ func nativeFn_ob_get_level(args []values.RuntimeValue, context runtime.Context)
 	return values.NewInt(int64(context.Interpreter.GetOutputBufferStack().Len())), nil
 }
 
+// -------------------------------------- ob_get_status -------------------------------------- MARK: ob_get_status
+
+func nativeFn_ob_get_status(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.ob-get-status.php
+
+	args, err := funcParamValidator.NewValidator("ob_get_status").
+		AddParam("$full_status", []string{"bool"}, values.NewBool(false)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// If called without the full_status parameter or with full_status = false a simple array
+	// with the status of the top level output buffer is returned.
+	// If called with full_status = true an array with one element for each active output buffer level is returned.
+
+	stack := context.Interpreter.GetOutputBufferStack()
+	if !args[0].(*values.Bool).Value {
+		if stack.Len() == 0 {
+			return values.NewArray(), nil
+		}
+		return bufferStatus(stack.GetLast(), stack.Len()-1), nil
+	}
+
+	result := values.NewArray()
+	for level := 0; level < stack.Len(); level++ {
+		if err := result.SetElement(nil, bufferStatus(stack.Get(level), level)); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+func bufferStatus(buffer *outputBuffer.Buffer, level int) *values.Array {
+	// Output handlers are either internal (0) or user-defined (1)
+	handlerType := int64(0)
+	if buffer.Handler != nil {
+		handlerType = 1
+	}
+
+	bufferSize := int64(16384)
+	if buffer.ChunkSize > 1 {
+		// The buffer size is aligned to 4096 bytes
+		bufferSize = buffer.ChunkSize + 4096 - buffer.ChunkSize%4096
+	}
+
+	status := values.NewArray()
+	status.SetElement(values.NewStr("name"), values.NewStr(buffer.Name))
+	status.SetElement(values.NewStr("type"), values.NewInt(handlerType))
+	status.SetElement(values.NewStr("flags"), values.NewInt(buffer.Flags|handlerType))
+	status.SetElement(values.NewStr("level"), values.NewInt(int64(level)))
+	status.SetElement(values.NewStr("chunk_size"), values.NewInt(buffer.ChunkSize))
+	status.SetElement(values.NewStr("buffer_size"), values.NewInt(bufferSize))
+	status.SetElement(values.NewStr("buffer_used"), values.NewInt(int64(len(buffer.Content))))
+	return status
+}
+
+// -------------------------------------- ob_implicit_flush -------------------------------------- MARK: ob_implicit_flush
+
+func nativeFn_ob_implicit_flush(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.ob-implicit-flush.php
+
+	args, err := funcParamValidator.NewValidator("ob_implicit_flush").
+		AddParam("$enable", []string{"bool"}, values.NewBool(true)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Implicit flushing will result in a flush operation after every output call,
+	// so that explicit calls to flush() will no longer be needed.
+	context.Interpreter.GetOutputBufferStack().ImplicitFlush = args[0].(*values.Bool).Value
+
+	return values.NewVoid(), nil
+}
+
+// -------------------------------------- ob_list_handlers -------------------------------------- MARK: ob_list_handlers
+
+func nativeFn_ob_list_handlers(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.ob-list-handlers.php
+
+	_, err := funcParamValidator.NewValidator("ob_list_handlers").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// This will return an array with the output handlers in use (if any).
+	stack := context.Interpreter.GetOutputBufferStack()
+	result := values.NewArray()
+	for level := 0; level < stack.Len(); level++ {
+		if err := result.SetElement(nil, values.NewStr(stack.Get(level).Name)); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
 // -------------------------------------- ob_start -------------------------------------- MARK: ob_start
 
 func nativeFn_ob_start(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
 	// Spec: https://www.php.net/manual/en/function.ob-start
 
-	_, err := funcParamValidator.NewValidator("ob_start").Validate(args)
+	args, err := funcParamValidator.NewValidator("ob_start").
+		AddParam("$callback", []string{"mixed"}, values.NewNull()).
+		AddParam("$chunk_size", []string{"int"}, values.NewInt(0)).
+		AddParam("$flags", []string{"int"}, values.NewInt(outputBuffer.PHP_OUTPUT_HANDLER_STDFLAGS)).
+		Validate(args)
 	if err != nil {
 		return values.NewVoid(), err
 	}
 
-	// TODO ob_start parameters
-	//  ob_start(?callable $callback = null, int $chunk_size = 0, int $flags = PHP_OUTPUT_HANDLER_STDFLAGS): bool
+	if err := checkHandlerNotRunning("ob_start", context); err != nil {
+		return values.NewVoid(), err
+	}
+
+	// An optional callback function may be specified.
+	// The function will be called when the output buffer is flushed (sent), cleaned, or when the output buffer is flushed at the end of the script.
+	var handler values.RuntimeValue = nil
+	name := outputBuffer.DefaultHandlerName
+	if args[0].GetType() != values.NullValue {
+		if _, reason, ok := context.Interpreter.IsCallable(args[0], false, context.Env); !ok {
+			context.Interpreter.PrintError(phpError.NewWarning("ob_start(): %s in %s", reason, context.Stmt.GetPosString()))
+			context.Interpreter.PrintError(phpError.NewNotice("ob_start(): Failed to create buffer in %s", context.Stmt.GetPosString()))
+			return values.NewBool(false), nil
+		}
+		handler = args[0]
+		name = handlerName(handler)
+	}
+
+	// If the optional parameter chunk_size is passed, the buffer will be flushed after any output call
+	// which causes the buffer's length to equal or exceed chunk_size.
+	// The default value 0 means that the output function will only be called when the output buffer is closed.
+	chunkSize := args[1].(*values.Int).Value
+	if chunkSize < 0 {
+		chunkSize = 0
+	}
+
+	// The flags parameter is a bitmask that controls the operations that can be performed on the output buffer.
+	flags := args[2].(*values.Int).Value & outputBuffer.PHP_OUTPUT_HANDLER_STDFLAGS
 
-	context.Interpreter.GetOutputBufferStack().Push()
+	context.Interpreter.GetOutputBufferStack().Push(outputBuffer.NewBuffer(handler, name, chunkSize, flags))
 
 	return values.NewBool(true), nil
 }
 
-// TODO flush
-// TODO ob_​get_​length
-// TODO ob_​get_​status
-// TODO ob_​implicit_​flush
-// TODO ob_​list_​handlers
 // TODO output_​add_​rewrite_​var
 // TODO output_​reset_​rewrite_​vars
@@ -0,0 +1,139 @@
+package outputControl
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/outputBuffer"
+	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/values"
+)
+
+// Write the output to the active output buffer or send it if there is no active output buffer
+func WriteOutput(str string, context runtime.Context) phpError.Error {
+	stack := context.Interpreter.GetOutputBufferStack()
+	// Output of an output handler is discarded
+	if stack.IsHandlerRunning {
+		return nil
+	}
+	return writeToLevel(str, stack.Len()-1, context)
+}
+
+// Pass the contents of all output buffers through their output handlers and turn off all output buffers.
+// Buffers are removed even if they are not removable.
+func EndAll(context runtime.Context) phpError.Error {
+	stack := context.Interpreter.GetOutputBufferStack()
+	for stack.Len() > 0 {
+		if err := endBuffer(outputBuffer.PHP_OUTPUT_HANDLER_FINAL, true, context); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Output buffers can not be used while an output handler is running
+func checkHandlerNotRunning(functionName string, context runtime.Context) phpError.Error {
+	if context.Interpreter.GetOutputBufferStack().IsHandlerRunning {
+		return phpError.NewError("%s(): Cannot use output buffering in output buffering display handlers%s", functionName, context.InPosition())
+	}
+	return nil
+}
+
+// Append the output to the buffer with the given level (-1 = send output)
+func writeToLevel(str string, level int, context runtime.Context) phpError.Error {
+	if level < 0 {
+		context.Interpreter.WriteResult(str)
+		return nil
+	}
+
+	buffer := context.Interpreter.GetOutputBufferStack().Get(level)
+	buffer.Content += str
+	if buffer.ChunkSize > 0 && int64(len(buffer.Content)) >= buffer.ChunkSize {
+		return flushLevel(level, outputBuffer.PHP_OUTPUT_HANDLER_WRITE, context)
+	}
+	return nil
+}
+
+// Pass the content of the buffer through its output handler and send the result to the next lower level
+func flushLevel(level int, phase int64, context runtime.Context) phpError.Error {
+	output, err := processBuffer(level, phase, context)
+	if err != nil {
+		return err
+	}
+	return writeToLevel(output, level-1, context)
+}
+
+// Pass the content of the active buffer through its output handler, turn off the buffer
+// and send the result to the next lower level if it is not a clean operation
+func endBuffer(phase int64, send bool, context runtime.Context) phpError.Error {
+	stack := context.Interpreter.GetOutputBufferStack()
+	level := stack.Len() - 1
+	output, err := processBuffer(level, phase, context)
+	stack.Pop()
+	if err != nil || !send {
+		return err
+	}
+	return writeToLevel(output, level-1, context)
+}
+
+// Pass the content of the buffer through its output handler and empty the buffer
+func processBuffer(level int, phase int64, context runtime.Context) (string, phpError.Error) {
+	stack := context.Interpreter.GetOutputBufferStack()
+	buffer := stack.Get(level)
+	content := buffer.Content
+	buffer.Content = ""
+
+	// The first invocation of the output handler is marked with PHP_OUTPUT_HANDLER_START
+	if buffer.Flags&outputBuffer.PHP_OUTPUT_HANDLER_STARTED == 0 {
+		phase |= outputBuffer.PHP_OUTPUT_HANDLER_START
+		buffer.Flags |= outputBuffer.PHP_OUTPUT_HANDLER_STARTED
+	}
+
+	if buffer.Handler == nil || buffer.Flags&outputBuffer.PHP_OUTPUT_HANDLER_DISABLED != 0 {
+		buffer.Flags |= outputBuffer.PHP_OUTPUT_HANDLER_PROCESSED
+		return content, nil
+	}
+
+	wasHandlerRunning := stack.IsHandlerRunning
+	stack.IsHandlerRunning = true
+	result, err := context.Interpreter.CallFunction(buffer.Handler, []values.RuntimeValue{values.NewStr(content), values.NewInt(phase)}, context.Env)
+	stack.IsHandlerRunning = wasHandlerRunning
+	if err != nil {
+		return "", err
+	}
+
+	// If the output handler returns false, the handler is disabled and the original input is sent
+	if result.GetType() == values.BoolValue && !result.(*values.Bool).Value {
+		buffer.Flags |= outputBuffer.PHP_OUTPUT_HANDLER_DISABLED
+		return content, nil
+	}
+
+	buffer.Flags |= outputBuffer.PHP_OUTPUT_HANDLER_PROCESSED
+	return variableHandling.StrVal(result)
+}
+
+// Get the name of an output handler as listed by ob_list_handlers
+func handlerName(handler values.RuntimeValue) string {
+	switch handler.GetType() {
+	case values.StrValue:
+		return handler.(*values.Str).Value
+	case values.ObjectValue:
+		return handler.(*values.Object).Class.GetQualifiedName() + "::__invoke"
+	case values.ArrayValue:
+		array := handler.(*values.Array)
+		target, targetFound := array.GetElement(values.NewInt(0))
+		method, methodFound := array.GetElement(values.NewInt(1))
+		if !targetFound || !methodFound {
+			return "???"
+		}
+		className := ""
+		if target.GetType() == values.ObjectValue {
+			className = target.Value.(*values.Object).Class.GetQualifiedName()
+		} else {
+			className, _ = variableHandling.StrVal(target.Value)
+		}
+		methodName, _ := variableHandling.StrVal(method.Value)
+		return className + "::" + methodName
+	default:
+		return "???"
+	}
+}
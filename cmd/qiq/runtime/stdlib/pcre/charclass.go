@@ -0,0 +1,309 @@
+package pcre
+
+import (
+	"strings"
+	"unicode"
+)
+
+type runeRange struct {
+	from, to rune
+}
+
+// Character class like "[a-z\d]", an escape like "\w" or the dot
+type charClass struct {
+	negated bool
+	// Ranges of characters that are matched case-insensitive if caseless is set
+	ranges []runeRange
+	// Classes like "\d" or "\p{Lu}" that are not affected by the case-insensitive matching
+	items    []func(rune) bool
+	caseless bool
+	utf      bool
+	// Precomputed result for the characters below 256
+	lowTable [256]bool
+}
+
+func newCharClass(caseless bool, utf bool) *charClass {
+	return &charClass{caseless: caseless, utf: utf}
+}
+
+func (class *charClass) addRange(from rune, to rune) {
+	class.ranges = append(class.ranges, runeRange{from, to})
+}
+
+func (class *charClass) addItem(item func(rune) bool) {
+	class.items = append(class.items, item)
+}
+
+// Precompute the lookup table. Must be called after all ranges and items are added.
+func (class *charClass) finalize() *charClass {
+	for r := rune(0); r < 256; r++ {
+		class.lowTable[r] = class.compute(r)
+	}
+	return class
+}
+
+func (class *charClass) matches(r rune) bool {
+	if r < 256 {
+		return class.lowTable[r]
+	}
+	return class.compute(r)
+}
+
+func (class *charClass) compute(r rune) bool {
+	return class.contains(r) != class.negated
+}
+
+func (class *charClass) contains(r rune) bool {
+	for _, item := range class.items {
+		if item(r) {
+			return true
+		}
+	}
+	if class.inRanges(r) {
+		return true
+	}
+	if class.caseless {
+		for _, other := range caseVariants(r, class.utf) {
+			if class.inRanges(other) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (class *charClass) inRanges(r rune) bool {
+	for _, runeRange := range class.ranges {
+		if r >= runeRange.from && r <= runeRange.to {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the other cases of the character.
+// Without UTF mode only ASCII letters have other cases like in the C locale.
+func caseVariants(r rune, utf bool) []rune {
+	if !utf {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return []rune{r - 'a' + 'A'}
+		case r >= 'A' && r <= 'Z':
+			return []rune{r - 'A' + 'a'}
+		}
+		return nil
+	}
+	var variants []rune
+	for other := unicode.SimpleFold(r); other != r; other = unicode.SimpleFold(other) {
+		variants = append(variants, other)
+	}
+	return variants
+}
+
+func equalFold(a rune, b rune, utf bool) bool {
+	if a == b {
+		return true
+	}
+	for _, other := range caseVariants(a, utf) {
+		if other == b {
+			return true
+		}
+	}
+	return false
+}
+
+// -------------------------------------- Escape classes -------------------------------------- MARK: Escape classes
+
+func isDigit(utf bool) func(rune) bool {
+	if utf {
+		return func(r rune) bool { return unicode.Is(unicode.Nd, r) }
+	}
+	return func(r rune) bool { return r >= '0' && r <= '9' }
+}
+
+func isSpace(utf bool) func(rune) bool {
+	if utf {
+		return unicode.IsSpace
+	}
+	return func(r rune) bool { return r == ' ' || (r >= '\t' && r <= '\r') }
+}
+
+func isWord(utf bool) func(rune) bool {
+	if utf {
+		return func(r rune) bool {
+			return r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.In(r, unicode.Mn, unicode.Pc)
+		}
+	}
+	return func(r rune) bool {
+		return r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+	}
+}
+
+func isHorizontalSpace(utf bool) func(rune) bool {
+	return func(r rune) bool {
+		switch {
+		case r == '\t' || r == ' ' || r == 0xA0:
+			return true
+		case !utf:
+			return false
+		case r == 0x1680 || r == 0x180E || (r >= 0x2000 && r <= 0x200A) || r == 0x202F || r == 0x205F || r == 0x3000:
+			return true
+		}
+		return false
+	}
+}
+
+func isVerticalSpace(utf bool) func(rune) bool {
+	return func(r rune) bool {
+		return (r >= '\n' && r <= '\r') || r == 0x85 || (utf && (r == 0x2028 || r == 0x2029))
+	}
+}
+
+func negate(item func(rune) bool) func(rune) bool {
+	return func(r rune) bool { return !item(r) }
+}
+
+// Get the class of an escape like "d" for "\d"
+func escapeClass(escape rune, utf bool) (func(rune) bool, bool) {
+	switch escape {
+	case 'd':
+		return isDigit(utf), true
+	case 'D':
+		return negate(isDigit(utf)), true
+	case 's':
+		return isSpace(utf), true
+	case 'S':
+		return negate(isSpace(utf)), true
+	case 'w':
+		return isWord(utf), true
+	case 'W':
+		return negate(isWord(utf)), true
+	case 'h':
+		return isHorizontalSpace(utf), true
+	case 'H':
+		return negate(isHorizontalSpace(utf)), true
+	case 'v':
+		return isVerticalSpace(utf), true
+	case 'V':
+		return negate(isVerticalSpace(utf)), true
+	}
+	return nil, false
+}
+
+// -------------------------------------- POSIX classes -------------------------------------- MARK: POSIX classes
+
+// Get the POSIX class like "alpha" for "[:alpha:]"
+func posixClass(name string, caseless bool, utf bool) (func(rune) bool, bool) {
+	isASCII := func(test func(rune) bool) func(rune) bool {
+		return func(r rune) bool { return r < 128 && test(r) }
+	}
+	isAlpha := func(r rune) bool { return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') }
+	isUpper := func(r rune) bool { return r >= 'A' && r <= 'Z' }
+	isLower := func(r rune) bool { return r >= 'a' && r <= 'z' }
+	isDigit := func(r rune) bool { return r >= '0' && r <= '9' }
+	isPunct := func(r rune) bool { return r > ' ' && r < 127 && !isAlpha(r) && !isDigit(r) }
+	if utf {
+		isAlpha = unicode.IsLetter
+		isUpper = unicode.IsUpper
+		isLower = unicode.IsLower
+		isDigit = func(r rune) bool { return unicode.Is(unicode.Nd, r) }
+	}
+	if caseless {
+		isUpper = func(r rune) bool {
+			return isAlpha(r) && (unicode.IsUpper(r) || unicode.IsLower(r) || unicode.IsTitle(r))
+		}
+		isLower = isUpper
+	}
+
+	switch name {
+	case "alnum":
+		return func(r rune) bool { return isAlpha(r) || isDigit(r) || (utf && unicode.IsNumber(r)) }, true
+	case "alpha":
+		return isAlpha, true
+	case "ascii":
+		return func(r rune) bool { return r < 128 }, true
+	case "blank":
+		return isHorizontalSpace(utf), true
+	case "cntrl":
+		return isASCII(func(r rune) bool { return r < 32 || r == 127 }), true
+	case "digit":
+		return isDigit, true
+	case "graph":
+		if utf {
+			return func(r rune) bool { return unicode.IsGraphic(r) && !unicode.IsSpace(r) }, true
+		}
+		return func(r rune) bool { return r > ' ' && r < 127 }, true
+	case "lower":
+		return isLower, true
+	case "print":
+		if utf {
+			return func(r rune) bool { return unicode.IsGraphic(r) && (r == ' ' || !unicode.IsSpace(r)) }, true
+		}
+		return func(r rune) bool { return r >= ' ' && r < 127 }, true
+	case "punct":
+		if utf {
+			return func(r rune) bool {
+				return (r < 128 && isPunct(r)) || (r >= 128 && unicode.IsPunct(r))
+			}, true
+		}
+		return isPunct, true
+	case "space":
+		return isSpace(utf), true
+	case "upper":
+		return isUpper, true
+	case "word":
+		return isWord(utf), true
+	case "xdigit":
+		return func(r rune) bool { return isDigit(r) && r < 128 || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F') }, true
+	}
+	return nil, false
+}
+
+// -------------------------------------- Unicode properties -------------------------------------- MARK: Unicode properties
+
+// Normalize the property name for the loose matching (e.g. "Old_Italic" and "old italic" are equal)
+func normalizePropertyName(name string) string {
+	name = strings.ToLower(name)
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(name)
+}
+
+var propertyTables map[string]*unicode.RangeTable
+
+func init() {
+	propertyTables = map[string]*unicode.RangeTable{}
+	for name, table := range unicode.Properties {
+		propertyTables[normalizePropertyName(name)] = table
+	}
+	for name, table := range unicode.Scripts {
+		propertyTables[normalizePropertyName(name)] = table
+	}
+	for name, table := range unicode.Categories {
+		propertyTables[normalizePropertyName(name)] = table
+	}
+}
+
+// Get the class of the Unicode property like "Lu" for "\p{Lu}"
+func unicodeProperty(name string) (func(rune) bool, bool) {
+	switch normalizePropertyName(name) {
+	case "any":
+		return func(rune) bool { return true }, true
+	case "l&", "lc":
+		return func(r rune) bool { return unicode.In(r, unicode.Lu, unicode.Ll, unicode.Lt) }, true
+	case "xan":
+		return func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }, true
+	case "xps", "xsp":
+		return unicode.IsSpace, true
+	case "xwd":
+		return isWord(true), true
+	case "xuc":
+		return func(r rune) bool {
+			return r == '$' || r == '@' || r == '`' || (r >= 0xA0 && r <= 0xD7FF) || r >= 0xE000
+		}, true
+	}
+	if table, found := propertyTables[normalizePropertyName(name)]; found {
+		return func(r rune) bool { return unicode.Is(table, r) }, true
+	}
+	return nil, false
+}
+
+func isMark(r rune) bool { return unicode.Is(unicode.M, r) }
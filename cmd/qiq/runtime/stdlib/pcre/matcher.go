@@ -0,0 +1,725 @@
+package pcre
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// State of a single search
+type matcher struct {
+	regex    *Regex
+	input    string
+	captures []int
+	// Offset where the search started (for \G)
+	searchStart int
+	// Offset of the current match attempt
+	attemptStart int
+	// Start of the match set by \K (-1 if not set)
+	keep            int
+	notEmptyAtStart bool
+	steps           int
+	limit           int
+	recursionLimit  int
+	// Active subroutine calls (group and position) to detect endless recursions
+	recursions []recursionFrame
+	err        MatchError
+	// Backtracking control verb that stops the current match attempt
+	verb   verbType
+	skipTo int
+}
+
+type recursionFrame struct {
+	group int
+	pos   int
+}
+
+// Continuation that matches the rest of the pattern at the position
+type continuation func(pos int) bool
+
+// Matcher of a node. It calls the continuation for each way the node matches at the position
+// and returns true as soon as the continuation returns true.
+type matchFn func(m *matcher, pos int, k continuation) bool
+
+// Matcher of a node that matches at most one way (e.g. a character or an assertion).
+// Returns the end position or -1 if the node does not match.
+type simpleFn func(m *matcher, pos int) int
+
+func (m *matcher) tryAt(start int) bool {
+	for i := range m.captures {
+		m.captures[i] = -1
+	}
+	m.attemptStart = start
+	m.keep = -1
+	m.verb = verbNone
+	m.recursions = m.recursions[:0]
+	return m.regex.program(m, start, func(end int) bool {
+		matchStart := start
+		if m.keep >= 0 {
+			matchStart = m.keep
+		}
+		if m.notEmptyAtStart && end == m.searchStart && matchStart == m.searchStart {
+			return false
+		}
+		m.captures[0], m.captures[1] = matchStart, end
+		return true
+	})
+}
+
+// Count a backtracking step. Returns false if the match attempt has to be stopped.
+func (m *matcher) step() bool {
+	if m.err != NoError || m.verb != verbNone {
+		return false
+	}
+	m.steps++
+	if m.limit > 0 && m.steps > m.limit {
+		m.err = BacktrackLimitError
+		return false
+	}
+	return true
+}
+
+func (m *matcher) stopped() bool { return m.err != NoError || m.verb != verbNone }
+
+// Get the character at the position and its size (0 at the end of the input)
+func (m *matcher) charAt(pos int) (rune, int) {
+	if pos >= len(m.input) {
+		return 0, 0
+	}
+	if m.regex.options.utf {
+		return utf8.DecodeRuneInString(m.input[pos:])
+	}
+	return rune(m.input[pos]), 1
+}
+
+// Get the character before the position and its size (0 at the start of the input)
+func (m *matcher) charBefore(pos int) (rune, int) {
+	if pos <= 0 {
+		return 0, 0
+	}
+	if m.regex.options.utf {
+		return utf8.DecodeLastRuneInString(m.input[:pos])
+	}
+	return rune(m.input[pos-1]), 1
+}
+
+func (m *matcher) saveCaptures() []int {
+	return append([]int(nil), m.captures...)
+}
+
+// -------------------------------------- Compiler -------------------------------------- MARK: Compiler
+
+type compiler struct {
+	regex *Regex
+}
+
+func (compiler *compiler) utf() bool { return compiler.regex.options.utf }
+
+func (compiler *compiler) compile(n *node) matchFn {
+	if simple := compiler.compileSimple(n); simple != nil {
+		return fromSimple(simple)
+	}
+
+	switch n.typ {
+	case nodeSequence:
+		return compiler.compileSequence(n.children)
+	case nodeAlternation:
+		return compiler.compileAlternation(n.children)
+	case nodeGroup:
+		return compiler.compileGroup(n)
+	case nodeRepeat:
+		return compiler.compileRepeat(n)
+	case nodeAtomic:
+		return atomic(compiler.compile(n.children[0]))
+	case nodeLookahead:
+		return compiler.compileLookahead(n)
+	case nodeLookbehind:
+		return compiler.compileLookbehind(n)
+	case nodeRecursion:
+		return compiler.compileRecursion(n)
+	case nodeConditional:
+		return compiler.compileConditional(n)
+	case nodeKeep:
+		return func(m *matcher, pos int, k continuation) bool {
+			saved := m.keep
+			m.keep = pos
+			if k(pos) {
+				return true
+			}
+			m.keep = saved
+			return false
+		}
+	case nodeNewline:
+		return compiler.compileNewline()
+	case nodeVerb:
+		return compileVerb(n.verb)
+	}
+	return func(m *matcher, pos int, k continuation) bool { return k(pos) }
+}
+
+func fromSimple(simple simpleFn) matchFn {
+	return func(m *matcher, pos int, k continuation) bool {
+		end := simple(m, pos)
+		return end >= 0 && k(end)
+	}
+}
+
+// Compile nodes that match at most one way. Returns nil for other nodes.
+func (compiler *compiler) compileSimple(n *node) simpleFn {
+	switch n.typ {
+	case nodeEmpty:
+		return func(m *matcher, pos int) int { return pos }
+	case nodeChar:
+		return compiler.compileChar(n)
+	case nodeClass:
+		class := n.class
+		return func(m *matcher, pos int) int {
+			char, size := m.charAt(pos)
+			if size == 0 || !class.matches(char) {
+				return -1
+			}
+			return pos + size
+		}
+	case nodeAssertion:
+		return compiler.compileAssertion(n)
+	case nodeBackref:
+		return compiler.compileBackref(n)
+	case nodeSequence:
+		simples := []simpleFn{}
+		for _, child := range n.children {
+			simple := compiler.compileSimple(child)
+			if simple == nil {
+				return nil
+			}
+			simples = append(simples, simple)
+		}
+		return sequenceOfSimples(simples)
+	}
+	return nil
+}
+
+func sequenceOfSimples(simples []simpleFn) simpleFn {
+	if len(simples) == 1 {
+		return simples[0]
+	}
+	return func(m *matcher, pos int) int {
+		for _, simple := range simples {
+			if pos = simple(m, pos); pos < 0 {
+				return -1
+			}
+		}
+		return pos
+	}
+}
+
+func (compiler *compiler) compileChar(n *node) simpleFn {
+	utf := compiler.utf()
+	if !n.caseless {
+		var builder strings.Builder
+		writeChar(&builder, n.char, utf)
+		literal := builder.String()
+		return func(m *matcher, pos int) int {
+			if strings.HasPrefix(m.input[pos:], literal) {
+				return pos + len(literal)
+			}
+			return -1
+		}
+	}
+	expected := n.char
+	return func(m *matcher, pos int) int {
+		char, size := m.charAt(pos)
+		if size == 0 || !equalFold(expected, char, utf) {
+			return -1
+		}
+		return pos + size
+	}
+}
+
+// Compile a sequence. Consecutive simple nodes are matched without continuations.
+func (compiler *compiler) compileSequence(children []*node) matchFn {
+	parts := []matchFn{}
+	simples := []simpleFn{}
+	flush := func() {
+		if len(simples) > 0 {
+			parts = append(parts, fromSimple(sequenceOfSimples(simples)))
+			simples = []simpleFn{}
+		}
+	}
+	for _, child := range children {
+		if simple := compiler.compileSimple(child); simple != nil {
+			simples = append(simples, simple)
+			continue
+		}
+		flush()
+		parts = append(parts, compiler.compile(child))
+	}
+	flush()
+	return sequence(parts)
+}
+
+func sequence(parts []matchFn) matchFn {
+	switch len(parts) {
+	case 0:
+		return func(m *matcher, pos int, k continuation) bool { return k(pos) }
+	case 1:
+		return parts[0]
+	}
+	first, rest := parts[0], sequence(parts[1:])
+	return func(m *matcher, pos int, k continuation) bool {
+		return first(m, pos, func(next int) bool { return rest(m, next, k) })
+	}
+}
+
+func (compiler *compiler) compileAlternation(children []*node) matchFn {
+	alternatives := make([]matchFn, len(children))
+	for i, child := range children {
+		alternatives[i] = compiler.compile(child)
+	}
+	return func(m *matcher, pos int, k continuation) bool {
+		for _, alternative := range alternatives {
+			if !m.step() {
+				return false
+			}
+			if alternative(m, pos, k) {
+				return true
+			}
+			// (*THEN) continues with the next alternative
+			if m.verb == verbThen {
+				m.verb = verbNone
+			}
+		}
+		return false
+	}
+}
+
+func (compiler *compiler) compileGroup(n *node) matchFn {
+	body := compiler.compile(n.children[0])
+	if n.group < 0 {
+		return body
+	}
+	group := n.group
+	compiler.regex.groups[group] = body
+	return func(m *matcher, pos int, k continuation) bool {
+		return body(m, pos, func(end int) bool {
+			oldStart, oldEnd := m.captures[2*group], m.captures[2*group+1]
+			m.captures[2*group], m.captures[2*group+1] = pos, end
+			if k(end) {
+				return true
+			}
+			m.captures[2*group], m.captures[2*group+1] = oldStart, oldEnd
+			return false
+		})
+	}
+}
+
+// Match the body only once: The first way it matches is used without backtracking into it
+func atomic(body matchFn) matchFn {
+	return func(m *matcher, pos int, k continuation) bool {
+		saved := m.saveCaptures()
+		end := -1
+		if !body(m, pos, func(bodyEnd int) bool { end = bodyEnd; return true }) {
+			return false
+		}
+		if k(end) {
+			return true
+		}
+		copy(m.captures, saved)
+		return false
+	}
+}
+
+// -------------------------------------- Repetition -------------------------------------- MARK: Repetition
+
+func (compiler *compiler) compileRepeat(n *node) matchFn {
+	child := n.children[0]
+	var loop matchFn
+	if single := compiler.singleChar(child); single != nil {
+		loop = compiler.compileCharRepeat(single, n.min, n.max, n.greedy)
+	} else {
+		loop = compileGeneralRepeat(compiler.compile(child), n.min, n.max, n.greedy)
+	}
+	if n.possessive {
+		return atomic(loop)
+	}
+	return loop
+}
+
+// Get a matcher for nodes that always match exactly one character (returns the size or -1)
+func (compiler *compiler) singleChar(n *node) func(m *matcher, pos int) int {
+	if n.typ == nodeGroup && n.group < 0 {
+		return compiler.singleChar(n.children[0])
+	}
+	if n.typ != nodeChar && n.typ != nodeClass {
+		return nil
+	}
+	simple := compiler.compileSimple(n)
+	return func(m *matcher, pos int) int {
+		end := simple(m, pos)
+		if end < 0 {
+			return -1
+		}
+		return end - pos
+	}
+}
+
+// Repeat a single character. The positions are found without recursion.
+func (compiler *compiler) compileCharRepeat(single func(m *matcher, pos int) int, min int, max int, greedy bool) matchFn {
+	utf := compiler.utf()
+	if !greedy {
+		return func(m *matcher, pos int, k continuation) bool {
+			for count := 0; ; count++ {
+				if count >= min {
+					if !m.step() {
+						return false
+					}
+					if k(pos) {
+						return true
+					}
+				}
+				if max >= 0 && count >= max {
+					return false
+				}
+				size := single(m, pos)
+				if size < 0 {
+					return false
+				}
+				pos += size
+			}
+		}
+	}
+	return func(m *matcher, pos int, k continuation) bool {
+		count := 0
+		end := pos
+		for max < 0 || count < max {
+			size := single(m, end)
+			if size < 0 {
+				break
+			}
+			end += size
+			count++
+		}
+		for ; count >= min; count-- {
+			if !m.step() {
+				return false
+			}
+			if k(end) {
+				return true
+			}
+			if count == 0 {
+				break
+			}
+			if utf {
+				_, size := utf8.DecodeLastRuneInString(m.input[:end])
+				end -= size
+			} else {
+				end--
+			}
+		}
+		return false
+	}
+}
+
+func compileGeneralRepeat(body matchFn, min int, max int, greedy bool) matchFn {
+	return func(m *matcher, pos int, k continuation) bool {
+		var iterate func(pos int, count int) bool
+		iterate = func(pos int, count int) bool {
+			if !m.step() {
+				return false
+			}
+			if !greedy && count >= min && k(pos) {
+				return true
+			}
+			if max < 0 || count < max {
+				matched := body(m, pos, func(end int) bool {
+					// An empty iteration ends the repetition
+					if end == pos && greedy && count+1 >= min {
+						return k(end)
+					}
+					if end == pos && !greedy && count >= min {
+						return false
+					}
+					return iterate(end, count+1)
+				})
+				if matched {
+					return true
+				}
+			}
+			return greedy && count >= min && !m.stopped() && k(pos)
+		}
+		return iterate(pos, 0)
+	}
+}
+
+// -------------------------------------- Assertions -------------------------------------- MARK: Assertions
+
+func (compiler *compiler) compileAssertion(n *node) simpleFn {
+	utf := compiler.utf()
+	newline := compiler.regex.newline
+	word := isWord(utf)
+	isWordAt := func(m *matcher, pos int) bool {
+		char, size := m.charAt(pos)
+		return size > 0 && word(char)
+	}
+	isWordBefore := func(m *matcher, pos int) bool {
+		char, size := m.charBefore(pos)
+		return size > 0 && word(char)
+	}
+	// Check if the position is at the end or before a newline at the end
+	atEndOrFinalNewline := func(m *matcher, pos int) bool {
+		if pos == len(m.input) {
+			return true
+		}
+		length := newline.lengthAt(m.input, pos)
+		return length > 0 && pos+length == len(m.input)
+	}
+
+	var test func(m *matcher, pos int) bool
+	switch n.assertion {
+	case assertSubjectStart:
+		test = func(m *matcher, pos int) bool { return pos == 0 }
+	case assertSubjectEnd:
+		test = func(m *matcher, pos int) bool { return pos == len(m.input) }
+	case assertSubjectEndOrNewline:
+		test = atEndOrFinalNewline
+	case assertSearchStart:
+		test = func(m *matcher, pos int) bool { return pos == m.searchStart }
+	case assertWordBoundary:
+		test = func(m *matcher, pos int) bool { return isWordBefore(m, pos) != isWordAt(m, pos) }
+	case assertNotWordBoundary:
+		test = func(m *matcher, pos int) bool { return isWordBefore(m, pos) == isWordAt(m, pos) }
+	case assertLineStart:
+		if n.multiline {
+			// A newline at the end of the subject does not start a new line
+			test = func(m *matcher, pos int) bool {
+				return pos == 0 || (pos < len(m.input) && newline.endsAt(m.input, pos))
+			}
+		} else {
+			test = func(m *matcher, pos int) bool { return pos == 0 }
+		}
+	case assertLineEnd:
+		switch {
+		case n.multiline:
+			test = func(m *matcher, pos int) bool { return pos == len(m.input) || newline.lengthAt(m.input, pos) > 0 }
+		case n.dollarEndOnly:
+			test = func(m *matcher, pos int) bool { return pos == len(m.input) }
+		default:
+			test = atEndOrFinalNewline
+		}
+	}
+	return func(m *matcher, pos int) int {
+		if test(m, pos) {
+			return pos
+		}
+		return -1
+	}
+}
+
+func (compiler *compiler) compileLookahead(n *node) matchFn {
+	body := compiler.compile(n.children[0])
+	return lookaround(func(m *matcher, pos int, k continuation) bool {
+		return body(m, pos, k)
+	}, n.negate)
+}
+
+// Match the body that must end at the position. The body matches a bounded number of characters.
+func (compiler *compiler) compileLookbehind(n *node) matchFn {
+	body := compiler.compile(n.children[0])
+	minWidth, maxWidth := width(n.children[0])
+	return lookaround(func(m *matcher, pos int, k continuation) bool {
+		start := pos
+		for count := 0; count <= maxWidth; count++ {
+			if count >= minWidth {
+				if body(m, start, func(end int) bool { return end == pos && k(pos) }) {
+					return true
+				}
+				if m.stopped() {
+					return false
+				}
+			}
+			_, size := m.charBefore(start)
+			if size == 0 {
+				break
+			}
+			start -= size
+		}
+		return false
+	}, n.negate)
+}
+
+func lookaround(test matchFn, negated bool) matchFn {
+	succeed := func(int) bool { return true }
+	if negated {
+		return func(m *matcher, pos int, k continuation) bool {
+			saved := m.saveCaptures()
+			if test(m, pos, succeed) {
+				copy(m.captures, saved)
+				return false
+			}
+			return !m.stopped() && k(pos)
+		}
+	}
+	return func(m *matcher, pos int, k continuation) bool {
+		saved := m.saveCaptures()
+		if !test(m, pos, succeed) {
+			return false
+		}
+		if k(pos) {
+			return true
+		}
+		copy(m.captures, saved)
+		return false
+	}
+}
+
+// -------------------------------------- References -------------------------------------- MARK: References
+
+func (compiler *compiler) compileBackref(n *node) simpleFn {
+	group := n.group
+	caseless := n.caseless
+	utf := compiler.utf()
+	return func(m *matcher, pos int) int {
+		start, end := m.captures[2*group], m.captures[2*group+1]
+		if start < 0 || end < 0 {
+			return -1
+		}
+		captured := m.input[start:end]
+		if !caseless {
+			if strings.HasPrefix(m.input[pos:], captured) {
+				return pos + len(captured)
+			}
+			return -1
+		}
+		for i := 0; i < len(captured); {
+			expected, expectedSize := decodeChar(captured[i:], utf)
+			char, size := m.charAt(pos)
+			if size == 0 || !equalFold(expected, char, utf) {
+				return -1
+			}
+			i += expectedSize
+			pos += size
+		}
+		return pos
+	}
+}
+
+func decodeChar(str string, utf bool) (rune, int) {
+	if utf {
+		return utf8.DecodeRuneInString(str)
+	}
+	return rune(str[0]), 1
+}
+
+// Call the group (or the whole pattern for group 0) as subroutine.
+// The captures set in the subroutine are reverted after the call.
+func (compiler *compiler) compileRecursion(n *node) matchFn {
+	group := n.group
+	groups := compiler.regex.groups
+	return func(m *matcher, pos int, k continuation) bool {
+		for _, frame := range m.recursions {
+			if frame.group == group && frame.pos == pos {
+				// Endless recursion without consuming characters
+				return false
+			}
+		}
+		if m.recursionLimit > 0 && len(m.recursions) >= m.recursionLimit {
+			m.err = RecursionLimitError
+			return false
+		}
+
+		saved := m.saveCaptures()
+		m.recursions = append(m.recursions, recursionFrame{group: group, pos: pos})
+		depth := len(m.recursions)
+		matched := groups[group](m, pos, func(end int) bool {
+			frame := m.recursions[depth-1]
+			m.recursions = m.recursions[:depth-1]
+			inner := m.saveCaptures()
+			copy(m.captures, saved)
+			if k(end) {
+				return true
+			}
+			copy(m.captures, inner)
+			m.recursions = append(m.recursions[:depth-1], frame)
+			return false
+		})
+		if !matched {
+			m.recursions = m.recursions[:depth-1]
+		}
+		return matched
+	}
+}
+
+func (compiler *compiler) compileConditional(n *node) matchFn {
+	condition := n.condition
+	yes := compiler.compile(n.children[0])
+	no := compiler.compile(n.children[1])
+
+	switch {
+	case condition.define:
+		return func(m *matcher, pos int, k continuation) bool { return k(pos) }
+	case condition.assertion != nil:
+		assertion := compiler.compile(condition.assertion)
+		return func(m *matcher, pos int, k continuation) bool {
+			if assertion(m, pos, func(int) bool { return true }) {
+				return yes(m, pos, k)
+			}
+			if m.stopped() {
+				return false
+			}
+			return no(m, pos, k)
+		}
+	case condition.recursion:
+		group := condition.group
+		return func(m *matcher, pos int, k continuation) bool {
+			recursing := len(m.recursions) > 0
+			if recursing && group > 0 {
+				recursing = m.recursions[len(m.recursions)-1].group == group
+			}
+			if recursing {
+				return yes(m, pos, k)
+			}
+			return no(m, pos, k)
+		}
+	}
+
+	group := condition.group
+	return func(m *matcher, pos int, k continuation) bool {
+		if m.captures[2*group+1] >= 0 {
+			return yes(m, pos, k)
+		}
+		return no(m, pos, k)
+	}
+}
+
+// -------------------------------------- Others -------------------------------------- MARK: Others
+
+// Match \R: a CRLF or a single vertical whitespace
+func (compiler *compiler) compileNewline() matchFn {
+	vertical := isVerticalSpace(compiler.utf())
+	return fromSimple(func(m *matcher, pos int) int {
+		if strings.HasPrefix(m.input[pos:], "\r\n") {
+			return pos + 2
+		}
+		char, size := m.charAt(pos)
+		if size == 0 || !vertical(char) {
+			return -1
+		}
+		return pos + size
+	})
+}
+
+// Compile a backtracking control verb. If the match backtracks to the verb, the verb stops the match attempt.
+func compileVerb(verb verbType) matchFn {
+	if verb == verbFail {
+		return func(m *matcher, pos int, k continuation) bool { return false }
+	}
+	return func(m *matcher, pos int, k continuation) bool {
+		if k(pos) {
+			return true
+		}
+		if m.stopped() {
+			return false
+		}
+		m.verb = verb
+		m.skipTo = pos
+		return false
+	}
+}
@@ -0,0 +1,1355 @@
+package pcre
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type nodeType int
+
+const (
+	nodeEmpty nodeType = iota
+	nodeChar
+	nodeClass
+	nodeSequence
+	nodeAlternation
+	nodeGroup
+	nodeRepeat
+	nodeAtomic
+	nodeLookahead
+	nodeLookbehind
+	nodeAssertion
+	nodeBackref
+	nodeRecursion
+	nodeConditional
+	nodeKeep
+	nodeNewline
+	nodeVerb
+)
+
+type assertionType int
+
+const (
+	assertLineStart assertionType = iota
+	assertLineEnd
+	assertSubjectStart
+	assertSubjectEnd
+	assertSubjectEndOrNewline
+	assertSearchStart
+	assertWordBoundary
+	assertNotWordBoundary
+)
+
+type verbType int
+
+const (
+	verbNone verbType = iota
+	verbFail
+	verbCommit
+	verbPrune
+	verbSkip
+	verbThen
+)
+
+type node struct {
+	typ      nodeType
+	children []*node
+	char     rune
+	class    *charClass
+	caseless bool
+	// Repetition
+	min, max   int
+	greedy     bool
+	possessive bool
+	// Number of the (referenced) group
+	group int
+	// Name of the referenced group (resolved after parsing)
+	name string
+	// Relative reference to a group (resolved after parsing)
+	relative bool
+	negate   bool
+	// Assertions
+	assertion     assertionType
+	multiline     bool
+	dollarEndOnly bool
+	verb          verbType
+	// Conditions
+	condition *condition
+	// Offset in the pattern for error messages
+	offset int
+}
+
+// Condition of a conditional group like "(?(1)yes|no)"
+type condition struct {
+	group     int
+	name      string
+	recursion bool
+	define    bool
+	assertion *node
+}
+
+type options struct {
+	caseless       bool
+	multiline      bool
+	dotAll         bool
+	extended       bool
+	ungreedy       bool
+	dollarEndOnly  bool
+	anchored       bool
+	utf            bool
+	noAutoCapture  bool
+	duplicateNames bool
+}
+
+type CompileError struct {
+	Message string
+	Offset  int
+}
+
+func (err *CompileError) Error() string {
+	return fmt.Sprintf("%s at offset %d", err.Message, err.Offset)
+}
+
+type parser struct {
+	pattern  string
+	pos      int
+	options  options
+	newline  newlineType
+	limit    int
+	captures int
+	// Names of the capturing groups (index is the group number)
+	groupNames []string
+	names      map[string][]int
+	// Nodes that reference groups and are resolved after parsing
+	references []*node
+	// The characters are literal (between \Q and \E)
+	quoted bool
+}
+
+func (parser *parser) error(offset int, message string) *CompileError {
+	return &CompileError{Message: message, Offset: offset}
+}
+
+func (parser *parser) atEnd() bool { return parser.pos >= len(parser.pattern) }
+
+// Get the character at the current position and its size
+func (parser *parser) peek() (rune, int) {
+	if parser.atEnd() {
+		return 0, 0
+	}
+	if parser.options.utf {
+		return utf8.DecodeRuneInString(parser.pattern[parser.pos:])
+	}
+	return rune(parser.pattern[parser.pos]), 1
+}
+
+func (parser *parser) next() rune {
+	r, size := parser.peek()
+	parser.pos += size
+	return r
+}
+
+func (parser *parser) lookingAt(prefix string) bool {
+	return strings.HasPrefix(parser.pattern[parser.pos:], prefix)
+}
+
+func (parser *parser) consume(prefix string) bool {
+	if parser.lookingAt(prefix) {
+		parser.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+// Skip whitespaces and comments in the extended mode
+func (parser *parser) skipExtended() {
+	if !parser.options.extended {
+		return
+	}
+	for !parser.atEnd() {
+		switch parser.pattern[parser.pos] {
+		case ' ', '\t', '\n', '\r', '\f', '\v':
+			parser.pos++
+		case '#':
+			for !parser.atEnd() && parser.pattern[parser.pos] != '\n' {
+				parser.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// -------------------------------------- Structure -------------------------------------- MARK: Structure
+
+func (parser *parser) parseAlternation() (*node, *CompileError) {
+	alternatives := []*node{}
+	for {
+		sequence, err := parser.parseSequence()
+		if err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, sequence)
+		if !parser.consume("|") {
+			break
+		}
+	}
+	if len(alternatives) == 1 {
+		return alternatives[0], nil
+	}
+	return &node{typ: nodeAlternation, children: alternatives}, nil
+}
+
+func (parser *parser) parseSequence() (*node, *CompileError) {
+	items := []*node{}
+	for {
+		if parser.quoted {
+			if parser.consume(`\E`) {
+				parser.quoted = false
+				continue
+			}
+			if parser.atEnd() {
+				break
+			}
+			start := parser.pos
+			char := parser.charNode(parser.next(), start)
+			if !parser.consume(`\E`) {
+				items = append(items, char)
+				continue
+			}
+			// A quantifier after the end of the quoting applies to the last character
+			parser.quoted = false
+			parser.skipExtended()
+			repeated, err := parser.parseQuantifier(char, start)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, repeated)
+			continue
+		}
+		parser.skipExtended()
+		if parser.atEnd() || parser.lookingAt("|") || parser.lookingAt(")") {
+			break
+		}
+		start := parser.pos
+		atom, err := parser.parseAtom()
+		if err != nil {
+			return nil, err
+		}
+		if atom == nil {
+			// Comments, option settings and quoting
+			continue
+		}
+		parser.skipExtended()
+		repeated, err := parser.parseQuantifier(atom, start)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, repeated)
+	}
+	if len(items) == 1 {
+		return items[0], nil
+	}
+	return &node{typ: nodeSequence, children: items}, nil
+}
+
+// Check if the position is at a quantifier. "{" is only a quantifier if it has the form {n}, {n,}, {n,m} or {,m}.
+func (parser *parser) quantifierAt() bool {
+	if parser.atEnd() {
+		return false
+	}
+	switch parser.pattern[parser.pos] {
+	case '*', '+', '?':
+		return true
+	case '{':
+		_, _, _, ok := parser.scanBraces()
+		return ok
+	}
+	return false
+}
+
+// Scan a "{n,m}" quantifier at the current position. Returns the bounds (max -1 for no limit) and the length.
+func (parser *parser) scanBraces() (int, int, int, bool) {
+	rest := parser.pattern[parser.pos:]
+	end := strings.IndexByte(rest, '}')
+	if end < 0 {
+		return 0, 0, 0, false
+	}
+	content := strings.NewReplacer(" ", "", "\t", "").Replace(rest[1:end])
+	isNumber := func(str string) bool {
+		if str == "" {
+			return false
+		}
+		for _, char := range []byte(str) {
+			if char < '0' || char > '9' {
+				return false
+			}
+		}
+		return true
+	}
+	toInt := func(str string) int {
+		value, err := strconv.Atoi(str)
+		if err != nil || value > 65535 {
+			return 65536
+		}
+		return value
+	}
+	minStr, maxStr, hasComma := strings.Cut(content, ",")
+	switch {
+	case !hasComma && isNumber(minStr):
+		return toInt(minStr), toInt(minStr), end + 1, true
+	case hasComma && isNumber(minStr) && maxStr == "":
+		return toInt(minStr), -1, end + 1, true
+	case hasComma && (minStr == "" || isNumber(minStr)) && isNumber(maxStr):
+		min := 0
+		if minStr != "" {
+			min = toInt(minStr)
+		}
+		return min, toInt(maxStr), end + 1, true
+	}
+	return 0, 0, 0, false
+}
+
+func (parser *parser) parseQuantifier(atom *node, atomStart int) (*node, *CompileError) {
+	if !parser.quantifierAt() {
+		return atom, nil
+	}
+	quantifierStart := parser.pos
+	if !isRepeatable(atom) {
+		return nil, parser.error(quantifierStart, "quantifier does not follow a repeatable item")
+	}
+
+	var min, max int
+	switch parser.pattern[parser.pos] {
+	case '*':
+		min, max = 0, -1
+		parser.pos++
+	case '+':
+		min, max = 1, -1
+		parser.pos++
+	case '?':
+		min, max = 0, 1
+		parser.pos++
+	case '{':
+		var length int
+		min, max, length, _ = parser.scanBraces()
+		parser.pos += length
+		if min > 65535 || max > 65535 {
+			return nil, parser.error(parser.pos-1, "number too big in {} quantifier")
+		}
+		if max >= 0 && max < min {
+			return nil, parser.error(parser.pos-1, "numbers out of order in {} quantifier")
+		}
+	}
+
+	greedy := !parser.options.ungreedy
+	possessive := false
+	if parser.consume("+") {
+		possessive = true
+		greedy = true
+	} else if parser.consume("?") {
+		greedy = !greedy
+	}
+
+	parser.skipExtended()
+	if parser.quantifierAt() && parser.pattern[parser.pos] != '{' {
+		return nil, parser.error(parser.pos, "quantifier does not follow a repeatable item")
+	}
+
+	// Assertions can be repeated but are only checked once
+	if atom.typ == nodeLookahead || atom.typ == nodeLookbehind {
+		if min == 0 {
+			return &node{typ: nodeEmpty}, nil
+		}
+		return atom, nil
+	}
+	return &node{typ: nodeRepeat, children: []*node{atom}, min: min, max: max, greedy: greedy, possessive: possessive, offset: atomStart}, nil
+}
+
+func isRepeatable(atom *node) bool {
+	switch atom.typ {
+	case nodeAssertion, nodeKeep, nodeVerb, nodeRepeat:
+		return false
+	}
+	return true
+}
+
+// -------------------------------------- Atoms -------------------------------------- MARK: Atoms
+
+func (parser *parser) parseAtom() (*node, *CompileError) {
+	start := parser.pos
+	char := parser.next()
+	switch char {
+	case '(':
+		return parser.parseGroup(start)
+	case '[':
+		if parser.lookingAt(":") || parser.lookingAt(".") || parser.lookingAt("=") {
+			if end := strings.Index(parser.pattern[parser.pos:], parser.pattern[parser.pos:parser.pos+1]+"]"); end > 0 &&
+				!strings.ContainsAny(parser.pattern[parser.pos+1:parser.pos+end], "[]") {
+				return nil, parser.error(parser.pos, "POSIX named classes are supported only within a class")
+			}
+		}
+		return parser.parseClass(start)
+	case '.':
+		class := newCharClass(false, parser.options.utf)
+		class.negated = true
+		if !parser.options.dotAll {
+			class.addItem(parser.newline.isNewlineChar)
+		}
+		return parser.classNode(class, start), nil
+	case '^':
+		return &node{typ: nodeAssertion, assertion: assertLineStart, multiline: parser.options.multiline}, nil
+	case '$':
+		return &node{typ: nodeAssertion, assertion: assertLineEnd, multiline: parser.options.multiline, dollarEndOnly: parser.options.dollarEndOnly}, nil
+	case '\\':
+		return parser.parseEscape(start)
+	case '*', '+', '?':
+		return nil, parser.error(start, "quantifier does not follow a repeatable item")
+	case '{':
+		parser.pos = start
+		if parser.quantifierAt() {
+			return nil, parser.error(start, "quantifier does not follow a repeatable item")
+		}
+		parser.pos++
+	}
+	return parser.charNode(char, start), nil
+}
+
+func (parser *parser) charNode(char rune, offset int) *node {
+	caseless := parser.options.caseless && len(caseVariants(char, parser.options.utf)) > 0
+	return &node{typ: nodeChar, char: char, caseless: caseless, offset: offset}
+}
+
+func (parser *parser) classNode(class *charClass, offset int) *node {
+	return &node{typ: nodeClass, class: class.finalize(), offset: offset}
+}
+
+// Parse an escape sequence outside a character class (the backslash is consumed)
+func (parser *parser) parseEscape(start int) (*node, *CompileError) {
+	if parser.atEnd() {
+		return nil, parser.error(parser.pos, `\ at end of pattern`)
+	}
+	utf := parser.options.utf
+	char, _ := parser.peek()
+
+	switch char {
+	case 'A', 'z', 'Z', 'G', 'b', 'B':
+		parser.pos++
+		assertion := map[rune]assertionType{
+			'A': assertSubjectStart, 'z': assertSubjectEnd, 'Z': assertSubjectEndOrNewline,
+			'G': assertSearchStart, 'b': assertWordBoundary, 'B': assertNotWordBoundary,
+		}[char]
+		return &node{typ: nodeAssertion, assertion: assertion, offset: start}, nil
+	case 'K':
+		parser.pos++
+		return &node{typ: nodeKeep}, nil
+	case 'R':
+		parser.pos++
+		return &node{typ: nodeNewline}, nil
+	case 'X':
+		// Extended grapheme cluster (approximated by a character and the following combining marks)
+		parser.pos++
+		return parser.graphemeNode(start), nil
+	case 'N':
+		if parser.lookingAt("N{") {
+			break
+		}
+		parser.pos++
+		class := newCharClass(false, utf)
+		class.negated = true
+		class.addItem(parser.newline.isNewlineChar)
+		return parser.classNode(class, start), nil
+	case 'Q':
+		parser.pos++
+		parser.quoted = true
+		return nil, nil
+	case 'E':
+		parser.pos++
+		return nil, nil
+	case 'p', 'P':
+		parser.pos++
+		item, err := parser.parseProperty(char == 'P')
+		if err != nil {
+			return nil, err
+		}
+		class := newCharClass(false, utf)
+		class.addItem(item)
+		return parser.classNode(class, start), nil
+	case 'g':
+		return parser.parseGReference(start)
+	case 'k':
+		parser.pos++
+		name, err := parser.parseReferenceName()
+		if err != nil {
+			return nil, err
+		}
+		return parser.backrefNode(0, name, false, start), nil
+	}
+
+	if item, ok := escapeClass(char, utf); ok {
+		parser.pos++
+		class := newCharClass(false, utf)
+		class.addItem(item)
+		return parser.classNode(class, start), nil
+	}
+
+	// Backreferences like \1 or \10 (if there are at least 10 groups so far)
+	if char >= '1' && char <= '9' {
+		digitsEnd := parser.pos
+		for digitsEnd < len(parser.pattern) && parser.pattern[digitsEnd] >= '0' && parser.pattern[digitsEnd] <= '9' {
+			digitsEnd++
+		}
+		number, _ := strconv.Atoi(parser.pattern[parser.pos:digitsEnd])
+		if number < 10 || char >= '8' || number <= parser.captures {
+			parser.pos = digitsEnd
+			return parser.backrefNode(number, "", false, start), nil
+		}
+	}
+
+	value, err := parser.parseCharEscape(false)
+	if err != nil {
+		return nil, err
+	}
+	return parser.charNode(value, start), nil
+}
+
+// Parse a reference like \g1, \g{-1}, \g{name} or a subroutine call like \g<name>
+func (parser *parser) parseGReference(start int) (*node, *CompileError) {
+	parser.pos++
+	if parser.lookingAt("<") || parser.lookingAt("'") {
+		terminator := map[string]string{"<": ">", "'": "'"}[parser.pattern[parser.pos:parser.pos+1]]
+		parser.pos++
+		end := strings.Index(parser.pattern[parser.pos:], terminator)
+		if end < 0 {
+			return nil, parser.error(parser.pos, "\\g is not followed by a braced, angle-bracketed, or quoted name/number or by a plain number")
+		}
+		reference := parser.pattern[parser.pos : parser.pos+end]
+		parser.pos += end + 1
+		return parser.recursionNode(reference, start)
+	}
+
+	reference := ""
+	if parser.consume("{") {
+		end := strings.IndexByte(parser.pattern[parser.pos:], '}')
+		if end < 0 {
+			return nil, parser.error(parser.pos, "\\g is not followed by a braced, angle-bracketed, or quoted name/number or by a plain number")
+		}
+		reference = parser.pattern[parser.pos : parser.pos+end]
+		parser.pos += end + 1
+	} else {
+		end := parser.pos
+		if end < len(parser.pattern) && (parser.pattern[end] == '-' || parser.pattern[end] == '+') {
+			end++
+		}
+		for end < len(parser.pattern) && parser.pattern[end] >= '0' && parser.pattern[end] <= '9' {
+			end++
+		}
+		reference = parser.pattern[parser.pos:end]
+		parser.pos = end
+	}
+
+	number, err := strconv.Atoi(reference)
+	if err != nil {
+		if !isValidName(reference) {
+			return nil, parser.error(parser.pos, "\\g is not followed by a braced, angle-bracketed, or quoted name/number or by a plain number")
+		}
+		return parser.backrefNode(0, reference, false, start), nil
+	}
+	if number == 0 {
+		return nil, parser.error(parser.pos, "a relative value of zero is not allowed")
+	}
+	if number < 0 {
+		number = parser.captures + number + 1
+		if number <= 0 {
+			return nil, parser.error(parser.pos, "reference to non-existent subpattern")
+		}
+	}
+	return parser.backrefNode(number, "", false, start), nil
+}
+
+func (parser *parser) backrefNode(group int, name string, relative bool, offset int) *node {
+	backref := &node{typ: nodeBackref, group: group, name: name, relative: relative, caseless: parser.options.caseless, offset: offset}
+	parser.references = append(parser.references, backref)
+	return backref
+}
+
+// Create a subroutine call like (?1), (?-1), (?+1), (?&name) or (?R)
+func (parser *parser) recursionNode(reference string, offset int) (*node, *CompileError) {
+	recursion := &node{typ: nodeRecursion, offset: offset}
+	if reference == "R" {
+		parser.references = append(parser.references, recursion)
+		return recursion, nil
+	}
+	number, err := strconv.Atoi(reference)
+	switch {
+	case err != nil:
+		if !isValidName(reference) {
+			return nil, parser.error(parser.pos, "subpattern name expected")
+		}
+		recursion.name = reference
+	case strings.HasPrefix(reference, "-"):
+		if number == 0 {
+			return nil, parser.error(parser.pos, "a relative value of zero is not allowed")
+		}
+		number = parser.captures + number + 1
+		if number <= 0 {
+			return nil, parser.error(parser.pos, "reference to non-existent subpattern")
+		}
+		recursion.group = number
+	case strings.HasPrefix(reference, "+"):
+		if number == 0 {
+			return nil, parser.error(parser.pos, "a relative value of zero is not allowed")
+		}
+		recursion.group = parser.captures + number
+	default:
+		recursion.group = number
+	}
+	parser.references = append(parser.references, recursion)
+	return recursion, nil
+}
+
+// Parse a name like <name>, 'name' or {name} (the opening character is at the current position)
+func (parser *parser) parseReferenceName() (string, *CompileError) {
+	terminators := map[byte]byte{'<': '>', '\'': '\'', '{': '}'}
+	if parser.atEnd() {
+		return "", parser.error(parser.pos, "\\k is not followed by a braced, angle-bracketed, or quoted name")
+	}
+	terminator, found := terminators[parser.pattern[parser.pos]]
+	if !found {
+		return "", parser.error(parser.pos, "\\k is not followed by a braced, angle-bracketed, or quoted name")
+	}
+	parser.pos++
+	return parser.parseName(terminator)
+}
+
+// Parse a group name up to the terminator
+func (parser *parser) parseName(terminator byte) (string, *CompileError) {
+	start := parser.pos
+	for !parser.atEnd() && isNameChar(parser.pattern[parser.pos]) {
+		parser.pos++
+	}
+	name := parser.pattern[start:parser.pos]
+	if name == "" {
+		return "", parser.error(parser.pos, "subpattern name expected")
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		return "", parser.error(start, "subpattern name must start with a non-digit")
+	}
+	if parser.atEnd() || parser.pattern[parser.pos] != terminator {
+		return "", parser.error(parser.pos, "syntax error in subpattern name (missing terminator?)")
+	}
+	parser.pos++
+	return name, nil
+}
+
+func isNameChar(char byte) bool {
+	return char == '_' || (char >= '0' && char <= '9') || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+}
+
+func isValidName(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, char := range []byte(name) {
+		if !isNameChar(char) {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse a property like "L", "{Lu}" or "{^Greek}" after \p or \P
+func (parser *parser) parseProperty(negated bool) (func(rune) bool, *CompileError) {
+	name := ""
+	if parser.consume("{") {
+		end := strings.IndexByte(parser.pattern[parser.pos:], '}')
+		if end < 0 {
+			return nil, parser.error(len(parser.pattern), `malformed \P or \p sequence`)
+		}
+		name = parser.pattern[parser.pos : parser.pos+end]
+		parser.pos += end + 1
+		if strings.HasPrefix(name, "^") {
+			negated = !negated
+			name = name[1:]
+		}
+	} else {
+		if parser.atEnd() {
+			return nil, parser.error(parser.pos, `malformed \P or \p sequence`)
+		}
+		name = parser.pattern[parser.pos : parser.pos+1]
+		parser.pos++
+	}
+	item, found := unicodeProperty(name)
+	if !found {
+		return nil, parser.error(parser.pos, `unknown property after \P or \p`)
+	}
+	if negated {
+		return negate(item), nil
+	}
+	return item, nil
+}
+
+// Parse an escape for a single character like \n, \x41, \101 or \. (the backslash is consumed).
+// In a class \b is a backspace.
+func (parser *parser) parseCharEscape(inClass bool) (rune, *CompileError) {
+	if parser.atEnd() {
+		return 0, parser.error(parser.pos, `\ at end of pattern`)
+	}
+	start := parser.pos
+	char := parser.next()
+	switch char {
+	case 'a':
+		return 7, nil
+	case 'e':
+		return 27, nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'b':
+		if inClass {
+			return '\b', nil
+		}
+	case 'c':
+		if parser.atEnd() {
+			return 0, parser.error(parser.pos, `\c at end of pattern`)
+		}
+		control := parser.next()
+		if control < 32 || control > 126 {
+			return 0, parser.error(parser.pos, `\c must be followed by a printable ASCII character`)
+		}
+		if control >= 'a' && control <= 'z' {
+			control -= 32
+		}
+		return control ^ 0x40, nil
+	case 'x':
+		if parser.consume("{") {
+			end := strings.IndexByte(parser.pattern[parser.pos:], '}')
+			value, err := strconv.ParseUint(strings.TrimSpace(parser.pattern[parser.pos:parser.pos+max(end, 0)]), 16, 32)
+			if end <= 0 || err != nil {
+				return 0, parser.error(parser.pos, `\x{ is not followed by }`)
+			}
+			parser.pos += end + 1
+			return parser.checkCodePoint(rune(value))
+		}
+		value := rune(0)
+		for i := 0; i < 2 && !parser.atEnd() && isHexDigit(parser.pattern[parser.pos]); i++ {
+			digit, _ := strconv.ParseUint(parser.pattern[parser.pos:parser.pos+1], 16, 8)
+			value = value*16 + rune(digit)
+			parser.pos++
+		}
+		return value, nil
+	case 'o':
+		if !parser.consume("{") {
+			return 0, parser.error(parser.pos, `missing opening brace after \o`)
+		}
+		end := strings.IndexByte(parser.pattern[parser.pos:], '}')
+		value, err := strconv.ParseUint(parser.pattern[parser.pos:parser.pos+max(end, 0)], 8, 32)
+		if end <= 0 || err != nil {
+			return 0, parser.error(parser.pos, `non-octal character in \o{} (closing brace missing?)`)
+		}
+		parser.pos += end + 1
+		return parser.checkCodePoint(rune(value))
+	case 'N':
+		if parser.consume("{U+") {
+			end := strings.IndexByte(parser.pattern[parser.pos:], '}')
+			value, err := strconv.ParseUint(parser.pattern[parser.pos:parser.pos+max(end, 0)], 16, 32)
+			if end <= 0 || err != nil || !parser.options.utf {
+				return 0, parser.error(parser.pos, `\N{U+dddd} is supported only in Unicode (UTF) mode`)
+			}
+			parser.pos += end + 1
+			return parser.checkCodePoint(rune(value))
+		}
+	}
+
+	// Octal escapes like \0, \012 or \101 (up to three digits)
+	if char >= '0' && char <= '7' {
+		value := char - '0'
+		for i := 1; i < 3 && !parser.atEnd() && parser.pattern[parser.pos] >= '0' && parser.pattern[parser.pos] <= '7'; i++ {
+			value = value*8 + rune(parser.pattern[parser.pos]-'0')
+			parser.pos++
+		}
+		return parser.checkCodePoint(value)
+	}
+	if (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9') {
+		return 0, parser.error(start, `unrecognized character follows \`)
+	}
+	return char, nil
+}
+
+func (parser *parser) checkCodePoint(value rune) (rune, *CompileError) {
+	if (!parser.options.utf && value > 0xFF) || value > utf8.MaxRune {
+		return 0, parser.error(parser.pos, `character code point value in \x{} or \o{} is too large`)
+	}
+	if parser.options.utf && value >= 0xD800 && value <= 0xDFFF {
+		return 0, parser.error(parser.pos, "disallowed Unicode code point (>= 0xd800 && <= 0xdfff)")
+	}
+	return value, nil
+}
+
+func isHexDigit(char byte) bool {
+	return (char >= '0' && char <= '9') || (char >= 'a' && char <= 'f') || (char >= 'A' && char <= 'F')
+}
+
+// Create a node for \X: a character that is not a combining mark followed by combining marks
+func (parser *parser) graphemeNode(offset int) *node {
+	marks := newCharClass(false, parser.options.utf)
+	marks.addItem(func(r rune) bool { return isMark(r) })
+	mark := parser.classNode(marks, offset)
+	any := parser.classNode(newCharClass(false, parser.options.utf), offset)
+	any.class.negated = true
+	any.class.finalize()
+	crlf := &node{typ: nodeSequence, children: []*node{{typ: nodeChar, char: '\r'}, {typ: nodeChar, char: '\n'}}}
+	cluster := &node{typ: nodeSequence, children: []*node{any, {typ: nodeRepeat, children: []*node{mark}, min: 0, max: -1, greedy: true, possessive: true}}}
+	return &node{typ: nodeAtomic, children: []*node{{typ: nodeAlternation, children: []*node{crlf, cluster}}}}
+}
+
+// -------------------------------------- Character classes -------------------------------------- MARK: Character classes
+
+// Parse a character class like "[^a-z\d]" (the opening bracket is consumed)
+func (parser *parser) parseClass(start int) (*node, *CompileError) {
+	utf := parser.options.utf
+	class := newCharClass(parser.options.caseless, utf)
+	if parser.consume("^") {
+		class.negated = true
+	}
+
+	missingBracket := func() *CompileError {
+		return parser.error(len(parser.pattern), "missing terminating ] for character class")
+	}
+
+	// Parse a single item. Returns the character or the class (e.g. \d) and if the end of the class is reached.
+	first := true
+	quoted := false
+	parseItem := func() (rune, func(rune) bool, bool, *CompileError) {
+		for {
+			if parser.atEnd() {
+				return 0, nil, false, missingBracket()
+			}
+			if quoted {
+				if parser.consume(`\E`) {
+					quoted = false
+					continue
+				}
+				return parser.next(), nil, false, nil
+			}
+			if parser.lookingAt("]") && !first {
+				parser.pos++
+				return 0, nil, true, nil
+			}
+			first = false
+			if parser.lookingAt("[:") {
+				if end := strings.Index(parser.pattern[parser.pos+2:], ":]"); end >= 0 {
+					name := parser.pattern[parser.pos+2 : parser.pos+2+end]
+					negated := strings.HasPrefix(name, "^")
+					item, found := posixClass(strings.TrimPrefix(name, "^"), parser.options.caseless, utf)
+					if !found {
+						return 0, nil, false, parser.error(parser.pos+2+end, "unknown POSIX class name")
+					}
+					parser.pos += end + 4
+					if negated {
+						item = negate(item)
+					}
+					return 0, item, false, nil
+				}
+			}
+			char := parser.next()
+			if char != '\\' {
+				return char, nil, false, nil
+			}
+			if parser.atEnd() {
+				return 0, nil, false, parser.error(parser.pos, `\ at end of pattern`)
+			}
+			escape, _ := parser.peek()
+			switch escape {
+			case 'Q':
+				parser.pos++
+				quoted = true
+				continue
+			case 'E':
+				parser.pos++
+				continue
+			case 'p', 'P':
+				parser.pos++
+				item, err := parser.parseProperty(escape == 'P')
+				return 0, item, false, err
+			case 'N', 'R', 'X', 'B', 'K':
+				return 0, nil, false, parser.error(parser.pos+1, "escape sequence is invalid in character class")
+			}
+			if item, ok := escapeClass(escape, utf); ok {
+				parser.pos++
+				return 0, item, false, nil
+			}
+			if escape == '8' || escape == '9' {
+				parser.pos++
+				return escape, nil, false, nil
+			}
+			value, err := parser.parseCharEscape(true)
+			return value, nil, false, err
+		}
+	}
+
+	for {
+		char, item, end, err := parseItem()
+		if err != nil {
+			return nil, err
+		}
+		if end {
+			break
+		}
+		if item != nil {
+			if parser.lookingAt("-") && !parser.lookingAt("-]") {
+				return nil, parser.error(parser.pos+1, "invalid range in character class")
+			}
+			class.addItem(item)
+			continue
+		}
+		if !quoted && parser.lookingAt("-") && !parser.lookingAt("-]") {
+			parser.pos++
+			to, toItem, end, err := parseItem()
+			if err != nil {
+				return nil, err
+			}
+			if end {
+				return nil, missingBracket()
+			}
+			if toItem != nil {
+				return nil, parser.error(parser.pos-1, "invalid range in character class")
+			}
+			if to < char {
+				return nil, parser.error(parser.pos-1, "range out of order in character class")
+			}
+			class.addRange(char, to)
+			continue
+		}
+		class.addRange(char, char)
+	}
+	return parser.classNode(class, start), nil
+}
+
+// -------------------------------------- Groups -------------------------------------- MARK: Groups
+
+// Parse a group (the opening parenthesis is consumed)
+func (parser *parser) parseGroup(start int) (*node, *CompileError) {
+	savedOptions := parser.options
+
+	if parser.consume("*") {
+		return parser.parseVerb(start)
+	}
+
+	if !parser.consume("?") {
+		if parser.options.noAutoCapture {
+			return parser.parseGroupBody(start, &node{typ: nodeGroup, group: -1}, savedOptions)
+		}
+		return parser.parseCapturingGroup(start, "", savedOptions)
+	}
+
+	if parser.atEnd() {
+		return nil, parser.error(parser.pos, "unrecognized character after (? or (?-")
+	}
+	switch char := parser.pattern[parser.pos]; {
+	case char == '#':
+		end := strings.IndexByte(parser.pattern[parser.pos:], ')')
+		if end < 0 {
+			return nil, parser.error(len(parser.pattern), "missing ) after (?# comment")
+		}
+		parser.pos += end + 1
+		return nil, nil
+	case char == ':':
+		parser.pos++
+		return parser.parseGroupBody(start, &node{typ: nodeGroup, group: -1}, savedOptions)
+	case char == '|':
+		parser.pos++
+		return parser.parseBranchReset(start, savedOptions)
+	case char == '>':
+		parser.pos++
+		return parser.parseGroupBody(start, &node{typ: nodeAtomic}, savedOptions)
+	case char == '=' || char == '!':
+		parser.pos++
+		return parser.parseGroupBody(start, &node{typ: nodeLookahead, negate: char == '!'}, savedOptions)
+	case parser.consume("<=") || parser.consume("<!"):
+		return parser.parseGroupBody(start, &node{typ: nodeLookbehind, negate: parser.pattern[parser.pos-1] == '!'}, savedOptions)
+	case char == '<' || char == '\'' || parser.lookingAt("P<"):
+		if char == 'P' {
+			parser.pos++
+		}
+		terminator := map[byte]byte{'<': '>', '\'': '\''}[parser.pattern[parser.pos]]
+		parser.pos++
+		name, err := parser.parseName(terminator)
+		if err != nil {
+			return nil, err
+		}
+		return parser.parseCapturingGroup(start, name, savedOptions)
+	case parser.consume("P="):
+		name, err := parser.parseName(')')
+		if err != nil {
+			return nil, err
+		}
+		return parser.backrefNode(0, name, false, start), nil
+	case parser.consume("P>") || parser.consume("&"):
+		name, err := parser.parseName(')')
+		if err != nil {
+			return nil, err
+		}
+		return parser.recursionNode(name, start)
+	case char == 'R' || char == '+' || char == '-' && parser.pos+1 < len(parser.pattern) && parser.pattern[parser.pos+1] >= '0' && parser.pattern[parser.pos+1] <= '9' || (char >= '0' && char <= '9'):
+		end := strings.IndexByte(parser.pattern[parser.pos:], ')')
+		if end < 0 {
+			return nil, parser.error(len(parser.pattern), "missing closing parenthesis")
+		}
+		reference := parser.pattern[parser.pos : parser.pos+end]
+		if _, err := strconv.Atoi(reference); err != nil && reference != "R" {
+			return nil, parser.error(parser.pos+1, "(?R (recursive pattern call) must be followed by )")
+		}
+		parser.pos += end + 1
+		return parser.recursionNode(reference, start)
+	case char == '(':
+		parser.pos++
+		return parser.parseConditional(start, savedOptions)
+	}
+	return parser.parseOptionSetting(start, savedOptions)
+}
+
+func (parser *parser) parseCapturingGroup(start int, name string, savedOptions options) (*node, *CompileError) {
+	parser.captures++
+	group := parser.captures
+	for len(parser.groupNames) <= group {
+		parser.groupNames = append(parser.groupNames, "")
+	}
+	if name != "" {
+		if existing, found := parser.names[name]; found && !parser.options.duplicateNames && existing[0] != group {
+			return nil, parser.error(parser.pos-1, "two named subpatterns have the same name (PCRE2_DUPNAMES not set)")
+		}
+		if parser.groupNames[group] == "" {
+			parser.names[name] = append(parser.names[name], group)
+		}
+		parser.groupNames[group] = name
+	}
+	return parser.parseGroupBody(start, &node{typ: nodeGroup, group: group, offset: start}, savedOptions)
+}
+
+// Parse the alternatives of the group up to the closing parenthesis
+func (parser *parser) parseGroupBody(start int, group *node, savedOptions options) (*node, *CompileError) {
+	body, err := parser.parseAlternation()
+	if err != nil {
+		return nil, err
+	}
+	if !parser.consume(")") {
+		return nil, parser.error(len(parser.pattern), "missing closing parenthesis")
+	}
+	parser.options = savedOptions
+	group.children = []*node{body}
+	if group.typ == nodeLookbehind {
+		if _, max := width(body); max < 0 {
+			return nil, parser.error(start, "length of lookbehind assertion is not limited")
+		}
+	}
+	return group, nil
+}
+
+// Parse a group like "(?|(a)|(b))" where each alternative starts with the same group number
+func (parser *parser) parseBranchReset(start int, savedOptions options) (*node, *CompileError) {
+	firstGroup := parser.captures
+	maxGroup := firstGroup
+	alternatives := []*node{}
+	for {
+		parser.captures = firstGroup
+		sequence, err := parser.parseSequence()
+		if err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, sequence)
+		maxGroup = max(maxGroup, parser.captures)
+		if !parser.consume("|") {
+			break
+		}
+	}
+	parser.captures = maxGroup
+	if !parser.consume(")") {
+		return nil, parser.error(len(parser.pattern), "missing closing parenthesis")
+	}
+	parser.options = savedOptions
+	return &node{typ: nodeGroup, group: -1, children: []*node{{typ: nodeAlternation, children: alternatives}}}, nil
+}
+
+// Parse a conditional group like "(?(1)yes|no)" (the parenthesis of the condition is consumed)
+func (parser *parser) parseConditional(start int, savedOptions options) (*node, *CompileError) {
+	condition := &condition{}
+	switch {
+	case parser.lookingAt("?=") || parser.lookingAt("?!") || parser.lookingAt("?<=") || parser.lookingAt("?<!"):
+		assertionStart := parser.pos - 1
+		assertion, err := parser.parseGroup(assertionStart)
+		if err != nil {
+			return nil, err
+		}
+		condition.assertion = assertion
+	case parser.lookingAt("<") || parser.lookingAt("'"):
+		terminator := map[byte]byte{'<': '>', '\'': '\''}[parser.pattern[parser.pos]]
+		parser.pos++
+		name, err := parser.parseName(terminator)
+		if err != nil {
+			return nil, err
+		}
+		if !parser.consume(")") {
+			return nil, parser.error(parser.pos, "malformed number or name after (?(")
+		}
+		condition.name = name
+	default:
+		end := strings.IndexByte(parser.pattern[parser.pos:], ')')
+		if end < 0 {
+			return nil, parser.error(len(parser.pattern), "missing closing parenthesis for condition")
+		}
+		reference := parser.pattern[parser.pos : parser.pos+end]
+		parser.pos += end + 1
+		number, err := strconv.Atoi(reference)
+		switch {
+		case reference == "DEFINE":
+			condition.define = true
+		case reference == "R":
+			condition.recursion = true
+		case strings.HasPrefix(reference, "R&"):
+			condition.recursion = true
+			condition.name = reference[2:]
+		case strings.HasPrefix(reference, "R") && isNumeric(reference[1:]):
+			condition.recursion = true
+			condition.group, _ = strconv.Atoi(reference[1:])
+		case err == nil && (strings.HasPrefix(reference, "-") || strings.HasPrefix(reference, "+")):
+			if number < 0 {
+				number = parser.captures + number + 1
+			} else {
+				number = parser.captures + number
+			}
+			if number <= 0 {
+				return nil, parser.error(parser.pos-1, "reference to non-existent subpattern")
+			}
+			condition.group = number
+		case err == nil:
+			condition.group = number
+		case isValidName(reference):
+			condition.name = reference
+		default:
+			return nil, parser.error(parser.pos-1, "malformed number or name after (?(")
+		}
+	}
+
+	body, err := parser.parseAlternation()
+	if err != nil {
+		return nil, err
+	}
+	if !parser.consume(")") {
+		return nil, parser.error(len(parser.pattern), "missing closing parenthesis")
+	}
+	parser.options = savedOptions
+
+	branches := []*node{body}
+	if body.typ == nodeAlternation {
+		branches = body.children
+	}
+	if len(branches) > 2 {
+		return nil, parser.error(parser.pos-1, "conditional subpattern contains more than two branches")
+	}
+	if condition.define && len(branches) > 1 {
+		return nil, parser.error(parser.pos-1, "DEFINE subpattern contains more than one branch")
+	}
+	if len(branches) == 1 {
+		branches = append(branches, &node{typ: nodeEmpty})
+	}
+	conditional := &node{typ: nodeConditional, children: branches, condition: condition, offset: start}
+	if condition.name != "" || condition.group > 0 {
+		parser.references = append(parser.references, conditional)
+	}
+	return conditional, nil
+}
+
+func isNumeric(str string) bool {
+	_, err := strconv.Atoi(str)
+	return err == nil && !strings.HasPrefix(str, "-") && !strings.HasPrefix(str, "+")
+}
+
+// Parse an option setting like "(?i)", "(?-x)", "(?^i)" or a group with options like "(?i:...)"
+func (parser *parser) parseOptionSetting(start int, savedOptions options) (*node, *CompileError) {
+	enable := true
+	if parser.consume("^") {
+		parser.options.caseless = false
+		parser.options.multiline = false
+		parser.options.dotAll = false
+		parser.options.extended = false
+		parser.options.noAutoCapture = false
+	}
+	for !parser.atEnd() {
+		char := parser.pattern[parser.pos]
+		parser.pos++
+		switch char {
+		case '-':
+			enable = false
+		case 'i':
+			parser.options.caseless = enable
+		case 'm':
+			parser.options.multiline = enable
+		case 's':
+			parser.options.dotAll = enable
+		case 'x':
+			parser.options.extended = enable
+		case 'n':
+			parser.options.noAutoCapture = enable
+		case 'U':
+			parser.options.ungreedy = enable
+		case 'J':
+			parser.options.duplicateNames = enable
+		case ')':
+			// The options apply to the rest of the enclosing group
+			return nil, nil
+		case ':':
+			return parser.parseGroupBody(start, &node{typ: nodeGroup, group: -1}, savedOptions)
+		default:
+			return nil, parser.error(parser.pos-1, "unrecognized character after (? or (?-")
+		}
+	}
+	return nil, parser.error(len(parser.pattern), "missing closing parenthesis")
+}
+
+// Parse a backtracking control verb like "(*FAIL)" (the opening "(*" is consumed)
+func (parser *parser) parseVerb(start int) (*node, *CompileError) {
+	end := strings.IndexByte(parser.pattern[parser.pos:], ')')
+	if end < 0 {
+		return nil, parser.error(len(parser.pattern), "missing closing parenthesis")
+	}
+	name := parser.pattern[parser.pos : parser.pos+end]
+	parser.pos += end + 1
+	verbs := map[string]verbType{
+		"FAIL": verbFail, "F": verbFail, "COMMIT": verbCommit, "PRUNE": verbPrune, "SKIP": verbSkip, "THEN": verbThen,
+	}
+	verb, found := verbs[name]
+	if !found {
+		return nil, parser.error(parser.pos-1, "(*VERB) not recognized or malformed")
+	}
+	return &node{typ: nodeVerb, verb: verb, offset: start}, nil
+}
+
+// Parse the options at the start of the pattern like "(*UTF)" or "(*CRLF)"
+func (parser *parser) parseStartOptions() {
+	for parser.lookingAt("(*") {
+		end := strings.IndexByte(parser.pattern[parser.pos:], ')')
+		if end < 0 {
+			return
+		}
+		name := parser.pattern[parser.pos+2 : parser.pos+end]
+		switch {
+		case name == "UTF" || name == "UTF8" || name == "UCP":
+			parser.options.utf = true
+		case name == "CR":
+			parser.newline = newlineCR
+		case name == "LF":
+			parser.newline = newlineLF
+		case name == "CRLF":
+			parser.newline = newlineCRLF
+		case name == "ANYCRLF":
+			parser.newline = newlineAnyCRLF
+		case name == "ANY":
+			parser.newline = newlineAny
+		case name == "NUL":
+			parser.newline = newlineNUL
+		case strings.HasPrefix(name, "LIMIT_MATCH="):
+			if limit, err := strconv.Atoi(name[len("LIMIT_MATCH="):]); err == nil {
+				parser.limit = limit
+			}
+		case name == "NO_AUTO_POSSESS" || name == "NO_START_OPT" || name == "NO_DOTSTAR_ANCHOR" || name == "NO_JIT" ||
+			name == "NOTEMPTY" || name == "NOTEMPTY_ATSTART" || name == "BSR_ANYCRLF" || name == "BSR_UNICODE" ||
+			strings.HasPrefix(name, "LIMIT_DEPTH=") || strings.HasPrefix(name, "LIMIT_HEAP=") || strings.HasPrefix(name, "LIMIT_RECURSION="):
+		default:
+			return
+		}
+		parser.pos += end + 1
+	}
+}
+
+// -------------------------------------- Analysis -------------------------------------- MARK: Analysis
+
+// Get the minimum and maximum number of characters matched by the node (-1 if there is no maximum)
+func width(n *node) (int, int) {
+	switch n.typ {
+	case nodeChar, nodeClass:
+		return 1, 1
+	case nodeNewline:
+		return 1, 2
+	case nodeEmpty, nodeAssertion, nodeLookahead, nodeLookbehind, nodeKeep, nodeVerb:
+		return 0, 0
+	case nodeSequence:
+		min, max := 0, 0
+		for _, child := range n.children {
+			childMin, childMax := width(child)
+			min += childMin
+			if max >= 0 {
+				max = addMax(max, childMax)
+			}
+		}
+		return min, max
+	case nodeAlternation, nodeConditional:
+		min, max := -1, 0
+		for _, child := range n.children {
+			childMin, childMax := width(child)
+			if min < 0 || childMin < min {
+				min = childMin
+			}
+			if max >= 0 && (childMax < 0 || childMax > max) {
+				max = childMax
+			}
+		}
+		return min, max
+	case nodeGroup, nodeAtomic:
+		return width(n.children[0])
+	case nodeRepeat:
+		childMin, childMax := width(n.children[0])
+		if n.max < 0 || childMax < 0 {
+			if childMax == 0 {
+				return 0, 0
+			}
+			return childMin * n.min, -1
+		}
+		return childMin * n.min, childMax * n.max
+	}
+	// Backreferences and recursions
+	return 0, -1
+}
+
+func addMax(a int, b int) int {
+	if a < 0 || b < 0 {
+		return -1
+	}
+	return a + b
+}
+
+// Resolve the names and numbers of the referenced groups
+func (parser *parser) resolveReferences() *CompileError {
+	for _, reference := range parser.references {
+		name, group := reference.name, reference.group
+		if reference.typ == nodeConditional {
+			name, group = reference.condition.name, reference.condition.group
+			if reference.condition.recursion && name == "" {
+				continue
+			}
+		}
+		if name != "" {
+			groups, found := parser.names[name]
+			if !found {
+				return parser.error(reference.offset, "reference to non-existent subpattern")
+			}
+			group = groups[0]
+		}
+		if group > parser.captures {
+			return parser.error(reference.offset, "reference to non-existent subpattern")
+		}
+		if reference.typ == nodeConditional {
+			reference.condition.group = group
+		} else {
+			reference.group = group
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package pcre
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"strings"
+	"sync"
+)
+
+const maxCachedPatterns = 4096
+
+// Compiled patterns by the pattern string with delimiters and modifiers
+var patternCache = struct {
+	sync.Mutex
+	regexes map[string]*Regex
+}{regexes: map[string]*Regex{}}
+
+// Get the compiled regex of a pattern like "/abc/i".
+// Invalid patterns are reported as warning and set the last error to PREG_INTERNAL_ERROR.
+func getRegex(functionName string, pattern string, context runtime.Context) (*Regex, bool) {
+	patternCache.Lock()
+	regex, found := patternCache.regexes[pattern]
+	patternCache.Unlock()
+	if found {
+		return regex, true
+	}
+
+	regex, message := compilePattern(pattern)
+	if regex == nil {
+		context.Interpreter.PrintError(phpError.NewWarning("%s(): %s%s", functionName, message, context.InPosition()))
+		context.Interpreter.GetExectionContext().SetPregLastError(PREG_INTERNAL_ERROR)
+		return nil, false
+	}
+
+	patternCache.Lock()
+	if len(patternCache.regexes) >= maxCachedPatterns {
+		patternCache.regexes = map[string]*Regex{}
+	}
+	patternCache.regexes[pattern] = regex
+	patternCache.Unlock()
+	return regex, true
+}
+
+// Split the pattern into the expression, the delimiters and the modifiers and compile it.
+// Returns the regex or the message of the warning.
+func compilePattern(pattern string) (*Regex, string) {
+	pos := 0
+	for pos < len(pattern) && isWhitespace(pattern[pos]) {
+		pos++
+	}
+	if pos >= len(pattern) {
+		return nil, "Empty regular expression"
+	}
+
+	delimiter := pattern[pos]
+	if isAlphanumeric(delimiter) || delimiter == '\\' || delimiter == 0 {
+		return nil, "Delimiter must not be alphanumeric, backslash, or NUL byte"
+	}
+	pos++
+	start := pos
+
+	// Bracket-style delimiters can be nested in the expression
+	endDelimiter := delimiter
+	if index := strings.IndexByte("([{<", delimiter); index >= 0 {
+		endDelimiter = ")]}>"[index]
+	}
+	depth := 1
+	for ; pos < len(pattern); pos++ {
+		char := pattern[pos]
+		if char == '\\' && pos+1 < len(pattern) {
+			pos++
+			continue
+		}
+		if char == endDelimiter && endDelimiter != delimiter {
+			depth--
+		} else if char == delimiter && endDelimiter != delimiter {
+			depth++
+		} else if char == endDelimiter {
+			depth = 0
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	if pos >= len(pattern) {
+		if endDelimiter != delimiter {
+			return nil, "No ending matching delimiter '" + string(endDelimiter) + "' found"
+		}
+		return nil, "No ending delimiter '" + string(delimiter) + "' found"
+	}
+	expression := pattern[start:pos]
+
+	options := Options{}
+	for _, modifier := range []byte(pattern[pos+1:]) {
+		switch modifier {
+		case 'i':
+			options.Caseless = true
+		case 'm':
+			options.Multiline = true
+		case 's':
+			options.DotAll = true
+		case 'x':
+			options.Extended = true
+		case 'A':
+			options.Anchored = true
+		case 'D':
+			options.DollarEndOnly = true
+		case 'U':
+			options.Ungreedy = true
+		case 'J':
+			options.DuplicateNames = true
+		case 'u':
+			options.Utf = true
+		case 'n':
+			options.NoAutoCapture = true
+		case 'S', 'X', 'r', ' ', '\n', '\r':
+		case 0:
+			return nil, "NUL byte is not a valid modifier"
+		default:
+			return nil, "Unknown modifier '" + string(modifier) + "'"
+		}
+	}
+
+	regex, err := Compile(expression, options)
+	if err != nil {
+		return nil, "Compilation failed: " + err.Error()
+	}
+	return regex, ""
+}
+
+func isWhitespace(char byte) bool {
+	return char == ' ' || (char >= '\t' && char <= '\r')
+}
+
+func isAlphanumeric(char byte) bool {
+	return (char >= '0' && char <= '9') || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+}
@@ -0,0 +1,784 @@
+package pcre
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/values"
+	"strings"
+)
+
+func Register(environment runtime.Environment) {
+	// Category: PCRE Functions
+	environment.AddNativeFunctionByRef("preg_filter", nativeFn_preg_filter, runtime.NewByRefParams(4))
+	environment.AddNativeFunction("preg_grep", nativeFn_preg_grep)
+	environment.AddNativeFunction("preg_last_error", nativeFn_preg_last_error)
+	environment.AddNativeFunction("preg_last_error_msg", nativeFn_preg_last_error_msg)
+	environment.AddNativeFunctionByRef("preg_match", nativeFn_preg_match, runtime.NewByRefParams(2))
+	environment.AddNativeFunctionByRef("preg_match_all", nativeFn_preg_match_all, runtime.NewByRefParams(2))
+	environment.AddNativeFunction("preg_quote", nativeFn_preg_quote)
+	environment.AddNativeFunctionByRef("preg_replace", nativeFn_preg_replace, runtime.NewByRefParams(4))
+	environment.AddNativeFunctionByRef("preg_replace_callback", nativeFn_preg_replace_callback, runtime.NewByRefParams(4))
+	environment.AddNativeFunctionByRef("preg_replace_callback_array", nativeFn_preg_replace_callback_array, runtime.NewByRefParams(3))
+	environment.AddNativeFunction("preg_split", nativeFn_preg_split)
+
+	// Const Category: PCRE Constants
+	// Spec: https://www.php.net/manual/en/pcre.constants.php
+	environment.AddPredefinedConstant("PREG_PATTERN_ORDER", values.NewInt(PREG_PATTERN_ORDER))
+	environment.AddPredefinedConstant("PREG_SET_ORDER", values.NewInt(PREG_SET_ORDER))
+	environment.AddPredefinedConstant("PREG_OFFSET_CAPTURE", values.NewInt(PREG_OFFSET_CAPTURE))
+	environment.AddPredefinedConstant("PREG_UNMATCHED_AS_NULL", values.NewInt(PREG_UNMATCHED_AS_NULL))
+	environment.AddPredefinedConstant("PREG_SPLIT_NO_EMPTY", values.NewInt(PREG_SPLIT_NO_EMPTY))
+	environment.AddPredefinedConstant("PREG_SPLIT_DELIM_CAPTURE", values.NewInt(PREG_SPLIT_DELIM_CAPTURE))
+	environment.AddPredefinedConstant("PREG_SPLIT_OFFSET_CAPTURE", values.NewInt(PREG_SPLIT_OFFSET_CAPTURE))
+	environment.AddPredefinedConstant("PREG_GREP_INVERT", values.NewInt(PREG_GREP_INVERT))
+	environment.AddPredefinedConstant("PREG_NO_ERROR", values.NewInt(PREG_NO_ERROR))
+	environment.AddPredefinedConstant("PREG_INTERNAL_ERROR", values.NewInt(PREG_INTERNAL_ERROR))
+	environment.AddPredefinedConstant("PREG_BACKTRACK_LIMIT_ERROR", values.NewInt(PREG_BACKTRACK_LIMIT_ERROR))
+	environment.AddPredefinedConstant("PREG_RECURSION_LIMIT_ERROR", values.NewInt(PREG_RECURSION_LIMIT_ERROR))
+	environment.AddPredefinedConstant("PREG_BAD_UTF8_ERROR", values.NewInt(PREG_BAD_UTF8_ERROR))
+	environment.AddPredefinedConstant("PREG_BAD_UTF8_OFFSET_ERROR", values.NewInt(PREG_BAD_UTF8_OFFSET_ERROR))
+	environment.AddPredefinedConstant("PREG_JIT_STACKLIMIT_ERROR", values.NewInt(PREG_JIT_STACKLIMIT_ERROR))
+	environment.AddPredefinedConstant("PCRE_VERSION", values.NewStr("10.44 2024-06-07"))
+	environment.AddPredefinedConstant("PCRE_VERSION_MAJOR", values.NewInt(10))
+	environment.AddPredefinedConstant("PCRE_VERSION_MINOR", values.NewInt(44))
+	environment.AddPredefinedConstant("PCRE_JIT_SUPPORT", values.NewBool(false))
+}
+
+const (
+	PREG_PATTERN_ORDER        int64 = 1
+	PREG_SET_ORDER            int64 = 2
+	PREG_OFFSET_CAPTURE       int64 = 256
+	PREG_UNMATCHED_AS_NULL    int64 = 512
+	PREG_SPLIT_NO_EMPTY       int64 = 1
+	PREG_SPLIT_DELIM_CAPTURE  int64 = 2
+	PREG_SPLIT_OFFSET_CAPTURE int64 = 4
+	PREG_GREP_INVERT          int64 = 1
+
+	PREG_NO_ERROR              int64 = 0
+	PREG_INTERNAL_ERROR        int64 = 1
+	PREG_BACKTRACK_LIMIT_ERROR int64 = 2
+	PREG_RECURSION_LIMIT_ERROR int64 = 3
+	PREG_BAD_UTF8_ERROR        int64 = 4
+	PREG_BAD_UTF8_OFFSET_ERROR int64 = 5
+	PREG_JIT_STACKLIMIT_ERROR  int64 = 6
+)
+
+var errorMessages = map[int64]string{
+	PREG_NO_ERROR:              "No error",
+	PREG_INTERNAL_ERROR:        "Internal error",
+	PREG_BACKTRACK_LIMIT_ERROR: "Backtrack limit exhausted",
+	PREG_RECURSION_LIMIT_ERROR: "Recursion limit exhausted",
+	PREG_BAD_UTF8_ERROR:        "Malformed UTF-8 characters, possibly incorrectly encoded",
+	PREG_BAD_UTF8_OFFSET_ERROR: "The offset did not correspond to the beginning of a valid UTF-8 code point",
+	PREG_JIT_STACKLIMIT_ERROR:  "JIT stack limit exhausted",
+}
+
+// -------------------------------------- Matching -------------------------------------- MARK: Matching
+
+// Search the subject like the PHP functions: Invalid UTF-8 is only checked for the first search of a subject.
+type searcher struct {
+	regex   *Regex
+	subject string
+	context runtime.Context
+	checked bool
+}
+
+func newSearcher(regex *Regex, subject string, context runtime.Context) *searcher {
+	return &searcher{regex: regex, subject: subject, context: context}
+}
+
+// Search starting at the offset. Returns the captures (nil if there is no match) or false on errors.
+// Errors are stored as the last error.
+func (searcher *searcher) search(offset int, notEmptyAtStart bool, anchored bool) ([]int, bool) {
+	ini := searcher.context.Interpreter.GetIni()
+	captures, err := searcher.regex.Match(searcher.subject, offset, MatchOptions{
+		NotEmptyAtStart: notEmptyAtStart,
+		Anchored:        anchored,
+		NoUtfCheck:      searcher.checked,
+		BacktrackLimit:  int(ini.GetInt("pcre.backtrack_limit")),
+		RecursionLimit:  int(ini.GetInt("pcre.recursion_limit")),
+	})
+	searcher.checked = true
+	if err != NoError {
+		searcher.context.Interpreter.GetExectionContext().SetPregLastError(int64(err))
+		return nil, false
+	}
+	return captures, true
+}
+
+// Call the function for each match of a global search like preg_match_all.
+// Empty matches are retried at the same position as non-empty match before the search advances.
+// Returns false if the search stopped with an error.
+func (searcher *searcher) searchAll(offset int, onMatch func(captures []int) bool) bool {
+	captures, ok := searcher.search(offset, false, false)
+	for ok && captures != nil {
+		if !onMatch(captures) {
+			return true
+		}
+		offset = captures[1]
+		if captures[0] != captures[1] {
+			captures, ok = searcher.search(offset, false, false)
+			continue
+		}
+		captures, ok = searcher.search(offset, true, true)
+		if !ok || captures != nil {
+			continue
+		}
+		if offset >= len(searcher.subject) {
+			return true
+		}
+		offset += searcher.regex.UnitLength(searcher.subject, offset)
+		captures, ok = searcher.search(offset, false, false)
+	}
+	return ok
+}
+
+// Get the number of groups up to the last set group (like the result of pcre2_match)
+func setGroupCount(captures []int) int {
+	count := len(captures) / 2
+	for count > 1 && captures[2*(count-1)] < 0 {
+		count--
+	}
+	return count
+}
+
+// Get the value of a group: the string, "" or null for unset groups (with PREG_UNMATCHED_AS_NULL)
+// and with PREG_OFFSET_CAPTURE an array with the string and the offset.
+func groupValue(subject string, captures []int, group int, flags int64) values.RuntimeValue {
+	var value values.RuntimeValue
+	start := -1
+	if 2*group < len(captures) {
+		start = captures[2*group]
+	}
+	switch {
+	case start >= 0:
+		value = values.NewStr(subject[start:captures[2*group+1]])
+	case flags&PREG_UNMATCHED_AS_NULL != 0:
+		value = values.NewNull()
+	default:
+		value = values.NewStr("")
+	}
+	if flags&PREG_OFFSET_CAPTURE == 0 {
+		return value
+	}
+	return values.NewArrayFromSlice([]values.RuntimeValue{value, values.NewInt(int64(start))})
+}
+
+// Create the array of the groups of a match like the $matches of preg_match
+func matchArray(regex *Regex, subject string, captures []int, flags int64) *values.Array {
+	result := values.NewArray()
+	count := setGroupCount(captures)
+	// With PREG_UNMATCHED_AS_NULL the trailing unset groups are added as well
+	if flags&PREG_UNMATCHED_AS_NULL != 0 {
+		count = regex.GroupCount() + 1
+	}
+	names := regex.GroupNames()
+	for group := 0; group < count; group++ {
+		value := groupValue(subject, captures, group, flags)
+		if names[group] != "" {
+			result.SetElement(values.NewStr(names[group]), value)
+		}
+		result.SetElement(nil, value)
+	}
+	return result
+}
+
+// Get the subject offset from an offset argument that can be negative
+func subjectOffset(offset int64, subject string) int64 {
+	if offset < 0 {
+		offset += int64(len(subject))
+		if offset < 0 {
+			return 0
+		}
+	}
+	return offset
+}
+
+// -------------------------------------- preg_grep -------------------------------------- MARK: preg_grep
+
+func nativeFn_preg_grep(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.preg-grep.php
+	args, err := funcParamValidator.NewValidator("preg_grep").
+		AddParam("$pattern", []string{"string"}, nil).
+		AddParam("$array", []string{"array"}, nil).
+		AddParam("$flags", []string{"int"}, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	regex, ok := getRegex("preg_grep", args[0].(*values.Str).Value, context)
+	if !ok {
+		return values.NewBool(false), nil
+	}
+	context.Interpreter.GetExectionContext().SetPregLastError(PREG_NO_ERROR)
+
+	array := args[1].(*values.Array)
+	invert := args[2].(*values.Int).Value&PREG_GREP_INVERT != 0
+	result := values.NewArray()
+	for _, key := range array.Keys {
+		slot, _ := array.GetElement(key)
+		subject, err := variableHandling.StrVal(slot.Value)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		captures, ok := newSearcher(regex, subject, context).search(0, false, false)
+		if !ok {
+			break
+		}
+		if (captures != nil) != invert {
+			result.SetElement(key, slot.Value)
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- preg_last_error -------------------------------------- MARK: preg_last_error
+
+func nativeFn_preg_last_error(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.preg-last-error.php
+	_, err := funcParamValidator.NewValidator("preg_last_error").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(context.Interpreter.GetExectionContext().GetPregLastError()), nil
+}
+
+// -------------------------------------- preg_last_error_msg -------------------------------------- MARK: preg_last_error_msg
+
+func nativeFn_preg_last_error_msg(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.preg-last-error-msg.php
+	_, err := funcParamValidator.NewValidator("preg_last_error_msg").Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	message, found := errorMessages[context.Interpreter.GetExectionContext().GetPregLastError()]
+	if !found {
+		message = "Unknown error"
+	}
+	return values.NewStr(message), nil
+}
+
+// -------------------------------------- preg_match -------------------------------------- MARK: preg_match
+
+func nativeFn_preg_match(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.preg-match.php
+	return pregMatch("preg_match", args, context, false)
+}
+
+// -------------------------------------- preg_match_all -------------------------------------- MARK: preg_match_all
+
+func nativeFn_preg_match_all(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.preg-match-all.php
+	return pregMatch("preg_match_all", args, context, true)
+}
+
+func pregMatch(functionName string, args []values.RuntimeValue, context runtime.Context, global bool) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$pattern", []string{"string"}, nil).
+		AddParam("$subject", []string{"string"}, nil).
+		AddParam("$matches", []string{"mixed"}, values.NewNull()).
+		AddParam("$flags", []string{"int"}, values.NewInt(0)).
+		AddParam("$offset", []string{"int"}, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	regex, ok := getRegex(functionName, args[0].(*values.Str).Value, context)
+	if !ok {
+		return values.NewBool(false), nil
+	}
+
+	subject := args[1].(*values.Str).Value
+	flags := args[3].(*values.Int).Value
+	order := flags & 0xff
+	if global && order == 0 {
+		order = PREG_PATTERN_ORDER
+	}
+	if (global && order != PREG_PATTERN_ORDER && order != PREG_SET_ORDER) || (!global && order != 0) {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: %s(): Argument #4 ($flags) must be a PREG_* constant", functionName)
+	}
+	flags &= PREG_OFFSET_CAPTURE | PREG_UNMATCHED_AS_NULL
+
+	context.Interpreter.GetExectionContext().SetPregLastError(PREG_NO_ERROR)
+	context.SetRefArg(2, values.NewArray())
+	offset := subjectOffset(args[4].(*values.Int).Value, subject)
+	if offset > int64(len(subject)) {
+		context.Interpreter.GetExectionContext().SetPregLastError(PREG_INTERNAL_ERROR)
+		return values.NewBool(false), nil
+	}
+
+	searcher := newSearcher(regex, subject, context)
+	if !global {
+		captures, ok := searcher.search(int(offset), false, false)
+		if !ok {
+			return values.NewBool(false), nil
+		}
+		if captures == nil {
+			return values.NewInt(0), nil
+		}
+		context.SetRefArg(2, matchArray(regex, subject, captures, flags))
+		return values.NewInt(1), nil
+	}
+
+	matchCount := 0
+	sets := values.NewArray()
+	groupCount := regex.GroupCount() + 1
+	patternOrder := make([]*values.Array, groupCount)
+	for group := range patternOrder {
+		patternOrder[group] = values.NewArray()
+	}
+	ok = searcher.searchAll(int(offset), func(captures []int) bool {
+		matchCount++
+		if order == PREG_SET_ORDER {
+			sets.SetElement(nil, matchArray(regex, subject, captures, flags))
+			return true
+		}
+		for group := 0; group < groupCount; group++ {
+			patternOrder[group].SetElement(nil, groupValue(subject, captures, group, flags))
+		}
+		return true
+	})
+
+	if order == PREG_PATTERN_ORDER {
+		names := regex.GroupNames()
+		for group, matches := range patternOrder {
+			if names[group] != "" {
+				sets.SetElement(values.NewStr(names[group]), matches)
+			}
+			sets.SetElement(nil, matches)
+		}
+	}
+	context.SetRefArg(2, sets)
+	if !ok {
+		return values.NewBool(false), nil
+	}
+	return values.NewInt(int64(matchCount)), nil
+}
+
+// -------------------------------------- preg_quote -------------------------------------- MARK: preg_quote
+
+func nativeFn_preg_quote(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.preg-quote.php
+	args, err := funcParamValidator.NewValidator("preg_quote").
+		AddParam("$str", []string{"string"}, nil).
+		AddParam("$delimiter", []string{"string", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	delimiter := ""
+	if args[1].GetType() == values.StrValue && args[1].(*values.Str).Value != "" {
+		delimiter = args[1].(*values.Str).Value[:1]
+	}
+
+	var builder strings.Builder
+	for _, char := range []byte(args[0].(*values.Str).Value) {
+		switch {
+		case char == 0:
+			builder.WriteString(`\000`)
+			continue
+		case strings.IndexByte(`.\+*?[^]$(){}=!<>|:-#`, char) >= 0 || (delimiter != "" && char == delimiter[0]):
+			builder.WriteByte('\\')
+		}
+		builder.WriteByte(char)
+	}
+	return values.NewStr(builder.String()), nil
+}
+
+// -------------------------------------- preg_replace -------------------------------------- MARK: preg_replace
+
+func nativeFn_preg_replace(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.preg-replace.php
+	return pregReplace("preg_replace", args, context, false)
+}
+
+// -------------------------------------- preg_filter -------------------------------------- MARK: preg_filter
+
+func nativeFn_preg_filter(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.preg-filter.php
+	return pregReplace("preg_filter", args, context, true)
+}
+
+// Replacement of the matches: a string with references like "$1" or a callback
+type replacement struct {
+	template string
+	callback values.RuntimeValue
+	flags    int64
+}
+
+func pregReplace(functionName string, args []values.RuntimeValue, context runtime.Context, filter bool) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$pattern", []string{"array", "string"}, nil).
+		AddParam("$replacement", []string{"array", "string"}, nil).
+		AddParam("$subject", []string{"array", "string"}, nil).
+		AddParam("$limit", []string{"int"}, values.NewInt(-1)).
+		AddParam("$count", []string{"mixed"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	pattern, replacementArg := args[0], args[1]
+	if replacementArg.GetType() == values.ArrayValue && pattern.GetType() != values.ArrayValue {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught TypeError: %s(): Argument #1 ($pattern) must be of type array when argument #2 ($replacement) is an array, string given", functionName,
+		)
+	}
+
+	patterns, err := toStrings(pattern)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	replacements := make([]replacement, len(patterns))
+	if replacementArg.GetType() == values.StrValue {
+		for i := range replacements {
+			replacements[i].template = replacementArg.(*values.Str).Value
+		}
+	} else {
+		templates, err := toStrings(replacementArg)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		// Missing replacements are empty strings
+		for i := range replacements {
+			if i < len(templates) {
+				replacements[i].template = templates[i]
+			}
+		}
+	}
+
+	return replaceInSubjects(functionName, patterns, replacements, args[2], args[3].(*values.Int).Value, 4, filter, context)
+}
+
+// Get the string values of a string or array argument
+func toStrings(value values.RuntimeValue) ([]string, phpError.Error) {
+	if value.GetType() == values.StrValue {
+		return []string{value.(*values.Str).Value}, nil
+	}
+	array := value.(*values.Array)
+	result := make([]string, len(array.Keys))
+	for i, key := range array.Keys {
+		slot, _ := array.GetElement(key)
+		str, err := variableHandling.StrVal(slot.Value)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = str
+	}
+	return result, nil
+}
+
+// Replace the patterns in the subject (string or array) and set the count argument.
+// With filter only the subjects with matches are returned.
+func replaceInSubjects(
+	functionName string, patterns []string, replacements []replacement, subject values.RuntimeValue,
+	limit int64, countArg int, filter bool, context runtime.Context,
+) (values.RuntimeValue, phpError.Error) {
+	context.Interpreter.GetExectionContext().SetPregLastError(PREG_NO_ERROR)
+	count := int64(0)
+
+	if subject.GetType() != values.ArrayValue {
+		subjectStr, err := variableHandling.StrVal(subject)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		result, replaced, ok, err := replaceInSubject(functionName, patterns, replacements, subjectStr, limit, context)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		count += replaced
+		context.SetRefArg(countArg, values.NewInt(count))
+		if !ok || (filter && replaced == 0) {
+			return values.NewNull(), nil
+		}
+		return values.NewStr(result), nil
+	}
+
+	array := subject.(*values.Array)
+	resultArray := values.NewArray()
+	for _, key := range array.Keys {
+		slot, _ := array.GetElement(key)
+		subjectStr, err := variableHandling.StrVal(slot.Value)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		result, replaced, ok, err := replaceInSubject(functionName, patterns, replacements, subjectStr, limit, context)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		count += replaced
+		if ok && (!filter || replaced > 0) {
+			resultArray.SetElement(key, values.NewStr(result))
+		}
+	}
+	context.SetRefArg(countArg, values.NewInt(count))
+	return resultArray, nil
+}
+
+// Replace each pattern in the subject. Returns the result, the number of replacements and false on errors.
+func replaceInSubject(
+	functionName string, patterns []string, replacements []replacement, subject string, limit int64, context runtime.Context,
+) (string, int64, bool, phpError.Error) {
+	count := int64(0)
+	for i, pattern := range patterns {
+		regex, ok := getRegex(functionName, pattern, context)
+		if !ok {
+			return "", count, false, nil
+		}
+
+		var builder strings.Builder
+		lastEnd := 0
+		remaining := limit
+		var callbackErr phpError.Error
+		searcher := newSearcher(regex, subject, context)
+		ok = searcher.searchAll(0, func(captures []int) bool {
+			if remaining == 0 {
+				return false
+			}
+			if remaining > 0 {
+				remaining--
+			}
+			count++
+			builder.WriteString(subject[lastEnd:captures[0]])
+			replaced, err := replacements[i].apply(regex, subject, captures, context)
+			if err != nil {
+				callbackErr = err
+				return false
+			}
+			builder.WriteString(replaced)
+			lastEnd = captures[1]
+			return true
+		})
+		if callbackErr != nil {
+			return "", count, false, callbackErr
+		}
+		if !ok {
+			return "", count, false, nil
+		}
+		builder.WriteString(subject[lastEnd:])
+		subject = builder.String()
+	}
+	return subject, count, true, nil
+}
+
+// Get the replacement of the match
+func (replacement replacement) apply(regex *Regex, subject string, captures []int, context runtime.Context) (string, phpError.Error) {
+	if replacement.callback != nil {
+		matches := matchArray(regex, subject, captures, replacement.flags)
+		result, err := context.Interpreter.CallFunction(replacement.callback, []values.RuntimeValue{matches}, context.Env)
+		if err != nil {
+			return "", err
+		}
+		return variableHandling.StrVal(result)
+	}
+
+	// References are written as \n, $n or ${n} (n is 0 to 99). "\\" and "\$" escape the backslash or dollar.
+	template := replacement.template
+	count := setGroupCount(captures)
+	var builder strings.Builder
+	var last byte
+	for pos := 0; pos < len(template); {
+		char := template[pos]
+		if char == '\\' || char == '$' {
+			if last == '\\' {
+				// Replace the escaping backslash
+				result := builder.String()
+				builder.Reset()
+				builder.WriteString(result[:len(result)-1])
+				builder.WriteByte(char)
+				pos++
+				last = 0
+				continue
+			}
+			if group, length, ok := parseReference(template[pos:]); ok {
+				if group < count && captures[2*group] >= 0 {
+					builder.WriteString(subject[captures[2*group]:captures[2*group+1]])
+				}
+				pos += length
+				continue
+			}
+		}
+		builder.WriteByte(char)
+		last = char
+		pos++
+	}
+	return builder.String(), nil
+}
+
+// Parse a reference like "\1", "$12" or "${1}". Returns the group and the length of the reference.
+func parseReference(str string) (int, int, bool) {
+	pos := 1
+	inBraces := str[0] == '$' && strings.HasPrefix(str[1:], "{")
+	if inBraces {
+		pos++
+	}
+	isDigit := func(pos int) bool { return pos < len(str) && str[pos] >= '0' && str[pos] <= '9' }
+	if !isDigit(pos) {
+		return 0, 0, false
+	}
+	group := int(str[pos] - '0')
+	pos++
+	if isDigit(pos) {
+		group = group*10 + int(str[pos]-'0')
+		pos++
+	}
+	if inBraces {
+		if pos >= len(str) || str[pos] != '}' {
+			return 0, 0, false
+		}
+		pos++
+	}
+	return group, pos, true
+}
+
+// -------------------------------------- preg_replace_callback -------------------------------------- MARK: preg_replace_callback
+
+func nativeFn_preg_replace_callback(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.preg-replace-callback.php
+	args, err := funcParamValidator.NewValidator("preg_replace_callback").
+		AddParam("$pattern", []string{"array", "string"}, nil).
+		AddParam("$callback", []string{"mixed"}, nil).
+		AddParam("$subject", []string{"array", "string"}, nil).
+		AddParam("$limit", []string{"int"}, values.NewInt(-1)).
+		AddParam("$count", []string{"mixed"}, values.NewNull()).
+		AddParam("$flags", []string{"int"}, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if err := context.ValidateCallback("preg_replace_callback", 2, "$callback", args[1], false); err != nil {
+		return values.NewVoid(), err
+	}
+
+	patterns, err := toStrings(args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	replacements := make([]replacement, len(patterns))
+	for i := range replacements {
+		replacements[i] = replacement{callback: args[1], flags: args[5].(*values.Int).Value}
+	}
+	return replaceInSubjects("preg_replace_callback", patterns, replacements, args[2], args[3].(*values.Int).Value, 4, false, context)
+}
+
+// -------------------------------------- preg_replace_callback_array -------------------------------------- MARK: preg_replace_callback_array
+
+func nativeFn_preg_replace_callback_array(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.preg-replace-callback-array.php
+	args, err := funcParamValidator.NewValidator("preg_replace_callback_array").
+		AddParam("$pattern", []string{"array"}, nil).
+		AddParam("$subject", []string{"array", "string"}, nil).
+		AddParam("$limit", []string{"int"}, values.NewInt(-1)).
+		AddParam("$count", []string{"mixed"}, values.NewNull()).
+		AddParam("$flags", []string{"int"}, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	patternArray := args[0].(*values.Array)
+	patterns := []string{}
+	replacements := []replacement{}
+	for _, key := range patternArray.Keys {
+		if key.GetType() != values.StrValue {
+			return values.NewVoid(), phpError.NewError(
+				"Uncaught TypeError: preg_replace_callback_array(): Argument #1 ($pattern) must contain only string patterns as keys",
+			)
+		}
+		slot, _ := patternArray.GetElement(key)
+		if _, _, ok := context.Interpreter.IsCallable(slot.Value, false, context.Env); !ok {
+			return values.NewVoid(), phpError.NewError(
+				"Uncaught TypeError: preg_replace_callback_array(): Argument #1 ($pattern) must contain only valid callbacks",
+			)
+		}
+		patterns = append(patterns, key.(*values.Str).Value)
+		replacements = append(replacements, replacement{callback: slot.Value, flags: args[4].(*values.Int).Value})
+	}
+	return replaceInSubjects("preg_replace_callback_array", patterns, replacements, args[1], args[2].(*values.Int).Value, 3, false, context)
+}
+
+// -------------------------------------- preg_split -------------------------------------- MARK: preg_split
+
+func nativeFn_preg_split(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.preg-split.php
+	args, err := funcParamValidator.NewValidator("preg_split").
+		AddParam("$pattern", []string{"string"}, nil).
+		AddParam("$subject", []string{"string"}, nil).
+		AddParam("$limit", []string{"int", "null"}, values.NewInt(-1)).
+		AddParam("$flags", []string{"int"}, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	regex, ok := getRegex("preg_split", args[0].(*values.Str).Value, context)
+	if !ok {
+		return values.NewBool(false), nil
+	}
+	context.Interpreter.GetExectionContext().SetPregLastError(PREG_NO_ERROR)
+
+	subject := args[1].(*values.Str).Value
+	limit := int64(-1)
+	if args[2].GetType() == values.IntValue {
+		limit = args[2].(*values.Int).Value
+	}
+	// A limit of 0 means no limit
+	if limit == 0 {
+		limit = -1
+	}
+	flags := args[3].(*values.Int).Value
+	noEmpty := flags&PREG_SPLIT_NO_EMPTY != 0
+	delimCapture := flags&PREG_SPLIT_DELIM_CAPTURE != 0
+	offsetCapture := flags&PREG_SPLIT_OFFSET_CAPTURE != 0
+
+	result := values.NewArray()
+	addPiece := func(start int, end int) {
+		piece := values.RuntimeValue(values.NewStr(subject[start:end]))
+		if offsetCapture {
+			piece = values.NewArrayFromSlice([]values.RuntimeValue{piece, values.NewInt(int64(start))})
+		}
+		result.SetElement(nil, piece)
+	}
+
+	lastEnd := 0
+	if limit == -1 || limit > 1 {
+		searcher := newSearcher(regex, subject, context)
+		ok = searcher.searchAll(0, func(captures []int) bool {
+			if !noEmpty || captures[0] != lastEnd {
+				addPiece(lastEnd, captures[0])
+				if limit != -1 {
+					limit--
+				}
+			}
+			if delimCapture {
+				for group := 1; group < setGroupCount(captures); group++ {
+					start, end := captures[2*group], captures[2*group+1]
+					if start < 0 {
+						start, end = 0, 0
+					}
+					if !noEmpty || start != end {
+						addPiece(start, end)
+					}
+				}
+			}
+			lastEnd = captures[1]
+			return limit == -1 || limit > 1
+		})
+		if !ok {
+			return values.NewBool(false), nil
+		}
+	}
+
+	if !noEmpty || lastEnd < len(subject) {
+		addPiece(lastEnd, len(subject))
+	}
+	return result, nil
+}
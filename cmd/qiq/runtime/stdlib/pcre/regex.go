@@ -0,0 +1,357 @@
+package pcre
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Compiled regular expression with the syntax and semantics of PCRE2.
+// A regex can be used concurrently because the match state is kept in a separate matcher.
+type Regex struct {
+	options    options
+	newline    newlineType
+	groupCount int
+	groupNames []string
+	// Match limit set in the pattern with (*LIMIT_MATCH=n)
+	limit int
+	// Whole pattern and the capturing groups (index is the group number) for subroutine calls
+	groups  []matchFn
+	program matchFn
+	// The pattern can only match at the start offset
+	startAnchored bool
+	// Literal prefix that each match starts with
+	prefix string
+}
+
+// Options of a regular expression given by the pattern modifiers
+type Options struct {
+	Caseless       bool // i
+	Multiline      bool // m
+	DotAll         bool // s
+	Extended       bool // x
+	Anchored       bool // A
+	DollarEndOnly  bool // D
+	Ungreedy       bool // U
+	DuplicateNames bool // J
+	Utf            bool // u
+	NoAutoCapture  bool // n
+}
+
+// Options of a single match
+type MatchOptions struct {
+	// An empty string at the start offset is not a match
+	NotEmptyAtStart bool
+	// Only match at the start offset
+	Anchored bool
+	// The subject is known to be valid UTF-8
+	NoUtfCheck     bool
+	BacktrackLimit int
+	RecursionLimit int
+}
+
+// Errors of a match (the values are the PREG_*_ERROR codes)
+type MatchError int
+
+const (
+	NoError             MatchError = 0
+	InternalError       MatchError = 1
+	BacktrackLimitError MatchError = 2
+	RecursionLimitError MatchError = 3
+	BadUtf8Error        MatchError = 4
+	BadUtf8OffsetError  MatchError = 5
+)
+
+// Compile the pattern (without delimiters and modifiers)
+func Compile(pattern string, regexOptions Options) (*Regex, *CompileError) {
+	parser := &parser{
+		pattern: pattern,
+		options: options{
+			caseless: regexOptions.Caseless, multiline: regexOptions.Multiline, dotAll: regexOptions.DotAll,
+			extended: regexOptions.Extended, anchored: regexOptions.Anchored, dollarEndOnly: regexOptions.DollarEndOnly,
+			ungreedy: regexOptions.Ungreedy, duplicateNames: regexOptions.DuplicateNames, utf: regexOptions.Utf,
+			noAutoCapture: regexOptions.NoAutoCapture,
+		},
+		groupNames: []string{""},
+		names:      map[string][]int{},
+	}
+	parser.parseStartOptions()
+	if parser.options.utf {
+		if offset, message := findInvalidUtf8(pattern); offset >= 0 {
+			return nil, &CompileError{Message: message, Offset: offset}
+		}
+	}
+
+	root, err := parser.parseAlternation()
+	if err != nil {
+		return nil, err
+	}
+	if !parser.atEnd() {
+		return nil, parser.error(parser.pos, "unmatched closing parenthesis")
+	}
+	if err := parser.resolveReferences(); err != nil {
+		return nil, err
+	}
+
+	regex := &Regex{
+		options:    parser.options,
+		newline:    parser.newline,
+		groupCount: parser.captures,
+		groupNames: parser.groupNames,
+		limit:      parser.limit,
+		groups:     make([]matchFn, parser.captures+1),
+	}
+	// Restore the options of the pattern that are changed by inline settings
+	regex.options.utf = parser.options.utf
+	regex.options.anchored = regexOptions.Anchored
+	compiler := &compiler{regex: regex}
+	regex.program = compiler.compile(root)
+	regex.groups[0] = regex.program
+	regex.startAnchored = regexOptions.Anchored || isStartAnchored(root)
+	if !regexOptions.Caseless {
+		regex.prefix = literalPrefix(root, regex.options.utf)
+	}
+	return regex, nil
+}
+
+// Get the number of capturing groups
+func (regex *Regex) GroupCount() int { return regex.groupCount }
+
+// Get the names of the capturing groups (index is the group number, empty for unnamed groups)
+func (regex *Regex) GroupNames() []string { return regex.groupNames }
+
+// Check if the pattern is compiled in the UTF-8 mode
+func (regex *Regex) IsUtf() bool { return regex.options.utf }
+
+// Get the number of bytes to advance after an empty match at the position
+func (regex *Regex) UnitLength(subject string, pos int) int {
+	if pos >= len(subject) {
+		return 1
+	}
+	if regex.newline.isCRLFAware() && strings.HasPrefix(subject[pos:], "\r\n") {
+		return 2
+	}
+	if regex.options.utf {
+		_, size := utf8.DecodeRuneInString(subject[pos:])
+		return size
+	}
+	return 1
+}
+
+// Search the subject starting at the offset.
+// Returns the start and end offsets of the match and the groups (-1 for unset groups) or nil if there is no match.
+func (regex *Regex) Match(subject string, offset int, matchOptions MatchOptions) ([]int, MatchError) {
+	if regex.options.utf && !matchOptions.NoUtfCheck {
+		if !utf8.ValidString(subject) {
+			return nil, BadUtf8Error
+		}
+		if offset < len(subject) && !utf8.RuneStart(subject[offset]) {
+			return nil, BadUtf8OffsetError
+		}
+	}
+
+	limit := matchOptions.BacktrackLimit
+	if regex.limit > 0 && (limit <= 0 || regex.limit < limit) {
+		limit = regex.limit
+	}
+	matcher := &matcher{
+		regex:           regex,
+		input:           subject,
+		captures:        make([]int, 2*(regex.groupCount+1)),
+		searchStart:     offset,
+		notEmptyAtStart: matchOptions.NotEmptyAtStart,
+		limit:           limit,
+		recursionLimit:  matchOptions.RecursionLimit,
+	}
+
+	anchored := matchOptions.Anchored || regex.startAnchored
+	for start := offset; start <= len(subject); {
+		if !anchored && regex.prefix != "" {
+			index := strings.Index(subject[start:], regex.prefix)
+			if index < 0 {
+				return nil, NoError
+			}
+			start += index
+		}
+
+		if matcher.tryAt(start) {
+			return matcher.captures, NoError
+		}
+		if matcher.err != NoError {
+			return nil, matcher.err
+		}
+
+		next := start + regex.UnitLength(subject, start)
+		switch matcher.verb {
+		case verbCommit:
+			return nil, NoError
+		case verbSkip:
+			if matcher.skipTo > start {
+				next = matcher.skipTo
+			}
+		}
+		if anchored {
+			break
+		}
+		start = next
+	}
+	return nil, NoError
+}
+
+// Find the first invalid UTF-8 sequence. Returns its offset (-1 if there is none) and the PCRE2 error message.
+func findInvalidUtf8(str string) (int, string) {
+	for pos := 0; pos < len(str); {
+		r, size := utf8.DecodeRuneInString(str[pos:])
+		if r == utf8.RuneError && size == 1 {
+			if str[pos]&0xC0 == 0x80 {
+				return pos, "UTF-8 error: isolated byte with 0x80 bit set"
+			}
+			return pos, "UTF-8 error: invalid UTF-8 string"
+		}
+		pos += size
+	}
+	return -1, ""
+}
+
+// -------------------------------------- Newlines -------------------------------------- MARK: Newlines
+
+type newlineType int
+
+const (
+	newlineLF newlineType = iota
+	newlineCR
+	newlineCRLF
+	newlineAnyCRLF
+	newlineAny
+	newlineNUL
+)
+
+func (newline newlineType) isCRLFAware() bool {
+	return newline == newlineCRLF || newline == newlineAnyCRLF || newline == newlineAny
+}
+
+// Check if the character is a newline (or the start of a newline for CRLF)
+func (newline newlineType) isNewlineChar(r rune) bool {
+	switch newline {
+	case newlineCR, newlineCRLF:
+		return r == '\r'
+	case newlineAnyCRLF:
+		return r == '\r' || r == '\n'
+	case newlineAny:
+		return isVerticalSpace(true)(r)
+	case newlineNUL:
+		return r == 0
+	}
+	return r == '\n'
+}
+
+// Get the length of the newline at the position (0 if there is none)
+func (newline newlineType) lengthAt(input string, pos int) int {
+	if pos >= len(input) {
+		return 0
+	}
+	if newline == newlineCRLF {
+		if strings.HasPrefix(input[pos:], "\r\n") {
+			return 2
+		}
+		return 0
+	}
+	if newline.isCRLFAware() && strings.HasPrefix(input[pos:], "\r\n") {
+		return 2
+	}
+	r, size := utf8.DecodeRuneInString(input[pos:])
+	if r == utf8.RuneError {
+		r, size = rune(input[pos]), 1
+	}
+	if newline.isNewlineChar(r) {
+		return size
+	}
+	return 0
+}
+
+// Check if a newline ends at the position
+func (newline newlineType) endsAt(input string, pos int) bool {
+	if pos == 0 {
+		return false
+	}
+	switch newline {
+	case newlineCRLF:
+		return pos >= 2 && input[pos-2:pos] == "\r\n"
+	case newlineLF:
+		return input[pos-1] == '\n'
+	case newlineCR:
+		return input[pos-1] == '\r'
+	case newlineNUL:
+		return input[pos-1] == 0
+	}
+	// The CR of a CRLF is not a newline on its own
+	if input[pos-1] == '\r' && pos < len(input) && input[pos] == '\n' {
+		return false
+	}
+	r, _ := utf8.DecodeLastRuneInString(input[:pos])
+	return newline.isNewlineChar(r)
+}
+
+// -------------------------------------- Analysis -------------------------------------- MARK: Analysis
+
+// Check if each alternative of the pattern starts with \A, \G or ^ (without multiline mode)
+func isStartAnchored(n *node) bool {
+	switch n.typ {
+	case nodeAssertion:
+		return n.assertion == assertSubjectStart || n.assertion == assertSearchStart || (n.assertion == assertLineStart && !n.multiline)
+	case nodeSequence:
+		return len(n.children) > 0 && isStartAnchored(n.children[0])
+	case nodeAlternation:
+		for _, child := range n.children {
+			if !isStartAnchored(child) {
+				return false
+			}
+		}
+		return true
+	case nodeGroup, nodeAtomic:
+		return isStartAnchored(n.children[0])
+	}
+	return false
+}
+
+// Get the literal characters each match must start with
+func literalPrefix(n *node, utf bool) string {
+	var builder strings.Builder
+	var collect func(n *node) bool
+	// Returns false if the characters after the node are not part of the prefix
+	collect = func(n *node) bool {
+		switch n.typ {
+		case nodeChar:
+			if n.caseless {
+				return false
+			}
+			writeChar(&builder, n.char, utf)
+			return true
+		case nodeSequence:
+			for _, child := range n.children {
+				if !collect(child) {
+					return false
+				}
+			}
+			return true
+		case nodeGroup:
+			return collect(n.children[0])
+		case nodeRepeat:
+			if n.min > 0 {
+				collect(n.children[0])
+			}
+			return false
+		}
+		return false
+	}
+	collect(n)
+	return builder.String()
+}
+
+// Write the character as UTF-8 or as a single byte
+func writeChar(builder *strings.Builder, char rune, utf bool) {
+	if utf {
+		builder.WriteRune(char)
+	} else {
+		builder.WriteByte(byte(char))
+	}
+}
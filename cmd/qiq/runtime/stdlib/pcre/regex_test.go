@@ -0,0 +1,181 @@
+package pcre
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Format the match like "[0-3 1-2]" ("-" for unset groups, "nil" for no match)
+func formatMatch(captures []int) string {
+	if captures == nil {
+		return "nil"
+	}
+	parts := []string{}
+	for i := 0; i < len(captures); i += 2 {
+		if captures[i] < 0 {
+			parts = append(parts, "-")
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", captures[i], captures[i+1]))
+		}
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
+func testMatch(t *testing.T, pattern string, regexOptions Options, subject string, expected string) {
+	t.Helper()
+	regex, err := Compile(pattern, regexOptions)
+	if err != nil {
+		t.Errorf("Pattern %q: Unexpected error: %s", pattern, err)
+		return
+	}
+	captures, matchErr := regex.Match(subject, 0, MatchOptions{BacktrackLimit: 1000000})
+	if matchErr != NoError {
+		t.Errorf("Pattern %q, subject %q: Unexpected match error: %d", pattern, subject, matchErr)
+		return
+	}
+	if actual := formatMatch(captures); actual != expected {
+		t.Errorf("Pattern %q, subject %q:\nExpected: %s\nGot:      %s", pattern, subject, expected, actual)
+	}
+}
+
+func TestLiteralsAndClasses(t *testing.T) {
+	testMatch(t, `abc`, Options{}, "xxabcxx", "[2-5]")
+	testMatch(t, `abc`, Options{}, "xxabxx", "nil")
+	testMatch(t, `a.c`, Options{}, "a\nc abc", "[4-7]")
+	testMatch(t, `a.c`, Options{DotAll: true}, "a\nc", "[0-3]")
+	testMatch(t, `[a-c]+`, Options{}, "xxabcabd", "[2-7]")
+	testMatch(t, `[^a-c]+`, Options{}, "abxyz", "[2-5]")
+	testMatch(t, `[]a]+`, Options{}, "x]a]", "[1-4]")
+	testMatch(t, `[\d-]+`, Options{}, "x12-34", "[1-6]")
+	testMatch(t, `[[:alpha:]]+`, Options{}, "12abC3", "[2-5]")
+	testMatch(t, `[[:^digit:]]+`, Options{}, "12ab3", "[2-4]")
+	testMatch(t, `\w+\s\d+`, Options{}, "-- abc 123", "[3-10]")
+	testMatch(t, `ABC`, Options{Caseless: true}, "xabc", "[1-4]")
+	testMatch(t, `[A-C]+`, Options{Caseless: true}, "xabc", "[1-4]")
+	testMatch(t, `\x41\101`, Options{}, "AA", "[0-2]")
+	testMatch(t, `\Qa.b\E+`, Options{}, "a.bbb", "[0-5]")
+	testMatch(t, `ä+`, Options{Utf: true}, "xää", "[1-5]")
+	testMatch(t, `Ä`, Options{Utf: true, Caseless: true}, "ä", "[0-2]")
+	testMatch(t, `\w+`, Options{Utf: true}, "-äbc-", "[1-5]")
+	testMatch(t, `\p{Lu}+`, Options{Utf: true}, "abÄÖc", "[2-6]")
+	testMatch(t, `\p{Greek}+`, Options{Utf: true}, "abαβ", "[2-6]")
+	testMatch(t, `\P{L}+`, Options{Utf: true}, "ab12c", "[2-4]")
+	testMatch(t, `a b # comment
+		c`, Options{Extended: true}, "abc", "[0-3]")
+}
+
+func TestQuantifiers(t *testing.T) {
+	testMatch(t, `a*`, Options{}, "aaab", "[0-3]")
+	testMatch(t, `a*?b`, Options{}, "aaab", "[0-4]")
+	testMatch(t, `a+?`, Options{}, "aaa", "[0-1]")
+	testMatch(t, `a{2,3}`, Options{}, "aaaa", "[0-3]")
+	testMatch(t, `a{2,3}?`, Options{}, "aaaa", "[0-2]")
+	testMatch(t, `a{,2}`, Options{}, "aaaa", "[0-2]")
+	testMatch(t, `a{2}`, Options{}, "aaaa", "[0-2]")
+	testMatch(t, `x{a}`, Options{}, "x{a}", "[0-4]")
+	testMatch(t, `a++a`, Options{}, "aaa", "nil")
+	testMatch(t, `a+`, Options{Ungreedy: true}, "aaa", "[0-1]")
+	testMatch(t, `(ab)+`, Options{}, "ababab", "[0-6 4-6]")
+	testMatch(t, `(a|b)*c`, Options{}, "abac", "[0-4 2-3]")
+	testMatch(t, `(a?)+?b`, Options{}, "b", "[0-1 0-0]")
+	testMatch(t, `(a|)*`, Options{}, "b", "[0-0 0-0]")
+	testMatch(t, `(?:a|ab)(?:c|bcd)(d*)`, Options{}, "abcd", "[0-4 4-4]")
+}
+
+func TestGroupsAndReferences(t *testing.T) {
+	testMatch(t, `(a)(b)?(c)`, Options{}, "ac", "[0-2 0-1 - 1-2]")
+	testMatch(t, `(?<year>\d{4})-(?<month>\d\d)`, Options{}, "on 2024-05", "[3-10 3-7 8-10]")
+	testMatch(t, `(\w)\1`, Options{}, "abccd", "[2-4 2-3]")
+	testMatch(t, `(?i)(a)\1`, Options{}, "aA", "[0-2 0-1]")
+	testMatch(t, `(?<q>['"]).*?\k<q>`, Options{}, `say "hi" 'x'`, "[4-8 4-5]")
+	testMatch(t, `(a)\g{-1}`, Options{}, "aa", "[0-2 0-1]")
+	testMatch(t, `(?P<x>a)(?P=x)`, Options{}, "aa", "[0-2 0-1]")
+	testMatch(t, `(?|(a)|(b))c`, Options{}, "bc", "[0-2 0-1]")
+	testMatch(t, `(?>a+)b`, Options{}, "aaab", "[0-4]")
+	testMatch(t, `(?>a+)a`, Options{}, "aaaa", "nil")
+	testMatch(t, `a(?i)b|c`, Options{}, "C", "[0-1]")
+	testMatch(t, `a(?i:b)c`, Options{}, "aBc aBC", "[0-3]")
+	testMatch(t, `(?:a)(b)`, Options{}, "ab", "[0-2 1-2]")
+	testMatch(t, `(a)(?:b)`, Options{NoAutoCapture: true}, "ab", "[0-2]")
+}
+
+func TestAssertions(t *testing.T) {
+	testMatch(t, `^b`, Options{}, "a\nb", "nil")
+	testMatch(t, `^b`, Options{Multiline: true}, "a\nb", "[2-3]")
+	testMatch(t, `a$`, Options{}, "a\n", "[0-1]")
+	testMatch(t, `a$`, Options{DollarEndOnly: true}, "a\n", "nil")
+	testMatch(t, `a$`, Options{Multiline: true}, "a\nb", "[0-1]")
+	testMatch(t, `\bfoo\b`, Options{}, "afoo foo", "[5-8]")
+	testMatch(t, `\Bfoo`, Options{}, "foo afoo", "[5-8]")
+	testMatch(t, `foo(?=bar)`, Options{}, "foobaz foobar", "[7-10]")
+	testMatch(t, `foo(?!bar)`, Options{}, "foobar foobaz", "[7-10]")
+	testMatch(t, `(?<=\$)\d+`, Options{}, "12 $34", "[4-6]")
+	testMatch(t, `(?<!\$)\b\d+`, Options{}, "$12 34", "[4-6]")
+	testMatch(t, `(?<=ab|c)d`, Options{}, "abd", "[2-3]")
+	testMatch(t, `a\Kb`, Options{}, "ab", "[1-2]")
+	testMatch(t, `\Aa`, Options{}, "ba", "nil")
+	testMatch(t, `a\z`, Options{}, "a\n", "nil")
+	testMatch(t, `a\Z`, Options{}, "a\n", "[0-1]")
+	testMatch(t, `\R`, Options{}, "a\r\nb", "[1-3]")
+}
+
+func TestRecursionAndConditionals(t *testing.T) {
+	testMatch(t, `\((?:[^()]|(?R))*\)`, Options{}, "x(a(b)c)", "[1-8]")
+	testMatch(t, `^((\w)(?:(?1)|\w?)\2)$`, Options{}, "abcba", "[0-5 0-5 0-1]")
+	testMatch(t, `(?<n>a|b(?&n))`, Options{}, "bba", "[0-3 0-3]")
+	testMatch(t, `(a)?(?(1)b|c)`, Options{}, "c", "[0-1 -]")
+	testMatch(t, `(a)?(?(1)b|c)`, Options{}, "ab", "[0-2 0-1]")
+	testMatch(t, `(?(?=\d)\d+|[a-z]+)`, Options{}, "-abc", "[1-4]")
+	testMatch(t, `(?(DEFINE)(?<d>\d))(?&d)+`, Options{}, "a12", "[1-3 -]")
+}
+
+func TestVerbs(t *testing.T) {
+	testMatch(t, `"[^"]*"(*SKIP)(*F)|\w+`, Options{}, `"ab" cd`, "[5-7]")
+	testMatch(t, `a(*COMMIT)b|ac`, Options{}, "ac", "nil")
+	testMatch(t, `a(*PRUNE)b|ac`, Options{}, "ac", "nil")
+	testMatch(t, `a(*THEN)b|ac`, Options{}, "ac", "[0-2]")
+	testMatch(t, `a(*F)|b`, Options{}, "ab", "[1-2]")
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := map[string]string{
+		`a(b`:            "missing closing parenthesis at offset 3",
+		`a)b`:            "unmatched closing parenthesis at offset 1",
+		`[a`:             "missing terminating ] for character class at offset 2",
+		`*a`:             "quantifier does not follow a repeatable item at offset 0",
+		`a**`:            "quantifier does not follow a repeatable item at offset 2",
+		`[z-a]`:          "range out of order in character class at offset 3",
+		`\2(a)`:          "reference to non-existent subpattern at offset 0",
+		`a\`:             `\ at end of pattern at offset 2`,
+		`\i`:             `unrecognized character follows \ at offset 1`,
+		`(?<=a+)b`:       "length of lookbehind assertion is not limited at offset 0",
+		`(?<n>a)(?<n>b)`: "two named subpatterns have the same name (PCRE2_DUPNAMES not set) at offset 11",
+		`a{3,2}`:         "numbers out of order in {} quantifier at offset 5",
+		`\p{Foo}`:        `unknown property after \P or \p at offset 7`,
+		`(?z)`:           "unrecognized character after (? or (?- at offset 2",
+	}
+	for pattern, expected := range tests {
+		_, err := Compile(pattern, Options{})
+		if err == nil {
+			t.Errorf("Pattern %q: Expected error %q", pattern, expected)
+		} else if err.Error() != expected {
+			t.Errorf("Pattern %q:\nExpected: %s\nGot:      %s", pattern, expected, err)
+		}
+	}
+}
+
+func TestMatchLimits(t *testing.T) {
+	regex, _ := Compile(`(?:\D+|<\d+>)*[!?]`, Options{})
+	if _, err := regex.Match("foobar foobar foobar", 0, MatchOptions{BacktrackLimit: 1000}); err != BacktrackLimitError {
+		t.Errorf("Expected the backtrack limit error, got %d", err)
+	}
+
+	regex, _ = Compile(`a`, Options{Utf: true})
+	if _, err := regex.Match("\xff", 0, MatchOptions{}); err != BadUtf8Error {
+		t.Errorf("Expected the bad UTF-8 error, got %d", err)
+	}
+	if _, err := regex.Match("ä", 1, MatchOptions{}); err != BadUtf8OffsetError {
+		t.Errorf("Expected the bad UTF-8 offset error, got %d", err)
+	}
+}
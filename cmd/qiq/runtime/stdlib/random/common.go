@@ -0,0 +1,165 @@
+package random
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/values"
+)
+
+var (
+	mixedType  = []string{"mixed"}
+	arrayType  = []string{"array"}
+	floatType  = []string{"float"}
+	intType    = []string{"int"}
+	stringType = []string{"string"}
+	voidType   = []string{"void"}
+)
+
+func newNullLiteral() ast.IExpression { return ast.NewConstantAccessExpr(0, nil, "NULL") }
+
+func newException(className string, format string, a ...any) phpError.Error {
+	return phpError.NewError("Uncaught "+className+": "+format, a...)
+}
+
+// Create a new object of the given (native) class without calling the constructor
+func newObject(interpreter runtime.Interpreter, className string) (*values.Object, phpError.Error) {
+	classDecl, found := interpreter.GetClass(className)
+	if !found {
+		return nil, phpError.NewError(`Class "%s" not found`, className)
+	}
+	return values.NewObject(classDecl), nil
+}
+
+// Encode the generated number as little endian string like the generate method of the engines
+func numberToBytes(number uint64, size int) string {
+	bytes := make([]byte, size)
+	for i := range size {
+		bytes[i] = byte(number >> (8 * i))
+	}
+	return string(bytes)
+}
+
+// -------------------------------------- Default engine -------------------------------------- MARK: Default engine
+
+// Get the Mt19937 engine used by mt_rand, rand, shuffle, str_shuffle and array_rand.
+// It is seeded randomly if mt_srand or srand has not been called.
+func defaultEngine(context runtime.Context) (*mt19937, phpError.Error) {
+	if engine, ok := context.Interpreter.GetExectionContext().GetRandomState().(*mt19937); ok {
+		return engine, nil
+	}
+	engine, err := newRandomMt19937(mtRandMt19937)
+	if err != nil {
+		return nil, err
+	}
+	context.Interpreter.GetExectionContext().SetRandomState(engine)
+	return engine, nil
+}
+
+// -------------------------------------- Algorithms -------------------------------------- MARK: Algorithms
+
+// Shuffle the array with the default engine like shuffle. The elements get new keys.
+func ShuffleArray(array *values.Array, context runtime.Context) (*values.Array, phpError.Error) {
+	engine, err := defaultEngine(context)
+	if err != nil {
+		return nil, err
+	}
+	return shuffleArray(engine, context, array)
+}
+
+// Shuffle the bytes of the string with the default engine like str_shuffle
+func ShuffleBytes(str string, context runtime.Context) (string, phpError.Error) {
+	engine, err := defaultEngine(context)
+	if err != nil {
+		return "", err
+	}
+	return shuffleBytes(engine, context, str)
+}
+
+// Pick num distinct keys of the array with the default engine like array_rand.
+// The keys are returned in the order of the array. num must be between 1 and the number of elements.
+func PickArrayKeys(array *values.Array, num int, context runtime.Context) ([]values.RuntimeValue, phpError.Error) {
+	engine, err := defaultEngine(context)
+	if err != nil {
+		return nil, err
+	}
+	return pickArrayKeys(engine, context, array, num)
+}
+
+// Fisher-Yates shuffle like php_array_data_shuffle
+func shuffleArray(engine engine, context runtime.Context, array *values.Array) (*values.Array, phpError.Error) {
+	elements := make([]values.RuntimeValue, len(array.Keys))
+	for i, key := range array.Keys {
+		slot, _ := array.GetElement(key)
+		elements[i] = slot.Value
+	}
+
+	for left := int64(len(elements)) - 1; left > 0; left-- {
+		index, err := randomRange(engine, context, 0, left)
+		if err != nil {
+			return nil, err
+		}
+		elements[left], elements[index] = elements[index], elements[left]
+	}
+
+	return values.NewArrayFromSlice(elements), nil
+}
+
+// Fisher-Yates shuffle like php_binary_string_shuffle
+func shuffleBytes(engine engine, context runtime.Context, str string) (string, phpError.Error) {
+	bytes := []byte(str)
+	for left := int64(len(bytes)) - 1; left > 0; left-- {
+		index, err := randomRange(engine, context, 0, left)
+		if err != nil {
+			return "", err
+		}
+		bytes[left], bytes[index] = bytes[index], bytes[left]
+	}
+	return string(bytes), nil
+}
+
+// Pick distinct keys like php_array_pick_keys.
+// If more than half of the keys are requested, the keys that are not returned are picked instead.
+func pickArrayKeys(engine engine, context runtime.Context, array *values.Array, num int) ([]values.RuntimeValue, phpError.Error) {
+	count := len(array.Keys)
+	if num == 1 {
+		index, err := randomRange(engine, context, 0, int64(count)-1)
+		if err != nil {
+			return nil, err
+		}
+		return []values.RuntimeValue{array.Keys[index]}, nil
+	}
+
+	negative := false
+	if num > count>>1 {
+		negative = true
+		num = count - num
+	}
+
+	picked := make([]bool, count)
+	failures := 0
+	for num > 0 {
+		index, err := randomRange(engine, context, 0, int64(count)-1)
+		if err != nil {
+			return nil, err
+		}
+		if picked[index] {
+			failures++
+			if failures > rangeAttempts {
+				return nil, brokenEngineError()
+			}
+			continue
+		}
+		picked[index] = true
+		num--
+		failures = 0
+	}
+
+	keys := []values.RuntimeValue{}
+	for i, key := range array.Keys {
+		if picked[i] != negative {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
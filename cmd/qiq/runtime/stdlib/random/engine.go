@@ -0,0 +1,191 @@
+package random
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/values"
+	"crypto/rand"
+	"encoding/binary"
+	"strings"
+)
+
+// Maximum number of attempts to find a random number without modulo bias (PHP_RANDOM_RANGE_ATTEMPTS)
+const rangeAttempts = 50
+
+// Random number generator of the engine classes.
+// Every call to generate returns the next random number and its size in bytes (1 to 8).
+type engine interface {
+	generate(context runtime.Context) (result uint64, size int, err phpError.Error)
+}
+
+// Get the engine of a Random\Engine object. Engines implemented in PHP are called via their generate method.
+func getEngine(object *values.Object) engine {
+	if engine, ok := object.Internal.(engine); ok {
+		return engine
+	}
+	if strings.EqualFold(object.Class.GetQualifiedName(), secureClassName) {
+		return &secureEngine{}
+	}
+	return &userEngine{object: object}
+}
+
+// -------------------------------------- User engine -------------------------------------- MARK: User engine
+
+type userEngine struct {
+	object *values.Object
+}
+
+func (engine *userEngine) generate(context runtime.Context) (uint64, int, phpError.Error) {
+	result, err := context.Interpreter.CallMethod(engine.object, "generate", []values.RuntimeValue{}, context.Env)
+	if err != nil {
+		return 0, 0, err
+	}
+	str, ok := result.(*values.Str)
+	if !ok {
+		return 0, 0, phpError.NewError(
+			"Uncaught TypeError: %s::generate(): Return value must be of type string, %s returned",
+			engine.object.Class.GetQualifiedName(), values.ToPhpType(result),
+		)
+	}
+	if len(str.Value) == 0 {
+		return 0, 0, phpError.NewError(`Uncaught Random\BrokenRandomEngineError: A random engine must return a non-empty string`)
+	}
+
+	// Only the first 8 bytes are used
+	size := min(len(str.Value), 8)
+	var number uint64
+	for i := range size {
+		number |= uint64(str.Value[i]) << (8 * i)
+	}
+	return number, size, nil
+}
+
+// -------------------------------------- Secure -------------------------------------- MARK: Secure
+
+// Cryptographically secure engine backed by the random source of the operating system
+type secureEngine struct{}
+
+func (engine *secureEngine) generate(_ runtime.Context) (uint64, int, phpError.Error) {
+	bytes, err := secureBytes(8)
+	if err != nil {
+		return 0, 0, err
+	}
+	return binary.LittleEndian.Uint64(bytes), 8, nil
+}
+
+func secureBytes(length int) ([]byte, phpError.Error) {
+	bytes := make([]byte, length)
+	if _, err := rand.Read(bytes); err != nil {
+		return nil, phpError.NewError(`Uncaught Random\RandomException: Failed to read from the system random source: %s`, err)
+	}
+	return bytes, nil
+}
+
+// -------------------------------------- Range -------------------------------------- MARK: Range
+
+// Get a uniformly distributed random number between min and max (inclusive) like php_random_range
+func randomRange(engine engine, context runtime.Context, min int64, max int64) (int64, phpError.Error) {
+	umax := uint64(max) - uint64(min)
+	if umax > 0xFFFFFFFF {
+		result, err := range64(engine, context, umax)
+		return int64(result + uint64(min)), err
+	}
+	result, err := range32(engine, context, uint32(umax))
+	return int64(uint64(result) + uint64(min)), err
+}
+
+func range32(engine engine, context runtime.Context, umax uint32) (uint32, phpError.Error) {
+	generate := func() (uint32, phpError.Error) {
+		var result uint32
+		for totalSize := 0; totalSize < 4; {
+			number, size, err := engine.generate(context)
+			if err != nil {
+				return 0, err
+			}
+			result |= uint32(number) << (totalSize * 8)
+			totalSize += size
+		}
+		return result, nil
+	}
+
+	result, err := generate()
+	if err != nil {
+		return 0, err
+	}
+
+	// Special case where no modulus is required
+	if umax == 0xFFFFFFFF {
+		return result, nil
+	}
+
+	// Increment the max so the range is inclusive of max
+	umax++
+
+	// Powers of two are not biased
+	if umax&(umax-1) == 0 {
+		return result & (umax - 1), nil
+	}
+
+	// Discard numbers over the limit to avoid modulo bias
+	limit := 0xFFFFFFFF - (0xFFFFFFFF % umax) - 1
+	for count := 1; result > limit; count++ {
+		if count > rangeAttempts {
+			return 0, brokenEngineError()
+		}
+		if result, err = generate(); err != nil {
+			return 0, err
+		}
+	}
+
+	return result % umax, nil
+}
+
+func range64(engine engine, context runtime.Context, umax uint64) (uint64, phpError.Error) {
+	generate := func() (uint64, phpError.Error) {
+		var result uint64
+		for totalSize := 0; totalSize < 8; {
+			number, size, err := engine.generate(context)
+			if err != nil {
+				return 0, err
+			}
+			result |= number << (totalSize * 8)
+			totalSize += size
+		}
+		return result, nil
+	}
+
+	result, err := generate()
+	if err != nil {
+		return 0, err
+	}
+
+	// Special case where no modulus is required
+	if umax == 0xFFFFFFFFFFFFFFFF {
+		return result, nil
+	}
+
+	// Increment the max so the range is inclusive of max
+	umax++
+
+	// Powers of two are not biased
+	if umax&(umax-1) == 0 {
+		return result & (umax - 1), nil
+	}
+
+	// Discard numbers over the limit to avoid modulo bias
+	limit := 0xFFFFFFFFFFFFFFFF - (0xFFFFFFFFFFFFFFFF % umax) - 1
+	for count := 1; result > limit; count++ {
+		if count > rangeAttempts {
+			return 0, brokenEngineError()
+		}
+		if result, err = generate(); err != nil {
+			return 0, err
+		}
+	}
+
+	return result % umax, nil
+}
+
+func brokenEngineError() phpError.Error {
+	return phpError.NewError(`Uncaught Random\BrokenRandomEngineError: Failed to generate an acceptable random number in %d attempts`, rangeAttempts)
+}
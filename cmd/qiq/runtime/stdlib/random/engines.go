@@ -0,0 +1,195 @@
+package random
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+	"encoding/binary"
+)
+
+const (
+	mt19937ClassName             = `Random\Engine\Mt19937`
+	pcgOneseq128XslRr64ClassName = `Random\Engine\PcgOneseq128XslRr64`
+	xoshiro256StarStarClassName  = `Random\Engine\Xoshiro256StarStar`
+	secureClassName              = `Random\Engine\Secure`
+)
+
+func registerEngines(interpreter runtime.Interpreter) {
+	seedParam := runtime.NewOptionalParam("$seed", newNullLiteral(), "string", "int", "null")
+
+	// Spec: https://www.php.net/manual/en/class.random-engine-mt19937.php
+	runtime.NewNativeClass(interpreter, mt19937ClassName).
+		Final().
+		Implements(`Random\Engine`).
+		AddMethod("__construct", []ast.FunctionParameter{
+			runtime.NewOptionalParam("$seed", newNullLiteral(), "int", "null"),
+			runtime.NewOptionalParam("$mode", ast.NewConstantAccessExpr(0, nil, "MT_RAND_MT19937"), "int"),
+		}, nil, mt19937Construct).
+		AddMethod("generate", []ast.FunctionParameter{}, stringType, engineGenerate(mt19937ClassName)).
+		Register()
+
+	// Spec: https://www.php.net/manual/en/class.random-engine-pcgoneseq128xslrr64.php
+	runtime.NewNativeClass(interpreter, pcgOneseq128XslRr64ClassName).
+		Final().
+		Implements(`Random\Engine`).
+		AddMethod("__construct", []ast.FunctionParameter{seedParam}, nil, pcgOneseq128XslRr64Construct).
+		AddMethod("generate", []ast.FunctionParameter{}, stringType, engineGenerate(pcgOneseq128XslRr64ClassName)).
+		AddMethod("jump", []ast.FunctionParameter{runtime.NewParam("$advance", "int")}, voidType, pcgOneseq128XslRr64Jump).
+		Register()
+
+	// Spec: https://www.php.net/manual/en/class.random-engine-xoshiro256starstar.php
+	runtime.NewNativeClass(interpreter, xoshiro256StarStarClassName).
+		Final().
+		Implements(`Random\Engine`).
+		AddMethod("__construct", []ast.FunctionParameter{seedParam}, nil, xoshiro256StarStarConstruct).
+		AddMethod("generate", []ast.FunctionParameter{}, stringType, engineGenerate(xoshiro256StarStarClassName)).
+		AddMethod("jump", []ast.FunctionParameter{}, voidType, xoshiro256StarStarJump(xoshiroJump, "jump")).
+		AddMethod("jumpLong", []ast.FunctionParameter{}, voidType, xoshiro256StarStarJump(xoshiroLongJump, "jumpLong")).
+		Register()
+
+	// Spec: https://www.php.net/manual/en/class.random-engine-secure.php
+	runtime.NewNativeClass(interpreter, secureClassName).
+		Final().
+		Implements(`Random\CryptoSafeEngine`).
+		AddMethod("generate", []ast.FunctionParameter{}, stringType, engineGenerate(secureClassName)).
+		Register()
+}
+
+// Generate method of the native engines. The random number is returned as little endian string.
+func engineGenerate(className string) runtime.NativeMethod {
+	return func(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+		if _, err := funcParamValidator.NewValidator(className + "::generate").Validate(args); err != nil {
+			return values.NewVoid(), err
+		}
+
+		number, size, err := getEngine(object).generate(context)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		return values.NewStr(numberToBytes(number, size)), nil
+	}
+}
+
+// -------------------------------------- Mt19937 -------------------------------------- MARK: Mt19937
+
+func mt19937Construct(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(mt19937ClassName+"::__construct").
+		AddParam("$seed", []string{"int", "null"}, values.NewNull()).
+		AddParam("$mode", intType, values.NewInt(mtRandMt19937)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	mode := args[1].(*values.Int).Value
+	switch mode {
+	case mtRandMt19937:
+	case mtRandPhp:
+		context.Interpreter.PrintError(phpError.NewDeprecatedError("Random\\Engine\\Mt19937::__construct(): The MT_RAND_PHP variant of Mt19937 is deprecated%s", context.InPosition()))
+	default:
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: %s::__construct(): Argument #2 ($mode) must be either MT_RAND_MT19937 or MT_RAND_PHP", mt19937ClassName,
+		)
+	}
+
+	if args[0].GetType() == values.NullValue {
+		engine, err := newRandomMt19937(mode)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		object.Internal = engine
+	} else {
+		object.Internal = newMt19937(uint32(args[0].(*values.Int).Value), mode)
+	}
+	return values.NewVoid(), nil
+}
+
+// -------------------------------------- PcgOneseq128XslRr64 -------------------------------------- MARK: PcgOneseq128XslRr64
+
+func pcgOneseq128XslRr64Construct(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	functionName := pcgOneseq128XslRr64ClassName + "::__construct"
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$seed", []string{"string", "int", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	switch seed := args[0].(type) {
+	case *values.Null:
+		engine, err := newRandomPcgOneseq128XslRr64()
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		object.Internal = engine
+	case *values.Str:
+		if len(seed.Value) != 16 {
+			return values.NewVoid(), phpError.NewError("Uncaught ValueError: %s(): Argument #1 ($seed) must be a 16 byte (128 bit) string", functionName)
+		}
+		bytes := []byte(seed.Value)
+		object.Internal = newPcgOneseq128XslRr64(uint128{hi: binary.LittleEndian.Uint64(bytes[:8]), lo: binary.LittleEndian.Uint64(bytes[8:])})
+	case *values.Int:
+		object.Internal = newPcgOneseq128XslRr64(uint128{hi: 0, lo: uint64(seed.Value)})
+	}
+	return values.NewVoid(), nil
+}
+
+func pcgOneseq128XslRr64Jump(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	functionName := pcgOneseq128XslRr64ClassName + "::jump"
+	args, err := funcParamValidator.NewValidator(functionName).AddParam("$advance", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	advance := args[0].(*values.Int).Value
+	if advance < 0 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: %s(): Argument #1 ($advance) must be greater than or equal to 0", functionName)
+	}
+	object.Internal.(*pcgOneseq128XslRr64).advance(uint64(advance))
+	return values.NewVoid(), nil
+}
+
+// -------------------------------------- Xoshiro256StarStar -------------------------------------- MARK: Xoshiro256StarStar
+
+func xoshiro256StarStarConstruct(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	functionName := xoshiro256StarStarClassName + "::__construct"
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$seed", []string{"string", "int", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	switch seed := args[0].(type) {
+	case *values.Null:
+		engine, err := newRandomXoshiro256StarStar()
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		object.Internal = engine
+	case *values.Str:
+		if len(seed.Value) != 32 {
+			return values.NewVoid(), phpError.NewError("Uncaught ValueError: %s(): Argument #1 ($seed) must be a 32 byte (256 bit) string", functionName)
+		}
+		engine := newXoshiro256StarStarFromBytes([]byte(seed.Value))
+		if engine.isZero() {
+			return values.NewVoid(), phpError.NewError("Uncaught ValueError: %s(): Argument #1 ($seed) must not consist entirely of NUL bytes", functionName)
+		}
+		object.Internal = engine
+	case *values.Int:
+		object.Internal = newXoshiro256StarStar(uint64(seed.Value))
+	}
+	return values.NewVoid(), nil
+}
+
+func xoshiro256StarStarJump(polynomial [4]uint64, methodName string) runtime.NativeMethod {
+	return func(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+		if _, err := funcParamValidator.NewValidator(xoshiro256StarStarClassName + "::" + methodName).Validate(args); err != nil {
+			return values.NewVoid(), err
+		}
+		object.Internal.(*xoshiro256StarStar).jump(polynomial)
+		return values.NewVoid(), nil
+	}
+}
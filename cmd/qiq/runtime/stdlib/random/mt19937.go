@@ -0,0 +1,113 @@
+package random
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"encoding/binary"
+)
+
+// Spec: https://www.php.net/manual/en/class.random-engine-mt19937.php
+// The implementation follows ext/random/engine_mt19937.c so that seeded sequences are identical to PHP.
+
+const (
+	// Length of the state vector
+	mtN = 624
+	// Period parameter
+	mtM = 397
+)
+
+const (
+	// Correct Mt19937 implementation
+	mtRandMt19937 int64 = 0
+	// Incorrect implementation of PHP < 7.1 (the twist uses the wrong bit and mt_rand with a range scales)
+	mtRandPhp int64 = 1
+)
+
+// Largest number returned by mt_rand and rand without arguments
+const mtRandMax = 0x7FFFFFFF
+
+type mt19937 struct {
+	state [mtN]uint32
+	count int
+	mode  int64
+}
+
+func newMt19937(seed uint32, mode int64) *mt19937 {
+	engine := &mt19937{mode: mode}
+	engine.seed(seed)
+	return engine
+}
+
+// Create an engine with a random seed
+func newRandomMt19937(mode int64) (*mt19937, phpError.Error) {
+	bytes, err := secureBytes(4)
+	if err != nil {
+		return nil, err
+	}
+	return newMt19937(binary.LittleEndian.Uint32(bytes), mode), nil
+}
+
+func (engine *mt19937) seed(seed uint32) {
+	// Initialize generator state with seed.
+	// See Knuth TAOCP Vol 2, 3rd Ed, p.106 for multiplier.
+	engine.state[0] = seed
+	for i := uint32(1); i < mtN; i++ {
+		previous := engine.state[i-1]
+		engine.state[i] = 1812433253*(previous^(previous>>30)) + i
+	}
+	engine.reload()
+}
+
+// Generate the next N words of the state
+func (engine *mt19937) reload() {
+	twist := mtTwist
+	if engine.mode == mtRandPhp {
+		twist = mtTwistPhp
+	}
+
+	state := &engine.state
+	i := 0
+	for ; i < mtN-mtM; i++ {
+		state[i] = twist(state[i+mtM], state[i], state[i+1])
+	}
+	for ; i < mtN-1; i++ {
+		state[i] = twist(state[i+mtM-mtN], state[i], state[i+1])
+	}
+	state[mtN-1] = twist(state[mtM-1], state[mtN-1], state[0])
+	engine.count = 0
+}
+
+// Move the high bit of u to the high bit of v
+func mtMixBits(u uint32, v uint32) uint32 { return u&0x80000000 | v&0x7FFFFFFF }
+
+func mtTwist(m uint32, u uint32, v uint32) uint32 {
+	return m ^ (mtMixBits(u, v) >> 1) ^ (-(v & 1) & 0x9908B0DF)
+}
+
+func mtTwistPhp(m uint32, u uint32, v uint32) uint32 {
+	return m ^ (mtMixBits(u, v) >> 1) ^ (-(u & 1) & 0x9908B0DF)
+}
+
+func (engine *mt19937) next() uint32 {
+	if engine.count >= mtN {
+		engine.reload()
+	}
+
+	s1 := engine.state[engine.count]
+	engine.count++
+	s1 ^= s1 >> 11
+	s1 ^= (s1 << 7) & 0x9D2C5680
+	s1 ^= (s1 << 15) & 0xEFC60000
+	return s1 ^ (s1 >> 18)
+}
+
+func (engine *mt19937) generate(_ runtime.Context) (uint64, int, phpError.Error) {
+	return uint64(engine.next()), 4, nil
+}
+
+// Scale a random number into the range like the MT_RAND_PHP mode of mt_rand (RAND_RANGE_BADSCALING)
+func (engine *mt19937) badScaling(min int64, max int64) int64 {
+	number := engine.next() >> 1
+	offset := uint64((float64(max) - float64(min) + 1.0) * (float64(number) / (mtRandMax + 1.0)))
+	return int64(offset + uint64(min))
+}
@@ -0,0 +1,83 @@
+package random
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"encoding/binary"
+	"math/bits"
+)
+
+// Spec: https://www.php.net/manual/en/class.random-engine-pcgoneseq128xslrr64.php
+// The implementation follows ext/random/engine_pcgoneseq128xslrr64.c so that seeded sequences are identical to PHP.
+
+type uint128 struct {
+	hi uint64
+	lo uint64
+}
+
+func (a uint128) add(b uint128) uint128 {
+	lo, carry := bits.Add64(a.lo, b.lo, 0)
+	hi, _ := bits.Add64(a.hi, b.hi, carry)
+	return uint128{hi: hi, lo: lo}
+}
+
+func (a uint128) multiply(b uint128) uint128 {
+	hi, lo := bits.Mul64(a.lo, b.lo)
+	return uint128{hi: hi + a.hi*b.lo + a.lo*b.hi, lo: lo}
+}
+
+var (
+	pcgMultiplier = uint128{hi: 2549297995355413924, lo: 4865540595714422341}
+	pcgIncrement  = uint128{hi: 6364136223846793005, lo: 1442695040888963407}
+)
+
+type pcgOneseq128XslRr64 struct {
+	state uint128
+}
+
+func newPcgOneseq128XslRr64(seed uint128) *pcgOneseq128XslRr64 {
+	engine := &pcgOneseq128XslRr64{}
+	engine.step()
+	engine.state = engine.state.add(seed)
+	engine.step()
+	return engine
+}
+
+// Create an engine with a random state
+func newRandomPcgOneseq128XslRr64() (*pcgOneseq128XslRr64, phpError.Error) {
+	bytes, err := secureBytes(16)
+	if err != nil {
+		return nil, err
+	}
+	return &pcgOneseq128XslRr64{state: uint128{hi: binary.LittleEndian.Uint64(bytes[8:]), lo: binary.LittleEndian.Uint64(bytes[:8])}}, nil
+}
+
+func (engine *pcgOneseq128XslRr64) step() {
+	engine.state = engine.state.multiply(pcgMultiplier).add(pcgIncrement)
+}
+
+func (engine *pcgOneseq128XslRr64) generate(_ runtime.Context) (uint64, int, phpError.Error) {
+	engine.step()
+	value := engine.state.hi ^ engine.state.lo
+	return bits.RotateLeft64(value, -int(engine.state.hi>>58)), 8, nil
+}
+
+// Advance the state by the given number of steps in logarithmic time
+func (engine *pcgOneseq128XslRr64) advance(advance uint64) {
+	currentMultiplier := pcgMultiplier
+	currentIncrement := pcgIncrement
+	accumulatedMultiplier := uint128{hi: 0, lo: 1}
+	accumulatedIncrement := uint128{hi: 0, lo: 0}
+
+	for advance > 0 {
+		if advance&1 == 1 {
+			accumulatedMultiplier = accumulatedMultiplier.multiply(currentMultiplier)
+			accumulatedIncrement = accumulatedIncrement.multiply(currentMultiplier).add(currentIncrement)
+		}
+		currentIncrement = currentMultiplier.add(uint128{hi: 0, lo: 1}).multiply(currentIncrement)
+		currentMultiplier = currentMultiplier.multiply(currentMultiplier)
+		advance /= 2
+	}
+
+	engine.state = accumulatedMultiplier.multiply(engine.state).add(accumulatedIncrement)
+}
@@ -0,0 +1,196 @@
+package random
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+)
+
+func Register(environment runtime.Environment) {
+	// Category: Random Functions
+	environment.AddNativeFunction("getrandmax", nativeFn_getrandmax)
+	environment.AddNativeFunction("mt_getrandmax", nativeFn_mt_getrandmax)
+	environment.AddNativeFunction("mt_rand", nativeFn_mt_rand)
+	environment.AddNativeFunction("mt_srand", nativeFn_mt_srand)
+	environment.AddNativeFunction("rand", nativeFn_rand)
+	environment.AddNativeFunction("random_bytes", nativeFn_random_bytes)
+	environment.AddNativeFunction("random_int", nativeFn_random_int)
+	environment.AddNativeFunction("srand", nativeFn_srand)
+
+	// Const Category: Random Constants
+	// Spec: https://www.php.net/manual/en/random.constants.php
+	environment.AddPredefinedConstant("MT_RAND_MT19937", values.NewInt(mtRandMt19937))
+	environment.AddPredefinedConstant("MT_RAND_PHP", values.NewInt(mtRandPhp))
+}
+
+func RegisterClasses(interpreter runtime.Interpreter) {
+	registerEngines(interpreter)
+	registerRandomizer(interpreter)
+}
+
+// -------------------------------------- getrandmax -------------------------------------- MARK: getrandmax
+
+func nativeFn_getrandmax(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.getrandmax.php
+	if _, err := funcParamValidator.NewValidator("getrandmax").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(mtRandMax), nil
+}
+
+// -------------------------------------- mt_getrandmax -------------------------------------- MARK: mt_getrandmax
+
+func nativeFn_mt_getrandmax(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.mt-getrandmax.php
+	if _, err := funcParamValidator.NewValidator("mt_getrandmax").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(mtRandMax), nil
+}
+
+// -------------------------------------- mt_rand -------------------------------------- MARK: mt_rand
+
+func nativeFn_mt_rand(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.mt-rand.php
+	return lib_mt_rand("mt_rand", args, context)
+}
+
+func lib_mt_rand(functionName string, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	engine, err := defaultEngine(context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Without arguments a number between 0 and mt_getrandmax() is returned
+	if len(args) == 0 {
+		return values.NewInt(int64(engine.next() >> 1)), nil
+	}
+
+	args, err = funcParamValidator.NewValidator(functionName).
+		AddParam("$min", intType, nil).AddParam("$max", intType, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	min := args[0].(*values.Int).Value
+	max := args[1].(*values.Int).Value
+
+	if max < min {
+		if functionName == "mt_rand" {
+			return values.NewVoid(), phpError.NewError(
+				"Uncaught ValueError: mt_rand(): Argument #2 ($max) must be greater than or equal to argument #1 ($min)",
+			)
+		}
+		// rand allows max to be smaller than min
+		min, max = max, min
+	}
+
+	if engine.mode == mtRandPhp {
+		return values.NewInt(engine.badScaling(min, max)), nil
+	}
+	result, err := randomRange(engine, context, min, max)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(result), nil
+}
+
+// -------------------------------------- mt_srand -------------------------------------- MARK: mt_srand
+
+func nativeFn_mt_srand(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.mt-srand.php
+	return lib_mt_srand("mt_srand", args, context)
+}
+
+func lib_mt_srand(functionName string, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$seed", []string{"int", "null"}, values.NewNull()).
+		AddParam("$mode", intType, values.NewInt(mtRandMt19937)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Unknown modes are treated as MT_RAND_MT19937
+	mode := mtRandMt19937
+	if args[1].(*values.Int).Value == mtRandPhp {
+		mode = mtRandPhp
+		context.Interpreter.PrintError(phpError.NewDeprecatedError("mt_srand(): The MT_RAND_PHP variant of Mt19937 is deprecated%s", context.InPosition()))
+	}
+
+	var engine *mt19937
+	if args[0].GetType() == values.NullValue {
+		if engine, err = newRandomMt19937(mode); err != nil {
+			return values.NewVoid(), err
+		}
+	} else {
+		engine = newMt19937(uint32(args[0].(*values.Int).Value), mode)
+	}
+	context.Interpreter.GetExectionContext().SetRandomState(engine)
+
+	return values.NewVoid(), nil
+}
+
+// -------------------------------------- rand -------------------------------------- MARK: rand
+
+func nativeFn_rand(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.rand.php
+	return lib_mt_rand("rand", args, context)
+}
+
+// -------------------------------------- random_bytes -------------------------------------- MARK: random_bytes
+
+func nativeFn_random_bytes(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.random-bytes.php
+	args, err := funcParamValidator.NewValidator("random_bytes").AddParam("$length", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	length := args[0].(*values.Int).Value
+	if length < 1 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: random_bytes(): Argument #1 ($length) must be greater than 0")
+	}
+
+	bytes, err := secureBytes(int(length))
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewStr(string(bytes)), nil
+}
+
+// -------------------------------------- random_int -------------------------------------- MARK: random_int
+
+func nativeFn_random_int(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.random-int.php
+	args, err := funcParamValidator.NewValidator("random_int").
+		AddParam("$min", intType, nil).AddParam("$max", intType, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	min := args[0].(*values.Int).Value
+	max := args[1].(*values.Int).Value
+	if min > max {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: random_int(): Argument #1 ($min) must be less than or equal to argument #2 ($max)",
+		)
+	}
+
+	result, err := randomRange(&secureEngine{}, context, min, max)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(result), nil
+}
+
+// -------------------------------------- srand -------------------------------------- MARK: srand
+
+func nativeFn_srand(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.srand.php
+	return lib_mt_srand("srand", args, context)
+}
+
+// TODO lcg_value
@@ -0,0 +1,270 @@
+package random
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+)
+
+const randomizerClassName = `Random\Randomizer`
+
+func registerRandomizer(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.random-randomizer.php
+	runtime.NewNativeClass(interpreter, randomizerClassName).
+		Final().
+		AddProperty("$engine", "public", []string{`Random\Engine`}, nil).
+		AddMethod("__construct", []ast.FunctionParameter{runtime.NewOptionalParam("$engine", newNullLiteral(), `Random\Engine`, "null")}, nil, randomizerConstruct).
+		AddMethod("getBytes", []ast.FunctionParameter{runtime.NewParam("$length", "int")}, stringType, randomizerGetBytes).
+		AddMethod("getBytesFromString", []ast.FunctionParameter{runtime.NewParam("$string", "string"), runtime.NewParam("$length", "int")}, stringType, randomizerGetBytesFromString).
+		AddMethod("getInt", []ast.FunctionParameter{runtime.NewParam("$min", "int"), runtime.NewParam("$max", "int")}, intType, randomizerGetInt).
+		AddMethod("nextFloat", []ast.FunctionParameter{}, floatType, randomizerNextFloat).
+		AddMethod("nextInt", []ast.FunctionParameter{}, intType, randomizerNextInt).
+		AddMethod("pickArrayKeys", []ast.FunctionParameter{runtime.NewParam("$array", "array"), runtime.NewParam("$num", "int")}, arrayType, randomizerPickArrayKeys).
+		AddMethod("shuffleArray", []ast.FunctionParameter{runtime.NewParam("$array", "array")}, arrayType, randomizerShuffleArray).
+		AddMethod("shuffleBytes", []ast.FunctionParameter{runtime.NewParam("$bytes", "string")}, stringType, randomizerShuffleBytes).
+		Register()
+}
+
+// Validate the arguments and get the engine of the randomizer
+func randomizerEngine(object *values.Object, validator *funcParamValidator.Validator, args []values.RuntimeValue) (engine, []values.RuntimeValue, phpError.Error) {
+	args, err := validator.Validate(args)
+	if err != nil {
+		return nil, args, err
+	}
+	return object.Internal.(engine), args, nil
+}
+
+func randomizerConstruct(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	functionName := randomizerClassName + "::__construct"
+	args, err := funcParamValidator.NewValidator(functionName).AddParam("$engine", mixedType, values.NewNull()).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	engineObject, err := context.ObjectArg(functionName, 1, "$engine", `Random\Engine`, true, args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// The cryptographically secure engine is used by default
+	if engineObject == nil {
+		if engineObject, err = newObject(context.Interpreter, secureClassName); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+
+	object.SetProperty("$engine", engineObject)
+	object.Internal = getEngine(engineObject)
+	return values.NewVoid(), nil
+}
+
+func randomizerGetBytes(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	functionName := randomizerClassName + "::getBytes"
+	engine, args, err := randomizerEngine(object, funcParamValidator.NewValidator(functionName).AddParam("$length", intType, nil), args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	length := args[0].(*values.Int).Value
+	if length < 1 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: %s(): Argument #1 ($length) must be greater than 0", functionName)
+	}
+
+	bytes := make([]byte, 0, length)
+	for int64(len(bytes)) < length {
+		number, size, err := engine.generate(context)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		for i := 0; i < size && int64(len(bytes)) < length; i++ {
+			bytes = append(bytes, byte(number>>(i*8)))
+		}
+	}
+	return values.NewStr(string(bytes)), nil
+}
+
+func randomizerGetBytesFromString(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	functionName := randomizerClassName + "::getBytesFromString"
+	engine, args, err := randomizerEngine(object,
+		funcParamValidator.NewValidator(functionName).AddParam("$string", stringType, nil).AddParam("$length", intType, nil),
+		args,
+	)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	source := args[0].(*values.Str).Value
+	length := args[1].(*values.Int).Value
+	if len(source) < 1 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: %s(): Argument #1 ($string) cannot be empty", functionName)
+	}
+	if length < 1 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: %s(): Argument #2 ($length) must be greater than 0", functionName)
+	}
+
+	maxOffset := uint64(len(source) - 1)
+	bytes := make([]byte, 0, length)
+
+	if maxOffset > 0xFF {
+		for int64(len(bytes)) < length {
+			offset, err := randomRange(engine, context, 0, int64(maxOffset))
+			if err != nil {
+				return values.NewVoid(), err
+			}
+			bytes = append(bytes, source[offset])
+		}
+		return values.NewStr(string(bytes)), nil
+	}
+
+	// Every byte of the generated number is used as offset if it is not greater than the max offset.
+	// The mask copies the top-most bit of the max offset into all lower bits.
+	mask := maxOffset
+	mask |= mask >> 1
+	mask |= mask >> 2
+	mask |= mask >> 4
+
+	failures := 0
+	for int64(len(bytes)) < length {
+		number, size, err := engine.generate(context)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		for i := 0; i < size && int64(len(bytes)) < length; i++ {
+			offset := (number >> (i * 8)) & mask
+			if offset > maxOffset {
+				failures++
+				if failures > rangeAttempts {
+					return values.NewVoid(), brokenEngineError()
+				}
+				continue
+			}
+			failures = 0
+			bytes = append(bytes, source[offset])
+		}
+	}
+	return values.NewStr(string(bytes)), nil
+}
+
+func randomizerGetInt(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	functionName := randomizerClassName + "::getInt"
+	engine, args, err := randomizerEngine(object,
+		funcParamValidator.NewValidator(functionName).AddParam("$min", intType, nil).AddParam("$max", intType, nil),
+		args,
+	)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	min := args[0].(*values.Int).Value
+	max := args[1].(*values.Int).Value
+	if max < min {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: %s(): Argument #2 ($max) must be greater than or equal to argument #1 ($min)", functionName,
+		)
+	}
+
+	// The legacy Mt19937 mode scales the number into the range like mt_rand
+	if mt, ok := engine.(*mt19937); ok && mt.mode == mtRandPhp {
+		return values.NewInt(mt.badScaling(min, max)), nil
+	}
+
+	result, err := randomRange(engine, context, min, max)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(result), nil
+}
+
+func randomizerNextFloat(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	engine, _, err := randomizerEngine(object, funcParamValidator.NewValidator(randomizerClassName+"::nextFloat"), args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	var result uint64
+	for totalSize := 0; totalSize < 8; {
+		number, size, err := engine.generate(context)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		result |= number << (totalSize * 8)
+		totalSize += size
+	}
+
+	// A float has 53 bits of precision. The upper bits are used because the lower bits of some engines are of lower quality.
+	return values.NewFloat(float64(result>>11) / (1 << 53)), nil
+}
+
+func randomizerNextInt(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	engine, _, err := randomizerEngine(object, funcParamValidator.NewValidator(randomizerClassName+"::nextInt"), args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	number, _, err := engine.generate(context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(int64(number >> 1)), nil
+}
+
+func randomizerPickArrayKeys(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	functionName := randomizerClassName + "::pickArrayKeys"
+	engine, args, err := randomizerEngine(object,
+		funcParamValidator.NewValidator(functionName).AddParam("$array", arrayType, nil).AddParam("$num", intType, nil),
+		args,
+	)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	array := args[0].(*values.Array)
+	num := args[1].(*values.Int).Value
+	if len(array.Keys) == 0 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: %s(): Argument #1 ($array) cannot be empty", functionName)
+	}
+	if num < 1 || num > int64(len(array.Keys)) {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: %s(): Argument #2 ($num) must be between 1 and the number of elements in argument #1 ($array)", functionName,
+		)
+	}
+
+	keys, err := pickArrayKeys(engine, context, array, int(num))
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewArrayFromSlice(keys), nil
+}
+
+func randomizerShuffleArray(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	engine, args, err := randomizerEngine(object,
+		funcParamValidator.NewValidator(randomizerClassName+"::shuffleArray").AddParam("$array", arrayType, nil),
+		args,
+	)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	result, err := shuffleArray(engine, context, args[0].(*values.Array))
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return result, nil
+}
+
+func randomizerShuffleBytes(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	engine, args, err := randomizerEngine(object,
+		funcParamValidator.NewValidator(randomizerClassName+"::shuffleBytes").AddParam("$bytes", stringType, nil),
+		args,
+	)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	result, err := shuffleBytes(engine, context, args[0].(*values.Str).Value)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewStr(result), nil
+}
@@ -0,0 +1,100 @@
+package random
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"encoding/binary"
+	"math/bits"
+)
+
+// Spec: https://www.php.net/manual/en/class.random-engine-xoshiro256starstar.php
+// The implementation follows ext/random/engine_xoshiro256starstar.c so that seeded sequences are identical to PHP.
+
+var (
+	xoshiroJump     = [4]uint64{0x180EC6D33CFD0ABA, 0xD5A61266F0C9392C, 0xA9582618E03FC9AA, 0x39ABDC4529B1661C}
+	xoshiroLongJump = [4]uint64{0x76E15D3EFEFDCBBF, 0xC5004E441C522FB3, 0x77710069854EE241, 0x39109BB02ACBE635}
+)
+
+type xoshiro256StarStar struct {
+	state [4]uint64
+}
+
+// Create an engine from a 64 bit seed that is expanded with SplitMix64
+func newXoshiro256StarStar(seed uint64) *xoshiro256StarStar {
+	splitMix64 := func() uint64 {
+		seed += 0x9E3779B97F4A7C15
+		result := seed
+		result = (result ^ (result >> 30)) * 0xBF58476D1CE4E5B9
+		result = (result ^ (result >> 27)) * 0x94D049BB133111EB
+		return result ^ (result >> 31)
+	}
+
+	engine := &xoshiro256StarStar{}
+	for i := range engine.state {
+		engine.state[i] = splitMix64()
+	}
+	return engine
+}
+
+// Create an engine from 32 bytes of seed. The state must not be all zeros.
+func newXoshiro256StarStarFromBytes(seed []byte) *xoshiro256StarStar {
+	engine := &xoshiro256StarStar{}
+	for i := range engine.state {
+		engine.state[i] = binary.LittleEndian.Uint64(seed[i*8:])
+	}
+	return engine
+}
+
+// Create an engine with a random state
+func newRandomXoshiro256StarStar() (*xoshiro256StarStar, phpError.Error) {
+	for {
+		bytes, err := secureBytes(32)
+		if err != nil {
+			return nil, err
+		}
+		if engine := newXoshiro256StarStarFromBytes(bytes); !engine.isZero() {
+			return engine, nil
+		}
+	}
+}
+
+func (engine *xoshiro256StarStar) isZero() bool {
+	return engine.state == [4]uint64{}
+}
+
+func (engine *xoshiro256StarStar) next() uint64 {
+	state := &engine.state
+	result := bits.RotateLeft64(state[1]*5, 7) * 9
+	t := state[1] << 17
+
+	state[2] ^= state[0]
+	state[3] ^= state[1]
+	state[1] ^= state[2]
+	state[0] ^= state[3]
+
+	state[2] ^= t
+
+	state[3] = bits.RotateLeft64(state[3], 45)
+
+	return result
+}
+
+func (engine *xoshiro256StarStar) generate(_ runtime.Context) (uint64, int, phpError.Error) {
+	return engine.next(), 8, nil
+}
+
+// Advance the state by 2^128 (jump) or 2^192 (long jump) steps
+func (engine *xoshiro256StarStar) jump(polynomial [4]uint64) {
+	var state [4]uint64
+	for _, word := range polynomial {
+		for bit := range 64 {
+			if word&(1<<bit) != 0 {
+				for i := range state {
+					state[i] ^= engine.state[i]
+				}
+			}
+			engine.next()
+		}
+	}
+	engine.state = state
+}
@@ -0,0 +1,373 @@
+package spl
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/array"
+	"QIQ/cmd/qiq/runtime/values"
+	"strings"
+)
+
+// Internal state of ArrayObject and ArrayIterator
+type arrayStorage struct {
+	array         *values.Array
+	flags         int64
+	iteratorClass string
+	position      int
+}
+
+func (storage *arrayStorage) GetReferences() []values.RuntimeValue {
+	return []values.RuntimeValue{storage.array}
+}
+
+func getArrayStorage(object *values.Object) *arrayStorage {
+	if object.Internal == nil {
+		object.Internal = newArrayStorage(values.NewArray(), 0, "ArrayIterator")
+	}
+	return object.Internal.(*arrayStorage)
+}
+
+func newArrayStorage(array *values.Array, flags int64, iteratorClass string) *arrayStorage {
+	values.Retain(array)
+	return &arrayStorage{array: array, flags: flags, iteratorClass: iteratorClass}
+}
+
+// Replace the stored array and move the reference to the new array
+func (storage *arrayStorage) setArray(array *values.Array) {
+	values.Retain(array)
+	values.Release(storage.array)
+	storage.array = array
+}
+
+// Get a copy of the array or the properties of the object passed to the constructor or exchangeArray
+func toStorageArray(value values.RuntimeValue) *values.Array {
+	if value.GetType() == values.ArrayValue {
+		return values.DeepCopy(values.NewSlot(value)).Value.(*values.Array)
+	}
+
+	object := value.(*values.Object)
+	if storage, ok := object.Internal.(*arrayStorage); ok {
+		return values.DeepCopy(values.NewSlot(storage.array)).Value.(*values.Array)
+	}
+	result := values.NewArray()
+	for _, propertyName := range object.PropertyNames {
+		if propertyValue, found := object.GetProperty(propertyName); found {
+			result.SetElement(values.NewStr(strings.TrimPrefix(propertyName, "$")), propertyValue)
+		}
+	}
+	return result
+}
+
+// -------------------------------------- ArrayObject -------------------------------------- MARK: ArrayObject
+
+func registerArrayObject(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.arrayobject.php
+	class := runtime.NewNativeClass(interpreter, "ArrayObject").
+		Implements("IteratorAggregate", "ArrayAccess", "Countable").
+		AddConst("STD_PROP_LIST", newIntLiteral(1)).
+		AddConst("ARRAY_AS_PROPS", newIntLiteral(2)).
+		AddMethod("__construct", []ast.FunctionParameter{
+			runtime.NewOptionalParam("$array", ast.NewArrayLiteralExpr(0, nil), "array", "object"),
+			runtime.NewOptionalParam("$flags", newIntLiteral(0), "int"),
+			runtime.NewOptionalParam("$iteratorClass", ast.NewStringLiteralExpr(0, nil, "ArrayIterator", ast.SingleQuotedString), "string"),
+		}, nil, arrayObjectConstruct).
+		AddMethod("exchangeArray", []ast.FunctionParameter{runtime.NewParam("$array", "array", "object")}, arrayType, arrayObjectExchangeArray).
+		AddMethod("getIterator", []ast.FunctionParameter{}, []string{"Iterator"}, arrayObjectGetIterator).
+		AddMethod("getIteratorClass", []ast.FunctionParameter{}, []string{"string"}, arrayObjectGetIteratorClass).
+		AddMethod("setIteratorClass", []ast.FunctionParameter{runtime.NewParam("$iteratorClass", "string")}, voidType, arrayObjectSetIteratorClass)
+	addArrayStorageMethods(class)
+	class.Register()
+}
+
+func arrayObjectConstruct(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("ArrayObject::__construct").
+		AddParam("$array", []string{"array", "object"}, values.NewArray()).
+		AddParam("$flags", intType, values.NewInt(0)).
+		AddParam("$iteratorClass", []string{"string"}, values.NewStr("ArrayIterator")).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	storage := getArrayStorage(object)
+	storage.setArray(toStorageArray(args[0]))
+	storage.flags = args[1].(*values.Int).Value
+	storage.iteratorClass = args[2].(*values.Str).Value
+	return values.NewVoid(), nil
+}
+
+func arrayObjectExchangeArray(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("ArrayObject::exchangeArray").
+		AddParam("$array", []string{"array", "object"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	storage := getArrayStorage(object)
+	oldArray := storage.array
+	storage.setArray(toStorageArray(args[0]))
+	return oldArray, nil
+}
+
+func arrayObjectGetIterator(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("ArrayObject::getIterator").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	storage := getArrayStorage(object)
+	iterator, err := newObject(context.Interpreter, storage.iteratorClass)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	// The iterator works on the same array as the ArrayObject
+	iterator.Internal = newArrayStorage(storage.array, storage.flags, "")
+	return iterator, nil
+}
+
+func arrayObjectGetIteratorClass(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("ArrayObject::getIteratorClass").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(getArrayStorage(object).iteratorClass), nil
+}
+
+func arrayObjectSetIteratorClass(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("ArrayObject::setIteratorClass").
+		AddParam("$iteratorClass", []string{"string"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	className := args[0].(*values.Str).Value
+	classDecl, found := context.Interpreter.GetClass(className)
+	if !found || !context.Interpreter.GetExectionContext().IsInstanceOf(classDecl, "ArrayIterator") {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught TypeError: ArrayObject::setIteratorClass(): Argument #1 ($iteratorClass) must be a class name derived from ArrayIterator, %s given",
+			className,
+		)
+	}
+
+	getArrayStorage(object).iteratorClass = classDecl.GetQualifiedName()
+	return values.NewVoid(), nil
+}
+
+// -------------------------------------- ArrayIterator -------------------------------------- MARK: ArrayIterator
+
+func registerArrayIterator(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.arrayiterator.php
+	class := runtime.NewNativeClass(interpreter, "ArrayIterator").
+		Implements("SeekableIterator", "ArrayAccess", "Countable").
+		AddConst("STD_PROP_LIST", newIntLiteral(1)).
+		AddConst("ARRAY_AS_PROPS", newIntLiteral(2)).
+		AddMethod("__construct", []ast.FunctionParameter{
+			runtime.NewOptionalParam("$array", ast.NewArrayLiteralExpr(0, nil), "array", "object"),
+			runtime.NewOptionalParam("$flags", newIntLiteral(0), "int"),
+		}, nil, arrayIteratorConstruct).
+		AddMethod("current", []ast.FunctionParameter{}, mixedType, arrayIteratorCurrent).
+		AddMethod("key", []ast.FunctionParameter{}, []string{"string", "int", "null"}, arrayIteratorKey).
+		AddMethod("next", []ast.FunctionParameter{}, voidType, arrayIteratorNext).
+		AddMethod("rewind", []ast.FunctionParameter{}, voidType, arrayIteratorRewind).
+		AddMethod("seek", []ast.FunctionParameter{runtime.NewParam("$offset", "int")}, voidType, arrayIteratorSeek).
+		AddMethod("valid", []ast.FunctionParameter{}, boolType, arrayIteratorValid)
+	addArrayStorageMethods(class)
+	class.Register()
+}
+
+func arrayIteratorConstruct(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("ArrayIterator::__construct").
+		AddParam("$array", []string{"array", "object"}, values.NewArray()).
+		AddParam("$flags", intType, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	storage := getArrayStorage(object)
+	storage.setArray(toStorageArray(args[0]))
+	storage.flags = args[1].(*values.Int).Value
+	return values.NewVoid(), nil
+}
+
+func arrayIteratorCurrent(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("ArrayIterator::current").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	storage := getArrayStorage(object)
+	if storage.position >= len(storage.array.Keys) {
+		return values.NewNull(), nil
+	}
+	slot, _ := storage.array.GetElement(storage.array.Keys[storage.position])
+	return slot.Value, nil
+}
+
+func arrayIteratorKey(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("ArrayIterator::key").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	storage := getArrayStorage(object)
+	if storage.position >= len(storage.array.Keys) {
+		return values.NewNull(), nil
+	}
+	return storage.array.Keys[storage.position], nil
+}
+
+func arrayIteratorNext(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("ArrayIterator::next").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	getArrayStorage(object).position++
+	return values.NewVoid(), nil
+}
+
+func arrayIteratorRewind(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("ArrayIterator::rewind").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	getArrayStorage(object).position = 0
+	return values.NewVoid(), nil
+}
+
+func arrayIteratorSeek(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("ArrayIterator::seek").AddParam("$offset", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	storage := getArrayStorage(object)
+	offset := args[0].(*values.Int).Value
+	if offset < 0 || offset >= int64(len(storage.array.Keys)) {
+		return values.NewVoid(), newException("OutOfBoundsException", "Seek position %d is out of range", offset)
+	}
+	storage.position = int(offset)
+	return values.NewVoid(), nil
+}
+
+func arrayIteratorValid(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("ArrayIterator::valid").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	storage := getArrayStorage(object)
+	return values.NewBool(storage.position < len(storage.array.Keys)), nil
+}
+
+// -------------------------------------- Shared methods -------------------------------------- MARK: Shared methods
+
+// Add the methods ArrayObject and ArrayIterator have in common
+func addArrayStorageMethods(class *runtime.NativeClass) {
+	className := class.Decl.Name
+
+	class.
+		AddMethod("append", []ast.FunctionParameter{runtime.NewParam("$value", "mixed")}, voidType,
+			func(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+				args, err := funcParamValidator.NewValidator(className+"::append").AddParam("$value", mixedType, nil).Validate(args)
+				if err != nil {
+					return values.NewVoid(), err
+				}
+
+				return values.NewVoid(), getArrayStorage(object).array.SetElement(nil, args[0])
+			},
+		).
+		AddMethod("count", []ast.FunctionParameter{}, intType,
+			func(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+				if _, err := funcParamValidator.NewValidator(className + "::count").Validate(args); err != nil {
+					return values.NewVoid(), err
+				}
+
+				return values.NewInt(int64(len(getArrayStorage(object).array.Keys))), nil
+			},
+		).
+		AddMethod("getArrayCopy", []ast.FunctionParameter{}, arrayType,
+			func(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+				if _, err := funcParamValidator.NewValidator(className + "::getArrayCopy").Validate(args); err != nil {
+					return values.NewVoid(), err
+				}
+
+				return values.DeepCopy(values.NewSlot(getArrayStorage(object).array)).Value, nil
+			},
+		).
+		AddMethod("getFlags", []ast.FunctionParameter{}, intType,
+			func(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+				if _, err := funcParamValidator.NewValidator(className + "::getFlags").Validate(args); err != nil {
+					return values.NewVoid(), err
+				}
+
+				return values.NewInt(getArrayStorage(object).flags), nil
+			},
+		).
+		AddMethod("setFlags", []ast.FunctionParameter{runtime.NewParam("$flags", "int")}, voidType,
+			func(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+				args, err := funcParamValidator.NewValidator(className+"::setFlags").AddParam("$flags", intType, nil).Validate(args)
+				if err != nil {
+					return values.NewVoid(), err
+				}
+
+				getArrayStorage(object).flags = args[0].(*values.Int).Value
+				return values.NewVoid(), nil
+			},
+		).
+		AddMethod("offsetExists", []ast.FunctionParameter{runtime.NewParam("$offset", "mixed")}, boolType,
+			func(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+				args, err := funcParamValidator.NewValidator(className+"::offsetExists").AddParam("$key", mixedType, nil).Validate(args)
+				if err != nil {
+					return values.NewVoid(), err
+				}
+
+				return values.NewBool(getArrayStorage(object).array.Contains(args[0])), nil
+			},
+		).
+		AddMethod("offsetGet", []ast.FunctionParameter{runtime.NewParam("$offset", "mixed")}, mixedType,
+			func(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+				args, err := funcParamValidator.NewValidator(className+"::offsetGet").AddParam("$key", mixedType, nil).Validate(args)
+				if err != nil {
+					return values.NewVoid(), err
+				}
+
+				slot, found := getArrayStorage(object).array.GetElement(args[0])
+				if !found {
+					return values.NewNull(), nil
+				}
+				return slot.Value, nil
+			},
+		).
+		AddMethod("offsetSet", []ast.FunctionParameter{runtime.NewParam("$offset", "mixed"), runtime.NewParam("$value", "mixed")}, voidType,
+			func(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+				args, err := funcParamValidator.NewValidator(className+"::offsetSet").
+					AddParam("$key", mixedType, nil).AddParam("$value", mixedType, nil).
+					Validate(args)
+				if err != nil {
+					return values.NewVoid(), err
+				}
+
+				var key values.RuntimeValue = args[0]
+				if key.GetType() == values.NullValue {
+					key = nil
+				}
+				return values.NewVoid(), getArrayStorage(object).array.SetElement(key, args[1])
+			},
+		).
+		AddMethod("offsetUnset", []ast.FunctionParameter{runtime.NewParam("$offset", "mixed")}, voidType,
+			func(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+				args, err := funcParamValidator.NewValidator(className+"::offsetUnset").AddParam("$key", mixedType, nil).Validate(args)
+				if err != nil {
+					return values.NewVoid(), err
+				}
+
+				storage := getArrayStorage(object)
+				key, found := findKey(storage.array, args[0])
+				if !found {
+					return values.NewVoid(), nil
+				}
+				return values.NewVoid(), array.RemoveByKey(storage.array, key)
+			},
+		)
+}
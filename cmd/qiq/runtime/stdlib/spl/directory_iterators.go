@@ -0,0 +1,453 @@
+package spl
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/filesystem"
+	"QIQ/cmd/qiq/runtime/stream"
+	"QIQ/cmd/qiq/runtime/values"
+	"os"
+)
+
+// Spec: https://www.php.net/manual/en/class.filesystemiterator.php#filesystemiterator.constants
+const (
+	currentAsPathname int64 = 32
+	currentAsFileInfo int64 = 0
+	currentAsSelf     int64 = 16
+	currentModeMask   int64 = 240
+	keyAsPathname     int64 = 0
+	keyAsFilename     int64 = 256
+	followSymlinks    int64 = 16384
+	keyModeMask       int64 = 3840
+	newCurrentAndKey  int64 = keyAsFilename | currentAsFileInfo
+	otherModeMask     int64 = 28672
+	skipDots          int64 = 4096
+	unixPaths         int64 = 8192
+)
+
+// Iteration state of DirectoryIterator and its subclasses
+type directoryIterator struct {
+	// Path of the directory without trailing slash
+	path    string
+	entries []string
+	// Position in the entries
+	position int
+	// Key of the current entry. Skipped dot entries are not counted.
+	index int64
+	flags int64
+	// Path of the directory relative to the directory of the RecursiveDirectoryIterator that started the iteration
+	subPath string
+}
+
+// Get the name of the current entry or "" if all entries were read
+func (iterator *directoryIterator) current() string {
+	if iterator.position >= len(iterator.entries) {
+		return ""
+	}
+	return iterator.entries[iterator.position]
+}
+
+func (iterator *directoryIterator) isDot() bool {
+	entry := iterator.current()
+	return entry == "." || entry == ".."
+}
+
+func (iterator *directoryIterator) skipDots() {
+	for iterator.flags&skipDots != 0 && iterator.isDot() {
+		iterator.position++
+	}
+}
+
+func (iterator *directoryIterator) rewind() {
+	iterator.position = 0
+	iterator.index = 0
+	iterator.skipDots()
+}
+
+func (iterator *directoryIterator) next() {
+	iterator.position++
+	iterator.index++
+	iterator.skipDots()
+}
+
+// Open the directory of a DirectoryIterator. Port of spl_filesystem_dir_open.
+func openDirectoryIterator(object *values.Object, className string, path string, flags int64, context runtime.Context) phpError.Error {
+	if path == "" {
+		return phpError.NewError("Uncaught ValueError: %s::__construct(): Argument #1 ($directory) cannot be empty", className)
+	}
+
+	entries, err := stream.ReadDir(path, context)
+	if err != nil {
+		return newException("UnexpectedValueException", "%s::__construct(%s): Failed to open directory: %s", className, path, err)
+	}
+	if len(path) > 1 && path[len(path)-1] == '/' {
+		path = path[:len(path)-1]
+	}
+
+	info := newFileInfo()
+	info.directory = &directoryIterator{path: path, entries: entries, flags: flags}
+	info.directory.rewind()
+	object.Internal = info
+	return nil
+}
+
+func getDirectoryIterator(object *values.Object) (*fileInfo, phpError.Error) {
+	info, err := getFileInfo(object)
+	if err != nil {
+		return nil, err
+	}
+	if info.directory == nil {
+		return nil, phpError.NewError("Uncaught Error: Object not initialized")
+	}
+	return info, nil
+}
+
+// -------------------------------------- DirectoryIterator -------------------------------------- MARK: DirectoryIterator
+
+func registerDirectoryIterator(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.directoryiterator.php
+	runtime.NewNativeClass(interpreter, "DirectoryIterator").
+		Extends("SplFileInfo").
+		Implements("SeekableIterator").
+		AddMethod("__construct", []ast.FunctionParameter{runtime.NewParam("$directory", "string")}, nil, directoryIteratorConstruct).
+		AddMethod("__toString", []ast.FunctionParameter{}, stringType, splFileInfoGetFilename).
+		AddMethod("current", []ast.FunctionParameter{}, mixedType, directoryIteratorCurrent).
+		AddMethod("isDot", []ast.FunctionParameter{}, boolType, directoryIteratorIsDot).
+		AddMethod("key", []ast.FunctionParameter{}, mixedType, directoryIteratorKey).
+		AddMethod("next", []ast.FunctionParameter{}, voidType, directoryIteratorNext).
+		AddMethod("rewind", []ast.FunctionParameter{}, voidType, directoryIteratorRewind).
+		AddMethod("seek", []ast.FunctionParameter{runtime.NewParam("$offset", "int")}, voidType, directoryIteratorSeek).
+		AddMethod("valid", []ast.FunctionParameter{}, boolType, directoryIteratorValid).
+		Register()
+}
+
+func directoryIteratorConstruct(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("DirectoryIterator::__construct").AddParam("$directory", stringType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewVoid(), openDirectoryIterator(object, "DirectoryIterator", args[0].(*values.Str).Value, 0, context)
+}
+
+func directoryIteratorCurrent(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("DirectoryIterator::current").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	if _, err := getDirectoryIterator(object); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return object, nil
+}
+
+func directoryIteratorIsDot(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("DirectoryIterator::isDot").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getDirectoryIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(info.directory.isDot()), nil
+}
+
+func directoryIteratorKey(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("DirectoryIterator::key").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getDirectoryIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(info.directory.index), nil
+}
+
+func directoryIteratorNext(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("DirectoryIterator::next").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getDirectoryIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	info.directory.next()
+	return values.NewVoid(), nil
+}
+
+func directoryIteratorRewind(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("DirectoryIterator::rewind").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getDirectoryIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	info.directory.rewind()
+	return values.NewVoid(), nil
+}
+
+func directoryIteratorSeek(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("DirectoryIterator::seek").AddParam("$offset", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getDirectoryIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// The methods are called so that overwritten methods of subclasses are used
+	offset := args[0].(*values.Int).Value
+	if info.directory.index > offset {
+		if _, err := callIteratorMethod(object, "rewind", context); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	for info.directory.index < offset {
+		valid, err := callIteratorMethodBool(object, "valid", context)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		if !valid {
+			return values.NewVoid(), newException("OutOfBoundsException", "Seek position %d is out of range", offset)
+		}
+		if _, err := callIteratorMethod(object, "next", context); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return values.NewVoid(), nil
+}
+
+func directoryIteratorValid(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("DirectoryIterator::valid").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getDirectoryIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(info.directory.current() != ""), nil
+}
+
+// -------------------------------------- FilesystemIterator -------------------------------------- MARK: FilesystemIterator
+
+func registerFilesystemIterator(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.filesystemiterator.php
+	runtime.NewNativeClass(interpreter, "FilesystemIterator").
+		Extends("DirectoryIterator").
+		AddConst("CURRENT_MODE_MASK", newIntLiteral(currentModeMask)).
+		AddConst("CURRENT_AS_PATHNAME", newIntLiteral(currentAsPathname)).
+		AddConst("CURRENT_AS_FILEINFO", newIntLiteral(currentAsFileInfo)).
+		AddConst("CURRENT_AS_SELF", newIntLiteral(currentAsSelf)).
+		AddConst("KEY_MODE_MASK", newIntLiteral(keyModeMask)).
+		AddConst("KEY_AS_PATHNAME", newIntLiteral(keyAsPathname)).
+		AddConst("FOLLOW_SYMLINKS", newIntLiteral(followSymlinks)).
+		AddConst("KEY_AS_FILENAME", newIntLiteral(keyAsFilename)).
+		AddConst("NEW_CURRENT_AND_KEY", newIntLiteral(newCurrentAndKey)).
+		AddConst("OTHER_MODE_MASK", newIntLiteral(otherModeMask)).
+		AddConst("SKIP_DOTS", newIntLiteral(skipDots)).
+		AddConst("UNIX_PATHS", newIntLiteral(unixPaths)).
+		AddMethod("__construct", []ast.FunctionParameter{
+			runtime.NewParam("$directory", "string"),
+			runtime.NewOptionalParam("$flags", newIntLiteral(keyAsPathname|currentAsFileInfo|skipDots), "int"),
+		}, nil, filesystemIteratorConstruct).
+		AddMethod("current", []ast.FunctionParameter{}, []string{"string", "SplFileInfo", "FilesystemIterator"}, filesystemIteratorCurrent).
+		AddMethod("getFlags", []ast.FunctionParameter{}, intType, filesystemIteratorGetFlags).
+		AddMethod("key", []ast.FunctionParameter{}, stringType, filesystemIteratorKey).
+		AddMethod("setFlags", []ast.FunctionParameter{runtime.NewParam("$flags", "int")}, voidType, filesystemIteratorSetFlags).
+		Register()
+}
+
+func filesystemIteratorConstruct(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("FilesystemIterator::__construct").
+		AddParam("$directory", stringType, nil).
+		AddParam("$flags", intType, values.NewInt(keyAsPathname|currentAsFileInfo|skipDots)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewVoid(), openDirectoryIterator(object, "FilesystemIterator", args[0].(*values.Str).Value, args[1].(*values.Int).Value, context)
+}
+
+func filesystemIteratorCurrent(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("FilesystemIterator::current").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getDirectoryIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	switch {
+	case info.directory.flags&currentAsPathname != 0:
+		return values.NewStr(info.getPathname()), nil
+	case info.directory.flags&currentAsSelf != 0:
+		return object, nil
+	default:
+		return newFileInfoObject(info.infoClass, context, values.NewStr(info.getPathname()))
+	}
+}
+
+func filesystemIteratorGetFlags(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("FilesystemIterator::getFlags").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getDirectoryIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(info.directory.flags & (keyModeMask | currentModeMask | otherModeMask)), nil
+}
+
+func filesystemIteratorKey(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("FilesystemIterator::key").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getDirectoryIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if info.directory.flags&keyAsFilename != 0 {
+		return values.NewStr(info.directory.current()), nil
+	}
+	return values.NewStr(info.getPathname()), nil
+}
+
+func filesystemIteratorSetFlags(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("FilesystemIterator::setFlags").AddParam("$flags", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getDirectoryIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	mask := keyModeMask | currentModeMask | otherModeMask
+	info.directory.flags = info.directory.flags&^mask | args[0].(*values.Int).Value&mask
+	return values.NewVoid(), nil
+}
+
+// -------------------------------------- RecursiveDirectoryIterator -------------------------------------- MARK: RecursiveDirectoryIterator
+
+func registerRecursiveDirectoryIterator(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.recursivedirectoryiterator.php
+	runtime.NewNativeClass(interpreter, "RecursiveDirectoryIterator").
+		Extends("FilesystemIterator").
+		Implements("RecursiveIterator").
+		AddMethod("__construct", []ast.FunctionParameter{
+			runtime.NewParam("$directory", "string"),
+			runtime.NewOptionalParam("$flags", newIntLiteral(keyAsPathname|currentAsFileInfo), "int"),
+		}, nil, recursiveDirectoryIteratorConstruct).
+		AddMethod("getChildren", []ast.FunctionParameter{}, []string{"RecursiveDirectoryIterator"}, recursiveDirectoryIteratorGetChildren).
+		AddMethod("getSubPath", []ast.FunctionParameter{}, stringType, recursiveDirectoryIteratorGetSubPath).
+		AddMethod("getSubPathname", []ast.FunctionParameter{}, stringType, recursiveDirectoryIteratorGetSubPathname).
+		AddMethod("hasChildren", []ast.FunctionParameter{
+			runtime.NewOptionalParam("$allowLinks", ast.NewConstantAccessExpr(0, nil, "FALSE"), "bool"),
+		}, boolType, recursiveDirectoryIteratorHasChildren).
+		Register()
+}
+
+func recursiveDirectoryIteratorConstruct(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("RecursiveDirectoryIterator::__construct").
+		AddParam("$directory", stringType, nil).
+		AddParam("$flags", intType, values.NewInt(keyAsPathname|currentAsFileInfo)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewVoid(), openDirectoryIterator(object, "RecursiveDirectoryIterator", args[0].(*values.Str).Value, args[1].(*values.Int).Value, context)
+}
+
+func recursiveDirectoryIteratorGetChildren(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("RecursiveDirectoryIterator::getChildren").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getDirectoryIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// The children are iterated by an instance of the same class
+	child := values.NewObject(object.Class)
+	if _, err := callIteratorMethod(child, "__construct", context, values.NewStr(info.getPathname()), values.NewInt(info.directory.flags)); err != nil {
+		return values.NewVoid(), err
+	}
+	childInfo, err := getDirectoryIterator(child)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	childInfo.directory.subPath = info.directory.current()
+	if info.directory.subPath != "" {
+		childInfo.directory.subPath = info.directory.subPath + "/" + info.directory.current()
+	}
+	childInfo.infoClass = info.infoClass
+	childInfo.fileClass = info.fileClass
+	return child, nil
+}
+
+func recursiveDirectoryIteratorGetSubPath(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("RecursiveDirectoryIterator::getSubPath").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getDirectoryIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(info.directory.subPath), nil
+}
+
+func recursiveDirectoryIteratorGetSubPathname(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("RecursiveDirectoryIterator::getSubPathname").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getDirectoryIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if info.directory.subPath != "" {
+		return values.NewStr(info.directory.subPath + "/" + info.directory.current()), nil
+	}
+	return values.NewStr(info.directory.current()), nil
+}
+
+func recursiveDirectoryIteratorHasChildren(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("RecursiveDirectoryIterator::hasChildren").
+		AddParam("$allowLinks", boolType, values.NewBool(false)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getDirectoryIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if info.directory.current() == "" || info.directory.isDot() {
+		return values.NewBool(false), nil
+	}
+	pathname := info.getPathname()
+	// Symbolic links are only followed if allowed
+	if !args[0].(*values.Bool).Value && info.directory.flags&followSymlinks == 0 {
+		linkInfo, goErr := filesystem.StatFile(pathname, true, context)
+		if goErr != nil || linkInfo.Mode()&os.ModeSymlink != 0 {
+			return values.NewBool(false), nil
+		}
+	}
+	fileInfo, goErr := filesystem.StatFile(pathname, false, context)
+	return values.NewBool(goErr == nil && fileInfo.IsDir()), nil
+}
@@ -0,0 +1,394 @@
+package spl
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+	"slices"
+)
+
+const (
+	itModeFifo   int64 = 0
+	itModeLifo   int64 = 2
+	itModeDelete int64 = 1
+)
+
+// Internal state of SplDoublyLinkedList, SplStack and SplQueue
+type doublyLinkedList struct {
+	elements []values.RuntimeValue
+	mode     int64
+	position int
+	// SplStack and SplQueue do not allow to change the direction
+	isFrozen bool
+}
+
+func (list *doublyLinkedList) GetReferences() []values.RuntimeValue { return list.elements }
+
+func (list *doublyLinkedList) insert(index int, value values.RuntimeValue) {
+	list.elements = slices.Insert(list.elements, index, value)
+	values.Retain(value)
+}
+
+func (list *doublyLinkedList) remove(index int) values.RuntimeValue {
+	value := list.elements[index]
+	list.elements = slices.Delete(list.elements, index, index+1)
+	values.Release(value)
+	return value
+}
+
+func getDoublyLinkedList(object *values.Object, context runtime.Context) *doublyLinkedList {
+	if object.Internal == nil {
+		list := &doublyLinkedList{elements: []values.RuntimeValue{}, mode: itModeFifo}
+		executionContext := context.Interpreter.GetExectionContext()
+		if executionContext.IsInstanceOf(object.Class, "SplStack") {
+			list.mode = itModeLifo
+			list.isFrozen = true
+		} else if executionContext.IsInstanceOf(object.Class, "SplQueue") {
+			list.isFrozen = true
+		}
+		object.Internal = list
+	}
+	return object.Internal.(*doublyLinkedList)
+}
+
+func (list *doublyLinkedList) isLifo() bool { return list.mode&itModeLifo != 0 }
+
+func (list *doublyLinkedList) toIndex(offset values.RuntimeValue, methodName string, allowAppend bool) (int, phpError.Error) {
+	index, err := toIndex(offset, "SplDoublyLinkedList")
+	if err != nil {
+		return 0, err
+	}
+	maxIndex := len(list.elements) - 1
+	if allowAppend {
+		maxIndex++
+	}
+	if index < 0 || index > maxIndex {
+		return 0, newException("OutOfRangeException", "SplDoublyLinkedList::%s(): Argument #1 ($index) is out of range", methodName)
+	}
+	return index, nil
+}
+
+// -------------------------------------- SplDoublyLinkedList -------------------------------------- MARK: SplDoublyLinkedList
+
+func registerSplDoublyLinkedList(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.spldoublylinkedlist.php
+	runtime.NewNativeClass(interpreter, "SplDoublyLinkedList").
+		Implements("Iterator", "Countable", "ArrayAccess").
+		AddConst("IT_MODE_LIFO", newIntLiteral(itModeLifo)).
+		AddConst("IT_MODE_FIFO", newIntLiteral(itModeFifo)).
+		AddConst("IT_MODE_DELETE", newIntLiteral(itModeDelete)).
+		AddConst("IT_MODE_KEEP", newIntLiteral(0)).
+		AddMethod("add", []ast.FunctionParameter{runtime.NewParam("$index", "int"), runtime.NewParam("$value", "mixed")}, voidType, splDoublyLinkedListAdd).
+		AddMethod("bottom", []ast.FunctionParameter{}, mixedType, splDoublyLinkedListBottom).
+		AddMethod("count", []ast.FunctionParameter{}, intType, splDoublyLinkedListCount).
+		AddMethod("current", []ast.FunctionParameter{}, mixedType, splDoublyLinkedListCurrent).
+		AddMethod("getIteratorMode", []ast.FunctionParameter{}, intType, splDoublyLinkedListGetIteratorMode).
+		AddMethod("isEmpty", []ast.FunctionParameter{}, boolType, splDoublyLinkedListIsEmpty).
+		AddMethod("key", []ast.FunctionParameter{}, intType, splDoublyLinkedListKey).
+		AddMethod("next", []ast.FunctionParameter{}, voidType, splDoublyLinkedListNext).
+		AddMethod("offsetExists", []ast.FunctionParameter{runtime.NewParam("$offset", "mixed")}, boolType, splDoublyLinkedListOffsetExists).
+		AddMethod("offsetGet", []ast.FunctionParameter{runtime.NewParam("$offset", "mixed")}, mixedType, splDoublyLinkedListOffsetGet).
+		AddMethod("offsetSet", []ast.FunctionParameter{runtime.NewParam("$offset", "mixed"), runtime.NewParam("$value", "mixed")}, voidType, splDoublyLinkedListOffsetSet).
+		AddMethod("offsetUnset", []ast.FunctionParameter{runtime.NewParam("$offset", "mixed")}, voidType, splDoublyLinkedListOffsetUnset).
+		AddMethod("pop", []ast.FunctionParameter{}, mixedType, splDoublyLinkedListPop).
+		AddMethod("prev", []ast.FunctionParameter{}, voidType, splDoublyLinkedListPrev).
+		AddMethod("push", []ast.FunctionParameter{runtime.NewParam("$value", "mixed")}, voidType, splDoublyLinkedListPush).
+		AddMethod("rewind", []ast.FunctionParameter{}, voidType, splDoublyLinkedListRewind).
+		AddMethod("setIteratorMode", []ast.FunctionParameter{runtime.NewParam("$mode", "int")}, intType, splDoublyLinkedListSetIteratorMode).
+		AddMethod("shift", []ast.FunctionParameter{}, mixedType, splDoublyLinkedListShift).
+		AddMethod("top", []ast.FunctionParameter{}, mixedType, splDoublyLinkedListTop).
+		AddMethod("toArray", []ast.FunctionParameter{}, arrayType, splDoublyLinkedListToArray).
+		AddMethod("unshift", []ast.FunctionParameter{runtime.NewParam("$value", "mixed")}, voidType, splDoublyLinkedListUnshift).
+		AddMethod("valid", []ast.FunctionParameter{}, boolType, splDoublyLinkedListValid).
+		Register()
+
+	// Spec: https://www.php.net/manual/en/class.splstack.php
+	runtime.NewNativeClass(interpreter, "SplStack").Extends("SplDoublyLinkedList").Register()
+
+	// Spec: https://www.php.net/manual/en/class.splqueue.php
+	runtime.NewNativeClass(interpreter, "SplQueue").
+		Extends("SplDoublyLinkedList").
+		AddMethod("enqueue", []ast.FunctionParameter{runtime.NewParam("$value", "mixed")}, voidType, splDoublyLinkedListPush).
+		AddMethod("dequeue", []ast.FunctionParameter{}, mixedType, splDoublyLinkedListShift).
+		Register()
+}
+
+func splDoublyLinkedListAdd(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplDoublyLinkedList::add").
+		AddParam("$index", intType, nil).AddParam("$value", mixedType, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	index, err := list.toIndex(args[0], "add", true)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	list.insert(index, args[1])
+	return values.NewVoid(), nil
+}
+
+func splDoublyLinkedListBottom(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplDoublyLinkedList::bottom").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	if len(list.elements) == 0 {
+		return values.NewVoid(), newException("RuntimeException", "Can't peek at an empty datastructure")
+	}
+	return list.elements[0], nil
+}
+
+func splDoublyLinkedListCount(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplDoublyLinkedList::count").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(int64(len(getDoublyLinkedList(object, context).elements))), nil
+}
+
+func splDoublyLinkedListCurrent(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplDoublyLinkedList::current").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	if list.position < 0 || list.position >= len(list.elements) {
+		return values.NewNull(), nil
+	}
+	return list.elements[list.position], nil
+}
+
+func splDoublyLinkedListGetIteratorMode(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplDoublyLinkedList::getIteratorMode").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(getDoublyLinkedList(object, context).mode), nil
+}
+
+func splDoublyLinkedListIsEmpty(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplDoublyLinkedList::isEmpty").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(len(getDoublyLinkedList(object, context).elements) == 0), nil
+}
+
+func splDoublyLinkedListKey(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplDoublyLinkedList::key").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(int64(getDoublyLinkedList(object, context).position)), nil
+}
+
+func splDoublyLinkedListNext(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplDoublyLinkedList::next").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	if list.mode&itModeDelete != 0 {
+		if list.position >= 0 && list.position < len(list.elements) {
+			list.remove(list.position)
+		}
+		if list.isLifo() {
+			list.position = len(list.elements) - 1
+		}
+		return values.NewVoid(), nil
+	}
+	if list.isLifo() {
+		list.position--
+	} else {
+		list.position++
+	}
+	return values.NewVoid(), nil
+}
+
+func splDoublyLinkedListOffsetExists(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplDoublyLinkedList::offsetExists").AddParam("$index", mixedType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	index, err := toIndex(args[0], "SplDoublyLinkedList")
+	if err != nil {
+		return values.NewBool(false), nil
+	}
+	return values.NewBool(index >= 0 && index < len(list.elements)), nil
+}
+
+func splDoublyLinkedListOffsetGet(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplDoublyLinkedList::offsetGet").AddParam("$index", mixedType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	index, err := list.toIndex(args[0], "offsetGet", false)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return list.elements[index], nil
+}
+
+func splDoublyLinkedListOffsetSet(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplDoublyLinkedList::offsetSet").
+		AddParam("$index", mixedType, nil).AddParam("$value", mixedType, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	if args[0].GetType() == values.NullValue {
+		list.insert(len(list.elements), args[1])
+		return values.NewVoid(), nil
+	}
+	index, err := list.toIndex(args[0], "offsetSet", false)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	values.Store(&list.elements[index], args[1])
+	return values.NewVoid(), nil
+}
+
+func splDoublyLinkedListOffsetUnset(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplDoublyLinkedList::offsetUnset").AddParam("$index", mixedType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	index, err := list.toIndex(args[0], "offsetUnset", false)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	list.remove(index)
+	return values.NewVoid(), nil
+}
+
+func splDoublyLinkedListPop(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplDoublyLinkedList::pop").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	if len(list.elements) == 0 {
+		return values.NewVoid(), newException("RuntimeException", "Can't pop from an empty datastructure")
+	}
+	return list.remove(len(list.elements) - 1), nil
+}
+
+func splDoublyLinkedListPrev(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplDoublyLinkedList::prev").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	if list.isLifo() {
+		list.position++
+	} else {
+		list.position--
+	}
+	return values.NewVoid(), nil
+}
+
+func splDoublyLinkedListPush(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplDoublyLinkedList::push").AddParam("$value", mixedType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	list.insert(len(list.elements), args[0])
+	return values.NewVoid(), nil
+}
+
+func splDoublyLinkedListRewind(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplDoublyLinkedList::rewind").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	if list.isLifo() {
+		list.position = len(list.elements) - 1
+	} else {
+		list.position = 0
+	}
+	return values.NewVoid(), nil
+}
+
+func splDoublyLinkedListSetIteratorMode(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplDoublyLinkedList::setIteratorMode").AddParam("$mode", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	mode := args[0].(*values.Int).Value
+	if list.isFrozen && mode&itModeLifo != list.mode&itModeLifo {
+		return values.NewVoid(), newException("RuntimeException", "Iterators' LIFO/FIFO modes for SplStack/SplQueue objects are frozen")
+	}
+	list.mode = mode & (itModeLifo | itModeDelete)
+	return values.NewInt(list.mode), nil
+}
+
+func splDoublyLinkedListShift(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplDoublyLinkedList::shift").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	if len(list.elements) == 0 {
+		return values.NewVoid(), newException("RuntimeException", "Can't shift from an empty datastructure")
+	}
+	return list.remove(0), nil
+}
+
+func splDoublyLinkedListTop(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplDoublyLinkedList::top").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	if len(list.elements) == 0 {
+		return values.NewVoid(), newException("RuntimeException", "Can't peek at an empty datastructure")
+	}
+	return list.elements[len(list.elements)-1], nil
+}
+
+func splDoublyLinkedListToArray(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplDoublyLinkedList::toArray").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewArrayFromSlice(getDoublyLinkedList(object, context).elements), nil
+}
+
+func splDoublyLinkedListUnshift(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplDoublyLinkedList::unshift").AddParam("$value", mixedType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	list.insert(0, args[0])
+	return values.NewVoid(), nil
+}
+
+func splDoublyLinkedListValid(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplDoublyLinkedList::valid").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	list := getDoublyLinkedList(object, context)
+	return values.NewBool(list.position >= 0 && list.position < len(list.elements)), nil
+}
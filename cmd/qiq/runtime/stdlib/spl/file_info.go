@@ -0,0 +1,477 @@
+package spl
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/filesystem"
+	"QIQ/cmd/qiq/runtime/stream"
+	"QIQ/cmd/qiq/runtime/values"
+	"os"
+	"strings"
+)
+
+var stringType = []string{"string"}
+
+// Internal state of SplFileInfo and its subclasses (DirectoryIterator and SplFileObject)
+type fileInfo struct {
+	fileName  string
+	path      string
+	infoClass string
+	fileClass string
+	// Only set for DirectoryIterator and its subclasses
+	directory *directoryIterator
+	// Only set for SplFileObject
+	file *fileObject
+}
+
+func (info *fileInfo) GetReferences() []values.RuntimeValue {
+	if info.file != nil && info.file.currentValue != nil {
+		return []values.RuntimeValue{info.file.currentValue}
+	}
+	return nil
+}
+
+func newFileInfo() *fileInfo {
+	return &fileInfo{infoClass: "SplFileInfo", fileClass: "SplFileObject"}
+}
+
+func getFileInfo(object *values.Object) (*fileInfo, phpError.Error) {
+	info, ok := object.Internal.(*fileInfo)
+	if !ok {
+		return nil, phpError.NewError("Uncaught Error: Object not initialized")
+	}
+	return info, nil
+}
+
+// Set the file name without trailing slashes. The path is the part before the last slash.
+// Port of spl_filesystem_info_set_filename.
+func (info *fileInfo) setFilename(fileName string) {
+	pathLen := len(fileName)
+	for pathLen > 1 && fileName[pathLen-1] == '/' {
+		pathLen--
+	}
+	info.fileName = fileName[:pathLen]
+	for pathLen > 1 && fileName[pathLen-1] != '/' {
+		pathLen--
+	}
+	if pathLen > 0 {
+		pathLen--
+	}
+	info.path = fileName[:pathLen]
+}
+
+func (info *fileInfo) getPath() string {
+	if info.directory != nil {
+		return info.directory.path
+	}
+	return info.path
+}
+
+func (info *fileInfo) getPathname() string {
+	if info.directory != nil {
+		entry := info.directory.current()
+		if entry == "" {
+			return ""
+		}
+		return info.directory.path + "/" + entry
+	}
+	return info.fileName
+}
+
+func (info *fileInfo) getFilename() string {
+	if info.directory != nil {
+		return info.directory.current()
+	}
+	path := info.getPath()
+	if path != "" && len(path) < len(info.fileName) {
+		return info.fileName[len(path)+1:]
+	}
+	return info.fileName
+}
+
+// Create an object of the info or file class for the path name. The constructor is called with the arguments.
+func newFileInfoObject(className string, context runtime.Context, args ...values.RuntimeValue) (*values.Object, phpError.Error) {
+	object, err := newObject(context.Interpreter, className)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := callIteratorMethod(object, "__construct", context, args...); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// Get the class argument of getFileInfo, getPathInfo, setFileClass and setInfoClass. Null is the default class.
+func classArg(methodName string, value values.RuntimeValue, defaultClass string, baseClass string, context runtime.Context) (string, phpError.Error) {
+	if value.GetType() == values.NullValue {
+		return defaultClass, nil
+	}
+	className := value.(*values.Str).Value
+	classDecl, found := context.Interpreter.GetClass(className)
+	if !found || !context.Interpreter.GetExectionContext().IsInstanceOf(classDecl, baseClass) {
+		orNull := ""
+		if strings.HasPrefix(methodName, "get") {
+			orNull = " or null"
+		}
+		return "", phpError.NewError(
+			"Uncaught TypeError: SplFileInfo::%s(): Argument #1 ($class) must be a class name derived from %s%s, %s given",
+			methodName, baseClass, orNull, className,
+		)
+	}
+	return classDecl.GetQualifiedName(), nil
+}
+
+// -------------------------------------- SplFileInfo -------------------------------------- MARK: SplFileInfo
+
+func registerSplFileInfo(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.splfileinfo.php
+	runtime.NewNativeClass(interpreter, "SplFileInfo").
+		Implements("Stringable").
+		AddMethod("__construct", []ast.FunctionParameter{runtime.NewParam("$filename", "string")}, nil, splFileInfoConstruct).
+		AddMethod("__toString", []ast.FunctionParameter{}, stringType, splFileInfoGetPathname).
+		AddMethod("getATime", []ast.FunctionParameter{}, []string{"int", "false"}, fileStatMethod("getATime", "atime")).
+		AddMethod("getBasename", []ast.FunctionParameter{
+			runtime.NewOptionalParam("$suffix", ast.NewStringLiteralExpr(0, nil, "", ast.SingleQuotedString), "string"),
+		}, stringType, splFileInfoGetBasename).
+		AddMethod("getCTime", []ast.FunctionParameter{}, []string{"int", "false"}, fileStatMethod("getCTime", "ctime")).
+		AddMethod("getExtension", []ast.FunctionParameter{}, stringType, splFileInfoGetExtension).
+		AddMethod("getFileInfo", []ast.FunctionParameter{
+			runtime.NewOptionalParam("$class", newNullLiteral(), "string", "null"),
+		}, []string{"SplFileInfo"}, splFileInfoGetFileInfo).
+		AddMethod("getFilename", []ast.FunctionParameter{}, stringType, splFileInfoGetFilename).
+		AddMethod("getGroup", []ast.FunctionParameter{}, []string{"int", "false"}, fileStatMethod("getGroup", "gid")).
+		AddMethod("getInode", []ast.FunctionParameter{}, []string{"int", "false"}, fileStatMethod("getInode", "ino")).
+		AddMethod("getLinkTarget", []ast.FunctionParameter{}, []string{"string", "false"}, splFileInfoGetLinkTarget).
+		AddMethod("getMTime", []ast.FunctionParameter{}, []string{"int", "false"}, fileStatMethod("getMTime", "mtime")).
+		AddMethod("getOwner", []ast.FunctionParameter{}, []string{"int", "false"}, fileStatMethod("getOwner", "uid")).
+		AddMethod("getPath", []ast.FunctionParameter{}, stringType, splFileInfoGetPath).
+		AddMethod("getPathInfo", []ast.FunctionParameter{
+			runtime.NewOptionalParam("$class", newNullLiteral(), "string", "null"),
+		}, []string{"SplFileInfo", "null"}, splFileInfoGetPathInfo).
+		AddMethod("getPathname", []ast.FunctionParameter{}, stringType, splFileInfoGetPathname).
+		AddMethod("getPerms", []ast.FunctionParameter{}, []string{"int", "false"}, fileStatMethod("getPerms", "mode")).
+		AddMethod("getRealPath", []ast.FunctionParameter{}, []string{"string", "false"}, splFileInfoGetRealPath).
+		AddMethod("getSize", []ast.FunctionParameter{}, []string{"int", "false"}, fileStatMethod("getSize", "size")).
+		AddMethod("getType", []ast.FunctionParameter{}, []string{"string", "false"}, splFileInfoGetType).
+		AddMethod("isDir", []ast.FunctionParameter{}, boolType, fileCheckMethod("isDir", func(info os.FileInfo) bool { return info.IsDir() })).
+		AddMethod("isExecutable", []ast.FunctionParameter{}, boolType, fileAccessMethod("isExecutable", filesystem.X_OK)).
+		AddMethod("isFile", []ast.FunctionParameter{}, boolType, fileCheckMethod("isFile", func(info os.FileInfo) bool { return info.Mode().IsRegular() })).
+		AddMethod("isLink", []ast.FunctionParameter{}, boolType, splFileInfoIsLink).
+		AddMethod("isReadable", []ast.FunctionParameter{}, boolType, fileAccessMethod("isReadable", filesystem.R_OK)).
+		AddMethod("isWritable", []ast.FunctionParameter{}, boolType, fileAccessMethod("isWritable", filesystem.W_OK)).
+		AddMethod("openFile", []ast.FunctionParameter{
+			runtime.NewOptionalParam("$mode", ast.NewStringLiteralExpr(0, nil, "r", ast.SingleQuotedString), "string"),
+			runtime.NewOptionalParam("$useIncludePath", ast.NewConstantAccessExpr(0, nil, "FALSE"), "bool"),
+			runtime.NewOptionalParam("$context", newNullLiteral(), "mixed"),
+		}, []string{"SplFileObject"}, splFileInfoOpenFile).
+		AddMethod("setFileClass", []ast.FunctionParameter{
+			runtime.NewOptionalParam("$class", ast.NewStringLiteralExpr(0, nil, "SplFileObject", ast.SingleQuotedString), "string"),
+		}, voidType, splFileInfoSetFileClass).
+		AddMethod("setInfoClass", []ast.FunctionParameter{
+			runtime.NewOptionalParam("$class", ast.NewStringLiteralExpr(0, nil, "SplFileInfo", ast.SingleQuotedString), "string"),
+		}, voidType, splFileInfoSetInfoClass).
+		Register()
+}
+
+func splFileInfoConstruct(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFileInfo::__construct").AddParam("$filename", stringType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	info := newFileInfo()
+	info.setFilename(args[0].(*values.Str).Value)
+	object.Internal = info
+	return values.NewVoid(), nil
+}
+
+// Create a method that returns a value of the file information (e.g. getSize).
+// A RuntimeException is thrown if the file does not exist.
+func fileStatMethod(methodName string, statName string) runtime.NativeMethod {
+	return func(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+		if _, err := funcParamValidator.NewValidator("SplFileInfo::" + methodName).Validate(args); err != nil {
+			return values.NewVoid(), err
+		}
+		info, err := getFileInfo(object)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+
+		pathname := info.getPathname()
+		fileInfo, goErr := filesystem.StatFile(pathname, false, context)
+		if goErr != nil {
+			return values.NewVoid(), newException("RuntimeException", "SplFileInfo::%s(): stat failed for %s", methodName, pathname)
+		}
+		return values.NewInt(filesystem.StatValue(fileInfo, statName)), nil
+	}
+}
+
+// Create a method that checks the file information (e.g. isDir). Returns false if the file does not exist.
+func fileCheckMethod(methodName string, check func(info os.FileInfo) bool) runtime.NativeMethod {
+	return func(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+		if _, err := funcParamValidator.NewValidator("SplFileInfo::" + methodName).Validate(args); err != nil {
+			return values.NewVoid(), err
+		}
+		info, err := getFileInfo(object)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+
+		fileInfo, goErr := filesystem.StatFile(info.getPathname(), false, context)
+		return values.NewBool(goErr == nil && check(fileInfo)), nil
+	}
+}
+
+// Create a method that checks the access to the file with R_OK, W_OK or X_OK (e.g. isReadable)
+func fileAccessMethod(methodName string, mode uint32) runtime.NativeMethod {
+	return func(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+		if _, err := funcParamValidator.NewValidator("SplFileInfo::" + methodName).Validate(args); err != nil {
+			return values.NewVoid(), err
+		}
+		info, err := getFileInfo(object)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+
+		pathname := info.getPathname()
+		return values.NewBool(pathname != "" && stream.IsAllowedPath(pathname, context) && filesystem.HasAccess(stream.ResolvePath(pathname, context), mode)), nil
+	}
+}
+
+func splFileInfoGetBasename(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFileInfo::getBasename").
+		AddParam("$suffix", stringType, values.NewStr("")).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileInfo(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(filesystem.Basename(info.getFilename(), args[0].(*values.Str).Value)), nil
+}
+
+func splFileInfoGetExtension(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileInfo::getExtension").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileInfo(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	basename := filesystem.Basename(info.getFilename(), "")
+	if pos := strings.LastIndexByte(basename, '.'); pos >= 0 {
+		return values.NewStr(basename[pos+1:]), nil
+	}
+	return values.NewStr(""), nil
+}
+
+func splFileInfoGetFileInfo(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFileInfo::getFileInfo").
+		AddParam("$class", []string{"string", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileInfo(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	className, err := classArg("getFileInfo", args[0], info.infoClass, "SplFileInfo", context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return newFileInfoObject(className, context, values.NewStr(info.getPathname()))
+}
+
+func splFileInfoGetFilename(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileInfo::getFilename").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileInfo(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(info.getFilename()), nil
+}
+
+func splFileInfoGetLinkTarget(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileInfo::getLinkTarget").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileInfo(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	pathname := info.getPathname()
+	if !stream.CheckOpenBasedir("SplFileInfo::getLinkTarget", pathname, context) {
+		return values.NewBool(false), nil
+	}
+	target, goErr := os.Readlink(stream.ResolvePath(pathname, context))
+	if goErr != nil {
+		return values.NewVoid(), newException("RuntimeException", "Unable to read link %s, error: %s", pathname, stream.ErrorMessage(goErr))
+	}
+	return values.NewStr(target), nil
+}
+
+func splFileInfoGetPath(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileInfo::getPath").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileInfo(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(info.getPath()), nil
+}
+
+func splFileInfoGetPathInfo(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFileInfo::getPathInfo").
+		AddParam("$class", []string{"string", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileInfo(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	className, err := classArg("getPathInfo", args[0], info.infoClass, "SplFileInfo", context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	pathname := info.getPathname()
+	if pathname == "" {
+		return values.NewNull(), nil
+	}
+	return newFileInfoObject(className, context, values.NewStr(filesystem.Dirname(pathname)))
+}
+
+func splFileInfoGetPathname(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileInfo::getPathname").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileInfo(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(info.getPathname()), nil
+}
+
+func splFileInfoGetRealPath(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileInfo::getRealPath").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileInfo(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	pathname := info.getPathname()
+	if pathname == "" && info.directory != nil {
+		pathname = info.directory.path
+	}
+	path, ok := filesystem.Realpath(pathname, context)
+	if !ok || !stream.CheckOpenBasedir("SplFileInfo::getRealPath", path, context) {
+		return values.NewBool(false), nil
+	}
+	return values.NewStr(path), nil
+}
+
+func splFileInfoGetType(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileInfo::getType").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileInfo(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	pathname := info.getPathname()
+	fileInfo, goErr := filesystem.StatFile(pathname, true, context)
+	if goErr != nil {
+		return values.NewVoid(), newException("RuntimeException", "SplFileInfo::getType(): Lstat failed for %s", pathname)
+	}
+	return values.NewStr(filesystem.FileType(fileInfo)), nil
+}
+
+func splFileInfoIsLink(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileInfo::isLink").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileInfo(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileInfo, goErr := filesystem.StatFile(info.getPathname(), true, context)
+	return values.NewBool(goErr == nil && fileInfo.Mode()&os.ModeSymlink != 0), nil
+}
+
+func splFileInfoOpenFile(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFileInfo::openFile").
+		AddParam("$mode", stringType, values.NewStr("r")).
+		AddParam("$useIncludePath", boolType, values.NewBool(false)).
+		AddParam("$context", mixedType, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileInfo(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return newFileInfoObject(info.fileClass, context, values.NewStr(info.getPathname()), args[0], args[1], args[2])
+}
+
+func splFileInfoSetFileClass(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFileInfo::setFileClass").
+		AddParam("$class", stringType, values.NewStr("SplFileObject")).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileInfo(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	className, err := classArg("setFileClass", args[0], "SplFileObject", "SplFileObject", context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info.fileClass = className
+	return values.NewVoid(), nil
+}
+
+func splFileInfoSetInfoClass(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFileInfo::setInfoClass").
+		AddParam("$class", stringType, values.NewStr("SplFileInfo")).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileInfo(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	className, err := classArg("setInfoClass", args[0], "SplFileInfo", "SplFileInfo", context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info.infoClass = className
+	return values.NewVoid(), nil
+}
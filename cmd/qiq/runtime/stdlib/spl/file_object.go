@@ -0,0 +1,831 @@
+package spl
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/filesystem"
+	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/stream"
+	"QIQ/cmd/qiq/runtime/values"
+	"fmt"
+)
+
+// Spec: https://www.php.net/manual/en/class.splfileobject.php#splfileobject.constants.drop-new-line
+const (
+	dropNewLine int64 = 1
+	readAhead   int64 = 2
+	skipEmpty   int64 = 4
+	readCsv     int64 = 8
+)
+
+// Internal state of SplFileObject
+type fileObject struct {
+	stream *stream.Stream
+	flags  int64
+	// Maximum length of a line (0 = unlimited)
+	maxLineLen int64
+	// Line that was read last. hasLine is false if no line was read yet or the line was freed.
+	currentLine string
+	hasLine     bool
+	// Array of the line that was read last with READ_CSV
+	currentValue values.RuntimeValue
+	lineNum      int64
+	separator    byte
+	enclosure    byte
+	escape       int
+}
+
+func (file *fileObject) freeLine() {
+	file.currentLine = ""
+	file.hasLine = false
+	values.Store(&file.currentValue, nil)
+}
+
+// Read the next line. Returns false at the end of the file. Port of spl_filesystem_file_read_ex.
+func (file *fileObject) readEx(fileName string, silent bool, lineAdd int64, csv bool) (bool, phpError.Error) {
+	file.freeLine()
+	if file.stream.Eof() {
+		if !silent {
+			return false, newException("RuntimeException", "Cannot read from file %s", fileName)
+		}
+		return false, nil
+	}
+
+	maxLength := -1
+	if file.maxLineLen > 0 {
+		maxLength = int(file.maxLineLen)
+	}
+	line, _, _ := file.stream.ReadLine(maxLength)
+	if !csv && file.flags&dropNewLine != 0 {
+		line = filesystem.TrimLineEnding(line)
+	}
+	file.currentLine = line
+	file.hasLine = true
+	file.lineNum += lineAdd
+	return true, nil
+}
+
+// Read the next line. The line number is increased if a line was read before.
+func (file *fileObject) read(fileName string, silent bool, csv bool) (bool, phpError.Error) {
+	var lineAdd int64 = 0
+	if file.hasLine || file.currentValue != nil {
+		lineAdd = 1
+	}
+	return file.readEx(fileName, silent, lineAdd, csv)
+}
+
+// Read the next line and parse it as CSV
+func (file *fileObject) readCsv(fileName string, silent bool, separator byte, enclosure byte, escape int) (bool, phpError.Error) {
+	for {
+		if ok, err := file.read(fileName, silent, true); !ok {
+			return false, err
+		}
+		if file.currentLine != "" || file.flags&skipEmpty == 0 {
+			break
+		}
+	}
+
+	nextLine := func() (string, bool) {
+		line, ok, _ := file.stream.ReadLine(-1)
+		return line, ok
+	}
+	values.Store(&file.currentValue, filesystem.ParseCsv(file.currentLine, separator, enclosure, escape, nextLine))
+	return true, nil
+}
+
+func (file *fileObject) isLineEmpty() bool {
+	if file.currentValue != nil {
+		array := file.currentValue.(*values.Array)
+		if len(array.Keys) != 1 {
+			return len(array.Keys) == 0
+		}
+		slot, _ := array.GetElement(array.Keys[0])
+		return slot.Value.GetType() == values.NullValue || (slot.Value.GetType() == values.StrValue && slot.Value.(*values.Str).Value == "")
+	}
+	return file.currentLine == "" ||
+		(file.flags&readAhead != 0 && file.flags&dropNewLine != 0 && (file.currentLine == "\n" || file.currentLine == "\r\n"))
+}
+
+// Read the next line with the flags READ_CSV and SKIP_EMPTY. Port of spl_filesystem_file_read_line.
+func (file *fileObject) readLine(fileName string, silent bool) (bool, phpError.Error) {
+	readLineEx := func() (bool, phpError.Error) {
+		if file.flags&readCsv != 0 {
+			return file.readCsv(fileName, silent, file.separator, file.enclosure, file.escape)
+		}
+		return file.read(fileName, silent, false)
+	}
+
+	ok, err := readLineEx()
+	for file.flags&skipEmpty != 0 && ok && file.isLineEmpty() {
+		file.freeLine()
+		ok, err = readLineEx()
+	}
+	return ok, err
+}
+
+func (file *fileObject) rewind(fileName string) phpError.Error {
+	if !file.stream.SetPosition(0, stream.SEEK_SET) {
+		return newException("RuntimeException", "Cannot rewind file %s", fileName)
+	}
+	file.freeLine()
+	file.lineNum = 0
+	if file.flags&readAhead != 0 {
+		file.readLine(fileName, true)
+	}
+	return nil
+}
+
+func getFileObject(object *values.Object) (*fileInfo, phpError.Error) {
+	info, err := getFileInfo(object)
+	if err != nil {
+		return nil, err
+	}
+	if info.file == nil {
+		return nil, phpError.NewError("Uncaught Error: Object not initialized")
+	}
+	return info, nil
+}
+
+// Open the file of a SplFileObject. Port of spl_filesystem_file_open.
+func openFileObject(object *values.Object, className string, fileName string, mode string, context runtime.Context) phpError.Error {
+	if fileName == "" {
+		return phpError.NewError("Uncaught ValueError: Path cannot be empty")
+	}
+	if info, err := filesystem.StatFile(fileName, false, context); err == nil && info.IsDir() {
+		return newException("LogicException", "Cannot use SplFileObject with directories")
+	}
+
+	fileStream, goErr := stream.Open(fileName, mode, context)
+	if goErr != nil {
+		return newException("RuntimeException", "%s::__construct(%s): Failed to open stream: %s", className, fileName, goErr)
+	}
+
+	info := newFileInfo()
+	info.setFilename(fileName)
+	info.file = &fileObject{stream: fileStream, separator: ',', enclosure: '"', escape: '\\'}
+	object.Internal = info
+	return nil
+}
+
+// -------------------------------------- SplFileObject -------------------------------------- MARK: SplFileObject
+
+func registerSplFileObject(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.splfileobject.php
+	runtime.NewNativeClass(interpreter, "SplFileObject").
+		Extends("SplFileInfo").
+		Implements("RecursiveIterator", "SeekableIterator").
+		AddConst("DROP_NEW_LINE", newIntLiteral(dropNewLine)).
+		AddConst("READ_AHEAD", newIntLiteral(readAhead)).
+		AddConst("SKIP_EMPTY", newIntLiteral(skipEmpty)).
+		AddConst("READ_CSV", newIntLiteral(readCsv)).
+		AddMethod("__construct", []ast.FunctionParameter{
+			runtime.NewParam("$filename", "string"),
+			runtime.NewOptionalParam("$mode", ast.NewStringLiteralExpr(0, nil, "r", ast.SingleQuotedString), "string"),
+			runtime.NewOptionalParam("$useIncludePath", ast.NewConstantAccessExpr(0, nil, "FALSE"), "bool"),
+			runtime.NewOptionalParam("$context", newNullLiteral(), "mixed"),
+		}, nil, splFileObjectConstruct).
+		AddMethod("__toString", []ast.FunctionParameter{}, stringType, splFileObjectToString).
+		AddMethod("current", []ast.FunctionParameter{}, []string{"string", "array", "false"}, splFileObjectCurrent).
+		AddMethod("eof", []ast.FunctionParameter{}, boolType, splFileObjectEof).
+		AddMethod("fflush", []ast.FunctionParameter{}, boolType, splFileObjectFflush).
+		AddMethod("fgetc", []ast.FunctionParameter{}, []string{"string", "false"}, splFileObjectFgetc).
+		AddMethod("fgetcsv", csvControlParams(), []string{"array", "false"}, splFileObjectFgetcsv).
+		AddMethod("fgets", []ast.FunctionParameter{}, stringType, splFileObjectFgets).
+		AddMethod("flock", []ast.FunctionParameter{
+			runtime.NewParam("$operation", "int"),
+			runtime.NewOptionalParam("$wouldBlock", newNullLiteral(), "mixed"),
+		}, boolType, splFileObjectFlock).
+		AddMethod("fpassthru", []ast.FunctionParameter{}, intType, splFileObjectFpassthru).
+		AddMethod("fputcsv", append([]ast.FunctionParameter{runtime.NewParam("$fields", "array")},
+			append(csvControlParams(), runtime.NewOptionalParam("$eol", ast.NewStringLiteralExpr(0, nil, "\n", ast.DoubleQuotedString), "string"))...,
+		), []string{"int", "false"}, splFileObjectFputcsv).
+		AddMethod("fread", []ast.FunctionParameter{runtime.NewParam("$length", "int")}, []string{"string", "false"}, splFileObjectFread).
+		AddMethod("fseek", []ast.FunctionParameter{
+			runtime.NewParam("$offset", "int"),
+			runtime.NewOptionalParam("$whence", newIntLiteral(int64(stream.SEEK_SET)), "int"),
+		}, intType, splFileObjectFseek).
+		AddMethod("fstat", []ast.FunctionParameter{}, arrayType, splFileObjectFstat).
+		AddMethod("ftell", []ast.FunctionParameter{}, []string{"int", "false"}, splFileObjectFtell).
+		AddMethod("ftruncate", []ast.FunctionParameter{runtime.NewParam("$size", "int")}, boolType, splFileObjectFtruncate).
+		AddMethod("fwrite", []ast.FunctionParameter{
+			runtime.NewParam("$data", "string"),
+			runtime.NewOptionalParam("$length", newIntLiteral(0), "int"),
+		}, []string{"int", "false"}, splFileObjectFwrite).
+		AddMethod("getChildren", []ast.FunctionParameter{}, []string{"null"}, splFileObjectGetChildren).
+		AddMethod("getCsvControl", []ast.FunctionParameter{}, arrayType, splFileObjectGetCsvControl).
+		AddMethod("getCurrentLine", []ast.FunctionParameter{}, stringType, splFileObjectFgets).
+		AddMethod("getFlags", []ast.FunctionParameter{}, intType, splFileObjectGetFlags).
+		AddMethod("getMaxLineLen", []ast.FunctionParameter{}, intType, splFileObjectGetMaxLineLen).
+		AddMethod("hasChildren", []ast.FunctionParameter{}, []string{"false"}, splFileObjectHasChildren).
+		AddMethod("key", []ast.FunctionParameter{}, intType, splFileObjectKey).
+		AddMethod("next", []ast.FunctionParameter{}, voidType, splFileObjectNext).
+		AddMethod("rewind", []ast.FunctionParameter{}, voidType, splFileObjectRewind).
+		AddMethod("seek", []ast.FunctionParameter{runtime.NewParam("$line", "int")}, voidType, splFileObjectSeek).
+		AddMethod("setCsvControl", csvControlParams(), voidType, splFileObjectSetCsvControl).
+		AddMethod("setFlags", []ast.FunctionParameter{runtime.NewParam("$flags", "int")}, voidType, splFileObjectSetFlags).
+		AddMethod("setMaxLineLen", []ast.FunctionParameter{runtime.NewParam("$maxLength", "int")}, voidType, splFileObjectSetMaxLineLen).
+		AddMethod("valid", []ast.FunctionParameter{}, boolType, splFileObjectValid).
+		Register()
+
+	// Spec: https://www.php.net/manual/en/class.spltempfileobject.php
+	runtime.NewNativeClass(interpreter, "SplTempFileObject").
+		Extends("SplFileObject").
+		AddMethod("__construct", []ast.FunctionParameter{
+			runtime.NewOptionalParam("$maxMemory", newIntLiteral(2*1024*1024), "int"),
+		}, nil, splTempFileObjectConstruct).
+		Register()
+}
+
+func csvControlParams() []ast.FunctionParameter {
+	return []ast.FunctionParameter{
+		runtime.NewOptionalParam("$separator", ast.NewStringLiteralExpr(0, nil, ",", ast.SingleQuotedString), "string"),
+		runtime.NewOptionalParam("$enclosure", ast.NewStringLiteralExpr(0, nil, `"`, ast.SingleQuotedString), "string"),
+		runtime.NewOptionalParam("$escape", ast.NewStringLiteralExpr(0, nil, `\`, ast.SingleQuotedString), "string"),
+	}
+}
+
+// Get the separator, enclosure and escape arguments of fgetcsv, fputcsv and setCsvControl.
+// Arguments that were not passed (argCount) are taken from the CSV control of the file.
+func csvControlArgs(methodName string, args []values.RuntimeValue, firstArgNum int, argCount int, file *fileObject) (byte, byte, int, phpError.Error) {
+	functionName := "SplFileObject::" + methodName
+	separator, enclosure, escape := int(file.separator), int(file.enclosure), file.escape
+	var err phpError.Error
+	if argCount > 0 {
+		if separator, err = filesystem.CsvCharArg(functionName, firstArgNum, "$separator", args[0].(*values.Str).Value, false); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	if argCount > 1 {
+		if enclosure, err = filesystem.CsvCharArg(functionName, firstArgNum+1, "$enclosure", args[1].(*values.Str).Value, false); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	if argCount > 2 {
+		if escape, err = filesystem.CsvCharArg(functionName, firstArgNum+2, "$escape", args[2].(*values.Str).Value, true); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	return byte(separator), byte(enclosure), escape, nil
+}
+
+func addCsvControlParams(validator *funcParamValidator.Validator) *funcParamValidator.Validator {
+	return validator.
+		AddParam("$separator", stringType, values.NewStr(",")).
+		AddParam("$enclosure", stringType, values.NewStr(`"`)).
+		AddParam("$escape", stringType, values.NewStr(`\`))
+}
+
+func splFileObjectConstruct(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFileObject::__construct").
+		AddParam("$filename", stringType, nil).
+		AddParam("$mode", stringType, values.NewStr("r")).
+		AddParam("$useIncludePath", boolType, values.NewBool(false)).
+		AddParam("$context", mixedType, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// TODO SplFileObject::__construct: Add support for useIncludePath and context
+
+	return values.NewVoid(), openFileObject(object, "SplFileObject", args[0].(*values.Str).Value, args[1].(*values.Str).Value, context)
+}
+
+func splFileObjectToString(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::__toString").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if !info.file.hasLine {
+		if _, err := info.file.read(info.fileName, false, false); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return values.NewStr(info.file.currentLine), nil
+}
+
+func splFileObjectCurrent(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::current").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if !info.file.hasLine && info.file.currentValue == nil {
+		info.file.readLine(info.fileName, true)
+	}
+	if info.file.hasLine && (info.file.flags&readCsv == 0 || info.file.currentValue == nil) {
+		return values.NewStr(info.file.currentLine), nil
+	}
+	if info.file.currentValue != nil {
+		return info.file.currentValue, nil
+	}
+	return values.NewBool(false), nil
+}
+
+func splFileObjectEof(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::eof").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(info.file.stream.Eof()), nil
+}
+
+func splFileObjectFflush(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::fflush").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(info.file.stream.Flush()), nil
+}
+
+func splFileObjectFgetc(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::fgetc").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	info.file.freeLine()
+	char, goErr := info.file.stream.Read(1)
+	if goErr != nil {
+		filesystem.PrintIoNotice("SplFileObject::fgetc", "Read", 1, goErr, context)
+		return values.NewBool(false), nil
+	}
+	if char == "" {
+		return values.NewBool(false), nil
+	}
+	if char == "\n" {
+		info.file.lineNum++
+	}
+	return values.NewStr(char), nil
+}
+
+func splFileObjectFgetcsv(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	argCount := len(args)
+	args, err := addCsvControlParams(funcParamValidator.NewValidator("SplFileObject::fgetcsv")).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	separator, enclosure, escape, err := csvControlArgs("fgetcsv", args, 1, argCount, info.file)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if ok, _ := info.file.readCsv(info.fileName, true, separator, enclosure, escape); !ok {
+		return values.NewBool(false), nil
+	}
+	return info.file.currentValue, nil
+}
+
+func splFileObjectFgets(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::fgets").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if _, err := info.file.readEx(info.fileName, false, 1, false); err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewStr(info.file.currentLine), nil
+}
+
+func splFileObjectFlock(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFileObject::flock").
+		AddParam("$operation", intType, nil).
+		AddParam("$wouldBlock", mixedType, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// TODO SplFileObject::flock: Set wouldBlock as soon as native methods support reference parameters
+	operation := int(args[0].(*values.Int).Value)
+	switch operation &^ stream.LOCK_NB {
+	case stream.LOCK_SH, stream.LOCK_EX, stream.LOCK_UN:
+	default:
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: SplFileObject::flock(): Argument #1 ($operation) must be one of LOCK_SH, LOCK_EX, or LOCK_UN")
+	}
+	ok, _ := info.file.stream.Lock(operation)
+	return values.NewBool(ok), nil
+}
+
+func splFileObjectFpassthru(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::fpassthru").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	data, goErr := info.file.stream.ReadAll()
+	if goErr != nil {
+		filesystem.PrintIoNotice("SplFileObject::fpassthru", "Read", 8192, goErr, context)
+		return values.NewInt(0), nil
+	}
+	context.Interpreter.Print(data)
+	return values.NewInt(int64(len(data))), nil
+}
+
+func splFileObjectFputcsv(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	argCount := len(args)
+	args, err := addCsvControlParams(funcParamValidator.NewValidator("SplFileObject::fputcsv").AddParam("$fields", arrayType, nil)).
+		AddParam("$eol", stringType, values.NewStr("\n")).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	separator, enclosure, escape, err := csvControlArgs("fputcsv", args[1:4], 2, argCount-1, info.file)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	array := args[0].(*values.Array)
+	fields := make([]string, len(array.Keys))
+	for index, key := range array.Keys {
+		slot, _ := array.GetElement(key)
+		fields[index], err = variableHandling.StrVal(slot.Value)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+	}
+
+	line := filesystem.FormatCsv(fields, separator, enclosure, escape, args[4].(*values.Str).Value)
+	written, goErr := info.file.stream.Write(line)
+	if goErr != nil {
+		filesystem.PrintIoNotice("SplFileObject::fputcsv", "Write", len(line), goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewInt(int64(written)), nil
+}
+
+func splFileObjectFread(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFileObject::fread").AddParam("$length", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	length := args[0].(*values.Int).Value
+	if length <= 0 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: SplFileObject::fread(): Argument #1 ($length) must be greater than 0")
+	}
+	data, goErr := info.file.stream.Read(int(length))
+	if goErr != nil {
+		filesystem.PrintIoNotice("SplFileObject::fread", "Read", int(length), goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewStr(data), nil
+}
+
+func splFileObjectFseek(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFileObject::fseek").
+		AddParam("$offset", intType, nil).
+		AddParam("$whence", intType, values.NewInt(int64(stream.SEEK_SET))).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	info.file.freeLine()
+	if !info.file.stream.SetPosition(args[0].(*values.Int).Value, int(args[1].(*values.Int).Value)) {
+		return values.NewInt(-1), nil
+	}
+	return values.NewInt(0), nil
+}
+
+func splFileObjectFstat(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::fstat").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileInfo, ok := info.file.stream.Stat()
+	if !ok {
+		return values.NewArray(), nil
+	}
+	return filesystem.StatToArray(fileInfo), nil
+}
+
+func splFileObjectFtell(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::ftell").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(info.file.stream.GetPosition()), nil
+}
+
+func splFileObjectFtruncate(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFileObject::ftruncate").AddParam("$size", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if !info.file.stream.IsTruncatable() {
+		return values.NewVoid(), newException("LogicException", "Can't truncate file %s", info.fileName)
+	}
+	return values.NewBool(info.file.stream.Truncate(args[0].(*values.Int).Value)), nil
+}
+
+func splFileObjectFwrite(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	hasLength := len(args) > 1
+	args, err := funcParamValidator.NewValidator("SplFileObject::fwrite").
+		AddParam("$data", stringType, nil).
+		AddParam("$length", intType, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	data := args[0].(*values.Str).Value
+	if hasLength {
+		data = data[:max(0, min(int64(len(data)), args[1].(*values.Int).Value))]
+	}
+	if data == "" {
+		return values.NewInt(0), nil
+	}
+
+	written, goErr := info.file.stream.Write(data)
+	if goErr != nil {
+		filesystem.PrintIoNotice("SplFileObject::fwrite", "Write", len(data), goErr, context)
+		return values.NewBool(false), nil
+	}
+	return values.NewInt(int64(written)), nil
+}
+
+func splFileObjectGetChildren(_ *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::getChildren").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewNull(), nil
+}
+
+func splFileObjectGetCsvControl(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::getCsvControl").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	escape := ""
+	if info.file.escape != filesystem.NoEscape {
+		escape = string(rune(info.file.escape))
+	}
+	result := values.NewArray()
+	result.SetElement(nil, values.NewStr(string(info.file.separator)))
+	result.SetElement(nil, values.NewStr(string(info.file.enclosure)))
+	result.SetElement(nil, values.NewStr(escape))
+	return result, nil
+}
+
+func splFileObjectGetFlags(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::getFlags").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(info.file.flags), nil
+}
+
+func splFileObjectGetMaxLineLen(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::getMaxLineLen").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(info.file.maxLineLen), nil
+}
+
+func splFileObjectHasChildren(_ *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::hasChildren").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(false), nil
+}
+
+func splFileObjectKey(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::key").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// The next line is not read so that fgetc counts the lines correctly
+	return values.NewInt(info.file.lineNum), nil
+}
+
+func splFileObjectNext(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::next").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	info.file.freeLine()
+	if info.file.flags&readAhead != 0 {
+		info.file.readLine(info.fileName, true)
+	}
+	info.file.lineNum++
+	return values.NewVoid(), nil
+}
+
+func splFileObjectRewind(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::rewind").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewVoid(), info.file.rewind(info.fileName)
+}
+
+func splFileObjectSeek(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFileObject::seek").AddParam("$line", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	line := args[0].(*values.Int).Value
+	if line < 0 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: SplFileObject::seek(): Argument #1 ($line) must be greater than or equal to 0")
+	}
+	if err := info.file.rewind(info.fileName); err != nil {
+		return values.NewVoid(), err
+	}
+	for i := int64(0); i < line; i++ {
+		if ok, _ := info.file.readLine(info.fileName, true); !ok {
+			return values.NewVoid(), nil
+		}
+	}
+	if line > 0 && info.file.flags&readAhead == 0 {
+		info.file.lineNum++
+		info.file.freeLine()
+	}
+	return values.NewVoid(), nil
+}
+
+func splFileObjectSetCsvControl(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := addCsvControlParams(funcParamValidator.NewValidator("SplFileObject::setCsvControl")).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	separator, enclosure, escape, err := csvControlArgs("setCsvControl", args, 1, 3, info.file)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	info.file.separator, info.file.enclosure, info.file.escape = separator, enclosure, escape
+	return values.NewVoid(), nil
+}
+
+func splFileObjectSetFlags(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFileObject::setFlags").AddParam("$flags", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	info.file.flags = args[0].(*values.Int).Value
+	return values.NewVoid(), nil
+}
+
+func splFileObjectSetMaxLineLen(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFileObject::setMaxLineLen").AddParam("$maxLength", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	maxLength := args[0].(*values.Int).Value
+	if maxLength < 0 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: SplFileObject::setMaxLineLen(): Argument #1 ($maxLength) must be greater than or equal to 0")
+	}
+	info.file.maxLineLen = maxLength
+	return values.NewVoid(), nil
+}
+
+func splFileObjectValid(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFileObject::valid").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+	info, err := getFileObject(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if info.file.flags&readAhead != 0 {
+		return values.NewBool(info.file.hasLine || info.file.currentValue != nil), nil
+	}
+	return values.NewBool(!info.file.stream.Eof()), nil
+}
+
+// -------------------------------------- SplTempFileObject -------------------------------------- MARK: SplTempFileObject
+
+func splTempFileObjectConstruct(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	hasMaxMemory := len(args) > 0
+	args, err := funcParamValidator.NewValidator("SplTempFileObject::__construct").
+		AddParam("$maxMemory", intType, values.NewInt(2*1024*1024)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	maxMemory := args[0].(*values.Int).Value
+	fileName := "php://temp"
+	if maxMemory < 0 {
+		fileName = "php://memory"
+	} else if hasMaxMemory {
+		fileName = fmt.Sprintf("php://temp/maxmemory:%d", maxMemory)
+	}
+	if err := openFileObject(object, "SplTempFileObject", fileName, "wb", context); err != nil {
+		return values.NewVoid(), err
+	}
+	object.Internal.(*fileInfo).path = ""
+	return values.NewVoid(), nil
+}
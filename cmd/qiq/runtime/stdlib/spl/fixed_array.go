@@ -0,0 +1,233 @@
+package spl
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+)
+
+// Internal state of SplFixedArray
+type fixedArray struct {
+	elements []values.RuntimeValue
+}
+
+func (fixedArray *fixedArray) GetReferences() []values.RuntimeValue { return fixedArray.elements }
+
+func getFixedArray(object *values.Object) *fixedArray {
+	if object.Internal == nil {
+		object.Internal = &fixedArray{elements: []values.RuntimeValue{}}
+	}
+	return object.Internal.(*fixedArray)
+}
+
+func (array *fixedArray) setSize(size int) {
+	if size < len(array.elements) {
+		for _, value := range array.elements[size:] {
+			values.Release(value)
+		}
+		array.elements = array.elements[:size]
+		return
+	}
+	for len(array.elements) < size {
+		array.elements = append(array.elements, values.NewNull())
+	}
+}
+
+func (array *fixedArray) toIndex(offset values.RuntimeValue) (int, phpError.Error) {
+	index, err := toIndex(offset, "SplFixedArray")
+	if err != nil {
+		return 0, err
+	}
+	if index < 0 || index >= len(array.elements) {
+		return 0, newException("RuntimeException", "Index invalid or out of range")
+	}
+	return index, nil
+}
+
+// -------------------------------------- SplFixedArray -------------------------------------- MARK: SplFixedArray
+
+func registerSplFixedArray(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.splfixedarray.php
+	runtime.NewNativeClass(interpreter, "SplFixedArray").
+		Implements("IteratorAggregate", "ArrayAccess", "Countable").
+		AddMethod("__construct", []ast.FunctionParameter{runtime.NewOptionalParam("$size", newIntLiteral(0), "int")}, nil, splFixedArrayConstruct).
+		AddMethod("count", []ast.FunctionParameter{}, intType, splFixedArrayGetSize).
+		AddStaticMethod("fromArray", []ast.FunctionParameter{
+			runtime.NewParam("$array", "array"),
+			runtime.NewOptionalParam("$preserveKeys", ast.NewConstantAccessExpr(0, nil, "TRUE"), "bool"),
+		}, []string{"SplFixedArray"}, splFixedArrayFromArray).
+		AddMethod("getIterator", []ast.FunctionParameter{}, []string{"Iterator"}, splFixedArrayGetIterator).
+		AddMethod("getSize", []ast.FunctionParameter{}, intType, splFixedArrayGetSize).
+		AddMethod("offsetExists", []ast.FunctionParameter{runtime.NewParam("$offset", "mixed")}, boolType, splFixedArrayOffsetExists).
+		AddMethod("offsetGet", []ast.FunctionParameter{runtime.NewParam("$offset", "mixed")}, mixedType, splFixedArrayOffsetGet).
+		AddMethod("offsetSet", []ast.FunctionParameter{runtime.NewParam("$offset", "mixed"), runtime.NewParam("$value", "mixed")}, voidType, splFixedArrayOffsetSet).
+		AddMethod("offsetUnset", []ast.FunctionParameter{runtime.NewParam("$offset", "mixed")}, voidType, splFixedArrayOffsetUnset).
+		AddMethod("setSize", []ast.FunctionParameter{runtime.NewParam("$size", "int")}, []string{"bool"}, splFixedArraySetSize).
+		AddMethod("toArray", []ast.FunctionParameter{}, arrayType, splFixedArrayToArray).
+		Register()
+}
+
+func splFixedArrayConstruct(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFixedArray::__construct").AddParam("$size", intType, values.NewInt(0)).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	size := args[0].(*values.Int).Value
+	if size < 0 {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: SplFixedArray::__construct(): Argument #1 ($size) must be greater than or equal to 0",
+		)
+	}
+	getFixedArray(object).setSize(int(size))
+	return values.NewVoid(), nil
+}
+
+func splFixedArrayFromArray(_ *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFixedArray::fromArray").
+		AddParam("$array", arrayType, nil).AddParam("$preserveKeys", boolType, values.NewBool(true)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	object, err := newObject(context.Interpreter, "SplFixedArray")
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	fixedArray := getFixedArray(object)
+
+	array := args[0].(*values.Array)
+	if !args[1].(*values.Bool).Value {
+		for _, key := range array.Keys {
+			slot, _ := array.GetElement(key)
+			fixedArray.elements = append(fixedArray.elements, slot.Value)
+			values.Retain(slot.Value)
+		}
+		return object, nil
+	}
+
+	size := 0
+	for _, key := range array.Keys {
+		if key.GetType() != values.IntValue || key.(*values.Int).Value < 0 {
+			return values.NewVoid(), phpError.NewError("Uncaught ValueError: array must contain only positive integer keys")
+		}
+		size = max(size, int(key.(*values.Int).Value)+1)
+	}
+	fixedArray.setSize(size)
+	for _, key := range array.Keys {
+		slot, _ := array.GetElement(key)
+		values.Store(&fixedArray.elements[key.(*values.Int).Value], slot.Value)
+	}
+	return object, nil
+}
+
+func splFixedArrayGetIterator(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFixedArray::getIterator").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	fixedArray := getFixedArray(object)
+	return newInternalIterator(context.Interpreter, &internalIterator{
+		count:   func() int { return len(fixedArray.elements) },
+		key:     func(index int) values.RuntimeValue { return values.NewInt(int64(index)) },
+		current: func(index int) values.RuntimeValue { return fixedArray.elements[index] },
+	})
+}
+
+func splFixedArrayGetSize(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFixedArray::getSize").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(int64(len(getFixedArray(object).elements))), nil
+}
+
+func splFixedArrayOffsetExists(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFixedArray::offsetExists").AddParam("$index", mixedType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fixedArray := getFixedArray(object)
+	index, err := fixedArray.toIndex(args[0])
+	if err != nil {
+		return values.NewBool(false), nil
+	}
+	return values.NewBool(fixedArray.elements[index].GetType() != values.NullValue), nil
+}
+
+func splFixedArrayOffsetGet(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFixedArray::offsetGet").AddParam("$index", mixedType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fixedArray := getFixedArray(object)
+	index, err := fixedArray.toIndex(args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return fixedArray.elements[index], nil
+}
+
+func splFixedArrayOffsetSet(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFixedArray::offsetSet").
+		AddParam("$index", mixedType, nil).AddParam("$value", mixedType, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if args[0].GetType() == values.NullValue {
+		return values.NewVoid(), newException("RuntimeException", "[] operator not supported for SplFixedArray")
+	}
+	fixedArray := getFixedArray(object)
+	index, err := fixedArray.toIndex(args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	values.Store(&fixedArray.elements[index], args[1])
+	return values.NewVoid(), nil
+}
+
+func splFixedArrayOffsetUnset(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFixedArray::offsetUnset").AddParam("$index", mixedType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fixedArray := getFixedArray(object)
+	index, err := fixedArray.toIndex(args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	values.Store(&fixedArray.elements[index], values.NewNull())
+	return values.NewVoid(), nil
+}
+
+func splFixedArraySetSize(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplFixedArray::setSize").AddParam("$size", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	size := args[0].(*values.Int).Value
+	if size < 0 {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: SplFixedArray::setSize(): Argument #1 ($size) must be greater than or equal to 0",
+		)
+	}
+	getFixedArray(object).setSize(int(size))
+	return values.NewBool(true), nil
+}
+
+func splFixedArrayToArray(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplFixedArray::toArray").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewArrayFromSlice(getFixedArray(object).elements), nil
+}
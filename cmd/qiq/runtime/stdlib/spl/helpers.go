@@ -0,0 +1,88 @@
+package spl
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/common"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/values"
+	"strings"
+)
+
+var (
+	mixedType = []string{"mixed"}
+	boolType  = []string{"bool"}
+	intType   = []string{"int"}
+	voidType  = []string{"void"}
+	arrayType = []string{"array"}
+)
+
+func newException(className string, format string, a ...any) phpError.Error {
+	return phpError.NewError("Uncaught "+className+": "+format, a...)
+}
+
+// Create a new object of the given (native) class without calling the constructor
+func newObject(interpreter runtime.Interpreter, className string) (*values.Object, phpError.Error) {
+	classDecl, found := interpreter.GetClass(className)
+	if !found {
+		return nil, phpError.NewError(`Class "%s" not found`, className)
+	}
+	return values.NewObject(classDecl), nil
+}
+
+// Find the method in the given class or one of its base classes
+func findMethod(interpreter runtime.Interpreter, class *ast.ClassDeclarationStatement, name string) (*ast.MethodDefinitionStatement, bool) {
+	for {
+		if method, found := class.GetMethod(name); found {
+			return method, true
+		}
+		if class.BaseClass == "" {
+			return nil, false
+		}
+		baseClass, found := interpreter.GetClass(class.BaseClass)
+		if !found {
+			return nil, false
+		}
+		class = baseClass
+	}
+}
+
+func newIntLiteral(value int64) ast.IExpression { return ast.NewIntegerLiteralExpr(0, nil, value) }
+
+func newNullLiteral() ast.IExpression { return ast.NewConstantAccessExpr(0, nil, "NULL") }
+
+// Convert an offset into an integer index as done for SplFixedArray and SplDoublyLinkedList
+func toIndex(offset values.RuntimeValue, className string) (int, phpError.Error) {
+	switch offset.GetType() {
+	case values.IntValue:
+		return int(offset.(*values.Int).Value), nil
+	case values.FloatValue:
+		return int(offset.(*values.Float).Value), nil
+	case values.BoolValue:
+		if offset.(*values.Bool).Value {
+			return 1, nil
+		}
+		return 0, nil
+	case values.StrValue:
+		if common.IsDecimalLiteral(offset.(*values.Str).Value, false) {
+			return int(common.DecimalLiteralToInt64(offset.(*values.Str).Value, false)), nil
+		}
+	}
+	return 0, phpError.NewError(
+		"Uncaught TypeError: Cannot access offset of type %s on %s", strings.ToLower(values.ToPhpType(offset)), className,
+	)
+}
+
+// Get the key as it is stored in the array
+func findKey(array *values.Array, key values.RuntimeValue) (values.RuntimeValue, bool) {
+	mapKey, found, err := array.GetMapKey(key, true)
+	if err != nil || !found {
+		return nil, false
+	}
+	for _, arrayKey := range array.Keys {
+		if arrayMapKey, _, _ := array.GetMapKey(arrayKey, false); arrayMapKey == mapKey {
+			return arrayKey, true
+		}
+	}
+	return nil, false
+}
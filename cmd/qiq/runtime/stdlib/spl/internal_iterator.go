@@ -0,0 +1,124 @@
+package spl
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+)
+
+// Internal state of InternalIterator.
+// The callbacks give access to the elements of the natively implemented class that is iterated.
+type internalIterator struct {
+	count    func() int
+	key      func(index int) values.RuntimeValue
+	current  func(index int) values.RuntimeValue
+	position int
+}
+
+func newInternalIterator(interpreter runtime.Interpreter, iterator *internalIterator) (*values.Object, phpError.Error) {
+	object, err := newObject(interpreter, "InternalIterator")
+	if err != nil {
+		return nil, err
+	}
+	object.Internal = iterator
+	return object, nil
+}
+
+// Create an InternalIterator for natively implemented classes of other packages
+func NewInternalIterator(
+	interpreter runtime.Interpreter, count func() int, key func(index int) values.RuntimeValue, current func(index int) values.RuntimeValue,
+) (*values.Object, phpError.Error) {
+	return newInternalIterator(interpreter, &internalIterator{count: count, key: key, current: current})
+}
+
+func getInternalIterator(object *values.Object) (*internalIterator, phpError.Error) {
+	iterator, ok := object.Internal.(*internalIterator)
+	if !ok {
+		return nil, phpError.NewError("Uncaught Error: The InternalIterator object has not been properly initialized")
+	}
+	return iterator, nil
+}
+
+// -------------------------------------- InternalIterator -------------------------------------- MARK: InternalIterator
+
+func registerInternalIterator(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.internaliterator.php
+	runtime.NewNativeClass(interpreter, "InternalIterator").
+		Implements("Iterator").
+		AddMethod("current", []ast.FunctionParameter{}, mixedType, internalIteratorCurrent).
+		AddMethod("key", []ast.FunctionParameter{}, mixedType, internalIteratorKey).
+		AddMethod("next", []ast.FunctionParameter{}, voidType, internalIteratorNext).
+		AddMethod("valid", []ast.FunctionParameter{}, boolType, internalIteratorValid).
+		AddMethod("rewind", []ast.FunctionParameter{}, voidType, internalIteratorRewind).
+		Register()
+}
+
+func internalIteratorCurrent(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("InternalIterator::current").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getInternalIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if iterator.position >= iterator.count() {
+		return values.NewNull(), nil
+	}
+	return iterator.current(iterator.position), nil
+}
+
+func internalIteratorKey(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("InternalIterator::key").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getInternalIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if iterator.position >= iterator.count() {
+		return values.NewNull(), nil
+	}
+	return iterator.key(iterator.position), nil
+}
+
+func internalIteratorNext(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("InternalIterator::next").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getInternalIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	iterator.position++
+	return values.NewVoid(), nil
+}
+
+func internalIteratorValid(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("InternalIterator::valid").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getInternalIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewBool(iterator.position < iterator.count()), nil
+}
+
+func internalIteratorRewind(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("InternalIterator::rewind").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getInternalIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	iterator.position = 0
+	return values.NewVoid(), nil
+}
@@ -0,0 +1,556 @@
+package spl
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/values"
+)
+
+// Call a method of an iterator. User defined and native iterators are handled the same way.
+func callIteratorMethod(iterator *values.Object, method string, context runtime.Context, args ...values.RuntimeValue) (values.RuntimeValue, phpError.Error) {
+	return context.Interpreter.CallMethod(iterator, method, args, context.Env)
+}
+
+func callIteratorMethodBool(iterator *values.Object, method string, context runtime.Context, args ...values.RuntimeValue) (bool, phpError.Error) {
+	result, err := callIteratorMethod(iterator, method, context, args...)
+	if err != nil {
+		return false, err
+	}
+	return variableHandling.BoolVal(result)
+}
+
+// Get the Iterator of a Traversable. An IteratorAggregate is asked for its iterator until an Iterator is returned.
+func getIterator(traversable *values.Object, context runtime.Context) (*values.Object, phpError.Error) {
+	executionContext := context.Interpreter.GetExectionContext()
+	for executionContext.IsInstanceOf(traversable.Class, "IteratorAggregate") {
+		runtimeValue, err := callIteratorMethod(traversable, "getIterator", context)
+		if err != nil {
+			return nil, err
+		}
+		if runtimeValue.GetType() != values.ObjectValue || !executionContext.IsInstanceOf(runtimeValue.(*values.Object).Class, "Traversable") {
+			return nil, phpError.NewError(
+				"Uncaught TypeError: %s::getIterator(): Return value must be of type Traversable, %s returned",
+				traversable.Class.GetQualifiedName(), values.ToPhpType(runtimeValue),
+			)
+		}
+		traversable = runtimeValue.(*values.Object)
+	}
+	if !executionContext.IsInstanceOf(traversable.Class, "Iterator") {
+		return nil, phpError.NewError("Uncaught TypeError: %s is not traversable", traversable.Class.GetQualifiedName())
+	}
+	return traversable, nil
+}
+
+// Iterate a Traversable lazily. The callback returns false to stop the iteration.
+func iterate(traversable *values.Object, context runtime.Context, callback func(key, value values.RuntimeValue) (bool, phpError.Error)) phpError.Error {
+	iterator, err := getIterator(traversable, context)
+	if err != nil {
+		return err
+	}
+
+	if _, err := callIteratorMethod(iterator, "rewind", context); err != nil {
+		return err
+	}
+	for {
+		valid, err := callIteratorMethodBool(iterator, "valid", context)
+		if err != nil || !valid {
+			return err
+		}
+		value, err := callIteratorMethod(iterator, "current", context)
+		if err != nil {
+			return err
+		}
+		key, err := callIteratorMethod(iterator, "key", context)
+		if err != nil {
+			return err
+		}
+		if proceed, err := callback(key, value); err != nil || !proceed {
+			return err
+		}
+		if _, err := callIteratorMethod(iterator, "next", context); err != nil {
+			return err
+		}
+	}
+}
+
+// Internal state of IteratorIterator and its subclasses.
+// Current element and key of the inner iterator are cached after each move, as done by PHP.
+type dualIterator struct {
+	inner    *values.Object
+	valid    bool
+	current  values.RuntimeValue
+	key      values.RuntimeValue
+	position int64
+	// LimitIterator
+	offset int64
+	limit  int64
+	// CallbackFilterIterator
+	callback values.RuntimeValue
+}
+
+func (iterator *dualIterator) GetReferences() []values.RuntimeValue {
+	references := []values.RuntimeValue{iterator.current, iterator.key, iterator.callback}
+	if iterator.inner != nil {
+		references = append(references, iterator.inner)
+	}
+	return references
+}
+
+func getDualIterator(object *values.Object) (*dualIterator, phpError.Error) {
+	iterator, ok := object.Internal.(*dualIterator)
+	if !ok {
+		return nil, phpError.NewError(
+			"Uncaught LogicException: The object is in an invalid state as the parent constructor was not called",
+		)
+	}
+	return iterator, nil
+}
+
+func newDualIterator(object *values.Object, traversable values.RuntimeValue, context runtime.Context) (*dualIterator, phpError.Error) {
+	if traversable.GetType() != values.ObjectValue || !context.Interpreter.GetExectionContext().IsInstanceOf(traversable.(*values.Object).Class, "Traversable") {
+		return nil, phpError.NewError(
+			"Uncaught TypeError: %s::__construct(): Argument #1 ($iterator) must be of type Traversable, %s given",
+			object.Class.GetQualifiedName(), values.ToPhpType(traversable),
+		)
+	}
+	inner, err := getIterator(traversable.(*values.Object), context)
+	if err != nil {
+		return nil, err
+	}
+	iterator := &dualIterator{inner: inner, current: values.NewNull(), key: values.NewNull(), limit: -1}
+	values.Retain(inner)
+	object.Internal = iterator
+	return iterator, nil
+}
+
+// Cache the current element and key of the inner iterator
+func (iterator *dualIterator) fetch(context runtime.Context) phpError.Error {
+	iterator.valid = false
+	values.Store(&iterator.current, values.NewNull())
+	values.Store(&iterator.key, values.NewNull())
+
+	valid, err := callIteratorMethodBool(iterator.inner, "valid", context)
+	if err != nil || !valid {
+		return err
+	}
+	current, err := callIteratorMethod(iterator.inner, "current", context)
+	if err != nil {
+		return err
+	}
+	values.Store(&iterator.current, current)
+	key, err := callIteratorMethod(iterator.inner, "key", context)
+	if err != nil {
+		return err
+	}
+	values.Store(&iterator.key, key)
+	iterator.valid = true
+	return nil
+}
+
+func (iterator *dualIterator) rewind(context runtime.Context) phpError.Error {
+	if _, err := callIteratorMethod(iterator.inner, "rewind", context); err != nil {
+		return err
+	}
+	iterator.position = 0
+	return iterator.fetch(context)
+}
+
+func (iterator *dualIterator) next(context runtime.Context) phpError.Error {
+	if _, err := callIteratorMethod(iterator.inner, "next", context); err != nil {
+		return err
+	}
+	iterator.position++
+	return iterator.fetch(context)
+}
+
+// -------------------------------------- IteratorIterator -------------------------------------- MARK: IteratorIterator
+
+func registerIteratorIterator(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.iteratoriterator.php
+	runtime.NewNativeClass(interpreter, "IteratorIterator").
+		Implements("OuterIterator").
+		AddMethod("__construct", []ast.FunctionParameter{
+			runtime.NewParam("$iterator", "Traversable"),
+			runtime.NewOptionalParam("$class", newNullLiteral(), "string", "null"),
+		}, nil, iteratorIteratorConstruct).
+		AddMethod("current", []ast.FunctionParameter{}, mixedType, iteratorIteratorCurrent).
+		AddMethod("getInnerIterator", []ast.FunctionParameter{}, []string{"null", "Iterator"}, iteratorIteratorGetInnerIterator).
+		AddMethod("key", []ast.FunctionParameter{}, mixedType, iteratorIteratorKey).
+		AddMethod("next", []ast.FunctionParameter{}, voidType, iteratorIteratorNext).
+		AddMethod("rewind", []ast.FunctionParameter{}, voidType, iteratorIteratorRewind).
+		AddMethod("valid", []ast.FunctionParameter{}, boolType, iteratorIteratorValid).
+		Register()
+}
+
+func iteratorIteratorConstruct(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("IteratorIterator::__construct").
+		AddParam("$iterator", []string{"object"}, nil).AddParam("$class", []string{"string", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	_, err = newDualIterator(object, args[0], context)
+	return values.NewVoid(), err
+}
+
+func iteratorIteratorCurrent(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("IteratorIterator::current").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getDualIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return iterator.current, nil
+}
+
+func iteratorIteratorGetInnerIterator(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("IteratorIterator::getInnerIterator").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getDualIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return iterator.inner, nil
+}
+
+func iteratorIteratorKey(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("IteratorIterator::key").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getDualIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return iterator.key, nil
+}
+
+func iteratorIteratorNext(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("IteratorIterator::next").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getDualIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewVoid(), iterator.next(context)
+}
+
+func iteratorIteratorRewind(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("IteratorIterator::rewind").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getDualIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewVoid(), iterator.rewind(context)
+}
+
+func iteratorIteratorValid(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("IteratorIterator::valid").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getDualIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewBool(iterator.valid), nil
+}
+
+// -------------------------------------- FilterIterator -------------------------------------- MARK: FilterIterator
+
+func registerFilterIterator(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.filteriterator.php
+	runtime.NewNativeClass(interpreter, "FilterIterator").
+		Abstract().
+		Extends("IteratorIterator").
+		AddAbstractMethod("accept", []ast.FunctionParameter{}, boolType).
+		AddMethod("__construct", []ast.FunctionParameter{runtime.NewParam("$iterator", "Iterator")}, nil, filterIteratorConstruct).
+		AddMethod("next", []ast.FunctionParameter{}, voidType, filterIteratorNext).
+		AddMethod("rewind", []ast.FunctionParameter{}, voidType, filterIteratorRewind).
+		Register()
+}
+
+// Move forward until an element is accepted or the inner iterator is exhausted
+func fetchAccepted(object *values.Object, iterator *dualIterator, context runtime.Context) phpError.Error {
+	for {
+		if err := iterator.fetch(context); err != nil || !iterator.valid {
+			return err
+		}
+		accepted, err := callIteratorMethodBool(object, "accept", context)
+		if err != nil || accepted {
+			return err
+		}
+		if _, err := callIteratorMethod(iterator.inner, "next", context); err != nil {
+			return err
+		}
+	}
+}
+
+func filterIteratorConstruct(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("FilterIterator::__construct").AddParam("$iterator", []string{"object"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	_, err = newDualIterator(object, args[0], context)
+	return values.NewVoid(), err
+}
+
+func filterIteratorNext(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("FilterIterator::next").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getDualIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if _, err := callIteratorMethod(iterator.inner, "next", context); err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewVoid(), fetchAccepted(object, iterator, context)
+}
+
+func filterIteratorRewind(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("FilterIterator::rewind").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getDualIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if _, err := callIteratorMethod(iterator.inner, "rewind", context); err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewVoid(), fetchAccepted(object, iterator, context)
+}
+
+// -------------------------------------- CallbackFilterIterator -------------------------------------- MARK: CallbackFilterIterator
+
+func registerCallbackFilterIterator(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.callbackfilteriterator.php
+	runtime.NewNativeClass(interpreter, "CallbackFilterIterator").
+		Extends("FilterIterator").
+		AddMethod("__construct", []ast.FunctionParameter{
+			runtime.NewParam("$iterator", "Iterator"),
+			runtime.NewParam("$callback", "callable"),
+		}, nil, callbackFilterIteratorConstruct).
+		AddMethod("accept", []ast.FunctionParameter{}, boolType, callbackFilterIteratorAccept).
+		Register()
+}
+
+func callbackFilterIteratorConstruct(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("CallbackFilterIterator::__construct").
+		AddParam("$iterator", []string{"object"}, nil).AddParam("$callback", []string{"mixed"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if err := context.ValidateCallback("CallbackFilterIterator::__construct", 2, "$callback", args[1], false); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := newDualIterator(object, args[0], context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	values.Store(&iterator.callback, args[1])
+	return values.NewVoid(), nil
+}
+
+func callbackFilterIteratorAccept(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("CallbackFilterIterator::accept").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getDualIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	result, err := context.Interpreter.CallFunction(
+		iterator.callback, []values.RuntimeValue{iterator.current, iterator.key, iterator.inner}, context.Env,
+	)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	accepted, err := variableHandling.BoolVal(result)
+	return values.NewBool(accepted), err
+}
+
+// -------------------------------------- LimitIterator -------------------------------------- MARK: LimitIterator
+
+func registerLimitIterator(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.limititerator.php
+	runtime.NewNativeClass(interpreter, "LimitIterator").
+		Extends("IteratorIterator").
+		AddMethod("__construct", []ast.FunctionParameter{
+			runtime.NewParam("$iterator", "Iterator"),
+			runtime.NewOptionalParam("$offset", newIntLiteral(0), "int"),
+			runtime.NewOptionalParam("$limit", newIntLiteral(-1), "int"),
+		}, nil, limitIteratorConstruct).
+		AddMethod("getPosition", []ast.FunctionParameter{}, intType, limitIteratorGetPosition).
+		AddMethod("next", []ast.FunctionParameter{}, voidType, limitIteratorNext).
+		AddMethod("rewind", []ast.FunctionParameter{}, voidType, limitIteratorRewind).
+		AddMethod("seek", []ast.FunctionParameter{runtime.NewParam("$offset", "int")}, intType, limitIteratorSeek).
+		AddMethod("valid", []ast.FunctionParameter{}, boolType, limitIteratorValid).
+		Register()
+}
+
+// Move the inner iterator to the given position. A SeekableIterator is moved directly.
+func (iterator *dualIterator) seek(position int64, context runtime.Context) phpError.Error {
+	if position < iterator.offset {
+		return newException("OutOfBoundsException", "Cannot seek to %d which is below the offset %d", position, iterator.offset)
+	}
+	if iterator.limit != -1 && position >= iterator.offset+iterator.limit {
+		return newException(
+			"OutOfBoundsException", "Cannot seek to %d which is behind offset %d plus count %d", position, iterator.offset, iterator.limit,
+		)
+	}
+
+	if position != iterator.position && context.Interpreter.GetExectionContext().IsInstanceOf(iterator.inner.Class, "SeekableIterator") {
+		if _, err := callIteratorMethod(iterator.inner, "seek", context, values.NewInt(position)); err != nil {
+			return err
+		}
+		iterator.position = position
+		return iterator.fetch(context)
+	}
+
+	if position < iterator.position {
+		if err := iterator.rewind(context); err != nil {
+			return err
+		}
+	}
+	for iterator.position < position && iterator.valid {
+		if err := iterator.next(context); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func limitIteratorConstruct(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("LimitIterator::__construct").
+		AddParam("$iterator", []string{"object"}, nil).
+		AddParam("$offset", intType, values.NewInt(0)).
+		AddParam("$limit", intType, values.NewInt(-1)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	offset := args[1].(*values.Int).Value
+	limit := args[2].(*values.Int).Value
+	if offset < 0 {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: LimitIterator::__construct(): Argument #2 ($offset) must be greater than or equal to 0",
+		)
+	}
+	if limit < -1 {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: LimitIterator::__construct(): Argument #3 ($limit) must be greater than or equal to -1",
+		)
+	}
+
+	iterator, err := newDualIterator(object, args[0], context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	iterator.offset = offset
+	iterator.limit = limit
+	return values.NewVoid(), nil
+}
+
+func limitIteratorGetPosition(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("LimitIterator::getPosition").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getDualIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(iterator.position), nil
+}
+
+func limitIteratorNext(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("LimitIterator::next").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getDualIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if _, err := callIteratorMethod(iterator.inner, "next", context); err != nil {
+		return values.NewVoid(), err
+	}
+	iterator.position++
+	// Elements behind the limit are not fetched
+	if iterator.limit == -1 || iterator.position < iterator.offset+iterator.limit {
+		return values.NewVoid(), iterator.fetch(context)
+	}
+	iterator.valid = false
+	return values.NewVoid(), nil
+}
+
+func limitIteratorRewind(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("LimitIterator::rewind").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getDualIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if err := iterator.rewind(context); err != nil {
+		return values.NewVoid(), err
+	}
+	if iterator.limit == 0 {
+		return values.NewVoid(), nil
+	}
+	return values.NewVoid(), iterator.seek(iterator.offset, context)
+}
+
+func limitIteratorSeek(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("LimitIterator::seek").AddParam("$offset", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getDualIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if err := iterator.seek(args[0].(*values.Int).Value, context); err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(iterator.position), nil
+}
+
+func limitIteratorValid(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("LimitIterator::valid").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getDualIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	inLimit := iterator.limit == -1 || iterator.position < iterator.offset+iterator.limit
+	return values.NewBool(inLimit && iterator.valid), nil
+}
@@ -0,0 +1,326 @@
+package spl
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+	"slices"
+)
+
+// Internal state of SplObjectStorage.
+// Objects are identified by their handle (pointer) and keep their insertion order.
+type objectStorage struct {
+	objects  []*values.Object
+	data     map[*values.Object]values.RuntimeValue
+	position int
+}
+
+func (storage *objectStorage) GetReferences() []values.RuntimeValue {
+	references := make([]values.RuntimeValue, 0, 2*len(storage.objects))
+	for _, object := range storage.objects {
+		references = append(references, object, storage.data[object])
+	}
+	return references
+}
+
+func getObjectStorage(object *values.Object) *objectStorage {
+	if object.Internal == nil {
+		object.Internal = &objectStorage{objects: []*values.Object{}, data: map[*values.Object]values.RuntimeValue{}}
+	}
+	return object.Internal.(*objectStorage)
+}
+
+func (storage *objectStorage) attach(object *values.Object, info values.RuntimeValue) {
+	old, found := storage.data[object]
+	if !found {
+		storage.objects = append(storage.objects, object)
+		values.Retain(object)
+	}
+	values.Store(&old, info)
+	storage.data[object] = info
+}
+
+func (storage *objectStorage) detach(object *values.Object) {
+	info, found := storage.data[object]
+	if !found {
+		return
+	}
+	delete(storage.data, object)
+	storage.objects = slices.DeleteFunc(storage.objects, func(o *values.Object) bool { return o == object })
+	values.Release(object)
+	values.Release(info)
+}
+
+func (storage *objectStorage) contains(object *values.Object) bool {
+	_, found := storage.data[object]
+	return found
+}
+
+// -------------------------------------- SplObjectStorage -------------------------------------- MARK: SplObjectStorage
+
+func registerSplObjectStorage(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.splobjectstorage.php
+	runtime.NewNativeClass(interpreter, "SplObjectStorage").
+		Implements("Countable", "SeekableIterator", "ArrayAccess").
+		AddMethod("addAll", []ast.FunctionParameter{runtime.NewParam("$storage", "SplObjectStorage")}, intType, splObjectStorageAddAll).
+		AddMethod("attach", []ast.FunctionParameter{runtime.NewParam("$object", "object"), runtime.NewOptionalParam("$info", newNullLiteral(), "mixed")}, voidType, splObjectStorageAttach).
+		AddMethod("contains", []ast.FunctionParameter{runtime.NewParam("$object", "object")}, boolType, splObjectStorageContains).
+		AddMethod("count", []ast.FunctionParameter{runtime.NewOptionalParam("$mode", newIntLiteral(0), "int")}, intType, splObjectStorageCount).
+		AddMethod("current", []ast.FunctionParameter{}, []string{"object"}, splObjectStorageCurrent).
+		AddMethod("detach", []ast.FunctionParameter{runtime.NewParam("$object", "object")}, voidType, splObjectStorageDetach).
+		AddMethod("getHash", []ast.FunctionParameter{runtime.NewParam("$object", "object")}, []string{"string"}, splObjectStorageGetHash).
+		AddMethod("getInfo", []ast.FunctionParameter{}, mixedType, splObjectStorageGetInfo).
+		AddMethod("key", []ast.FunctionParameter{}, intType, splObjectStorageKey).
+		AddMethod("next", []ast.FunctionParameter{}, voidType, splObjectStorageNext).
+		AddMethod("offsetExists", []ast.FunctionParameter{runtime.NewParam("$offset", "mixed")}, boolType, splObjectStorageOffsetExists).
+		AddMethod("offsetGet", []ast.FunctionParameter{runtime.NewParam("$offset", "mixed")}, mixedType, splObjectStorageOffsetGet).
+		AddMethod("offsetSet", []ast.FunctionParameter{runtime.NewParam("$offset", "mixed"), runtime.NewParam("$value", "mixed")}, voidType, splObjectStorageOffsetSet).
+		AddMethod("offsetUnset", []ast.FunctionParameter{runtime.NewParam("$offset", "mixed")}, voidType, splObjectStorageOffsetUnset).
+		AddMethod("removeAll", []ast.FunctionParameter{runtime.NewParam("$storage", "SplObjectStorage")}, intType, splObjectStorageRemoveAll).
+		AddMethod("removeAllExcept", []ast.FunctionParameter{runtime.NewParam("$storage", "SplObjectStorage")}, intType, splObjectStorageRemoveAllExcept).
+		AddMethod("rewind", []ast.FunctionParameter{}, voidType, splObjectStorageRewind).
+		AddMethod("seek", []ast.FunctionParameter{runtime.NewParam("$offset", "int")}, voidType, splObjectStorageSeek).
+		AddMethod("setInfo", []ast.FunctionParameter{runtime.NewParam("$info", "mixed")}, voidType, splObjectStorageSetInfo).
+		AddMethod("valid", []ast.FunctionParameter{}, boolType, splObjectStorageValid).
+		Register()
+}
+
+// Validate that the argument is a SplObjectStorage and get its internal state
+func getObjectStorageArg(methodName string, args []values.RuntimeValue, context runtime.Context) (*objectStorage, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplObjectStorage::"+methodName).AddParam("$storage", []string{"object"}, nil).Validate(args)
+	if err != nil {
+		return nil, err
+	}
+	other := args[0].(*values.Object)
+	if !context.Interpreter.GetExectionContext().IsInstanceOf(other.Class, "SplObjectStorage") {
+		return nil, phpError.NewError(
+			"Uncaught TypeError: SplObjectStorage::%s(): Argument #1 ($storage) must be of type SplObjectStorage, %s given",
+			methodName, other.Class.GetQualifiedName(),
+		)
+	}
+	return getObjectStorage(other), nil
+}
+
+func splObjectStorageAddAll(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	other, err := getObjectStorageArg("addAll", args, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	storage := getObjectStorage(object)
+	for _, otherObject := range other.objects {
+		storage.attach(otherObject, other.data[otherObject])
+	}
+	return values.NewInt(int64(len(storage.objects))), nil
+}
+
+func splObjectStorageAttach(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplObjectStorage::attach").
+		AddParam("$object", []string{"object"}, nil).AddParam("$info", mixedType, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	getObjectStorage(object).attach(args[0].(*values.Object), args[1])
+	return values.NewVoid(), nil
+}
+
+func splObjectStorageContains(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplObjectStorage::contains").AddParam("$object", []string{"object"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(getObjectStorage(object).contains(args[0].(*values.Object))), nil
+}
+
+func splObjectStorageCount(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplObjectStorage::count").AddParam("$mode", intType, values.NewInt(0)).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// TODO SplObjectStorage::count - Add support for COUNT_RECURSIVE
+	return values.NewInt(int64(len(getObjectStorage(object).objects))), nil
+}
+
+func splObjectStorageCurrent(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplObjectStorage::current").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	storage := getObjectStorage(object)
+	if storage.position >= len(storage.objects) {
+		return values.NewVoid(), newException("RuntimeException", "Called current() on invalid iterator")
+	}
+	return storage.objects[storage.position], nil
+}
+
+func splObjectStorageDetach(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplObjectStorage::detach").AddParam("$object", []string{"object"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	getObjectStorage(object).detach(args[0].(*values.Object))
+	return values.NewVoid(), nil
+}
+
+func splObjectStorageGetHash(_ *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplObjectStorage::getHash").AddParam("$object", []string{"object"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(lib_spl_object_hash(args[0].(*values.Object), context)), nil
+}
+
+func splObjectStorageGetInfo(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplObjectStorage::getInfo").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	storage := getObjectStorage(object)
+	if storage.position >= len(storage.objects) {
+		return values.NewNull(), nil
+	}
+	return storage.data[storage.objects[storage.position]], nil
+}
+
+func splObjectStorageKey(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplObjectStorage::key").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(int64(getObjectStorage(object).position)), nil
+}
+
+func splObjectStorageNext(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplObjectStorage::next").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	getObjectStorage(object).position++
+	return values.NewVoid(), nil
+}
+
+func splObjectStorageOffsetExists(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplObjectStorage::offsetExists").AddParam("$object", []string{"object"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(getObjectStorage(object).contains(args[0].(*values.Object))), nil
+}
+
+func splObjectStorageOffsetGet(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplObjectStorage::offsetGet").AddParam("$object", []string{"object"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	info, found := getObjectStorage(object).data[args[0].(*values.Object)]
+	if !found {
+		return values.NewVoid(), newException("UnexpectedValueException", "Object not found")
+	}
+	return info, nil
+}
+
+func splObjectStorageOffsetSet(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplObjectStorage::offsetSet").
+		AddParam("$object", []string{"object"}, nil).AddParam("$info", mixedType, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	getObjectStorage(object).attach(args[0].(*values.Object), args[1])
+	return values.NewVoid(), nil
+}
+
+func splObjectStorageOffsetUnset(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplObjectStorage::offsetUnset").AddParam("$object", []string{"object"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	getObjectStorage(object).detach(args[0].(*values.Object))
+	return values.NewVoid(), nil
+}
+
+func splObjectStorageRemoveAll(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	other, err := getObjectStorageArg("removeAll", args, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	storage := getObjectStorage(object)
+	for _, otherObject := range slices.Clone(other.objects) {
+		storage.detach(otherObject)
+	}
+	return values.NewInt(int64(len(storage.objects))), nil
+}
+
+func splObjectStorageRemoveAllExcept(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	other, err := getObjectStorageArg("removeAllExcept", args, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	storage := getObjectStorage(object)
+	for _, storedObject := range slices.Clone(storage.objects) {
+		if !other.contains(storedObject) {
+			storage.detach(storedObject)
+		}
+	}
+	return values.NewInt(int64(len(storage.objects))), nil
+}
+
+func splObjectStorageRewind(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplObjectStorage::rewind").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	getObjectStorage(object).position = 0
+	return values.NewVoid(), nil
+}
+
+func splObjectStorageSeek(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplObjectStorage::seek").AddParam("$offset", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	storage := getObjectStorage(object)
+	offset := args[0].(*values.Int).Value
+	if offset < 0 || offset >= int64(len(storage.objects)) {
+		return values.NewVoid(), newException("OutOfBoundsException", "Seek position %d is out of range", offset)
+	}
+	storage.position = int(offset)
+	return values.NewVoid(), nil
+}
+
+func splObjectStorageSetInfo(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplObjectStorage::setInfo").AddParam("$info", mixedType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	storage := getObjectStorage(object)
+	if storage.position < len(storage.objects) {
+		storage.attach(storage.objects[storage.position], args[0])
+	}
+	return values.NewVoid(), nil
+}
+
+func splObjectStorageValid(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplObjectStorage::valid").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	storage := getObjectStorage(object)
+	return values.NewBool(storage.position < len(storage.objects)), nil
+}
@@ -0,0 +1,280 @@
+package spl
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/values"
+	"slices"
+)
+
+const (
+	extrData     int64 = 1
+	extrPriority int64 = 2
+	extrBoth     int64 = 3
+)
+
+type priorityQueueElement struct {
+	data     values.RuntimeValue
+	priority values.RuntimeValue
+}
+
+// Internal state of SplPriorityQueue.
+// The elements are sorted by descending priority. Elements with the same priority are extracted in insertion order.
+type priorityQueue struct {
+	elements     []priorityQueueElement
+	extractFlags int64
+}
+
+func (queue *priorityQueue) GetReferences() []values.RuntimeValue {
+	references := make([]values.RuntimeValue, 0, 2*len(queue.elements))
+	for _, element := range queue.elements {
+		references = append(references, element.data, element.priority)
+	}
+	return references
+}
+
+func getPriorityQueue(object *values.Object) *priorityQueue {
+	if object.Internal == nil {
+		object.Internal = &priorityQueue{elements: []priorityQueueElement{}, extractFlags: extrData}
+	}
+	return object.Internal.(*priorityQueue)
+}
+
+// Remove the element with the highest priority
+func (queue *priorityQueue) shift() priorityQueueElement {
+	element := queue.elements[0]
+	queue.elements = queue.elements[1:]
+	values.Release(element.data)
+	values.Release(element.priority)
+	return element
+}
+
+func (queue *priorityQueue) toRuntimeValue(element priorityQueueElement) values.RuntimeValue {
+	switch queue.extractFlags {
+	case extrData:
+		return element.data
+	case extrPriority:
+		return element.priority
+	default:
+		array := values.NewArray()
+		array.SetElement(values.NewStr("data"), element.data)
+		array.SetElement(values.NewStr("priority"), element.priority)
+		return array
+	}
+}
+
+// Compare two priorities. A user defined compare method of a subclass takes precedence.
+func comparePriorities(object *values.Object, priority1, priority2 values.RuntimeValue, context runtime.Context) (int64, phpError.Error) {
+	if method, found := findMethod(context.Interpreter, object.Class, "compare"); found && method.Class.GetQualifiedName() != "SplPriorityQueue" {
+		result, err := context.Interpreter.CallMethod(object, "compare", []values.RuntimeValue{priority1, priority2}, context.Env)
+		if err != nil {
+			return 0, err
+		}
+		return variableHandling.IntVal(result, false)
+	}
+
+	result, err := variableHandling.CompareRelation(priority1, "<=>", priority2, false)
+	if err != nil {
+		return 0, err
+	}
+	return result.Value.(*values.Int).Value, nil
+}
+
+// -------------------------------------- SplPriorityQueue -------------------------------------- MARK: SplPriorityQueue
+
+func registerSplPriorityQueue(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.splpriorityqueue.php
+	runtime.NewNativeClass(interpreter, "SplPriorityQueue").
+		Implements("Iterator", "Countable").
+		AddConst("EXTR_DATA", newIntLiteral(extrData)).
+		AddConst("EXTR_PRIORITY", newIntLiteral(extrPriority)).
+		AddConst("EXTR_BOTH", newIntLiteral(extrBoth)).
+		AddMethod("compare", []ast.FunctionParameter{runtime.NewParam("$priority1", "mixed"), runtime.NewParam("$priority2", "mixed")}, intType, splPriorityQueueCompare).
+		AddMethod("count", []ast.FunctionParameter{}, intType, splPriorityQueueCount).
+		AddMethod("current", []ast.FunctionParameter{}, mixedType, splPriorityQueueCurrent).
+		AddMethod("extract", []ast.FunctionParameter{}, mixedType, splPriorityQueueExtract).
+		AddMethod("getExtractFlags", []ast.FunctionParameter{}, intType, splPriorityQueueGetExtractFlags).
+		AddMethod("insert", []ast.FunctionParameter{runtime.NewParam("$value", "mixed"), runtime.NewParam("$priority", "mixed")}, []string{"true"}, splPriorityQueueInsert).
+		AddMethod("isCorrupted", []ast.FunctionParameter{}, boolType, splPriorityQueueIsCorrupted).
+		AddMethod("isEmpty", []ast.FunctionParameter{}, boolType, splPriorityQueueIsEmpty).
+		AddMethod("key", []ast.FunctionParameter{}, intType, splPriorityQueueKey).
+		AddMethod("next", []ast.FunctionParameter{}, voidType, splPriorityQueueNext).
+		AddMethod("recoverFromCorruption", []ast.FunctionParameter{}, []string{"true"}, splPriorityQueueRecoverFromCorruption).
+		AddMethod("rewind", []ast.FunctionParameter{}, voidType, splPriorityQueueRewind).
+		AddMethod("setExtractFlags", []ast.FunctionParameter{runtime.NewParam("$flags", "int")}, intType, splPriorityQueueSetExtractFlags).
+		AddMethod("top", []ast.FunctionParameter{}, mixedType, splPriorityQueueTop).
+		AddMethod("valid", []ast.FunctionParameter{}, boolType, splPriorityQueueValid).
+		Register()
+}
+
+func splPriorityQueueCompare(_ *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplPriorityQueue::compare").
+		AddParam("$priority1", mixedType, nil).AddParam("$priority2", mixedType, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	result, err := variableHandling.CompareRelation(args[0], "<=>", args[1], false)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return result.Value, nil
+}
+
+func splPriorityQueueCount(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplPriorityQueue::count").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(int64(len(getPriorityQueue(object).elements))), nil
+}
+
+func splPriorityQueueCurrent(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplPriorityQueue::current").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	queue := getPriorityQueue(object)
+	if len(queue.elements) == 0 {
+		return values.NewNull(), nil
+	}
+	return queue.toRuntimeValue(queue.elements[0]), nil
+}
+
+func splPriorityQueueExtract(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplPriorityQueue::extract").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	queue := getPriorityQueue(object)
+	if len(queue.elements) == 0 {
+		return values.NewVoid(), newException("RuntimeException", "Can't extract from an empty heap")
+	}
+	return queue.toRuntimeValue(queue.shift()), nil
+}
+
+func splPriorityQueueGetExtractFlags(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplPriorityQueue::getExtractFlags").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(getPriorityQueue(object).extractFlags), nil
+}
+
+func splPriorityQueueInsert(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplPriorityQueue::insert").
+		AddParam("$value", mixedType, nil).AddParam("$priority", mixedType, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	queue := getPriorityQueue(object)
+	// Insert behind all elements with a higher or the same priority
+	index := len(queue.elements)
+	for index > 0 {
+		result, err := comparePriorities(object, queue.elements[index-1].priority, args[1], context)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		if result >= 0 {
+			break
+		}
+		index--
+	}
+	queue.elements = slices.Insert(queue.elements, index, priorityQueueElement{data: args[0], priority: args[1]})
+	values.Retain(args[0])
+	values.Retain(args[1])
+	return values.NewBool(true), nil
+}
+
+func splPriorityQueueIsCorrupted(_ *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplPriorityQueue::isCorrupted").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(false), nil
+}
+
+func splPriorityQueueIsEmpty(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplPriorityQueue::isEmpty").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(len(getPriorityQueue(object).elements) == 0), nil
+}
+
+func splPriorityQueueKey(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplPriorityQueue::key").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(int64(len(getPriorityQueue(object).elements) - 1)), nil
+}
+
+func splPriorityQueueNext(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplPriorityQueue::next").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Iterating a priority queue removes the elements
+	queue := getPriorityQueue(object)
+	if len(queue.elements) > 0 {
+		queue.shift()
+	}
+	return values.NewVoid(), nil
+}
+
+func splPriorityQueueRecoverFromCorruption(_ *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplPriorityQueue::recoverFromCorruption").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(true), nil
+}
+
+func splPriorityQueueRewind(_ *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplPriorityQueue::rewind").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewVoid(), nil
+}
+
+func splPriorityQueueSetExtractFlags(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("SplPriorityQueue::setExtractFlags").AddParam("$flags", intType, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	flags := args[0].(*values.Int).Value & extrBoth
+	if flags == 0 {
+		return values.NewVoid(), newException("RuntimeException", "Must specify at least one extract flag")
+	}
+	getPriorityQueue(object).extractFlags = flags
+	return values.NewInt(flags), nil
+}
+
+func splPriorityQueueTop(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplPriorityQueue::top").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	queue := getPriorityQueue(object)
+	if len(queue.elements) == 0 {
+		return values.NewVoid(), newException("RuntimeException", "Can't peek at an empty heap")
+	}
+	return queue.toRuntimeValue(queue.elements[0]), nil
+}
+
+func splPriorityQueueValid(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("SplPriorityQueue::valid").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewBool(len(getPriorityQueue(object).elements) > 0), nil
+}
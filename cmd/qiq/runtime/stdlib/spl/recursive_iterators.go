@@ -0,0 +1,494 @@
+package spl
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+)
+
+// -------------------------------------- RecursiveArrayIterator -------------------------------------- MARK: RecursiveArrayIterator
+
+const childArraysOnly int64 = 4
+
+func registerRecursiveArrayIterator(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.recursivearrayiterator.php
+	runtime.NewNativeClass(interpreter, "RecursiveArrayIterator").
+		Extends("ArrayIterator").
+		Implements("RecursiveIterator").
+		AddConst("CHILD_ARRAYS_ONLY", newIntLiteral(childArraysOnly)).
+		AddMethod("getChildren", []ast.FunctionParameter{}, []string{"null", "RecursiveArrayIterator"}, recursiveArrayIteratorGetChildren).
+		AddMethod("hasChildren", []ast.FunctionParameter{}, boolType, recursiveArrayIteratorHasChildren).
+		Register()
+}
+
+func recursiveArrayIteratorGetChildren(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("RecursiveArrayIterator::getChildren").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	current, err := callIteratorMethod(object, "current", context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if current.GetType() != values.ArrayValue && current.GetType() != values.ObjectValue {
+		return values.NewNull(), nil
+	}
+	// An object that is already a RecursiveArrayIterator is returned as it is
+	if current.GetType() == values.ObjectValue && context.Interpreter.GetExectionContext().IsInstanceOf(current.(*values.Object).Class, object.Class.GetQualifiedName()) {
+		return current, nil
+	}
+
+	// The children are iterated by an instance of the same class
+	child := values.NewObject(object.Class)
+	if _, err := callIteratorMethod(child, "__construct", context, current, values.NewInt(getArrayStorage(object).flags)); err != nil {
+		return values.NewVoid(), err
+	}
+	return child, nil
+}
+
+func recursiveArrayIteratorHasChildren(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("RecursiveArrayIterator::hasChildren").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	current, err := callIteratorMethod(object, "current", context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if current.GetType() == values.ArrayValue {
+		return values.NewBool(true), nil
+	}
+	return values.NewBool(current.GetType() == values.ObjectValue && getArrayStorage(object).flags&childArraysOnly == 0), nil
+}
+
+// -------------------------------------- RecursiveIteratorIterator -------------------------------------- MARK: RecursiveIteratorIterator
+
+const (
+	leavesOnly    int64 = 0
+	selfFirst     int64 = 1
+	childFirst    int64 = 2
+	catchGetChild int64 = 16
+)
+
+// State of an iterator level. The states are the same as used by PHP.
+type recursiveIteratorState int
+
+const (
+	rsNext recursiveIteratorState = iota
+	rsTest
+	rsSelf
+	rsChild
+	rsStart
+)
+
+type recursiveIteratorLevel struct {
+	iterator *values.Object
+	state    recursiveIteratorState
+}
+
+// Internal state of RecursiveIteratorIterator.
+// Only the iterators of the current path are kept so that the structure is traversed lazily.
+type recursiveIterator struct {
+	levels      []recursiveIteratorLevel
+	mode        int64
+	flags       int64
+	maxDepth    int64
+	inIteration bool
+}
+
+func (iterator *recursiveIterator) GetReferences() []values.RuntimeValue {
+	references := make([]values.RuntimeValue, 0, len(iterator.levels))
+	for _, level := range iterator.levels {
+		references = append(references, level.iterator)
+	}
+	return references
+}
+
+func getRecursiveIterator(object *values.Object) (*recursiveIterator, phpError.Error) {
+	iterator, ok := object.Internal.(*recursiveIterator)
+	if !ok {
+		return nil, phpError.NewError(
+			"Uncaught LogicException: The object is in an invalid state as the parent constructor was not called",
+		)
+	}
+	return iterator, nil
+}
+
+func (iterator *recursiveIterator) top() *recursiveIteratorLevel {
+	return &iterator.levels[len(iterator.levels)-1]
+}
+
+func (iterator *recursiveIterator) pushLevel(child *values.Object) {
+	iterator.levels = append(iterator.levels, recursiveIteratorLevel{iterator: child, state: rsStart})
+	values.Retain(child)
+}
+
+func (iterator *recursiveIterator) popLevel() {
+	values.Release(iterator.top().iterator)
+	iterator.levels = iterator.levels[:len(iterator.levels)-1]
+}
+
+func (iterator *recursiveIterator) rewind(object *values.Object, context runtime.Context) phpError.Error {
+	// Drop all sub iterators
+	for len(iterator.levels) > 1 {
+		iterator.popLevel()
+		if _, err := callIteratorMethod(object, "endChildren", context); err != nil {
+			return err
+		}
+	}
+	if _, err := callIteratorMethod(iterator.levels[0].iterator, "rewind", context); err != nil {
+		return err
+	}
+	iterator.levels[0].state = rsStart
+	if !iterator.inIteration {
+		if _, err := callIteratorMethod(object, "beginIteration", context); err != nil {
+			return err
+		}
+	}
+	iterator.inIteration = true
+	return iterator.moveForward(object, context)
+}
+
+// Move to the next element according to the mode. Port of spl_recursive_it_move_forward_ex.
+func (iterator *recursiveIterator) moveForward(object *values.Object, context runtime.Context) phpError.Error {
+	for {
+		level := iterator.top()
+		switch level.state {
+		case rsNext, rsStart:
+			if level.state == rsNext {
+				if _, err := callIteratorMethod(level.iterator, "next", context); err != nil {
+					return err
+				}
+			}
+			valid, err := callIteratorMethodBool(level.iterator, "valid", context)
+			if err != nil {
+				return err
+			}
+			if !valid {
+				break
+			}
+			level.state = rsTest
+			continue
+
+		case rsTest:
+			hasChildren, err := callIteratorMethodBool(object, "callHasChildren", context)
+			if err != nil {
+				return err
+			}
+			if iterator.maxDepth != -1 && int64(len(iterator.levels)-1) >= iterator.maxDepth {
+				hasChildren = false
+			}
+			if hasChildren {
+				if iterator.mode == selfFirst {
+					level.state = rsSelf
+				} else {
+					level.state = rsChild
+				}
+				continue
+			}
+			level.state = rsNext
+			_, err = callIteratorMethod(object, "nextElement", context)
+			return err
+
+		case rsSelf:
+			if iterator.mode == selfFirst {
+				level.state = rsChild
+			} else {
+				level.state = rsNext
+			}
+			_, err := callIteratorMethod(object, "nextElement", context)
+			return err
+
+		case rsChild:
+			child, err := callIteratorMethod(object, "callGetChildren", context)
+			if err != nil {
+				if iterator.flags&catchGetChild == 0 {
+					return err
+				}
+				level.state = rsNext
+				continue
+			}
+			if child.GetType() != values.ObjectValue || !context.Interpreter.GetExectionContext().IsInstanceOf(child.(*values.Object).Class, "RecursiveIterator") {
+				return newException("UnexpectedValueException", "Objects returned by RecursiveIterator::getChildren() must implement RecursiveIterator")
+			}
+			if iterator.mode == childFirst {
+				level.state = rsSelf
+			} else {
+				level.state = rsNext
+			}
+			iterator.pushLevel(child.(*values.Object))
+			if _, err := callIteratorMethod(child.(*values.Object), "rewind", context); err != nil {
+				return err
+			}
+			if _, err := callIteratorMethod(object, "beginChildren", context); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// The current level is exhausted
+		if len(iterator.levels) == 1 {
+			return nil
+		}
+		iterator.popLevel()
+		if _, err := callIteratorMethod(object, "endChildren", context); err != nil {
+			return err
+		}
+	}
+}
+
+func (iterator *recursiveIterator) valid(object *values.Object, context runtime.Context) (bool, phpError.Error) {
+	for index := len(iterator.levels) - 1; index >= 0; index-- {
+		valid, err := callIteratorMethodBool(iterator.levels[index].iterator, "valid", context)
+		if err != nil || valid {
+			return valid, err
+		}
+	}
+	if iterator.inIteration {
+		iterator.inIteration = false
+		if _, err := callIteratorMethod(object, "endIteration", context); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+func registerRecursiveIteratorIterator(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.recursiveiteratoriterator.php
+	runtime.NewNativeClass(interpreter, "RecursiveIteratorIterator").
+		Implements("OuterIterator").
+		AddConst("LEAVES_ONLY", newIntLiteral(leavesOnly)).
+		AddConst("SELF_FIRST", newIntLiteral(selfFirst)).
+		AddConst("CHILD_FIRST", newIntLiteral(childFirst)).
+		AddConst("CATCH_GET_CHILD", newIntLiteral(catchGetChild)).
+		AddMethod("__construct", []ast.FunctionParameter{
+			runtime.NewParam("$iterator", "Traversable"),
+			runtime.NewOptionalParam("$mode", newIntLiteral(leavesOnly), "int"),
+			runtime.NewOptionalParam("$flags", newIntLiteral(0), "int"),
+		}, nil, recursiveIteratorIteratorConstruct).
+		AddMethod("beginChildren", []ast.FunctionParameter{}, voidType, recursiveIteratorIteratorHook).
+		AddMethod("beginIteration", []ast.FunctionParameter{}, voidType, recursiveIteratorIteratorHook).
+		AddMethod("callGetChildren", []ast.FunctionParameter{}, []string{"null", "RecursiveIterator"}, recursiveIteratorIteratorCallGetChildren).
+		AddMethod("callHasChildren", []ast.FunctionParameter{}, boolType, recursiveIteratorIteratorCallHasChildren).
+		AddMethod("current", []ast.FunctionParameter{}, mixedType, recursiveIteratorIteratorCurrent).
+		AddMethod("endChildren", []ast.FunctionParameter{}, voidType, recursiveIteratorIteratorHook).
+		AddMethod("endIteration", []ast.FunctionParameter{}, voidType, recursiveIteratorIteratorHook).
+		AddMethod("getDepth", []ast.FunctionParameter{}, intType, recursiveIteratorIteratorGetDepth).
+		AddMethod("getInnerIterator", []ast.FunctionParameter{}, []string{"RecursiveIterator"}, recursiveIteratorIteratorGetInnerIterator).
+		AddMethod("getMaxDepth", []ast.FunctionParameter{}, []string{"int", "false"}, recursiveIteratorIteratorGetMaxDepth).
+		AddMethod("getSubIterator", []ast.FunctionParameter{runtime.NewOptionalParam("$level", newNullLiteral(), "int", "null")}, []string{"null", "RecursiveIterator"}, recursiveIteratorIteratorGetSubIterator).
+		AddMethod("key", []ast.FunctionParameter{}, mixedType, recursiveIteratorIteratorKey).
+		AddMethod("next", []ast.FunctionParameter{}, voidType, recursiveIteratorIteratorNext).
+		AddMethod("nextElement", []ast.FunctionParameter{}, voidType, recursiveIteratorIteratorHook).
+		AddMethod("rewind", []ast.FunctionParameter{}, voidType, recursiveIteratorIteratorRewind).
+		AddMethod("setMaxDepth", []ast.FunctionParameter{runtime.NewOptionalParam("$maxDepth", newIntLiteral(-1), "int")}, voidType, recursiveIteratorIteratorSetMaxDepth).
+		AddMethod("valid", []ast.FunctionParameter{}, boolType, recursiveIteratorIteratorValid).
+		Register()
+}
+
+func recursiveIteratorIteratorConstruct(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("RecursiveIteratorIterator::__construct").
+		AddParam("$iterator", []string{"object"}, nil).
+		AddParam("$mode", intType, values.NewInt(leavesOnly)).
+		AddParam("$flags", intType, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	executionContext := context.Interpreter.GetExectionContext()
+	iterator := args[0].(*values.Object)
+	if executionContext.IsInstanceOf(iterator.Class, "IteratorAggregate") {
+		if iterator, err = getIterator(iterator, context); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	if !executionContext.IsInstanceOf(iterator.Class, "RecursiveIterator") {
+		return values.NewVoid(), newException(
+			"InvalidArgumentException", "An instance of RecursiveIterator or IteratorAggregate creating it is required",
+		)
+	}
+
+	object.Internal = &recursiveIterator{
+		levels:   []recursiveIteratorLevel{{iterator: iterator, state: rsStart}},
+		mode:     args[1].(*values.Int).Value,
+		flags:    args[2].(*values.Int).Value,
+		maxDepth: -1,
+	}
+	values.Retain(iterator)
+	return values.NewVoid(), nil
+}
+
+// Default implementation of the hooks beginChildren, beginIteration, endChildren, endIteration and nextElement.
+// The hooks can be overridden by user defined subclasses.
+func recursiveIteratorIteratorHook(_ *values.Object, _ []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	return values.NewVoid(), nil
+}
+
+func recursiveIteratorIteratorCallGetChildren(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("RecursiveIteratorIterator::callGetChildren").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getRecursiveIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return callIteratorMethod(iterator.top().iterator, "getChildren", context)
+}
+
+func recursiveIteratorIteratorCallHasChildren(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("RecursiveIteratorIterator::callHasChildren").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getRecursiveIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return callIteratorMethod(iterator.top().iterator, "hasChildren", context)
+}
+
+func recursiveIteratorIteratorCurrent(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("RecursiveIteratorIterator::current").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getRecursiveIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return callIteratorMethod(iterator.top().iterator, "current", context)
+}
+
+func recursiveIteratorIteratorGetDepth(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("RecursiveIteratorIterator::getDepth").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getRecursiveIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(int64(len(iterator.levels) - 1)), nil
+}
+
+func recursiveIteratorIteratorGetInnerIterator(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("RecursiveIteratorIterator::getInnerIterator").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getRecursiveIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return iterator.top().iterator, nil
+}
+
+func recursiveIteratorIteratorGetMaxDepth(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("RecursiveIteratorIterator::getMaxDepth").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getRecursiveIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if iterator.maxDepth == -1 {
+		return values.NewBool(false), nil
+	}
+	return values.NewInt(iterator.maxDepth), nil
+}
+
+func recursiveIteratorIteratorGetSubIterator(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("RecursiveIteratorIterator::getSubIterator").
+		AddParam("$level", []string{"int", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getRecursiveIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	level := int64(len(iterator.levels) - 1)
+	if args[0].GetType() == values.IntValue {
+		level = args[0].(*values.Int).Value
+	}
+	if level < 0 || level >= int64(len(iterator.levels)) {
+		return values.NewNull(), nil
+	}
+	return iterator.levels[level].iterator, nil
+}
+
+func recursiveIteratorIteratorKey(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("RecursiveIteratorIterator::key").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getRecursiveIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return callIteratorMethod(iterator.top().iterator, "key", context)
+}
+
+func recursiveIteratorIteratorNext(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("RecursiveIteratorIterator::next").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getRecursiveIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewVoid(), iterator.moveForward(object, context)
+}
+
+func recursiveIteratorIteratorRewind(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("RecursiveIteratorIterator::rewind").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getRecursiveIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewVoid(), iterator.rewind(object, context)
+}
+
+func recursiveIteratorIteratorSetMaxDepth(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("RecursiveIteratorIterator::setMaxDepth").AddParam("$maxDepth", intType, values.NewInt(-1)).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getRecursiveIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	maxDepth := args[0].(*values.Int).Value
+	if maxDepth < -1 {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: RecursiveIteratorIterator::setMaxDepth(): Argument #1 ($maxDepth) must be greater than or equal to -1",
+		)
+	}
+	iterator.maxDepth = maxDepth
+	return values.NewVoid(), nil
+}
+
+func recursiveIteratorIteratorValid(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("RecursiveIteratorIterator::valid").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := getRecursiveIterator(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	valid, err := iterator.valid(object, context)
+	return values.NewBool(valid), err
+}
@@ -0,0 +1,208 @@
+package spl
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/values"
+	"fmt"
+)
+
+func Register(environment runtime.Environment) {
+	// Category: SPL Functions
+	environment.AddNativeFunction("iterator_apply", nativeFn_iterator_apply)
+	environment.AddNativeFunction("iterator_count", nativeFn_iterator_count)
+	environment.AddNativeFunction("iterator_to_array", nativeFn_iterator_to_array)
+	environment.AddNativeFunction("spl_object_hash", nativeFn_spl_object_hash)
+	environment.AddNativeFunction("spl_object_id", nativeFn_spl_object_id)
+}
+
+func RegisterClasses(interpreter runtime.Interpreter) {
+	registerInternalIterator(interpreter)
+	registerArrayObject(interpreter)
+	registerArrayIterator(interpreter)
+	registerSplDoublyLinkedList(interpreter)
+	registerSplObjectStorage(interpreter)
+	registerSplFixedArray(interpreter)
+	registerSplPriorityQueue(interpreter)
+	registerIteratorIterator(interpreter)
+	registerFilterIterator(interpreter)
+	registerCallbackFilterIterator(interpreter)
+	registerLimitIterator(interpreter)
+	registerRecursiveArrayIterator(interpreter)
+	registerRecursiveIteratorIterator(interpreter)
+	registerWeakReference(interpreter)
+	registerWeakMap(interpreter)
+	registerSplFileInfo(interpreter)
+	registerDirectoryIterator(interpreter)
+	registerFilesystemIterator(interpreter)
+	registerRecursiveDirectoryIterator(interpreter)
+	registerSplFileObject(interpreter)
+}
+
+// -------------------------------------- iterator_apply -------------------------------------- MARK: iterator_apply
+
+func nativeFn_iterator_apply(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.iterator-apply.php
+
+	args, err := funcParamValidator.NewValidator("iterator_apply").
+		AddParam("$iterator", []string{"object"}, nil).
+		AddParam("$callback", []string{"mixed"}, nil).
+		AddParam("$args", []string{"array", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	iterator, err := toTraversable(args[0], "iterator_apply", context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if err := context.ValidateCallback("iterator_apply", 2, "$callback", args[1], false); err != nil {
+		return values.NewVoid(), err
+	}
+	callbackArgs := []values.RuntimeValue{}
+	if args[2].GetType() == values.ArrayValue {
+		array := args[2].(*values.Array)
+		for _, key := range array.Keys {
+			slot, _ := array.GetElement(key)
+			callbackArgs = append(callbackArgs, slot.Value)
+		}
+	}
+
+	// The callback is called for each element until it returns false
+	var count int64 = 0
+	err = iterate(iterator, context, func(_, _ values.RuntimeValue) (bool, phpError.Error) {
+		count++
+		result, err := context.Interpreter.CallFunction(args[1], callbackArgs, context.Env)
+		if err != nil {
+			return false, err
+		}
+		return variableHandling.BoolVal(result)
+	})
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(count), nil
+}
+
+// -------------------------------------- iterator_count -------------------------------------- MARK: iterator_count
+
+func nativeFn_iterator_count(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.iterator-count.php
+
+	args, err := funcParamValidator.NewValidator("iterator_count").AddParam("$iterator", []string{"array", "object"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if args[0].GetType() == values.ArrayValue {
+		return values.NewInt(int64(len(args[0].(*values.Array).Keys))), nil
+	}
+	iterator, err := toTraversable(args[0], "iterator_count", context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	var count int64 = 0
+	err = iterate(iterator, context, func(_, _ values.RuntimeValue) (bool, phpError.Error) {
+		count++
+		return true, nil
+	})
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(count), nil
+}
+
+// -------------------------------------- iterator_to_array -------------------------------------- MARK: iterator_to_array
+
+func nativeFn_iterator_to_array(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.iterator-to-array.php
+
+	args, err := funcParamValidator.NewValidator("iterator_to_array").
+		AddParam("$iterator", []string{"array", "object"}, nil).
+		AddParam("$preserve_keys", []string{"bool"}, values.NewBool(true)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	preserveKeys := args[1].(*values.Bool).Value
+	if args[0].GetType() == values.ArrayValue {
+		if preserveKeys {
+			return args[0], nil
+		}
+		array := args[0].(*values.Array)
+		result := values.NewArray()
+		for _, key := range array.Keys {
+			slot, _ := array.GetElement(key)
+			result.SetElement(nil, slot.Value)
+		}
+		return result, nil
+	}
+
+	iterator, err := toTraversable(args[0], "iterator_to_array", context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	result := values.NewArray()
+	err = iterate(iterator, context, func(key, value values.RuntimeValue) (bool, phpError.Error) {
+		if !preserveKeys {
+			return true, result.SetElement(nil, value)
+		}
+		if key.GetType() != values.IntValue && key.GetType() != values.StrValue && key.GetType() != values.NullValue &&
+			key.GetType() != values.BoolValue && key.GetType() != values.FloatValue {
+			return false, phpError.NewError("Uncaught TypeError: Cannot access offset of type %s on array", values.ToPhpType(key))
+		}
+		return true, result.SetElement(key, value)
+	})
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return result, nil
+}
+
+// Check that the value is a Traversable object
+func toTraversable(value values.RuntimeValue, functionName string, context runtime.Context) (*values.Object, phpError.Error) {
+	if value.GetType() != values.ObjectValue || !context.Interpreter.GetExectionContext().IsInstanceOf(value.(*values.Object).Class, "Traversable") {
+		typeStr := values.ToPhpType(value)
+		if value.GetType() == values.ObjectValue {
+			typeStr = value.(*values.Object).Class.GetQualifiedName()
+		}
+		return nil, phpError.NewError(
+			"Uncaught TypeError: %s(): Argument #1 ($iterator) must be of type Traversable|array, %s given", functionName, typeStr,
+		)
+	}
+	return value.(*values.Object), nil
+}
+
+// -------------------------------------- spl_object_hash -------------------------------------- MARK: spl_object_hash
+
+func nativeFn_spl_object_hash(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.spl-object-hash.php
+
+	args, err := funcParamValidator.NewValidator("spl_object_hash").AddParam("$object", []string{"object"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(lib_spl_object_hash(args[0].(*values.Object), context)), nil
+}
+
+func lib_spl_object_hash(object *values.Object, context runtime.Context) string {
+	return fmt.Sprintf("%016x%016x", context.Interpreter.GetExectionContext().GetObjectId(object), 0)
+}
+
+// -------------------------------------- spl_object_id -------------------------------------- MARK: spl_object_id
+
+func nativeFn_spl_object_id(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.spl-object-id.php
+
+	args, err := funcParamValidator.NewValidator("spl_object_id").AddParam("$object", []string{"object"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(context.Interpreter.GetExectionContext().GetObjectId(args[0].(*values.Object))), nil
+}
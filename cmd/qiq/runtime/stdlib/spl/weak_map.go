@@ -0,0 +1,153 @@
+package spl
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+	"slices"
+)
+
+// Internal state of WeakMap.
+// Entries are removed as soon as their key object is freed.
+type weakMap struct {
+	objects []*values.Object
+	data    map[*values.Object]values.RuntimeValue
+}
+
+func getWeakMap(object *values.Object) *weakMap {
+	if object.Internal == nil {
+		object.Internal = &weakMap{objects: []*values.Object{}, data: map[*values.Object]values.RuntimeValue{}}
+	}
+	return object.Internal.(*weakMap)
+}
+
+// Only the values are strong references
+func (weakMap *weakMap) GetReferences() []values.RuntimeValue {
+	references := make([]values.RuntimeValue, 0, len(weakMap.objects))
+	for _, object := range weakMap.objects {
+		references = append(references, weakMap.data[object])
+	}
+	return references
+}
+
+func (weakMap *weakMap) RemoveWeakReference(key *values.Object) {
+	if value, found := weakMap.data[key]; found {
+		values.Release(value)
+		delete(weakMap.data, key)
+		weakMap.objects = slices.DeleteFunc(weakMap.objects, func(object *values.Object) bool { return object == key })
+	}
+}
+
+// Validate the offset argument
+func getWeakMapKey(object *values.Object, args []values.RuntimeValue, validator *funcParamValidator.Validator) (*weakMap, []values.RuntimeValue, phpError.Error) {
+	args, err := validator.Validate(args)
+	if err != nil {
+		return nil, args, err
+	}
+	if args[0].GetType() != values.ObjectValue {
+		return nil, args, phpError.NewError("Uncaught TypeError: WeakMap key must be an object")
+	}
+	return getWeakMap(object), args, nil
+}
+
+// -------------------------------------- WeakMap -------------------------------------- MARK: WeakMap
+
+func registerWeakMap(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.weakmap.php
+	runtime.NewNativeClass(interpreter, "WeakMap").
+		Final().
+		Implements("ArrayAccess", "Countable", "IteratorAggregate").
+		AddMethod("count", []ast.FunctionParameter{}, intType, weakMapCount).
+		AddMethod("getIterator", []ast.FunctionParameter{}, []string{"Iterator"}, weakMapGetIterator).
+		AddMethod("offsetExists", []ast.FunctionParameter{runtime.NewParam("$object", "object")}, boolType, weakMapOffsetExists).
+		AddMethod("offsetGet", []ast.FunctionParameter{runtime.NewParam("$object", "object")}, mixedType, weakMapOffsetGet).
+		AddMethod("offsetSet", []ast.FunctionParameter{runtime.NewParam("$object", "object"), runtime.NewParam("$value", "mixed")}, voidType, weakMapOffsetSet).
+		AddMethod("offsetUnset", []ast.FunctionParameter{runtime.NewParam("$object", "object")}, voidType, weakMapOffsetUnset).
+		Register()
+}
+
+func weakMapCount(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("WeakMap::count").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	weakMap := getWeakMap(object)
+	return values.NewInt(int64(len(weakMap.objects))), nil
+}
+
+func weakMapGetIterator(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("WeakMap::getIterator").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	weakMap := getWeakMap(object)
+	return newInternalIterator(context.Interpreter, &internalIterator{
+		count:   func() int { return len(weakMap.objects) },
+		key:     func(index int) values.RuntimeValue { return weakMap.objects[index] },
+		current: func(index int) values.RuntimeValue { return weakMap.data[weakMap.objects[index]] },
+	})
+}
+
+func weakMapOffsetExists(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	weakMap, args, err := getWeakMapKey(object, args,
+		funcParamValidator.NewValidator("WeakMap::offsetExists").AddParam("$object", mixedType, nil),
+	)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	value, found := weakMap.data[args[0].(*values.Object)]
+	return values.NewBool(found && value.GetType() != values.NullValue), nil
+}
+
+func weakMapOffsetGet(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	weakMap, args, err := getWeakMapKey(object, args,
+		funcParamValidator.NewValidator("WeakMap::offsetGet").AddParam("$object", mixedType, nil),
+	)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	key := args[0].(*values.Object)
+	value, found := weakMap.data[key]
+	if !found {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught Error: Object %s#%d not contained in WeakMap",
+			key.Class.GetQualifiedName(), context.Interpreter.GetExectionContext().GetObjectId(key),
+		)
+	}
+	return value, nil
+}
+
+func weakMapOffsetSet(object *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	weakMap, args, err := getWeakMapKey(object, args,
+		funcParamValidator.NewValidator("WeakMap::offsetSet").AddParam("$object", mixedType, nil).AddParam("$value", mixedType, nil),
+	)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	key := args[0].(*values.Object)
+	value, found := weakMap.data[key]
+	if !found {
+		weakMap.objects = append(weakMap.objects, key)
+		context.Interpreter.GetExectionContext().AddWeakHolder(key, object)
+	}
+	values.Store(&value, args[1])
+	weakMap.data[key] = value
+	return values.NewVoid(), nil
+}
+
+func weakMapOffsetUnset(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	weakMap, args, err := getWeakMapKey(object, args,
+		funcParamValidator.NewValidator("WeakMap::offsetUnset").AddParam("$object", mixedType, nil),
+	)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	weakMap.RemoveWeakReference(args[0].(*values.Object))
+	return values.NewVoid(), nil
+}
@@ -0,0 +1,78 @@
+package spl
+
+import (
+	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+)
+
+// Internal state of WeakReference.
+// The referenced object is removed as soon as it is freed.
+type weakReference struct {
+	object *values.Object
+}
+
+func (reference *weakReference) RemoveWeakReference(_ *values.Object) { reference.object = nil }
+
+func getWeakReference(object *values.Object) (*weakReference, phpError.Error) {
+	reference, ok := object.Internal.(*weakReference)
+	if !ok {
+		return nil, phpError.NewError("Uncaught Error: The WeakReference object has not been properly initialized")
+	}
+	return reference, nil
+}
+
+// -------------------------------------- WeakReference -------------------------------------- MARK: WeakReference
+
+func registerWeakReference(interpreter runtime.Interpreter) {
+	// Spec: https://www.php.net/manual/en/class.weakreference.php
+	runtime.NewNativeClass(interpreter, "WeakReference").
+		Final().
+		AddMethod("__construct", []ast.FunctionParameter{}, nil, weakReferenceConstruct).
+		AddStaticMethod("create", []ast.FunctionParameter{runtime.NewParam("$object", "object")}, []string{"WeakReference"}, weakReferenceCreate).
+		AddMethod("get", []ast.FunctionParameter{}, []string{"object", "null"}, weakReferenceGet).
+		Register()
+}
+
+func weakReferenceConstruct(_ *values.Object, _ []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	return values.NewVoid(), phpError.NewError("Uncaught Error: Cannot directly construct WeakReference, use WeakReference::create() instead")
+}
+
+func weakReferenceCreate(_ *values.Object, args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator("WeakReference::create").AddParam("$object", []string{"object"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// There is only one WeakReference per object as long as the object exists
+	object := args[0].(*values.Object)
+	executionContext := context.Interpreter.GetExectionContext()
+	if existing, found := executionContext.GetWeakReference(object); found {
+		return existing, nil
+	}
+
+	reference, err := newObject(context.Interpreter, "WeakReference")
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	reference.Internal = &weakReference{object: object}
+	executionContext.SetWeakReference(object, reference)
+	return reference, nil
+}
+
+func weakReferenceGet(object *values.Object, args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	if _, err := funcParamValidator.NewValidator("WeakReference::get").Validate(args); err != nil {
+		return values.NewVoid(), err
+	}
+
+	reference, err := getWeakReference(object)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	if reference.object != nil {
+		return reference.object, nil
+	}
+	return values.NewNull(), nil
+}
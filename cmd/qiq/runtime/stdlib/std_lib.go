@@ -3,32 +3,53 @@ package stdlib
 import (
 	"QIQ/cmd/qiq/runtime"
 	"QIQ/cmd/qiq/runtime/stdlib/array"
+	"QIQ/cmd/qiq/runtime/stdlib/bcmath"
 	"QIQ/cmd/qiq/runtime/stdlib/classes"
 	"QIQ/cmd/qiq/runtime/stdlib/dateTime"
 	"QIQ/cmd/qiq/runtime/stdlib/directory"
 	"QIQ/cmd/qiq/runtime/stdlib/errorHandling"
 	"QIQ/cmd/qiq/runtime/stdlib/filesystem"
 	"QIQ/cmd/qiq/runtime/stdlib/functionHandling"
+	"QIQ/cmd/qiq/runtime/stdlib/json"
 	"QIQ/cmd/qiq/runtime/stdlib/math"
 	"QIQ/cmd/qiq/runtime/stdlib/misc"
 	"QIQ/cmd/qiq/runtime/stdlib/optionsInfo"
 	"QIQ/cmd/qiq/runtime/stdlib/outputControl"
+	"QIQ/cmd/qiq/runtime/stdlib/pcre"
+	"QIQ/cmd/qiq/runtime/stdlib/random"
+	"QIQ/cmd/qiq/runtime/stdlib/spl"
 	"QIQ/cmd/qiq/runtime/stdlib/strings"
+	"QIQ/cmd/qiq/runtime/stdlib/url"
 	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
 )
 
 func Register(environment runtime.Environment) {
 	array.Register(environment)
+	bcmath.Register(environment)
 	classes.Register(environment)
 	dateTime.Register(environment)
 	directory.Register(environment)
 	errorHandling.Register(environment)
 	filesystem.Register(environment)
 	functionHandling.Register(environment)
+	json.Register(environment)
 	math.Register(environment)
 	misc.Register(environment)
 	optionsInfo.Register(environment)
 	outputControl.Register(environment)
+	pcre.Register(environment)
+	random.Register(environment)
+	spl.Register(environment)
 	strings.Register(environment)
+	url.Register(environment)
 	variableHandling.Register(environment)
 }
+
+func RegisterClasses(interpreter runtime.Interpreter) {
+	bcmath.RegisterClasses(interpreter)
+	dateTime.RegisterClasses(interpreter)
+	directory.RegisterClasses(interpreter)
+	math.RegisterClasses(interpreter)
+	random.RegisterClasses(interpreter)
+	spl.RegisterClasses(interpreter)
+}
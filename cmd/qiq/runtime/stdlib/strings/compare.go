@@ -0,0 +1,534 @@
+package strings
+
+import (
+	"QIQ/cmd/qiq/common"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+	goStrings "strings"
+)
+
+// Convert the bytes "A" to "Z" to lowercase. All other bytes are left unchanged.
+func asciiToLower(str string) string {
+	return goStrings.Map(func(char rune) rune {
+		if char >= 'A' && char <= 'Z' {
+			return char + 32
+		}
+		return char
+	}, str)
+}
+
+// Compare two strings byte by byte and return -1, 0 or 1
+func compareStrings(lhs string, rhs string) int {
+	return goStrings.Compare(lhs, rhs)
+}
+
+// Validate the two string arguments of a comparison function
+func validateStringPair(functionName string, args []values.RuntimeValue) (string, string, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$string1", []string{"string"}, nil).
+		AddParam("$string2", []string{"string"}, nil).
+		Validate(args)
+	if err != nil {
+		return "", "", err
+	}
+	return args[0].(*values.Str).Value, args[1].(*values.Str).Value, nil
+}
+
+// Validate the arguments of strncmp and strncasecmp and cut the strings to the given length
+func validateStringPairWithLength(functionName string, args []values.RuntimeValue) (string, string, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$string1", []string{"string"}, nil).
+		AddParam("$string2", []string{"string"}, nil).
+		AddParam("$length", []string{"int"}, nil).
+		Validate(args)
+	if err != nil {
+		return "", "", err
+	}
+	length := args[2].(*values.Int).Value
+	if length < 0 {
+		return "", "", phpError.NewError("Uncaught ValueError: %s(): Argument #3 ($length) must be greater than or equal to 0", functionName)
+	}
+	string1 := args[0].(*values.Str).Value
+	string2 := args[1].(*values.Str).Value
+	return string1[:min(len(string1), int(length))], string2[:min(len(string2), int(length))], nil
+}
+
+// -------------------------------------- levenshtein -------------------------------------- MARK: levenshtein
+
+func nativeFn_levenshtein(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.levenshtein.php
+	args, err := funcParamValidator.NewValidator("levenshtein").
+		AddParam("$string1", []string{"string"}, nil).
+		AddParam("$string2", []string{"string"}, nil).
+		AddParam("$insertion_cost", []string{"int"}, values.NewInt(1)).
+		AddParam("$replacement_cost", []string{"int"}, values.NewInt(1)).
+		AddParam("$deletion_cost", []string{"int"}, values.NewInt(1)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	string1 := args[0].(*values.Str).Value
+	string2 := args[1].(*values.Str).Value
+	insertionCost := args[2].(*values.Int).Value
+	replacementCost := args[3].(*values.Int).Value
+	deletionCost := args[4].(*values.Int).Value
+
+	if len(string1) == 0 {
+		return values.NewInt(int64(len(string2)) * insertionCost), nil
+	}
+	if len(string2) == 0 {
+		return values.NewInt(int64(len(string1)) * deletionCost), nil
+	}
+
+	previousRow := make([]int64, len(string2)+1)
+	currentRow := make([]int64, len(string2)+1)
+	for i := range previousRow {
+		previousRow[i] = int64(i) * insertionCost
+	}
+	for i := 0; i < len(string1); i++ {
+		currentRow[0] = previousRow[0] + deletionCost
+		for j := 0; j < len(string2); j++ {
+			cost := previousRow[j]
+			if string1[i] != string2[j] {
+				cost += replacementCost
+			}
+			cost = min(cost, previousRow[j+1]+deletionCost, currentRow[j]+insertionCost)
+			currentRow[j+1] = cost
+		}
+		previousRow, currentRow = currentRow, previousRow
+	}
+
+	return values.NewInt(previousRow[len(string2)]), nil
+}
+
+// -------------------------------------- metaphone -------------------------------------- MARK: metaphone
+
+func nativeFn_metaphone(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.metaphone.php
+	args, err := funcParamValidator.NewValidator("metaphone").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$max_phonemes", []string{"int"}, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	maxPhonemes := args[1].(*values.Int).Value
+	if maxPhonemes < 0 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: metaphone(): Argument #2 ($max_phonemes) must be greater than or equal to 0")
+	}
+
+	return values.NewStr(metaphone(args[0].(*values.Str).Value, int(maxPhonemes))), nil
+}
+
+// Flags of the letters used by the metaphone algorithm
+const (
+	metaphoneVowel    = 1  // AEIOU
+	metaphoneNoChange = 2  // FJLMNR - passed through unchanged
+	metaphoneAffectH  = 4  // CGPST - form diphthongs when preceded by H
+	metaphoneMakeSoft = 8  // EIY - make C and G soft
+	metaphoneNoGhToF  = 16 // BDH - prevent GH from becoming F
+)
+
+var metaphoneCodes = [26]int{
+	// A  B   C  D   E  F  G  H   I  J  K  L  M  N  O  P  Q  R  S  T  U  V  W  X  Y  Z
+	1, 16, 4, 16, 9, 2, 4, 16, 9, 2, 0, 2, 2, 2, 1, 4, 0, 2, 4, 4, 1, 0, 0, 0, 8, 0,
+}
+
+// Port of the metaphone algorithm as implemented by PHP (ext/standard/metaphone.c)
+func metaphone(word string, maxPhonemes int) string {
+	isAlpha := func(char byte) bool { return (char >= 'A' && char <= 'Z') || (char >= 'a' && char <= 'z') }
+	letterAt := func(index int) byte {
+		if index < 0 || index >= len(word) {
+			return 0
+		}
+		return common.ToUpper(word[index])
+	}
+	hasFlag := func(char byte, flag int) bool {
+		return isAlpha(char) && metaphoneCodes[common.ToUpper(char)-'A']&flag != 0
+	}
+
+	var result goStrings.Builder
+	pos := 0
+
+	// Skip leading non-alpha characters
+	for pos < len(word) && !isAlpha(word[pos]) {
+		pos++
+	}
+	if pos >= len(word) {
+		return ""
+	}
+
+	// Handle the prefixes
+	switch letterAt(pos) {
+	case 'A':
+		// AE becomes E
+		if letterAt(pos+1) == 'E' {
+			result.WriteByte('E')
+			pos += 2
+		} else {
+			// Vowels at the beginning are preserved
+			result.WriteByte('A')
+			pos++
+		}
+	case 'G', 'K', 'P':
+		// [GKP]N becomes N
+		if letterAt(pos+1) == 'N' {
+			result.WriteByte('N')
+			pos += 2
+		}
+	case 'W':
+		// WR becomes R, WH or W followed by a vowel becomes W
+		if letterAt(pos+1) == 'R' {
+			result.WriteByte('R')
+			pos += 2
+		} else if letterAt(pos+1) == 'H' || hasFlag(letterAt(pos+1), metaphoneVowel) {
+			result.WriteByte('W')
+			pos += 2
+		}
+	case 'X':
+		// X becomes S
+		result.WriteByte('S')
+		pos++
+	case 'E', 'I', 'O', 'U':
+		// Vowels at the beginning are preserved
+		result.WriteByte(letterAt(pos))
+		pos++
+	}
+
+	for ; pos < len(word) && (maxPhonemes == 0 || result.Len() < maxPhonemes); pos++ {
+		current := letterAt(pos)
+		if !isAlpha(current) {
+			continue
+		}
+		previous := letterAt(pos - 1)
+		next := letterAt(pos + 1)
+		afterNext := byte(0)
+		if next != 0 {
+			afterNext = letterAt(pos + 2)
+		}
+
+		// Drop duplicates, except CC
+		if current == previous && current != 'C' {
+			continue
+		}
+
+		skipLetters := 0
+		switch current {
+		case 'B':
+			// B unless in MB
+			if previous != 'M' {
+				result.WriteByte('B')
+			}
+		case 'C':
+			if hasFlag(next, metaphoneMakeSoft) {
+				if next == 'I' && afterNext == 'A' {
+					// CIA
+					result.WriteByte('X')
+				} else if previous != 'S' {
+					// SC[IEY] is dropped
+					result.WriteByte('S')
+				}
+			} else if next == 'H' {
+				result.WriteByte('X')
+				skipLetters++
+			} else {
+				result.WriteByte('K')
+			}
+		case 'D':
+			// J if in -DGE-, -DGI- or -DGY-, else T
+			if next == 'G' && hasFlag(afterNext, metaphoneMakeSoft) {
+				result.WriteByte('J')
+				skipLetters++
+			} else {
+				result.WriteByte('T')
+			}
+		case 'G':
+			if next == 'H' {
+				// F if in -GH and not B--GH, D--GH, -H--GH, -H---GH, else silent
+				if !(hasFlag(letterAt(pos-3), metaphoneNoGhToF) || letterAt(pos-4) == 'H') {
+					result.WriteByte('F')
+					skipLetters++
+				}
+			} else if next == 'N' {
+				// Dropped if -GNED or -GN
+				if !(!isAlpha(afterNext) || (afterNext == 'E' && letterAt(pos+3) == 'D')) {
+					result.WriteByte('K')
+				}
+			} else if hasFlag(next, metaphoneMakeSoft) && previous != 'G' {
+				result.WriteByte('J')
+			} else {
+				result.WriteByte('K')
+			}
+		case 'H':
+			// H if before a vowel and not after C, G, P, S or T
+			if hasFlag(next, metaphoneVowel) && !hasFlag(previous, metaphoneAffectH) {
+				result.WriteByte('H')
+			}
+		case 'K':
+			// Dropped if after C
+			if previous != 'C' {
+				result.WriteByte('K')
+			}
+		case 'P':
+			// F if before H, else P
+			if next == 'H' {
+				result.WriteByte('F')
+			} else {
+				result.WriteByte('P')
+			}
+		case 'Q':
+			result.WriteByte('K')
+		case 'S':
+			// SH in -SH-, -SIO- or -SIA-
+			if next == 'I' && (afterNext == 'O' || afterNext == 'A') {
+				result.WriteByte('X')
+			} else if next == 'H' {
+				result.WriteByte('X')
+				skipLetters++
+			} else {
+				result.WriteByte('S')
+			}
+		case 'T':
+			// SH in -TIA- or -TIO-, TH before H, TCH is silent
+			if next == 'I' && (afterNext == 'O' || afterNext == 'A') {
+				result.WriteByte('X')
+			} else if next == 'H' {
+				result.WriteByte('0')
+				skipLetters++
+			} else if !(next == 'C' && afterNext == 'H') {
+				result.WriteByte('T')
+			}
+		case 'V':
+			result.WriteByte('F')
+		case 'W':
+			// W before a vowel, else dropped
+			if hasFlag(next, metaphoneVowel) {
+				result.WriteByte('W')
+			}
+		case 'X':
+			result.WriteString("KS")
+		case 'Y':
+			// Y if followed by a vowel
+			if hasFlag(next, metaphoneVowel) {
+				result.WriteByte('Y')
+			}
+		case 'Z':
+			result.WriteByte('S')
+		default:
+			if hasFlag(current, metaphoneNoChange) {
+				result.WriteByte(current)
+			}
+		}
+
+		pos += skipLetters
+	}
+
+	return result.String()
+}
+
+// -------------------------------------- similar_text -------------------------------------- MARK: similar_text
+
+func nativeFn_similar_text(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.similar-text.php
+	args, err := funcParamValidator.NewValidator("similar_text").
+		AddParam("$string1", []string{"string"}, nil).
+		AddParam("$string2", []string{"string"}, nil).
+		AddParam("$percent", []string{"mixed"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	string1 := args[0].(*values.Str).Value
+	string2 := args[1].(*values.Str).Value
+
+	similarity := similarText(string1, string2)
+	percent := 0.0
+	if len(string1)+len(string2) > 0 {
+		percent = float64(similarity) * 2 * 100 / float64(len(string1)+len(string2))
+	}
+	context.SetRefArg(2, values.NewFloat(percent))
+
+	return values.NewInt(int64(similarity)), nil
+}
+
+// Get the number of matching chars: The first longest common substring plus
+// the matching chars left and right of it (recursively).
+func similarText(string1 string, string2 string) int {
+	pos1, pos2, maxLength := 0, 0, 0
+	for i := 0; i < len(string1); i++ {
+		for j := 0; j < len(string2); j++ {
+			length := 0
+			for i+length < len(string1) && j+length < len(string2) && string1[i+length] == string2[j+length] {
+				length++
+			}
+			if length > maxLength {
+				pos1, pos2, maxLength = i, j, length
+			}
+		}
+	}
+	if maxLength == 0 {
+		return 0
+	}
+	return maxLength +
+		similarText(string1[:pos1], string2[:pos2]) +
+		similarText(string1[pos1+maxLength:], string2[pos2+maxLength:])
+}
+
+// -------------------------------------- soundex -------------------------------------- MARK: soundex
+
+func nativeFn_soundex(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.soundex.php
+	args, err := funcParamValidator.NewValidator("soundex").AddParam("$string", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := args[0].(*values.Str).Value
+	if input == "" {
+		return values.NewStr(""), nil
+	}
+
+	// Soundex codes of the letters A to Z. Vowels, H, W and Y have no code.
+	const soundexCodes = "01230120022455012623010202"
+
+	result := []byte{}
+	var last byte
+	for i := 0; i < len(input) && len(result) < 4; i++ {
+		char := common.ToUpper(input[i])
+		if char < 'A' || char > 'Z' {
+			continue
+		}
+		code := soundexCodes[char-'A']
+		if len(result) == 0 {
+			// Keep the first letter
+			result = append(result, char)
+			last = code
+			continue
+		}
+		// Ignore sequences of consonants with the same code
+		if code != last {
+			if code != '0' {
+				result = append(result, code)
+			}
+			last = code
+		}
+	}
+	for len(result) < 4 {
+		result = append(result, '0')
+	}
+
+	return values.NewStr(string(result)), nil
+}
+
+// -------------------------------------- strcasecmp -------------------------------------- MARK: strcasecmp
+
+func nativeFn_strcasecmp(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strcasecmp.php
+	string1, string2, err := validateStringPair("strcasecmp", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(int64(compareStrings(asciiToLower(string1), asciiToLower(string2)))), nil
+}
+
+// -------------------------------------- strcmp -------------------------------------- MARK: strcmp
+
+func nativeFn_strcmp(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strcmp.php
+	string1, string2, err := validateStringPair("strcmp", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(int64(compareStrings(string1, string2))), nil
+}
+
+// -------------------------------------- strnatcasecmp -------------------------------------- MARK: strnatcasecmp
+
+func nativeFn_strnatcasecmp(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strnatcasecmp.php
+	string1, string2, err := validateStringPair("strnatcasecmp", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(int64(common.NaturalCompare(string1, string2, true))), nil
+}
+
+// -------------------------------------- strnatcmp -------------------------------------- MARK: strnatcmp
+
+func nativeFn_strnatcmp(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strnatcmp.php
+	string1, string2, err := validateStringPair("strnatcmp", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(int64(common.NaturalCompare(string1, string2, false))), nil
+}
+
+// -------------------------------------- strncasecmp -------------------------------------- MARK: strncasecmp
+
+func nativeFn_strncasecmp(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strncasecmp.php
+	string1, string2, err := validateStringPairWithLength("strncasecmp", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(int64(compareStrings(asciiToLower(string1), asciiToLower(string2)))), nil
+}
+
+// -------------------------------------- strncmp -------------------------------------- MARK: strncmp
+
+func nativeFn_strncmp(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strncmp.php
+	string1, string2, err := validateStringPairWithLength("strncmp", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewInt(int64(compareStrings(string1, string2))), nil
+}
+
+// -------------------------------------- substr_compare -------------------------------------- MARK: substr_compare
+
+func nativeFn_substr_compare(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.substr-compare.php
+	args, err := funcParamValidator.NewValidator("substr_compare").
+		AddParam("$haystack", []string{"string"}, nil).
+		AddParam("$needle", []string{"string"}, nil).
+		AddParam("$offset", []string{"int"}, nil).
+		AddParam("$length", []string{"int", "null"}, values.NewNull()).
+		AddParam("$case_insensitive", []string{"bool"}, values.NewBool(false)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	haystack := args[0].(*values.Str).Value
+	needle := args[1].(*values.Str).Value
+	offset := int(args[2].(*values.Int).Value)
+
+	if offset < 0 {
+		offset = max(len(haystack)+offset, 0)
+	}
+	if offset > len(haystack) {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: substr_compare(): Argument #3 ($offset) must be contained in argument #1 ($haystack)")
+	}
+
+	haystack = haystack[offset:]
+	if args[3].GetType() == values.IntValue {
+		length := int(args[3].(*values.Int).Value)
+		if length < 0 {
+			return values.NewVoid(), phpError.NewError("Uncaught ValueError: substr_compare(): Argument #4 ($length) must be greater than or equal to 0")
+		}
+		haystack = haystack[:min(len(haystack), length)]
+		needle = needle[:min(len(needle), length)]
+	}
+
+	if args[4].(*values.Bool).Value {
+		haystack, needle = asciiToLower(haystack), asciiToLower(needle)
+	}
+	return values.NewInt(int64(compareStrings(haystack, needle))), nil
+}
@@ -0,0 +1,610 @@
+package strings
+
+import (
+	"QIQ/cmd/qiq/common"
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/filesystem"
+	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/stream"
+	"QIQ/cmd/qiq/runtime/values"
+	"math"
+	"strconv"
+	goStrings "strings"
+)
+
+// Default precision of the float conversions if no precision is given
+const defaultFloatPrecision = 6
+
+// Maximum precision of the float conversions
+const maxFloatPrecision = 53
+
+// Specification of a single conversion (e.g. "%'*-10.2f")
+type formatSpec struct {
+	padding    byte
+	alignLeft  bool
+	alwaysSign bool
+	width      int
+	precision  int
+	// Precision was given explicitly
+	hasPrecision bool
+}
+
+// Format the arguments according to the format string as done by sprintf.
+// argOffset is the number of arguments preceding the values (e.g. 1 for the format of sprintf)
+// or -1 if the values are passed as array (e.g. vsprintf).
+func FormatString(functionName string, format string, args []values.RuntimeValue, argOffset int, context runtime.Context) (string, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.sprintf.php
+	var result goStrings.Builder
+	currentArg := 0
+	maxMissingArg := -1
+
+	// Read a number (e.g. width, precision or argnum)
+	readNumber := func(pos int) (int, int, bool) {
+		start := pos
+		for pos < len(format) && format[pos] >= '0' && format[pos] <= '9' {
+			pos++
+		}
+		number, err := strconv.Atoi(format[start:pos])
+		if err != nil || number >= math.MaxInt32 {
+			return 0, pos, false
+		}
+		return number, pos, true
+	}
+
+	// Read an argnum (e.g. "1$") and return the index of the argument or -1 if no argnum is given
+	readArgnum := func(pos int) (int, int, phpError.Error) {
+		end := pos
+		for end < len(format) && format[end] >= '0' && format[end] <= '9' {
+			end++
+		}
+		if end >= len(format) || format[end] != '$' {
+			return -1, pos, nil
+		}
+		argnum, pos, ok := readNumber(pos)
+		if !ok || argnum <= 0 {
+			return 0, pos, phpError.NewError("Uncaught ValueError: Argument number specifier must be greater than zero and less than %d", math.MaxInt32)
+		}
+		// Skip the "$"
+		return argnum - 1, pos + 1, nil
+	}
+
+	// Read a width or precision given as argument (e.g. "*" or "*1$")
+	readArgValue := func(pos int, name string, minValue int) (int, int, bool, phpError.Error) {
+		argIndex, pos, err := readArgnum(pos)
+		if err != nil {
+			return 0, pos, false, err
+		}
+		if argIndex == -1 {
+			argIndex = currentArg
+			currentArg++
+		}
+		if argIndex >= len(args) {
+			maxMissingArg = max(maxMissingArg, argIndex)
+			return 0, pos, false, nil
+		}
+		if args[argIndex].GetType() != values.IntValue {
+			return 0, pos, false, phpError.NewError("Uncaught ValueError: %s must be an integer", name)
+		}
+		value := args[argIndex].(*values.Int).Value
+		if value < int64(minValue) || value > math.MaxInt32 {
+			if minValue == 0 {
+				return 0, pos, false, phpError.NewError("Uncaught ValueError: %s must be greater than zero and less than %d", name, math.MaxInt32)
+			}
+			return 0, pos, false, phpError.NewError("Uncaught ValueError: %s must be between %d and %d", name, minValue, math.MaxInt32)
+		}
+		return int(value), pos, true, nil
+	}
+
+	pos := 0
+	for pos < len(format) {
+		if format[pos] != '%' {
+			result.WriteByte(format[pos])
+			pos++
+			continue
+		}
+		if pos+1 < len(format) && format[pos+1] == '%' {
+			result.WriteByte('%')
+			pos += 2
+			continue
+		}
+		pos++
+
+		// Argnum
+		argIndex, newPos, err := readArgnum(pos)
+		if err != nil {
+			return "", err
+		}
+		pos = newPos
+
+		// Flags
+		spec := formatSpec{padding: ' '}
+	flags:
+		for pos < len(format) {
+			switch format[pos] {
+			case ' ', '0':
+				spec.padding = format[pos]
+			case '-':
+				spec.alignLeft = true
+			case '+':
+				spec.alwaysSign = true
+			case '\'':
+				if pos+1 >= len(format) {
+					return "", phpError.NewError("Uncaught ValueError: Missing padding character")
+				}
+				pos++
+				spec.padding = format[pos]
+			default:
+				break flags
+			}
+			pos++
+		}
+
+		// Width
+		argsMissing := false
+		if pos < len(format) && format[pos] == '*' {
+			width, newPos, ok, err := readArgValue(pos+1, "Width", 0)
+			if err != nil {
+				return "", err
+			}
+			pos = newPos
+			spec.width = width
+			argsMissing = !ok
+		} else if pos < len(format) && format[pos] >= '0' && format[pos] <= '9' {
+			width, newPos, ok := readNumber(pos)
+			if !ok {
+				return "", phpError.NewError("Uncaught ValueError: Width must be greater than zero and less than %d", math.MaxInt32)
+			}
+			pos = newPos
+			spec.width = width
+		}
+
+		// Precision
+		if pos < len(format) && format[pos] == '.' {
+			pos++
+			spec.hasPrecision = true
+			if pos < len(format) && format[pos] == '*' {
+				precision, newPos, ok, err := readArgValue(pos+1, "Precision", -1)
+				if err != nil {
+					return "", err
+				}
+				pos = newPos
+				spec.precision = precision
+				argsMissing = argsMissing || !ok
+			} else if pos < len(format) && format[pos] >= '0' && format[pos] <= '9' {
+				precision, newPos, ok := readNumber(pos)
+				if !ok {
+					return "", phpError.NewError("Uncaught ValueError: Precision must be greater than zero and less than %d", math.MaxInt32)
+				}
+				pos = newPos
+				spec.precision = precision
+			}
+		}
+
+		// Length modifier (ignored)
+		if pos < len(format) && format[pos] == 'l' {
+			pos++
+		}
+
+		if pos >= len(format) {
+			return "", phpError.NewError("Uncaught ValueError: Missing format specifier at end of string")
+		}
+		specifier := format[pos]
+		pos++
+
+		// The value is read after the width and precision given as argument
+		if argIndex == -1 {
+			argIndex = currentArg
+			currentArg++
+		}
+		if argsMissing {
+			continue
+		}
+		if argIndex >= len(args) {
+			maxMissingArg = max(maxMissingArg, argIndex)
+			continue
+		}
+
+		str, err := formatArg(functionName, specifier, spec, args[argIndex], context)
+		if err != nil {
+			return "", err
+		}
+		result.WriteString(str)
+	}
+
+	if maxMissingArg >= 0 {
+		if argOffset == -1 {
+			return "", phpError.NewError("Uncaught ValueError: The arguments array must contain %d items, %d given", maxMissingArg+1, len(args))
+		}
+		return "", phpError.NewError("Uncaught ArgumentCountError: %d arguments are required, %d given", maxMissingArg+argOffset+1, len(args)+argOffset)
+	}
+
+	return result.String(), nil
+}
+
+// Convert a single argument according to the conversion specifier
+func formatArg(functionName string, specifier byte, spec formatSpec, arg values.RuntimeValue, context runtime.Context) (string, phpError.Error) {
+	switch specifier {
+	case 's':
+		var str string
+		if arg.GetType() == values.FloatValue {
+			str = common.FormatFloatWithPrecision(arg.(*values.Float).Value, int(context.Interpreter.GetIni().GetInt("precision")), 'E')
+		} else {
+			var err phpError.Error
+			str, err = variableHandling.StrVal(arg)
+			if err != nil {
+				return "", err
+			}
+		}
+		if spec.hasPrecision && spec.precision < len(str) {
+			str = str[:spec.precision]
+		}
+		return padString(str, spec, false), nil
+
+	case 'd':
+		number, err := variableHandling.IntVal(arg, true)
+		if err != nil {
+			return "", err
+		}
+		str := strconv.FormatInt(number, 10)
+		if spec.alwaysSign && number >= 0 {
+			str = "+" + str
+		}
+		return padInteger(str, spec, number < 0 || spec.alwaysSign), nil
+
+	case 'u':
+		number, err := variableHandling.IntVal(arg, true)
+		if err != nil {
+			return "", err
+		}
+		spec.alwaysSign = false
+		return padInteger(strconv.FormatUint(uint64(number), 10), spec, false), nil
+
+	case 'b', 'o', 'x', 'X':
+		number, err := variableHandling.IntVal(arg, true)
+		if err != nil {
+			return "", err
+		}
+		base := map[byte]int{'b': 2, 'o': 8, 'x': 16, 'X': 16}[specifier]
+		str := strconv.FormatUint(uint64(number), base)
+		if specifier == 'X' {
+			str = goStrings.ToUpper(str)
+		}
+		spec.alwaysSign = false
+		return padString(str, spec, false), nil
+
+	case 'c':
+		number, err := variableHandling.IntVal(arg, true)
+		if err != nil {
+			return "", err
+		}
+		// The width and the padding are ignored
+		return string([]byte{byte(number)}), nil
+
+	case 'e', 'E', 'f', 'F', 'g', 'G', 'h', 'H':
+		number, err := variableHandling.FloatVal(arg, true)
+		if err != nil {
+			return "", err
+		}
+		return formatFloat(functionName, specifier, spec, number, context), nil
+
+	default:
+		return "", phpError.NewError("Uncaught ValueError: Unknown format specifier \"%c\"", specifier)
+	}
+}
+
+// Format a float for the conversion specifiers e, E, f, F, g, G, h and H
+func formatFloat(functionName string, specifier byte, spec formatSpec, number float64, context runtime.Context) string {
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		// The width is ignored for NaN and Inf
+		str := "NaN"
+		if math.IsInf(number, -1) {
+			str = "-Inf"
+		} else if math.IsInf(number, 1) {
+			str = "Inf"
+			if spec.alwaysSign {
+				str = "+Inf"
+			}
+		}
+		return str
+	}
+
+	precision := spec.precision
+	if !spec.hasPrecision {
+		precision = defaultFloatPrecision
+	} else if precision > maxFloatPrecision {
+		context.Interpreter.PrintError(phpError.NewNotice(
+			"%s(): Requested precision of %d digits was truncated to PHP maximum of %d digits%s",
+			functionName, precision, maxFloatPrecision, context.InPosition(),
+		))
+		precision = maxFloatPrecision
+	}
+
+	isNegative := math.Signbit(number)
+	absNumber := math.Abs(number)
+	var str string
+	switch specifier {
+	case 'e', 'E':
+		mantissa, exponent, _ := goStrings.Cut(strconv.FormatFloat(absNumber, 'e', precision, 64), "e")
+		exponentValue, _ := strconv.Atoi(exponent)
+		exponentSign := "+"
+		if exponentValue < 0 {
+			exponentSign = "-"
+			exponentValue = -exponentValue
+		}
+		str = mantissa + string(specifier) + exponentSign + strconv.Itoa(exponentValue)
+	case 'f', 'F':
+		str = strconv.FormatFloat(roundHalfUp(absNumber, precision), 'f', precision, 64)
+	case 'g', 'G', 'h', 'H':
+		exponentChar := byte('e')
+		if specifier == 'G' || specifier == 'H' {
+			exponentChar = 'E'
+		}
+		str = common.FormatFloatWithPrecision(absNumber, max(precision, 1), exponentChar)
+		isNegative = number < 0
+	}
+
+	if isNegative {
+		str = "-" + str
+	} else if spec.alwaysSign {
+		str = "+" + str
+	}
+	return padString(str, spec, isNegative || spec.alwaysSign)
+}
+
+// Pad an integer. Integers can not be padded with zeros on the right side.
+func padInteger(str string, spec formatSpec, hasSign bool) string {
+	if spec.alignLeft && spec.padding == '0' {
+		spec.padding = ' '
+	}
+	return padString(str, spec, hasSign)
+}
+
+// Pad the string to the width of the specification.
+// If the string is right aligned and padded with zeros, the sign is placed before the padding.
+func padString(str string, spec formatSpec, hasSign bool) string {
+	if len(str) >= spec.width {
+		return str
+	}
+	padding := goStrings.Repeat(string(spec.padding), spec.width-len(str))
+	if spec.alignLeft {
+		return str + padding
+	}
+	if hasSign && spec.padding == '0' {
+		return str[:1] + padding + str[1:]
+	}
+	return padding + str
+}
+
+// -------------------------------------- number_format -------------------------------------- MARK: number_format
+
+func nativeFn_number_format(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.number-format.php
+	args, err := funcParamValidator.NewValidator("number_format").
+		AddParam("$num", []string{"int", "float"}, nil).
+		AddParam("$decimals", []string{"int"}, values.NewInt(0)).
+		AddParam("$decimal_separator", []string{"string", "null"}, values.NewStr(".")).
+		AddParam("$thousands_separator", []string{"string", "null"}, values.NewStr(",")).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	number, err := variableHandling.FloatVal(args[0], false)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	decimals := int(args[1].(*values.Int).Value)
+	decimalSeparator := "."
+	if args[2].GetType() == values.StrValue {
+		decimalSeparator = args[2].(*values.Str).Value
+	}
+	thousandsSeparator := ","
+	if args[3].GetType() == values.StrValue {
+		thousandsSeparator = args[3].(*values.Str).Value
+	}
+
+	return values.NewStr(numberFormat(number, decimals, decimalSeparator, thousandsSeparator)), nil
+}
+
+func numberFormat(number float64, decimals int, decimalSeparator string, thousandsSeparator string) string {
+	number = roundHalfUp(number, decimals)
+	decimals = max(decimals, 0)
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return common.FormatFloatWithPrecision(number, 14, 'E')
+	}
+
+	str := strconv.FormatFloat(math.Abs(number), 'f', decimals, 64)
+	integerPart, fractionPart, _ := goStrings.Cut(str, ".")
+
+	var result goStrings.Builder
+	// The number is no longer negative if it is rounded to zero
+	if number < 0 {
+		result.WriteByte('-')
+	}
+	for i := 0; i < len(integerPart); i++ {
+		if i > 0 && (len(integerPart)-i)%3 == 0 {
+			result.WriteString(thousandsSeparator)
+		}
+		result.WriteByte(integerPart[i])
+	}
+	if decimals > 0 {
+		result.WriteString(decimalSeparator)
+		result.WriteString(fractionPart)
+	}
+	return result.String()
+}
+
+// Round half away from zero to the given number of decimal places (negative places round before the decimal point).
+// The value is pre-rounded to 15 significant digits so that e.g. 1.005 is rounded to 1.01.
+func roundHalfUp(value float64, places int) float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) || value == 0 {
+		return value
+	}
+	factor := math.Pow(10, math.Abs(float64(places)))
+	var scaled float64
+	if places >= 0 {
+		scaled = value * factor
+	} else {
+		scaled = value / factor
+	}
+	if math.IsInf(scaled, 0) {
+		return value
+	}
+	scaled, _ = strconv.ParseFloat(strconv.FormatFloat(scaled, 'g', 15, 64), 64)
+	scaled = math.Round(scaled)
+	if places >= 0 {
+		return scaled / factor
+	}
+	return scaled * factor
+}
+
+// -------------------------------------- fprintf -------------------------------------- MARK: fprintf
+
+func nativeFn_fprintf(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.fprintf.php
+	args, err := funcParamValidator.NewValidator("fprintf").
+		AddParam("$stream", []string{"resource"}, nil).
+		AddParam("$format", []string{"string"}, nil).
+		AddVariableLenParam("$values", []string{"mixed"}).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := filesystem.GetStream("fprintf", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	str, err := FormatString("fprintf", args[1].(*values.Str).Value, arrayToSlice(args[2].(*values.Array)), 2, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return writeFormatted("fprintf", fileStream, str, context), nil
+}
+
+// Write the formatted string to the stream and return its length
+func writeFormatted(functionName string, fileStream *stream.Stream, str string, context runtime.Context) values.RuntimeValue {
+	if str == "" {
+		return values.NewInt(0)
+	}
+	if _, err := fileStream.Write(str); err != nil {
+		filesystem.PrintIoNotice(functionName, "Write", len(str), err, context)
+	}
+	return values.NewInt(int64(len(str)))
+}
+
+// -------------------------------------- printf -------------------------------------- MARK: printf
+
+func nativeFn_printf(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.printf.php
+	args, err := funcParamValidator.NewValidator("printf").
+		AddParam("$format", []string{"string"}, nil).
+		AddVariableLenParam("$values", []string{"mixed"}).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	str, err := FormatString("printf", args[0].(*values.Str).Value, arrayToSlice(args[1].(*values.Array)), 1, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	context.Interpreter.Print(str)
+	return values.NewInt(int64(len(str))), nil
+}
+
+// -------------------------------------- sprintf -------------------------------------- MARK: sprintf
+
+func nativeFn_sprintf(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.sprintf.php
+	args, err := funcParamValidator.NewValidator("sprintf").
+		AddParam("$format", []string{"string"}, nil).
+		AddVariableLenParam("$values", []string{"mixed"}).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	str, err := FormatString("sprintf", args[0].(*values.Str).Value, arrayToSlice(args[1].(*values.Array)), 1, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewStr(str), nil
+}
+
+// -------------------------------------- vfprintf -------------------------------------- MARK: vfprintf
+
+func nativeFn_vfprintf(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.vfprintf.php
+	args, err := funcParamValidator.NewValidator("vfprintf").
+		AddParam("$stream", []string{"resource"}, nil).
+		AddParam("$format", []string{"string"}, nil).
+		AddParam("$values", []string{"array"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	fileStream, err := filesystem.GetStream("vfprintf", args[0])
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	str, err := FormatString("vfprintf", args[1].(*values.Str).Value, arrayToSlice(args[2].(*values.Array)), -1, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return writeFormatted("vfprintf", fileStream, str, context), nil
+}
+
+// -------------------------------------- vprintf -------------------------------------- MARK: vprintf
+
+func nativeFn_vprintf(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.vprintf.php
+	args, err := funcParamValidator.NewValidator("vprintf").
+		AddParam("$format", []string{"string"}, nil).
+		AddParam("$values", []string{"array"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	str, err := FormatString("vprintf", args[0].(*values.Str).Value, arrayToSlice(args[1].(*values.Array)), -1, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	context.Interpreter.Print(str)
+	return values.NewInt(int64(len(str))), nil
+}
+
+// -------------------------------------- vsprintf -------------------------------------- MARK: vsprintf
+
+func nativeFn_vsprintf(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.vsprintf.php
+	args, err := funcParamValidator.NewValidator("vsprintf").
+		AddParam("$format", []string{"string"}, nil).
+		AddParam("$values", []string{"array"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	str, err := FormatString("vsprintf", args[0].(*values.Str).Value, arrayToSlice(args[1].(*values.Array)), -1, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewStr(str), nil
+}
+
+// Get the values of the array in order
+func arrayToSlice(array *values.Array) []values.RuntimeValue {
+	result := make([]values.RuntimeValue, len(array.Keys))
+	for index, key := range array.Keys {
+		slot, _ := array.GetElement(key)
+		result[index] = slot.Value
+	}
+	return result
+}
@@ -0,0 +1,737 @@
+package strings
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+	"slices"
+	"strconv"
+	goStrings "strings"
+	"unicode/utf8"
+)
+
+// Spec: https://www.php.net/manual/en/string.constants.php
+const (
+	HTML_SPECIALCHARS int64 = 0
+	HTML_ENTITIES     int64 = 1
+
+	ENT_HTML_QUOTE_NONE   int64 = 0
+	ENT_HTML_QUOTE_SINGLE int64 = 1
+	ENT_HTML_QUOTE_DOUBLE int64 = 2
+	ENT_COMPAT            int64 = 2
+	ENT_QUOTES            int64 = 3
+	ENT_NOQUOTES          int64 = 0
+	ENT_IGNORE            int64 = 4
+	ENT_SUBSTITUTE        int64 = 8
+	ENT_DISALLOWED        int64 = 128
+	ENT_HTML401           int64 = 0
+	ENT_XML1              int64 = 16
+	ENT_XHTML             int64 = 32
+	ENT_HTML5             int64 = 48
+
+	// Mask of the document type flags
+	entDocTypeMask int64 = 48
+)
+
+const (
+	charsetUtf8   = "UTF-8"
+	charsetLatin1 = "ISO-8859-1"
+)
+
+// Names of the HTML 4.01 character entities
+// Spec: https://www.w3.org/TR/html401/sgml/entities.html
+var html401EntityNames = []string{
+	// Latin-1 characters (U+00A0 to U+00FF)
+	"nbsp", "iexcl", "cent", "pound", "curren", "yen", "brvbar", "sect", "uml", "copy", "ordf", "laquo", "not", "shy", "reg", "macr",
+	"deg", "plusmn", "sup2", "sup3", "acute", "micro", "para", "middot", "cedil", "sup1", "ordm", "raquo", "frac14", "frac12", "frac34", "iquest",
+	"Agrave", "Aacute", "Acirc", "Atilde", "Auml", "Aring", "AElig", "Ccedil", "Egrave", "Eacute", "Ecirc", "Euml", "Igrave", "Iacute", "Icirc", "Iuml",
+	"ETH", "Ntilde", "Ograve", "Oacute", "Ocirc", "Otilde", "Ouml", "times", "Oslash", "Ugrave", "Uacute", "Ucirc", "Uuml", "Yacute", "THORN", "szlig",
+	"agrave", "aacute", "acirc", "atilde", "auml", "aring", "aelig", "ccedil", "egrave", "eacute", "ecirc", "euml", "igrave", "iacute", "icirc", "iuml",
+	"eth", "ntilde", "ograve", "oacute", "ocirc", "otilde", "ouml", "divide", "oslash", "ugrave", "uacute", "ucirc", "uuml", "yacute", "thorn", "yuml",
+	// Special characters
+	"quot", "amp", "lt", "gt", "OElig", "oelig", "Scaron", "scaron", "Yuml", "circ", "tilde", "ensp", "emsp", "thinsp", "zwnj", "zwj", "lrm", "rlm",
+	"ndash", "mdash", "lsquo", "rsquo", "sbquo", "ldquo", "rdquo", "bdquo", "dagger", "Dagger", "permil", "lsaquo", "rsaquo", "euro",
+	// Symbols, mathematical symbols and Greek letters
+	"fnof", "Alpha", "Beta", "Gamma", "Delta", "Epsilon", "Zeta", "Eta", "Theta", "Iota", "Kappa", "Lambda", "Mu", "Nu", "Xi", "Omicron", "Pi",
+	"Rho", "Sigma", "Tau", "Upsilon", "Phi", "Chi", "Psi", "Omega", "alpha", "beta", "gamma", "delta", "epsilon", "zeta", "eta", "theta", "iota",
+	"kappa", "lambda", "mu", "nu", "xi", "omicron", "pi", "rho", "sigmaf", "sigma", "tau", "upsilon", "phi", "chi", "psi", "omega", "thetasym",
+	"upsih", "piv", "bull", "hellip", "prime", "Prime", "oline", "frasl", "weierp", "image", "real", "trade", "alefsym", "larr", "uarr", "rarr",
+	"darr", "harr", "crarr", "lArr", "uArr", "rArr", "dArr", "hArr", "forall", "part", "exist", "empty", "nabla", "isin", "notin", "ni", "prod",
+	"sum", "minus", "lowast", "radic", "prop", "infin", "ang", "and", "or", "cap", "cup", "int", "there4", "sim", "cong", "asymp", "ne", "equiv",
+	"le", "ge", "sub", "sup", "nsub", "sube", "supe", "oplus", "otimes", "perp", "sdot", "lceil", "rceil", "lfloor", "rfloor", "lang", "rang",
+	"loz", "spades", "clubs", "hearts", "diams",
+}
+
+// Entity tables (name without "&" and ";" to character) of the document types
+var (
+	xml1Entities    = map[string]string{"quot": "\"", "amp": "&", "lt": "<", "gt": ">", "apos": "'"}
+	html401Entities = func() map[string]string {
+		entities := map[string]string{}
+		for _, name := range html401EntityNames {
+			entities[name] = html5Entities[name]
+		}
+		// The angle brackets differ between HTML 4.01 and HTML5
+		entities["lang"] = "〈"
+		entities["rang"] = "〉"
+		return entities
+	}()
+	xhtmlEntities = func() map[string]string {
+		entities := map[string]string{"apos": "'"}
+		for name, value := range html401Entities {
+			entities[name] = value
+		}
+		return entities
+	}()
+)
+
+// Inverse entity tables (character to name) used for encoding
+var (
+	html401EntityNamesByChar = func() map[string]string {
+		names := map[string]string{}
+		for name, value := range html401Entities {
+			names[value] = name
+		}
+		return names
+	}()
+	html5EntityNamesByChar = func() map[string]string {
+		names := map[string]string{}
+		for name, value := range html5Entities {
+			if utf8.RuneCountInString(value) != 1 {
+				continue
+			}
+			// The names known from HTML 4.01 are preferred, then the shortest name
+			if html401Name, found := html401EntityNamesByChar[value]; found && html5Entities[html401Name] == value {
+				names[value] = html401Name
+				continue
+			}
+			if current, found := names[value]; found && (len(current) < len(name) || (len(current) == len(name) && current < name)) {
+				continue
+			}
+			names[value] = name
+		}
+		return names
+	}()
+)
+
+// Get the entity table for decoding of the document type given in the flags
+func entityTable(flags int64) map[string]string {
+	switch flags & entDocTypeMask {
+	case ENT_XML1:
+		return xml1Entities
+	case ENT_XHTML:
+		return xhtmlEntities
+	case ENT_HTML5:
+		return html5Entities
+	default:
+		return html401Entities
+	}
+}
+
+// Get the entity table for encoding of the document type given in the flags
+func entityNamesByChar(flags int64) map[string]string {
+	switch flags & entDocTypeMask {
+	case ENT_XML1:
+		return map[string]string{}
+	case ENT_HTML5:
+		return html5EntityNamesByChar
+	default:
+		return html401EntityNamesByChar
+	}
+}
+
+// Check if the code point is allowed in the document type
+func isCodepointAllowed(codepoint int64, flags int64) bool {
+	isNonCharacter := (codepoint&0xFFFF) >= 0xFFFE || (codepoint >= 0xFDD0 && codepoint <= 0xFDEF)
+	switch flags & entDocTypeMask {
+	case ENT_HTML5:
+		return (codepoint >= 0x20 && codepoint <= 0x7E) ||
+			(codepoint >= 0x09 && codepoint <= 0x0D && codepoint != 0x0B) ||
+			(codepoint >= 0xA0 && codepoint <= 0xD7FF) ||
+			(codepoint >= 0xE000 && codepoint <= 0x10FFFF && !isNonCharacter)
+	case ENT_XHTML, ENT_XML1:
+		return (codepoint >= 0x20 && codepoint <= 0xD7FF) ||
+			codepoint == 0x0A || codepoint == 0x09 || codepoint == 0x0D ||
+			(codepoint >= 0xE000 && codepoint <= 0x10FFFF && codepoint != 0xFFFE && codepoint != 0xFFFF)
+	default:
+		return (codepoint >= 0x20 && codepoint <= 0x7E) ||
+			codepoint == 0x0A || codepoint == 0x09 || codepoint == 0x0D ||
+			(codepoint >= 0xA0 && codepoint <= 0xD7FF) ||
+			(codepoint >= 0xE000 && codepoint <= 0x10FFFF && !isNonCharacter)
+	}
+}
+
+// Check if a numeric entity with the code point is allowed in the document type.
+// This is less restrictive than isCodepointAllowed.
+func isNumericEntityAllowed(codepoint int64, flags int64) bool {
+	switch flags & entDocTypeMask {
+	case ENT_HTML5:
+		return (codepoint >= 0x20 && codepoint <= 0x7E) ||
+			(codepoint >= 0x09 && codepoint <= 0x0C && codepoint != 0x0B) ||
+			(codepoint >= 0xA0 && codepoint <= 0x10FFFF && (codepoint&0xFFFF) < 0xFFFE && (codepoint < 0xFDD0 || codepoint > 0xFDEF))
+	case ENT_XHTML, ENT_XML1:
+		return isCodepointAllowed(codepoint, flags)
+	default:
+		return codepoint <= 0x10FFFF
+	}
+}
+
+// Get the charset for the encoding argument
+func getCharset(functionName string, encoding values.RuntimeValue, context runtime.Context) string {
+	charset := context.Interpreter.GetIni().GetStr("default_charset")
+	if encoding.GetType() == values.StrValue && encoding.(*values.Str).Value != "" {
+		charset = encoding.(*values.Str).Value
+	}
+
+	switch goStrings.ToUpper(charset) {
+	case "UTF-8", "UTF8", "":
+		return charsetUtf8
+	case "ISO-8859-1", "ISO8859-1", "LATIN1":
+		return charsetLatin1
+	default:
+		context.Interpreter.PrintError(phpError.NewWarning("%s(): Charset \"%s\" is not supported, assuming UTF-8%s", functionName, charset, context.InPosition()))
+		return charsetUtf8
+	}
+}
+
+// Parse the entity at the beginning of the input (starting with "&").
+// Returns the length of the entity, the name (for named entities) and the code point (for numeric entities).
+func parseEntity(input string) (length int, name string, codepoint int64, isNumeric bool) {
+	if len(input) < 3 || input[0] != '&' {
+		return 0, "", 0, false
+	}
+
+	if input[1] == '#' {
+		pos := 2
+		base := 10
+		if pos < len(input) && (input[pos] == 'x' || input[pos] == 'X') {
+			base = 16
+			pos++
+		}
+		start := pos
+		for pos < len(input) && isDigitOfBase(input[pos], base) {
+			pos++
+		}
+		if pos == start || pos >= len(input) || input[pos] != ';' {
+			return 0, "", 0, false
+		}
+		codepoint, err := strconv.ParseInt(input[start:pos], base, 64)
+		if err != nil {
+			codepoint = 0x110000
+		}
+		return pos + 1, "", codepoint, true
+	}
+
+	pos := 1
+	for pos < len(input) && ((input[pos] >= 'a' && input[pos] <= 'z') || (input[pos] >= 'A' && input[pos] <= 'Z') || (input[pos] >= '0' && input[pos] <= '9')) {
+		pos++
+	}
+	if pos == 1 || pos >= len(input) || input[pos] != ';' {
+		return 0, "", 0, false
+	}
+	return pos + 1, input[1:pos], 0, false
+}
+
+// Escape the special characters (and all characters with an entity if allEntities is set)
+func encodeHtml(input string, flags int64, charset string, doubleEncode bool, allEntities bool) string {
+	entities := entityTable(flags)
+	entityNames := entityNamesByChar(flags)
+	isHtml401 := flags&entDocTypeMask == ENT_HTML401
+
+	var result goStrings.Builder
+	for pos := 0; pos < len(input); {
+		// Get the next character
+		var char string
+		var codepoint int64
+		if charset == charsetUtf8 {
+			r, size := utf8.DecodeRuneInString(input[pos:])
+			if r == utf8.RuneError && size <= 1 {
+				pos++
+				// Invalid code unit sequence
+				if flags&ENT_IGNORE != 0 {
+					continue
+				}
+				if flags&ENT_SUBSTITUTE != 0 {
+					result.WriteString("�")
+					continue
+				}
+				return ""
+			}
+			char = input[pos : pos+size]
+			codepoint = int64(r)
+			pos += size
+		} else {
+			char = input[pos : pos+1]
+			codepoint = int64(input[pos])
+			pos++
+		}
+
+		switch char {
+		case "&":
+			if !doubleEncode {
+				length, name, entityCodepoint, isNumeric := parseEntity(input[pos-1:])
+				valid := false
+				if length > 0 && isNumeric {
+					valid = isNumericEntityAllowed(entityCodepoint, flags)
+				} else if length > 0 {
+					_, valid = entities[name]
+				}
+				if valid {
+					result.WriteString(input[pos-1 : pos-1+length])
+					pos += length - 1
+					continue
+				}
+			}
+			result.WriteString("&amp;")
+		case "\"":
+			if flags&ENT_HTML_QUOTE_DOUBLE != 0 {
+				result.WriteString("&quot;")
+			} else {
+				result.WriteString(char)
+			}
+		case "'":
+			if flags&ENT_HTML_QUOTE_SINGLE == 0 {
+				result.WriteString(char)
+			} else if isHtml401 {
+				result.WriteString("&#039;")
+			} else {
+				result.WriteString("&apos;")
+			}
+		case "<":
+			result.WriteString("&lt;")
+		case ">":
+			result.WriteString("&gt;")
+		default:
+			if flags&ENT_DISALLOWED != 0 && !isCodepointAllowed(codepoint, flags) {
+				if charset == charsetUtf8 {
+					result.WriteString("�")
+				} else {
+					result.WriteString("&#xFFFD;")
+				}
+				continue
+			}
+			if allEntities {
+				if charset == charsetLatin1 {
+					char = string(rune(codepoint))
+				}
+				if name, found := entityNames[char]; found {
+					result.WriteString("&" + name + ";")
+					continue
+				}
+			}
+			result.WriteString(input[pos-len(char) : pos])
+		}
+	}
+
+	return result.String()
+}
+
+// Decode the entities (only the entities of special characters if allEntities is not set)
+func decodeHtml(input string, flags int64, charset string, allEntities bool) string {
+	entities := entityTable(flags)
+	if !allEntities {
+		entities = map[string]string{"quot": "\"", "amp": "&", "lt": "<", "gt": ">"}
+		if flags&entDocTypeMask != ENT_HTML401 {
+			entities["apos"] = "'"
+		}
+	}
+
+	var result goStrings.Builder
+	for pos := 0; pos < len(input); pos++ {
+		if input[pos] != '&' {
+			result.WriteByte(input[pos])
+			continue
+		}
+
+		length, name, codepoint, isNumeric := parseEntity(input[pos:])
+		if length == 0 {
+			result.WriteByte('&')
+			continue
+		}
+
+		var decoded string
+		if isNumeric {
+			// Only the special characters are decoded by htmlspecialchars_decode
+			if !allEntities && codepoint != '"' && codepoint != '&' && codepoint != '\'' && codepoint != '<' && codepoint != '>' {
+				result.WriteByte('&')
+				continue
+			}
+			// U+000D is allowed literally in HTML5 but not as numeric entity
+			if !isCodepointAllowed(codepoint, flags) || (flags&entDocTypeMask == ENT_HTML5 && codepoint == 0x0D) {
+				result.WriteByte('&')
+				continue
+			}
+			decoded = string(rune(codepoint))
+		} else {
+			var found bool
+			if decoded, found = entities[name]; !found {
+				result.WriteByte('&')
+				continue
+			}
+		}
+
+		if (decoded == "'" && flags&ENT_HTML_QUOTE_SINGLE == 0) || (decoded == "\"" && flags&ENT_HTML_QUOTE_DOUBLE == 0) {
+			result.WriteByte('&')
+			continue
+		}
+
+		if charset == charsetLatin1 {
+			r, size := utf8.DecodeRuneInString(decoded)
+			if r > 0xFF || size != len(decoded) {
+				result.WriteByte('&')
+				continue
+			}
+			decoded = string([]byte{byte(r)})
+		}
+
+		result.WriteString(decoded)
+		pos += length - 1
+	}
+
+	return result.String()
+}
+
+// -------------------------------------- get_html_translation_table -------------------------------------- MARK: get_html_translation_table
+
+func nativeFn_get_html_translation_table(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.get-html-translation-table.php
+	args, err := funcParamValidator.NewValidator("get_html_translation_table").
+		AddParam("$table", []string{"int"}, values.NewInt(HTML_SPECIALCHARS)).
+		AddParam("$flags", []string{"int"}, values.NewInt(ENT_QUOTES|ENT_SUBSTITUTE|ENT_HTML401)).
+		AddParam("$encoding", []string{"string"}, values.NewStr(charsetUtf8)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	table := args[0].(*values.Int).Value
+	flags := args[1].(*values.Int).Value
+	charset := getCharset("get_html_translation_table", args[2], context)
+
+	translations := map[string]string{"&": "&amp;", "<": "&lt;", ">": "&gt;"}
+	if flags&ENT_HTML_QUOTE_DOUBLE != 0 {
+		translations["\""] = "&quot;"
+	}
+	if flags&ENT_HTML_QUOTE_SINGLE != 0 {
+		if flags&entDocTypeMask == ENT_HTML401 {
+			translations["'"] = "&#039;"
+		} else {
+			translations["'"] = "&apos;"
+		}
+	}
+	if table == HTML_ENTITIES {
+		for char, name := range entityNamesByChar(flags) {
+			if _, found := translations[char]; found || char == "\"" || char == "'" {
+				continue
+			}
+			if charset == charsetLatin1 {
+				r, _ := utf8.DecodeRuneInString(char)
+				if r > 0xFF {
+					continue
+				}
+				char = string([]byte{byte(r)})
+			}
+			translations[char] = "&" + name + ";"
+		}
+	}
+
+	// Sort the characters by code point
+	chars := make([]string, 0, len(translations))
+	for char := range translations {
+		chars = append(chars, char)
+	}
+	slices.Sort(chars)
+
+	result := values.NewArray()
+	for _, char := range chars {
+		if err := result.SetElement(values.NewStr(char), values.NewStr(translations[char])); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- html_entity_decode -------------------------------------- MARK: html_entity_decode
+
+func nativeFn_html_entity_decode(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.html-entity-decode.php
+	args, err := funcParamValidator.NewValidator("html_entity_decode").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$flags", []string{"int"}, values.NewInt(ENT_QUOTES|ENT_SUBSTITUTE|ENT_HTML401)).
+		AddParam("$encoding", []string{"string", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	charset := getCharset("html_entity_decode", args[2], context)
+	return values.NewStr(decodeHtml(args[0].(*values.Str).Value, args[1].(*values.Int).Value, charset, true)), nil
+}
+
+// -------------------------------------- htmlentities -------------------------------------- MARK: htmlentities
+
+func nativeFn_htmlentities(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.htmlentities.php
+	return htmlEscape("htmlentities", args, context, true)
+}
+
+// Validate the arguments of htmlspecialchars and htmlentities and escape the string
+func htmlEscape(functionName string, args []values.RuntimeValue, context runtime.Context, allEntities bool) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$flags", []string{"int"}, values.NewInt(ENT_QUOTES|ENT_SUBSTITUTE|ENT_HTML401)).
+		AddParam("$encoding", []string{"string", "null"}, values.NewNull()).
+		AddParam("$double_encode", []string{"bool"}, values.NewBool(true)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	charset := getCharset(functionName, args[2], context)
+	return values.NewStr(encodeHtml(
+		args[0].(*values.Str).Value, args[1].(*values.Int).Value, charset, args[3].(*values.Bool).Value, allEntities,
+	)), nil
+}
+
+// -------------------------------------- htmlspecialchars -------------------------------------- MARK: htmlspecialchars
+
+func nativeFn_htmlspecialchars(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.htmlspecialchars.php
+	return htmlEscape("htmlspecialchars", args, context, false)
+}
+
+// -------------------------------------- htmlspecialchars_decode -------------------------------------- MARK: htmlspecialchars_decode
+
+func nativeFn_htmlspecialchars_decode(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.htmlspecialchars-decode.php
+	args, err := funcParamValidator.NewValidator("htmlspecialchars_decode").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$flags", []string{"int"}, values.NewInt(ENT_QUOTES|ENT_SUBSTITUTE|ENT_HTML401)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(decodeHtml(args[0].(*values.Str).Value, args[1].(*values.Int).Value, charsetUtf8, false)), nil
+}
+
+// -------------------------------------- strip_tags -------------------------------------- MARK: strip_tags
+
+func nativeFn_strip_tags(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strip-tags.php
+	args, err := funcParamValidator.NewValidator("strip_tags").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$allowed_tags", []string{"array", "string", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Allowed tags are normalized to the form "<tag>"
+	allowedTags := ""
+	switch allowed := args[1].(type) {
+	case *values.Str:
+		allowedTags = asciiToLower(allowed.Value)
+	case *values.Array:
+		for _, tag := range arrayToSlice(allowed) {
+			if tag.GetType() == values.StrValue {
+				allowedTags += "<" + asciiToLower(tag.(*values.Str).Value) + ">"
+			}
+		}
+	}
+
+	return values.NewStr(stripTags(args[0].(*values.Str).Value, allowedTags)), nil
+}
+
+// Port of PHP's php_strip_tags_ex state machine
+func stripTags(input string, allowedTags string) string {
+	const (
+		stateOutside = iota
+		stateTag
+		statePhp
+		stateExclamation
+		stateComment
+	)
+
+	var result goStrings.Builder
+	var tagBuffer goStrings.Builder
+	state := stateOutside
+	depth := 0
+	var inQuote, lastChar byte
+	isXml := false
+	allow := allowedTags != ""
+
+	prev := func(pos int, offset int) byte {
+		if pos-offset < 0 {
+			return 0
+		}
+		return input[pos-offset]
+	}
+	isSpace := func(char byte) bool {
+		return char == ' ' || char == '\t' || char == '\n' || char == '\r' || char == '\v' || char == '\f'
+	}
+	regularChar := func(char byte) {
+		if state == stateOutside {
+			result.WriteByte(char)
+		} else if allow && state == stateTag {
+			tagBuffer.WriteByte(char)
+		}
+	}
+
+	for pos := 0; pos < len(input); pos++ {
+		char := input[pos]
+		switch char {
+		case 0:
+		case '<':
+			if inQuote != 0 {
+				break
+			}
+			if pos+1 < len(input) && isSpace(input[pos+1]) && !allow {
+				regularChar(char)
+				break
+			}
+			if state == stateOutside {
+				lastChar = '<'
+				state = stateTag
+				if allow {
+					tagBuffer.Reset()
+					tagBuffer.WriteByte('<')
+				}
+			} else if state == stateTag {
+				depth++
+			}
+		case '>':
+			if depth > 0 {
+				depth--
+				break
+			}
+			if inQuote != 0 {
+				break
+			}
+			switch state {
+			case stateTag:
+				lastChar = '>'
+				if isXml && prev(pos, 1) == '-' {
+					break
+				}
+				inQuote, state, isXml = 0, stateOutside, false
+				if allow {
+					tagBuffer.WriteByte('>')
+					if isAllowedTag(tagBuffer.String(), allowedTags) {
+						result.WriteString(tagBuffer.String())
+					}
+					tagBuffer.Reset()
+				}
+			case statePhp:
+				if lastChar != '"' && prev(pos, 1) == '?' {
+					inQuote, state = 0, stateOutside
+					tagBuffer.Reset()
+				}
+			case stateExclamation:
+				inQuote, state = 0, stateOutside
+				tagBuffer.Reset()
+			case stateComment:
+				if prev(pos, 1) == '-' && prev(pos, 2) == '-' {
+					inQuote, state = 0, stateOutside
+					tagBuffer.Reset()
+				}
+			default:
+				result.WriteByte(char)
+			}
+		case '"', '\'':
+			if state == stateComment {
+				break
+			}
+			if state == statePhp && prev(pos, 1) != '\\' {
+				if lastChar == char {
+					lastChar = 0
+				} else if lastChar != '\\' {
+					lastChar = char
+				}
+			} else if state == stateOutside {
+				result.WriteByte(char)
+			} else if allow && state == stateTag {
+				tagBuffer.WriteByte(char)
+			}
+			if state != stateOutside && pos > 0 && (state == stateTag || prev(pos, 1) != '\\') && (inQuote == 0 || char == inQuote) {
+				if inQuote != 0 {
+					inQuote = 0
+				} else {
+					inQuote = char
+				}
+			}
+		case '!':
+			// JavaScript & Other HTML scripting languages
+			if state == stateTag && prev(pos, 1) == '<' {
+				state = stateExclamation
+				lastChar = char
+			} else {
+				regularChar(char)
+			}
+		case '?':
+			if state == stateTag && prev(pos, 1) == '<' {
+				state = statePhp
+			} else {
+				regularChar(char)
+			}
+		case '-':
+			if state == stateExclamation && prev(pos, 1) == '-' && prev(pos, 2) == '!' {
+				state = stateComment
+			} else {
+				regularChar(char)
+			}
+		case 'E', 'e':
+			// !DOCTYPE exception
+			if state == stateExclamation && pos > 6 && asciiToLower(input[pos-6:pos]) == "doctyp" {
+				state = stateTag
+			} else {
+				regularChar(char)
+			}
+		case 'l', 'L':
+			// XML declaration
+			if state == statePhp && pos >= 4 && asciiToLower(input[pos-4:pos]) == "<?xm" {
+				state = stateTag
+				isXml = true
+			} else {
+				regularChar(char)
+			}
+		default:
+			regularChar(char)
+		}
+	}
+
+	return result.String()
+}
+
+// Check if the tag (e.g. "<a href='...'>" or "</a>") is contained in the allowed tags (e.g. "<a><b>")
+func isAllowedTag(tag string, allowedTags string) bool {
+	// Normalize the tag to the form "<name>"
+	var normalized goStrings.Builder
+	started := false
+	for pos := 0; pos < len(tag); pos++ {
+		char := tag[pos]
+		if char >= 'A' && char <= 'Z' {
+			char += 'a' - 'A'
+		}
+		if char == '<' {
+			normalized.WriteByte(char)
+			continue
+		}
+		if char == '>' {
+			break
+		}
+		if char == ' ' || char == '\t' || char == '\n' || char == '\r' || char == '\v' || char == '\f' {
+			if started {
+				break
+			}
+			continue
+		}
+		started = true
+		if char != '/' || (tag[pos-1] != '<' && (pos+1 >= len(tag) || tag[pos+1] != '>')) {
+			normalized.WriteByte(char)
+		}
+	}
+	normalized.WriteByte('>')
+
+	return goStrings.Contains(allowedTags, normalized.String())
+}
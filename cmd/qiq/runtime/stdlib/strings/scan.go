@@ -0,0 +1,372 @@
+package strings
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+	"strconv"
+	goStrings "strings"
+)
+
+// -------------------------------------- sscanf -------------------------------------- MARK: sscanf
+
+func nativeFn_sscanf(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.sscanf.php
+	args, err := funcParamValidator.NewValidator("sscanf").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$format", []string{"string"}, nil).
+		AddVariableLenParam("$vars", []string{"mixed"}).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := args[0].(*values.Str).Value
+	format := args[1].(*values.Str).Value
+	varCount := len(args[2].(*values.Array).Keys)
+
+	results, conversions, eof, err := scanString(input, format)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	if varCount > 0 {
+		if varCount < len(results) {
+			return values.NewVoid(), phpError.NewError("Uncaught ValueError: Different numbers of variable names and field specifiers")
+		}
+		if varCount > len(results) {
+			return values.NewVoid(), phpError.NewError("Uncaught ValueError: Variable is not assigned by any conversion specifiers")
+		}
+		// The input ended before the first conversion
+		if eof && conversions == 0 {
+			return values.NewInt(-1), nil
+		}
+		for index, result := range results {
+			if result != nil {
+				context.SetRefArg(index+2, result)
+			}
+		}
+		return values.NewInt(int64(conversions)), nil
+	}
+
+	// The input ended before the first conversion
+	if eof && conversions == 0 {
+		return values.NewNull(), nil
+	}
+	array := values.NewArray()
+	for _, result := range results {
+		if result == nil {
+			result = values.NewNull()
+		}
+		if err := array.SetElement(nil, result); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return array, nil
+}
+
+// Parse the input according to the format.
+// Returns the converted values (nil if not converted), the number of conversions and
+// if the input ended before the format was processed completely.
+func scanString(input string, format string) ([]values.RuntimeValue, int, bool, phpError.Error) {
+	isSpace := func(char byte) bool {
+		return char == ' ' || char == '\t' || char == '\n' || char == '\r' || char == '\v' || char == '\f'
+	}
+
+	// Count the conversions to get the number of results
+	resultCount := 0
+	usesArgnum := false
+	for pos := 0; pos < len(format); pos++ {
+		if format[pos] != '%' {
+			continue
+		}
+		pos++
+		if pos >= len(format) || format[pos] == '%' || format[pos] == '*' {
+			continue
+		}
+		start := pos
+		for pos < len(format) && format[pos] >= '0' && format[pos] <= '9' {
+			pos++
+		}
+		if pos < len(format) && format[pos] == '$' {
+			argnum, _ := strconv.Atoi(format[start:pos])
+			if argnum <= 0 {
+				return nil, 0, false, phpError.NewError("Uncaught ValueError: \"%%n$\" argument index out of range")
+			}
+			usesArgnum = true
+			resultCount = max(resultCount, argnum)
+		} else {
+			if usesArgnum {
+				return nil, 0, false, phpError.NewError("Uncaught ValueError: cannot mix \"%%\" and \"%%n$\" conversion specifiers")
+			}
+			resultCount++
+		}
+		// Skip a character set so that "%" inside it is not handled as conversion
+		for pos < len(format) && format[pos] >= '0' && format[pos] <= '9' {
+			pos++
+		}
+		if pos < len(format) && format[pos] == '[' {
+			pos++
+			if pos < len(format) && format[pos] == '^' {
+				pos++
+			}
+			if pos < len(format) && format[pos] == ']' {
+				pos++
+			}
+			for pos < len(format) && format[pos] != ']' {
+				pos++
+			}
+		}
+	}
+
+	results := make([]values.RuntimeValue, resultCount)
+	conversions := 0
+	nextResult := 0
+	inputPos := 0
+
+	for formatPos := 0; formatPos < len(format); {
+		char := format[formatPos]
+		formatPos++
+
+		// Whitespace in the format matches any amount of whitespace in the input
+		if isSpace(char) {
+			for inputPos < len(input) && isSpace(input[inputPos]) {
+				inputPos++
+			}
+			continue
+		}
+
+		// Literal characters must match exactly
+		if char != '%' || (formatPos < len(format) && format[formatPos] == '%') {
+			if char == '%' {
+				formatPos++
+			}
+			if inputPos >= len(input) {
+				return results, conversions, true, nil
+			}
+			if input[inputPos] != char {
+				return results, conversions, false, nil
+			}
+			inputPos++
+			continue
+		}
+
+		// Conversion: %[*][argnum$][width][l|L|h]specifier
+		suppress := false
+		resultIndex := -1
+		if formatPos < len(format) && format[formatPos] == '*' {
+			suppress = true
+			formatPos++
+		} else {
+			start := formatPos
+			for formatPos < len(format) && format[formatPos] >= '0' && format[formatPos] <= '9' {
+				formatPos++
+			}
+			if formatPos < len(format) && format[formatPos] == '$' {
+				resultIndex, _ = strconv.Atoi(format[start:formatPos])
+				resultIndex--
+				formatPos++
+			} else {
+				formatPos = start
+			}
+		}
+		widthStart := formatPos
+		for formatPos < len(format) && format[formatPos] >= '0' && format[formatPos] <= '9' {
+			formatPos++
+		}
+		width, _ := strconv.Atoi(format[widthStart:formatPos])
+		if formatPos < len(format) && (format[formatPos] == 'l' || format[formatPos] == 'L' || format[formatPos] == 'h') {
+			formatPos++
+		}
+		if formatPos >= len(format) {
+			return nil, 0, false, phpError.NewError("Uncaught ValueError: Bad scan conversion character \"\"")
+		}
+		specifier := format[formatPos]
+		formatPos++
+
+		if !suppress && resultIndex == -1 {
+			resultIndex = nextResult
+			nextResult++
+		}
+		store := func(value values.RuntimeValue) {
+			if !suppress {
+				results[resultIndex] = value
+				conversions++
+			}
+		}
+
+		if specifier == 'n' {
+			store(values.NewInt(int64(inputPos)))
+			continue
+		}
+
+		// All conversions except "c" and "[" skip leading whitespace
+		if specifier != 'c' && specifier != '[' {
+			for inputPos < len(input) && isSpace(input[inputPos]) {
+				inputPos++
+			}
+		}
+		if inputPos >= len(input) {
+			return results, conversions, true, nil
+		}
+
+		// Maximum end of the field
+		end := len(input)
+		if width > 0 && inputPos+width < end {
+			end = inputPos + width
+		}
+
+		switch specifier {
+		case 'c':
+			store(values.NewStr(input[inputPos : inputPos+1]))
+			inputPos++
+
+		case 's':
+			start := inputPos
+			for inputPos < end && !isSpace(input[inputPos]) {
+				inputPos++
+			}
+			store(values.NewStr(input[start:inputPos]))
+
+		case '[':
+			// Parse the character set
+			negate := false
+			if formatPos < len(format) && format[formatPos] == '^' {
+				negate = true
+				formatPos++
+			}
+			setStart := formatPos
+			if formatPos < len(format) && format[formatPos] == ']' {
+				formatPos++
+			}
+			for formatPos < len(format) && format[formatPos] != ']' {
+				formatPos++
+			}
+			if formatPos >= len(format) {
+				return nil, 0, false, phpError.NewError("Uncaught ValueError: Unmatched [ in format string")
+			}
+			set := format[setStart:formatPos]
+			formatPos++
+
+			inSet := func(char byte) bool {
+				for i := 0; i < len(set); i++ {
+					if i+2 < len(set) && set[i+1] == '-' {
+						if char >= min(set[i], set[i+2]) && char <= max(set[i], set[i+2]) {
+							return true
+						}
+						i += 2
+						continue
+					}
+					if set[i] == char {
+						return true
+					}
+				}
+				return false
+			}
+
+			start := inputPos
+			for inputPos < end && inSet(input[inputPos]) != negate {
+				inputPos++
+			}
+			if inputPos == start {
+				return results, conversions, false, nil
+			}
+			store(values.NewStr(input[start:inputPos]))
+
+		case 'd', 'D', 'i', 'o', 'x', 'X', 'u':
+			base := map[byte]int{'d': 10, 'D': 10, 'i': 0, 'o': 8, 'x': 16, 'X': 16, 'u': 10}[specifier]
+			sign := ""
+			if inputPos < end && (input[inputPos] == '+' || input[inputPos] == '-') {
+				sign = input[inputPos : inputPos+1]
+				inputPos++
+			}
+			if base == 0 {
+				base = 10
+				if inputPos+1 < end && input[inputPos] == '0' && (input[inputPos+1] == 'x' || input[inputPos+1] == 'X') {
+					base = 16
+					inputPos += 2
+				} else if inputPos < end && input[inputPos] == '0' {
+					base = 8
+				}
+			} else if base == 16 && inputPos+1 < end && input[inputPos] == '0' && (input[inputPos+1] == 'x' || input[inputPos+1] == 'X') {
+				inputPos += 2
+			}
+			digitStart := inputPos
+			for inputPos < end && isDigitOfBase(input[inputPos], base) {
+				inputPos++
+			}
+			if inputPos == digitStart {
+				return results, conversions, false, nil
+			}
+			number, err := strconv.ParseInt(sign+input[digitStart:inputPos], base, 64)
+			if err != nil {
+				// Values out of range are saturated like strtol does
+				if sign == "-" {
+					number = -1 << 63
+				} else {
+					number = 1<<63 - 1
+				}
+			}
+			if specifier == 'u' && number < 0 {
+				store(values.NewStr(strconv.FormatUint(uint64(number), 10)))
+			} else {
+				store(values.NewInt(number))
+			}
+
+		case 'e', 'E', 'f', 'g':
+			start := inputPos
+			if inputPos < end && (input[inputPos] == '+' || input[inputPos] == '-') {
+				inputPos++
+			}
+			digits := 0
+			for inputPos < end && input[inputPos] >= '0' && input[inputPos] <= '9' {
+				inputPos++
+				digits++
+			}
+			if inputPos < end && input[inputPos] == '.' {
+				inputPos++
+				for inputPos < end && input[inputPos] >= '0' && input[inputPos] <= '9' {
+					inputPos++
+					digits++
+				}
+			}
+			if digits == 0 {
+				return results, conversions, false, nil
+			}
+			// Exponent
+			if inputPos < end && (input[inputPos] == 'e' || input[inputPos] == 'E') {
+				exponentPos := inputPos + 1
+				if exponentPos < end && (input[exponentPos] == '+' || input[exponentPos] == '-') {
+					exponentPos++
+				}
+				if exponentPos < end && input[exponentPos] >= '0' && input[exponentPos] <= '9' {
+					for exponentPos < end && input[exponentPos] >= '0' && input[exponentPos] <= '9' {
+						exponentPos++
+					}
+					inputPos = exponentPos
+				}
+			}
+			number, _ := strconv.ParseFloat(goStrings.TrimSuffix(input[start:inputPos], "."), 64)
+			store(values.NewFloat(number))
+
+		default:
+			return nil, 0, false, phpError.NewError("Uncaught ValueError: Bad scan conversion character \"%c\"", specifier)
+		}
+	}
+
+	return results, conversions, false, nil
+}
+
+func isDigitOfBase(char byte, base int) bool {
+	switch {
+	case char >= '0' && char <= '9':
+		return int(char-'0') < base
+	case char >= 'a' && char <= 'f':
+		return base == 16
+	case char >= 'A' && char <= 'F':
+		return base == 16
+	default:
+		return false
+	}
+}
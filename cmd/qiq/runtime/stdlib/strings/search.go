@@ -0,0 +1,306 @@
+package strings
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/values"
+	goStrings "strings"
+)
+
+// Validate the arguments of the strpos functions
+func validateHaystackAndNeedle(functionName string, args []values.RuntimeValue) (string, string, int, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$haystack", []string{"string"}, nil).
+		AddParam("$needle", []string{"string"}, nil).
+		AddParam("$offset", []string{"int"}, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return "", "", 0, err
+	}
+	return args[0].(*values.Str).Value, args[1].(*values.Str).Value, int(args[2].(*values.Int).Value), nil
+}
+
+func offsetError(functionName string) phpError.Error {
+	return phpError.NewError("Uncaught ValueError: %s(): Argument #3 ($offset) must be contained in argument #1 ($haystack)", functionName)
+}
+
+// Find the position of the first occurrence of the needle starting at the offset
+func findFirst(functionName string, haystack string, needle string, offset int) (values.RuntimeValue, phpError.Error) {
+	if offset < 0 {
+		offset += len(haystack)
+	}
+	if offset < 0 || offset > len(haystack) {
+		return values.NewVoid(), offsetError(functionName)
+	}
+
+	pos := goStrings.Index(haystack[offset:], needle)
+	if pos == -1 {
+		return values.NewBool(false), nil
+	}
+	return values.NewInt(int64(offset + pos)), nil
+}
+
+// Find the position of the last occurrence of the needle.
+// A negative offset stops the search the given number of bytes from the end of the haystack.
+func findLast(functionName string, haystack string, needle string, offset int) (values.RuntimeValue, phpError.Error) {
+	start, end := 0, len(haystack)
+	if offset >= 0 {
+		if offset > len(haystack) {
+			return values.NewVoid(), offsetError(functionName)
+		}
+		start = offset
+	} else {
+		if -offset > len(haystack) {
+			return values.NewVoid(), offsetError(functionName)
+		}
+		if -offset >= len(needle) {
+			end = len(haystack) + offset + len(needle)
+		}
+	}
+
+	pos := goStrings.LastIndex(haystack[start:end], needle)
+	if pos == -1 {
+		return values.NewBool(false), nil
+	}
+	return values.NewInt(int64(start + pos)), nil
+}
+
+// Get the part of the haystack starting at the given position or the part before it
+func partOfHaystack(haystack string, pos int, beforeNeedle bool) values.RuntimeValue {
+	if pos == -1 {
+		return values.NewBool(false)
+	}
+	if beforeNeedle {
+		return values.NewStr(haystack[:pos])
+	}
+	return values.NewStr(haystack[pos:])
+}
+
+// Validate the arguments of the strstr functions
+func validateStrstr(functionName string, args []values.RuntimeValue) (string, string, bool, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$haystack", []string{"string"}, nil).
+		AddParam("$needle", []string{"string"}, nil).
+		AddParam("$before_needle", []string{"bool"}, values.NewBool(false)).
+		Validate(args)
+	if err != nil {
+		return "", "", false, err
+	}
+	return args[0].(*values.Str).Value, args[1].(*values.Str).Value, args[2].(*values.Bool).Value, nil
+}
+
+// Get the part of the string selected by offset and length (like substr) for strspn and strcspn
+func validateSpan(functionName string, args []values.RuntimeValue) (string, string, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$characters", []string{"string"}, nil).
+		AddParam("$offset", []string{"int"}, values.NewInt(0)).
+		AddParam("$length", []string{"int", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return "", "", err
+	}
+
+	input := args[0].(*values.Str).Value
+	offset := int(args[2].(*values.Int).Value)
+	if offset < 0 {
+		offset = max(len(input)+offset, 0)
+	}
+	offset = min(offset, len(input))
+	input = input[offset:]
+
+	if args[3].GetType() == values.IntValue {
+		length := int(args[3].(*values.Int).Value)
+		if length < 0 {
+			length = max(len(input)+length, 0)
+		}
+		input = input[:min(length, len(input))]
+	}
+	return input, args[1].(*values.Str).Value, nil
+}
+
+// -------------------------------------- strcspn -------------------------------------- MARK: strcspn
+
+func nativeFn_strcspn(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strcspn.php
+	input, characters, err := validateSpan("strcspn", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	length := 0
+	for length < len(input) && goStrings.IndexByte(characters, input[length]) == -1 {
+		length++
+	}
+	return values.NewInt(int64(length)), nil
+}
+
+// -------------------------------------- stripos -------------------------------------- MARK: stripos
+
+func nativeFn_stripos(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.stripos.php
+	haystack, needle, offset, err := validateHaystackAndNeedle("stripos", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return findFirst("stripos", asciiToLower(haystack), asciiToLower(needle), offset)
+}
+
+// -------------------------------------- stristr -------------------------------------- MARK: stristr
+
+func nativeFn_stristr(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.stristr.php
+	haystack, needle, beforeNeedle, err := validateStrstr("stristr", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return partOfHaystack(haystack, goStrings.Index(asciiToLower(haystack), asciiToLower(needle)), beforeNeedle), nil
+}
+
+// -------------------------------------- strpbrk -------------------------------------- MARK: strpbrk
+
+func nativeFn_strpbrk(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strpbrk.php
+	args, err := funcParamValidator.NewValidator("strpbrk").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$characters", []string{"string"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := args[0].(*values.Str).Value
+	characters := args[1].(*values.Str).Value
+	if characters == "" {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: strpbrk(): Argument #2 ($characters) must be a non-empty string")
+	}
+
+	for i := 0; i < len(input); i++ {
+		if goStrings.IndexByte(characters, input[i]) != -1 {
+			return values.NewStr(input[i:]), nil
+		}
+	}
+	return values.NewBool(false), nil
+}
+
+// -------------------------------------- strpos -------------------------------------- MARK: strpos
+
+func nativeFn_strpos(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strpos.php
+	haystack, needle, offset, err := validateHaystackAndNeedle("strpos", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return findFirst("strpos", haystack, needle, offset)
+}
+
+// -------------------------------------- strrchr -------------------------------------- MARK: strrchr
+
+func nativeFn_strrchr(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strrchr.php
+	haystack, needle, beforeNeedle, err := validateStrstr("strrchr", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Only the first character of the needle is used
+	var char byte
+	if len(needle) > 0 {
+		char = needle[0]
+	}
+	return partOfHaystack(haystack, goStrings.LastIndexByte(haystack, char), beforeNeedle), nil
+}
+
+// -------------------------------------- strripos -------------------------------------- MARK: strripos
+
+func nativeFn_strripos(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strripos.php
+	haystack, needle, offset, err := validateHaystackAndNeedle("strripos", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return findLast("strripos", asciiToLower(haystack), asciiToLower(needle), offset)
+}
+
+// -------------------------------------- strrpos -------------------------------------- MARK: strrpos
+
+func nativeFn_strrpos(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strrpos.php
+	haystack, needle, offset, err := validateHaystackAndNeedle("strrpos", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return findLast("strrpos", haystack, needle, offset)
+}
+
+// -------------------------------------- strspn -------------------------------------- MARK: strspn
+
+func nativeFn_strspn(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strspn.php
+	input, characters, err := validateSpan("strspn", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	length := 0
+	for length < len(input) && goStrings.IndexByte(characters, input[length]) != -1 {
+		length++
+	}
+	return values.NewInt(int64(length)), nil
+}
+
+// -------------------------------------- strstr -------------------------------------- MARK: strstr
+
+func nativeFn_strstr(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strstr.php
+	haystack, needle, beforeNeedle, err := validateStrstr("strstr", args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return partOfHaystack(haystack, goStrings.Index(haystack, needle), beforeNeedle), nil
+}
+
+// -------------------------------------- substr_count -------------------------------------- MARK: substr_count
+
+func nativeFn_substr_count(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.substr-count.php
+	args, err := funcParamValidator.NewValidator("substr_count").
+		AddParam("$haystack", []string{"string"}, nil).
+		AddParam("$needle", []string{"string"}, nil).
+		AddParam("$offset", []string{"int"}, values.NewInt(0)).
+		AddParam("$length", []string{"int", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	haystack := args[0].(*values.Str).Value
+	needle := args[1].(*values.Str).Value
+	offset := int(args[2].(*values.Int).Value)
+
+	if needle == "" {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: substr_count(): Argument #2 ($needle) cannot be empty")
+	}
+
+	if offset < 0 {
+		offset += len(haystack)
+	}
+	if offset < 0 || offset > len(haystack) {
+		return values.NewVoid(), offsetError("substr_count")
+	}
+	haystack = haystack[offset:]
+
+	if args[3].GetType() == values.IntValue {
+		length := int(args[3].(*values.Int).Value)
+		if length < 0 {
+			length += len(haystack)
+		}
+		if length < 0 || length > len(haystack) {
+			return values.NewVoid(), phpError.NewError("Uncaught ValueError: substr_count(): Argument #4 ($length) must be contained in argument #1 ($haystack)")
+		}
+		haystack = haystack[:length]
+	}
+
+	return values.NewInt(int64(goStrings.Count(haystack, needle))), nil
+}
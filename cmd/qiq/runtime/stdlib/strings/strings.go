@@ -2,39 +2,110 @@ package strings
 
 import (
 	"QIQ/cmd/qiq/ast"
+	"QIQ/cmd/qiq/common"
 	"QIQ/cmd/qiq/phpError"
 	"QIQ/cmd/qiq/runtime"
 	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/random"
+	"QIQ/cmd/qiq/runtime/stdlib/url"
 	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
 	"QIQ/cmd/qiq/runtime/values"
 	"crypto/md5"
 	"crypto/sha1"
 	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"math"
 	"regexp"
+	"slices"
+	"strconv"
 	goStrings "strings"
 )
 
 func Register(environment runtime.Environment) {
 	// Category: String Functions
+	environment.AddNativeFunction("addcslashes", nativeFn_addcslashes)
+	environment.AddNativeFunction("addslashes", nativeFn_addslashes)
 	environment.AddNativeFunction("bin2hex", nativeFn_bin2hex)
+	environment.AddNativeFunction("chop", nativeFn_rtrim)
 	environment.AddNativeFunction("chr", nativeFn_chr)
+	environment.AddNativeFunction("chunk_split", nativeFn_chunk_split)
+	environment.AddNativeFunction("count_chars", nativeFn_count_chars)
+	environment.AddNativeFunction("crc32", nativeFn_crc32)
+	environment.AddNativeFunction("explode", nativeFn_explode)
+	environment.AddNativeFunction("get_html_translation_table", nativeFn_get_html_translation_table)
 	environment.AddNativeFunction("hex2bin", nativeFn_hex2bin)
+	environment.AddNativeFunction("html_entity_decode", nativeFn_html_entity_decode)
+	environment.AddNativeFunction("htmlentities", nativeFn_htmlentities)
+	environment.AddNativeFunction("htmlspecialchars", nativeFn_htmlspecialchars)
+	environment.AddNativeFunction("fprintf", nativeFn_fprintf)
+	environment.AddNativeFunction("htmlspecialchars_decode", nativeFn_htmlspecialchars_decode)
 	environment.AddNativeFunction("implode", nativeFn_implode)
 	environment.AddNativeFunction("join", nativeFn_implode)
 	environment.AddNativeFunction("lcfirst", nativeFn_lcfirst)
+	environment.AddNativeFunction("levenshtein", nativeFn_levenshtein)
+	environment.AddNativeFunction("ltrim", nativeFn_ltrim)
 	environment.AddNativeFunction("md5", nativeFn_md5)
+	environment.AddNativeFunction("metaphone", nativeFn_metaphone)
 	environment.AddNativeFunction("nl2br", nativeFn_nl2br)
+	environment.AddNativeFunction("number_format", nativeFn_number_format)
+	environment.AddNativeFunction("ord", nativeFn_ord)
+	environment.AddNativeFunctionByRef("parse_str", nativeFn_parse_str, runtime.NewByRefParams(1))
+	environment.AddNativeFunction("printf", nativeFn_printf)
 	environment.AddNativeFunction("quotemeta", nativeFn_quotemeta)
+	environment.AddNativeFunction("rtrim", nativeFn_rtrim)
 	environment.AddNativeFunction("sha1", nativeFn_sha1)
+	environment.AddNativeFunctionByRef("similar_text", nativeFn_similar_text, runtime.NewByRefParams(2))
+	environment.AddNativeFunction("soundex", nativeFn_soundex)
+	environment.AddNativeFunction("sprintf", nativeFn_sprintf)
+	environment.AddNativeFunctionByRef("sscanf", nativeFn_sscanf, runtime.NewVariadicByRefParams(2))
 	environment.AddNativeFunction("str_contains", nativeFn_str_contains)
 	environment.AddNativeFunction("str_ends_with", nativeFn_str_ends_with)
+	environment.AddNativeFunctionByRef("str_ireplace", nativeFn_str_ireplace, runtime.NewByRefParams(3))
+	environment.AddNativeFunction("str_pad", nativeFn_str_pad)
 	environment.AddNativeFunction("str_repeat", nativeFn_str_repeat)
+	environment.AddNativeFunctionByRef("str_replace", nativeFn_str_replace, runtime.NewByRefParams(3))
+	environment.AddNativeFunction("str_rot13", nativeFn_str_rot13)
+	environment.AddNativeFunction("str_shuffle", nativeFn_str_shuffle)
+	environment.AddNativeFunction("str_split", nativeFn_str_split)
 	environment.AddNativeFunction("str_starts_with", nativeFn_str_starts_with)
+	environment.AddNativeFunction("str_word_count", nativeFn_str_word_count)
+	environment.AddNativeFunction("strcasecmp", nativeFn_strcasecmp)
+	environment.AddNativeFunction("strchr", nativeFn_strstr)
+	environment.AddNativeFunction("strcmp", nativeFn_strcmp)
+	environment.AddNativeFunction("strcspn", nativeFn_strcspn)
+	environment.AddNativeFunction("strip_tags", nativeFn_strip_tags)
+	environment.AddNativeFunction("stripcslashes", nativeFn_stripcslashes)
+	environment.AddNativeFunction("stripos", nativeFn_stripos)
+	environment.AddNativeFunction("stripslashes", nativeFn_stripslashes)
+	environment.AddNativeFunction("stristr", nativeFn_stristr)
 	environment.AddNativeFunction("strlen", nativeFn_strlen)
+	environment.AddNativeFunction("strnatcasecmp", nativeFn_strnatcasecmp)
+	environment.AddNativeFunction("strnatcmp", nativeFn_strnatcmp)
+	environment.AddNativeFunction("strncasecmp", nativeFn_strncasecmp)
+	environment.AddNativeFunction("strncmp", nativeFn_strncmp)
+	environment.AddNativeFunction("strpbrk", nativeFn_strpbrk)
+	environment.AddNativeFunction("strpos", nativeFn_strpos)
+	environment.AddNativeFunction("strrchr", nativeFn_strrchr)
+	environment.AddNativeFunction("strrev", nativeFn_strrev)
+	environment.AddNativeFunction("strripos", nativeFn_strripos)
+	environment.AddNativeFunction("strrpos", nativeFn_strrpos)
+	environment.AddNativeFunction("strspn", nativeFn_strspn)
+	environment.AddNativeFunction("strstr", nativeFn_strstr)
 	environment.AddNativeFunction("strtolower", nativeFn_strtolower)
 	environment.AddNativeFunction("strtoupper", nativeFn_strtoupper)
+	environment.AddNativeFunction("strtr", nativeFn_strtr)
 	environment.AddNativeFunction("substr", nativeFn_substr)
+	environment.AddNativeFunction("substr_compare", nativeFn_substr_compare)
+	environment.AddNativeFunction("substr_count", nativeFn_substr_count)
+	environment.AddNativeFunction("substr_replace", nativeFn_substr_replace)
+	environment.AddNativeFunction("trim", nativeFn_trim)
 	environment.AddNativeFunction("ucfirst", nativeFn_ucfirst)
+	environment.AddNativeFunction("ucwords", nativeFn_ucwords)
+	environment.AddNativeFunction("vfprintf", nativeFn_vfprintf)
+	environment.AddNativeFunction("vprintf", nativeFn_vprintf)
+	environment.AddNativeFunction("vsprintf", nativeFn_vsprintf)
+	environment.AddNativeFunction("wordwrap", nativeFn_wordwrap)
 
 	// Const Category: String Constants
 	// Spec: https://www.php.net/manual/en/string.constants.php
@@ -45,18 +116,18 @@ func Register(environment runtime.Environment) {
 	environment.AddPredefinedConstant("CRYPT_BLOWFISH", values.NewInt(1))
 	environment.AddPredefinedConstant("CRYPT_SHA256", values.NewInt(1))
 	environment.AddPredefinedConstant("CRYPT_SHA512", values.NewInt(0))
-	environment.AddPredefinedConstant("HTML_SPECIALCHARS", values.NewInt(1))
-	environment.AddPredefinedConstant("HTML_ENTITIES", values.NewInt(2))
-	environment.AddPredefinedConstant("ENT_COMPAT", values.NewInt(3))
-	environment.AddPredefinedConstant("ENT_QUOTES", values.NewInt(0))
-	environment.AddPredefinedConstant("ENT_NOQUOTES", values.NewInt(4))
-	environment.AddPredefinedConstant("ENT_IGNORE", values.NewInt(8))
-	environment.AddPredefinedConstant("ENT_SUBSTITUTE", values.NewInt(128))
-	environment.AddPredefinedConstant("ENT_DISALLOWED", values.NewInt(0))
-	environment.AddPredefinedConstant("ENT_HTML401", values.NewInt(16))
-	environment.AddPredefinedConstant("ENT_XML1", values.NewInt(32))
-	environment.AddPredefinedConstant("ENT_XHTML", values.NewInt(48))
-	environment.AddPredefinedConstant("ENT_HTML5", values.NewInt(127))
+	environment.AddPredefinedConstant("HTML_SPECIALCHARS", values.NewInt(HTML_SPECIALCHARS))
+	environment.AddPredefinedConstant("HTML_ENTITIES", values.NewInt(HTML_ENTITIES))
+	environment.AddPredefinedConstant("ENT_COMPAT", values.NewInt(ENT_COMPAT))
+	environment.AddPredefinedConstant("ENT_QUOTES", values.NewInt(ENT_QUOTES))
+	environment.AddPredefinedConstant("ENT_NOQUOTES", values.NewInt(ENT_NOQUOTES))
+	environment.AddPredefinedConstant("ENT_IGNORE", values.NewInt(ENT_IGNORE))
+	environment.AddPredefinedConstant("ENT_SUBSTITUTE", values.NewInt(ENT_SUBSTITUTE))
+	environment.AddPredefinedConstant("ENT_DISALLOWED", values.NewInt(ENT_DISALLOWED))
+	environment.AddPredefinedConstant("ENT_HTML401", values.NewInt(ENT_HTML401))
+	environment.AddPredefinedConstant("ENT_XML1", values.NewInt(ENT_XML1))
+	environment.AddPredefinedConstant("ENT_XHTML", values.NewInt(ENT_XHTML))
+	environment.AddPredefinedConstant("ENT_HTML5", values.NewInt(ENT_HTML5))
 	environment.AddPredefinedConstant("CHAR_MAX", values.NewInt(0))
 	environment.AddPredefinedConstant("LC_CTYPE", values.NewInt(1))
 	environment.AddPredefinedConstant("LC_NUMERIC", values.NewInt(2))
@@ -65,8 +136,124 @@ func Register(environment runtime.Environment) {
 	environment.AddPredefinedConstant("LC_MONETARY", values.NewInt(6))
 	environment.AddPredefinedConstant("LC_ALL", values.NewInt(5))
 	environment.AddPredefinedConstant("LC_MESSAGES", values.NewInt(0))
-	environment.AddPredefinedConstant("STR_PAD_LEFT", values.NewInt(1))
-	environment.AddPredefinedConstant("STR_PAD_RIGHT", values.NewInt(2))
+	environment.AddPredefinedConstant("STR_PAD_LEFT", values.NewInt(STR_PAD_LEFT))
+	environment.AddPredefinedConstant("STR_PAD_RIGHT", values.NewInt(STR_PAD_RIGHT))
+	environment.AddPredefinedConstant("STR_PAD_BOTH", values.NewInt(STR_PAD_BOTH))
+}
+
+// -------------------------------------- addcslashes -------------------------------------- MARK: addcslashes
+
+func nativeFn_addcslashes(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.addcslashes.php
+	args, err := funcParamValidator.NewValidator("addcslashes").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$characters", []string{"string"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := args[0].(*values.Str).Value
+	mask := charMask("addcslashes", args[1].(*values.Str).Value, context)
+
+	var result goStrings.Builder
+	for i := 0; i < len(input); i++ {
+		char := input[i]
+		if !mask[char] {
+			result.WriteByte(char)
+			continue
+		}
+		result.WriteByte('\\')
+		if char < 32 || char > 126 {
+			// Non-printable characters are converted to C-style or octal representation
+			switch char {
+			case '\a':
+				result.WriteByte('a')
+			case '\b':
+				result.WriteByte('b')
+			case '\t':
+				result.WriteByte('t')
+			case '\n':
+				result.WriteByte('n')
+			case '\v':
+				result.WriteByte('v')
+			case '\f':
+				result.WriteByte('f')
+			case '\r':
+				result.WriteByte('r')
+			default:
+				result.WriteString(fmt.Sprintf("%03o", char))
+			}
+			continue
+		}
+		result.WriteByte(char)
+	}
+
+	return values.NewStr(result.String()), nil
+}
+
+// Get the characters contained in the list. Ranges can be specified with ".." (e.g. "a..z").
+func charMask(functionName string, characters string, context runtime.Context) [256]bool {
+	var mask [256]bool
+	for i := 0; i < len(characters); i++ {
+		char := characters[i]
+		// Range (e.g. "a..z")
+		if i+3 < len(characters) && characters[i+1] == '.' && characters[i+2] == '.' && characters[i+3] >= char {
+			for c := int(char); c <= int(characters[i+3]); c++ {
+				mask[c] = true
+			}
+			i += 3
+			continue
+		}
+
+		if i+1 < len(characters) && characters[i] == '.' && characters[i+1] == '.' {
+			// Error, try to be as helpful as possible
+			var message string
+			switch {
+			case i == 0:
+				message = "Invalid '..'-range, no character to the left of '..'"
+			case i+2 >= len(characters):
+				message = "Invalid '..'-range, no character to the right of '..'"
+			case characters[i-1] > characters[i+2]:
+				message = "Invalid '..'-range, '..'-range needs to be incrementing"
+			default:
+				message = "Invalid '..'-range"
+			}
+			context.Interpreter.PrintError(phpError.NewWarning("%s(): %s%s", functionName, message, context.InPosition()))
+			continue
+		}
+
+		mask[char] = true
+	}
+	return mask
+}
+
+// -------------------------------------- addslashes -------------------------------------- MARK: addslashes
+
+func nativeFn_addslashes(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.addslashes.php
+	args, err := funcParamValidator.NewValidator("addslashes").AddParam("$string", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.addslashes.php
+	// The characters to be escaped are: single quote ('), double quote ("), backslash (\), NUL (the NUL byte)
+	input := args[0].(*values.Str).Value
+	var result goStrings.Builder
+	for i := 0; i < len(input); i++ {
+		switch input[i] {
+		case '\'', '"', '\\':
+			result.WriteByte('\\')
+			result.WriteByte(input[i])
+		case 0:
+			result.WriteString(`\0`)
+		default:
+			result.WriteByte(input[i])
+		}
+	}
+
+	return values.NewStr(result.String()), nil
 }
 
 // -------------------------------------- bin2hex -------------------------------------- MARK: bin2hex
@@ -108,7 +295,148 @@ func nativeFn_chr(args []values.RuntimeValue, _ runtime.Context) (values.Runtime
 	}
 	codepoint %= 256
 
-	return values.NewStr(string(rune(codepoint))), nil
+	return values.NewStr(string([]byte{byte(codepoint)})), nil
+}
+
+// -------------------------------------- chunk_split -------------------------------------- MARK: chunk_split
+
+func nativeFn_chunk_split(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.chunk-split.php
+	args, err := funcParamValidator.NewValidator("chunk_split").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$length", []string{"int"}, values.NewInt(76)).
+		AddParam("$separator", []string{"string"}, values.NewStr("\r\n")).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := args[0].(*values.Str).Value
+	length := int(args[1].(*values.Int).Value)
+	separator := args[2].(*values.Str).Value
+
+	if length < 1 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: chunk_split(): Argument #2 ($length) must be greater than 0")
+	}
+
+	var result goStrings.Builder
+	for start := 0; start < len(input); start += length {
+		result.WriteString(input[start:min(start+length, len(input))])
+		result.WriteString(separator)
+	}
+
+	return values.NewStr(result.String()), nil
+}
+
+// -------------------------------------- count_chars -------------------------------------- MARK: count_chars
+
+func nativeFn_count_chars(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.count-chars.php
+	args, err := funcParamValidator.NewValidator("count_chars").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$mode", []string{"int"}, values.NewInt(0)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := args[0].(*values.Str).Value
+	mode := args[1].(*values.Int).Value
+
+	if mode < 0 || mode > 4 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: count_chars(): Argument #2 ($mode) must be between 0 and 4 (inclusive)")
+	}
+
+	var counts [256]int64
+	for i := 0; i < len(input); i++ {
+		counts[input[i]]++
+	}
+
+	// Spec: https://www.php.net/manual/en/function.count-chars.php
+	// 0 - an array with the byte-value as key and the frequency of every byte as value.
+	// 1 - same as 0 but only byte-values with a frequency greater than zero are listed.
+	// 2 - same as 0 but only byte-values with a frequency equal to zero are listed.
+	// 3 - a string containing all unique characters is returned.
+	// 4 - a string containing all not used characters is returned.
+	if mode == 3 || mode == 4 {
+		var result goStrings.Builder
+		for char, count := range counts {
+			if (count > 0) == (mode == 3) {
+				result.WriteByte(byte(char))
+			}
+		}
+		return values.NewStr(result.String()), nil
+	}
+
+	result := values.NewArray()
+	for char, count := range counts {
+		if mode == 0 || (mode == 1 && count > 0) || (mode == 2 && count == 0) {
+			if err := result.SetElement(values.NewInt(int64(char)), values.NewInt(count)); err != nil {
+				return values.NewVoid(), err
+			}
+		}
+	}
+	return result, nil
+}
+
+// -------------------------------------- crc32 -------------------------------------- MARK: crc32
+
+func nativeFn_crc32(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.crc32.php
+	args, err := funcParamValidator.NewValidator("crc32").AddParam("$string", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(int64(crc32.ChecksumIEEE([]byte(args[0].(*values.Str).Value)))), nil
+}
+
+// -------------------------------------- explode -------------------------------------- MARK: explode
+
+func nativeFn_explode(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.explode.php
+	args, err := funcParamValidator.NewValidator("explode").
+		AddParam("$separator", []string{"string"}, nil).
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$limit", []string{"int"}, values.NewInt(math.MaxInt64)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	separator := args[0].(*values.Str).Value
+	input := args[1].(*values.Str).Value
+	limit := args[2].(*values.Int).Value
+
+	if separator == "" {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: explode(): Argument #1 ($separator) cannot be empty")
+	}
+
+	var parts []string
+	switch {
+	case limit > 0:
+		// Spec: https://www.php.net/manual/en/function.explode.php
+		// If limit is set and positive, the returned array will contain a maximum of limit elements
+		// with the last element containing the rest of string.
+		parts = goStrings.SplitN(input, separator, int(min(limit, math.MaxInt32)))
+	case limit < 0:
+		// Spec: https://www.php.net/manual/en/function.explode.php
+		// If the limit parameter is negative, all components except the last -limit are returned.
+		parts = goStrings.Split(input, separator)
+		parts = parts[:max(int64(len(parts))+limit, 0)]
+	default:
+		// Spec: https://www.php.net/manual/en/function.explode.php
+		// If the limit parameter is zero, then this is treated as 1.
+		parts = []string{input}
+	}
+
+	result := values.NewArray()
+	for _, part := range parts {
+		if err := result.SetElement(nil, values.NewStr(part)); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
 }
 
 // -------------------------------------- hex2bin -------------------------------------- MARK: hex2bin
@@ -225,6 +553,41 @@ func nativeFn_lcfirst(args []values.RuntimeValue, _ runtime.Context) (values.Run
 	return values.NewStr(input), nil
 }
 
+// -------------------------------------- ltrim -------------------------------------- MARK: ltrim
+
+func nativeFn_ltrim(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.ltrim.php
+	return trimString("ltrim", args, context, true, false)
+}
+
+// Strip the characters from the beginning and/or end of the string
+func trimString(functionName string, args []values.RuntimeValue, context runtime.Context, left bool, right bool) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$characters", []string{"string"}, values.NewStr(" \n\r\t\v\x00")).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := args[0].(*values.Str).Value
+	mask := charMask(functionName, args[1].(*values.Str).Value, context)
+
+	start, end := 0, len(input)
+	if left {
+		for start < end && mask[input[start]] {
+			start++
+		}
+	}
+	if right {
+		for end > start && mask[input[end-1]] {
+			end--
+		}
+	}
+
+	return values.NewStr(input[start:end]), nil
+}
+
 // -------------------------------------- md5 -------------------------------------- MARK: md5
 
 func nativeFn_md5(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -279,6 +642,61 @@ func nativeFn_nl2br(args []values.RuntimeValue, _ runtime.Context) (values.Runti
 	return values.NewStr(result), nil
 }
 
+// -------------------------------------- ord -------------------------------------- MARK: ord
+
+func nativeFn_ord(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.ord.php
+	args, err := funcParamValidator.NewValidator("ord").AddParam("$character", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := args[0].(*values.Str).Value
+	if input == "" {
+		return values.NewInt(0), nil
+	}
+	return values.NewInt(int64(input[0])), nil
+}
+
+// -------------------------------------- parse_str -------------------------------------- MARK: parse_str
+
+func nativeFn_parse_str(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.parse-str.php
+	args, err := funcParamValidator.NewValidator("parse_str").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$result", []string{"mixed"}, nil).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	ini := context.Interpreter.GetIni()
+	separators := ini.GetStr("arg_separator.input")
+	maxInputVars := ini.GetInt("max_input_vars")
+
+	result := values.NewArray()
+	// Spec: https://www.php.net/manual/en/ini.core.php#ini.arg-separator.input
+	// Every character in this directive is considered as separator!
+	variables := goStrings.FieldsFunc(args[0].(*values.Str).Value, func(char rune) bool {
+		return goStrings.ContainsRune(separators, char)
+	})
+	for index, variable := range variables {
+		if int64(index) >= maxInputVars {
+			context.Interpreter.PrintError(phpError.NewWarning(
+				"parse_str(): Input variables exceeded %d. To increase the limit change max_input_vars in php.ini.%s", maxInputVars, context.InPosition(),
+			))
+			break
+		}
+		key, value, _ := goStrings.Cut(variable, "=")
+		if err := url.AddQueryVariable(result, key, value, ini.GetInt("max_input_nesting_level")); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+
+	context.SetRefArg(1, result)
+	return values.NewVoid(), nil
+}
+
 // -------------------------------------- quotemeta -------------------------------------- MARK: quotemeta
 
 func nativeFn_quotemeta(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -306,6 +724,13 @@ func nativeFn_quotemeta(args []values.RuntimeValue, _ runtime.Context) (values.R
 	return values.NewStr(output.String()), nil
 }
 
+// -------------------------------------- rtrim -------------------------------------- MARK: rtrim
+
+func nativeFn_rtrim(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.rtrim.php
+	return trimString("rtrim", args, context, false, true)
+}
+
 // -------------------------------------- sha1 -------------------------------------- MARK: sha1
 
 func nativeFn_sha1(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -369,6 +794,65 @@ func nativeFn_str_ends_with(args []values.RuntimeValue, _ runtime.Context) (valu
 	return values.NewBool(goStrings.HasSuffix(haystack, needle)), nil
 }
 
+// -------------------------------------- str_ireplace -------------------------------------- MARK: str_ireplace
+
+func nativeFn_str_ireplace(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.str-ireplace.php
+	return replaceStrings("str_ireplace", args, context, true)
+}
+
+// -------------------------------------- str_pad -------------------------------------- MARK: str_pad
+
+const (
+	STR_PAD_LEFT  int64 = 0
+	STR_PAD_RIGHT int64 = 1
+	STR_PAD_BOTH  int64 = 2
+)
+
+func nativeFn_str_pad(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.str-pad.php
+	args, err := funcParamValidator.NewValidator("str_pad").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$length", []string{"int"}, nil).
+		AddParam("$pad_string", []string{"string"}, values.NewStr(" ")).
+		AddParam("$pad_type", []string{"int"}, values.NewInt(STR_PAD_RIGHT)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := args[0].(*values.Str).Value
+	length := int(args[1].(*values.Int).Value)
+	padString := args[2].(*values.Str).Value
+	padType := args[3].(*values.Int).Value
+
+	// Spec: https://www.php.net/manual/en/function.str-pad.php
+	// If the value of length is negative, less than, or equal to the length of the input string, no padding takes place
+	if length <= len(input) {
+		return values.NewStr(input), nil
+	}
+	if padString == "" {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: str_pad(): Argument #3 ($pad_string) must be a non-empty string")
+	}
+	if padType != STR_PAD_LEFT && padType != STR_PAD_RIGHT && padType != STR_PAD_BOTH {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: str_pad(): Argument #4 ($pad_type) must be STR_PAD_LEFT, STR_PAD_RIGHT, or STR_PAD_BOTH")
+	}
+
+	padding := func(length int) string {
+		return goStrings.Repeat(padString, length/len(padString)+1)[:length]
+	}
+
+	padLength := length - len(input)
+	switch padType {
+	case STR_PAD_LEFT:
+		return values.NewStr(padding(padLength) + input), nil
+	case STR_PAD_BOTH:
+		return values.NewStr(padding(padLength/2) + input + padding(padLength-padLength/2)), nil
+	default:
+		return values.NewStr(input + padding(padLength)), nil
+	}
+}
+
 // -------------------------------------- str_repeat -------------------------------------- MARK: str_repeat
 
 func nativeFn_str_repeat(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -395,6 +879,203 @@ func nativeFn_str_repeat(args []values.RuntimeValue, _ runtime.Context) (values.
 	return values.NewStr(goStrings.Repeat(input, int(times))), nil
 }
 
+// -------------------------------------- str_replace -------------------------------------- MARK: str_replace
+
+func nativeFn_str_replace(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.str-replace.php
+	return replaceStrings("str_replace", args, context, false)
+}
+
+// Replace all occurrences of the search string(s) with the replacement string(s) in the subject(s)
+func replaceStrings(functionName string, args []values.RuntimeValue, context runtime.Context, ignoreCase bool) (values.RuntimeValue, phpError.Error) {
+	args, err := funcParamValidator.NewValidator(functionName).
+		AddParam("$search", []string{"array", "string"}, nil).
+		AddParam("$replace", []string{"array", "string"}, nil).
+		AddParam("$subject", []string{"array", "string"}, nil).
+		AddParam("$count", []string{"mixed"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	search := args[0]
+	replace := args[1]
+	subject := args[2]
+
+	if search.GetType() == values.StrValue && replace.GetType() == values.ArrayValue {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught TypeError: %s(): Argument #2 ($replace) must be of type string when argument #1 ($search) is a string", functionName,
+		)
+	}
+
+	// Get the pairs of search and replacement strings
+	var searches, replacements []string
+	if search.GetType() == values.StrValue {
+		searches = []string{search.(*values.Str).Value}
+		replacements = []string{replace.(*values.Str).Value}
+	} else {
+		var replaceArray []values.RuntimeValue
+		if replace.GetType() == values.ArrayValue {
+			replaceArray = arrayToSlice(replace.(*values.Array))
+		}
+		for index, searchValue := range arrayToSlice(search.(*values.Array)) {
+			searchStr, err := variableHandling.StrVal(searchValue)
+			if err != nil {
+				return values.NewVoid(), err
+			}
+			// Spec: https://www.php.net/manual/en/function.str-replace.php
+			// If replace has fewer values than search, then an empty string is used for the rest of replacement values.
+			replacement := ""
+			if replace.GetType() == values.StrValue {
+				replacement = replace.(*values.Str).Value
+			} else if index < len(replaceArray) {
+				replacement, err = variableHandling.StrVal(replaceArray[index])
+				if err != nil {
+					return values.NewVoid(), err
+				}
+			}
+			searches = append(searches, searchStr)
+			replacements = append(replacements, replacement)
+		}
+	}
+
+	count := int64(0)
+	replaceInSubject := func(subject string) string {
+		for index, search := range searches {
+			if search == "" {
+				continue
+			}
+			var replaced int
+			subject, replaced = replaceString(subject, search, replacements[index], ignoreCase)
+			count += int64(replaced)
+		}
+		return subject
+	}
+
+	var result values.RuntimeValue
+	if subject.GetType() == values.StrValue {
+		result = values.NewStr(replaceInSubject(subject.(*values.Str).Value))
+	} else {
+		array := values.NewArray()
+		subjectArray := subject.(*values.Array)
+		for _, key := range subjectArray.Keys {
+			slot, _ := subjectArray.GetElement(key)
+			value := slot.Value
+			// Spec: https://www.php.net/manual/en/function.str-replace.php
+			// If subject is an array, then the search and replace is performed with every entry of subject
+			if value.GetType() != values.ArrayValue && value.GetType() != values.ObjectValue {
+				str, err := variableHandling.StrVal(value)
+				if err != nil {
+					return values.NewVoid(), err
+				}
+				value = values.NewStr(replaceInSubject(str))
+			}
+			if err := array.SetElement(key, value); err != nil {
+				return values.NewVoid(), err
+			}
+		}
+		result = array
+	}
+
+	context.SetRefArg(3, values.NewInt(count))
+	return result, nil
+}
+
+// Replace all occurrences of search in subject and return the number of replacements
+func replaceString(subject string, search string, replacement string, ignoreCase bool) (string, int) {
+	if !ignoreCase {
+		count := goStrings.Count(subject, search)
+		if count == 0 {
+			return subject, 0
+		}
+		return goStrings.ReplaceAll(subject, search, replacement), count
+	}
+
+	lowerSubject := asciiToLower(subject)
+	lowerSearch := asciiToLower(search)
+	var result goStrings.Builder
+	count := 0
+	start := 0
+	for {
+		pos := goStrings.Index(lowerSubject[start:], lowerSearch)
+		if pos == -1 {
+			break
+		}
+		result.WriteString(subject[start : start+pos])
+		result.WriteString(replacement)
+		start += pos + len(search)
+		count++
+	}
+	result.WriteString(subject[start:])
+	return result.String(), count
+}
+
+// -------------------------------------- str_rot13 -------------------------------------- MARK: str_rot13
+
+func nativeFn_str_rot13(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.str-rot13.php
+	args, err := funcParamValidator.NewValidator("str_rot13").AddParam("$string", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := []byte(args[0].(*values.Str).Value)
+	for i, char := range input {
+		switch {
+		case char >= 'a' && char <= 'z':
+			input[i] = 'a' + (char-'a'+13)%26
+		case char >= 'A' && char <= 'Z':
+			input[i] = 'A' + (char-'A'+13)%26
+		}
+	}
+
+	return values.NewStr(string(input)), nil
+}
+
+// -------------------------------------- str_shuffle -------------------------------------- MARK: str_shuffle
+
+func nativeFn_str_shuffle(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.str-shuffle.php
+	args, err := funcParamValidator.NewValidator("str_shuffle").AddParam("$string", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	result, err := random.ShuffleBytes(args[0].(*values.Str).Value, context)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+	return values.NewStr(result), nil
+}
+
+// -------------------------------------- str_split -------------------------------------- MARK: str_split
+
+func nativeFn_str_split(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.str-split.php
+	args, err := funcParamValidator.NewValidator("str_split").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$length", []string{"int"}, values.NewInt(1)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := args[0].(*values.Str).Value
+	length := int(args[1].(*values.Int).Value)
+
+	if length < 1 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: str_split(): Argument #2 ($length) must be greater than 0")
+	}
+
+	result := values.NewArray()
+	for start := 0; start < len(input); start += length {
+		if err := result.SetElement(nil, values.NewStr(input[start:min(start+length, len(input))])); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
 // -------------------------------------- str_starts_with -------------------------------------- MARK: str_starts_with
 
 func nativeFn_str_starts_with(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -414,6 +1095,171 @@ func nativeFn_str_starts_with(args []values.RuntimeValue, _ runtime.Context) (va
 	return values.NewBool(goStrings.HasPrefix(haystack, needle)), nil
 }
 
+// -------------------------------------- str_word_count -------------------------------------- MARK: str_word_count
+
+func nativeFn_str_word_count(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.str-word-count.php
+	args, err := funcParamValidator.NewValidator("str_word_count").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$format", []string{"int"}, values.NewInt(0)).
+		AddParam("$characters", []string{"string", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := args[0].(*values.Str).Value
+	format := args[1].(*values.Int).Value
+	var mask [256]bool
+	if args[2].GetType() == values.StrValue {
+		mask = charMask("str_word_count", args[2].(*values.Str).Value, context)
+	}
+
+	if format < 0 || format > 2 {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: str_word_count(): Argument #2 ($format) must be a valid format value")
+	}
+
+	// Spec: https://www.php.net/manual/en/function.str-word-count.php
+	// A 'word' is defined as a locale dependent string containing alphabetic characters,
+	// which also may contain, but not start with "'" and "-" characters.
+	start, end := 0, len(input)
+	if start < end && ((input[start] == '\'' && !mask['\'']) || (input[start] == '-' && !mask['-'])) {
+		start++
+	}
+	if start < end && input[end-1] == '-' && !mask['-'] {
+		end--
+	}
+
+	isWordChar := func(char byte) bool {
+		return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || mask[char] || char == '\'' || char == '-'
+	}
+
+	result := values.NewArray()
+	count := int64(0)
+	for pos := start; pos < end; {
+		wordStart := pos
+		for pos < end && isWordChar(input[pos]) {
+			pos++
+		}
+		if pos > wordStart {
+			var err phpError.Error
+			switch format {
+			case 1:
+				err = result.SetElement(nil, values.NewStr(input[wordStart:pos]))
+			case 2:
+				err = result.SetElement(values.NewInt(int64(wordStart)), values.NewStr(input[wordStart:pos]))
+			default:
+				count++
+			}
+			if err != nil {
+				return values.NewVoid(), err
+			}
+		} else {
+			pos++
+		}
+	}
+
+	if format == 0 {
+		return values.NewInt(count), nil
+	}
+	return result, nil
+}
+
+// -------------------------------------- stripcslashes -------------------------------------- MARK: stripcslashes
+
+func nativeFn_stripcslashes(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.stripcslashes.php
+	args, err := funcParamValidator.NewValidator("stripcslashes").AddParam("$string", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.stripcslashes.php
+	// Recognizes C-like \n, \r ..., octal and hexadecimal representation.
+	input := args[0].(*values.Str).Value
+	var result goStrings.Builder
+	for i := 0; i < len(input); i++ {
+		if input[i] != '\\' || i+1 >= len(input) {
+			result.WriteByte(input[i])
+			continue
+		}
+		i++
+		switch input[i] {
+		case 'n':
+			result.WriteByte('\n')
+		case 't':
+			result.WriteByte('\t')
+		case 'r':
+			result.WriteByte('\r')
+		case 'a':
+			result.WriteByte('\a')
+		case 'v':
+			result.WriteByte('\v')
+		case 'b':
+			result.WriteByte('\b')
+		case 'f':
+			result.WriteByte('\f')
+		case 'x':
+			if i+1 < len(input) && isDigitOfBase(input[i+1], 16) {
+				end := i + 2
+				if end < len(input) && isDigitOfBase(input[end], 16) {
+					end++
+				}
+				value, _ := strconv.ParseUint(input[i+1:end], 16, 8)
+				result.WriteByte(byte(value))
+				i = end - 1
+			} else {
+				result.WriteByte('x')
+			}
+		default:
+			if isDigitOfBase(input[i], 8) {
+				end := i + 1
+				for end < len(input) && end < i+3 && isDigitOfBase(input[end], 8) {
+					end++
+				}
+				value, _ := strconv.ParseUint(input[i:end], 8, 16)
+				result.WriteByte(byte(value))
+				i = end - 1
+			} else {
+				result.WriteByte(input[i])
+			}
+		}
+	}
+
+	return values.NewStr(result.String()), nil
+}
+
+// -------------------------------------- stripslashes -------------------------------------- MARK: stripslashes
+
+func nativeFn_stripslashes(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.stripslashes.php
+	args, err := funcParamValidator.NewValidator("stripslashes").AddParam("$string", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Spec: https://www.php.net/manual/en/function.stripslashes.php
+	// Backslashes are removed. (\\ are made into a single backslash and \0 into the NUL byte.)
+	input := args[0].(*values.Str).Value
+	var result goStrings.Builder
+	for i := 0; i < len(input); i++ {
+		if input[i] != '\\' {
+			result.WriteByte(input[i])
+			continue
+		}
+		i++
+		if i < len(input) {
+			if input[i] == '0' {
+				result.WriteByte(0)
+			} else {
+				result.WriteByte(input[i])
+			}
+		}
+	}
+
+	return values.NewStr(result.String()), nil
+}
+
 // -------------------------------------- strlen -------------------------------------- MARK: strlen
 
 func nativeFn_strlen(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -427,6 +1273,23 @@ func nativeFn_strlen(args []values.RuntimeValue, _ runtime.Context) (values.Runt
 	return values.NewInt(int64(len(args[0].(*values.Str).Value))), nil
 }
 
+// -------------------------------------- strrev -------------------------------------- MARK: strrev
+
+func nativeFn_strrev(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strrev.php
+	args, err := funcParamValidator.NewValidator("strrev").AddParam("$string", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := []byte(args[0].(*values.Str).Value)
+	for i, j := 0, len(input)-1; i < j; i, j = i+1, j-1 {
+		input[i], input[j] = input[j], input[i]
+	}
+
+	return values.NewStr(string(input)), nil
+}
+
 // -------------------------------------- strtolower -------------------------------------- MARK: strtolower
 
 func nativeFn_strtolower(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -475,6 +1338,100 @@ func nativeFn_strtoupper(args []values.RuntimeValue, _ runtime.Context) (values.
 	return values.NewStr(input), nil
 }
 
+// -------------------------------------- strtr -------------------------------------- MARK: strtr
+
+func nativeFn_strtr(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.strtr.php
+	args, err := funcParamValidator.NewValidator("strtr").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$from", []string{"array", "string"}, nil).
+		AddParam("$to", []string{"string", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := args[0].(*values.Str).Value
+
+	// strtr(string $string, array $replace_pairs): string
+	if args[2].GetType() == values.NullValue {
+		if args[1].GetType() != values.ArrayValue {
+			return values.NewVoid(), phpError.NewError("Uncaught TypeError: strtr(): Argument #2 ($from) must be of type array, string given")
+		}
+		pairs := map[string]string{}
+		lengths := []int{}
+		replacePairs := args[1].(*values.Array)
+		for _, key := range replacePairs.Keys {
+			from, err := variableHandling.StrVal(key)
+			if err != nil {
+				return values.NewVoid(), err
+			}
+			// Spec: https://www.php.net/manual/en/function.strtr.php
+			// If replace_pairs contains a key which is an empty string (""), the element is ignored
+			if from == "" {
+				continue
+			}
+			slot, _ := replacePairs.GetElement(key)
+			to, err := variableHandling.StrVal(slot.Value)
+			if err != nil {
+				return values.NewVoid(), err
+			}
+			pairs[from] = to
+			if !slices.Contains(lengths, len(from)) {
+				lengths = append(lengths, len(from))
+			}
+		}
+		// The longest keys are tried first
+		slices.Sort(lengths)
+		slices.Reverse(lengths)
+
+		// Spec: https://www.php.net/manual/en/function.strtr.php
+		// Once a substring has been replaced, its new value will not be searched again.
+		var result goStrings.Builder
+		for pos := 0; pos < len(input); {
+			replaced := false
+			for _, length := range lengths {
+				if pos+length > len(input) {
+					continue
+				}
+				if to, found := pairs[input[pos:pos+length]]; found {
+					result.WriteString(to)
+					pos += length
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				result.WriteByte(input[pos])
+				pos++
+			}
+		}
+		return values.NewStr(result.String()), nil
+	}
+
+	// strtr(string $string, string $from, string $to): string
+	if args[1].GetType() != values.StrValue {
+		return values.NewVoid(), phpError.NewError("Uncaught TypeError: strtr(): Argument #2 ($from) must be of type string, array given")
+	}
+	from := args[1].(*values.Str).Value
+	to := args[2].(*values.Str).Value
+
+	// Spec: https://www.php.net/manual/en/function.strtr.php
+	// If from and to have different lengths, the extra characters in the longer of the two are ignored.
+	var translation [256]byte
+	for i := range translation {
+		translation[i] = byte(i)
+	}
+	for i := 0; i < min(len(from), len(to)); i++ {
+		translation[from[i]] = to[i]
+	}
+	result := []byte(input)
+	for i, char := range result {
+		result[i] = translation[char]
+	}
+	return values.NewStr(string(result)), nil
+}
+
 // -------------------------------------- substr -------------------------------------- MARK: substr
 
 func nativeFn_substr(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -532,6 +1489,114 @@ func nativeFn_substr(args []values.RuntimeValue, _ runtime.Context) (values.Runt
 	return values.NewStr(input[offset:end]), nil
 }
 
+// -------------------------------------- substr_replace -------------------------------------- MARK: substr_replace
+
+func nativeFn_substr_replace(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.substr-replace.php
+	args, err := funcParamValidator.NewValidator("substr_replace").
+		AddParam("$string", []string{"array", "string"}, nil).
+		AddParam("$replace", []string{"array", "string"}, nil).
+		AddParam("$offset", []string{"array", "int"}, nil).
+		AddParam("$length", []string{"array", "int", "null"}, values.NewNull()).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Get the n-th value of an array argument or the argument itself if it is not an array.
+	// ok is false if the array has not enough elements.
+	nthValue := func(value values.RuntimeValue, index int) (values.RuntimeValue, bool) {
+		if value.GetType() != values.ArrayValue {
+			return value, true
+		}
+		elements := arrayToSlice(value.(*values.Array))
+		if index < len(elements) {
+			return elements[index], true
+		}
+		return nil, false
+	}
+
+	replaceOne := func(input string, index int) (string, phpError.Error) {
+		replacement := ""
+		if value, ok := nthValue(args[1], index); ok {
+			var err phpError.Error
+			if replacement, err = variableHandling.StrVal(value); err != nil {
+				return "", err
+			}
+		}
+
+		offset := int64(0)
+		if value, ok := nthValue(args[2], index); ok {
+			var err phpError.Error
+			if offset, err = variableHandling.IntVal(value, false); err != nil {
+				return "", err
+			}
+		}
+
+		length := int64(len(input))
+		if value, ok := nthValue(args[3], index); ok && value.GetType() != values.NullValue {
+			var err phpError.Error
+			if length, err = variableHandling.IntVal(value, false); err != nil {
+				return "", err
+			}
+		}
+
+		return substrReplace(input, replacement, int(offset), int(length)), nil
+	}
+
+	if args[0].GetType() == values.StrValue {
+		if args[2].GetType() == values.ArrayValue {
+			return values.NewVoid(), phpError.NewError("Uncaught TypeError: substr_replace(): Argument #3 ($offset) cannot be an array when working on a single string")
+		}
+		if args[3].GetType() == values.ArrayValue {
+			return values.NewVoid(), phpError.NewError("Uncaught TypeError: substr_replace(): Argument #4 ($length) cannot be an array when working on a single string")
+		}
+		result, err := replaceOne(args[0].(*values.Str).Value, 0)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		return values.NewStr(result), nil
+	}
+
+	result := values.NewArray()
+	inputs := args[0].(*values.Array)
+	for index, key := range inputs.Keys {
+		slot, _ := inputs.GetElement(key)
+		input, err := variableHandling.StrVal(slot.Value)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		replaced, err := replaceOne(input, index)
+		if err != nil {
+			return values.NewVoid(), err
+		}
+		if err := result.SetElement(key, values.NewStr(replaced)); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// Replace the part of the string selected by offset and length (like substr) with the replacement
+func substrReplace(input string, replacement string, offset int, length int) string {
+	if offset < 0 {
+		offset = max(len(input)+offset, 0)
+	}
+	offset = min(offset, len(input))
+	if length < 0 {
+		length = max(len(input)-offset+length, 0)
+	}
+	length = min(length, len(input)-offset)
+	return input[:offset] + replacement + input[offset+length:]
+}
+
+// -------------------------------------- trim -------------------------------------- MARK: trim
+
+func nativeFn_trim(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.trim.php
+	return trimString("trim", args, context, true, true)
+}
+
 // -------------------------------------- ucfirst -------------------------------------- MARK: ucfirst
 
 func nativeFn_ucfirst(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -558,86 +1623,119 @@ func nativeFn_ucfirst(args []values.RuntimeValue, _ runtime.Context) (values.Run
 	return values.NewStr(input), nil
 }
 
-// TODO addcslashes
-// TODO addslashes
-// TODO chop
-// TODO chunk_split
+// -------------------------------------- ucwords -------------------------------------- MARK: ucwords
+
+func nativeFn_ucwords(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.ucwords.php
+	args, err := funcParamValidator.NewValidator("ucwords").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$separators", []string{"string"}, values.NewStr(" \t\r\n\f\v")).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := []byte(args[0].(*values.Str).Value)
+	separators := args[1].(*values.Str).Value
+
+	// Spec: https://www.php.net/manual/en/function.ucwords.php
+	// Returns a string with the first character of each word in string capitalized, if that character is an ASCII character between "a" (0x61) and "z" (0x7a).
+	for i := range input {
+		if i == 0 || goStrings.IndexByte(separators, input[i-1]) != -1 {
+			input[i] = common.ToUpper(input[i])
+		}
+	}
+
+	return values.NewStr(string(input)), nil
+}
+
+// -------------------------------------- wordwrap -------------------------------------- MARK: wordwrap
+
+func nativeFn_wordwrap(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.wordwrap.php
+	args, err := funcParamValidator.NewValidator("wordwrap").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$width", []string{"int"}, values.NewInt(75)).
+		AddParam("$break", []string{"string"}, values.NewStr("\n")).
+		AddParam("$cut_long_words", []string{"bool"}, values.NewBool(false)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := args[0].(*values.Str).Value
+	width := int(args[1].(*values.Int).Value)
+	breakStr := args[2].(*values.Str).Value
+	cut := args[3].(*values.Bool).Value
+
+	if input == "" {
+		return values.NewStr(""), nil
+	}
+	if breakStr == "" {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: wordwrap(): Argument #3 ($break) cannot be empty")
+	}
+	if width == 0 && cut {
+		return values.NewVoid(), phpError.NewError("Uncaught ValueError: wordwrap(): Argument #2 ($width) cannot be 0 when argument #4 ($cut_long_words) is true")
+	}
+
+	// Port of PHP's algorithm: Existing breaks are kept, spaces at the line boundary are replaced with a break
+	// and words longer than the width are cut if requested.
+	var result goStrings.Builder
+	lastStart, lastSpace := 0, 0
+	current := 0
+	for ; current < len(input); current++ {
+		switch {
+		case input[current] == breakStr[0] && current+len(breakStr) < len(input) && input[current:current+len(breakStr)] == breakStr:
+			// Existing break
+			result.WriteString(input[lastStart : current+len(breakStr)])
+			current += len(breakStr) - 1
+			lastStart = current + 1
+			lastSpace = lastStart
+		case input[current] == ' ':
+			if current-lastStart >= width {
+				result.WriteString(input[lastStart:current])
+				result.WriteString(breakStr)
+				lastStart = current + 1
+			}
+			lastSpace = current
+		case current-lastStart >= width && cut && lastStart >= lastSpace:
+			// Cut a long word
+			result.WriteString(input[lastStart:current])
+			result.WriteString(breakStr)
+			lastStart = current
+			lastSpace = current
+		case current-lastStart >= width && lastStart < lastSpace:
+			// Break at the last space
+			result.WriteString(input[lastStart:lastSpace])
+			result.WriteString(breakStr)
+			lastSpace++
+			lastStart = lastSpace
+		}
+	}
+	if lastStart != current {
+		result.WriteString(input[lastStart:current])
+	}
+
+	return values.NewStr(result.String()), nil
+}
+
 // TODO convert_uudecode
 // TODO convert_uuencode
-// TODO count_hars
-// TODO crc32
 // TODO crypt
-// TODO explode
-// TODO fprintf
-// TODO get_html_translation_table
 // TODO hebrev
-// TODO html_entity_decode
-// TODO htmlentities
-// TODO htmlspecialchars
-// TODO htmlspecialchars_decode
-// TODO levenshtein
 // TODO localeconv
-// TODO ltrim
 // TODO md5_file
-// TODO metaphone
 // TODO money_format
 // TODO nl_langinfo
-// TODO number_format
-// TODO ord
-// TODO parse_str
-// TODO printf
 // TODO quoted_printable_decode
 // TODO quoted_printable_encode
-// TODO rtrim
 // TODO setlocale
 // TODO sha1_file
-// TODO similar_text
-// TODO soundex
-// TODO sprintf
-// TODO sscanf
 // TODO str_decrement
 // TODO str_getcsv
 // TODO str_increment
-// TODO str_ireplace
-// TODO str_pad
-// TODO str_replace
-// TODO str_rot13
-// TODO str_shuffle
-// TODO str_split
-// TODO str_word_count
-// TODO strcasecmp
-// TODO strchr
-// TODO strcmp
 // TODO strcoll
-// TODO strcspn
-// TODO strip_tags
-// TODO stripcslashes
-// TODO stripos
-// TODO stripslashes
-// TODO stristr
-// TODO strnatcasecmp
-// TODO strnatcmp
-// TODO strncasecmp
-// TODO strncmp
-// TODO strpbrk
-// TODO strpos
-// TODO strrchr
-// TODO strrev
-// TODO strripos
-// TODO strrpos
-// TODO strspn
-// TODO strstr
 // TODO strtok
-// TODO strtr
-// TODO substr_compare
-// TODO substr_count
-// TODO substr_replace
-// TODO trim
-// TODO ucwords
-// TODO vfprintf
-// TODO vprintf
-// TODO vsprintf
-// TODO wordwrap
 // Deprecated:
 // TODO convert_cyr_string
 // TODO hebrevc
@@ -0,0 +1,672 @@
+package url
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"QIQ/cmd/qiq/runtime/funcParamValidator"
+	"QIQ/cmd/qiq/runtime/stdlib/variableHandling"
+	"QIQ/cmd/qiq/runtime/values"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func Register(environment runtime.Environment) {
+	// Category: URL Functions
+	environment.AddNativeFunction("base64_decode", nativeFn_base64_decode)
+	environment.AddNativeFunction("base64_encode", nativeFn_base64_encode)
+	environment.AddNativeFunction("http_build_query", nativeFn_http_build_query)
+	environment.AddNativeFunction("parse_url", nativeFn_parse_url)
+	environment.AddNativeFunction("rawurldecode", nativeFn_rawurldecode)
+	environment.AddNativeFunction("rawurlencode", nativeFn_rawurlencode)
+	environment.AddNativeFunction("urldecode", nativeFn_urldecode)
+	environment.AddNativeFunction("urlencode", nativeFn_urlencode)
+
+	// Const Category: URL Constants
+	// Spec: https://www.php.net/manual/en/url.constants.php
+	environment.AddPredefinedConstant("PHP_URL_SCHEME", values.NewInt(PHP_URL_SCHEME))
+	environment.AddPredefinedConstant("PHP_URL_HOST", values.NewInt(PHP_URL_HOST))
+	environment.AddPredefinedConstant("PHP_URL_PORT", values.NewInt(PHP_URL_PORT))
+	environment.AddPredefinedConstant("PHP_URL_USER", values.NewInt(PHP_URL_USER))
+	environment.AddPredefinedConstant("PHP_URL_PASS", values.NewInt(PHP_URL_PASS))
+	environment.AddPredefinedConstant("PHP_URL_PATH", values.NewInt(PHP_URL_PATH))
+	environment.AddPredefinedConstant("PHP_URL_QUERY", values.NewInt(PHP_URL_QUERY))
+	environment.AddPredefinedConstant("PHP_URL_FRAGMENT", values.NewInt(PHP_URL_FRAGMENT))
+	environment.AddPredefinedConstant("PHP_QUERY_RFC1738", values.NewInt(PHP_QUERY_RFC1738))
+	environment.AddPredefinedConstant("PHP_QUERY_RFC3986", values.NewInt(PHP_QUERY_RFC3986))
+}
+
+const (
+	PHP_URL_SCHEME   int64 = 0
+	PHP_URL_HOST     int64 = 1
+	PHP_URL_PORT     int64 = 2
+	PHP_URL_USER     int64 = 3
+	PHP_URL_PASS     int64 = 4
+	PHP_URL_PATH     int64 = 5
+	PHP_URL_QUERY    int64 = 6
+	PHP_URL_FRAGMENT int64 = 7
+
+	PHP_QUERY_RFC1738 int64 = 1
+	PHP_QUERY_RFC3986 int64 = 2
+)
+
+// Encode the string like urlencode (raw = false) or rawurlencode (raw = true)
+func Encode(input string, raw bool) string {
+	const hexChars = "0123456789ABCDEF"
+	var result strings.Builder
+	for i := 0; i < len(input); i++ {
+		char := input[i]
+		switch {
+		case (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9') ||
+			char == '-' || char == '_' || char == '.':
+			result.WriteByte(char)
+		case char == '~' && raw:
+			result.WriteByte(char)
+		case char == ' ' && !raw:
+			result.WriteByte('+')
+		default:
+			result.WriteByte('%')
+			result.WriteByte(hexChars[char>>4])
+			result.WriteByte(hexChars[char&15])
+		}
+	}
+	return result.String()
+}
+
+// Decode the string like urldecode (raw = false) or rawurldecode (raw = true).
+// Invalid percent encodings are kept as they are.
+func Decode(input string, raw bool) string {
+	var result strings.Builder
+	for i := 0; i < len(input); i++ {
+		char := input[i]
+		if char == '+' && !raw {
+			result.WriteByte(' ')
+			continue
+		}
+		if char == '%' && i+2 < len(input) && isHexDigit(input[i+1]) && isHexDigit(input[i+2]) {
+			value, _ := strconv.ParseUint(input[i+1:i+3], 16, 8)
+			result.WriteByte(byte(value))
+			i += 2
+			continue
+		}
+		result.WriteByte(char)
+	}
+	return result.String()
+}
+
+func isHexDigit(char byte) bool {
+	return (char >= '0' && char <= '9') || (char >= 'a' && char <= 'f') || (char >= 'A' && char <= 'F')
+}
+
+// Decode the key and the value of a query variable (e.g. "a[b][]=c") and add it to the result.
+// The nested keys are created as arrays, unless the nesting level exceeds the given maximum.
+func AddQueryVariable(result *values.Array, key string, value string, maxNestingLevel int64) phpError.Error {
+	key = strings.TrimLeft(Decode(key, false), " ")
+	value = Decode(value, false)
+
+	// Split the key into the base name and the indices
+	baseName, indexPart, isArray := strings.Cut(key, "[")
+	indices := []string{}
+	for isArray {
+		index, rest, found := strings.Cut(indexPart, "]")
+		if !found {
+			// Not an index: The "[" is part of the name
+			if len(indices) == 0 {
+				isArray = false
+				baseName = key
+			}
+			break
+		}
+		indices = append(indices, index)
+		// Only directly following indices are used
+		if !strings.HasPrefix(rest, "[") {
+			break
+		}
+		indexPart = rest[1:]
+	}
+
+	// Spaces, dots and opening brackets are not allowed in the variable name
+	baseName = strings.NewReplacer(" ", "_", ".", "_", "[", "_").Replace(baseName)
+	if baseName == "" {
+		return nil
+	}
+
+	if len(indices)+1 >= int(maxNestingLevel) {
+		return nil
+	}
+
+	var current = result
+	var currentKey values.RuntimeValue = values.NewStr(baseName)
+	for _, index := range indices {
+		// Create or replace the element with an array
+		var next *values.Array
+		if slot, found := current.GetElement(currentKey); found && slot.Value.GetType() == values.ArrayValue {
+			next = slot.Value.(*values.Array)
+		} else {
+			next = values.NewArray()
+			if err := current.SetElement(currentKey, next); err != nil {
+				return err
+			}
+		}
+		current = next
+		if index == "" {
+			currentKey = nil
+		} else {
+			currentKey = values.NewStr(index)
+		}
+	}
+	return current.SetElement(currentKey, values.NewStr(value))
+}
+
+// -------------------------------------- base64_decode -------------------------------------- MARK: base64_decode
+
+func nativeFn_base64_decode(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.base64-decode.php
+	args, err := funcParamValidator.NewValidator("base64_decode").
+		AddParam("$string", []string{"string"}, nil).
+		AddParam("$strict", []string{"bool"}, values.NewBool(false)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	input := args[0].(*values.Str).Value
+	strict := args[1].(*values.Bool).Value
+
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+	result := []byte{}
+	count := 0
+	padding := 0
+	for i := 0; i < len(input); i++ {
+		char := input[i]
+		if char == '=' {
+			padding++
+			continue
+		}
+		value := strings.IndexByte(alphabet, char)
+		if value == -1 {
+			// Spec: https://www.php.net/manual/en/function.base64-decode.php
+			// If the strict parameter is set to true then the base64_decode() function will return false
+			// if the input contains character from outside the base64 alphabet. Otherwise invalid characters will be silently discarded.
+			if !strict || char == ' ' || char == '\t' || char == '\r' || char == '\n' {
+				continue
+			}
+			return values.NewBool(false), nil
+		}
+		// No data is allowed after the padding in strict mode
+		if strict && padding > 0 {
+			return values.NewBool(false), nil
+		}
+
+		switch count % 4 {
+		case 0:
+			result = append(result, byte(value<<2))
+		case 1:
+			result[len(result)-1] |= byte(value >> 4)
+			result = append(result, byte(value<<4))
+		case 2:
+			result[len(result)-1] |= byte(value >> 2)
+			result = append(result, byte(value<<6))
+		case 3:
+			result[len(result)-1] |= byte(value)
+		}
+		count++
+	}
+
+	// The last byte is incomplete unless a group of four characters was finished
+	if count%4 != 0 {
+		result = result[:len(result)-1]
+	}
+
+	if strict {
+		// The input is truncated (only one character in the last group)
+		if count%4 == 1 {
+			return values.NewBool(false), nil
+		}
+		// The padding length is wrong (padding is optional)
+		if padding > 0 && (padding > 2 || (count+padding)%4 != 0) {
+			return values.NewBool(false), nil
+		}
+	}
+
+	return values.NewStr(string(result)), nil
+}
+
+// -------------------------------------- base64_encode -------------------------------------- MARK: base64_encode
+
+func nativeFn_base64_encode(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.base64-encode.php
+	args, err := funcParamValidator.NewValidator("base64_encode").AddParam("$string", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(base64.StdEncoding.EncodeToString([]byte(args[0].(*values.Str).Value))), nil
+}
+
+// -------------------------------------- http_build_query -------------------------------------- MARK: http_build_query
+
+func nativeFn_http_build_query(args []values.RuntimeValue, context runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.http-build-query.php
+	args, err := funcParamValidator.NewValidator("http_build_query").
+		AddParam("$data", []string{"array", "object"}, nil).
+		AddParam("$numeric_prefix", []string{"string"}, values.NewStr("")).
+		AddParam("$arg_separator", []string{"string", "null"}, values.NewNull()).
+		AddParam("$encoding_type", []string{"int"}, values.NewInt(PHP_QUERY_RFC1738)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	numericPrefix := args[1].(*values.Str).Value
+	separator := context.Interpreter.GetIni().GetStr("arg_separator.output")
+	if args[2].GetType() == values.StrValue {
+		separator = args[2].(*values.Str).Value
+	}
+	raw := args[3].(*values.Int).Value == PHP_QUERY_RFC3986
+
+	parts := []string{}
+	var buildQuery func(data values.RuntimeValue, prefix string) phpError.Error
+	buildQuery = func(data values.RuntimeValue, prefix string) phpError.Error {
+		keys, elements := queryElements(data)
+		for index, key := range keys {
+			value := elements[index]
+			if value.GetType() == values.NullValue {
+				continue
+			}
+
+			var keyStr string
+			if key.GetType() == values.IntValue {
+				keyStr = fmt.Sprintf("%d", key.(*values.Int).Value)
+				if prefix == "" {
+					keyStr = numericPrefix + keyStr
+				}
+			} else {
+				keyStr = key.(*values.Str).Value
+			}
+			keyStr = Encode(keyStr, raw)
+			if prefix != "" {
+				keyStr = prefix + "%5B" + keyStr + "%5D"
+			}
+
+			switch value.GetType() {
+			case values.ArrayValue, values.ObjectValue:
+				if err := buildQuery(value, keyStr); err != nil {
+					return err
+				}
+			case values.BoolValue:
+				if value.(*values.Bool).Value {
+					parts = append(parts, keyStr+"=1")
+				} else {
+					parts = append(parts, keyStr+"=0")
+				}
+			default:
+				str, err := variableHandling.StrVal(value)
+				if err != nil {
+					return err
+				}
+				parts = append(parts, keyStr+"="+Encode(str, raw))
+			}
+		}
+		return nil
+	}
+	if err := buildQuery(args[0], ""); err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(strings.Join(parts, separator)), nil
+}
+
+// Get the keys and values of an array or the public properties of an object
+func queryElements(data values.RuntimeValue) ([]values.RuntimeValue, []values.RuntimeValue) {
+	keys := []values.RuntimeValue{}
+	elements := []values.RuntimeValue{}
+	switch data := data.(type) {
+	case *values.Array:
+		for _, key := range data.Keys {
+			slot, _ := data.GetElement(key)
+			keys = append(keys, key)
+			elements = append(elements, slot.Value)
+		}
+	case *values.Object:
+		for _, property := range data.PropertyNames {
+			if propertyDecl, found := data.Class.Properties[property]; found && propertyDecl.Visibility != "public" {
+				continue
+			}
+			value, _ := data.GetProperty(property)
+			keys = append(keys, values.NewStr(strings.TrimPrefix(property, "$")))
+			elements = append(elements, value)
+		}
+	}
+	return keys, elements
+}
+
+// -------------------------------------- parse_url -------------------------------------- MARK: parse_url
+
+type parsedUrl struct {
+	scheme, host, user, pass, path, query, fragment *string
+	port                                            *int64
+}
+
+func nativeFn_parse_url(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.parse-url.php
+	args, err := funcParamValidator.NewValidator("parse_url").
+		AddParam("$url", []string{"string"}, nil).
+		AddParam("$component", []string{"int"}, values.NewInt(-1)).
+		Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	component := args[1].(*values.Int).Value
+	if component < -1 || component > PHP_URL_FRAGMENT {
+		return values.NewVoid(), phpError.NewError(
+			"Uncaught ValueError: parse_url(): Argument #2 ($component) must be a valid URL component identifier, %d given", component,
+		)
+	}
+
+	url, ok := parseUrl(args[0].(*values.Str).Value)
+	if !ok {
+		return values.NewBool(false), nil
+	}
+
+	components := []struct {
+		id    int64
+		name  string
+		value *string
+	}{
+		{PHP_URL_SCHEME, "scheme", url.scheme},
+		{PHP_URL_HOST, "host", url.host},
+		{PHP_URL_PORT, "port", nil},
+		{PHP_URL_USER, "user", url.user},
+		{PHP_URL_PASS, "pass", url.pass},
+		{PHP_URL_PATH, "path", url.path},
+		{PHP_URL_QUERY, "query", url.query},
+		{PHP_URL_FRAGMENT, "fragment", url.fragment},
+	}
+
+	if component != -1 {
+		if component == PHP_URL_PORT {
+			if url.port == nil {
+				return values.NewNull(), nil
+			}
+			return values.NewInt(*url.port), nil
+		}
+		if components[component].value == nil {
+			return values.NewNull(), nil
+		}
+		return values.NewStr(*components[component].value), nil
+	}
+
+	result := values.NewArray()
+	for _, component := range components {
+		var value values.RuntimeValue
+		if component.id == PHP_URL_PORT {
+			if url.port == nil {
+				continue
+			}
+			value = values.NewInt(*url.port)
+		} else {
+			if component.value == nil {
+				continue
+			}
+			value = values.NewStr(*component.value)
+		}
+		if err := result.SetElement(values.NewStr(component.name), value); err != nil {
+			return values.NewVoid(), err
+		}
+	}
+	return result, nil
+}
+
+// Port of PHP's php_url_parse_ex2
+func parseUrl(input string) (parsedUrl, bool) {
+	url := parsedUrl{}
+
+	// Control characters are replaced with "_"
+	part := func(value string) *string {
+		bytes := []byte(value)
+		for i, char := range bytes {
+			if char < 32 || char == 127 {
+				bytes[i] = '_'
+			}
+		}
+		result := string(bytes)
+		return &result
+	}
+	isDigit := func(char byte) bool { return char >= '0' && char <= '9' }
+	isRelativeScheme := func(s int) bool { return s+1 < len(input) && input[s] == '/' && input[s+1] == '/' }
+	parsePort := func(value string) (int64, bool) {
+		port, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || port < 0 || port > 65535 {
+			return 0, false
+		}
+		return port, true
+	}
+
+	s := 0
+	e := strings.IndexByte(input, ':')
+
+	const (
+		stepPort = iota
+		stepHost
+		stepPath
+	)
+	step := stepHost
+
+	if e > 0 {
+		// Parse the scheme
+		// scheme = 1*[ lowalpha | digit | "+" | "-" | "." ]
+		validScheme := true
+		for p := 0; p < e; p++ {
+			char := input[p]
+			if !isDigit(char) && !(char >= 'a' && char <= 'z') && !(char >= 'A' && char <= 'Z') && char != '+' && char != '.' && char != '-' {
+				validScheme = false
+				break
+			}
+		}
+
+		questionMark := strings.IndexByte(input, '?')
+		switch {
+		case !validScheme && e+1 < len(input) && questionMark != -1 && e < questionMark:
+			step = stepPort
+		case !validScheme && isRelativeScheme(s):
+			s += 2
+			step = stepHost
+		case !validScheme:
+			step = stepPath
+		case e+1 == len(input):
+			// Only the scheme is available
+			url.scheme = part(input[:e])
+			return url, true
+		case input[e+1] != '/':
+			// Schemes like "mailto:" may not have any "/" after them.
+			// Check if the data is a port to correctly parse things like "a.com:80".
+			p := e + 1
+			for p < len(input) && isDigit(input[p]) {
+				p++
+			}
+			if (p == len(input) || input[p] == '/') && p-e < 7 {
+				step = stepPort
+			} else {
+				url.scheme = part(input[:e])
+				s = e + 1
+				step = stepPath
+			}
+		default:
+			url.scheme = part(input[:e])
+			if e+2 < len(input) && input[e+2] == '/' {
+				s = e + 3
+				if strings.EqualFold(*url.scheme, "file") && e+3 < len(input) && input[e+3] == '/' {
+					// Support windows drive letters as in "file:///c:/somedir/file.txt"
+					if e+5 < len(input) && input[e+5] == ':' {
+						s = e + 4
+					}
+					step = stepPath
+				}
+			} else {
+				s = e + 1
+				step = stepPath
+			}
+		}
+	} else if e == 0 {
+		// No scheme, starts with a colon: Look for a port
+		step = stepPort
+	} else if isRelativeScheme(s) {
+		s += 2
+	} else {
+		step = stepPath
+	}
+
+	if step == stepPort {
+		p := e + 1
+		pp := p
+		for pp < len(input) && pp-p < 6 && isDigit(input[pp]) {
+			pp++
+		}
+		if pp-p > 0 && pp-p < 6 && (pp == len(input) || input[pp] == '/') {
+			port, ok := parsePort(input[p:pp])
+			if !ok {
+				return url, false
+			}
+			url.port = &port
+			if isRelativeScheme(s) {
+				s += 2
+			}
+			step = stepHost
+		} else if p == pp && pp == len(input) {
+			return url, false
+		} else if isRelativeScheme(s) {
+			s += 2
+			step = stepHost
+		} else {
+			step = stepPath
+		}
+	}
+
+	if step == stepHost {
+		e = len(input)
+		if index := strings.IndexAny(input[s:], "/?#"); index != -1 {
+			e = s + index
+		}
+
+		// Check for login and password
+		if p := strings.LastIndexByte(input[s:e], '@'); p != -1 {
+			p += s
+			if pp := strings.IndexByte(input[s:p], ':'); pp != -1 {
+				url.user = part(input[s : s+pp])
+				url.pass = part(input[s+pp+1 : p])
+			} else {
+				url.user = part(input[s:p])
+			}
+			s = p + 1
+		}
+
+		// Check for the port (unless it is an IPv6 address)
+		p := -1
+		if !(e > s && input[s] == '[' && input[e-1] == ']') {
+			if index := strings.LastIndexByte(input[s:e], ':'); index != -1 {
+				p = s + index
+			}
+		}
+		if p != -1 {
+			if url.port == nil {
+				portStr := input[p+1 : e]
+				if len(portStr) > 5 {
+					// The port cannot be longer than 5 characters
+					return url, false
+				} else if len(portStr) > 0 {
+					port, ok := parsePort(portStr)
+					if !ok {
+						return url, false
+					}
+					url.port = &port
+				}
+			}
+		} else {
+			p = e
+		}
+
+		// Check for a valid host
+		if p-s < 1 {
+			return url, false
+		}
+		url.host = part(input[s:p])
+
+		if e == len(input) {
+			return url, true
+		}
+		s = e
+	}
+
+	// Parse the path, query and fragment
+	e = len(input)
+	if p := strings.IndexByte(input[s:e], '#'); p != -1 {
+		p += s
+		url.fragment = part(input[p+1 : e])
+		e = p
+	}
+	if p := strings.IndexByte(input[s:e], '?'); p != -1 {
+		p += s
+		url.query = part(input[p+1 : e])
+		e = p
+	}
+	if s < e || s == len(input) {
+		url.path = part(input[s:e])
+	}
+
+	return url, true
+}
+
+// -------------------------------------- rawurldecode -------------------------------------- MARK: rawurldecode
+
+func nativeFn_rawurldecode(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.rawurldecode.php
+	args, err := funcParamValidator.NewValidator("rawurldecode").AddParam("$string", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(Decode(args[0].(*values.Str).Value, true)), nil
+}
+
+// -------------------------------------- rawurlencode -------------------------------------- MARK: rawurlencode
+
+func nativeFn_rawurlencode(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.rawurlencode.php
+	args, err := funcParamValidator.NewValidator("rawurlencode").AddParam("$string", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(Encode(args[0].(*values.Str).Value, true)), nil
+}
+
+// -------------------------------------- urldecode -------------------------------------- MARK: urldecode
+
+func nativeFn_urldecode(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.urldecode.php
+	args, err := funcParamValidator.NewValidator("urldecode").AddParam("$string", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(Decode(args[0].(*values.Str).Value, false)), nil
+}
+
+// -------------------------------------- urlencode -------------------------------------- MARK: urlencode
+
+func nativeFn_urlencode(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.urlencode.php
+	args, err := funcParamValidator.NewValidator("urlencode").AddParam("$string", []string{"string"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(Encode(args[0].(*values.Str).Value, false)), nil
+}
+
+// TODO get_headers
+// TODO get_meta_tags
@@ -4,6 +4,7 @@ import (
 	"QIQ/cmd/qiq/common"
 	"QIQ/cmd/qiq/phpError"
 	"QIQ/cmd/qiq/runtime/values"
+	"slices"
 	"strings"
 )
 
@@ -64,6 +65,11 @@ func CompareRelation(lhs values.RuntimeValue, operator string, rhs values.Runtim
 		return CompareRelation(rhs, "<=", lhs, leadingNumeric)
 	}
 
+	// Resources are compared by their id with numbers and strings
+	if rhs.GetType() == values.ResourceValue && slices.Contains([]values.ValueType{values.FloatValue, values.IntValue, values.StrValue}, lhs.GetType()) {
+		rhs = values.NewInt(rhs.(*values.Resource).Id)
+	}
+
 	switch lhs.GetType() {
 	case values.ArrayValue:
 		return compareRelationArray(lhs.(*values.Array), operator, rhs, leadingNumeric)
@@ -79,6 +85,8 @@ func CompareRelation(lhs values.RuntimeValue, operator string, rhs values.Runtim
 		return compareRelationNull(operator, rhs, leadingNumeric)
 	case values.ObjectValue:
 		return compareRelationObject(lhs.(*values.Object), operator, rhs)
+	case values.ResourceValue:
+		return compareRelationResource(lhs.(*values.Resource), operator, rhs, leadingNumeric)
 	default:
 		return values.NewVoidSlot(), phpError.NewError(`compareRelation: Type "%s" not implemented`, lhs.GetType())
 	}
@@ -101,7 +109,6 @@ func compareRelationArray(lhs *values.Array, operator string, rhs values.Runtime
 	//      If all the values are equal, then the arrays are considered equal.
 
 	// TODO compareRelationArray - object
-	// TODO compareRelationArray - resource
 
 	if rhs.GetType() == values.NullValue {
 		var err phpError.Error
@@ -169,7 +176,7 @@ func compareRelationArray(lhs *values.Array, operator string, rhs values.Runtime
 		}
 		return compareRelationBoolean(values.NewBool(lhsBoolean), operator, rhs)
 
-	case values.FloatValue, values.IntValue, values.StrValue:
+	case values.FloatValue, values.IntValue, values.StrValue, values.ResourceValue:
 		switch operator {
 		case "<", "<=":
 			return values.NewBoolSlot(false), nil
@@ -450,7 +457,15 @@ func compareRelationNull(operator string, rhs values.RuntimeValue, leadingNumeri
 		return values.NewVoidSlot(), phpError.NewError(`compareRelationNull: Operator "%s" not implemented for type NULL`, operator)
 
 		// TODO compareRelationNull - object
-		// TODO compareRelationNull - resource
+
+	case values.ResourceValue:
+		switch operator {
+		case "<", "<=":
+			return values.NewBoolSlot(true), nil
+		case "<=>":
+			return values.NewIntSlot(-1), nil
+		}
+		return values.NewVoidSlot(), phpError.NewError(`compareRelationNull: Operator "%s" not implemented for type resource`, operator)
 
 	case values.StrValue:
 		lhs, err := StrVal(values.NewNull())
@@ -640,10 +655,45 @@ func compareRelationObject(lhs *values.Object, operator string, rhs values.Runti
 	}
 }
 
-// TODO compareRelationResource
-// Spec: https://phplang.org/spec/10-expressions.html#grammar-relational-expression
-//           NULL  bool  int  float  string  array  object  resource
-// resource   >     ->    ->   ->     2       <      3       2
+func compareRelationResource(lhs *values.Resource, operator string, rhs values.RuntimeValue, leadingNumeric bool) (*values.Slot, phpError.Error) {
+	// Spec: https://phplang.org/spec/10-expressions.html#grammar-relational-expression
+	//           NULL  bool  int  float  string  array  object  resource
+	// resource   >     ->    ->   ->     2       <      3       2
+
+	switch rhs.GetType() {
+	case values.NullValue:
+		switch operator {
+		case "<", "<=":
+			return values.NewBoolSlot(false), nil
+		case "<=>":
+			return values.NewIntSlot(1), nil
+		}
+		return values.NewVoidSlot(), phpError.NewError(`compareRelationResource: Operator "%s" not implemented for type null`, operator)
+
+	case values.ArrayValue:
+		switch operator {
+		case "<", "<=":
+			return values.NewBoolSlot(true), nil
+		case "<=>":
+			return values.NewIntSlot(-1), nil
+		}
+		return values.NewVoidSlot(), phpError.NewError(`compareRelationResource: Operator "%s" not implemented for type array`, operator)
+
+	case values.BoolValue:
+		return compareRelationBoolean(values.NewBool(true), operator, rhs)
+
+	case values.ResourceValue:
+		return compareRelationInteger(values.NewInt(lhs.Id), operator, values.NewInt(rhs.(*values.Resource).Id), leadingNumeric)
+
+	case values.FloatValue, values.IntValue, values.StrValue:
+		return compareRelationInteger(values.NewInt(lhs.Id), operator, rhs, leadingNumeric)
+
+	// TODO compareRelationResource - object
+
+	default:
+		return values.NewVoidSlot(), phpError.NewError(`compareRelationResource: Type "%s" not implemented`, rhs.GetType())
+	}
+}
 
 // -------------------------------------- comparison -------------------------------------- MARK: comparison
 
@@ -722,6 +772,8 @@ func Compare(lhs values.RuntimeValue, operator string, rhs values.RuntimeValue)
 				result = lhs.(*values.Str).Value == rhs.(*values.Str).Value
 			case values.ObjectValue:
 				result = lhs.(*values.Object) == rhs.(*values.Object)
+			case values.ResourceValue:
+				result = lhs.(*values.Resource) == rhs.(*values.Resource)
 			default:
 				return values.NewSlot(values.NewBool(false)), phpError.NewError(`compare: Runtime type %s for operator "===" not implemented`, lhs.GetType())
 			}
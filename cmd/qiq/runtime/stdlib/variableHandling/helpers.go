@@ -23,3 +23,11 @@ func ToValueType(valueType values.ValueType, runtimeValue values.RuntimeValue, l
 		return values.NewVoid(), phpError.NewError("runtimeValueToValueType: Unsupported runtime value: %s", valueType)
 	}
 }
+
+// Get the visibility of the object's property. Dynamic properties are public.
+func propertyVisibility(object *values.Object, propertyName string) string {
+	if property, found := object.Class.Properties[propertyName]; found {
+		return property.Visibility
+	}
+	return "public"
+}
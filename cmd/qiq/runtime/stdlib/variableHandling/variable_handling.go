@@ -18,6 +18,8 @@ func Register(environment runtime.Environment) {
 	environment.AddNativeFunction("doubleval", nativeFn_floatval)
 	environment.AddNativeFunction("floatval", nativeFn_floatval)
 	environment.AddNativeFunction("get_debug_type", nativeFn_get_debug_type)
+	environment.AddNativeFunction("get_resource_id", nativeFn_get_resource_id)
+	environment.AddNativeFunction("get_resource_type", nativeFn_get_resource_type)
 	environment.AddNativeFunction("gettype", nativeFn_gettype)
 	environment.AddNativeFunction("intval", nativeFn_intval)
 	environment.AddNativeFunction("is_array", nativeFn_is_array)
@@ -29,6 +31,7 @@ func Register(environment runtime.Environment) {
 	environment.AddNativeFunction("is_long", nativeFn_is_int)
 	environment.AddNativeFunction("is_null", nativeFn_is_null)
 	environment.AddNativeFunction("is_object", nativeFn_is_object)
+	environment.AddNativeFunction("is_resource", nativeFn_is_resource)
 	environment.AddNativeFunction("is_scalar", nativeFn_is_scalar)
 	environment.AddNativeFunction("is_string", nativeFn_is_string)
 	environment.AddNativeFunction("print_r", nativeFn_print_r)
@@ -53,8 +56,7 @@ func ArrayVal(runtimeValue values.RuntimeValue) (*values.Array, phpError.Error)
 		return values.NewArray(), nil
 	}
 
-	// TODO ArrayVal - resource
-	if IsScalar(runtimeValue) {
+	if IsScalar(runtimeValue) || runtimeValue.GetType() == values.ResourceValue {
 		// Spec: https://phplang.org/spec/08-conversions.html#converting-to-array-type
 		// If the source type is scalar or resource and it is non-NULL,
 		// the result value is an array of one element under the key 0 whose value is that of the source.
@@ -119,6 +121,10 @@ func BoolVal(runtimeValue values.RuntimeValue) (bool, phpError.Error) {
 		// If the source is an empty string or the string “0”, the result value is FALSE; otherwise, the result value is TRUE.
 		str := runtimeValue.(*values.Str).Value
 		return str != "" && str != "0", nil
+	case values.ResourceValue:
+		// Spec: https://phplang.org/spec/08-conversions.html#converting-to-boolean-type
+		// If the source is a resource, the result value is TRUE.
+		return true, nil
 	default:
 		return false, phpError.NewError("boolval: Unsupported runtime value %s", runtimeValue.GetType())
 	}
@@ -126,10 +132,6 @@ func BoolVal(runtimeValue values.RuntimeValue) (bool, phpError.Error) {
 	// TODO boolval - object
 	// Spec: https://phplang.org/spec/08-conversions.html#converting-to-boolean-type
 	// If the source is an object, the result value is TRUE.
-
-	// TODO boolval - resource
-	// Spec: https://phplang.org/spec/08-conversions.html#converting-to-boolean-type
-	// If the source is a resource, the result value is TRUE.
 }
 
 // -------------------------------------- floatval -------------------------------------- MARK: floatval
@@ -209,8 +211,6 @@ func lib_get_debug_type(runtimeValue values.RuntimeValue) (string, phpError.Erro
 	// Spec: https://www.php.net/manual/en/function.get-debug-type
 
 	// TODO lib_get_debug_type - object
-	// TODO lib_get_debug_type - resource
-	// TODO lib_get_debug_type - resource (closed)
 	switch runtimeValue.GetType() {
 	case values.ArrayValue:
 		return "array", nil
@@ -224,11 +224,42 @@ func lib_get_debug_type(runtimeValue values.RuntimeValue) (string, phpError.Erro
 		return "null", nil
 	case values.StrValue:
 		return "string", nil
+	case values.ResourceValue:
+		if runtimeValue.(*values.Resource).IsClosed {
+			return "resource (closed)", nil
+		}
+		return "resource (" + runtimeValue.(*values.Resource).Type + ")", nil
 	default:
 		return "unknown type", nil
 	}
 }
 
+// -------------------------------------- get_resource_id -------------------------------------- MARK: get_resource_id
+
+func nativeFn_get_resource_id(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.get-resource-id.php
+
+	args, err := funcParamValidator.NewValidator("get_resource_id").AddParam("$resource", []string{"resource"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewInt(args[0].(*values.Resource).Id), nil
+}
+
+// -------------------------------------- get_resource_type -------------------------------------- MARK: get_resource_type
+
+func nativeFn_get_resource_type(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.get-resource-type.php
+
+	args, err := funcParamValidator.NewValidator("get_resource_type").AddParam("$resource", []string{"resource"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	return values.NewStr(args[0].(*values.Resource).GetResourceType()), nil
+}
+
 // -------------------------------------- gettype -------------------------------------- MARK: gettype
 
 func nativeFn_gettype(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -244,8 +275,6 @@ func nativeFn_gettype(args []values.RuntimeValue, _ runtime.Context) (values.Run
 func GetType(runtimeValue values.RuntimeValue) (string, phpError.Error) {
 	// Spec: https://www.php.net/manual/en/function.gettype.php
 
-	// TODO GetType - resource
-	// TODO GetType - resource (closed)
 	switch runtimeValue.GetType() {
 	case values.ArrayValue:
 		return "array", nil
@@ -261,6 +290,11 @@ func GetType(runtimeValue values.RuntimeValue) (string, phpError.Error) {
 		return "string", nil
 	case values.ObjectValue:
 		return "object", nil
+	case values.ResourceValue:
+		if runtimeValue.(*values.Resource).IsClosed {
+			return "resource (closed)", nil
+		}
+		return "resource", nil
 	default:
 		return "unknown type", nil
 	}
@@ -329,6 +363,10 @@ func IntVal(runtimeValue values.RuntimeValue, leadingNumeric bool) (int64, phpEr
 			return intValue, nil
 		}
 		return 0, nil
+	case values.ResourceValue:
+		// Spec: https://phplang.org/spec/08-conversions.html#converting-to-integer-type
+		// If the source is a resource, the result is the resource’s unique ID.
+		return runtimeValue.(*values.Resource).Id, nil
 	default:
 		return 0, phpError.NewError("IntVal: Unsupported runtime value %s", runtimeValue.GetType())
 	}
@@ -336,10 +374,6 @@ func IntVal(runtimeValue values.RuntimeValue, leadingNumeric bool) (int64, phpEr
 	// TODO IntVal - object
 	// Spec: https://phplang.org/spec/08-conversions.html#converting-to-integer-type
 	// If the source is an object, if the class defines a conversion function, the result is determined by that function (this is currently available only to internal classes). If not, the conversion is invalid, the result is assumed to be 1 and a non-fatal error is produced.
-
-	// TODO IntVal - resource
-	// Spec: https://phplang.org/spec/08-conversions.html#converting-to-integer-type
-	// If the source is a resource, the result is the resource’s unique ID.
 }
 
 // -------------------------------------- is_array -------------------------------------- MARK: is_array
@@ -432,6 +466,20 @@ func nativeFn_is_object(args []values.RuntimeValue, _ runtime.Context) (values.R
 	return values.NewBool(args[0].GetType() == values.ObjectValue), nil
 }
 
+// -------------------------------------- is_resource -------------------------------------- MARK: is_resource
+
+func nativeFn_is_resource(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
+	// Spec: https://www.php.net/manual/en/function.is-resource.php
+
+	args, err := funcParamValidator.NewValidator("is_resource").AddParam("$value", []string{"mixed"}, nil).Validate(args)
+	if err != nil {
+		return values.NewVoid(), err
+	}
+
+	// Closed resources are no resources for is_resource
+	return values.NewBool(args[0].GetType() == values.ResourceValue && !args[0].(*values.Resource).IsClosed), nil
+}
+
 // -------------------------------------- is_scalar -------------------------------------- MARK: is_scalar
 
 func nativeFn_is_scalar(args []values.RuntimeValue, _ runtime.Context) (values.RuntimeValue, phpError.Error) {
@@ -525,6 +573,8 @@ func lib_print_r_var(value values.RuntimeValue, depth int) (string, phpError.Err
 		result = ""
 	case values.StrValue:
 		result = value.(*values.Str).Value
+	case values.ResourceValue:
+		result = value.(*values.Resource).ToPhpString()
 	case values.ObjectValue:
 		object := value.(*values.Object)
 		result = fmt.Sprintf("%s Object\n%s(\n", object.Class.Name, strings.Repeat(" ", depth-4))
@@ -569,6 +619,9 @@ func Serialize(runtimeValue values.RuntimeValue) (string, phpError.Error) {
 
 	case values.NullValue:
 		return "N;", nil
+	case values.ResourceValue:
+		// Resources cannot be serialized and are stored as integer zero
+		return "i:0;", nil
 	case values.IntValue:
 		// i:<value>;
 		return fmt.Sprintf("i:%d;", runtimeValue.(*values.Int).Value), nil
@@ -616,7 +669,7 @@ func Serialize(runtimeValue values.RuntimeValue) (string, phpError.Error) {
 			// Property name
 			// Remove the $ prefix
 			propertyName := property[1:]
-			switch object.Class.Properties[property].Visibility {
+			switch propertyVisibility(object, property) {
 			case "private":
 				propertyName = "\x00" + object.Class.Name + "\x00" + propertyName
 			case "protected":
@@ -687,6 +740,10 @@ func StrVal(runtimeValue values.RuntimeValue) (string, phpError.Error) {
 		return runtimeValue.(*values.Str).Value, nil
 	case values.VoidValue:
 		return "", nil
+	case values.ResourceValue:
+		// Spec: https://phplang.org/spec/08-conversions.html#converting-to-string-type
+		// If the source is a resource, the result value is an implementation-defined string.
+		return runtimeValue.(*values.Resource).ToPhpString(), nil
 	default:
 		return "", phpError.NewError("lib_strval: Unsupported runtime value %s", runtimeValue.GetType())
 	}
@@ -694,10 +751,6 @@ func StrVal(runtimeValue values.RuntimeValue) (string, phpError.Error) {
 	// TODO lib_strval - object
 	// Spec: https://phplang.org/spec/08-conversions.html#converting-to-string-type
 	// If the source is an object, then if that object’s class has a __toString method, the result value is the string returned by that method; otherwise, the conversion is invalid and a fatal error is produced.
-
-	// TODO lib_strval - resource
-	// Spec: https://phplang.org/spec/08-conversions.html#converting-to-string-type
-	// If the source is a resource, the result value is an implementation-defined string.
 }
 
 // -------------------------------------- unserialize -------------------------------------- MARK: unserialize
@@ -934,6 +987,9 @@ func lib_var_dump_var(context runtime.Context, value values.RuntimeValue, depth
 		context.Interpreter.Println("int(" + strVal + ")")
 	case values.NullValue:
 		context.Interpreter.Println("NULL")
+	case values.ResourceValue:
+		resource := value.(*values.Resource)
+		context.Interpreter.Println(fmt.Sprintf("resource(%d) of type (%s)", resource.Id, resource.GetResourceType()))
 	case values.StrValue:
 		strVal := value.(*values.Str).Value
 		context.Interpreter.Println(fmt.Sprintf(`string(%d) "%s"`, len(strVal), strVal))
@@ -941,14 +997,13 @@ func lib_var_dump_var(context runtime.Context, value values.RuntimeValue, depth
 		object := value.(*values.Object)
 		// TODO var_dump - object: dynamic counter of instaces
 		context.Interpreter.Println(fmt.Sprintf("object(%s)#1 (%d) {",
-			object.Class.GetQualifiedName(), len(object.Class.PropertieNames),
+			object.Class.GetQualifiedName(), len(object.PropertyNames),
 		))
 		for _, propertyName := range object.PropertyNames {
-			property := object.Class.Properties[propertyName]
 			propertyValue := object.Properties[propertyName]
 
 			context.Interpreter.Println(fmt.Sprintf(`%s["%s":"%s":%s]=>`,
-				strings.Repeat(" ", depth), propertyName[1:], object.Class.GetQualifiedName(), property.Visibility,
+				strings.Repeat(" ", depth), propertyName[1:], object.Class.GetQualifiedName(), propertyVisibility(object, propertyName),
 			))
 			context.Interpreter.Print(strings.Repeat(" ", depth))
 			if err := lib_var_dump_var(context, propertyValue.Value, depth+2); err != nil {
@@ -1031,7 +1086,8 @@ func lib_var_export_var(value values.RuntimeValue, depth int) (string, phpError.
 		if err != nil {
 			return "", err
 		}
-	case values.NullValue:
+	case values.NullValue, values.ResourceValue:
+		// TODO var_export - resource: Warning "var_export does not handle resources"
 		result = "NULL"
 	case values.StrValue:
 		result = "'" + value.(*values.Str).Value + "'"
@@ -1043,11 +1099,8 @@ func lib_var_export_var(value values.RuntimeValue, depth int) (string, phpError.
 
 // TODO debug_​zval_​dump
 // TODO get_​defined_​vars
-// TODO get_​resource_​id
-// TODO get_​resource_​type
 // TODO is_​callable
 // TODO is_​countable
 // TODO is_​iterable
 // TODO is_​numeric
-// TODO is_​resource
 // TODO settype
@@ -0,0 +1,62 @@
+package stream
+
+import (
+	"QIQ/cmd/qiq/runtime"
+	"errors"
+	"os"
+	"slices"
+	"syscall"
+)
+
+// Directory stream of a resource with the type "stream" (e.g. a directory opened with opendir).
+// The entries are read when the directory is opened and start with "." and "..".
+type Directory struct {
+	entries  []string
+	position int
+}
+
+// Open the directory. The error message is the reason as used in "Failed to open directory: <reason>".
+func OpenDir(path string, context runtime.Context) (*Directory, error) {
+	entries, err := ReadDir(path, context)
+	if err != nil {
+		return nil, err
+	}
+	return &Directory{entries: entries}, nil
+}
+
+// Get the names of the directory entries including "." and "..". The other names are sorted.
+// Directories outside of open_basedir cannot be read.
+func ReadDir(path string, context runtime.Context) ([]string, error) {
+	if !CheckOpenBasedir(currentFunctionName(context), path, context) {
+		return nil, errors.New(ErrorMessage(syscall.EPERM))
+	}
+	file, err := os.Open(ResolvePath(path, context))
+	if err != nil {
+		return nil, errors.New(ErrorMessage(err))
+	}
+	defer file.Close()
+
+	entries, err := file.ReadDir(-1)
+	if err != nil {
+		return nil, errors.New(ErrorMessage(err))
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	slices.Sort(names)
+	return append([]string{".", ".."}, names...), nil
+}
+
+// Get the next entry. Returns false if all entries were read.
+func (directory *Directory) Read() (string, bool) {
+	if directory.position >= len(directory.entries) {
+		return "", false
+	}
+	directory.position++
+	return directory.entries[directory.position-1], true
+}
+
+func (directory *Directory) Rewind() { directory.position = 0 }
+
+func (directory *Directory) Close() { directory.entries = nil }
@@ -0,0 +1,128 @@
+package stream
+
+import (
+	"io"
+	"os"
+)
+
+// Underlying data source of a stream (e.g. a file or a memory buffer).
+// Handles can implement io.Seeker, Truncate(size int64) error and Sync() error if supported.
+type handle interface {
+	io.Reader
+	io.Writer
+	io.Closer
+}
+
+type truncater interface {
+	Truncate(size int64) error
+}
+
+// -------------------------------------- stdio -------------------------------------- MARK: stdio
+
+// Standard input, output or error of the process.
+// Closing the stream does not close the file descriptor of the process.
+type stdioHandle struct {
+	*os.File
+	// Receives the written data instead of the file (e.g. to order STDOUT with the output of the script)
+	print func(str string)
+}
+
+func (handle *stdioHandle) Write(data []byte) (int, error) {
+	if handle.print == nil {
+		return handle.File.Write(data)
+	}
+	handle.print(string(data))
+	return len(data), nil
+}
+
+func (handle *stdioHandle) Close() error { return nil }
+
+// -------------------------------------- temporary file -------------------------------------- MARK: temporary file
+
+// Temporary file created by tmpfile that is deleted when the stream is closed
+type tempFileHandle struct {
+	*os.File
+}
+
+func (handle *tempFileHandle) Close() error {
+	err := handle.File.Close()
+	os.Remove(handle.Name())
+	return err
+}
+
+// -------------------------------------- memory -------------------------------------- MARK: memory
+
+// In-memory data used by php://memory, php://temp and php://input
+type memoryHandle struct {
+	data     []byte
+	position int64
+}
+
+func newMemoryHandle(data string) *memoryHandle { return &memoryHandle{data: []byte(data)} }
+
+// Read the data at the current position. The end of the data is reported with the last bytes (like PHP memory streams).
+func (handle *memoryHandle) Read(buffer []byte) (int, error) {
+	if handle.position >= int64(len(handle.data)) {
+		return 0, io.EOF
+	}
+	n := copy(buffer, handle.data[handle.position:])
+	handle.position += int64(n)
+	if handle.position >= int64(len(handle.data)) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (handle *memoryHandle) Write(data []byte) (int, error) {
+	end := handle.position + int64(len(data))
+	if end > int64(len(handle.data)) {
+		handle.data = append(handle.data, make([]byte, end-int64(len(handle.data)))...)
+	}
+	copy(handle.data[handle.position:], data)
+	handle.position = end
+	return len(data), nil
+}
+
+func (handle *memoryHandle) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += handle.position
+	case io.SeekEnd:
+		offset += int64(len(handle.data))
+	}
+	if offset < 0 {
+		return handle.position, os.ErrInvalid
+	}
+	handle.position = offset
+	return offset, nil
+}
+
+func (handle *memoryHandle) Truncate(size int64) error {
+	if size > int64(len(handle.data)) {
+		handle.data = append(handle.data, make([]byte, size-int64(len(handle.data)))...)
+	} else {
+		handle.data = handle.data[:size]
+	}
+	return nil
+}
+
+func (handle *memoryHandle) Close() error {
+	handle.data = nil
+	return nil
+}
+
+// -------------------------------------- output -------------------------------------- MARK: output
+
+// Output of the script (php://output) that is written like echo and print
+type outputHandle struct {
+	print func(str string)
+}
+
+func (handle *outputHandle) Read(buffer []byte) (int, error) { return 0, io.EOF }
+
+func (handle *outputHandle) Write(data []byte) (int, error) {
+	handle.print(string(data))
+	return len(data), nil
+}
+
+func (handle *outputHandle) Close() error { return nil }
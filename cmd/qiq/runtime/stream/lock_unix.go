@@ -0,0 +1,28 @@
+//go:build !windows
+
+package stream
+
+import (
+	"os"
+	"syscall"
+)
+
+// Apply the advisory lock operation (LOCK_SH, LOCK_EX or LOCK_UN with optional LOCK_NB) to the file
+func lockFile(file *os.File, operation int) (ok bool, wouldBlock bool) {
+	how := 0
+	switch operation &^ LOCK_NB {
+	case LOCK_SH:
+		how = syscall.LOCK_SH
+	case LOCK_EX:
+		how = syscall.LOCK_EX
+	case LOCK_UN:
+		how = syscall.LOCK_UN
+	default:
+		return false, false
+	}
+	if operation&LOCK_NB != 0 {
+		how |= syscall.LOCK_NB
+	}
+	err := syscall.Flock(int(file.Fd()), how)
+	return err == nil, err == syscall.EWOULDBLOCK
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package stream
+
+import "os"
+
+// Advisory locks are not supported on Windows. The operations succeed without locking.
+func lockFile(file *os.File, operation int) (ok bool, wouldBlock bool) {
+	switch operation &^ LOCK_NB {
+	case LOCK_SH, LOCK_EX, LOCK_UN:
+		return true, false
+	}
+	return false, false
+}
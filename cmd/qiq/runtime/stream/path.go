@@ -0,0 +1,126 @@
+package stream
+
+import (
+	"QIQ/cmd/qiq/phpError"
+	"QIQ/cmd/qiq/runtime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Resolve the relative path against the working directory of the script (e.g. "data.txt" => "/var/www/data.txt")
+func ResolvePath(path string, context runtime.Context) string {
+	workingDir := context.Interpreter.GetWorkingDir()
+	if path == "" || filepath.IsAbs(path) || workingDir == "" {
+		return path
+	}
+	return filepath.Join(workingDir, path)
+}
+
+// -------------------------------------- open_basedir -------------------------------------- MARK: open_basedir
+
+// Check if the path is within one of the directories of the ini directive open_basedir.
+// Symbolic links are resolved before the check. Port of php_check_open_basedir_ex.
+func IsAllowedPath(path string, context runtime.Context) bool {
+	openBasedir := context.Interpreter.GetIni().GetStr("open_basedir")
+	if openBasedir == "" {
+		return true
+	}
+
+	resolvedPath := realPath(ResolvePath(path, context))
+	for _, basedir := range filepath.SplitList(openBasedir) {
+		if basedir == "" {
+			continue
+		}
+		if isWithinBasedir(resolvedPath, basedir, context) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if the path is allowed by open_basedir. If not, a warning is printed for the function.
+func CheckOpenBasedir(functionName string, path string, context runtime.Context) bool {
+	if IsAllowedPath(path, context) {
+		return true
+	}
+	context.Interpreter.PrintError(phpError.NewWarning(
+		"%s(): open_basedir restriction in effect. File(%s) is not within the allowed path(s): (%s)%s",
+		functionName, path, context.Interpreter.GetIni().GetStr("open_basedir"), context.InPosition(),
+	))
+	return false
+}
+
+// Check if the new value of open_basedir is at least as restrictive as the current value.
+// At runtime, open_basedir can only be set if it is empty or if every directory is within the current directories.
+func IsOpenBasedirTightened(value string, context runtime.Context) bool {
+	if context.Interpreter.GetIni().GetStr("open_basedir") == "" {
+		return true
+	}
+	if value == "" {
+		return false
+	}
+	for _, basedir := range filepath.SplitList(value) {
+		if basedir == ".." || !IsAllowedPath(basedir, context) {
+			return false
+		}
+	}
+	return true
+}
+
+// Check if the resolved path is within the directory. A directory without trailing slash is a prefix
+// (e.g. "/var/www" allows "/var/www2"). Port of php_check_specific_open_basedir.
+func isWithinBasedir(resolvedPath string, basedir string, context runtime.Context) bool {
+	// "." is the working directory of the script
+	if basedir == "." {
+		basedir = ResolvePath(".", context)
+	}
+	resolvedBasedir := realPath(ResolvePath(basedir, context))
+	if strings.HasSuffix(basedir, string(filepath.Separator)) && !strings.HasSuffix(resolvedBasedir, string(filepath.Separator)) {
+		resolvedBasedir += string(filepath.Separator)
+	}
+
+	if strings.HasPrefix(resolvedPath, resolvedBasedir) {
+		return true
+	}
+	// The directory itself is allowed if the basedir has a trailing slash
+	return strings.HasSuffix(resolvedBasedir, string(filepath.Separator)) && resolvedPath+string(filepath.Separator) == resolvedBasedir
+}
+
+// Get the absolute path with resolved symbolic links. The path does not have to exist:
+// the longest existing part is resolved and the remaining part is appended.
+func realPath(path string) string {
+	if !filepath.IsAbs(path) {
+		workingDir, err := os.Getwd()
+		if err != nil {
+			return filepath.Clean(path)
+		}
+		path = workingDir + string(filepath.Separator) + path
+	}
+
+	// The path is not cleaned before the symbolic links are resolved, because "link/.." is the parent of the link target
+	remaining := ""
+	for {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			return filepath.Join(resolved, remaining)
+		}
+		index := strings.LastIndexByte(path, filepath.Separator)
+		if index <= 0 {
+			return filepath.Join(string(filepath.Separator), path, remaining)
+		}
+		remaining = filepath.Join(path[index+1:], remaining)
+		path = path[:index]
+	}
+}
+
+// Get the name of the native function or method that is executed (e.g. "fopen" or "SplFileObject::__construct")
+func currentFunctionName(context runtime.Context) string {
+	backtrace := context.Interpreter.GetBacktrace()
+	if len(backtrace) == 0 {
+		return ""
+	}
+	if backtrace[0].Class != "" {
+		return backtrace[0].Class + "::" + backtrace[0].Function
+	}
+	return backtrace[0].Function
+}
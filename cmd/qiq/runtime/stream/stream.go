@@ -0,0 +1,401 @@
+package stream
+
+import (
+	"QIQ/cmd/qiq/runtime"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// Spec: https://www.php.net/manual/en/function.flock.php
+const (
+	LOCK_SH int = 1
+	LOCK_EX int = 2
+	LOCK_UN int = 3
+	LOCK_NB int = 4
+)
+
+// Spec: https://www.php.net/manual/en/function.fseek.php
+const (
+	SEEK_SET int = 0
+	SEEK_CUR int = 1
+	SEEK_END int = 2
+)
+
+// Size of the chunks read from the handle
+const chunkSize = 8192
+
+// Stream of a resource with the type "stream" (e.g. a file opened with fopen or php://memory)
+type Stream struct {
+	handle   handle
+	readable bool
+	writable bool
+	// Every write appends to the end (modes "a" and "a+")
+	appendOnly bool
+	// Reads return the available data instead of waiting for the requested length (e.g. STDIN)
+	readOnce bool
+	// Data that was read from the handle but not consumed yet
+	readBuffer []byte
+	// The handle has no more data
+	handleAtEnd bool
+	position    int64
+}
+
+func newStream(handle handle, readable bool, writable bool) *Stream {
+	return &Stream{handle: handle, readable: readable, writable: writable}
+}
+
+// Create the stream for STDIN, STDOUT or STDERR
+func NewStdioStream(file *os.File, readable bool, writable bool) *Stream {
+	stream := newStream(&stdioHandle{File: file}, readable, writable)
+	stream.readOnce = true
+	return stream
+}
+
+// Pass the data written to a stdio stream to the print function instead of writing it to the file of the process
+func (stream *Stream) SetPrint(print func(str string)) {
+	if stdio, isStdio := stream.handle.(*stdioHandle); isStdio {
+		stdio.print = print
+	}
+}
+
+// -------------------------------------- Open -------------------------------------- MARK: Open
+
+// Open the file or the php:// stream with the mode (e.g. "r", "w+" or "ab").
+// The error message is the reason as used in "Failed to open stream: <reason>". Files outside of open_basedir cannot be opened.
+func Open(filename string, mode string, context runtime.Context) (*Stream, error) {
+	openMode, ok := parseMode(mode)
+	if !ok {
+		return nil, errors.New("`" + mode + "' is not a valid mode for fopen")
+	}
+
+	if strings.HasPrefix(strings.ToLower(filename), "php://") {
+		return openPhpStream(filename[len("php://"):], mode, openMode, context)
+	}
+	filename = strings.TrimPrefix(filename, "file://")
+	if !CheckOpenBasedir(currentFunctionName(context), filename, context) {
+		return nil, errors.New(ErrorMessage(syscall.EPERM))
+	}
+	filename = ResolvePath(filename, context)
+
+	file, err := os.OpenFile(filename, openMode.flags, 0666)
+	if err != nil {
+		return nil, errors.New(ErrorMessage(err))
+	}
+	stream := newStream(file, openMode.readable, openMode.writable)
+	stream.appendOnly = openMode.flags&os.O_APPEND != 0
+	if stream.appendOnly {
+		stream.position, _ = file.Seek(0, io.SeekEnd)
+	}
+	return stream, nil
+}
+
+// Create a temporary file in the directory that is deleted when the stream is closed
+func OpenTempFile(directory string) (*Stream, error) {
+	file, err := os.CreateTemp(directory, "php")
+	if err != nil {
+		return nil, errors.New(ErrorMessage(err))
+	}
+	return newStream(&tempFileHandle{File: file}, true, true), nil
+}
+
+// Open a stream like "php://memory" (name is "memory")
+func openPhpStream(name string, mode string, openMode fileMode, context runtime.Context) (*Stream, error) {
+	lowerName := strings.ToLower(name)
+	switch {
+	case lowerName == "stdin":
+		return NewStdioStream(os.Stdin, openMode.readable, openMode.writable), nil
+	case lowerName == "stdout":
+		// The output is written after the output buffers like the output of the script
+		stdout := NewStdioStream(os.Stdout, openMode.readable, openMode.writable)
+		stdout.SetPrint(context.Interpreter.WriteResult)
+		return stdout, nil
+	case lowerName == "stderr":
+		return NewStdioStream(os.Stderr, openMode.readable, openMode.writable), nil
+	case lowerName == "memory", lowerName == "temp", strings.HasPrefix(lowerName, "temp/maxmemory:"):
+		// Memory streams are read-only unless the mode allows writing
+		writable := strings.ContainsAny(mode, "wa+")
+		return newStream(newMemoryHandle(""), true, writable), nil
+	case lowerName == "input":
+		return newStream(newMemoryHandle(requestBody(context)), true, false), nil
+	case lowerName == "output":
+		return newStream(&outputHandle{print: context.Interpreter.Print}, false, true), nil
+	}
+	return nil, errors.New("operation failed")
+}
+
+// Get the raw request body as read by php://input. The body of multipart requests is not available.
+func requestBody(context runtime.Context) string {
+	body := context.Interpreter.GetRequest().Post
+	if strings.HasPrefix(body, "Content-Type: multipart/form-data;") {
+		return ""
+	}
+	return body
+}
+
+type fileMode struct {
+	flags    int
+	readable bool
+	writable bool
+}
+
+// Parse the fopen mode. Only the first character and "+" are relevant, other characters like "b" or "t" are ignored.
+func parseMode(mode string) (fileMode, bool) {
+	if mode == "" {
+		return fileMode{}, false
+	}
+	var result fileMode
+	switch mode[0] {
+	case 'r':
+		result = fileMode{flags: os.O_RDONLY, readable: true}
+	case 'w':
+		result = fileMode{flags: os.O_WRONLY | os.O_CREATE | os.O_TRUNC, writable: true}
+	case 'a':
+		result = fileMode{flags: os.O_WRONLY | os.O_CREATE | os.O_APPEND, writable: true}
+	case 'x':
+		result = fileMode{flags: os.O_WRONLY | os.O_CREATE | os.O_EXCL, writable: true}
+	case 'c':
+		result = fileMode{flags: os.O_WRONLY | os.O_CREATE, writable: true}
+	default:
+		return fileMode{}, false
+	}
+	if strings.Contains(mode, "+") {
+		result.flags = result.flags&^(os.O_RDONLY|os.O_WRONLY) | os.O_RDWR
+		result.readable = true
+		result.writable = true
+	}
+	return result, true
+}
+
+// Get the message of the system error like the C function strerror (e.g. "No such file or directory")
+func ErrorMessage(err error) string {
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		message := errno.Error()
+		if message != "" {
+			return strings.ToUpper(message[:1]) + message[1:]
+		}
+	}
+	return err.Error()
+}
+
+// -------------------------------------- Reading -------------------------------------- MARK: Reading
+
+func (stream *Stream) IsReadable() bool { return stream.readable }
+
+// Read the next chunk from the handle into the read buffer
+func (stream *Stream) fill() error {
+	chunk := make([]byte, chunkSize)
+	n, err := stream.handle.Read(chunk)
+	stream.readBuffer = append(stream.readBuffer, chunk[:n]...)
+	if err == io.EOF || (n == 0 && err == nil) {
+		stream.handleAtEnd = true
+		return nil
+	}
+	return err
+}
+
+// Take the given number of bytes from the read buffer
+func (stream *Stream) consume(length int) string {
+	result := string(stream.readBuffer[:length])
+	stream.readBuffer = stream.readBuffer[length:]
+	stream.position += int64(length)
+	return result
+}
+
+// Read up to length bytes. Less bytes are returned at the end of the stream.
+func (stream *Stream) Read(length int) (string, error) {
+	if !stream.readable {
+		return "", syscall.EBADF
+	}
+	for len(stream.readBuffer) < length && !stream.handleAtEnd {
+		if stream.readOnce && len(stream.readBuffer) > 0 {
+			break
+		}
+		if err := stream.fill(); err != nil {
+			return "", err
+		}
+	}
+	return stream.consume(min(length, len(stream.readBuffer))), nil
+}
+
+// Read the line including the line ending "\n" with at most maxLength bytes (-1 = unlimited).
+// Returns false at the end of the stream.
+func (stream *Stream) ReadLine(maxLength int) (string, bool, error) {
+	if !stream.readable {
+		return "", false, syscall.EBADF
+	}
+	for {
+		if index := bytes.IndexByte(stream.readBuffer, '\n'); index >= 0 && (maxLength < 0 || index < maxLength) {
+			return stream.consume(index + 1), true, nil
+		}
+		if maxLength >= 0 && len(stream.readBuffer) >= maxLength {
+			return stream.consume(maxLength), true, nil
+		}
+		if stream.handleAtEnd {
+			if len(stream.readBuffer) == 0 {
+				return "", false, nil
+			}
+			return stream.consume(len(stream.readBuffer)), true, nil
+		}
+		if err := stream.fill(); err != nil {
+			return "", false, err
+		}
+	}
+}
+
+// Read the remaining data of the stream
+func (stream *Stream) ReadAll() (string, error) {
+	if !stream.readable {
+		return "", syscall.EBADF
+	}
+	for !stream.handleAtEnd {
+		if err := stream.fill(); err != nil {
+			return "", err
+		}
+	}
+	return stream.consume(len(stream.readBuffer)), nil
+}
+
+// The end of the stream is reached after a read found no more data
+func (stream *Stream) Eof() bool { return stream.handleAtEnd && len(stream.readBuffer) == 0 }
+
+// Move the position of the handle back to the logical position of the stream
+func (stream *Stream) discardReadBuffer() {
+	if len(stream.readBuffer) == 0 {
+		return
+	}
+	if seeker, ok := stream.handle.(io.Seeker); ok {
+		seeker.Seek(stream.position, io.SeekStart)
+	}
+	stream.readBuffer = nil
+	stream.handleAtEnd = false
+}
+
+// -------------------------------------- Writing -------------------------------------- MARK: Writing
+
+func (stream *Stream) IsWritable() bool { return stream.writable }
+
+func (stream *Stream) Write(data string) (int, error) {
+	if !stream.writable {
+		return 0, syscall.EBADF
+	}
+	stream.discardReadBuffer()
+	seeker, seekable := stream.handle.(io.Seeker)
+	if stream.appendOnly && seekable {
+		if _, isFile := stream.handle.(*os.File); !isFile {
+			seeker.Seek(0, io.SeekEnd)
+		}
+	}
+	n, err := stream.handle.Write([]byte(data))
+	stream.position += int64(n)
+	if stream.appendOnly && seekable {
+		stream.position, _ = seeker.Seek(0, io.SeekCurrent)
+	}
+	return n, err
+}
+
+// Flush the written data. Writes are not buffered by the streams.
+func (stream *Stream) Flush() bool { return true }
+
+// Truncate the data to the given size. The position is not changed.
+func (stream *Stream) Truncate(size int64) bool {
+	handle, ok := stream.handle.(truncater)
+	if !ok || !stream.writable {
+		return false
+	}
+	stream.discardReadBuffer()
+	return handle.Truncate(size) == nil
+}
+
+// Check if the stream supports truncating (e.g. php://output does not)
+func (stream *Stream) IsTruncatable() bool {
+	_, ok := stream.handle.(truncater)
+	return ok
+}
+
+// -------------------------------------- Positioning -------------------------------------- MARK: Positioning
+
+// Set the position with SEEK_SET, SEEK_CUR or SEEK_END. Returns false if the stream is not seekable.
+func (stream *Stream) SetPosition(offset int64, whence int) bool {
+	seeker, ok := stream.handle.(io.Seeker)
+	if !ok {
+		return false
+	}
+	if _, isStdio := stream.handle.(*stdioHandle); isStdio {
+		return false
+	}
+	switch whence {
+	case SEEK_SET:
+	case SEEK_CUR:
+		offset += stream.position
+	case SEEK_END:
+		end, err := seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return false
+		}
+		offset += end
+	default:
+		return false
+	}
+	if offset < 0 {
+		return false
+	}
+	position, err := seeker.Seek(offset, io.SeekStart)
+	if err != nil {
+		return false
+	}
+	stream.position = position
+	stream.readBuffer = nil
+	stream.handleAtEnd = false
+	return true
+}
+
+func (stream *Stream) GetPosition() int64 { return stream.position }
+
+// -------------------------------------- Locking -------------------------------------- MARK: Locking
+
+// Apply an advisory lock (LOCK_SH, LOCK_EX or LOCK_UN with optional LOCK_NB). Only files support locking.
+func (stream *Stream) Lock(operation int) (ok bool, wouldBlock bool) {
+	file, isFile := stream.File()
+	if !isFile {
+		return false, false
+	}
+	return lockFile(file, operation)
+}
+
+// -------------------------------------- Files -------------------------------------- MARK: Files
+
+// Get the file of the stream. Returns false if the stream is not a file (e.g. php://memory).
+func (stream *Stream) File() (*os.File, bool) {
+	switch handle := stream.handle.(type) {
+	case *os.File:
+		return handle, true
+	case *tempFileHandle:
+		return handle.File, true
+	}
+	return nil, false
+}
+
+// Get the information of the underlying file. Returns false if the stream has no file (e.g. php://memory).
+func (stream *Stream) Stat() (os.FileInfo, bool) {
+	file, isFile := stream.File()
+	if stdio, isStdio := stream.handle.(*stdioHandle); isStdio {
+		file, isFile = stdio.File, true
+	}
+	if !isFile {
+		return nil, false
+	}
+	info, err := file.Stat()
+	return info, err == nil
+}
+
+// -------------------------------------- Closing -------------------------------------- MARK: Closing
+
+func (stream *Stream) Close() error {
+	stream.readBuffer = nil
+	return stream.handle.Close()
+}
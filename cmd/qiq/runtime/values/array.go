@@ -1,10 +1,10 @@
 package values
 
 import (
-	"QIQ/cmd/qiq/common"
 	"QIQ/cmd/qiq/config"
 	"QIQ/cmd/qiq/phpError"
 	"fmt"
+	"strconv"
 )
 
 type Array struct {
@@ -14,6 +14,8 @@ type Array struct {
 	// Keeping track of next key
 	nextKey    int64
 	nextKeySet bool
+	// Number of slots and values that hold the array
+	holders int
 }
 
 func NewArray() *Array {
@@ -60,11 +62,30 @@ func (array *Array) SetElement(key RuntimeValue, value RuntimeValue) phpError.Er
 	if !found {
 		array.Keys = append(array.Keys, key)
 	}
-	array.Elements[mapKey] = NewSlot(value)
+	slot := NewSlot(value)
+	if array.holders > 0 {
+		slot.Hold()
+		if found {
+			array.Elements[mapKey].Release()
+		}
+	}
+	array.Elements[mapKey] = slot
 
 	return nil
 }
 
+// Remove the slot with the given map key. The key has to be removed from Keys by the caller.
+func (array *Array) RemoveElement(mapKey string) {
+	slot, found := array.Elements[mapKey]
+	if !found {
+		return
+	}
+	delete(array.Elements, mapKey)
+	if array.holders > 0 {
+		slot.Release()
+	}
+}
+
 func (array *Array) GetMapKey(key RuntimeValue, shouldConvertKey bool) (string, bool, phpError.Error) {
 	if shouldConvertKey {
 		var err phpError.Error
@@ -139,11 +160,13 @@ func convertKey(key RuntimeValue) (RuntimeValue, phpError.Error) {
 		return NewInt(int64(key.(*Float).Value)), nil
 	}
 
-	if key.GetType() == StrValue && common.IsDecimalLiteral(key.(*Str).Value, false) {
+	if key.GetType() == StrValue {
 		// Spec: https://www.php.net/manual/en/language.types.array.php
 		// Strings containing valid decimal ints, unless the number is preceded by a + sign, will be cast to the int type.
 		// E.g. the key "8" will actually be stored under 8. On the other hand "08" will not be cast, as it isn't a valid decimal integer.
-		return NewInt(common.DecimalLiteralToInt64(key.(*Str).Value, false)), nil
+		if intValue, err := strconv.ParseInt(key.(*Str).Value, 10, 64); err == nil && strconv.FormatInt(intValue, 10) == key.(*Str).Value {
+			return NewInt(intValue), nil
+		}
 	}
 
 	if key.GetType() == NullValue {
@@ -169,7 +192,7 @@ func (array *Array) getNextKey(key RuntimeValue) (RuntimeValue, phpError.Error)
 			keyValue := key.(*Int).Value
 			// If no key is stored yet or the passed key is greater than nextKey
 			if !array.nextKeySet ||
-				(array.nextKeySet && keyValue > array.nextKey) {
+				(array.nextKeySet && keyValue >= array.nextKey) {
 				// Store value + 1 as next key
 				array.nextKey = keyValue + 1
 				array.nextKeySet = true
@@ -2,6 +2,7 @@ package values
 
 import (
 	"QIQ/cmd/qiq/ast"
+	"slices"
 )
 
 type Object struct {
@@ -11,9 +12,28 @@ type Object struct {
 	Properties    map[string]*Slot
 	// TODO methods
 	// TODO parent
+	// Internal state of natively implemented classes
+	Internal any
+	// Reference counting
+	refCount int
+	Observer RefCountObserver
 	// Status
-	IsUsed       bool
 	IsDestructed bool
+	// The properties and internal references are released (the object is freed)
+	IsReleased bool
+}
+
+// Implemented by the internal state of natively implemented classes that holds values.
+// The returned values are the values that are retained by the internal state (see Retain).
+// The garbage collector releases them when the object is freed and follows them when it searches for cycles.
+type InternalReferences interface {
+	GetReferences() []RuntimeValue
+}
+
+// Implemented by the internal state of natively implemented classes that references objects weakly (e.g. WeakMap).
+// The references are removed when the referenced object is freed.
+type WeakReferences interface {
+	RemoveWeakReference(object *Object)
 }
 
 func NewObject(class *ast.ClassDeclarationStatement) *Object {
@@ -24,11 +44,27 @@ func NewObject(class *ast.ClassDeclarationStatement) *Object {
 	}
 }
 
+// Set the value of the property. Properties that are not declared by the class are appended as dynamic properties.
 func (object *Object) SetProperty(name string, value RuntimeValue) {
 	if _, found := object.Properties[name]; found {
-		object.Properties[name].Value = value
-	} else {
-		object.Properties[name] = NewSlot(value)
+		object.Properties[name].Assign(value)
+		return
+	}
+	slot := NewSlot(value)
+	slot.Hold()
+	object.Properties[name] = slot
+	if !slices.Contains(object.PropertyNames, name) {
+		object.PropertyNames = append(object.PropertyNames, name)
+	}
+}
+
+func (object *Object) DeleteProperty(name string) {
+	if slot, found := object.Properties[name]; found {
+		delete(object.Properties, name)
+		slot.Release()
+	}
+	if index := slices.Index(object.PropertyNames, name); index >= 0 {
+		object.PropertyNames = slices.Delete(object.PropertyNames, index, index+1)
 	}
 }
 
@@ -35,6 +35,8 @@ func ToString(value RuntimeValue) string {
 	case ObjectValue:
 		return fmt.Sprintf("{Object: %s}\n", value.(*Object).Class.Name)
 		// TODO Add properties
+	case ResourceValue:
+		return fmt.Sprintf("{Resource: %d}\n", value.(*Resource).Id)
 	default:
 		return fmt.Sprintf("Unsupported RuntimeValue type %s\n", value.GetType())
 	}
@@ -56,6 +58,8 @@ func ToPhpType(value RuntimeValue) string {
 		return "string"
 	case ObjectValue:
 		return "object"
+	case ResourceValue:
+		return "resource"
 	case VoidValue:
 		return "void"
 	default:
@@ -0,0 +1,138 @@
+package values
+
+// Reference counting
+//
+// Objects count the references that are held by variables, array elements, properties, internal states of
+// native classes and temporary values of running statements.
+// Slots count their holders (variables, arrays and objects that contain the slot). A slot references its value
+// as long as it has at least one holder, so that references (e.g. `$b = &$a`) count as one reference to the value.
+// Arrays count their holders as well and only reference the values of their elements while they are held.
+// Temporary arrays (e.g. the result of an expression) do not keep their elements alive.
+// Resources count their references as well and are released as soon as they are no longer referenced.
+
+// Notified when the reference count of an object changes (e.g. by the garbage collector of the interpreter)
+type RefCountObserver interface {
+	// The reference count dropped to zero
+	Unreferenced(object *Object)
+	// The reference count was decremented but is not zero. The object might be part of a garbage cycle.
+	PossibleRoot(object *Object)
+}
+
+// Add a reference to the value
+func Retain(value RuntimeValue) {
+	switch value := value.(type) {
+	case *Object:
+		if value != nil {
+			value.refCount++
+		}
+	case *Resource:
+		if value != nil {
+			value.refCount++
+		}
+	case *Array:
+		if value == nil {
+			return
+		}
+		value.holders++
+		if value.holders == 1 {
+			for _, key := range value.Keys {
+				if slot, found := value.GetElement(key); found {
+					slot.Hold()
+				}
+			}
+		}
+	}
+}
+
+// Remove a reference from the value
+func Release(value RuntimeValue) {
+	switch value := value.(type) {
+	case *Object:
+		if value == nil || value.refCount <= 0 {
+			return
+		}
+		value.refCount--
+		if value.Observer == nil {
+			return
+		}
+		if value.refCount == 0 {
+			value.Observer.Unreferenced(value)
+		} else {
+			value.Observer.PossibleRoot(value)
+		}
+	case *Resource:
+		if value == nil || value.refCount <= 0 {
+			return
+		}
+		value.refCount--
+		if value.refCount == 0 {
+			value.Close()
+		}
+	case *Array:
+		if value == nil || value.holders <= 0 {
+			return
+		}
+		value.holders--
+		if value.holders == 0 {
+			for _, key := range value.Keys {
+				if slot, found := value.GetElement(key); found {
+					slot.Release()
+				}
+			}
+		}
+	}
+}
+
+// Number of references to the object
+func (object *Object) GetRefCount() int { return object.refCount }
+
+// Number of references to the resource
+func (resource *Resource) GetRefCount() int { return resource.refCount }
+
+// Number of holders of the array
+func (array *Array) GetHolders() int { return array.holders }
+
+// Number of holders of the slot
+func (slot *Slot) GetHolders() int { return slot.holders }
+
+// Add a holder to the slot. The first holder adds a reference to the value.
+func (slot *Slot) Hold() {
+	slot.holders++
+	if slot.holders == 1 {
+		Retain(slot.Value)
+	}
+}
+
+// Remove a holder from the slot. The last holder removes the reference to the value.
+func (slot *Slot) Release() {
+	if slot.holders <= 0 {
+		return
+	}
+	slot.holders--
+	if slot.holders == 0 {
+		Release(slot.Value)
+	} else if object, isObject := slot.Value.(*Object); isObject && object.Observer != nil {
+		// The slot is still shared by other holders (e.g. a reference that is part of a cycle)
+		object.Observer.PossibleRoot(object)
+	}
+}
+
+// Replace the value of the slot and move the reference from the old to the new value
+func (slot *Slot) Assign(value RuntimeValue) {
+	if slot.holders == 0 {
+		slot.Value = value
+		return
+	}
+	Retain(value)
+	old := slot.Value
+	slot.Value = value
+	Release(old)
+}
+
+// Replace a value that is referenced by the internal state of a native class and move the reference to the new value
+func Store(field *RuntimeValue, value RuntimeValue) {
+	Retain(value)
+	old := *field
+	*field = value
+	Release(old)
+}
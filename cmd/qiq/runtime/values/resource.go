@@ -0,0 +1,40 @@
+package values
+
+import "fmt"
+
+type Resource struct {
+	*abstractValue
+	Id int64
+	// Type as returned by get_resource_type (e.g. "stream")
+	Type string
+	// Internal state of the resource (e.g. the stream)
+	Internal any
+	IsClosed bool
+	// Called when the resource is released without being closed (e.g. to delete the file of tmpfile)
+	Release func()
+	// Number of references to the resource (see Retain)
+	refCount int
+}
+
+func NewResource(id int64, resourceType string, internal any) *Resource {
+	return &Resource{abstractValue: newAbstractValue(ResourceValue), Id: id, Type: resourceType, Internal: internal}
+}
+
+// Get the type of the resource. Closed resources have the type "Unknown".
+func (resource *Resource) GetResourceType() string {
+	if resource.IsClosed {
+		return "Unknown"
+	}
+	return resource.Type
+}
+
+// Call the release function if the resource is not closed yet
+func (resource *Resource) Close() {
+	if resource.IsClosed || resource.Release == nil {
+		return
+	}
+	resource.IsClosed = true
+	resource.Release()
+}
+
+func (resource *Resource) ToPhpString() string { return fmt.Sprintf("Resource id #%d", resource.Id) }
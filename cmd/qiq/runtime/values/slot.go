@@ -2,6 +2,8 @@ package values
 
 type Slot struct {
 	Value RuntimeValue
+	// Number of variables, arrays and objects that contain the slot
+	holders int
 }
 
 func NewSlot(value RuntimeValue) *Slot { return &Slot{Value: value} }
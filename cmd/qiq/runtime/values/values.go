@@ -1,6 +1,7 @@
 package values
 
 import (
+	"math"
 	"strconv"
 )
 
@@ -89,7 +90,17 @@ func NewFloat(value float64) *Float {
 
 func NewFloatSlot(value float64) *Slot { return NewSlot(NewFloat(value)) }
 
-func (value *Float) ToPhpString() string { return strconv.FormatFloat(value.Value, 'f', -1, 64) }
+func (value *Float) ToPhpString() string {
+	switch {
+	case math.IsNaN(value.Value):
+		return "NAN"
+	case math.IsInf(value.Value, 1):
+		return "INF"
+	case math.IsInf(value.Value, -1):
+		return "-INF"
+	}
+	return strconv.FormatFloat(value.Value, 'f', -1, 64)
+}
 
 // MARK: Str
 
@@ -137,7 +137,11 @@ func (generator *AstGenerator) ProcessLogicalNotExpr(stmt *ast.LogicalNotExpress
 
 // ProcessMemberAccessExpr implements ast.Visitor.
 func (generator *AstGenerator) ProcessMemberAccessExpr(stmt *ast.MemberAccessExpression, _ any) (any, error) {
-	generator.print("ast.NewMemberAccessExpr(0, nil, ")
+	if stmt.IsScoped {
+		generator.print("ast.NewScopedPropertyAccessExpr(0, nil, ")
+	} else {
+		generator.print("ast.NewMemberAccessExpr(0, nil, ")
+	}
 	generator.processStmt(stmt.Object)
 	generator.print(", ")
 	generator.processStmt(stmt.Member)
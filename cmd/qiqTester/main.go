@@ -35,7 +35,7 @@ func main() {
 	verbosity2Flag := flag.Bool("v2", false, "Verbosity level 2: Show all tests and failure reason")
 	onlyFailedFlag := flag.Bool("only-failed", false, "Show only failed tests")
 	noColorFlag := flag.Bool("no-color", false, "Do not use color in output")
-	replaceJsonFlag := flag.String("replace-json", "", "JSON to replace parts of a test")
+	replaceJsonFlag := flag.String("replace-json", "", "JSON to replace parts of a test or to skip a test")
 
 	flag.Parse()
 	verbosity1 = *verbosity1Flag
@@ -119,6 +119,25 @@ func doTest(path string, info goOs.FileInfo, _ error) error {
 		return nil
 	}
 
+	// A crash of the interpreter only fails the current test
+	defer func() {
+		if r := recover(); r != nil {
+			if verbosity1 || verbosity2 {
+				printFail(path)
+			}
+			if verbosity2 {
+				fmt.Println("      Panic:", r)
+			}
+			failed++
+		}
+	}()
+
+	if skipEntry, found := replaceData.GetSkipEntry(path); found {
+		printSkip(path, skipEntry.Reason)
+		skipped++
+		return nil
+	}
+
 	replaceEntry, hasReplaceEntry := replaceData.GetEntry(path)
 	reader, err := phpt.NewReader(path, replaceEntry)
 	if err != nil {
@@ -179,19 +198,9 @@ func doTest(path string, info goOs.FileInfo, _ error) error {
 	if strings.HasPrefix(result, "skip for") || strings.HasPrefix(result, "skip Run") ||
 		strings.HasPrefix(result, "skip only") || strings.HasPrefix(result, "skip this") ||
 		strings.HasPrefix(result, "skip.. ") || strings.HasPrefix(result, "skip ") {
-		if !onlyFailed && (verbosity1 || verbosity2) {
-			if noColor {
-				fmt.Println("SKIP ", path)
-			} else {
-				fmt.Println("\033[33mSKIP\033[0m ", path)
-			}
-		}
-		if !onlyFailed && (verbosity2) {
-			reason := strings.TrimPrefix(result, "skip ")
-			reason = strings.TrimPrefix(reason, "skip.. ")
-			reason = strings.ToUpper(string(reason[0])) + reason[1:]
-			fmt.Println("     ", reason)
-		}
+		reason := strings.TrimPrefix(result, "skip ")
+		reason = strings.TrimPrefix(reason, "skip.. ")
+		printSkip(path, strings.ToUpper(string(reason[0]))+reason[1:])
 		skipped++
 		return nil
 	}
@@ -306,6 +315,19 @@ func printFail(path string) {
 	}
 }
 
+func printSkip(path string, reason string) {
+	if !onlyFailed && (verbosity1 || verbosity2) {
+		if noColor {
+			fmt.Println("SKIP ", path)
+		} else {
+			fmt.Println("\033[33mSKIP\033[0m ", path)
+		}
+	}
+	if !onlyFailed && verbosity2 {
+		fmt.Println("     ", reason)
+	}
+}
+
 var replaceData replacejson.ReplaceJson
 
 func loadReplaceJson() phpError.Error {
@@ -12,19 +12,38 @@ type ReplaceEntry struct {
 	Replace string `json:"replace"`
 }
 
+// Test that is not run because it depends on features that are out of scope (e.g. setlocale or object serialization)
+type SkipEntry struct {
+	File   string `json:"file"`
+	Reason string `json:"reason"`
+}
+
 type ReplaceJson struct {
 	Replace []ReplaceEntry `json:"replace"`
+	Skip    []SkipEntry    `json:"skip"`
 }
 
 func (replaceJson *ReplaceJson) GetEntry(filename string) (ReplaceEntry, bool) {
 	for _, entry := range replaceJson.Replace {
-		entryFile := entry.File
-		if os.IS_WIN {
-			entryFile = strings.ReplaceAll(entryFile, "/", `\`)
-		}
-		if entryFile == filename {
+		if isFile(entry.File, filename) {
 			return entry, true
 		}
 	}
 	return ReplaceEntry{}, false
 }
+
+func (replaceJson *ReplaceJson) GetSkipEntry(filename string) (SkipEntry, bool) {
+	for _, entry := range replaceJson.Skip {
+		if isFile(entry.File, filename) {
+			return entry, true
+		}
+	}
+	return SkipEntry{}, false
+}
+
+func isFile(entryFile string, filename string) bool {
+	if os.IS_WIN {
+		entryFile = strings.ReplaceAll(entryFile, "/", `\`)
+	}
+	return entryFile == filename
+}
@@ -0,0 +1,33 @@
+--TEST--
+DateTime::__construct() and DateTime::modify() with relative formats
+--INI--
+date.timezone=UTC
+--FILE--
+<?php
+$d = new DateTime('2024-01-31 08:00:00');
+echo $d->format('Y-m-d H:i:s'), "\n";
+$d->modify('+1 month');
+echo $d->format('Y-m-d H:i:s'), "\n";
+$d->modify('last day of previous month');
+echo $d->format('Y-m-d H:i:s'), "\n";
+$d->modify('next friday');
+echo $d->format('Y-m-d H:i:s'), "\n";
+$d->modify('noon +90 minutes');
+echo $d->format('Y-m-d H:i:s'), "\n";
+$i = new DateTimeImmutable('2024-03-10 12:00:00 America/New_York');
+echo $i->format('c T'), "\n";
+echo $i->modify('-1 day')->format('c T'), "\n";
+$d = new DateTime('@86400');
+echo $d->format('Y-m-d H:i:s e'), "\n";
+try { new DateTime('not a date'); } catch (Exception $e) { echo get_class($e), ": ", $e->getMessage(), "\n"; }
+?>
+--EXPECT--
+2024-01-31 08:00:00
+2024-03-02 08:00:00
+2024-02-29 08:00:00
+2024-03-01 00:00:00
+2024-03-01 13:30:00
+2024-03-10T12:00:00-04:00 EDT
+2024-03-09T12:00:00-05:00 EST
+1970-01-02 00:00:00 +00:00
+DateMalformedStringException: Failed to parse time string (not a date) at position 0 (n): The timezone could not be found in the database
@@ -0,0 +1,53 @@
+--TEST--
+date_parse_from_format() with timezone offsets, invalid dates and unmatched input
+--FILE--
+<?php
+print_r(date_parse_from_format("j.n.Y H:iP", "6.1.2009 13:00+01:00"));
+$r = date_parse_from_format("Y-m-d", "2024-02-30");
+var_dump($r["warning_count"], $r["warnings"], $r["hour"]);
+$r = date_parse_from_format("Y-m-d", "2024-02-10 extra");
+var_dump($r["error_count"], $r["errors"]);
+$r = date_parse_from_format("Y-m-d H:i", "2024-02-10");
+var_dump($r["error_count"], $r["errors"]);
+?>
+--EXPECT--
+Array
+(
+    [year] => 2009
+    [month] => 1
+    [day] => 6
+    [hour] => 13
+    [minute] => 0
+    [second] => 0
+    [fraction] => 0
+    [warning_count] => 0
+    [warnings] => Array
+        (
+        )
+
+    [error_count] => 0
+    [errors] => Array
+        (
+        )
+
+    [is_localtime] => 1
+    [zone_type] => 1
+    [zone] => 3600
+    [is_dst] => 
+)
+int(1)
+array(1) {
+  [10]=>
+  string(27) "The parsed date was invalid"
+}
+bool(false)
+int(1)
+array(1) {
+  [10]=>
+  string(13) "Trailing data"
+}
+int(1)
+array(1) {
+  [10]=>
+  string(43) "Not enough data available to satisfy format"
+}
@@ -0,0 +1,47 @@
+--TEST--
+strtotime() with timestamps, ISO-8601, RFC-2822 and timezone suffixes
+--INI--
+date.timezone=UTC
+--FILE--
+<?php
+$formats = [
+    '@1700000000', '@-86400',
+    '2024-02-29', '2024-02-29 23:59:59', '2024-02-29T23:59:59', '2024-02-29T23:59:59Z',
+    '2024-02-29T23:59:59+02:00', '2024-02-29T23:59:59.5-0330',
+    '20240229T235959', '2024-W09-4', '2024.060', '2024-02-30',
+    'Thu, 29 Feb 2024 23:59:59 +0000', 'Thu, 29 Feb 2024 23:59:59 GMT', 'Thursday, 29-Feb-24 23:59:59 UTC',
+    '29 February 2024', 'Feb 29, 2024 11:59pm', '02/29/2024', '29.02.2024', '29-02-2024',
+    '2024-02-29 12:00 Europe/Berlin', '2024-02-29 12:00 EST', '2024-02-29 12:00 America/New_York',
+];
+foreach ($formats as $format) {
+    echo $format, ": ", gmdate('Y-m-d H:i:s', strtotime($format)), "\n";
+}
+var_dump(strtotime(''), strtotime('not a date'), strtotime('+1 blah'));
+?>
+--EXPECT--
+@1700000000: 2023-11-14 22:13:20
+@-86400: 1969-12-31 00:00:00
+2024-02-29: 2024-02-29 00:00:00
+2024-02-29 23:59:59: 2024-02-29 23:59:59
+2024-02-29T23:59:59: 2024-02-29 23:59:59
+2024-02-29T23:59:59Z: 2024-02-29 23:59:59
+2024-02-29T23:59:59+02:00: 2024-02-29 21:59:59
+2024-02-29T23:59:59.5-0330: 2024-03-01 03:29:59
+20240229T235959: 2024-02-29 23:59:59
+2024-W09-4: 2024-02-29 00:00:00
+2024.060: 2024-02-29 00:00:00
+2024-02-30: 2024-03-01 00:00:00
+Thu, 29 Feb 2024 23:59:59 +0000: 2024-02-29 23:59:59
+Thu, 29 Feb 2024 23:59:59 GMT: 2024-02-29 23:59:59
+Thursday, 29-Feb-24 23:59:59 UTC: 2024-02-29 23:59:59
+29 February 2024: 2024-02-29 00:00:00
+Feb 29, 2024 11:59pm: 2024-02-29 23:59:00
+02/29/2024: 2024-02-29 00:00:00
+29.02.2024: 2024-02-29 00:00:00
+29-02-2024: 2024-02-29 00:00:00
+2024-02-29 12:00 Europe/Berlin: 2024-02-29 11:00:00
+2024-02-29 12:00 EST: 2024-02-29 17:00:00
+2024-02-29 12:00 America/New_York: 2024-02-29 17:00:00
+bool(false)
+bool(false)
+bool(false)
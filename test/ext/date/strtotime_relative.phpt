@@ -0,0 +1,46 @@
+--TEST--
+strtotime() with relative formats
+--INI--
+date.timezone=UTC
+--FILE--
+<?php
+// Wednesday
+$base = strtotime('2024-01-10 12:30:00');
+$formats = [
+    'now', 'today', 'midnight', 'noon', 'tomorrow', 'yesterday',
+    '+1 day', '-1 week', '+1 week 2 days', '+2 hours 30 minutes', '3 days ago',
+    'next monday', 'last friday', 'monday', 'wednesday', 'next month', 'last year',
+    'first day of next month', 'last day of next month', 'last day of february',
+    'first monday of january 2025', 'last sunday of march 2024',
+    'saturday this week', 'tomorrow noon', 'yesterday 14:00',
+];
+foreach ($formats as $format) {
+    echo $format, ": ", date('D Y-m-d H:i:s', strtotime($format, $base)), "\n";
+}
+?>
+--EXPECT--
+now: Wed 2024-01-10 12:30:00
+today: Wed 2024-01-10 00:00:00
+midnight: Wed 2024-01-10 00:00:00
+noon: Wed 2024-01-10 12:00:00
+tomorrow: Thu 2024-01-11 00:00:00
+yesterday: Tue 2024-01-09 00:00:00
++1 day: Thu 2024-01-11 12:30:00
+-1 week: Wed 2024-01-03 12:30:00
++1 week 2 days: Fri 2024-01-19 12:30:00
++2 hours 30 minutes: Wed 2024-01-10 15:00:00
+3 days ago: Sun 2024-01-07 12:30:00
+next monday: Mon 2024-01-15 00:00:00
+last friday: Fri 2024-01-05 00:00:00
+monday: Mon 2024-01-15 00:00:00
+wednesday: Wed 2024-01-10 00:00:00
+next month: Sat 2024-02-10 12:30:00
+last year: Tue 2023-01-10 12:30:00
+first day of next month: Thu 2024-02-01 12:30:00
+last day of next month: Thu 2024-02-29 12:30:00
+last day of february: Thu 2024-02-29 00:00:00
+first monday of january 2025: Mon 2025-01-06 00:00:00
+last sunday of march 2024: Sun 2024-03-31 00:00:00
+saturday this week: Sat 2024-01-13 00:00:00
+tomorrow noon: Thu 2024-01-11 12:00:00
+yesterday 14:00: Tue 2024-01-09 14:00:00